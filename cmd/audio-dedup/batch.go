@@ -0,0 +1,181 @@
+// file: cmd/audio-dedup/batch.go
+// package: main
+//
+// 批量模式（-batch）：为 -src 的每个直接子目录以子进程运行一次本程序（-src <子目录> -dst <目标目录>/<子目录名>），
+// 子进程的工作目录为批次目录下的同名子目录，报告、日志与相对路径的输出文件都写在那里；
+// 每个任务以 -summary-json 输出退出摘要，最后合并成汇总表（batch_summary.csv）与控制台摘要。
+// 任务可以并行（-batch-parallel），资源配额（-workers、-max-ffmpeg、-io-rate）由每个任务进程各自执行，
+// 一个大任务不会拖慢其它任务；每个任务的配额显示在进度行与汇总表中。
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"deduplicateMusic/internal/batch"
+	"deduplicateMusic/internal/config"
+	"deduplicateMusic/internal/memlimit"
+	"deduplicateMusic/internal/report"
+)
+
+// runBatch 运行各子目录的任务（最多 -batch-parallel 个同时运行）并输出汇总，
+// 返回进程退出码：有任务失败时为 1，有任务提前停止时为 3。
+// ctx 取消（Ctrl-C）后不再启动新任务；子进程与本进程在同一进程组，自行收到 Ctrl-C 并在安全点停止，
+// 未启动的任务记为提前停止
+func runBatch(ctx context.Context, cfg *config.Options, jsonOut io.Writer) int {
+	exe, err := os.Executable()
+	if err != nil {
+		fatalf("无法确定可执行文件路径: %v", err)
+	}
+	root, err := filepath.Abs("audio_dedup_batch_" + report.Stamp())
+	if err != nil {
+		fatalf("%v", err)
+	}
+	jobs, err := batch.Jobs(cfg.Src, cfg.Dst, root)
+	if err != nil {
+		fatalf("读取源目录失败: %v", err)
+	}
+	if len(jobs) == 0 {
+		fatalf("源目录 %s 下没有子目录，-batch 没有任务可运行", cfg.Src)
+	}
+	if cfg.IORate != "" {
+		if _, err := memlimit.ParseSize(cfg.IORate); err != nil {
+			fatalf("无效的 -io-rate: %v", err)
+		}
+	}
+	// 子进程沿用显式指定的参数（含 -workers、-max-ffmpeg、-io-rate 配额）；源、目标目录按子目录改写，
+	// 报告由子进程写在各自的工作目录中
+	args, err := config.Args(flag.CommandLine, "src", "dst", "batch", "batch-parallel", "config", "print-config", "summary-json", "quiet")
+	if err != nil {
+		fatalf("%v", err)
+	}
+	parallel := min(cfg.BatchParallel, len(jobs))
+	limits := batch.Limits{Workers: cfg.Workers, MaxFFmpeg: cfg.MaxFFmpeg, IORate: cfg.IORate}
+	workersSet := false
+	flag.CommandLine.Visit(func(f *flag.Flag) { workersSet = workersSet || f.Name == "workers" })
+	if !workersSet && parallel > 1 {
+		// 同时运行多个任务时平分 CPU，避免每个任务都按全部核数启动 worker
+		limits.Workers = max(1, runtime.NumCPU()/parallel)
+		args = append(args, "-workers="+strconv.Itoa(limits.Workers))
+	}
+	src, _ := filepath.Abs(cfg.Src)
+	dst, _ := filepath.Abs(cfg.Dst)
+	fmt.Printf("批量模式：%d 个子目录，同时运行 %d 个，每个任务 %s，报告写到 %s\n", len(jobs), parallel, limits, root)
+
+	results := make([]batch.Result, len(jobs))
+	var mu sync.Mutex // 保护进度输出
+	done := 0
+	run := func(i int) {
+		name := jobs[i]
+		dir := filepath.Join(root, name)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			fatalf("创建任务目录失败: %v", err)
+		}
+		res := batch.Result{Name: name, Limits: limits}
+		logFile, err := os.Create(filepath.Join(dir, "audio_dedup_batch.log"))
+		if err != nil {
+			fatalf("创建任务日志失败: %v", err)
+		}
+		var out bytes.Buffer
+		cmd := exec.Command(exe, append(args, "-src", filepath.Join(src, name), "-dst", filepath.Join(dst, name), "-summary-json", "-quiet")...)
+		cmd.Dir, cmd.Stdout, cmd.Stderr = dir, &out, logFile
+		started := time.Now()
+		err = cmd.Run()
+		logFile.Close()
+		var exitErr *exec.ExitError
+		switch {
+		case errors.As(err, &exitErr):
+			res.ExitCode = exitErr.ExitCode()
+		case err != nil:
+			res.ExitCode, res.Err = -1, err.Error()
+		}
+		if out.Len() > 0 {
+			if err := json.Unmarshal(out.Bytes(), &res.Summary); err != nil && res.Err == "" {
+				res.Err = "无法解析退出摘要: " + err.Error()
+			}
+		} else if res.Err == "" && res.ExitCode != 0 {
+			res.Err = fmt.Sprintf("退出码 %d，见 %s", res.ExitCode, logFile.Name())
+		}
+		// 报告路径相对于任务目录，汇总中改为绝对路径
+		for _, p := range []*string{&res.Summary.Report, &res.Summary.Summary} {
+			if *p != "" && !filepath.IsAbs(*p) {
+				*p = filepath.Join(dir, *p)
+			}
+		}
+		results[i] = res
+		mu.Lock()
+		defer mu.Unlock()
+		done++
+		fmt.Printf("[%d/%d] %s：%s，文件 %d，重复 %d（%s），耗时 %s，%s\n", done, len(jobs), name, res.Status(),
+			res.Summary.Files, res.Summary.Duplicates, report.HumanBytes(res.Summary.DuplicateBytes), time.Since(started).Round(time.Second), res.Limits)
+		if res.Err != "" {
+			warnf("任务 %s 失败: %s", name, res.Err)
+		}
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < parallel; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				run(i)
+			}
+		}()
+	}
+	for i := range jobs {
+		if ctx.Err() == nil {
+			select {
+			case next <- i:
+				continue
+			case <-ctx.Done():
+			}
+		}
+		results[i] = batch.Result{Name: jobs[i], Limits: limits, ExitCode: 3,
+			Summary: report.RunSummary{Stopped: "收到中断信号，未运行"}}
+	}
+	close(next)
+	wg.Wait()
+
+	f, err := os.Create(filepath.Join(root, "batch_summary.csv"))
+	if err == nil {
+		err = batch.WriteCSV(f, results)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		warnf("写入批量汇总失败: %v", err)
+	}
+	batch.WriteText(os.Stdout, results)
+	fmt.Printf("批量汇总已生成: %s\n", filepath.Join(root, "batch_summary.csv"))
+	if cfg.SummaryJSON {
+		total := batch.Total(results)
+		total.Summary = filepath.Join(root, "batch_summary.csv")
+		if err := total.WriteJSON(jsonOut); err != nil {
+			warnf("输出 JSON 摘要失败: %v", err)
+		}
+	}
+	code := 0
+	for _, r := range results {
+		switch {
+		case r.Failed():
+			return 1
+		case r.ExitCode == 3:
+			code = 3
+		}
+	}
+	return code
+}
@@ -0,0 +1,181 @@
+// file: cmd/audio-dedup/bench.go
+// package: main
+//
+// bench 子命令：对带标注的重复数据集（本地目录，或 -download 指定的 .tar.gz）只读地计算指纹、分组，
+// 报告当前算法与阈值下的 precision / recall / F1，便于定量比较算法改动。不复制、不写入数据集。
+//
+// 数据集约定：目录下有 labels.csv，每行 "path,group"（path 相对于 CSV 所在目录），同一 group 的文件互为重复。
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"deduplicateMusic/internal/dedup"
+	"deduplicateMusic/internal/eval"
+	"deduplicateMusic/internal/fingerprint"
+)
+
+// runBench 执行 bench 子命令，返回进程退出码
+func runBench(args []string) int {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	dataset := fs.String("dataset", "", "数据集目录（包含 labels.csv）")
+	labels := fs.String("labels", "", "标注文件（path,group），默认为 <dataset>/labels.csv")
+	download := fs.String("download", "", "数据集 .tar.gz 的 URL：下载并解压到 -cache 后使用（已存在时不重复下载）")
+	cacheDir := fs.String("cache", defaultBenchCache(), "下载数据集的缓存目录")
+	threshold := fs.Int("threshold", 8, "相似度阈值（哈希汉明距离）")
+	seconds := fs.Int("seconds", 8, "用于指纹的音频时长（秒）")
+	matcherName := fs.String("matcher", "hamming", "重复判定匹配器名称")
+	metricName := fs.String("metric", "hamming", "指纹距离度量："+strings.Join(dedup.MetricNames(), "、"))
+	workers := fs.Int("workers", 4, "并发解码数量")
+	fs.Parse(args)
+
+	if *download != "" {
+		dir, err := fetchDataset(*download, *cacheDir)
+		if err != nil {
+			fmt.Printf("下载数据集失败: %v\n", err)
+			return 2
+		}
+		*dataset = dir
+	}
+	if *labels == "" {
+		if *dataset == "" {
+			fmt.Println("bench 需要 -dataset、-labels 或 -download")
+			fs.Usage()
+			return 2
+		}
+		*labels = filepath.Join(*dataset, "labels.csv")
+	}
+	truth, err := eval.LoadGroupsFile(*labels)
+	if err != nil {
+		fmt.Printf("读取标注失败: %v\n", err)
+		return 2
+	}
+	matcher, err := lookupMatcher(*matcherName, *metricName, *threshold)
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		return 2
+	}
+
+	paths := make([]string, 0, len(truth.Label))
+	for p := range truth.Label {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	metas := fingerprintAll(paths, fingerprint.Options{Seconds: *seconds, Bits: 64, Blocks: strings.EqualFold(*metricName, "cosine")}, *workers)
+	s := eval.Evaluate(metas, truth, dedup.Options{Threshold: *threshold, Matcher: matcher})
+	fmt.Printf("数据集: %s（%d 个文件，成功 %d）\n", *labels, len(paths), len(metas))
+	fmt.Printf("matcher=%s metric=%s threshold=%d seconds=%d\n", *matcherName, *metricName, *threshold, *seconds)
+	fmt.Printf("TP=%d FP=%d FN=%d  precision=%.4f recall=%.4f F1=%.4f\n", s.TP, s.FP, s.FN, s.Precision, s.Recall, s.F1)
+	return 0
+}
+
+// fingerprintAll 并发计算指纹，失败的文件记录警告后跳过；返回结果保持 paths 的顺序
+func fingerprintAll(paths []string, opt fingerprint.Options, workers int) []dedup.FileMeta {
+	out := make([]dedup.FileMeta, len(paths))
+	ok := make([]bool, len(paths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				an, err := fingerprint.AnalyzeFile(paths[i], opt)
+				if err != nil {
+					slog.Warn("计算指纹失败", "path", paths[i], "err", err)
+					continue
+				}
+				var size int64
+				if info, err := os.Stat(paths[i]); err == nil {
+					size = info.Size()
+				}
+				out[i], ok[i] = dedup.FileMeta{Path: paths[i], Size: size, FP: an.FP, Blocks: an.Blocks}, true
+			}
+		}()
+	}
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	metas := out[:0]
+	for i, m := range out {
+		if ok[i] {
+			metas = append(metas, m)
+		}
+	}
+	return metas
+}
+
+func defaultBenchCache() string {
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "audio-dedup", "bench")
+	}
+	return filepath.Join(os.TempDir(), "audio-dedup-bench")
+}
+
+// fetchDataset 下载 .tar.gz 并解压到 cache 下以压缩包命名的目录，返回该目录
+func fetchDataset(url, cache string) (string, error) {
+	name := strings.TrimSuffix(strings.TrimSuffix(path.Base(url), ".tgz"), ".tar.gz")
+	dir := filepath.Join(cache, name)
+	done := filepath.Join(dir, ".complete")
+	if _, err := os.Stat(done); err == nil {
+		return dir, nil
+	}
+	resp, err := http.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		target := filepath.Join(dir, filepath.FromSlash(h.Name))
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(filepath.Separator)) {
+			return "", fmt.Errorf("压缩包中的路径越界: %s", h.Name)
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return "", err
+		}
+		f, err := os.Create(target)
+		if err != nil {
+			return "", err
+		}
+		_, err = io.Copy(f, tr)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return dir, os.WriteFile(done, nil, 0o644)
+}
@@ -0,0 +1,190 @@
+// file: cmd/audio-dedup/db.go
+// package: main
+//
+// db 子命令：维护长期使用的指纹库（见 internal/cache）。
+//
+//	audio-dedup db stats  [-cache 文件]             记录数、被覆盖的旧行、文件大小与各指纹参数签名的记录数
+//	audio-dedup db prune  [-cache 文件] [-dry-run]  删除已不存在或已改动（大小 / 修改时间不符）的文件的记录
+//	audio-dedup db vacuum [-cache 文件]             重写缓存文件，去掉被覆盖的旧行
+//	audio-dedup db serve  [-cache 文件] [-listen 地址]  以 HTTP 服务对外提供缓存，供其它机器共用
+//
+// 多台机器也可以直接共用网络路径（NFS / SMB）上的同一个缓存文件，读写时以旁边的 .lock 文件互斥。
+// -cache 为服务地址（http://主机:端口）时 stats 查询服务端的统计，prune 与 vacuum 须在服务端执行。
+// 指纹库中的相对路径按当前目录解析，应在写入指纹库的运行所在的目录执行 prune（可先加 -dry-run 核对）。
+package main
+
+import (
+	"context"
+	"deduplicateMusic/internal/cache"
+	"deduplicateMusic/internal/report"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"syscall"
+)
+
+// runDB 执行 db 子命令，返回进程退出码
+func runDB(args []string) int {
+	flags := flag.NewFlagSet("db", flag.ExitOnError)
+	path := flags.String("cache", cache.DefaultPath(), "指纹缓存文件")
+	dryRun := flags.Bool("dry-run", false, "prune 时只统计将删除的记录，不改动缓存")
+	listen := flags.String("listen", ":8765", "serve 时监听的地址")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "用法: %s db stats|prune|vacuum|serve [选项]\n", filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+	if len(args) == 0 {
+		flags.Usage()
+		return 2
+	}
+	action := args[0]
+	flags.Parse(args[1:])
+	if action != "stats" && action != "prune" && action != "vacuum" && action != "serve" {
+		fmt.Printf("未知的 db 操作: %s\n", action)
+		flags.Usage()
+		return 2
+	}
+	if cache.IsRemote(*path) {
+		return remoteDB(action, *path)
+	}
+	if action == "serve" {
+		return serveDB(*path, *listen)
+	}
+	if _, err := os.Stat(*path); err != nil {
+		fmt.Printf("无法读取指纹缓存: %v\n", err)
+		return 1
+	}
+	c, err := cache.Open(*path)
+	if err != nil {
+		fmt.Printf("打开指纹缓存失败: %v\n", err)
+		return 1
+	}
+	defer func() {
+		if err := c.Close(); err != nil {
+			fmt.Printf("保存指纹缓存失败: %v\n", err)
+		}
+	}()
+
+	switch action {
+	case "stats":
+		s, err := c.Summary()
+		if err != nil {
+			fmt.Printf("统计指纹缓存失败: %v\n", err)
+			return 1
+		}
+		printSummary(*path, s)
+	case "prune":
+		missing, changed := 0, 0
+		drop := func(e cache.Entry) bool {
+			fi, err := os.Stat(e.Path)
+			switch {
+			case errors.Is(err, fs.ErrNotExist):
+				missing++
+				return true
+			case err != nil:
+				return false // 暂时无法访问（权限、未挂载的网络盘）的文件不删除
+			case fi.Size() != e.Size || fi.ModTime().UnixNano() != e.ModTime:
+				changed++
+				return true
+			}
+			return false
+		}
+		if *dryRun {
+			s, _ := c.Summary()
+			n := 0
+			c.Prune(func(e cache.Entry) bool {
+				if drop(e) {
+					n++
+				}
+				return false
+			})
+			fmt.Printf("将删除 %d 条记录（文件已不存在 %d，已改动 %d），共 %d 条\n", n, missing, changed, s.Entries)
+			return 0
+		}
+		n := c.Prune(drop)
+		if err := c.Vacuum(); err != nil {
+			fmt.Printf("重写指纹缓存失败: %v\n", err)
+			return 1
+		}
+		fmt.Printf("已删除 %d 条记录（文件已不存在 %d，已改动 %d），剩余 %d 条\n", n, missing, changed, c.Len())
+	case "vacuum":
+		before, _ := c.Summary()
+		if err := c.Vacuum(); err != nil {
+			fmt.Printf("重写指纹缓存失败: %v\n", err)
+			return 1
+		}
+		after, _ := c.Summary()
+		fmt.Printf("已重写指纹缓存：%d 行 → %d 行，%s → %s\n", before.Lines, after.Lines,
+			report.HumanBytes(before.Bytes), report.HumanBytes(after.Bytes))
+	}
+	return 0
+}
+
+// printSummary 输出 db stats 的统计信息
+func printSummary(location string, s cache.Summary) {
+	fmt.Printf("指纹缓存 %s：%d 条记录，%d 行（其中 %d 行已被覆盖），%s\n",
+		location, s.Entries, s.Lines, s.Lines-s.Entries, report.HumanBytes(s.Bytes))
+	sigs := make([]string, 0, len(s.Sigs))
+	for sig := range s.Sigs {
+		sigs = append(sigs, sig)
+	}
+	sort.Strings(sigs)
+	for _, sig := range sigs {
+		fmt.Printf("  %6d  %s\n", s.Sigs[sig], sig)
+	}
+}
+
+// remoteDB 对缓存服务执行 db 操作：只支持 stats
+func remoteDB(action, addr string) int {
+	if action != "stats" {
+		fmt.Printf("db %s 须在缓存服务所在的机器上对缓存文件执行\n", action)
+		return 2
+	}
+	r, err := cache.OpenRemote(addr)
+	if err != nil {
+		fmt.Printf("连接指纹缓存服务失败: %v\n", err)
+		return 1
+	}
+	defer r.Close()
+	s, err := r.Summary()
+	if err != nil {
+		fmt.Printf("统计指纹缓存失败: %v\n", err)
+		return 1
+	}
+	printSummary(addr, s)
+	return 0
+}
+
+// serveDB 以 HTTP 服务对外提供 path 处的缓存，收到中断信号后写出缓冲并退出
+func serveDB(path, listen string) int {
+	c, err := cache.Open(path)
+	if err != nil {
+		fmt.Printf("打开指纹缓存失败: %v\n", err)
+		return 1
+	}
+	srv := &http.Server{Addr: listen, Handler: cache.Handler(c)}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		srv.Shutdown(context.Background())
+	}()
+	slog.Info("指纹缓存服务已启动", "cache", path, "listen", listen)
+	code := 0
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		fmt.Printf("指纹缓存服务失败: %v\n", err)
+		code = 1
+	}
+	if err := c.Close(); err != nil {
+		fmt.Printf("保存指纹缓存失败: %v\n", err)
+		return 1
+	}
+	return code
+}
@@ -0,0 +1,132 @@
+// file: cmd/audio-dedup/evalcmd.go
+// package: main
+//
+// eval 子命令：用户提供自己资料库中已知的重复对（CSV，每行 "a,b"），对其中出现的文件计算一次指纹，
+// 在一组阈值上分别评估误判（FP）与漏判（FN），输出阈值-F1 曲线，帮助为自己的资料库挑选阈值。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"deduplicateMusic/internal/dedup"
+	"deduplicateMusic/internal/eval"
+	"deduplicateMusic/internal/fingerprint"
+	"deduplicateMusic/internal/gzfile"
+	"deduplicateMusic/internal/report"
+)
+
+// runEval 执行 eval 子命令，返回进程退出码
+func runEval(args []string) int {
+	fs := flag.NewFlagSet("eval", flag.ExitOnError)
+	truthFile := fs.String("truth", "", "已知重复对 CSV（a,b），相对路径相对于 CSV 所在目录")
+	thresholds := fs.String("thresholds", "0-16", "要评估的阈值：范围 \"0-16\" 或列表 \"4,8,12\"")
+	seconds := fs.Int("seconds", 8, "用于指纹的音频时长（秒）")
+	matcherName := fs.String("matcher", "hamming", "重复判定匹配器名称")
+	metricName := fs.String("metric", "hamming", "指纹距离度量："+strings.Join(dedup.MetricNames(), "、"))
+	workers := fs.Int("workers", 4, "并发解码数量")
+	out := fs.String("out", "", "曲线 CSV 输出路径（默认 audio_dedup_eval_<时间戳>.csv；以 .gz 结尾时压缩）")
+	fs.Parse(args)
+
+	if *truthFile == "" {
+		fmt.Println("eval 需要 -truth")
+		fs.Usage()
+		return 2
+	}
+	ths, err := parseThresholds(*thresholds)
+	if err != nil {
+		fmt.Printf("无效的 -thresholds: %v\n", err)
+		return 2
+	}
+	truth, err := eval.LoadPairsFile(*truthFile)
+	if err != nil {
+		fmt.Printf("读取重复对失败: %v\n", err)
+		return 2
+	}
+	paths := make([]string, 0, len(truth.Label))
+	for p := range truth.Label {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	opt := fingerprint.Options{Seconds: *seconds, Bits: 64, Blocks: strings.EqualFold(*metricName, "cosine")}
+	metas := fingerprintAll(paths, opt, *workers)
+	scores, err := eval.Sweep(metas, truth, ths, func(th int) (dedup.Matcher, error) {
+		return lookupMatcher(*matcherName, *metricName, th)
+	})
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		return 2
+	}
+
+	fmt.Printf("已知重复对涉及 %d 个文件（成功 %d），matcher=%s metric=%s seconds=%d\n", len(paths), len(metas), *matcherName, *metricName, *seconds)
+	fmt.Println("阈值    FP    FN  precision  recall      F1")
+	for _, s := range scores {
+		fmt.Printf("%4d %5d %5d     %.4f  %.4f  %.4f %s\n", s.Threshold, s.FP, s.FN, s.Precision, s.Recall, s.F1,
+			strings.Repeat("#", int(s.F1*40+0.5)))
+	}
+	best := eval.Best(scores)
+	fmt.Printf("F1 最高的阈值: %d（F1=%.4f）\n", best.Threshold, best.F1)
+
+	name := *out
+	if name == "" {
+		name = fmt.Sprintf("audio_dedup_eval_%s.csv", report.Stamp())
+	}
+	f, err := gzfile.Create(name)
+	if err != nil {
+		fmt.Printf("写曲线失败: %v\n", err)
+		return 2
+	}
+	err = eval.WriteCurve(f, scores)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fmt.Printf("写曲线失败: %v\n", err)
+		return 2
+	}
+	fmt.Printf("阈值-F1 曲线已生成: %s\n", name)
+	return 0
+}
+
+// lookupMatcher 按匹配器名与距离度量名构造 Matcher；非 hamming 度量只能配合默认匹配器
+func lookupMatcher(matcherName, metricName string, threshold int) (dedup.Matcher, error) {
+	if strings.EqualFold(metricName, "hamming") {
+		return dedup.LookupMatcher(matcherName, threshold)
+	}
+	if matcherName != "hamming" {
+		return nil, fmt.Errorf("-metric 只能与默认的 hamming 匹配器一起使用（当前 -matcher=%s）", matcherName)
+	}
+	m, err := dedup.LookupMetric(metricName)
+	if err != nil {
+		return nil, err
+	}
+	return dedup.MetricMatcher(m, threshold), nil
+}
+
+// parseThresholds 解析 "0-16" 或 "4,8,12"
+func parseThresholds(s string) ([]int, error) {
+	if lo, hi, ok := strings.Cut(s, "-"); ok {
+		a, err1 := strconv.Atoi(strings.TrimSpace(lo))
+		b, err2 := strconv.Atoi(strings.TrimSpace(hi))
+		if err1 != nil || err2 != nil || a < 0 || b < a || b > 64 {
+			return nil, fmt.Errorf("%q", s)
+		}
+		var out []int
+		for t := a; t <= b; t++ {
+			out = append(out, t)
+		}
+		return out, nil
+	}
+	var out []int
+	for _, f := range strings.Split(s, ",") {
+		t, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil || t < 0 || t > 64 {
+			return nil, fmt.Errorf("%q", s)
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
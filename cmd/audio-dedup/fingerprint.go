@@ -0,0 +1,468 @@
+// file: cmd/audio-dedup/fingerprint.go
+// package: main
+//
+// 指纹阶段：打开指纹缓存、阈值校准反馈与保留决定，由 worker 并发计算指纹（逐字节相同的文件复用代表文件的结果），
+// 收集结果到内存或溢出文件。收到中断信号、超出时间预算或失败文件超过 -max-errors 时不再发送新任务。
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"deduplicateMusic/internal/budget"
+	"deduplicateMusic/internal/cache"
+	"deduplicateMusic/internal/calibrate"
+	"deduplicateMusic/internal/checksum"
+	"deduplicateMusic/internal/dedup"
+	"deduplicateMusic/internal/errbudget"
+	"deduplicateMusic/internal/filestamp"
+	"deduplicateMusic/internal/fingerprint"
+	"deduplicateMusic/internal/hooks"
+	"deduplicateMusic/internal/mp3scan"
+	"deduplicateMusic/internal/report"
+	"deduplicateMusic/internal/source"
+	"deduplicateMusic/internal/spill"
+	"deduplicateMusic/internal/tags"
+	"deduplicateMusic/internal/tune"
+)
+
+// fpResult 为一个文件的指纹计算结果
+type fpResult struct {
+	meta dedup.FileMeta
+	err  error
+}
+
+// fingerprint 并发计算所有文件的指纹；提前停止时置 r.fingerprintStopped，之后不分组
+func (r *run) fingerprint() {
+	cfg := r.cfg
+	jobs := make(chan string)
+	results := make(chan fpResult)
+	var wg sync.WaitGroup
+
+	// 自动调优时启动上限数量的 worker，由 Limiter 控制实际同时解码的数量
+	poolSize := cfg.Workers
+	stopTuner := make(chan struct{})
+	if cfg.AutoTune {
+		maxWorkers := 2 * runtime.NumCPU()
+		if cfg.Workers > maxWorkers {
+			maxWorkers = cfg.Workers
+		}
+		r.limiter = tune.NewLimiter(cfg.Workers, 1, maxWorkers)
+		r.tuner = tune.NewTuner(r.limiter)
+		poolSize = maxWorkers
+		var tuneLog *slog.Logger
+		if cfg.Verbose {
+			tuneLog = slog.Default()
+		}
+		go r.tuner.Run(stopTuner, 3*time.Second, tuneLog)
+	}
+	r.openStores()
+
+	for i := 0; i < poolSize; i++ {
+		wg.Add(1)
+		go r.work(jobs, results, &wg)
+	}
+	decodeFiles := r.decodeFiles
+	if r.order != dedup.ProcessOrders[0] {
+		decodeFiles = append([]string(nil), decodeFiles...)
+		sort.SliceStable(decodeFiles, func(i, j int) bool {
+			a, b := r.entryOf[decodeFiles[i]], r.entryOf[decodeFiles[j]]
+			return dedup.OrderBefore(r.order, dedup.FileMeta{Path: a.Path, Size: a.Size, ModTime: a.ModTime},
+				dedup.FileMeta{Path: b.Path, Size: b.Size, ModTime: b.ModTime})
+		})
+	}
+	maxErrors, _ := errbudget.Parse(cfg.MaxErrors) // 已由 Validate 校验
+	r.failBudget = errbudget.New(maxErrors, len(r.files))
+	// -upgrade-dst：目标目录中尚无缓存指纹的文件（首次对大型目标目录运行时几乎是全部）先只交给少量后台 worker，
+	// 不必等整个目标目录算完才看到源目录的结果；源文件发送完后前台 worker 也从同一队列接手
+	srcFirst := decodeFiles
+	var dstQueue chan string
+	if cfg.UpgradeDst && cfg.DstScanWorkers > 0 {
+		var pending, others []string
+		for _, f := range decodeFiles {
+			e := r.entryOf[f]
+			if r.inDst[f] && (r.fpCache == nil || !r.fpCache.Has(f, e.Size, e.ModTime, r.fingerprintOptions(f).Signature())) {
+				pending = append(pending, f)
+			} else {
+				others = append(others, f)
+			}
+		}
+		if len(pending) > 0 {
+			srcFirst = others
+			dstQueue = make(chan string, len(pending))
+			for _, f := range pending {
+				dstQueue <- f
+			}
+			close(dstQueue)
+			bgJobs := make(chan string)
+			go func() {
+				for f := range dstQueue {
+					if r.stopReason() != "" || r.failBudget.Exceeded() {
+						break
+					}
+					bgJobs <- f
+				}
+				close(bgJobs)
+			}()
+			for i := 0; i < cfg.DstScanWorkers; i++ {
+				wg.Add(1)
+				go r.work(bgJobs, results, &wg)
+			}
+			slog.Info("目标目录中尚无缓存指纹的文件在后台计算，源文件优先", "files", len(pending), "workers", cfg.DstScanWorkers)
+		}
+	}
+
+	// 发送任务；收到中断信号、超出时间预算或失败文件超过 -max-errors 时不再发送；
+	// 超出预算时已在解码的文件照常完成，中断时正在运行的 ffmpeg 随 ctx 一起结束
+	r.bar.Stage("指纹", len(r.files))
+	go func() {
+		defer close(jobs)
+		for _, f := range srcFirst {
+			if r.stopped = r.stopReason(); r.stopped != "" || r.failBudget.Exceeded() {
+				return
+			}
+			jobs <- f
+		}
+		if dstQueue == nil {
+			return
+		}
+		for f := range dstQueue {
+			if r.stopped = r.stopReason(); r.stopped != "" || r.failBudget.Exceeded() {
+				return
+			}
+			jobs <- f
+		}
+	}()
+
+	// 收集结果：默认保存在内存；指定 -spill-dir 时写入磁盘溢出文件
+	if cfg.SpillDir != "" {
+		store, err := spill.Create(cfg.SpillDir)
+		if err != nil {
+			fatalf("创建溢出文件失败: %v", err)
+		}
+		if cfg.SpeedTolerant {
+			slog.Warn("溢出模式按原始指纹划分分量，-speed-tolerant 只在分量内生效，跨分量的变速匹配会漏掉")
+		}
+		atExit = append(atExit, func() { _ = store.Close() })
+		r.store = store
+	}
+	r.feedbackMeta = map[string]dedup.FileMeta{}
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		r.collect(results, dstQueue != nil)
+	}()
+
+	// 等待 worker 完成后关闭 results
+	wg.Wait()
+	close(results)
+	<-collected
+	close(stopTuner)
+	r.fingerprinted = time.Now()
+	if r.limiter != nil && cfg.Verbose {
+		slog.Info("自动调优结束", "workers", r.limiter.Limit())
+	}
+
+	if r.collectErr != nil {
+		slog.Warn("存在文件处理错误（见上方警告），请核对处理日志", "failed", r.errCount)
+	}
+	if r.failBudget.Exceeded() {
+		// 大量失败多半是整体问题（缺少解码器、挂载点掉线），按原因汇总后中止，不凭部分文件分组
+		slog.Error("计算指纹时失败的文件过多，提前中止，本次不分组、不复制", "processed", r.okCount+r.errCount, "total", len(r.files), "ok", r.okCount)
+		for _, c := range r.failBudget.Causes(5) {
+			slog.Error("失败原因", "count", c.Count, "reason", c.Reason, "example", c.Example)
+		}
+		fatalf("%s，已中止", r.failBudget.Summary())
+	}
+
+	// 指纹阶段提前停止时不做任何决策：只凭部分文件分组会把尚未计算指纹的更好版本当作不存在
+	if r.stopped == "" && r.ctx.Err() != nil {
+		r.stopped = "收到中断信号" // 任务已全部发出，但正在解码的文件被中断
+	}
+	r.fingerprintStopped = r.stopped != ""
+	if r.fingerprintStopped {
+		slog.Warn("停止计算指纹，本次不分组、不复制；已计算的指纹保存在缓存中", "reason", r.stopped, "processed", r.okCount+r.errCount, "total", len(r.files))
+	} else {
+		if r.okCount == 0 {
+			fatalf("没有成功计算任何文件的指纹")
+		}
+		r.stage = budget.StageProcess
+		r.timeBudget.Begin(r.stage)
+	}
+	// 结果按完成先后到达，排序后后续处理与 -workers 无关
+	sort.Slice(r.metas, func(i, j int) bool { return r.metas[i].Path < r.metas[j].Path })
+}
+
+// openStores 打开跨运行的指纹缓存（未改动的文件沿用上次的解码结果）、阈值校准反馈与保留决定；
+// 打不开时只记录警告，本次不使用
+func (r *run) openStores() {
+	cfg := r.cfg
+	var err error
+	if !cfg.NoCache && cfg.Cache != "" {
+		if r.fpCache, err = cache.OpenStore(cfg.Cache); err != nil {
+			warnf("无法打开指纹缓存，本次不使用缓存: %v", err)
+			r.fpCache = nil
+		} else {
+			fpCache := r.fpCache
+			atExit = append(atExit, func() {
+				if err := fpCache.Close(); err != nil {
+					warnf("保存指纹缓存失败: %v", err)
+				}
+			})
+		}
+	}
+	if cfg.Feedback != "" {
+		if r.feedback, err = calibrate.Open(cfg.Feedback); err != nil {
+			warnf("无法读取阈值校准反馈，本次不记录: %v", err)
+			r.feedback = nil
+		} else {
+			feedback := r.feedback
+			atExit = append(atExit, func() {
+				if err := feedback.Close(); err != nil {
+					warnf("保存阈值校准反馈失败: %v", err)
+				}
+			})
+		}
+	}
+	r.feedbackProfile = fmt.Sprintf("matcher=%s metric=%s seconds=%d segments=%d speed=%v", cfg.Matcher, cfg.Metric, cfg.Seconds, cfg.Segments, cfg.SpeedTolerant)
+	if cfg.Decisions != "" {
+		if r.decisions, err = cache.OpenDecisions(cfg.Decisions); err != nil {
+			warnf("无法打开保留决定文件，本次不沿用也不记录决定: %v", err)
+			r.decisions = nil
+		} else {
+			decisions := r.decisions
+			atExit = append(atExit, func() {
+				if err := decisions.Close(); err != nil {
+					warnf("保存保留决定失败: %v", err)
+				}
+			})
+		}
+	}
+}
+
+// fingerprintOptions 返回文件的指纹参数（抽查时用同样的参数重新计算）
+func (r *run) fingerprintOptions(p string) fingerprint.Options {
+	cfg := r.cfg
+	opt := fingerprint.Options{Seconds: cfg.Seconds, Bits: 64, Envelope: cfg.Thumbnails != "", Blocks: strings.EqualFold(cfg.Metric, "cosine")} // 64-bit 指纹
+	if cfg.Segments > 1 {
+		opt.Segments = cfg.Segments
+	}
+	if cfg.SpeedTolerant {
+		opt.SpeedFactors = fingerprint.DefaultSpeedFactors
+	}
+	if source.IsLocalPath(p) && cfg.Gapless && strings.EqualFold(filepath.Ext(p), ".mp3") {
+		if g, gerr := mp3scan.ReadGapless(p); gerr == nil {
+			opt.Skip = g.ExtraLeading()
+		}
+	}
+	return opt
+}
+
+// work 从 jobs 取文件计算指纹，结果（以及内容相同的 followers 成员）写入 results
+func (r *run) work(jobs <-chan string, results chan<- fpResult, wg *sync.WaitGroup) {
+	defer wg.Done()
+	for p := range jobs {
+		res := r.analyze(p)
+		results <- res
+		for _, f := range r.followers[p] {
+			// 内容与 p 完全相同：分析结果与标签照搬，只替换文件自身的属性
+			fr := res
+			fr.meta.Path, fr.meta.Size, fr.meta.ModTime = f, r.entryOf[f].Size, r.entryOf[f].ModTime
+			fr.meta.Stamp = filestamp.Stamp{}
+			if r.cfg.DetectChanges {
+				fr.meta.Stamp, _ = filestamp.Take(f)
+			}
+			fr.meta.SHA256 = r.exactRes.Hash[f]
+			results <- fr
+		}
+	}
+}
+
+// analyze 计算单个文件的指纹：未改动且缓存中有的沿用缓存，远程文件边下载边解码；
+// 本地文件另读取时长、标签以及 -scan-mp3 / -verify-flac 的检查结果
+func (r *run) analyze(p string) fpResult {
+	cfg := r.cfg
+	var an fingerprint.Analysis
+	var size int64
+	var err error
+	opt := r.fingerprintOptions(p)
+	var stamp filestamp.Stamp
+	var modTime time.Time
+	cached := false
+	if source.IsLocalPath(p) {
+		if cfg.DetectChanges {
+			stamp, _ = filestamp.Take(p) // 解码前记录，解码期间的改动也能被发现
+		}
+		if e, ok := r.entryOf[p]; ok && !e.ModTime.IsZero() {
+			size, modTime = e.Size, e.ModTime
+		} else {
+			var info os.FileInfo
+			if info, err = os.Stat(p); err == nil {
+				size, modTime = info.Size(), info.ModTime()
+			}
+		}
+		if err == nil && r.fpCache != nil {
+			an, cached = r.fpCache.Get(p, size, modTime, opt.Signature())
+		}
+		if err == nil && !cached {
+			// 先确认可读：没有读取权限的文件交给 ffmpeg 只会得到含糊的解码错误
+			var f *os.File
+			if f, err = os.Open(p); err == nil {
+				f.Close()
+			}
+		}
+	}
+	if !cached && err == nil {
+		if r.limiter != nil {
+			r.limiter.Acquire()
+		}
+		r.governor.Acquire()
+		if source.IsLocalPath(p) {
+			an, err = fingerprint.AnalyzeFileContext(r.ctx, p, opt)
+			if err == nil && r.fpCache != nil {
+				if perr := r.fpCache.Put(p, size, modTime, opt.Signature(), an); perr != nil {
+					warnf("写入指纹缓存失败: %v", perr)
+				}
+			}
+		} else {
+			// 远程源：边下载边通过 stdin 送入 ffmpeg，不落临时文件
+			var rc io.ReadCloser
+			if rc, err = r.src.Open(p); err == nil {
+				an, err = fingerprint.AnalyzeReaderContext(r.ctx, rc, opt)
+				rc.Close()
+			}
+		}
+		r.governor.Release()
+		if r.limiter != nil {
+			r.limiter.Release()
+			r.tuner.Done()
+		}
+	}
+	if size == 0 {
+		size = r.entryOf[p].Size // 远程源无法 stat，使用列举时得到的大小
+	}
+	res := fpResult{meta: dedup.FileMeta{Path: p, Size: size, FP: an.FP, Envelope: an.Envelope, AltFPs: an.AltFPs, Blocks: an.Blocks, Segments: an.Segments, Stamp: stamp,
+		ModTime: r.entryOf[p].ModTime}, err: err}
+	if err == nil && source.IsLocalPath(p) {
+		if r.needDuration {
+			res.meta.Duration, _ = fingerprint.ProbeDuration(p) // 读不到时为 0，按最差处理
+		}
+		// 标签读取失败不影响去重，仅缺少统计信息
+		res.meta.Tags, _ = tags.ReadFile(p)
+		if cfg.ScanMP3 && strings.EqualFold(filepath.Ext(p), ".mp3") {
+			if st, serr := mp3scan.ScanFile(p); serr == nil {
+				res.meta.StreamErrors = st.Errors()
+				if st.Errors() > 0 && cfg.Verbose {
+					slog.Warn("MP3 帧扫描发现损坏", "path", p, "bad_spots", st.BadSpots, "truncated", st.Truncated)
+				}
+			}
+		}
+		if cfg.VerifyFLAC && strings.EqualFold(filepath.Ext(p), ".flac") {
+			r.governor.Acquire()
+			status, verr := checksum.VerifyFLAC(p)
+			r.governor.Release()
+			if verr != nil {
+				slog.Warn("FLAC 校验出错", "path", p, "err", verr)
+			} else if status == checksum.FLACCorrupt {
+				slog.Warn("FLAC 校验失败（可能已损坏）", "path", p)
+			}
+			res.meta.Integrity = status
+		}
+	}
+	res.meta.SHA256 = r.exactRes.Hash[p]
+	return res
+}
+
+// collect 收集 worker 的结果直到 results 关闭。background 为 true 表示目标目录在后台计算：
+// 源文件全部完成而目标目录尚未完成时，先输出源目录内部的比对结果
+func (r *run) collect(results <-chan fpResult, background bool) {
+	srcLeft, dstLeft := len(r.files)-len(r.inDst), len(r.inDst)
+	for res := range results {
+		r.bar.Add(1)
+		if res.err != nil && r.ctx.Err() != nil {
+			continue // 被中断的解码不算失败，下次运行重新计算
+		}
+		if background {
+			if r.inDst[res.meta.Path] {
+				dstLeft--
+			} else if srcLeft--; srcLeft == 0 && dstLeft > 0 && r.store == nil {
+				r.reportSourceOnly(res, dstLeft)
+			}
+		}
+		if res.err != nil {
+			r.failedItems = append(r.failedItems, report.ReportItem{FilePath: res.meta.Path, Size: r.entryOf[res.meta.Path].Size,
+				Action: report.ActionFailed, Error: res.err.Error()})
+		}
+		if errors.Is(res.err, fs.ErrPermission) {
+			slog.Warn("跳过无读取权限的文件", "path", res.meta.Path, "err", res.err)
+			r.skipped = append(r.skipped, report.NewSkipped(res.meta.Path, report.SkipStageFingerprint, res.err))
+			r.fireHook(hooks.Event{Event: hooks.EventError, Path: res.meta.Path, Error: res.err.Error()})
+			continue
+		}
+		if res.err != nil {
+			// 记录第一个错误并继续（不希望单文件失败就中断整个流程）
+			if r.collectErr == nil {
+				r.collectErr = res.err
+			}
+			r.errCount++
+			r.failBudget.Fail(res.meta.Path, res.err)
+			slog.Warn("处理文件失败", "path", res.meta.Path, "err", res.err)
+			r.fireHook(hooks.Event{Event: hooks.EventError, Path: res.meta.Path, Error: res.err.Error()})
+			continue
+		}
+		if r.store != nil {
+			if _, err := r.store.Append(res.meta); err != nil {
+				slog.Warn("写入溢出文件失败", "path", res.meta.Path, "err", err)
+				continue
+			}
+		} else {
+			r.metas = append(r.metas, res.meta)
+		}
+		r.albumIndex.Add(res.meta)
+		r.okCount++
+		if len(r.feedbackPaths) > 0 {
+			if abs, err := filepath.Abs(res.meta.Path); err == nil && r.feedbackPaths[abs] {
+				r.feedbackMeta[abs] = res.meta
+			}
+		}
+		if r.cfg.Verbose {
+			slog.Info("指纹计算完成", "path", res.meta.Path, "size", res.meta.Size, "fp", fmt.Sprintf("%016x", res.meta.FP))
+		}
+	}
+}
+
+// reportSourceOnly 输出源目录内部的比对结果（last 尚未加入 r.metas），dstLeft 为目标目录中尚未完成的文件数
+func (r *run) reportSourceOnly(last fpResult, dstLeft int) {
+	var src []dedup.FileMeta
+	for _, m := range r.metas {
+		if !r.inDst[m.Path] {
+			src = append(src, m)
+		}
+	}
+	if last.err == nil {
+		src = append(src, last.meta)
+	}
+	groups, dups, bytes := 0, 0, int64(0)
+	for _, g := range dedup.GroupWith(src, dedup.Options{Threshold: r.cfg.Threshold, Matcher: r.matcher}) {
+		if len(g.Duplicates) == 0 {
+			continue
+		}
+		groups++
+		for _, d := range g.Duplicates {
+			dups++
+			bytes += d.Size
+		}
+	}
+	slog.Info("源目录内部比对完成，目标目录的指纹计算完成后合并比对",
+		"groups", groups, "duplicates", dups, "size", report.HumanBytes(bytes), "dst_pending", dstLeft)
+}
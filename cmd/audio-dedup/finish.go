@@ -0,0 +1,287 @@
+// file: cmd/audio-dedup/finish.go
+// package: main
+//
+// 收尾：输出运行统计、抽查与各类报告（摘要、翻唱参考、目录重复、试听片段索引、同步计划、删除清单等），
+// 写校验文件、关闭去重报告、生成审计记录与 JSON 摘要。提前停止时写出检查点并以退出码 3 结束。
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"deduplicateMusic/internal/audit"
+	"deduplicateMusic/internal/budget"
+	"deduplicateMusic/internal/calibrate"
+	"deduplicateMusic/internal/checksum"
+	"deduplicateMusic/internal/fingerprint"
+	"deduplicateMusic/internal/musiclib"
+	"deduplicateMusic/internal/preview"
+	"deduplicateMusic/internal/report"
+	"deduplicateMusic/internal/source"
+	"deduplicateMusic/internal/spotcheck"
+	"deduplicateMusic/internal/syncplan"
+)
+
+// finish 输出统计与报告；提前停止时写出检查点后以退出码 3 退出
+func (p *processor) finish() {
+	cfg := p.cfg
+	p.printStats()
+	if cfg.SpotCheck > 0 {
+		p.spotCheck()
+	}
+
+	// 重复统计（按艺术家 / 专辑）
+	summary := p.summaryBuilder.Summary()
+	if p.feedback != nil {
+		if advice, ok := calibrate.Recommend(p.feedback.Samples(p.feedbackProfile), cfg.Threshold); ok {
+			summary.Advice = append(summary.Advice, advice.String())
+		}
+	}
+	summary.WriteText(os.Stdout, cfg.Top)
+	if cfg.Covers {
+		for i, c := range p.coverCandidates {
+			if to, ok := p.movedTo[c]; ok {
+				p.coverCandidates[i] = to
+			}
+		}
+		pairs := findCovers(p.coverCandidates, cfg.CoverSeconds, cfg.CoverThreshold, cfg.Workers)
+		report.WriteCoverText(os.Stdout, pairs, cfg.Top)
+		if name, err := report.WriteCoverReport(pairs); err != nil {
+			fmt.Printf("生成翻唱参考报告失败: %v\n", err)
+		} else {
+			fmt.Printf("翻唱参考报告已生成: %s\n", name)
+		}
+	}
+	if cfg.Folders {
+		folders := p.folderBuilder.Folders()
+		report.WriteFolderText(os.Stdout, folders, cfg.Top)
+		if name, err := report.WriteFolderReport(folders); err != nil {
+			fmt.Printf("生成目录重复报告失败: %v\n", err)
+		} else {
+			fmt.Printf("目录重复报告已生成: %s\n", name)
+		}
+	}
+	summaryPath, err := report.WriteSummaryReport(summary)
+	if err != nil {
+		fmt.Printf("生成摘要失败: %v\n", err)
+	} else {
+		fmt.Printf("去重摘要已生成: %s\n", summaryPath)
+	}
+	p.writeOutputs(summary)
+
+	// 处理完成后生成 CSV
+	reportFile, err := p.reportW.Close()
+	if err != nil {
+		fmt.Printf("生成报告失败: %v\n", err)
+	} else if names := p.reportW.Names(); len(names) > 0 && reportFile != report.StdoutPath {
+		fmt.Printf("去重报告已生成: %s\n", strings.Join(names, ", "))
+	}
+	if reportFile == report.StdoutPath {
+		reportFile = "" // 写到标准输出的报告没有可供审计记录哈希的文件
+	}
+
+	if cfg.Audit {
+		p.audit(reportFile)
+	}
+	if cfg.SummaryJSON {
+		p.writeSummaryJSON(summary, reportFile, summaryPath)
+	}
+
+	if p.stopped == "" {
+		if err := os.Remove(p.checkpointPath); err != nil && !os.IsNotExist(err) {
+			warnf("删除旧检查点失败: %v", err)
+		}
+		if err := p.journal.Remove(); err != nil {
+			warnf("删除处理日志失败: %v", err)
+		}
+		return
+	}
+	cp := budget.Checkpoint{Stage: p.stage, Reason: p.stopped, Started: p.start, Stopped: time.Now(),
+		Files: len(p.files), Fingerprinted: p.okCount, GroupsDone: p.groupCount, Processed: p.processed, Report: reportFile}
+	if err := budget.WriteCheckpoint(p.checkpointPath, cp); err != nil {
+		warnf("写检查点失败: %v", err)
+	} else {
+		fmt.Printf("检查点已写出: %s\n", p.checkpointPath)
+	}
+	runAtExit()
+	os.Exit(3)
+}
+
+// printStats 输出运行统计与需要注意的情况
+func (p *processor) printStats() {
+	cfg := p.cfg
+	fmt.Printf("完成：源文件 %d，处理成功 %d，保留并%s %d，耗时 %s\n", len(p.files), p.okCount, modeVerb(p.mode), p.keepCount, time.Since(p.start))
+	if p.fpCache != nil {
+		hits, misses := p.fpCache.Stats()
+		fmt.Printf("指纹缓存：命中 %d，重新解码 %d（%s）\n", hits, misses, cfg.Cache)
+	}
+	if p.resumedCount > 0 {
+		fmt.Printf("断点续跑：%d 个分组沿用上次运行的处理结果\n", p.resumedCount)
+	}
+	if p.decisions != nil {
+		if applied, _ := p.decisions.Stats(); applied > 0 {
+			fmt.Printf("保留决定：%d 组沿用上次选出的保留文件（-recompute-decisions 可重新选择）\n", applied)
+		}
+	}
+	if p.changedCount > 0 {
+		fmt.Printf("注意：%d 个文件在决策后被改动，已跳过（报告中标为 %s）\n", p.changedCount, report.ActionChanged)
+	}
+	if p.collisionCount > 0 {
+		fmt.Printf("目标文件名冲突：%d 个文件按 -on-collision=%s 处理（报告中标为 %s）\n", p.collisionCount, p.onCollision, p.collisionAction)
+	}
+	if p.keeperGoneCount > 0 {
+		fmt.Printf("注意：%d 个重复文件的保留文件在移除前已不存在或被改动，未移除（报告中标为 %s）\n", p.keeperGoneCount, report.ActionKeeperChanged)
+	}
+	if p.remover != nil {
+		fmt.Printf("原地去重：移除重复文件 %d 个（%s，%s）\n", p.removedCount, p.remover.Method(), report.HumanBytes(p.removedBytes))
+		if b := p.remover.Batch(); b != "" {
+			fmt.Printf("隔离批次: %s（可用 -undo-quarantine %s 还原）\n", b, b)
+		}
+	}
+	if cfg.MergeLyrics {
+		fmt.Printf("从重复文件合并歌词 %d 首\n", p.lyricsMerged)
+	}
+	if cfg.UpgradeDst {
+		fmt.Printf("目标目录：%d 个文件被更高质量的新副本替换（旧文件备份在 %s）\n", p.upgradeCount, p.backupRoot)
+	}
+	if p.collectErr != nil {
+		fmt.Println("注意：存在单文件处理错误，请查看日志。")
+	}
+	if len(p.skipped) > 0 {
+		report.WriteSkippedText(os.Stdout, p.skipped, cfg.Top, retryHint())
+		if name, err := report.WriteSkippedReport(p.skipped); err != nil {
+			fmt.Printf("生成跳过文件报告失败: %v\n", err)
+		} else {
+			fmt.Printf("跳过文件报告已生成: %s\n", name)
+		}
+	}
+	if p.stopped != "" {
+		fmt.Printf("注意：%s，在 %s 阶段提前停止（已处理 %d 个分组），报告只包含已完成的部分\n", p.stopped, p.stage, p.groupCount)
+	}
+}
+
+// spotCheck 重新计算抽样的保留 / 重复文件对的指纹（-spot-check）并输出抽查报告
+func (p *processor) spotCheck() {
+	results := spotcheck.Check(p.sampler.Pairs(), func(path string) (uint64, error) {
+		o := p.fingerprintOptions(path)
+		o.Segments = 0 // 抽查只比较整体指纹
+		an, err := fingerprint.AnalyzeFile(path, o)
+		return an.FP, err
+	})
+	report.WriteSpotCheckText(os.Stdout, results)
+	if name, err := report.WriteSpotCheckReport(results); err != nil {
+		fmt.Printf("生成抽查报告失败: %v\n", err)
+	} else {
+		fmt.Printf("抽查报告已生成: %s\n", name)
+	}
+}
+
+// writeOutputs 写出按参数开启的其余输出：试听片段索引、同步计划、设备删除清单、去重计划、
+// 频谱差异图与缩略图统计、磁盘占用文件，以及目标目录的校验文件
+func (p *processor) writeOutputs(summary report.Summary) {
+	cfg := p.cfg
+	if cfg.Previews != "" {
+		if err := preview.WriteIndex(cfg.Previews, p.clips); err != nil {
+			fmt.Printf("写试听片段索引失败: %v\n", err)
+		} else {
+			fmt.Printf("已生成 %d 个试听片段: %s\n", len(p.clips), cfg.Previews)
+		}
+	}
+
+	if cfg.SyncPlan != "" {
+		if err := syncplan.Write(cfg.SyncPlan, p.syncItems); err != nil {
+			fmt.Printf("写同步计划失败: %v\n", err)
+		} else {
+			fmt.Printf("同步计划已生成（%d 个曲目）: %s\n", len(p.syncItems), cfg.SyncPlan)
+		}
+	}
+	if cfg.DeviceRemoveList != "" {
+		if err := writeDeviceRemovals(cfg.DeviceRemoveList, p.deviceRemovals); err != nil {
+			fmt.Printf("写设备删除清单失败: %v\n", err)
+		} else {
+			fmt.Printf("设备上可删除 %d 个重复文件，清单: %s\n", len(p.deviceRemovals), cfg.DeviceRemoveList)
+		}
+	}
+	if cfg.MusicPlan != "" {
+		if err := musiclib.WritePlan(cfg.MusicPlan, p.planEntries); err != nil {
+			fmt.Printf("写去重计划失败: %v\n", err)
+		} else {
+			fmt.Printf("去重计划已生成（%d 个待删除文件）: %s\n", len(p.planEntries), cfg.MusicPlan)
+		}
+	}
+	if cfg.SpectroDiff != "" {
+		fmt.Printf("已生成 %d 个频谱差异图: %s\n", p.spectroCount, cfg.SpectroDiff)
+	}
+	if cfg.Thumbnails != "" {
+		fmt.Printf("已生成 %d 个波形缩略图: %s\n", p.thumbCount, cfg.Thumbnails)
+	}
+
+	if cfg.DuOut != "" {
+		if err := report.WriteDiskUsageFile(cfg.DuOut, cfg.Src, summary.Reclaimable, cfg.DuFormat); err != nil {
+			fmt.Printf("生成磁盘占用文件失败: %v\n", err)
+		} else {
+			fmt.Printf("重复空间磁盘占用已生成: %s\n", cfg.DuOut)
+		}
+	}
+
+	// 为目标目录逐目录写出校验文件（SHA256SUMS，含 FLAC 的目录另有 fingerprints.ffp）
+	if cfg.Checksums && !p.managedSafe {
+		if n, err := checksum.Write(cfg.Dst, true); err != nil {
+			fmt.Printf("写校验文件失败: %v\n", err)
+		} else {
+			fmt.Printf("已为 %d 个目录写出校验文件（%s / %s）\n", n, checksum.SumsFile, checksum.FFPFile)
+		}
+	}
+}
+
+// audit 生成审计记录：本地输入（已移除的除外）、放入目标目录的文件与报告的摘要
+func (p *processor) audit(reportFile string) {
+	cfg := p.cfg
+	rec := &audit.Record{
+		Tool:      "audio-dedup",
+		StartedAt: p.start,
+		Args:      os.Args[1:],
+		Src:       cfg.Src,
+		Dst:       cfg.Dst,
+	}
+	var inputs []string
+	for _, f := range p.files {
+		if source.IsLocalPath(f) && !p.removed[f] {
+			inputs = append(inputs, f)
+		}
+	}
+	if len(inputs) < len(p.files) {
+		slog.Warn("远程源的输入文件不计算摘要，审计记录只包含本地输入、输出与报告")
+	}
+	if err := writeAuditRecord(rec, inputs, p.copied, reportFile, p.signKey); err != nil {
+		fmt.Printf("生成审计记录失败: %v\n", err)
+	}
+}
+
+// writeSummaryJSON 向原 stdout 输出 JSON 格式的运行摘要（-summary-json）
+func (p *processor) writeSummaryJSON(summary report.Summary, reportFile, summaryPath string) {
+	rs := report.RunSummary{
+		Files: len(p.files), Fingerprinted: p.okCount, Errors: p.errCount,
+		Groups: p.groupCount, Kept: p.keepCount, Duplicates: len(summary.Reclaimable),
+		Copied: p.copiedCount, Upgraded: p.upgradeCount, Changed: p.changedCount, Removed: p.removedCount,
+		Skipped: len(p.skipped), SkippedPermission: report.CountPermission(p.skipped),
+		KeptBytes: p.keptBytes, CopiedBytes: p.copiedBytes, RemovedBytes: p.removedBytes,
+		Report: reportFile, Summary: summaryPath, Stopped: p.stopped,
+		Started:            p.start,
+		ElapsedSeconds:     time.Since(p.start).Seconds(),
+		FingerprintSeconds: p.fingerprinted.Sub(p.start).Seconds(),
+		ProcessSeconds:     time.Since(p.fingerprinted).Seconds(),
+	}
+	for _, r := range summary.Reclaimable {
+		rs.DuplicateBytes += r.Size
+	}
+	if p.fpCache != nil {
+		rs.CacheHits, rs.CacheMisses = p.fpCache.Stats()
+	}
+	if err := rs.WriteJSON(p.jsonOut); err != nil {
+		warnf("输出 JSON 摘要失败: %v", err)
+	}
+}
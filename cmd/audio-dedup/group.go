@@ -0,0 +1,135 @@
+// file: cmd/audio-dedup/group.go
+// package: main
+//
+// 分组参数：在保留策略上依次叠加 MP3 帧错误、FLAC 校验、设备 / 目标目录 / 参考库的优先规则，
+// 最后应用人工覆盖；并把本次运行中出现的误判 / 确认对记入阈值校准反馈。
+package main
+
+import (
+	"log/slog"
+
+	"deduplicateMusic/internal/calibrate"
+	"deduplicateMusic/internal/checksum"
+	"deduplicateMusic/internal/dedup"
+	"deduplicateMusic/internal/review"
+)
+
+// groupOptions 设置 r.opts（基于汉明距离 + union-find 组建分组）
+func (r *run) groupOptions() {
+	cfg := r.cfg
+	opts := dedup.Options{Threshold: cfg.Threshold, Policy: r.policy, Matcher: r.matcher, ShardBits: cfg.ShardBits, Exhaustive: cfg.Exhaustive, TreeMinFiles: cfg.BKTreeMinFiles}
+	if r.groupBy != dedup.GroupByFingerprint {
+		opts.Partition = dedup.TagKey
+	}
+	if !r.ruleSet.Empty() {
+		opts.Protect = r.ruleSet.Protected
+	}
+	if cfg.DurationTolerance > 0 {
+		apart := dedup.DurationApart(cfg.DurationTolerance)
+		opts.Apart = func(a, b dedup.FileMeta) bool {
+			if !apart(a, b) {
+				return false
+			}
+			slog.Debug("时长相差超过容差，不合并", "a", a.Path, "a_duration", a.Duration, "b", b.Path, "b_duration", b.Duration)
+			return true
+		}
+	}
+	if cfg.ScanMP3 {
+		// 帧错误更少的文件优先，避免体积更大但已截断/损坏的副本被保留
+		base := opts.Policy
+		opts.Policy = dedup.KeepPolicyFunc(func(a, b dedup.FileMeta) bool {
+			if a.StreamErrors != b.StreamErrors {
+				return a.StreamErrors < b.StreamErrors
+			}
+			return base.Better(a, b)
+		})
+	}
+	if cfg.VerifyFLAC {
+		// 校验失败的 FLAC 排在所有其他文件之后，仅当组内只剩损坏文件时才保留
+		base := opts.Policy
+		opts.Policy = dedup.KeepPolicyFunc(func(a, b dedup.FileMeta) bool {
+			ca, cb := a.Integrity == checksum.FLACCorrupt, b.Integrity == checksum.FLACCorrupt
+			if ca != cb {
+				return cb
+			}
+			return base.Better(a, b)
+		})
+	}
+	if onDevice := r.onDevice; len(onDevice) > 0 {
+		// 设备与主库重复时总是保留主库中的文件，设备上的副本进入删除清单
+		base := opts.Policy
+		opts.Policy = dedup.KeepPolicyFunc(func(a, b dedup.FileMeta) bool {
+			if onDevice[a.Path] != onDevice[b.Path] {
+				return !onDevice[a.Path]
+			}
+			return base.Better(a, b)
+		})
+	}
+	if inDst := r.inDst; len(inDst) > 0 {
+		// 只有严格更好的新副本才替换目标目录中的文件，同等质量时保留已有文件
+		base := opts.Policy
+		opts.Policy = dedup.KeepPolicyFunc(func(a, b dedup.FileMeta) bool {
+			if inDst[a.Path] != inDst[b.Path] {
+				if inDst[a.Path] {
+					return !base.Better(b, a) || base.Better(a, b)
+				}
+				return base.Better(a, b) && !base.Better(b, a)
+			}
+			return base.Better(a, b)
+		})
+	}
+	if inRef := r.inRef; len(inRef) > 0 {
+		// 与参考资料库重复时总是保留参考库中的文件，源目录中的副本不再放入目标目录
+		base := opts.Policy
+		opts.Policy = dedup.KeepPolicyFunc(func(a, b dedup.FileMeta) bool {
+			if inRef[a.Path] != inRef[b.Path] {
+				return inRef[a.Path]
+			}
+			return base.Better(a, b)
+		})
+	}
+	// 人工覆盖最后应用，优先于以上所有策略调整
+	r.pinned.Apply(&opts)
+	r.opts = opts
+	r.recordFeedback()
+}
+
+// recordFeedback 把本次运行中两个文件都在的误判 / 确认对，按当前配置重新计算距离后记入反馈
+func (r *run) recordFeedback() {
+	if r.feedback == nil {
+		return
+	}
+	record := func(pairs [][2]string, verdict string) {
+		for _, pair := range pairs {
+			a, okA := r.feedbackMeta[pair[0]]
+			b, okB := r.feedbackMeta[pair[1]]
+			if okA && okB {
+				r.feedback.Record(r.feedbackProfile, pair[0], pair[1], r.opts.Distance(a, b), verdict)
+			}
+		}
+	}
+	record(r.pinned.ApartPairs(), calibrate.Rejected)
+	record(r.pinned.Confirmed, calibrate.Accepted)
+}
+
+// refOnly 返回分组是否只含参考库中的文件：与源目录无关，不处理也不写入报告
+func (r *run) refOnly(g dedup.Group) bool {
+	for _, m := range review.Members(g) {
+		if !r.inRef[m.Path] {
+			return false
+		}
+	}
+	return true
+}
+
+// dropRefOnly 去掉只含参考库文件的分组，并重新编号
+func (r *run) dropRefOnly(groups []dedup.Group) []dedup.Group {
+	out := groups[:0]
+	for _, g := range groups {
+		if !r.refOnly(g) {
+			g.ID = len(out) + 1
+			out = append(out, g)
+		}
+	}
+	return out
+}
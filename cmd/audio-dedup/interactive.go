@@ -0,0 +1,155 @@
+// file: cmd/audio-dedup/interactive.go
+// package: main
+//
+// 处理前的交互：逐组审核（-review）与破坏性操作前的确认。两者都在终端上进行，
+// 用户放弃或拒绝时不做任何改动直接退出。
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"deduplicateMusic/internal/dedup"
+	"deduplicateMusic/internal/overrides"
+	"deduplicateMusic/internal/removal"
+	"deduplicateMusic/internal/report"
+	"deduplicateMusic/internal/review"
+	"deduplicateMusic/internal/source"
+)
+
+// reviewGroups 逐组交互审核（-review）：改选的保留文件记入保留决定，排除的组拆成单个文件并写入误判清单
+func (r *run) reviewGroups(groups []dedup.Group) []dedup.Group {
+	cfg := r.cfg
+	pending := 0
+	for _, g := range groups {
+		if len(g.Duplicates) > 0 {
+			pending++
+		}
+	}
+	if pending == 0 {
+		return groups
+	}
+	sess := review.New(os.Stdin, os.Stderr, pending)
+	out := make([]dedup.Group, 0, len(groups))
+	auto := false
+	changedKeep, excluded := 0, 0
+	for _, g := range groups {
+		if auto || len(g.Duplicates) == 0 {
+			out = append(out, g)
+			continue
+		}
+		// 先按已记录的决定显示，与随后处理时一致
+		sig := r.signature(g)
+		if r.decisions != nil && !cfg.RecomputeDecisions {
+			if keep, ok := r.decisions.Keeper(sig); ok {
+				dedup.Promote(&g, keep, r.opts)
+			}
+		}
+		res, err := sess.Group(g)
+		if err != nil {
+			fatalf("读取审核输入失败: %v", err)
+		}
+		switch res.Action {
+		case review.Quit:
+			fmt.Fprintln(os.Stderr, "已放弃审核，本次没有复制或移除任何文件")
+			runAtExit()
+			os.Exit(0)
+		case review.AcceptAll:
+			auto = true
+		case review.Exclude:
+			excluded++
+			members := review.Members(g)
+			if cfg.NeverMatch != "" {
+				paths := make([]string, len(members))
+				for i, m := range members {
+					paths[i] = m.Path
+				}
+				if err := overrides.AppendRow(cfg.NeverMatch, paths); err != nil {
+					warnf("写入误判清单失败，该组只在本次排除: %v", err)
+				}
+			}
+			for _, m := range members {
+				out = append(out, dedup.Group{Keep: m})
+			}
+			continue
+		}
+		if res.Keep != "" {
+			if !dedup.Promote(&g, res.Keep, r.opts) {
+				warnf("无法改为保留 %s（当前保留文件受保护）", res.Keep)
+			} else {
+				changedKeep++
+				if r.decisions != nil {
+					if err := r.decisions.Record(sig, g.Keep.Path); err != nil {
+						warnf("记录保留决定失败: %v", err)
+					}
+				}
+			}
+		}
+		out = append(out, g)
+	}
+	for i := range out {
+		out[i].ID = i + 1
+	}
+	fmt.Fprintf(os.Stderr, "审核完成：改选保留文件 %d 组，排除 %d 组\n", changedKeep, excluded)
+	return out
+}
+
+// confirmRemoval 按分组结果汇总将移除（或移出源目录）的文件并等待确认；拒绝时不做任何改动直接退出
+func (r *run) confirmRemoval(groups []dedup.Group) {
+	cfg := r.cfg
+	n, size := 0, int64(0)
+	for _, g := range groups {
+		if len(g.Duplicates) == 0 {
+			continue
+		}
+		sig := r.signature(g)
+		var prev json.RawMessage
+		if r.journal.Done(sig, &prev) {
+			continue // 续跑时沿用上次的结果，不再移动或移除
+		}
+		if r.decisions != nil && !cfg.RecomputeDecisions {
+			if keep, ok := r.decisions.Keeper(sig); ok {
+				dedup.Promote(&g, keep, r.opts)
+			}
+		}
+		if cfg.InPlace {
+			for _, d := range g.Duplicates {
+				if source.IsLocalPath(d.Path) && !r.inRef[d.Path] {
+					n++
+					size += d.Size
+				}
+			}
+			continue
+		}
+		for _, m := range append([]dedup.FileMeta{g.Keep}, g.Protected...) {
+			if source.IsLocalPath(m.Path) && !r.inDst[m.Path] && !r.inRef[m.Path] {
+				n++
+				size += m.Size
+			}
+		}
+	}
+	if n == 0 {
+		return
+	}
+	var question string
+	switch {
+	case !cfg.InPlace:
+		question = fmt.Sprintf("将把 %d 个保留文件（%s）从源目录移动到 %s，继续？", n, report.HumanBytes(size), cfg.Dst)
+	case r.removeMethod == removal.MethodDelete:
+		question = fmt.Sprintf("将删除 %d 个重复文件（无法撤销），释放 %s，继续？", n, report.HumanBytes(size))
+	case r.removeMethod == removal.MethodTrash:
+		question = fmt.Sprintf("将把 %d 个重复文件移入回收站，释放 %s，继续？", n, report.HumanBytes(size))
+	default:
+		question = fmt.Sprintf("将把 %d 个重复文件移入隔离目录 %s，释放 %s，继续？", n, cfg.Quarantine, report.HumanBytes(size))
+	}
+	ok, err := review.Confirm(os.Stdin, os.Stderr, question)
+	if err != nil {
+		fatalf("读取确认输入失败: %v", err)
+	}
+	if !ok {
+		fmt.Fprintln(os.Stderr, "已取消，本次没有复制或移除任何文件")
+		runAtExit()
+		os.Exit(0)
+	}
+}
@@ -2,157 +2,455 @@
 // package: main
 //
 // 命令行入口，解析参数，扫描源目录，计算指纹并去重，最后把保留的文件复制到目标目录。
+// 各阶段见 run.go（准备）、scan.go、fingerprint.go、group.go、process.go 与 finish.go。
 // 运行示例（在项目根目录下）：
 //
 //	go run ./cmd/audio-dedup -src /path/to/src -dst /path/to/dst -workers 4 -threshold 8
 package main
 
 import (
+	"context"
+	"deduplicateMusic/internal/audit"
+	"deduplicateMusic/internal/checksum"
+	"deduplicateMusic/internal/config"
 	"deduplicateMusic/internal/copyutil"
-	"deduplicateMusic/internal/dedup"
+	"deduplicateMusic/internal/cover"
 	"deduplicateMusic/internal/fingerprint"
+	"deduplicateMusic/internal/gzfile"
+	"deduplicateMusic/internal/logging"
+	"deduplicateMusic/internal/overrides"
+	"deduplicateMusic/internal/progress"
+	"deduplicateMusic/internal/provenance"
+	"deduplicateMusic/internal/removal"
 	"deduplicateMusic/internal/report"
-	"deduplicateMusic/internal/scanner"
+	"deduplicateMusic/internal/sidecar"
+	"deduplicateMusic/internal/source"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"net/url"
 	"os"
+	"os/signal"
+	"path"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
 )
 
-// reportItems 用于存放每个文件的处理记录
-var reportItems []report.ReportItem
+// atExit 中的函数在程序结束（含 fatalf 提前退出）时逆序执行，如释放运行锁
+var atExit []func()
+
+func runAtExit() {
+	for i := len(atExit) - 1; i >= 0; i-- {
+		atExit[i]()
+	}
+	atExit = nil
+}
+
+// fatalf 先执行清理，再以 error 级别记录并退出
+func fatalf(format string, args ...any) {
+	runAtExit()
+	slog.Error(fmt.Sprintf(format, args...))
+	os.Exit(1)
+}
+
+// scanFatal 报告扫描 what 失败并退出；因中断而失败时说明尚未做任何改动
+func scanFatal(what string, err error) {
+	if errors.Is(err, context.Canceled) {
+		fatalf("收到中断信号，%s未完成，尚未做任何改动", what)
+	}
+	fatalf("%s失败: %v", what, err)
+}
+
+// interruptContext 返回收到 Ctrl-C 或 SIGTERM 时取消的 ctx；取消后恢复默认处理，
+// 再按一次 Ctrl-C 立即退出
+func interruptContext() context.Context {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+		slog.Info("收到中断信号，在安全点停止（再按一次 Ctrl-C 立即退出）")
+	}()
+	return ctx
+}
+
+// warnf 以 warn 级别记录格式化的消息；逐文件的警告另带 path、err 等属性，见各调用处
+func warnf(format string, args ...any) {
+	slog.Warn(fmt.Sprintf(format, args...))
+}
 
 func main() {
-	// CLI 参数
-	srcDir := flag.String("src", "", "源目录，包含待去重的音频文件")
-	dstDir := flag.String("dst", "", "目标输出目录，保留的文件会被复制到此处")
-	workers := flag.Int("workers", runtime.NumCPU(), "并发工作数量（默认：CPU 核数）")
-	threshold := flag.Int("threshold", 8, "相似度阈值（哈希汉明距离），越小越严格，默认8")
-	durationSec := flag.Int("seconds", 8, "用于指纹的音频时长（秒）— 从文件开头读取多少秒用于指纹计算，默认8秒")
-	verbose := flag.Bool("v", false, "是否打印详细进度信息")
+	// 子命令
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "bench":
+			os.Exit(runBench(os.Args[2:]))
+		case "eval":
+			os.Exit(runEval(os.Args[2:]))
+		case "install-service":
+			os.Exit(runInstallService(os.Args[2:]))
+		case "db":
+			os.Exit(runDB(os.Args[2:]))
+		}
+	}
 
-	flag.Parse()
+	// CLI 参数（与配置文件、环境变量合并，见 config.Options）
+	cfg, configSources, err := config.Load(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		fatalf("%v", err)
+	}
+	// 进度条与日志共用 stderr：写日志前先清除进度条
+	bar := progress.New(os.Stderr, cfg.Quiet)
+	if err := logging.Setup(bar.Writer(os.Stderr), cfg.LogLevel, cfg.LogFormat); err != nil {
+		fatalf("%v", err)
+	}
+	atExit = append(atExit, bar.Finish)
+
+	if cfg.VerifyAudit != "" {
+		os.Exit(runVerifyAudit(cfg.VerifyAudit, cfg.AuditKey))
+	}
+	if cfg.VerifyChecksums != "" {
+		os.Exit(runVerifyChecksums(cfg.VerifyChecksums))
+	}
+	if cfg.UndoQuarantine != "" {
+		os.Exit(runUndoQuarantine(cfg.UndoQuarantine))
+	}
+	if cfg.MarkFalsePositive != "" {
+		if cfg.NeverMatch == "" {
+			fatalf("-mark-false-positive 需要 -never-match 指定误判清单")
+		}
+		if err := overrides.AppendRow(cfg.NeverMatch, strings.Split(cfg.MarkFalsePositive, "|")); err != nil {
+			fatalf("记录误判失败: %v", err)
+		}
+		fmt.Printf("已记录到误判清单 %s，之后的运行不会再合并这些文件\n", cfg.NeverMatch)
+		os.Exit(0)
+	}
+	if cfg.MarkConfirmed != "" {
+		if cfg.Confirmed == "" {
+			fatalf("-mark-confirmed 需要 -confirmed 指定确认清单")
+		}
+		if err := overrides.AppendRow(cfg.Confirmed, strings.Split(cfg.MarkConfirmed, "|")); err != nil {
+			fatalf("记录确认失败: %v", err)
+		}
+		fmt.Printf("已记录到确认清单 %s，之后的运行会据此校准阈值建议\n", cfg.Confirmed)
+		os.Exit(0)
+	}
+	if cfg.ProvenanceOf != "" {
+		os.Exit(runProvenanceOf(cfg.ProvenanceOf))
+	}
 
-	if *srcDir == "" || *dstDir == "" {
+	if err := cfg.Validate(configSources); errors.Is(err, config.ErrNoDirs) {
 		flag.Usage()
 		os.Exit(1)
+	} else if err != nil {
+		fatalf("%v", err)
+	}
+	if cfg.InPlace && cfg.SpotCheck > 0 {
+		slog.Warn("-in-place 会移除重复文件，忽略 -spot-check")
+		cfg.SpotCheck = 0
+	}
+	// -summary-json / -report -：stdout 只留给 JSON 对象或报告，人类可读的输出改写到 stderr
+	jsonOut := os.Stdout
+	if cfg.StdoutTaken() {
+		os.Stdout = os.Stderr
+	}
+	if cfg.PrintConfig {
+		if err := config.Write(jsonOut, flag.CommandLine, configSources, "config", "print-config"); err != nil {
+			fatalf("输出配置失败: %v", err)
+		}
+		os.Exit(0)
+	}
+	ctx := interruptContext()
+	if cfg.Batch {
+		os.Exit(runBatch(ctx, cfg, jsonOut))
 	}
+	r := newRun(ctx, cfg, bar, jsonOut)
+	defer runAtExit()
+	r.scan()
+	r.fingerprint()
+	r.groupOptions()
+	p := newProcessor(r)
+	p.processGroups()
+	p.finish()
+}
 
-	start := time.Now()
-	if *verbose {
-		log.Printf("开始音频去重：src=%s dst=%s workers=%d threshold=%d seconds=%d\n",
-			*srcDir, *dstDir, *workers, *threshold, *durationSec)
+// baseName 返回本地路径、adb 路径或 URL 的文件名（URL 会做路径反转义）
+func baseName(p string) string {
+	if dp := source.DevicePath(p); dp != "" {
+		return path.Base(dp)
+	}
+	if u, err := url.Parse(p); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		return path.Base(u.Path)
 	}
+	return filepath.Base(p)
+}
 
-	// 1. 扫描文件
-	exts := []string{".mp3", ".wav", ".flac", ".aac", ".m4a", ".ogg"} // 支持的扩展
-	files, err := scanner.ScanDir(*srcDir, exts)
+// copySidecars 把 audio 的伴随文件按 mode 放到 dstAudio 旁边：专属文件随音频改名，已存在的共享文件（如封面）不覆盖。
+// 共享文件可能还属于目录中的其它曲目，move 方式下也只复制不移动。
+func copySidecars(audio, dstAudio string, exts []string, mode copyutil.Mode, ro copyutil.ReadOnly) []audit.Output {
+	sc, err := sidecar.Find(audio, exts)
 	if err != nil {
-		log.Fatalf("扫描目录失败: %v", err)
+		slog.Warn("查找伴随文件失败", "path", audio, "err", err)
+		return nil
 	}
-	if len(files) == 0 {
-		log.Fatalf("未在 %s 找到任何支持的音频文件", *srcDir)
+	var out []audit.Output
+	cp := func(p string, own bool) {
+		target := sidecar.Target(p, dstAudio, own)
+		if _, err := os.Stat(target); err == nil && !own {
+			return
+		}
+		m := mode
+		if !own && m == copyutil.ModeMove {
+			m = copyutil.ModeCopy
+		}
+		if err := copyutil.Transfer(m, p, target, ro); err != nil {
+			slog.Warn(modeVerb(m)+"伴随文件失败", "path", p, "err", err)
+			return
+		}
+		out = append(out, audit.Output{FileDigest: audit.FileDigest{Path: target}, Source: p})
 	}
-	if *verbose {
-		log.Printf("扫描到 %d 个音频文件\n", len(files))
+	for _, p := range sc.Own {
+		cp(p, true)
 	}
+	for _, p := range sc.Shared {
+		cp(p, false)
+	}
+	return out
+}
 
-	// 2. 并发计算指纹
-	type result struct {
-		meta dedup.FileMeta
-		err  error
+// writeDeviceRemovals 写出设备上可删除的文件（adb 路径还原为设备端路径），每行一个
+func writeDeviceRemovals(out string, paths []string) error {
+	var b strings.Builder
+	for _, p := range paths {
+		if dp := source.DevicePath(p); dp != "" {
+			p = dp
+		}
+		b.WriteString(p)
+		b.WriteByte('\n')
 	}
+	f, err := gzfile.Create(out)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(f, b.String())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
 
-	jobs := make(chan string)
-	results := make(chan result)
-	var wg sync.WaitGroup
+// modeVerb 返回输出方式的中文动词，用于日志
+func modeVerb(m copyutil.Mode) string {
+	switch m {
+	case copyutil.ModeMove:
+		return "移动"
+	case copyutil.ModeHardlink:
+		return "硬链接"
+	case copyutil.ModeSymlink:
+		return "符号链接"
+	}
+	return "复制"
+}
 
-	// 启动 worker
-	for i := 0; i < *workers; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for p := range jobs {
-				fp, size, err := fingerprint.FingerprintFromFile(p, *durationSec, 64) // 64-bit 指纹
-				r := result{meta: dedup.FileMeta{Path: p, Size: size, FP: fp}, err: err}
-				results <- r
-			}
-		}()
+// transferFrom 把源后端中的文件按 mode 放到 dst：本地文件按 mode 处理，远程文件总是流式下载复制
+func transferFrom(src source.Source, mode copyutil.Mode, p, dst string, ro copyutil.ReadOnly) error {
+	if source.IsLocalPath(p) {
+		return copyutil.Transfer(mode, p, dst, ro)
+	}
+	rc, err := src.Open(p)
+	if err != nil {
+		return err
 	}
+	defer rc.Close()
+	return copyutil.CopyFromReader(rc, dst, ro)
+}
 
-	// 发送任务
-	go func() {
-		for _, f := range files {
-			jobs <- f
+// writeAuditRecord 计算输入/输出/报告的摘要，（可选）签名后写出审计记录
+func writeAuditRecord(rec *audit.Record, inputs []string, outputs []audit.Output, reportPath string, key []byte) error {
+	for _, p := range inputs {
+		d, err := audit.HashFile(p)
+		if err != nil {
+			slog.Warn("审计摘要计算失败", "path", p, "err", err)
+			continue
 		}
-		close(jobs)
-	}()
-
-	// 收集结果
-	var metas []dedup.FileMeta
-	var collectErr error
-	go func() {
-		for res := range results {
-			if res.err != nil {
-				// 记录第一个错误并继续（不希望单文件失败就中断整个流程）
-				if collectErr == nil {
-					collectErr = res.err
-				}
-				log.Printf("警告：处理文件 %s 失败: %v\n", res.meta.Path, res.err)
-				continue
-			}
-			metas = append(metas, res.meta)
-			if *verbose {
-				log.Printf("指纹计算完成: %s (size=%d bits=%b)\n", res.meta.Path, res.meta.Size, res.meta.FP)
-			}
+		rec.Inputs = append(rec.Inputs, d)
+	}
+	for _, o := range outputs {
+		d, err := audit.HashFile(o.Path)
+		if err != nil {
+			slog.Warn("审计摘要计算失败", "path", o.Path, "err", err)
+			continue
 		}
-	}()
+		o.FileDigest = d
+		rec.Outputs = append(rec.Outputs, o)
+	}
+	if reportPath != "" {
+		if d, err := audit.HashFile(reportPath); err == nil {
+			rec.Report = &d
+		}
+	}
+	rec.FinishedAt = time.Now()
+	if key != nil {
+		if err := rec.Sign(key); err != nil {
+			return err
+		}
+	}
+	name := fmt.Sprintf("audio_dedup_audit_%s.json", rec.StartedAt.Format("20060102_150405"))
+	if err := audit.Write(name, rec); err != nil {
+		return err
+	}
+	fmt.Printf("审计记录已生成: %s\n", name)
+	return nil
+}
 
-	// 等待 worker 完成后关闭 results
-	wg.Wait()
-	close(results)
+// runVerifyAudit 校验审计记录签名，返回进程退出码
+func runVerifyAudit(path, keyPath string) int {
+	if keyPath == "" {
+		fmt.Println("-verify-audit 需要配合 -audit-key 使用")
+		return 2
+	}
+	key, err := audit.LoadKey(keyPath)
+	if err != nil {
+		fmt.Printf("读取审计签名密钥失败: %v\n", err)
+		return 2
+	}
+	rec, err := audit.Read(path)
+	if err != nil {
+		fmt.Printf("读取审计记录失败: %v\n", err)
+		return 2
+	}
+	ok, err := rec.Verify(key)
+	if err != nil {
+		fmt.Printf("校验失败: %v\n", err)
+		return 1
+	}
+	if !ok {
+		fmt.Println("签名不匹配：审计记录可能被篡改或密钥不正确")
+		return 1
+	}
+	fmt.Printf("签名有效：%d 个输入，%d 个输出\n", len(rec.Inputs), len(rec.Outputs))
+	return 0
+}
 
-	if collectErr != nil {
-		log.Printf("注意：存在文件处理错误（见上方警告），请核对处理日志")
+// retryHint 返回因权限跳过文件时提升权限重新运行的提示（已是 root 时为空）
+func retryHint() string {
+	if runtime.GOOS == "windows" {
+		return "以管理员身份打开命令提示符后重新执行相同命令"
+	}
+	if os.Geteuid() == 0 {
+		return ""
 	}
+	args := make([]string, len(os.Args))
+	for i, a := range os.Args {
+		if a == "" || strings.ContainsAny(a, " \t'\"\\$`;&|<>()*?!#~") {
+			a = "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+		}
+		args[i] = a
+	}
+	return "sudo " + strings.Join(args, " ")
+}
 
-	if len(metas) == 0 {
-		log.Fatalf("没有成功计算任何文件的指纹")
+// runProvenanceOf 打印目标目录中文件的来源记录，返回进程退出码
+func runProvenanceOf(path string) int {
+	root, ok := provenance.FindRoot(path)
+	if !ok {
+		fmt.Printf("未找到来源清单（%s）: %s 不在记录过来源的目标目录中\n", provenance.ManifestFile, path)
+		return 2
+	}
+	recs, err := provenance.Lookup(root, path)
+	if err != nil {
+		fmt.Printf("读取来源清单失败: %v\n", err)
+		return 2
 	}
+	if len(recs) == 0 {
+		fmt.Printf("%s 中没有 %s 的来源记录\n", filepath.Join(root, provenance.ManifestFile), path)
+		return 1
+	}
+	for _, r := range recs {
+		fmt.Printf("%s  运行 %s（%s）: %s 中的 %s，%s\n", r.Time.Format(time.DateTime), r.RunID, r.Mode,
+			r.SourceRoot, r.SourcePath, report.HumanBytes(r.Size))
+	}
+	return 0
+}
 
-	// 3. 去重（基于汉明距离 + union-find 组建）
-	keeps := dedup.SelectKeep(metas, *threshold)
+// runUndoQuarantine 把隔离批次中的文件放回原处，返回进程退出码
+func runUndoQuarantine(batch string) int {
+	res, err := removal.Undo(batch)
+	for _, s := range res.Skipped {
+		fmt.Printf("未还原: %s\n", s)
+	}
+	if err != nil {
+		fmt.Printf("还原失败: %v\n", err)
+		return 2
+	}
+	fmt.Printf("已还原 %d 个文件，跳过 %d 个\n", res.Restored, len(res.Skipped))
+	if len(res.Skipped) > 0 {
+		return 1
+	}
+	return 0
+}
 
-	// 4. 复制保留文件到目标目录
-	if err := os.MkdirAll(*dstDir, 0o755); err != nil {
-		log.Fatalf("创建目标目录失败: %v", err)
+// runVerifyChecksums 校验目录下的校验旁路文件，返回进程退出码
+func runVerifyChecksums(dir string) int {
+	res, err := checksum.Verify(dir)
+	if err != nil {
+		fmt.Printf("校验失败: %v\n", err)
+		return 2
 	}
-	for _, m := range keeps {
-		dstPath := filepath.Join(*dstDir, filepath.Base(m.Path))
-		if err := copyutil.CopyFile(m.Path, dstPath); err != nil {
-			log.Printf("复制失败: %s -> %s : %v\n", m.Path, dstPath, err)
-		} else if *verbose {
-			log.Printf("复制成功: %s -> %s\n", m.Path, dstPath)
-		}
-		reportItems = append(reportItems, report.ReportItem{
-			FilePath: m.Path,
-			Kept:     true,
-			Size:     m.Size,
-			NewPath:  dstPath,
-		})
+	if res.FFPSkipped {
+		fmt.Println("注意：ffmpeg 未找到，跳过 FLAC 指纹（.ffp）校验")
 	}
+	for _, p := range res.Problems {
+		fmt.Printf("损坏: %s: %s\n", p.Path, p.Reason)
+	}
+	if len(res.Problems) > 0 {
+		fmt.Printf("共校验 %d 个文件，%d 个异常\n", res.Checked, len(res.Problems))
+		return 1
+	}
+	fmt.Printf("共校验 %d 个文件，全部正常\n", res.Checked)
+	return 0
+}
 
-	fmt.Printf("完成：源文件 %d，处理成功 %d，保留并复制 %d，耗时 %s\n", len(files), len(metas), len(keeps), time.Since(start))
-	if collectErr != nil {
-		fmt.Println("注意：存在单文件处理错误，请查看日志。")
+// findCovers 并发计算候选文件的色度序列，两两比较后返回相似度 >= threshold 的对（按相似度降序）
+func findCovers(paths []string, seconds int, threshold float64, workers int) []report.CoverPair {
+	chromas := make([]cover.Chroma, len(paths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				samples, err := fingerprint.DecodePCM(paths[i], seconds)
+				if err != nil {
+					warnf("翻唱检测解码失败: %v", err)
+					continue
+				}
+				chromas[i] = cover.Compute(samples)
+			}
+		}()
+	}
+	for i := range paths {
+		jobs <- i
 	}
+	close(jobs)
+	wg.Wait()
 
-	// 处理完成后生成 CSV
-	if err := report.WriteCSVReport(reportItems); err != nil {
-		fmt.Printf("生成报告失败: %v\n", err)
+	var pairs []report.CoverPair
+	for i := range paths {
+		for j := i + 1; j < len(paths); j++ {
+			// 允许约 ±4 秒的起始偏移（不同版本的前奏长度不同）
+			if score, tr := cover.Similarity(chromas[i], chromas[j], 8); score >= threshold {
+				pairs = append(pairs, report.CoverPair{A: paths[i], B: paths[j], Score: score, Transpose: tr})
+			}
+		}
 	}
+	sort.Slice(pairs, func(a, b int) bool { return pairs[a].Score > pairs[b].Score })
+	return pairs
 }
@@ -0,0 +1,728 @@
+// file: cmd/audio-dedup/process.go
+// package: main
+//
+// 处理阶段：分组后逐组把保留文件放入目标目录（复制、移动或链接，-upgrade-dst 时替换旧版本）、
+// 原地移除重复文件、执行钩子并边处理边写报告；每处理完一组写入处理日志，-resume 据此跳过。
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"deduplicateMusic/internal/audit"
+	"deduplicateMusic/internal/calibrate"
+	"deduplicateMusic/internal/copyutil"
+	"deduplicateMusic/internal/dedup"
+	"deduplicateMusic/internal/fingerprint"
+	"deduplicateMusic/internal/hooks"
+	"deduplicateMusic/internal/musiclib"
+	"deduplicateMusic/internal/preview"
+	"deduplicateMusic/internal/provenance"
+	"deduplicateMusic/internal/removal"
+	"deduplicateMusic/internal/report"
+	"deduplicateMusic/internal/sidecar"
+	"deduplicateMusic/internal/source"
+	"deduplicateMusic/internal/spotcheck"
+	"deduplicateMusic/internal/syncplan"
+	"deduplicateMusic/internal/tags"
+	"deduplicateMusic/internal/upgrade"
+)
+
+// processor 为处理阶段的状态与统计，finish 据此输出摘要与各类报告
+type processor struct {
+	*run
+
+	backupRoot      string // -upgrade-dst 替换下来的旧版本的备份目录
+	sampler         *spotcheck.Sampler
+	dstNames        *copyutil.Names
+	onCollision     copyutil.CollisionPolicy
+	collisionCount  int    // 文件名冲突数
+	collisionAction string // 冲突在报告中的标记（与 -on-collision 一一对应）
+	reportW         *report.Writer
+	reportErr       bool
+	needMedia       bool
+	remover         *removal.Remover
+	removed         map[string]bool
+	runID           string
+	provW           *provenance.Writer
+	provFailed      bool
+	xattrFailed     bool
+
+	clips           []preview.Clip
+	copied          []audit.Output
+	summaryBuilder  report.SummaryBuilder
+	folderBuilder   report.FolderBuilder
+	planEntries     []musiclib.PlanEntry
+	coverCandidates []string
+	movedTo         map[string]string // -mode move：已移走的保留文件 → 目标目录中的新位置
+	deviceRemovals  []string
+	syncItems       []syncplan.Item
+	processed       []string // 已处理分组中的源文件，提前停止时写入检查点
+
+	groupCount, keepCount, copiedCount int
+	keptBytes, copiedBytes             int64
+	removedCount                       int
+	removedBytes                       int64
+	upgradeCount, lyricsMerged         int
+	spectroCount, thumbCount           int
+	changedCount, keeperGoneCount      int
+	resumedCount                       int
+}
+
+// newProcessor 准备目标目录与各输出目录、打开报告与原地移除，并把指纹阶段失败的文件写入报告
+func newProcessor(r *run) *processor {
+	cfg := r.cfg
+	p := &processor{run: r,
+		backupRoot: filepath.Join(cfg.Dst, copyutil.BackupDirName, report.Stamp()),
+		sampler:    spotcheck.NewSampler(cfg.SpotCheck, time.Now().UnixNano()),
+		removed:    map[string]bool{},
+		movedTo:    map[string]string{},
+	}
+	if err := os.MkdirAll(cfg.Dst, 0o755); err != nil {
+		fatalf("创建目标目录失败: %v", err)
+	}
+	// 平铺到目标目录时同名文件会互相覆盖；大小写是否算同名由目标文件系统决定
+	caseSensitive, err := copyutil.CaseSensitive(cfg.Dst)
+	if err != nil {
+		warnf("探测目标目录是否区分大小写失败，按区分处理: %v", err)
+	} else if !caseSensitive && cfg.Verbose {
+		slog.Info("目标目录不区分文件名大小写", "dst", cfg.Dst)
+	}
+	p.dstNames = copyutil.NewNames(caseSensitive)
+	p.onCollision, _ = copyutil.ParseCollisionPolicy(cfg.OnCollision) // 已由 Validate 校验
+	if cfg.Previews != "" {
+		if err := os.MkdirAll(cfg.Previews, 0o755); err != nil {
+			fatalf("创建试听片段目录失败: %v", err)
+		}
+	}
+	if cfg.Thumbnails != "" {
+		if err := os.MkdirAll(cfg.Thumbnails, 0o755); err != nil {
+			fatalf("创建缩略图目录失败: %v", err)
+		}
+	}
+	if cfg.SpectroDiff != "" {
+		if err := os.MkdirAll(cfg.SpectroDiff, 0o755); err != nil {
+			fatalf("创建频谱差异图目录失败: %v", err)
+		}
+	}
+	// 报告边处理边写出，运行中途中断时已写出的部分仍然可用（没有结束标记行）
+	if p.reportW, err = report.CreateCSVReport(r.reportOpts); err != nil {
+		fatalf("%v", err)
+	}
+	if cfg.InPlace {
+		if p.remover, err = removal.New(r.removeMethod, cfg.Src, cfg.Quarantine, report.Stamp(), r.readOnly); err != nil {
+			fatalf("%v", err)
+		}
+		remover := p.remover
+		atExit = append(atExit, func() {
+			if err := remover.Close(); err != nil {
+				warnf("关闭隔离清单失败: %v", err)
+			}
+		})
+	}
+	// 来源追溯：每个放入目标目录的文件记下源根目录、源内路径与运行编号
+	p.runID = report.Stamp()
+	if cfg.ProvenanceXattr && (r.mode == copyutil.ModeHardlink || r.mode == copyutil.ModeSymlink) {
+		slog.Warn("目标文件与源文件共用数据，不写入来源扩展属性", "mode", r.mode)
+		cfg.ProvenanceXattr = false
+	}
+	p.needMedia = report.NeedsMedia(r.reportCols)
+	for _, item := range r.failedItems {
+		p.addReport(item)
+	}
+	return p
+}
+
+// processGroups 分组并逐组处理；处理阶段超出时间预算时在两个分组之间停止，已处理的分组照常写入报告
+func (p *processor) processGroups() {
+	cfg := p.cfg
+	switch {
+	case p.fingerprintStopped:
+		// 见 fingerprint：指纹阶段提前停止时不分组
+	case p.store == nil:
+		p.bar.Stage("比较", 0)
+		groups := dedup.GroupWith(p.metas, p.opts)
+		if cfg.FolderKeep {
+			// 分量与保留策略无关：先按原策略分组找出可整目录删除的目录，再让其中的文件让位后重新选择
+			var fb report.FolderBuilder
+			for _, g := range groups {
+				fb.Add(g)
+			}
+			redundant := map[string]bool{}
+			for _, f := range fb.Folders() {
+				if f.Redundant {
+					redundant[f.Dir] = true
+				}
+			}
+			if len(redundant) > 0 {
+				base := p.opts.Policy
+				p.opts.Policy = dedup.KeepPolicyFunc(func(a, b dedup.FileMeta) bool {
+					ra, rb := redundant[report.DirOf(a.Path)], redundant[report.DirOf(b.Path)]
+					if ra != rb {
+						return rb
+					}
+					return base.Better(a, b)
+				})
+				groups = dedup.GroupWith(p.metas, p.opts)
+			}
+		}
+		if len(p.inRef) > 0 {
+			groups = p.dropRefOnly(groups)
+		}
+		dedup.OrderGroups(groups, p.order)
+		if cfg.Review {
+			p.bar.Finish()
+			groups = p.reviewGroups(groups)
+		}
+		if p.destructive && !cfg.Yes {
+			p.bar.Finish()
+			p.confirmRemoval(groups)
+		}
+		p.bar.Stage("处理", len(groups))
+		for _, g := range groups {
+			if p.stopped = p.stopReason(); p.stopped != "" {
+				break
+			}
+			p.handle(g)
+			p.bar.Add(1)
+		}
+	default:
+		if cfg.FolderKeep {
+			slog.Warn("溢出模式下 -folder-keep 不生效，只报告重复目录")
+		}
+		if p.order != dedup.ProcessOrders[0] {
+			slog.Warn("溢出模式下 -order 只影响计算指纹的先后，分组仍按指纹顺序处理")
+		}
+		if cfg.Review {
+			slog.Warn("溢出模式下不支持 -review，按自动选择处理")
+		}
+		if p.groupBy == dedup.GroupByTags {
+			slog.Warn("溢出模式先按指纹划分分量，-group-by=tags 只能合并指纹分量内标签相同的文件")
+		}
+		// 溢出模式：先在紧凑指纹索引上划分连通分量，再逐个分量读回元数据并选择保留文件；
+		// 自定义匹配器与距离度量只在指纹（汉明）分量内生效。
+		nextID := 1
+		p.bar.Stage("比较", 0)
+		comps := dedup.IndexedComponents(p.store.FPs, p.opts)
+		p.bar.Stage("处理", len(comps))             // 溢出模式按分量计数
+		dedup.OrderComponents(comps, p.store.FPs) // 溢出文件按完成先后追加，按指纹排序使组 ID 与 -workers 无关
+	comps:
+		for _, comp := range comps {
+			members := make([]dedup.FileMeta, 0, len(comp))
+			for _, i := range comp {
+				m, err := p.store.Load(i)
+				if err != nil {
+					fatalf("读取溢出文件失败: %v", err)
+				}
+				members = append(members, m)
+			}
+			inner := p.opts
+			inner.ShardBits = 0 // 分量已很小，无需再分片
+			for _, g := range dedup.GroupWith(members, inner) {
+				if p.stopped = p.stopReason(); p.stopped != "" {
+					break comps
+				}
+				if p.refOnly(g) {
+					continue
+				}
+				g.ID = nextID
+				nextID++
+				p.handle(g)
+			}
+			p.bar.Add(1)
+		}
+	}
+	p.bar.Finish()
+}
+
+// groupItems 收集一组的报告行：写入报告，处理完后整组写入处理日志
+type groupItems struct {
+	p     *processor
+	id    int
+	tags  map[string]tags.Tags
+	items []report.ReportItem
+}
+
+func (gi *groupItems) add(item report.ReportItem) {
+	gi.items = append(gi.items, item)
+	item.GroupID, item.Tags = gi.id, gi.tags[item.FilePath]
+	gi.p.addReport(item)
+}
+
+// handle 处理一个分组
+func (p *processor) handle(g dedup.Group) {
+	cfg := p.cfg
+	sig := p.signature(g)
+	if p.decisions != nil && len(g.Duplicates) > 0 {
+		if keep, ok := p.decisions.Keeper(sig); ok && !cfg.RecomputeDecisions && dedup.Promote(&g, keep, p.opts) {
+			p.decisions.Applied()
+			if cfg.Verbose {
+				slog.Info("沿用上次的保留决定", "group", g.ID, "keep", keep)
+			}
+		}
+		if err := p.decisions.Record(sig, g.Keep.Path); err != nil {
+			warnf("记录保留决定失败: %v", err)
+		}
+	}
+	p.processed = append(p.processed, g.Keep.Path)
+	for _, m := range g.Protected {
+		p.processed = append(p.processed, m.Path)
+	}
+	for _, d := range g.Duplicates {
+		p.processed = append(p.processed, d.Path)
+	}
+	gi := &groupItems{p: p, id: g.ID, tags: map[string]tags.Tags{g.Keep.Path: g.Keep.Tags}}
+	for _, m := range g.Protected {
+		gi.tags[m.Path] = m.Tags
+	}
+	for _, d := range g.Duplicates {
+		gi.tags[d.Path] = d.Tags
+	}
+	p.groupCount++
+	p.keepCount += 1 + len(g.Protected)
+	p.keptBytes += g.Keep.Size
+	for _, m := range g.Protected {
+		p.keptBytes += m.Size
+	}
+	changed := map[string]bool{}
+	for _, d := range g.Duplicates {
+		if p.changedSince(d.FileMeta) != "" {
+			changed[d.Path] = true
+		}
+	}
+	p.summaryBuilder.Add(g)
+	if p.feedback != nil && p.pinned.Pinned(g.Keep) {
+		// 固定保留的文件所在的组视为用户确认的重复
+		keep, _ := filepath.Abs(g.Keep.Path)
+		for _, d := range g.Duplicates {
+			dup, _ := filepath.Abs(d.Path)
+			p.feedback.Record(p.feedbackProfile, keep, dup, d.Distance, calibrate.Accepted)
+		}
+	}
+	if cfg.Folders {
+		p.folderBuilder.Add(g)
+	}
+	if cfg.Previews != "" && len(g.Duplicates) > 0 {
+		p.renderPreviews(g)
+	}
+	if cfg.Thumbnails != "" && len(g.Duplicates) > 0 {
+		p.renderThumbnails(g)
+	}
+	if cfg.SpectroDiff != "" && source.IsLocalPath(g.Keep.Path) {
+		p.renderSpectroDiffs(g)
+	}
+	if cfg.SyncPlan != "" {
+		p.planSync(g)
+	}
+	if cfg.Covers && source.IsLocalPath(g.Keep.Path) {
+		p.coverCandidates = append(p.coverCandidates, g.Keep.Path) // 每组只取保留文件参与翻唱检测
+	}
+	for _, d := range g.Duplicates {
+		if p.onDevice[d.Path] && !changed[d.Path] {
+			p.deviceRemovals = append(p.deviceRemovals, d.Path)
+		}
+		if p.inDst[d.Path] || p.inRef[d.Path] || changed[d.Path] {
+			continue // 目标目录与参考库中的文件不属于源资料库；被改动的文件不列入删除计划
+		}
+		p.planEntries = append(p.planEntries, musiclib.PlanEntry{Path: d.Path, Seconds: -1, Title: d.Tags.Title, KeptPath: g.Keep.Path})
+	}
+	// -resume：上次运行已处理完的分组沿用记录的报告行，不再复制或移除
+	var done []report.ReportItem
+	if p.journal.Done(sig, &done) {
+		p.resumedCount++
+		for _, item := range done {
+			if item.Kept && item.NewPath != "" && item.NewPath != item.FilePath {
+				if _, err := os.Stat(item.NewPath); err == nil {
+					p.copied = append(p.copied, audit.Output{FileDigest: audit.FileDigest{Path: item.NewPath}, Source: item.FilePath})
+					p.copiedCount++
+					p.copiedBytes += item.Size
+				}
+			}
+			gi.add(item)
+		}
+		return
+	}
+	// -upgrade-dst：保留文件是新副本而目标目录中已有同一曲目时，替换其中第一个旧版本
+	plan := upgrade.For(g, p.inDst, p.inRef, changed, cfg.UpgradeOnly)
+	backups := map[string]string{}
+	finalKeep := g.Keep.Path // 抽查时使用的保留文件：复制成功后为目标目录中的副本
+	// 保留文件以及受保护规则命中的成员都会被复制（已在目标目录中的除外）
+	for _, m := range append([]dedup.FileMeta{g.Keep}, g.Protected...) {
+		if dstPath := p.place(g, m, plan, backups, gi); dstPath != "" && m.Path == g.Keep.Path {
+			finalKeep = dstPath
+		}
+	}
+	if cfg.SpotCheck > 0 && source.IsLocalPath(finalKeep) {
+		for _, d := range g.Duplicates {
+			if source.IsLocalPath(d.Path) && backups[d.Path] == "" && !changed[d.Path] {
+				p.sampler.Offer(spotcheck.Pair{GroupID: g.ID, Keep: g.Keep.Path, KeepFile: finalKeep, KeepFP: g.Keep.FP, KeepSize: g.Keep.Size,
+					Dup: d.Path, DupFP: d.FP, DupSize: d.Size, Distance: d.Distance})
+			}
+		}
+	}
+	for _, d := range g.Duplicates {
+		p.handleDuplicate(g, d, changed[d.Path], backups, gi)
+	}
+	if err := p.journal.Record(sig, gi.items); err != nil {
+		warnf("写入处理日志失败，之后不再记录: %v", err)
+		p.journal.Close()
+		p.journal = nil
+	}
+}
+
+// place 把组内的保留文件 m 放入目标目录（按 plan 替换旧版本时先备份，备份位置记入 backups），
+// 返回放入后的路径；未放入（已在目标目录或参考库、被改动、跳过或失败）时返回空串
+func (p *processor) place(g dedup.Group, m dedup.FileMeta, plan upgrade.Plan, backups map[string]string, gi *groupItems) string {
+	cfg := p.cfg
+	replaced := plan.Replaced
+	if p.inRef[m.Path] {
+		gi.add(report.ReportItem{FilePath: m.Path, Kept: true, Size: m.Size, Verify: m.Integrity, Action: report.ActionInRef})
+		return ""
+	}
+	if p.inDst[m.Path] {
+		gi.add(report.ReportItem{FilePath: m.Path, Kept: true, Size: m.Size, NewPath: m.Path, Verify: m.Integrity, Action: report.ActionInDst})
+		return ""
+	}
+	if p.changedSince(m) != "" || p.hashChanged(m) != "" {
+		gi.add(report.ReportItem{FilePath: m.Path, Kept: true, Size: m.Size, Verify: m.Integrity, Action: report.ActionChanged})
+		return ""
+	}
+	dstPath := filepath.Join(cfg.Dst, baseName(m.Path))
+	if cfg.PreserveStructure {
+		dstPath = syncplan.Target(p.rootOf(m.Path), cfg.Dst, m.Path)
+	}
+	action := plan.Action(m.Path)
+	if action == report.ActionUpgraded {
+		// 沿用旧文件的位置与文件名，扩展名随新副本
+		dstPath = strings.TrimSuffix(replaced.Path, filepath.Ext(replaced.Path)) + filepath.Ext(baseName(m.Path))
+	}
+	if action != report.ActionUpgraded && cfg.MinAlbumCompleteness > 0 && !p.albumIndex.Enough(m, cfg.MinAlbumCompleteness) {
+		if cfg.Verbose {
+			have, total, _ := p.albumIndex.Completeness(m)
+			slog.Info("专辑不完整，跳过导入", "path", m.Path, "have", have, "total", total)
+		}
+		gi.add(report.ReportItem{FilePath: m.Path, Kept: true, Size: m.Size, Verify: m.Integrity, Action: report.ActionIncomplete})
+		return ""
+	}
+	if action == report.ActionSkipped {
+		gi.add(report.ReportItem{FilePath: m.Path, Kept: true, Size: m.Size, Verify: m.Integrity, Action: action})
+		return ""
+	}
+	if p.managedSafe || cfg.InPlace {
+		gi.add(report.ReportItem{FilePath: m.Path, Kept: true, Size: m.Size, Verify: m.Integrity})
+		return ""
+	}
+	collided := ""
+	if action == "" {
+		resolved, ok := p.dstNames.Resolve(dstPath, m.Path, m.Size, p.onCollision)
+		if ok {
+			p.collisionCount++
+			switch p.onCollision {
+			case copyutil.CollisionSkip:
+				p.collisionAction = report.ActionCollisionSkipped
+				slog.Warn("目标文件名冲突，跳过", "path", m.Path, "dst", dstPath)
+				gi.add(report.ReportItem{FilePath: m.Path, Kept: true, Size: m.Size, NewPath: dstPath, Verify: m.Integrity, Action: report.ActionCollisionSkipped})
+				return ""
+			case copyutil.CollisionError:
+				p.collisionAction = report.ActionCollisionError
+				err := fmt.Errorf("目标文件 %s 与其它文件同名", dstPath)
+				slog.Error(modeVerb(p.mode)+"失败", "path", m.Path, "dst", dstPath, "err", err)
+				p.fireHook(hooks.Event{Event: hooks.EventError, Path: m.Path, Size: m.Size, GroupID: g.ID, Error: err.Error()})
+				gi.add(report.ReportItem{FilePath: m.Path, Kept: true, Size: m.Size, NewPath: dstPath, Verify: m.Integrity, Action: report.ActionCollisionError})
+				return ""
+			case copyutil.CollisionOverwrite:
+				slog.Warn("目标文件名冲突，覆盖已有文件", "path", m.Path, "dst", resolved)
+				collided = report.ActionOverwritten
+				p.collisionAction = collided
+			default:
+				slog.Warn("目标文件名冲突，改名保存", "path", m.Path, "dst", resolved)
+				collided = report.ActionRenamed
+				p.collisionAction = collided
+			}
+		}
+		dstPath = resolved
+	} else {
+		dstPath = p.dstNames.Claim(dstPath) // 替换升级沿用旧文件的位置
+	}
+	if action == report.ActionUpgraded {
+		bak, err := copyutil.Backup(cfg.Dst, replaced.Path, p.backupRoot, p.readOnly)
+		if err != nil {
+			slog.Error("备份失败，跳过替换", "path", replaced.Path, "err", err)
+			p.fireHook(hooks.Event{Event: hooks.EventError, Path: replaced.Path, Size: replaced.Size, GroupID: g.ID, Error: err.Error()})
+			gi.add(report.ReportItem{FilePath: m.Path, Kept: true, Size: m.Size, Verify: m.Integrity})
+			return ""
+		}
+		backups[replaced.Path] = bak
+		if cfg.Sidecars {
+			if sc, err := sidecar.Find(replaced.Path, p.exts); err == nil {
+				for _, s := range sc.Own {
+					if _, err := copyutil.Backup(cfg.Dst, s, p.backupRoot, p.readOnly); err != nil {
+						slog.Warn("备份伴随文件失败", "path", s, "err", err)
+					}
+				}
+			}
+		}
+	}
+	placed := ""
+	if err := transferFrom(p.src, p.mode, m.Path, dstPath, p.readOnly); err != nil {
+		slog.Error(modeVerb(p.mode)+"失败", "path", m.Path, "dst", dstPath, "err", err)
+		p.fireHook(hooks.Event{Event: hooks.EventError, Path: m.Path, Size: m.Size, GroupID: g.ID, Error: err.Error()})
+		if action != "" {
+			// 新副本没有写成功，把旧版本放回原处
+			bak := backups[replaced.Path]
+			if rerr := os.Rename(bak, replaced.Path); rerr == nil {
+				delete(backups, replaced.Path)
+			} else {
+				slog.Error("恢复旧文件失败", "backup", bak, "err", rerr)
+			}
+		}
+		action, collided = "", ""
+	} else {
+		placed = dstPath
+		p.kept(g, m, dstPath)
+		if action != "" {
+			p.upgradeCount++
+			if cfg.Verbose {
+				slog.Info("替换升级", "path", m.Path, "dst", dstPath, "backup", backups[replaced.Path])
+			}
+		} else if cfg.Verbose {
+			slog.Info(modeVerb(p.mode)+"成功", "path", m.Path, "dst", dstPath)
+		}
+		p.fireHook(hooks.Event{Event: hooks.EventKeep, Path: m.Path, Size: m.Size,
+			Fingerprint: fmt.Sprintf("%016x", m.FP), GroupID: g.ID, NewPath: dstPath})
+	}
+	if collided != "" {
+		action = collided
+	}
+	gi.add(report.ReportItem{
+		FilePath: m.Path,
+		Kept:     true,
+		Size:     m.Size,
+		NewPath:  dstPath,
+		Verify:   m.Integrity,
+		Action:   action,
+	})
+	return placed
+}
+
+// kept 记录已放入目标目录的保留文件：审计输出、来源追溯、伴随文件，以及保留文件的歌词合并（-merge-lyrics）
+func (p *processor) kept(g dedup.Group, m dedup.FileMeta, dstPath string) {
+	cfg := p.cfg
+	p.copied = append(p.copied, audit.Output{FileDigest: audit.FileDigest{Path: dstPath}, Source: m.Path})
+	p.recordProvenance(m, dstPath)
+	p.copiedCount++
+	p.copiedBytes += m.Size
+	keeper := m.Path
+	if p.mode == copyutil.ModeMove && source.IsLocalPath(m.Path) {
+		p.movedTo[m.Path] = dstPath
+		keeper = dstPath
+	}
+	if cfg.Sidecars && source.IsLocalPath(m.Path) {
+		p.copied = append(p.copied, copySidecars(m.Path, dstPath, p.exts, p.mode, p.readOnly)...)
+	}
+	if cfg.MergeLyrics && m.Path == g.Keep.Path && source.IsLocalPath(m.Path) {
+		var donors []string
+		for _, d := range g.Duplicates {
+			if source.IsLocalPath(d.Path) {
+				donors = append(donors, d.Path)
+			}
+		}
+		if from, err := sidecar.MergeLyrics(keeper, dstPath, donors); err != nil {
+			slog.Warn("写入歌词失败", "path", dstPath, "err", err)
+		} else if from != "" {
+			p.lyricsMerged++
+			if cfg.Verbose {
+				slog.Info("歌词合并", "from", from, "dst", dstPath)
+			}
+		}
+	}
+}
+
+// handleDuplicate 处理组内的重复文件 d：执行 on-duplicate 钩子，原地去重时核对后移除，并写入报告。
+// changed 表示 d 在决策后被改动；backups 为本组替换升级时备份的旧版本
+func (p *processor) handleDuplicate(g dedup.Group, d dedup.Member, changed bool, backups map[string]string, gi *groupItems) {
+	item := report.ReportItem{FilePath: d.Path, Size: d.Size, Verify: d.Integrity, KeptPath: g.Keep.Path, Distance: d.Distance}
+	if p.inRef[d.Path] {
+		// 参考库内部的重复（或人工固定保留了源文件时的参考库文件）：不执行钩子，也不移除
+		item.Action = report.ActionInRef
+		gi.add(item)
+		return
+	}
+	if changed {
+		item.Action = report.ActionChanged
+	}
+	if p.cfg.Sidecars && source.IsLocalPath(d.Path) {
+		if sc, err := sidecar.Find(d.Path, p.exts); err == nil {
+			item.Sidecars = sc.Own
+		}
+	}
+	if bak, ok := backups[d.Path]; ok {
+		item.NewPath, item.Action = bak, report.ActionBackup
+	}
+	// 原地去重：先执行 on-duplicate 钩子（钩子仍能读到文件），再移除
+	p.fireHook(hooks.Event{Event: hooks.EventDuplicate, Path: d.Path, Size: d.Size,
+		Fingerprint: fmt.Sprintf("%016x", d.FP), GroupID: g.ID, KeptPath: g.Keep.Path, Distance: d.Distance})
+	if p.remover != nil && !changed && source.IsLocalPath(d.Path) {
+		if why, keeper := p.verifyBeforeRemove(d, g.Keep); why != "" {
+			item.Action = report.ActionChanged
+			if keeper {
+				item.Action = report.ActionKeeperChanged
+			}
+		} else if action, where, ok := p.removeDuplicate(d, g.Keep.Path, item.Sidecars); ok {
+			item.Action, item.NewPath = action, where
+		}
+	}
+	gi.add(item)
+}
+
+// removeDuplicate 原地移除重复文件及其专属伴随文件，返回报告中的处理动作与文件被移到的位置
+func (p *processor) removeDuplicate(d dedup.Member, keptPath string, own []string) (action, where string, ok bool) {
+	where, err := p.remover.Remove(d.Path, keptPath, d.Size)
+	if err != nil {
+		slog.Error("移除重复文件失败", "path", d.Path, "method", p.remover.Method(), "err", err)
+		p.fireHook(hooks.Event{Event: hooks.EventError, Path: d.Path, Size: d.Size, KeptPath: keptPath, Error: err.Error()})
+		return "", "", false
+	}
+	for _, s := range own {
+		if _, err := p.remover.Remove(s, keptPath, 0); err != nil {
+			slog.Warn("移除伴随文件失败", "path", s, "err", err)
+		}
+	}
+	p.removed[d.Path] = true
+	p.removedCount++
+	p.removedBytes += d.Size
+	if p.cfg.Verbose {
+		slog.Info("已移除重复文件", "path", d.Path, "method", p.remover.Method())
+	}
+	switch p.remover.Method() {
+	case removal.MethodTrash:
+		return report.ActionTrashed, where, true
+	case removal.MethodQuarantine:
+		return report.ActionQuarantined, where, true
+	}
+	return report.ActionDeleted, "", true
+}
+
+// recordProvenance 为放入目标目录的文件记下来源（-provenance 写入清单，-provenance-xattr 写入扩展属性）
+func (p *processor) recordProvenance(m dedup.FileMeta, dstPath string) {
+	cfg := p.cfg
+	root, rel := provenance.Source(p.rootOf(m.Path), m.Path)
+	rec := provenance.Record{Path: dstPath, SourceRoot: root, SourcePath: rel, RunID: p.runID, Mode: string(p.mode), Size: m.Size}
+	if !source.IsLocalPath(m.Path) {
+		rec.Mode = string(copyutil.ModeCopy) // 远程文件总是复制
+	}
+	if cfg.Provenance && !p.provFailed {
+		if p.provW == nil {
+			w, err := provenance.Open(cfg.Dst)
+			if err != nil {
+				warnf("%v，本次不记录来源", err)
+				p.provFailed = true
+				return
+			}
+			p.provW = w
+			atExit = append(atExit, func() {
+				if err := w.Close(); err != nil {
+					warnf("关闭来源清单失败: %v", err)
+				}
+			})
+		}
+		if err := p.provW.Add(rec); err != nil {
+			warnf("写入来源清单失败: %v", err)
+			p.provFailed = true
+		}
+	}
+	if cfg.ProvenanceXattr && !p.xattrFailed {
+		if err := provenance.SetXattr(dstPath, rec); err != nil {
+			slog.Warn("写入来源扩展属性失败（之后不再尝试）", "path", dstPath, "err", err)
+			p.xattrFailed = true
+		}
+	}
+}
+
+// addReport 补全报告行的来源、运行编号、哈希（以及按需的时长与码率）后写入报告
+func (p *processor) addReport(item report.ReportItem) {
+	item.SourceRoot, item.RunID = p.rootOf(item.FilePath), p.runID
+	item.SHA256 = p.exactRes.Hash[item.FilePath]
+	if p.needMedia && source.IsLocalPath(item.FilePath) {
+		if secs, err := fingerprint.ProbeDuration(item.FilePath); err == nil && secs > 0 {
+			item.Duration = secs
+			item.Bitrate = int(float64(item.Size)*8/secs/1000 + 0.5)
+		}
+	}
+	if err := p.reportW.Add(item); err != nil && !p.reportErr {
+		p.reportErr = true
+		warnf("写入报告失败: %v", err)
+	}
+}
+
+// changedSince 返回文件自计算指纹以来的变化说明（未记录快照或未变化时为空串）
+func (p *processor) changedSince(m dedup.FileMeta) string {
+	if !m.Stamp.Recorded() {
+		return ""
+	}
+	why, err := m.Stamp.Changed(m.Path)
+	if err != nil {
+		why = err.Error()
+	}
+	if why != "" {
+		p.changedCount++
+		slog.Warn("文件在决策后被改动，跳过", "path", m.Path, "reason", why)
+	}
+	return why
+}
+
+// hashChanged 对算过完整哈希的文件（大小与其它文件相同，见 -exact-hash）重新计算并比对，
+// 捕捉大小、修改时间与首尾数据都没变的改写
+func (p *processor) hashChanged(m dedup.FileMeta) string {
+	want := p.exactRes.Hash[m.Path]
+	if want == "" || !source.IsLocalPath(m.Path) {
+		return ""
+	}
+	why := ""
+	if d, err := audit.HashFile(m.Path); err != nil {
+		why = err.Error()
+	} else if d.SHA256 != want {
+		why = "内容哈希已改变"
+	}
+	if why != "" {
+		p.changedCount++
+		slog.Warn("文件在决策后被改动，跳过", "path", m.Path, "reason", why)
+	}
+	return why
+}
+
+// verifyBeforeRemove 在移除重复文件前最后核对一次，与媒体管理程序等同时改动资料库的进程竞争时
+// 不会删掉最后一份：保留文件必须仍然存在且未被改动（无论是否开启 -detect-changes），
+// 重复文件必须仍与记录的大小、快照与哈希一致。keeper 为 true 表示问题出在保留文件上。
+func (p *processor) verifyBeforeRemove(d dedup.Member, keep dedup.FileMeta) (why string, keeper bool) {
+	if source.IsLocalPath(keep.Path) {
+		if _, err := os.Stat(keep.Path); err != nil {
+			why = "保留文件已不存在"
+		} else if w := p.changedSince(keep); w != "" {
+			why = "保留文件已改动: " + w
+		}
+		if why != "" {
+			p.keeperGoneCount++
+			slog.Warn("保留文件在移除重复文件前已不存在或被改动，不移除", "path", d.Path, "keep", keep.Path, "reason", why)
+			return why, true
+		}
+	}
+	if info, err := os.Stat(d.Path); err != nil {
+		why = err.Error()
+	} else if info.Size() != d.Size {
+		why = fmt.Sprintf("大小由 %d 变为 %d", d.Size, info.Size())
+	}
+	if why != "" {
+		p.changedCount++
+		slog.Warn("文件在决策后被改动，跳过", "path", d.Path, "reason", why)
+		return why, false
+	}
+	if why = p.changedSince(d.FileMeta); why == "" {
+		why = p.hashChanged(d.FileMeta)
+	}
+	return why, false
+}
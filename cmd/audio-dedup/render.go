@@ -0,0 +1,119 @@
+// file: cmd/audio-dedup/render.go
+// package: main
+//
+// 逐组生成的辅助输出：试听片段（-previews）、波形缩略图（-thumbnails）、频谱差异图（-spectro-diff）
+// 与设备同步计划（-sync-plan）。成员序号与文件名在三种输出之间保持一致。
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	"deduplicateMusic/internal/dedup"
+	"deduplicateMusic/internal/fingerprint"
+	"deduplicateMusic/internal/preview"
+	"deduplicateMusic/internal/spectro"
+	"deduplicateMusic/internal/syncplan"
+)
+
+// spectroMaxSeconds 为绘制频谱差异图时最多解码的时长（秒），避免超长文件拖慢报告生成
+const spectroMaxSeconds = 600
+
+// renderSpectroDiffs 为组内与保留文件距离较大的重复文件绘制频谱差异图
+func (p *processor) renderSpectroDiffs(g dedup.Group) {
+	var keep *spectro.Spectrogram
+	for i, d := range g.Duplicates {
+		if d.Distance == 0 || d.Distance*2 < p.cfg.Threshold {
+			continue // 只为边缘匹配绘图，距离很小的匹配无需解释
+		}
+		if keep == nil {
+			samples, err := fingerprint.DecodePCM(g.Keep.Path, spectroMaxSeconds)
+			if err != nil {
+				warnf("频谱差异图解码失败: %v", err)
+				return
+			}
+			sp := spectro.Compute(samples)
+			keep = &sp
+		}
+		samples, err := fingerprint.DecodePCM(d.Path, spectroMaxSeconds)
+		if err != nil {
+			warnf("频谱差异图解码失败: %v", err)
+			continue
+		}
+		sp := spectro.Compute(samples)
+		lag := spectro.Align(*keep, sp, spectro.MaxLagFrames(10, fingerprint.SampleRate))
+		index := 1 + len(g.Protected) + i // 与试听片段/缩略图的成员序号一致
+		name := strings.TrimSuffix(preview.ClipName(g.ID, index, "diff", baseName(d.Path)), ".ogg") + ".png"
+		if err := spectro.WritePNG(filepath.Join(p.cfg.SpectroDiff, name), spectro.DiffImage(*keep, sp, lag)); err != nil {
+			warnf("写频谱差异图失败: %v", err)
+			continue
+		}
+		p.spectroCount++
+	}
+}
+
+// renderThumbnails 为组内每个成员写出波形缩略图
+func (p *processor) renderThumbnails(g dedup.Group) {
+	members := append([]dedup.FileMeta{g.Keep}, g.Protected...)
+	roles := make([]string, len(members))
+	for i := range roles {
+		roles[i] = "keep"
+	}
+	for _, d := range g.Duplicates {
+		members = append(members, d.FileMeta)
+		roles = append(roles, "duplicate")
+	}
+	for i, m := range members {
+		name := strings.TrimSuffix(preview.ClipName(g.ID, i, roles[i], baseName(m.Path)), ".ogg") + ".svg"
+		if err := preview.WriteWaveform(filepath.Join(p.cfg.Thumbnails, name), m.Envelope); err != nil {
+			warnf("写波形缩略图失败: %v", err)
+			continue
+		}
+		p.thumbCount++
+	}
+}
+
+// renderPreviews 为组内每个成员截取试听片段
+func (p *processor) renderPreviews(g dedup.Group) {
+	type member struct{ path, role string }
+	members := []member{{g.Keep.Path, "keep"}}
+	for _, m := range g.Protected {
+		members = append(members, member{m.Path, "keep"})
+	}
+	for _, d := range g.Duplicates {
+		members = append(members, member{d.Path, "duplicate"})
+	}
+	for i, m := range members {
+		out := filepath.Join(p.cfg.Previews, preview.ClipName(g.ID, i, m.role, baseName(m.path)))
+		if err := preview.Render(m.path, out, p.cfg.PreviewSeconds); err != nil {
+			warnf("%v", err)
+			continue
+		}
+		p.clips = append(p.clips, preview.Clip{GroupID: g.ID, Source: m.path, Role: m.role, File: out})
+	}
+}
+
+// planSync 把只在主库或只在设备上出现的曲目加入同步计划
+func (p *processor) planSync(g dedup.Group) {
+	members := append([]dedup.FileMeta{g.Keep}, g.Protected...)
+	for _, d := range g.Duplicates {
+		members = append(members, d.FileMeta)
+	}
+	hasLib, hasDev := false, false
+	for _, m := range members {
+		if p.onDevice[m.Path] {
+			hasDev = true
+		} else {
+			hasLib = true
+		}
+	}
+	// 保留文件即该曲目的最佳版本；设备与主库规则保证两侧都有时 Keep 来自主库
+	switch {
+	case hasLib && !hasDev && p.syncDirs[syncplan.ToDevice]:
+		p.syncItems = append(p.syncItems, syncplan.Item{Direction: syncplan.ToDevice, Source: g.Keep.Path,
+			Target: syncplan.Target(p.cfg.Src, p.devRoot, g.Keep.Path), Size: g.Keep.Size})
+	case hasDev && !hasLib && p.syncDirs[syncplan.ToLibrary]:
+		p.syncItems = append(p.syncItems, syncplan.Item{Direction: syncplan.ToLibrary, Source: g.Keep.Path,
+			Target: syncplan.Target(p.devRoot, p.cfg.Src, g.Keep.Path), Size: g.Keep.Size})
+	}
+}
@@ -0,0 +1,470 @@
+// file: cmd/audio-dedup/run.go
+// package: main
+//
+// 一次去重运行的状态与准备工作：main 解析参数后由 newRun 注册只读目录、解析匹配与保留策略、
+// 加载人工覆盖、获取运行锁并校验报告参数，之后依次调用 scan（scan.go）、fingerprint（fingerprint.go），
+// 由 processor（process.go）逐组处理，最后 finish（finish.go）输出摘要与各类报告。
+package main
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"deduplicateMusic/internal/albums"
+	"deduplicateMusic/internal/audit"
+	"deduplicateMusic/internal/budget"
+	"deduplicateMusic/internal/cache"
+	"deduplicateMusic/internal/calibrate"
+	"deduplicateMusic/internal/config"
+	"deduplicateMusic/internal/copyutil"
+	"deduplicateMusic/internal/dedup"
+	"deduplicateMusic/internal/errbudget"
+	"deduplicateMusic/internal/exact"
+	"deduplicateMusic/internal/fingerprint"
+	"deduplicateMusic/internal/hooks"
+	"deduplicateMusic/internal/lock"
+	"deduplicateMusic/internal/memlimit"
+	"deduplicateMusic/internal/musiclib"
+	"deduplicateMusic/internal/overrides"
+	"deduplicateMusic/internal/progress"
+	"deduplicateMusic/internal/quota"
+	"deduplicateMusic/internal/removal"
+	"deduplicateMusic/internal/report"
+	"deduplicateMusic/internal/rules"
+	"deduplicateMusic/internal/source"
+	"deduplicateMusic/internal/spill"
+	"deduplicateMusic/internal/syncplan"
+	"deduplicateMusic/internal/textnorm"
+	"deduplicateMusic/internal/tune"
+	"deduplicateMusic/pkg/audiodedup"
+)
+
+// run 为一次去重运行的状态；各阶段的结果保存在这里供后续阶段使用
+type run struct {
+	cfg          *config.Options
+	ctx          context.Context // 收到中断信号时取消
+	bar          *progress.Bar
+	jsonOut      *os.File // -summary-json / -report - 的输出（原 stdout）
+	start        time.Time
+	mode         copyutil.Mode
+	removeMethod removal.Method
+	timeBudget   *budget.Budget
+	stage        string // 当前阶段；timeBudget 为 nil 时也用于报告与检查点
+	stopped      string // 因中断或时间预算提前停止的原因
+	syncDirs     map[syncplan.Direction]bool
+	managedSafe  bool              // 音乐/iTunes 资料库安全模式：只生成报告与计划
+	destructive  bool              // 原地移除重复文件或移动保留文件，处理前需要确认
+	readOnly     copyutil.ReadOnly // 不允许写入的目录，传给所有写操作
+	signKey      []byte            // 审计记录的签名密钥
+
+	// 匹配与保留策略（见 setupMatching）
+	order         string
+	groupBy       string
+	needDuration  bool
+	policy        dedup.KeepPolicy
+	matcher       dedup.Matcher
+	ruleSet       *rules.Set
+	pinned        *overrides.Set
+	feedbackPaths map[string]bool // 反馈中涉及的文件（绝对路径），收集结果时保留其元数据以计算距离
+	opts          dedup.Options   // 分组参数（见 groupOptions）
+
+	governor       *memlimit.Governor
+	hookRunner     *hooks.Runner
+	checkpointPath string
+	journal        *budget.Journal // 处理日志，-resume 据此跳过已处理的分组
+	reportCols     []string
+	reportOpts     report.CSVOptions
+
+	// 扫描结果（见 scan）
+	src         source.Source
+	exts        []string
+	files       []string
+	entryOf     map[string]source.Entry // 扫描时得到的大小与修改时间，工作协程无需再 stat
+	skipped     []report.Skipped        // 无法访问的目录与文件，单独归类报告
+	onDevice    map[string]bool
+	devRoot     string
+	inDst       map[string]bool
+	inRef       map[string]bool
+	exactRes    exact.Result
+	followers   map[string][]string // 逐字节相同的文件：代表文件 → 复用其结果的其余成员
+	decodeFiles []string            // 需要解码的文件（不含 followers 中的成员）
+
+	// 指纹阶段（见 fingerprint）
+	fpCache            cache.Store
+	feedback           *calibrate.Store
+	feedbackProfile    string // 距离只在同一匹配配置下可比，反馈按配置分开统计
+	decisions          *cache.Decisions
+	limiter            *tune.Limiter
+	tuner              *tune.Tuner
+	failBudget         *errbudget.Budget
+	metas              []dedup.FileMeta // 默认保存在内存；指定 -spill-dir 时写入 store
+	store              *spill.Store
+	albumIndex         albums.Index
+	feedbackMeta       map[string]dedup.FileMeta // 反馈涉及的文件的元数据（键为绝对路径）
+	okCount, errCount  int
+	failedItems        []report.ReportItem // 处理失败的文件，同样列入报告
+	collectErr         error
+	fingerprinted      time.Time
+	fingerprintStopped bool
+}
+
+// newRun 完成处理前的准备工作，参数错误或无法获取运行锁时直接退出
+func newRun(ctx context.Context, cfg *config.Options, bar *progress.Bar, jsonOut *os.File) *run {
+	r := &run{cfg: cfg, ctx: ctx, bar: bar, jsonOut: jsonOut, removeMethod: cfg.RemoveMethod()}
+	r.mode, _ = copyutil.ParseMode(cfg.Mode) // 已由 Validate 校验
+	stageBudgets, err := budget.ParseStages(cfg.StageBudget)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	r.timeBudget = budget.New(cfg.MaxRuntime, stageBudgets)
+	var ok bool
+	if r.syncDirs, ok = syncplan.ParseDirections(cfg.SyncDirection); !ok {
+		fatalf("无效的 -sync-direction: %s", cfg.SyncDirection)
+	}
+	r.protect()
+
+	if cfg.AuditKey != "" {
+		k, err := audit.LoadKey(cfg.AuditKey)
+		if err != nil {
+			fatalf("读取审计签名密钥失败: %v", err)
+		}
+		r.signKey = k
+		cfg.Audit = true
+	}
+	r.setupMatching()
+	r.loadOverrides()
+
+	if cfg.MaxMemory != "" {
+		limit, err := memlimit.ParseSize(cfg.MaxMemory)
+		if err != nil {
+			fatalf("无效的 -max-memory: %v", err)
+		}
+		r.governor = memlimit.New(limit)
+	}
+	if cfg.IORate != "" {
+		rate, err := memlimit.ParseSize(cfg.IORate)
+		if err != nil {
+			fatalf("无效的 -io-rate: %v", err)
+		}
+		quota.SetIORate(int64(rate))
+	}
+	quota.SetMaxFFmpeg(cfg.MaxFFmpeg)
+	r.hookRunner = &hooks.Runner{OnKeep: cfg.OnKeep, OnDuplicate: cfg.OnDuplicate, OnError: cfg.OnError}
+
+	r.acquireLock()
+	r.setupReport()
+	if err := fingerprint.SetBackend(fingerprint.Backend(cfg.Decoder)); err != nil {
+		fatalf("%v", err)
+	}
+	slog.Info("解码后端", "backend", fingerprint.BackendSummary())
+
+	r.start = time.Now()
+	if cfg.Verbose {
+		slog.Info("开始音频去重", "src", cfg.Src, "dst", cfg.Dst, "workers", cfg.Workers, "threshold", cfg.Threshold,
+			"seconds", cfg.Seconds, "readonly_src", cfg.AssertReadOnlySrc, "kernel", dedup.KernelName())
+	}
+	return r
+}
+
+// stopReason 返回应在安全点停止的原因：收到中断信号或超出时间预算；否则返回空串
+func (r *run) stopReason() string {
+	if r.ctx.Err() != nil {
+		return "收到中断信号"
+	}
+	return r.timeBudget.Exceeded()
+}
+
+// fireHook 执行钩子，失败只记录警告
+func (r *run) fireHook(ev hooks.Event) {
+	if err := r.hookRunner.Fire(ev); err != nil {
+		warnf("%v", err)
+	}
+}
+
+// protect 确定只读目录（资料库安全模式、-assert-readonly-src、-ref），并在开始前检查
+// 运行锁、溢出目录、指纹缓存与各输出文件都不落在只读目录内
+func (r *run) protect() {
+	cfg := r.cfg
+	var err error
+	// 音乐/iTunes 资料库安全模式：文件由应用的数据库索引，不能在应用背后改动。
+	// 安全模式下源目录只读、不复制保留文件、不执行 on-duplicate 钩子，只生成报告与计划。
+	if root, ok := musiclib.Detect(cfg.Src); ok && !cfg.AllowManagedLibrary {
+		if cfg.InPlace {
+			fatalf("%s 由音乐/iTunes 资料库管理（%s），不能原地删除文件；请用 -music-plan 导出去重计划由应用删除", cfg.Src, root)
+		}
+		r.managedSafe = true
+		slog.Warn("源目录由音乐/iTunes 资料库管理，进入安全模式：只生成报告，不复制/删除文件；可用 -music-plan 导出去重计划", "src", cfg.Src, "library", root)
+		if r.readOnly, err = r.readOnly.Protect(cfg.Src); err != nil {
+			fatalf("注册只读源目录失败: %v", err)
+		}
+		if cfg.OnDuplicate != "" {
+			slog.Warn("安全模式：忽略 -on-duplicate 钩子")
+			cfg.OnDuplicate = ""
+		}
+	}
+	// 破坏性操作（原地移除重复文件、移动保留文件）在分组完成后确认；无法确认时在开始前就拒绝运行
+	r.destructive = !r.managedSafe && (cfg.InPlace || r.mode == copyutil.ModeMove)
+	if r.destructive && !cfg.Yes {
+		switch {
+		case cfg.SpillDir != "":
+			fatalf("-spill-dir 下无法在处理前汇总将移除的文件，-in-place / -mode move 需要加 -yes")
+		case !progress.IsTerminal(os.Stdin):
+			fatalf("标准输入不是终端，无法确认将移除的文件；-in-place / -mode move 需要加 -yes")
+		}
+	}
+
+	// 只读源保证：目标目录、报告所在的当前目录都不能落在源目录内
+	if cfg.AssertReadOnlySrc {
+		if copyutil.IsWithin(cfg.Src, cfg.Dst) {
+			fatalf("-assert-readonly-src: 目标目录 %s 位于源目录 %s 内，拒绝运行", cfg.Dst, cfg.Src)
+		}
+		if cwd, err := os.Getwd(); err == nil && copyutil.IsWithin(cfg.Src, cwd) {
+			fatalf("-assert-readonly-src: 报告将写入当前目录 %s，它位于源目录内，拒绝运行", cwd)
+		}
+		if r.readOnly, err = r.readOnly.Protect(cfg.Src); err != nil {
+			fatalf("注册只读源目录失败: %v", err)
+		}
+	}
+
+	// 参考资料库只读：其中的文件只参与比对
+	if cfg.Ref != "" {
+		if r.readOnly, err = r.readOnly.Protect(cfg.Ref); err != nil {
+			fatalf("注册只读参考目录失败: %v", err)
+		}
+	}
+
+	// 运行锁、溢出目录、指纹缓存与各输出文件都不能落在只读目录内，在开始前检查
+	writes := []string{filepath.Join(cfg.Dst, lock.FileName), cfg.SpillDir, cfg.Decisions,
+		cfg.DuOut, cfg.Previews, cfg.Thumbnails, cfg.SpectroDiff, cfg.MusicPlan, cfg.DeviceRemoveList, cfg.SyncPlan}
+	if !cfg.NoCache && !cache.IsRemote(cfg.Cache) {
+		writes = append(writes, cfg.Cache)
+	}
+	for _, out := range writes {
+		if out == "" {
+			continue
+		}
+		if err := r.readOnly.CheckWritable(out); err != nil {
+			fatalf("%v", err)
+		}
+	}
+}
+
+// setupMatching 加载插件，解析处理顺序、保留策略、匹配器、分组方式与规则文件
+func (r *run) setupMatching() {
+	cfg := r.cfg
+	var err error
+	// 加载插件后再解析策略/匹配器，使插件注册的名称可用
+	for _, p := range strings.Split(cfg.Plugins, ",") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		if err := audiodedup.LoadPlugin(p); err != nil {
+			fatalf("%v", err)
+		}
+	}
+	if dedup.PolicyUses(cfg.KeepPolicy, "priority") && strings.TrimSpace(cfg.PathPriority) == "" {
+		fatalf("保留策略 %q 需要用 -path-priority 指定目录优先级", cfg.KeepPolicy)
+	}
+	dedup.SetPathPriority(strings.Split(cfg.PathPriority, ","))
+	if r.order, err = dedup.ParseProcessOrder(cfg.Order); err != nil {
+		fatalf("%v", err)
+	}
+	if cfg.DurationTolerance < 0 {
+		fatalf("-duration-tolerance 不能为负数")
+	}
+	r.needDuration = dedup.NeedsDuration(cfg.KeepPolicy) || cfg.DurationTolerance > 0 || cfg.Review // 审核时显示码率
+	if r.policy, err = dedup.LookupKeepPolicy(cfg.KeepPolicy); err != nil {
+		fatalf("无效的保留策略 %q: %v（已注册: %s）", cfg.KeepPolicy, err, strings.Join(dedup.KeepPolicyNames(), ", "))
+	}
+	if cfg.SpeedTolerant && cfg.Matcher == "hamming" {
+		cfg.Matcher = "speed"
+	}
+	if cfg.Segments > 1 && strings.EqualFold(cfg.Metric, "hamming") {
+		cfg.Metric = "segments"
+	}
+	if r.matcher, err = lookupMatcher(cfg.Matcher, cfg.Metric, cfg.Threshold); err != nil {
+		fatalf("%v", err)
+	}
+	if !strings.EqualFold(cfg.Metric, "hamming") {
+		if cfg.ShardBits > 0 {
+			// 分片剪枝依赖"匹配 ⇒ 汉明距离 <= 阈值"，其它度量不满足
+			slog.Warn("非汉明距离度量时忽略 -shard-bits", "metric", cfg.Metric)
+			cfg.ShardBits = 0
+		}
+	}
+	if cfg.Classical {
+		r.matcher = dedup.TagAgreement(r.matcher)
+	}
+	if r.groupBy, err = dedup.ParseGroupBy(cfg.GroupBy); err != nil {
+		fatalf("%v", err)
+	}
+	if r.groupBy == dedup.GroupByTags {
+		r.matcher = dedup.TagMatcher(r.matcher)
+		if cfg.ShardBits > 0 {
+			// 同标签即匹配，不满足分片剪枝要求的"匹配 ⇒ 汉明距离 <= 阈值"
+			slog.Warn("-group-by=tags 时忽略 -shard-bits")
+			cfg.ShardBits = 0
+		}
+	}
+	switch cfg.CompilationPreference {
+	case "original", "compilation":
+		// 原专辑与合辑中的同一曲目：按配置优先保留其中一方，其余按原有策略
+		base := r.policy
+		wantComp := cfg.CompilationPreference == "compilation"
+		r.policy = dedup.KeepPolicyFunc(func(a, b dedup.FileMeta) bool {
+			ca, cb := a.Tags.IsCompilation(), b.Tags.IsCompilation()
+			if ca != cb {
+				return ca == wantComp
+			}
+			return base.Better(a, b)
+		})
+	case "none":
+	default:
+		fatalf("无效的 -compilation-preference: %s", cfg.CompilationPreference)
+	}
+	if cfg.TitlePatterns != "" {
+		if err := textnorm.LoadTitlePatterns(cfg.TitlePatterns); err != nil {
+			fatalf("加载标题模式失败: %v", err)
+		}
+	}
+	if cfg.Rules != "" {
+		if r.ruleSet, err = rules.Load(cfg.Rules); err != nil {
+			fatalf("加载规则文件失败: %v", err)
+		}
+		r.policy = r.ruleSet.PreferPolicy(r.policy)
+	}
+}
+
+// loadOverrides 加载覆盖文件、误判清单与确认清单；默认位置的文件不存在时忽略
+func (r *run) loadOverrides() {
+	cfg := r.cfg
+	if cfg.Overrides != "" {
+		pinned, err := overrides.Load(cfg.Overrides)
+		switch {
+		case errors.Is(err, fs.ErrNotExist) && cfg.Overrides == overrides.DefaultPath():
+			pinned = nil
+		case err != nil:
+			fatalf("加载覆盖文件失败: %v", err)
+		case cfg.Verbose:
+			keeps, aparts := pinned.Len()
+			slog.Info("已加载覆盖文件", "path", cfg.Overrides, "keeps", keeps, "apart_pairs", aparts)
+		}
+		r.pinned = pinned
+	}
+	if cfg.NeverMatch != "" {
+		if r.pinned == nil {
+			r.pinned = &overrides.Set{}
+		}
+		err := r.pinned.LoadNeverMatch(cfg.NeverMatch)
+		switch {
+		case errors.Is(err, fs.ErrNotExist) && cfg.NeverMatch == overrides.NeverMatchPath():
+		case err != nil:
+			fatalf("加载误判清单失败: %v", err)
+		case cfg.Verbose:
+			_, aparts := r.pinned.Len()
+			slog.Info("已加载误判清单（与覆盖文件合计）", "path", cfg.NeverMatch, "apart_pairs", aparts)
+		}
+	}
+	if cfg.Confirmed != "" {
+		if r.pinned == nil {
+			r.pinned = &overrides.Set{}
+		}
+		err := r.pinned.LoadConfirmed(cfg.Confirmed)
+		switch {
+		case errors.Is(err, fs.ErrNotExist) && cfg.Confirmed == overrides.ConfirmedPath():
+		case err != nil:
+			fatalf("加载确认清单失败: %v", err)
+		}
+	}
+	r.feedbackPaths = map[string]bool{}
+	if cfg.Feedback != "" {
+		for _, pair := range append(r.pinned.ApartPairs(), r.pinned.Confirmed...) {
+			r.feedbackPaths[pair[0]], r.feedbackPaths[pair[1]] = true, true
+		}
+	}
+}
+
+// acquireLock 获取目标目录的运行锁（防止两个进程同时写同一目标目录），读取上次运行的检查点并打开处理日志
+func (r *run) acquireLock() {
+	cfg := r.cfg
+	if err := os.MkdirAll(cfg.Dst, 0o755); err != nil {
+		fatalf("创建目标目录失败: %v", err)
+	}
+	runLock, err := lock.Acquire(filepath.Join(cfg.Dst, lock.FileName), cfg.Force)
+	if err != nil {
+		fatalf("无法获取运行锁: %v", err)
+	}
+	atExit = append(atExit, func() {
+		if err := runLock.Release(); err != nil {
+			warnf("释放运行锁失败: %v", err)
+		}
+	})
+	r.checkpointPath = filepath.Join(cfg.Dst, budget.CheckpointFile)
+	if cp, err := budget.ReadCheckpoint(r.checkpointPath); err == nil && !cfg.Resume {
+		slog.Warn("上次运行提前停止，本次将完整重新决策（-resume 可从中断处继续），已计算的指纹从缓存读取",
+			"started", cp.Started.Format(time.DateTime), "stage", cp.Stage, "reason", cp.Reason, "groups_done", cp.GroupsDone)
+	}
+	// 处理日志：每处理完一个分组追加一行，进程被终止后可用 -resume 继续
+	journal, prevGroups, err := budget.OpenJournal(filepath.Join(cfg.Dst, budget.JournalFile), cfg.Resume)
+	if err != nil {
+		warnf("无法打开处理日志，本次运行中断后无法续跑: %v", err)
+	} else {
+		r.journal = journal
+		atExit = append(atExit, func() { journal.Close() })
+	}
+	switch {
+	case cfg.Resume && prevGroups > 0:
+		slog.Info("从上次中断处继续，已处理完的分组沿用上次的结果", "groups_done", prevGroups)
+	case cfg.Resume:
+		slog.Info("没有可继续的运行记录，从头开始")
+	case prevGroups > 0:
+		slog.Warn("上次运行未完成，本次从头开始；-resume 可从中断处继续", "groups_done", prevGroups)
+	}
+}
+
+// setupReport 解析并校验报告的列、语言、格式与输出位置，在长时间运行开始前尽早报错
+func (r *run) setupReport() {
+	cfg := r.cfg
+	report.SetGzip(cfg.GzipReports)
+	reportCols, err := report.ParseColumns(cfg.ReportColumns)
+	if err == nil {
+		err = report.CSVOptions{Columns: reportCols, Lang: cfg.ReportLang}.Validate()
+	}
+	var reportFormats []report.Format
+	if err == nil {
+		reportFormats, err = report.ParseFormats(cfg.ReportFormat)
+	}
+	r.reportCols = reportCols
+	r.reportOpts = report.CSVOptions{Columns: reportCols, Lang: cfg.ReportLang, Formats: reportFormats,
+		Path: cfg.Report, Stdout: r.jsonOut, Disabled: cfg.NoReport}
+	if err == nil {
+		err = r.reportOpts.Validate()
+	}
+	if err != nil {
+		fatalf("%v", err)
+	}
+}
+
+// signature 返回分组的签名，用于保留决定与处理日志；含保留策略，改换策略后按新策略重新选择
+func (r *run) signature(g dedup.Group) string {
+	return g.Signature(r.cfg.KeepPolicy + "|" + r.cfg.PathPriority)
+}
+
+// rootOf 返回文件所在的根目录（源目录、设备、目标目录或参考库），用于来源追溯与报告
+func (r *run) rootOf(p string) string {
+	switch {
+	case r.onDevice[p]:
+		return r.devRoot
+	case r.inDst[p]:
+		return r.cfg.Dst
+	case r.inRef[p]:
+		return r.cfg.Ref
+	}
+	return r.cfg.Src
+}
@@ -0,0 +1,177 @@
+// file: cmd/audio-dedup/scan.go
+// package: main
+//
+// 扫描阶段：列出源目录（以及 -device、-upgrade-dst、-ref 指定的目录）中的音频文件，
+// 去掉同一物理文件的重复路径，检查解码器，并按需找出逐字节相同的文件以跳过解码。
+package main
+
+import (
+	"log/slog"
+
+	"deduplicateMusic/internal/budget"
+	"deduplicateMusic/internal/copyutil"
+	"deduplicateMusic/internal/exact"
+	"deduplicateMusic/internal/fingerprint"
+	"deduplicateMusic/internal/formats"
+	"deduplicateMusic/internal/report"
+	"deduplicateMusic/internal/source"
+)
+
+// scan 扫描所有输入目录，结果保存在 r.files、r.entryOf 等字段中
+func (r *run) scan() {
+	cfg := r.cfg
+	r.stage = budget.StageFingerprint
+	r.timeBudget.Begin(r.stage)
+	src, err := source.New(cfg.Src)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	r.exts = formats.Extensions() // 支持的扩展，见格式注册表
+	r.bar.Stage("扫描", 0)
+	entries, err := source.List(r.ctx, src, r.exts)
+	if err != nil {
+		scanFatal("扫描目录", err)
+	}
+	r.bar.Add(len(entries))
+	r.addScanSkips(src)
+	r.onDevice = map[string]bool{}
+	if cfg.Device != "" {
+		dev, err := source.New(cfg.Device)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		devEntries, err := source.List(r.ctx, dev, r.exts)
+		r.bar.Add(len(devEntries))
+		if err != nil {
+			scanFatal("扫描设备", err)
+		}
+		r.addScanSkips(dev)
+		for _, e := range devEntries {
+			r.onDevice[e.Path] = true
+		}
+		if cfg.Verbose {
+			slog.Info("设备扫描完成", "device", dev.Root(), "files", len(devEntries))
+		}
+		entries = append(entries, devEntries...)
+		r.devRoot = dev.Root()
+		src = source.Multi(src, dev)
+	}
+	r.src = src
+	// 同一物理文件经由符号链接、硬链接、绑定挂载等不同路径出现多次时只保留一份，避免与自身形成假分组
+	entries, aliases := source.DedupeSameFile(entries)
+	if len(aliases) > 0 {
+		slog.Warn("跳过与已扫描文件是同一物理文件的路径（符号链接、硬链接、绑定挂载或大小写不同的路径）", "count", len(aliases))
+		if cfg.Verbose {
+			for _, a := range aliases {
+				slog.Info("同一物理文件", "path", a.Path, "of", a.Of)
+			}
+		}
+	}
+	r.inDst = map[string]bool{}
+	if cfg.UpgradeDst {
+		dst, err := source.New(cfg.Dst)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		dstEntries, err := source.List(r.ctx, dst, r.exts)
+		r.bar.Add(len(dstEntries))
+		if err != nil {
+			scanFatal("扫描目标目录", err)
+		}
+		r.addScanSkips(dst)
+		for _, e := range dstEntries {
+			if copyutil.InBackupDir(e.Path) {
+				continue // 以前替换下来的旧版本
+			}
+			r.inDst[e.Path] = true
+			entries = append(entries, e)
+		}
+		if cfg.Verbose {
+			slog.Info("目标目录扫描完成", "dst", cfg.Dst, "files", len(r.inDst))
+		}
+	}
+	r.inRef = map[string]bool{}
+	if cfg.Ref != "" {
+		ref, err := source.New(cfg.Ref)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		refEntries, err := source.List(r.ctx, ref, r.exts)
+		r.bar.Add(len(refEntries))
+		if err != nil {
+			scanFatal("扫描参考目录", err)
+		}
+		r.addScanSkips(ref)
+		for _, e := range refEntries {
+			r.inRef[e.Path] = true
+			entries = append(entries, e)
+		}
+		slog.Info("参考目录中的文件只参与比对，不复制也不删除", "ref", cfg.Ref, "files", len(r.inRef))
+	}
+	r.files = make([]string, len(entries))
+	r.entryOf = make(map[string]source.Entry, len(entries))
+	for i, e := range entries {
+		r.files[i] = e.Path
+		r.entryOf[e.Path] = e
+	}
+	if len(r.files) == 0 {
+		fatalf("未在 %s 找到任何支持的音频文件", cfg.Src)
+	}
+	if cfg.Verbose {
+		slog.Info("扫描完成", "files", len(r.files))
+	}
+	// 解码器检查：在计算指纹之前指出无法解码的格式，而不是之后逐个文件报错
+	if missing, err := fingerprint.Preflight(r.files); err != nil {
+		warnf("%v", err)
+	} else if len(missing) > 0 {
+		for _, m := range missing {
+			warnf("解码器检查：%s", m)
+		}
+		if cfg.Strict {
+			fatalf("解码器检查未通过（-strict），请安装支持上述格式的 ffmpeg 或去掉 -strict")
+		}
+	}
+	r.findExact(entries)
+}
+
+// addScanSkips 把 s 中无法访问的目录 / 文件单独归类报告，而不是默默忽略
+func (r *run) addScanSkips(s source.Source) {
+	for _, sk := range source.SkippedOf(s) {
+		slog.Warn("跳过无法访问的目录", "path", sk.Path, "err", sk.Err)
+		r.skipped = append(r.skipped, report.NewSkipped(sk.Path, report.SkipStageScan, sk.Err))
+	}
+}
+
+// findExact 找出逐字节相同的文件（-exact-hash）：只解码代表文件，其余成员在 worker 中复用其结果
+func (r *run) findExact(entries []source.Entry) {
+	r.followers = map[string][]string{}
+	r.decodeFiles = r.files
+	if !r.cfg.ExactHash {
+		return
+	}
+	var local []exact.File
+	for _, e := range entries {
+		if source.IsLocalPath(e.Path) {
+			local = append(local, exact.File{Path: e.Path, Size: e.Size})
+		}
+	}
+	r.bar.Stage("哈希", 0)
+	r.exactRes = exact.Find(local, r.cfg.Workers)
+	r.followers = r.exactRes.Followers()
+	if len(r.exactRes.Sets) == 0 {
+		return
+	}
+	skip := map[string]bool{}
+	for _, set := range r.exactRes.Sets {
+		for _, p := range set[1:] {
+			skip[p] = true
+		}
+	}
+	r.decodeFiles = make([]string, 0, len(r.files)-len(skip))
+	for _, f := range r.files {
+		if !skip[f] {
+			r.decodeFiles = append(r.decodeFiles, f)
+		}
+	}
+	slog.Info("内容完全相同的文件跳过解码", "sets", len(r.exactRes.Sets), "skipped", len(skip))
+}
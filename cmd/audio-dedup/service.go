@@ -0,0 +1,96 @@
+// file: cmd/audio-dedup/service.go
+// package: main
+//
+// install-service 子命令：把 "--" 之后的去重参数写成本机的定时任务（systemd 用户单元 / launchd / Windows 任务计划），
+// 每天在指定时刻以当前工作目录运行一次。配合 -max-runtime 可把运行限制在夜间维护窗口内，
+// 配合指纹缓存只有新增/改动的文件需要重新解码。
+//
+//	audio-dedup install-service -at 03:00 -- -src /music -dst /music-dedup -max-runtime 4h
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"deduplicateMusic/internal/service"
+)
+
+// runInstallService 执行 install-service 子命令，返回进程退出码
+func runInstallService(args []string) int {
+	fs := flag.NewFlagSet("install-service", flag.ExitOnError)
+	name := fs.String("name", "audio-dedup", "单元 / 任务名")
+	at := fs.String("at", "03:00", "每天运行的时刻（HH:MM）")
+	target := fs.String("target", runtime.GOOS, "目标系统："+strings.Join(service.Targets, "、"))
+	dir := fs.String("dir", "", "单元文件写到该目录（默认为目标系统的用户单元目录；Windows 为当前目录）")
+	printOnly := fs.Bool("print", false, "只打印生成的单元文件，不写入")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "用法: %s install-service [选项] -- <去重参数>\n", filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	runArgs := fs.Args()
+	hasSrc := false
+	for _, a := range runArgs {
+		if a == "-src" || a == "--src" || strings.HasPrefix(a, "-src=") || strings.HasPrefix(a, "--src=") {
+			hasSrc = true
+		}
+	}
+	if !hasSrc {
+		fmt.Println("install-service 需要在 \"--\" 之后给出去重参数（至少包含 -src）")
+		fs.Usage()
+		return 2
+	}
+	hour, minute, err := service.ParseSchedule(*at)
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+	exe, err := os.Executable()
+	if err == nil {
+		exe, err = filepath.Abs(exe)
+	}
+	if err != nil {
+		fmt.Printf("无法确定可执行文件路径: %v\n", err)
+		return 2
+	}
+	if strings.Contains(exe, "go-build") {
+		fmt.Println("注意：当前是 go run 生成的临时可执行文件，请先 go build / go install 后再安装")
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		fmt.Printf("无法确定工作目录: %v\n", err)
+		return 2
+	}
+	home, _ := os.UserHomeDir()
+	cfg := service.Config{Name: *name, Exe: exe, Args: runArgs, WorkDir: wd, Hour: hour, Minute: minute}
+	files, activate, err := service.Generate(*target, cfg, home, *dir)
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+	for _, f := range files {
+		if *printOnly {
+			fmt.Printf("# %s\n%s\n", f.Path, f.Content)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(f.Path), 0o755); err != nil {
+			fmt.Printf("创建目录失败: %v\n", err)
+			return 1
+		}
+		if err := os.WriteFile(f.Path, []byte(f.Content), 0o644); err != nil {
+			fmt.Printf("写单元文件失败: %v\n", err)
+			return 1
+		}
+		fmt.Printf("已写出: %s\n", f.Path)
+	}
+	fmt.Printf("每天 %02d:%02d 在 %s 运行（报告写在该目录）。执行以下命令启用：\n", hour, minute, wd)
+	for _, c := range activate {
+		fmt.Printf("  %s\n", c)
+	}
+	return 0
+}
@@ -0,0 +1,80 @@
+// file: internal/albums/albums.go
+// package: albums
+//
+// 专辑完整度：按 (专辑艺术家, 专辑, 碟号) 汇总手头有哪些曲号，与标签记录的曲目总数比较，
+// 用于导入时跳过零散的单曲（例如只下载了某张专辑的一两首）。
+// 曲目总数只来自标签（TRACKTOTAL 或 "3/12"）；没有总数的专辑视为无法判断。
+// 不查询 MusicBrainz：离线运行，且多数抓轨软件已把总数写入标签。
+package albums
+
+import (
+	"strconv"
+
+	"deduplicateMusic/internal/dedup"
+	"deduplicateMusic/internal/tags"
+	"deduplicateMusic/internal/textnorm"
+)
+
+// Index 汇总各专辑（按碟）已有的曲目
+type Index struct {
+	discs map[string]*disc
+}
+
+type disc struct {
+	tracks map[int]bool
+	total  int
+}
+
+// key 返回专辑某一碟的键；没有专辑名或曲号时返回空串
+func key(m dedup.FileMeta) (string, tags.TrackID) {
+	if m.Tags.Album == "" {
+		return "", tags.TrackID{}
+	}
+	id := tags.ParseTrackID(m.Tags.Track, m.Tags.Disc, m.Path)
+	if !id.Known() {
+		return "", id
+	}
+	artist := m.Tags.AlbumArtist
+	if m.Tags.IsCompilation() {
+		artist = "various artists"
+	} else if artist == "" {
+		artist = m.Tags.Artist
+	}
+	return textnorm.Normalize(artist) + "\x00" + textnorm.Normalize(m.Tags.Album) + "\x00" + strconv.Itoa(id.Disc), id
+}
+
+// Add 记录一个文件
+func (x *Index) Add(m dedup.FileMeta) {
+	k, id := key(m)
+	if k == "" {
+		return
+	}
+	if x.discs == nil {
+		x.discs = map[string]*disc{}
+	}
+	d := x.discs[k]
+	if d == nil {
+		d = &disc{tracks: map[int]bool{}}
+		x.discs[k] = d
+	}
+	d.tracks[id.Track] = true
+	if n := m.Tags.TotalTracks(); n > d.total {
+		d.total = n
+	}
+}
+
+// Completeness 返回 m 所在专辑（碟）已有的曲目数与总数；无法判断时 ok 为 false
+func (x *Index) Completeness(m dedup.FileMeta) (have, total int, ok bool) {
+	k, _ := key(m)
+	d := x.discs[k]
+	if k == "" || d == nil || d.total == 0 {
+		return 0, 0, false
+	}
+	return len(d.tracks), d.total, true
+}
+
+// Enough 返回 m 所在专辑的完整度是否达到 minFraction；无法判断时视为达到
+func (x *Index) Enough(m dedup.FileMeta, minFraction float64) bool {
+	have, total, ok := x.Completeness(m)
+	return !ok || float64(have) >= minFraction*float64(total)
+}
@@ -0,0 +1,44 @@
+// file: internal/albums/albums_test.go
+// package: albums
+//
+// 测试按专辑/碟统计完整度：曲号去重、写法不同的专辑名合并、缺少总数时视为无法判断。
+package albums
+
+import (
+	"testing"
+
+	"deduplicateMusic/internal/dedup"
+	"deduplicateMusic/internal/tags"
+)
+
+func meta(album, track string) dedup.FileMeta {
+	return dedup.FileMeta{Path: track + ".flac", Tags: tags.Tags{AlbumArtist: "周杰倫", Album: album, Track: track}}
+}
+
+func TestCompleteness(t *testing.T) {
+	var x Index
+	for _, m := range []dedup.FileMeta{
+		meta("范特西", "1/10"),
+		meta("范特西", "2/10"),
+		meta("范特西", "2/10"), // 同一首的另一个版本
+		meta("范特西", "3"),
+		meta("七里香", "5/10"),
+		meta("无总数", "1"),
+	} {
+		x.Add(m)
+	}
+	x.Add(dedup.FileMeta{Path: "a.flac", Tags: tags.Tags{AlbumArtist: "周杰伦", Album: "范特西", Track: "4/10"}})
+
+	if have, total, ok := x.Completeness(meta("范特西", "1")); !ok || have != 4 || total != 10 {
+		t.Fatalf("范特西: %d/%d ok=%v", have, total, ok)
+	}
+	if x.Enough(meta("七里香", "5"), 0.5) {
+		t.Error("七里香只有 1/10，不应达到 50%")
+	}
+	if !x.Enough(meta("范特西", "1"), 0.4) {
+		t.Error("范特西 4/10 应达到 40%")
+	}
+	if _, _, ok := x.Completeness(meta("无总数", "1")); ok || !x.Enough(meta("无总数", "1"), 1) {
+		t.Error("没有曲目总数时应视为无法判断")
+	}
+}
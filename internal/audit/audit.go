@@ -0,0 +1,138 @@
+// file: internal/audit/audit.go
+// package: audit
+//
+// 审计记录：记录一次运行的参数、所有输入/输出文件及报告的 SHA-256，
+// 可选用本地密钥做 HMAC-SHA256 签名，便于事后证明自动去重任务改动了什么。
+package audit
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"deduplicateMusic/internal/quota"
+)
+
+// FileDigest 表示单个文件的摘要
+type FileDigest struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Output 表示一个输出文件及其来源
+type Output struct {
+	FileDigest
+	Source string `json:"source"`
+}
+
+// Record 是一次运行的审计记录
+type Record struct {
+	Tool       string       `json:"tool"`
+	StartedAt  time.Time    `json:"started_at"`
+	FinishedAt time.Time    `json:"finished_at"`
+	Args       []string     `json:"args"`
+	Src        string       `json:"src"`
+	Dst        string       `json:"dst"`
+	Inputs     []FileDigest `json:"inputs"`
+	Outputs    []Output     `json:"outputs"`
+	Report     *FileDigest  `json:"report,omitempty"`
+	// Signature 为对其余字段（Signature 置空后）JSON 的 HMAC-SHA256，十六进制
+	Signature string `json:"signature,omitempty"`
+}
+
+// HashFile 计算文件的 SHA-256 与大小
+func HashFile(path string) (FileDigest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return FileDigest{}, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, quota.Reader(f))
+	if err != nil {
+		return FileDigest{}, err
+	}
+	return FileDigest{Path: path, Size: n, SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// LoadKey 读取签名密钥文件（去掉首尾空白），空文件视为错误
+func LoadKey(path string) ([]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 {
+		return nil, errors.New("签名密钥为空")
+	}
+	return b, nil
+}
+
+// mac 计算记录（不含签名字段）的 HMAC
+func (r *Record) mac(key []byte) ([]byte, error) {
+	c := *r
+	c.Signature = ""
+	payload, err := json.Marshal(&c)
+	if err != nil {
+		return nil, err
+	}
+	m := hmac.New(sha256.New, key)
+	m.Write(payload)
+	return m.Sum(nil), nil
+}
+
+// Sign 用 key 对记录签名并写入 Signature 字段
+func (r *Record) Sign(key []byte) error {
+	sum, err := r.mac(key)
+	if err != nil {
+		return err
+	}
+	r.Signature = hex.EncodeToString(sum)
+	return nil
+}
+
+// Verify 校验签名是否与 key 匹配
+func (r *Record) Verify(key []byte) (bool, error) {
+	if r.Signature == "" {
+		return false, errors.New("审计记录未签名")
+	}
+	want, err := hex.DecodeString(r.Signature)
+	if err != nil {
+		return false, fmt.Errorf("签名格式错误: %w", err)
+	}
+	got, err := r.mac(key)
+	if err != nil {
+		return false, err
+	}
+	return hmac.Equal(want, got), nil
+}
+
+// Write 把记录以缩进 JSON 写到 path
+func Write(path string, r *Record) error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
+
+// Read 从 path 读取审计记录
+func Read(path string) (*Record, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r Record
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, fmt.Errorf("解析审计记录失败: %w", err)
+	}
+	return &r, nil
+}
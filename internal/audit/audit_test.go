@@ -0,0 +1,45 @@
+// file: internal/audit/audit_test.go
+// package: audit
+//
+// 测试签名与校验：同一密钥校验通过，记录被篡改或密钥不同则失败。
+package audit
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSignVerifyRoundTrip(t *testing.T) {
+	r := &Record{
+		Tool:      "audio-dedup",
+		StartedAt: time.Unix(1700000000, 0).UTC(),
+		Src:       "src",
+		Dst:       "dst",
+		Inputs:    []FileDigest{{Path: "src/a.mp3", Size: 3, SHA256: "abc"}},
+	}
+	key := []byte("secret")
+	if err := r.Sign(key); err != nil {
+		t.Fatalf("签名失败: %v", err)
+	}
+
+	// 写出再读回，签名应仍然有效
+	p := filepath.Join(t.TempDir(), "audit.json")
+	if err := Write(p, r); err != nil {
+		t.Fatalf("写审计记录失败: %v", err)
+	}
+	back, err := Read(p)
+	if err != nil {
+		t.Fatalf("读审计记录失败: %v", err)
+	}
+	if ok, err := back.Verify(key); err != nil || !ok {
+		t.Fatalf("期望签名校验通过，ok=%v err=%v", ok, err)
+	}
+	if ok, _ := back.Verify([]byte("other")); ok {
+		t.Fatalf("不同密钥不应校验通过")
+	}
+	back.Inputs[0].SHA256 = "def"
+	if ok, _ := back.Verify(key); ok {
+		t.Fatalf("篡改后的记录不应校验通过")
+	}
+}
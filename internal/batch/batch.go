@@ -0,0 +1,155 @@
+// file: internal/batch/batch.go
+// package: batch
+//
+// 批量模式（-batch）：把源目录的每个直接子目录（如按艺术家或年份整理的下载目录）作为独立的去重任务，
+// 各自的报告写在批次目录下的同名子目录中，最后把各任务的退出摘要合并成一张汇总表。
+// 任务本身由调用方执行（主程序以子进程运行自身），本包只负责列出任务与汇总结果。
+package batch
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"deduplicateMusic/internal/report"
+)
+
+// Jobs 返回 src 下作为任务的直接子目录名（按名称排序）：隐藏目录与 skip 中的目录（如位于源目录内的
+// 目标目录、批次报告目录）除外
+func Jobs(src string, skip ...string) ([]string, error) {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return nil, err
+	}
+	skipped := map[string]bool{}
+	for _, p := range skip {
+		if abs, err := filepath.Abs(p); err == nil {
+			skipped[abs] = true
+		}
+	}
+	var jobs []string
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		if abs, err := filepath.Abs(filepath.Join(src, e.Name())); err == nil && skipped[abs] {
+			continue
+		}
+		jobs = append(jobs, e.Name())
+	}
+	sort.Strings(jobs)
+	return jobs, nil
+}
+
+// Limits 为每个任务的资源配额（-workers、-max-ffmpeg、-io-rate），由各任务进程分别执行
+type Limits struct {
+	Workers   int    // 并发 worker 数
+	MaxFFmpeg int    // 同时运行的 ffmpeg 子进程上限，0 表示不限制
+	IORate    string // 读取速率上限（如 50MB，每秒），空表示不限制
+}
+
+// String 返回配额的简短描述，如 "workers=4 max-ffmpeg=2 io-rate=50MB/s"
+func (l Limits) String() string {
+	ff, rate := "不限", "不限"
+	if l.MaxFFmpeg > 0 {
+		ff = strconv.Itoa(l.MaxFFmpeg)
+	}
+	if l.IORate != "" {
+		rate = l.IORate + "/s"
+	}
+	return fmt.Sprintf("workers=%d max-ffmpeg=%s io-rate=%s", l.Workers, ff, rate)
+}
+
+// Result 为一个任务的结果
+type Result struct {
+	Name     string            // 子目录名
+	ExitCode int               // 子进程退出码：0 完成，3 因时间预算提前停止，其它为失败
+	Err      string            // 失败原因（无法启动、退出摘要无法解析等）
+	Summary  report.RunSummary // 任务的退出摘要；失败时可能为空
+	Limits   Limits            // 任务运行时的资源配额
+}
+
+// Failed 返回任务是否失败（提前停止不算失败）
+func (r Result) Failed() bool { return r.Err != "" || (r.ExitCode != 0 && r.ExitCode != 3) }
+
+// Total 合并各任务的计数与字节数；耗时取各任务之和，报告路径留空
+func Total(results []Result) report.RunSummary {
+	var t report.RunSummary
+	for _, r := range results {
+		s := r.Summary
+		if !s.Started.IsZero() && (t.Started.IsZero() || s.Started.Before(t.Started)) {
+			t.Started = s.Started
+		}
+		t.Files += s.Files
+		t.Fingerprinted += s.Fingerprinted
+		t.Errors += s.Errors
+		t.Skipped += s.Skipped
+		t.SkippedPermission += s.SkippedPermission
+		t.Groups += s.Groups
+		t.Kept += s.Kept
+		t.Duplicates += s.Duplicates
+		t.Copied += s.Copied
+		t.Upgraded += s.Upgraded
+		t.Changed += s.Changed
+		t.Removed += s.Removed
+		t.KeptBytes += s.KeptBytes
+		t.DuplicateBytes += s.DuplicateBytes
+		t.CopiedBytes += s.CopiedBytes
+		t.RemovedBytes += s.RemovedBytes
+		t.CacheHits += s.CacheHits
+		t.CacheMisses += s.CacheMisses
+		t.ElapsedSeconds += s.ElapsedSeconds
+		t.FingerprintSeconds += s.FingerprintSeconds
+		t.ProcessSeconds += s.ProcessSeconds
+		if s.Stopped != "" && t.Stopped == "" {
+			t.Stopped = s.Stopped
+		}
+	}
+	return t
+}
+
+// WriteCSV 写出汇总表：每个任务一行（含该任务的资源配额，0 与空值表示不限制），最后一行为合计
+func WriteCSV(w io.Writer, results []Result) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"folder", "status", "files", "groups", "duplicates", "duplicate_bytes", "copied", "removed", "errors", "elapsed_seconds",
+		"workers", "max_ffmpeg", "io_rate", "report", "error"})
+	row := func(name, status string, s report.RunSummary, limits []string, errMsg string) {
+		cw.Write(append(append([]string{name, status, strconv.Itoa(s.Files), strconv.Itoa(s.Groups), strconv.Itoa(s.Duplicates),
+			strconv.FormatInt(s.DuplicateBytes, 10), strconv.Itoa(s.Copied), strconv.Itoa(s.Removed), strconv.Itoa(s.Errors),
+			strconv.FormatFloat(s.ElapsedSeconds, 'f', 1, 64)}, limits...), s.Report, errMsg))
+	}
+	for _, r := range results {
+		row(r.Name, r.Status(), r.Summary, []string{strconv.Itoa(r.Limits.Workers), strconv.Itoa(r.Limits.MaxFFmpeg), r.Limits.IORate}, r.Err)
+	}
+	row("TOTAL", "", Total(results), []string{"", "", ""}, "")
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteText 以文本表格输出汇总
+func WriteText(w io.Writer, results []Result) {
+	fmt.Fprintf(w, "== 批量任务汇总：%d 个子目录 ==\n", len(results))
+	fmt.Fprintf(w, "  %-24s %-8s %8s %8s %12s %8s\n", "子目录", "状态", "文件", "重复", "可回收", "复制")
+	for _, r := range results {
+		s := r.Summary
+		fmt.Fprintf(w, "  %-24s %-8s %8d %8d %12s %8d\n", r.Name, r.Status(), s.Files, s.Duplicates, report.HumanBytes(s.DuplicateBytes), s.Copied)
+	}
+	t := Total(results)
+	fmt.Fprintf(w, "  %-24s %-8s %8d %8d %12s %8d\n", "合计", "", t.Files, t.Duplicates, report.HumanBytes(t.DuplicateBytes), t.Copied)
+}
+
+// Status 返回任务状态：ok、stopped（提前停止）或 failed
+func (r Result) Status() string {
+	switch {
+	case r.Failed():
+		return "failed"
+	case r.ExitCode == 3:
+		return "stopped"
+	}
+	return "ok"
+}
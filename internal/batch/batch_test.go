@@ -0,0 +1,60 @@
+// file: internal/batch/batch_test.go
+// package: batch
+//
+// 测试批量模式的任务列表（跳过隐藏目录与指定目录）与结果汇总。
+package batch
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"deduplicateMusic/internal/report"
+)
+
+func TestJobs(t *testing.T) {
+	src := t.TempDir()
+	for _, d := range []string{"b", "a", ".cache", "out"} {
+		if err := os.Mkdir(filepath.Join(src, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(src, "x.mp3"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	jobs, err := Jobs(src, filepath.Join(src, "out"))
+	if err != nil || strings.Join(jobs, ",") != "a,b" {
+		t.Fatalf("Jobs = %v, %v", jobs, err)
+	}
+}
+
+func TestTotal(t *testing.T) {
+	results := []Result{
+		{Name: "a", Summary: report.RunSummary{Files: 3, Duplicates: 2, DuplicateBytes: 100, Copied: 1}, Limits: Limits{Workers: 4, MaxFFmpeg: 2, IORate: "50MB"}},
+		{Name: "b", ExitCode: 3, Summary: report.RunSummary{Files: 5, Duplicates: 1, DuplicateBytes: 50, Stopped: "max-runtime"}},
+		{Name: "c", ExitCode: 1, Err: "退出码 1"},
+	}
+	tot := Total(results)
+	if tot.Files != 8 || tot.Duplicates != 3 || tot.DuplicateBytes != 150 || tot.Copied != 1 || tot.Stopped != "max-runtime" {
+		t.Fatalf("Total = %+v", tot)
+	}
+	if s := []string{results[0].Status(), results[1].Status(), results[2].Status()}; strings.Join(s, ",") != "ok,stopped,failed" {
+		t.Fatalf("Status = %v", s)
+	}
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 || !strings.HasPrefix(lines[4], "TOTAL,,8,") || !strings.HasPrefix(lines[3], "c,failed,") {
+		t.Fatalf("汇总表:\n%s", buf.String())
+	}
+	if !strings.Contains(lines[1], ",4,2,50MB,") {
+		t.Fatalf("汇总表缺少任务配额: %s", lines[1])
+	}
+	if got := results[0].Limits.String(); got != "workers=4 max-ffmpeg=2 io-rate=50MB/s" {
+		t.Fatalf("Limits = %q", got)
+	}
+}
@@ -0,0 +1,116 @@
+// file: internal/budget/budget.go
+// package: budget
+//
+// 运行时间预算：整次运行的总时限（-max-runtime）与各阶段的时限（-stage-budget）。
+// 超出预算时调用方在安全点（两个文件 / 两个分组之间）停止，完成已做的工作后写出检查点，
+// 适合 NAS 上按夜间维护窗口分批运行。
+package budget
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 阶段名
+const (
+	StageFingerprint = "fingerprint" // 扫描并计算指纹
+	StageProcess     = "process"     // 分组、复制与生成报告
+)
+
+// Stages 为可设置预算的阶段
+var Stages = []string{StageFingerprint, StageProcess}
+
+// ParseStages 解析 "fingerprint=2h,process=30m" 形式的阶段预算
+func ParseStages(spec string) (map[string]time.Duration, error) {
+	out := map[string]time.Duration{}
+	for _, part := range strings.Split(spec, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		name, val, ok := strings.Cut(part, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !ok || !known(name) {
+			return nil, fmt.Errorf("无效的阶段预算 %q（格式 阶段=时长，阶段可选 %s）", part, strings.Join(Stages, "、"))
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(val))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("无效的阶段预算 %q：时长应为正数，如 90m、2h", part)
+		}
+		out[name] = d
+	}
+	return out, nil
+}
+
+func known(stage string) bool {
+	for _, s := range Stages {
+		if s == stage {
+			return true
+		}
+	}
+	return false
+}
+
+// Budget 跟踪总时限与当前阶段的时限；nil 表示不限时
+type Budget struct {
+	mu       sync.Mutex
+	runEnd   time.Time // 零值表示总时间不限
+	max      time.Duration
+	stages   map[string]time.Duration
+	stage    string
+	stageEnd time.Time
+	now      func() time.Time
+}
+
+// New 从现在开始计时；maxRuntime <= 0 且没有阶段预算时返回 nil
+func New(maxRuntime time.Duration, stages map[string]time.Duration) *Budget {
+	if maxRuntime <= 0 && len(stages) == 0 {
+		return nil
+	}
+	b := &Budget{max: maxRuntime, stages: stages, now: time.Now}
+	if maxRuntime > 0 {
+		b.runEnd = b.now().Add(maxRuntime)
+	}
+	return b
+}
+
+// Begin 进入阶段 stage，开始计算该阶段的预算
+func (b *Budget) Begin(stage string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stage, b.stageEnd = stage, time.Time{}
+	if d, ok := b.stages[stage]; ok {
+		b.stageEnd = b.now().Add(d)
+	}
+}
+
+// Stage 返回当前阶段
+func (b *Budget) Stage() string {
+	if b == nil {
+		return ""
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stage
+}
+
+// Exceeded 返回超出预算的原因；未超出时返回空串
+func (b *Budget) Exceeded() string {
+	if b == nil {
+		return ""
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	if !b.runEnd.IsZero() && !now.Before(b.runEnd) {
+		return fmt.Sprintf("已达到总运行时限 %s", b.max)
+	}
+	if !b.stageEnd.IsZero() && !now.Before(b.stageEnd) {
+		return fmt.Sprintf("%s 阶段已达到时间预算 %s", b.stage, b.stages[b.stage])
+	}
+	return ""
+}
@@ -0,0 +1,119 @@
+// file: internal/budget/budget_test.go
+// package: budget
+//
+// 测试阶段预算解析、总时限与阶段时限的判定，以及检查点与处理日志的读写。
+package budget
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseStages(t *testing.T) {
+	got, err := ParseStages(" fingerprint=2h, Process=30m ")
+	if err != nil || got[StageFingerprint] != 2*time.Hour || got[StageProcess] != 30*time.Minute {
+		t.Fatalf("ParseStages = %v, %v", got, err)
+	}
+	for _, bad := range []string{"copy=1h", "fingerprint", "process=-1m", "process=soon"} {
+		if _, err := ParseStages(bad); err == nil {
+			t.Fatalf("%q 应报错", bad)
+		}
+	}
+}
+
+func TestBudgetExceeded(t *testing.T) {
+	if New(0, nil) != nil || (*Budget)(nil).Exceeded() != "" {
+		t.Fatal("没有任何预算时应不限时")
+	}
+	clock := time.Unix(0, 0)
+	b := New(time.Hour, map[string]time.Duration{StageProcess: 10 * time.Minute})
+	b.now = func() time.Time { return clock }
+	b.runEnd = clock.Add(time.Hour)
+
+	b.Begin(StageFingerprint)
+	clock = clock.Add(50 * time.Minute)
+	if r := b.Exceeded(); r != "" {
+		t.Fatalf("未超出预算: %s", r)
+	}
+	b.Begin(StageProcess)
+	clock = clock.Add(5 * time.Minute)
+	if r := b.Exceeded(); r != "" {
+		t.Fatalf("未超出预算: %s", r)
+	}
+	clock = clock.Add(5 * time.Minute)
+	if r := b.Exceeded(); r == "" {
+		t.Fatal("总时限已到应停止")
+	}
+
+	b = New(0, map[string]time.Duration{StageProcess: time.Minute})
+	b.now = func() time.Time { return clock }
+	b.Begin(StageProcess)
+	clock = clock.Add(time.Minute)
+	if r := b.Exceeded(); r == "" || b.Stage() != StageProcess {
+		t.Fatal("阶段预算已到应停止")
+	}
+}
+
+func TestCheckpointRoundTrip(t *testing.T) {
+	p := filepath.Join(t.TempDir(), CheckpointFile)
+	want := Checkpoint{Stage: StageProcess, Reason: "r", GroupsDone: 2, Processed: []string{"a.mp3", "b.flac"}}
+	if err := WriteCheckpoint(p, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadCheckpoint(p)
+	if err != nil || got.Stage != want.Stage || got.GroupsDone != 2 || len(got.Processed) != 2 {
+		t.Fatalf("ReadCheckpoint = %+v, %v", got, err)
+	}
+}
+
+func TestJournalResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), JournalFile)
+	type item struct{ Path string }
+	j, prev, err := OpenJournal(path, false)
+	if err != nil || prev != 0 {
+		t.Fatalf("OpenJournal = %d, %v", prev, err)
+	}
+	if err := j.Record("g1", []item{{"a"}, {"b"}}); err != nil {
+		t.Fatal(err)
+	}
+	j.Close()
+	// 模拟被终止时写了一半的行
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	f.WriteString(`{"sig":"g2","ite`)
+	f.Close()
+
+	j, prev, err = OpenJournal(path, true)
+	if err != nil || prev != 1 || j.Resumable() != 1 {
+		t.Fatalf("续跑 OpenJournal = %d, %d, %v", prev, j.Resumable(), err)
+	}
+	var got []item
+	if !j.Done("g1", &got) || len(got) != 2 || got[1].Path != "b" {
+		t.Fatalf("Done(g1) = %v", got)
+	}
+	if j.Done("g2", &got) {
+		t.Fatal("不完整的行不应视为已处理")
+	}
+	if err := j.Record("g3", []item{{"c"}}); err != nil {
+		t.Fatal(err)
+	}
+	j.Close()
+	j, _, _ = OpenJournal(path, true)
+	if !j.Done("g3", &got) || len(got) != 1 {
+		t.Fatal("续跑后追加的记录应能读回")
+	}
+	j.Close()
+
+	// 不续跑时清空旧记录
+	j, prev, err = OpenJournal(path, false)
+	if err != nil || prev != 2 || j.Resumable() != 0 {
+		t.Fatalf("重新开始 OpenJournal = %d, %d, %v", prev, j.Resumable(), err)
+	}
+	if err := j.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("Remove 后文件仍存在: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+// file: internal/budget/checkpoint.go
+// package: budget
+//
+// 检查点：因时间预算提前停止时写到目标目录，记录停止的阶段、原因与已完成的工作，
+// 下次运行启动时提示；完整运行结束后删除。已计算的指纹由指纹缓存保存，下次运行无需重新解码；
+// 已处理完的分组由处理日志（见 journal.go）记录，-resume 时沿用。
+package budget
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// CheckpointFile 为目标目录中的检查点文件名
+const CheckpointFile = ".audio-dedup-checkpoint.json"
+
+// Checkpoint 记录一次提前停止的运行
+type Checkpoint struct {
+	Stage         string    `json:"stage"`  // 停止时所处的阶段
+	Reason        string    `json:"reason"` // 停止原因
+	Started       time.Time `json:"started"`
+	Stopped       time.Time `json:"stopped"`
+	Files         int       `json:"files"`               // 扫描到的文件数
+	Fingerprinted int       `json:"fingerprinted"`       // 已计算指纹的文件数
+	GroupsDone    int       `json:"groups_done"`         // 已处理的分组数
+	Processed     []string  `json:"processed,omitempty"` // 已处理分组中的全部源文件
+	Report        string    `json:"report,omitempty"`    // 本次（部分）报告
+}
+
+// WriteCheckpoint 写出检查点（先写临时文件再重命名）
+func WriteCheckpoint(path string, c Checkpoint) error {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(b, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// ReadCheckpoint 读取检查点；文件不存在时返回 os.ErrNotExist
+func ReadCheckpoint(path string) (Checkpoint, error) {
+	var c Checkpoint
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, fmt.Errorf("解析检查点失败: %w", err)
+	}
+	return c, nil
+}
@@ -0,0 +1,135 @@
+// file: internal/budget/journal.go
+// package: budget
+//
+// 处理日志：运行过程中每处理完一个分组就向目标目录追加一行（组签名与该组的报告行），
+// 进程被终止或超出时间预算时已完成的分组都有记录。带 -resume 重新运行时，签名相同的分组
+// 直接沿用记录的结果，不再复制、移动或移除文件；完整运行结束后删除。
+// 每行单独写入文件（不经缓冲），被终止时最多丢失正在处理的那个分组，末尾不完整的行读取时忽略。
+package budget
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// JournalFile 为目标目录中的处理日志文件名
+const JournalFile = ".audio-dedup-journal.jsonl"
+
+type journalEntry struct {
+	Sig   string          `json:"sig"`
+	Items json.RawMessage `json:"items"`
+}
+
+// Journal 为处理日志
+type Journal struct {
+	path string
+	f    *os.File
+	done map[string]json.RawMessage // 上次运行已处理完的分组
+}
+
+// OpenJournal 打开 path 处的处理日志。resume 为 true 时读入已有记录并在其后追加，
+// 否则清空已有记录从头开始；prev 为文件中原有的分组数（不论是否沿用）。
+func OpenJournal(path string, resume bool) (j *Journal, prev int, err error) {
+	j = &Journal{path: path, done: map[string]json.RawMessage{}}
+	if f, err := os.Open(path); err == nil {
+		sc := bufio.NewScanner(f)
+		sc.Buffer(nil, 16<<20)
+		for sc.Scan() {
+			var e journalEntry
+			if json.Unmarshal(sc.Bytes(), &e) != nil || e.Sig == "" {
+				continue
+			}
+			if resume {
+				j.done[e.Sig] = e.Items
+			}
+			prev++
+		}
+		err = sc.Err()
+		f.Close()
+		if err != nil {
+			return nil, 0, fmt.Errorf("读取处理日志 %s 失败: %w", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, 0, err
+	}
+	flag := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if !resume {
+		flag |= os.O_TRUNC
+	}
+	if j.f, err = os.OpenFile(path, flag, 0o644); err != nil {
+		return nil, 0, err
+	}
+	if resume {
+		// 上次被终止时写了一半的行：先补上换行，新记录从新的一行开始
+		if info, err := j.f.Stat(); err == nil && info.Size() > 0 {
+			last := make([]byte, 1)
+			if r, err := os.Open(path); err == nil {
+				if _, err := r.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+					j.f.Write([]byte{'\n'})
+				}
+				r.Close()
+			}
+		}
+	}
+	return j, prev, nil
+}
+
+// Done 查找上次运行中签名为 sig 的分组，找到时把记录的报告行解码到 items
+func (j *Journal) Done(sig string, items any) bool {
+	if j == nil {
+		return false
+	}
+	raw, ok := j.done[sig]
+	return ok && json.Unmarshal(raw, items) == nil
+}
+
+// Resumable 返回可沿用的分组数
+func (j *Journal) Resumable() int {
+	if j == nil {
+		return 0
+	}
+	return len(j.done)
+}
+
+// Record 记录一个处理完的分组及其报告行
+func (j *Journal) Record(sig string, items any) error {
+	if j == nil {
+		return nil
+	}
+	raw, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(journalEntry{Sig: sig, Items: raw})
+	if err != nil {
+		return err
+	}
+	if _, err := j.f.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Close 关闭处理日志（保留文件，供下次 -resume 使用）
+func (j *Journal) Close() error {
+	if j == nil || j.f == nil {
+		return nil
+	}
+	err := j.f.Close()
+	j.f = nil
+	return err
+}
+
+// Remove 关闭并删除处理日志，用于完整运行结束后
+func (j *Journal) Remove() error {
+	if j == nil {
+		return nil
+	}
+	j.Close()
+	if err := os.Remove(j.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
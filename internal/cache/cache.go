@@ -0,0 +1,402 @@
+// file: internal/cache/cache.go
+// package: cache
+//
+// 跨运行的指纹缓存：以 路径 + 大小 + 修改时间 + 指纹参数签名 为键保存解码分析结果，
+// 重复运行同一个大曲库时未改动的文件无需再次解码；长期使用的缓存可以由 db 子命令统计、清理与压缩。
+// 存储为 JSON Lines：新记录逐行追加（中途崩溃也只丢失缓冲中的几行），加载时后出现的行覆盖
+// 先前同一路径、同一签名的记录；过期行过多时在 Close 时整体重写压缩。
+//
+// 缓存文件可以由多个进程（包括网络路径上的其它机器）共用：写出与重写都持有缓存旁的锁文件
+// （<缓存>.lock，以独占创建实现，NFS、SMB 上同样有效）；重写前先重新读取文件，合并其它进程追加的记录，
+// 被其它进程重写替换后重新打开文件再追加。不便共用文件系统时可以改用 HTTP 服务，见 server.go。
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"deduplicateMusic/internal/fingerprint"
+	"deduplicateMusic/internal/lock"
+)
+
+// FileName 为默认缓存文件名
+const FileName = "fingerprints.jsonl"
+
+// Entry 为一条缓存记录
+type Entry struct {
+	Path     string
+	Size     int64
+	ModTime  int64  // 纳秒级 Unix 时间
+	Sig      string // 指纹参数签名（见 fingerprint.Options.Signature），参数不同的结果不能复用
+	Analysis fingerprint.Analysis
+}
+
+// key 为记录在表中的键：同一文件在不同参数下的结果各占一条，互不覆盖
+func (e Entry) key() string { return e.Path + "\x00" + e.Sig }
+
+// 缓冲达到 flushSize 或距上次写出超过 flushInterval 时写入文件
+const (
+	flushSize     = 64 << 10
+	flushInterval = time.Second
+)
+
+// 共用缓存的锁：等待 lockWait 仍未释放、且锁文件已超过 lockStale 未更新时视为持有者已崩溃，直接接管
+var (
+	lockWait  = 10 * time.Second
+	lockStale = time.Minute
+)
+
+// Cache 为并发安全的指纹缓存。mu 保护内存中的记录与缓冲，持有时间很短；
+// 文件的写出与重写由 fmu 串行化，等待锁文件时不持有 mu，其它协程仍可查询与记录。
+type Cache struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]Entry
+	lines   int              // 文件中的总行数（含被覆盖的旧行）
+	buf     bytes.Buffer     // 尚未写出的完整行；只在持有锁文件时写出，避免与其它进程的行交错
+	pruned  map[string]Entry // 被 Prune 删除的记录，重写时不从文件中恢复
+	flushed time.Time        // 上次把缓冲写入文件的时间
+	hits    int
+	misses  int
+
+	fmu sync.Mutex
+	f   *os.File
+}
+
+// DefaultPath 返回默认缓存位置（用户缓存目录下）
+func DefaultPath() string {
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "audio-dedup", FileName)
+	}
+	return filepath.Join(os.TempDir(), "audio-dedup-"+FileName)
+}
+
+// Store 为运行时使用的缓存：本地文件（Cache）或缓存服务（Remote）
+type Store interface {
+	Has(path string, size int64, mod time.Time, sig string) bool
+	Get(path string, size int64, mod time.Time, sig string) (fingerprint.Analysis, bool)
+	Put(path string, size int64, mod time.Time, sig string, a fingerprint.Analysis) error
+	Stats() (hits, misses int)
+	Close() error
+}
+
+// OpenStore 打开 location 处的缓存：http(s):// 地址连接缓存服务，否则打开本地文件
+func OpenStore(location string) (Store, error) {
+	if IsRemote(location) {
+		r, err := OpenRemote(location)
+		if err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+	c, err := Open(location)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Open 打开（不存在时创建）path 处的缓存
+func Open(path string) (*Cache, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	entries, lines, err := load(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &Cache{path: path, entries: entries, lines: lines, pruned: map[string]Entry{}, f: f}, nil
+}
+
+// load 读取 path 中的记录；文件不存在时返回空表
+func load(path string) (map[string]Entry, int, error) {
+	entries := map[string]Entry{}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return entries, 0, nil
+	} else if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+	lines := 0
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64<<10), 16<<20)
+	for sc.Scan() {
+		var e Entry
+		if json.Unmarshal(sc.Bytes(), &e) != nil {
+			continue // 崩溃时写了一半的行
+		}
+		entries[e.key()] = e
+		lines++
+	}
+	if err := sc.Err(); err != nil {
+		return nil, 0, fmt.Errorf("读取缓存 %s 失败: %w", path, err)
+	}
+	return entries, lines, nil
+}
+
+// Has 返回 path 在参数签名 sig 下是否有可用的缓存结果（不计入命中统计）
+func (c *Cache) Has(path string, size int64, mod time.Time, sig string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[Entry{Path: path, Sig: sig}.key()]
+	return ok && e.Size == size && e.ModTime == mod.UnixNano()
+}
+
+// Get 返回 path 在参数签名 sig 下的分析结果；大小或修改时间不一致时视为未命中
+func (c *Cache) Get(path string, size int64, mod time.Time, sig string) (fingerprint.Analysis, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[Entry{Path: path, Sig: sig}.key()]
+	if !ok || e.Size != size || e.ModTime != mod.UnixNano() {
+		c.misses++
+		return fingerprint.Analysis{}, false
+	}
+	c.hits++
+	return e.Analysis, true
+}
+
+// Put 记录 path 在参数签名 sig 下的分析结果
+func (c *Cache) Put(path string, size int64, mod time.Time, sig string, a fingerprint.Analysis) error {
+	return c.put(Entry{Path: path, Size: size, ModTime: mod.UnixNano(), Sig: sig, Analysis: a})
+}
+
+func (c *Cache) put(e Entry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	k := e.key()
+	c.entries[k] = e
+	delete(c.pruned, k)
+	c.lines++
+	c.buf.Write(append(b, '\n'))
+	// 定期写出缓冲：进程被终止时最多丢失最近一秒内的结果，续跑（-resume）无需重新解码
+	due := c.buf.Len() >= flushSize || time.Since(c.flushed) >= flushInterval
+	c.mu.Unlock()
+	// 已有协程在写出时不必排队：它写完后剩下的行留到下一次
+	if due && c.fmu.TryLock() {
+		defer c.fmu.Unlock()
+		return c.flush()
+	}
+	return nil
+}
+
+// flush 持有锁文件把缓冲写出；须持有 fmu，不得持有 mu
+func (c *Cache) flush() error {
+	l, err := acquire(c.path)
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.flushLocked()
+}
+
+// flushLocked 为已持有锁文件、fmu 与 mu 时的 flush；文件已被其它进程重写替换时先重新打开
+func (c *Cache) flushLocked() error {
+	if c.buf.Len() == 0 {
+		return nil
+	}
+	if err := c.reopenIfReplaced(); err != nil {
+		return err
+	}
+	if _, err := c.f.Write(c.buf.Bytes()); err != nil {
+		return err
+	}
+	c.buf.Reset()
+	c.flushed = time.Now()
+	return nil
+}
+
+// reopenIfReplaced 在 c.path 已不是 c.f 打开的文件（被其它进程重写后改名替换）时重新打开
+func (c *Cache) reopenIfReplaced() error {
+	cur, err := c.f.Stat()
+	if err != nil {
+		return err
+	}
+	if fi, err := os.Stat(c.path); err == nil && os.SameFile(cur, fi) {
+		return nil
+	}
+	f, err := os.OpenFile(c.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	c.f.Close()
+	c.f = f
+	return nil
+}
+
+// acquire 获取 path 旁的锁文件，等待其它进程释放；锁文件长时间未更新时视为陈旧锁并接管
+func acquire(path string) (*lock.Lock, error) {
+	lp := path + ".lock"
+	deadline := time.Now().Add(lockWait)
+	for {
+		l, err := lock.Acquire(lp, false)
+		if !errors.Is(err, lock.ErrLocked) {
+			return l, err
+		}
+		if time.Now().After(deadline) {
+			if fi, serr := os.Stat(lp); serr == nil && time.Since(fi.ModTime()) > lockStale {
+				return lock.Acquire(lp, true)
+			}
+			return nil, fmt.Errorf("等待指纹缓存锁超时: %w", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// Stats 返回本次运行的命中与未命中次数
+func (c *Cache) Stats() (hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// Len 返回缓存中的记录数
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Summary 为缓存文件的统计信息
+type Summary struct {
+	Entries int            // 有效记录数
+	Lines   int            // 文件中的总行数（含被覆盖的旧行）
+	Bytes   int64          // 缓存文件大小
+	Sigs    map[string]int // 各指纹参数签名的记录数
+}
+
+// Summary 写出缓冲后返回缓存的统计信息
+func (c *Cache) Summary() (Summary, error) {
+	c.fmu.Lock()
+	defer c.fmu.Unlock()
+	c.mu.Lock()
+	s := Summary{Entries: len(c.entries), Lines: c.lines, Sigs: map[string]int{}}
+	for _, e := range c.entries {
+		s.Sigs[e.Sig]++
+	}
+	c.mu.Unlock()
+	if err := c.flush(); err != nil {
+		return s, err
+	}
+	fi, err := os.Stat(c.path)
+	if err != nil {
+		return s, err
+	}
+	s.Bytes = fi.Size()
+	return s, nil
+}
+
+// Prune 删除 drop 返回 true 的记录，返回删除的条数；文件在 Vacuum 或 Close 时重写
+func (c *Cache) Prune(drop func(Entry) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for k, e := range c.entries {
+		if drop(e) {
+			delete(c.entries, k)
+			c.pruned[k] = e
+			n++
+		}
+	}
+	return n
+}
+
+// Vacuum 立即重写缓存文件，只保留每条记录的最新一行
+func (c *Cache) Vacuum() error {
+	c.fmu.Lock()
+	defer c.fmu.Unlock()
+	l, err := acquire(c.path)
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.flushLocked(); err != nil {
+		return err
+	}
+	if err := c.compact(); err != nil {
+		return err
+	}
+	return c.reopenIfReplaced()
+}
+
+// Close 写出缓冲中的记录；被覆盖的旧行超过有效记录数或有记录被 Prune 删除时重写文件
+func (c *Cache) Close() error {
+	c.fmu.Lock()
+	defer c.fmu.Unlock()
+	l, err := acquire(c.path)
+	if err != nil {
+		c.f.Close()
+		return err
+	}
+	defer l.Release()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	err = c.flushLocked()
+	if cerr := c.f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil || (c.lines <= 2*len(c.entries) && len(c.pruned) == 0) {
+		return err
+	}
+	return c.compact()
+}
+
+// compact 只保留每条记录的最新一行，写入临时文件后原子替换；须持有锁文件、fmu 与 mu，且缓冲已写出。
+// 以文件的当前内容为准（含其它进程追加的记录），去掉本进程 Prune 删除且之后未被改写的记录
+func (c *Cache) compact() error {
+	entries, _, err := load(c.path)
+	if err != nil {
+		return err
+	}
+	for k, e := range c.pruned {
+		if cur, ok := entries[k]; ok && cur.Size == e.Size && cur.ModTime == e.ModTime {
+			delete(entries, k)
+		}
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".fingerprints-*.jsonl")
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	c.entries, c.lines, c.pruned = entries, len(entries), map[string]Entry{}
+	return nil
+}
@@ -0,0 +1,300 @@
+// file: internal/cache/cache_test.go
+// package: cache
+//
+// 验证缓存跨 Open 持久化、命中统计、键不一致时不命中、不同参数签名的记录互不覆盖、崩溃留下的半行被忽略、
+// 旧行过多时重写压缩、Prune / Vacuum / Summary，两个进程共用缓存文件时重写不丢失对方追加的记录，
+// 等待锁文件时不阻塞其它协程，以及通过 HTTP 服务读写缓存。
+package cache
+
+import (
+	"bufio"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"deduplicateMusic/internal/fingerprint"
+)
+
+func TestPersistAndInvalidate(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "sub", FileName)
+	mod := time.Unix(1700000000, 123)
+	c, err := Open(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Put("/a.flac", 10, mod, "sig", fingerprint.Analysis{FP: 42, Segments: []uint64{1, 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Put("/a.flac", 10, mod, "other", fingerprint.Analysis{FP: 7}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// 模拟崩溃时写了一半的行
+	f, _ := os.OpenFile(p, os.O_APPEND|os.O_WRONLY, 0)
+	f.WriteString(`{"Path":"/b.mp3","Si`)
+	f.Close()
+
+	c, err = Open(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if a, ok := c.Get("/a.flac", 10, mod, "sig"); !ok || a.FP != 42 || len(a.Segments) != 2 {
+		t.Fatalf("应命中: %+v %v", a, ok)
+	}
+	// 另一组参数的结果单独保存，不会覆盖前一条
+	if a, ok := c.Get("/a.flac", 10, mod, "other"); !ok || a.FP != 7 {
+		t.Fatalf("另一签名应命中: %+v %v", a, ok)
+	}
+	for _, miss := range []struct {
+		size int64
+		mod  time.Time
+		sig  string
+	}{{11, mod, "sig"}, {10, mod.Add(time.Second), "sig"}, {10, mod, "v3"}} {
+		if _, ok := c.Get("/a.flac", miss.size, miss.mod, miss.sig); ok {
+			t.Fatalf("键不一致时不应命中: %+v", miss)
+		}
+	}
+	if !c.Has("/a.flac", 10, mod, "sig") || c.Has("/a.flac", 10, mod, "v3") || c.Has("/b.mp3", 10, mod, "sig") {
+		t.Fatal("Has 结果不正确")
+	}
+	// Has 不计入命中统计
+	if hits, misses := c.Stats(); hits != 2 || misses != 3 || c.Len() != 2 {
+		t.Fatalf("统计不正确: hits=%d misses=%d len=%d", hits, misses, c.Len())
+	}
+}
+
+func TestCompact(t *testing.T) {
+	p := filepath.Join(t.TempDir(), FileName)
+	c, err := Open(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		c.Put("/a.flac", int64(i), time.Unix(0, 0), "sig", fingerprint.Analysis{FP: uint64(i)})
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f, _ := os.Open(p)
+	defer f.Close()
+	lines := 0
+	for sc := bufio.NewScanner(f); sc.Scan(); {
+		lines++
+	}
+	if lines != 1 {
+		t.Fatalf("压缩后应只剩 1 行，实际 %d", lines)
+	}
+	c, _ = Open(p)
+	defer c.Close()
+	if a, ok := c.Get("/a.flac", 4, time.Unix(0, 0), "sig"); !ok || a.FP != 4 {
+		t.Fatalf("压缩后应保留最新记录: %+v %v", a, ok)
+	}
+}
+
+func TestPruneVacuumSummary(t *testing.T) {
+	p := filepath.Join(t.TempDir(), FileName)
+	c, err := Open(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Put("/a.flac", 1, time.Unix(0, 0), "v1", fingerprint.Analysis{FP: 1})
+	c.Put("/a.flac", 2, time.Unix(0, 0), "v1", fingerprint.Analysis{FP: 2})
+	c.Put("/b.mp3", 1, time.Unix(0, 0), "v2", fingerprint.Analysis{FP: 3})
+	c.Put("/gone.mp3", 1, time.Unix(0, 0), "v1", fingerprint.Analysis{FP: 4})
+	s, err := c.Summary()
+	if err != nil || s.Entries != 3 || s.Lines != 4 || s.Bytes == 0 || s.Sigs["v1"] != 2 || s.Sigs["v2"] != 1 {
+		t.Fatalf("统计不正确: %+v %v", s, err)
+	}
+	if n := c.Prune(func(e Entry) bool { return e.Path == "/gone.mp3" }); n != 1 {
+		t.Fatalf("应删除 1 条，实际 %d", n)
+	}
+	if err := c.Vacuum(); err != nil {
+		t.Fatal(err)
+	}
+	// Vacuum 后仍可继续写入
+	c.Put("/c.ogg", 1, time.Unix(0, 0), "v1", fingerprint.Analysis{FP: 5})
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	c, err = Open(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if s, _ := c.Summary(); s.Entries != 3 || s.Lines != 3 {
+		t.Fatalf("重写后应为 3 条 3 行: %+v", s)
+	}
+	if _, ok := c.Get("/gone.mp3", 1, time.Unix(0, 0), "v1"); ok {
+		t.Fatal("Prune 删除的记录不应保留")
+	}
+	if _, ok := c.Get("/c.ogg", 1, time.Unix(0, 0), "v1"); !ok {
+		t.Fatal("Vacuum 后写入的记录丢失")
+	}
+}
+
+func TestSharedCacheKeepsOtherWriters(t *testing.T) {
+	p := filepath.Join(t.TempDir(), FileName)
+	mod := time.Unix(0, 0)
+	a, err := Open(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Open(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Put("/a.flac", 1, mod, "sig", fingerprint.Analysis{FP: 1})
+	b.Put("/b.flac", 1, mod, "sig", fingerprint.Analysis{FP: 2})
+	if _, err := b.Summary(); err != nil { // 写出 b 的缓冲
+		t.Fatal(err)
+	}
+	// a 重写文件时应合并 b 已追加的记录
+	if err := a.Vacuum(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := a.Get("/b.flac", 1, mod, "sig"); !ok {
+		t.Fatal("重写后应包含其它进程追加的记录")
+	}
+	// b 的文件已被替换，之后的记录应写入新文件
+	b.Put("/c.flac", 1, mod, "sig", fingerprint.Analysis{FP: 3})
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	c, err := Open(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	for _, f := range []string{"/a.flac", "/b.flac", "/c.flac"} {
+		if _, ok := c.Get(f, 1, mod, "sig"); !ok {
+			t.Errorf("缺少 %s", f)
+		}
+	}
+	if _, err := os.Stat(p + ".lock"); !os.IsNotExist(err) {
+		t.Fatalf("锁文件应已释放: %v", err)
+	}
+}
+
+func TestWriteDoesNotBlockWhileWaitingForLock(t *testing.T) {
+	p := filepath.Join(t.TempDir(), FileName)
+	c, err := Open(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 另一进程持有锁文件：写出缓冲的协程等待锁时，其它协程的查询与记录不应被阻塞
+	if err := os.WriteFile(p+".lock", nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waiting := make(chan error, 1)
+	go func() {
+		_, err := c.Summary()
+		waiting <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	done := make(chan struct{})
+	go func() {
+		c.Put("/a.flac", 1, time.Unix(0, 0), "sig", fingerprint.Analysis{FP: 1})
+		c.Get("/a.flac", 1, time.Unix(0, 0), "sig")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("等待锁文件时 Put / Get 被阻塞")
+	}
+	os.Remove(p + ".lock")
+	if err := <-waiting; err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRemote(t *testing.T) {
+	c, err := Open(filepath.Join(t.TempDir(), FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	srv := httptest.NewServer(Handler(c))
+	defer srv.Close()
+	if !IsRemote(srv.URL) || IsRemote("/var/cache/fingerprints.jsonl") {
+		t.Fatal("IsRemote 结果不正确")
+	}
+
+	r, err := OpenRemote(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	mod := time.Unix(1700000000, 5)
+	if err := r.Put("/music/a.flac", 10, mod, "sig", fingerprint.Analysis{FP: 42}); err != nil {
+		t.Fatal(err)
+	}
+	// 写入的记录由服务端的缓存保存，其它客户端与服务端本身都能查到
+	if a, ok := c.Get("/music/a.flac", 10, mod, "sig"); !ok || a.FP != 42 {
+		t.Fatalf("服务端应有记录: %+v %v", a, ok)
+	}
+	if a, ok := r.Get("/music/a.flac", 10, mod, "sig"); !ok || a.FP != 42 {
+		t.Fatalf("应命中: %+v %v", a, ok)
+	}
+	if _, ok := r.Get("/music/a.flac", 11, mod, "sig"); ok {
+		t.Fatal("大小不一致时不应命中")
+	}
+	if _, ok := r.Get("/music/b.flac", 10, mod, "sig"); ok {
+		t.Fatal("没有的记录不应命中")
+	}
+	if !r.Has("/music/a.flac", 10, mod, "sig") || r.Has("/music/b.flac", 10, mod, "sig") {
+		t.Fatal("Has 结果不正确")
+	}
+	if hits, misses := r.Stats(); hits != 1 || misses != 2 {
+		t.Fatalf("统计不正确: hits=%d misses=%d", hits, misses)
+	}
+	if s, err := r.Summary(); err != nil || s.Entries != 1 || s.Sigs["sig"] != 1 {
+		t.Fatalf("统计不正确: %+v %v", s, err)
+	}
+	if _, err := OpenRemote("http://127.0.0.1:1"); err == nil {
+		t.Fatal("连接不上时应返回错误")
+	}
+}
+
+func TestDecisionsPersist(t *testing.T) {
+	p := filepath.Join(t.TempDir(), DecisionsFileName)
+	d, err := OpenDecisions(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Record("g1", "/a.flac")
+	d.Record("g1", "/a.flac") // 相同决定不追加新行
+	d.Record("g2", "/b.mp3")
+	d.Record("g2", "/c.mp3")
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	d, err = OpenDecisions(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	if k, ok := d.Keeper("g1"); !ok || k != "/a.flac" {
+		t.Fatalf("g1: %q %v", k, ok)
+	}
+	if k, _ := d.Keeper("g2"); k != "/c.mp3" {
+		t.Fatalf("g2 应为最新决定，实际 %q", k)
+	}
+	if _, ok := d.Keeper("g3"); ok {
+		t.Fatal("未记录的签名不应命中")
+	}
+	if d.lines != 3 {
+		t.Fatalf("应有 3 行，实际 %d", d.lines)
+	}
+}
@@ -0,0 +1,149 @@
+// file: internal/cache/decisions.go
+// package: cache
+//
+// 跨运行的保留决定：以组签名（见 dedup.Group.Signature）为键记录上次选出的保留文件，
+// 相同输入再次运行时沿用原决定，避免打平规则调整等原因导致保留文件来回变化。
+// 存储格式与指纹缓存相同（JSON Lines，后出现的行覆盖先前记录，旧行过多时 Close 重写）。
+package cache
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DecisionsFileName 为默认决定文件名（与指纹缓存放在同一目录）
+const DecisionsFileName = "decisions.jsonl"
+
+// Decision 为一条保留决定
+type Decision struct {
+	Sig  string // 组签名
+	Keep string // 保留文件路径
+	Time int64  // 记录时间（Unix 秒）
+}
+
+// Decisions 为并发安全的保留决定存储
+type Decisions struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]Decision
+	lines   int
+	f       *os.File
+	w       *bufio.Writer
+	applied int
+}
+
+// DefaultDecisionsPath 返回默认决定文件位置（与 DefaultPath 同目录）
+func DefaultDecisionsPath() string {
+	return filepath.Join(filepath.Dir(DefaultPath()), DecisionsFileName)
+}
+
+// OpenDecisions 打开（不存在时创建）path 处的决定文件
+func OpenDecisions(path string) (*Decisions, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	d := &Decisions{path: path, entries: map[string]Decision{}}
+	if f, err := os.Open(path); err == nil {
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			var e Decision
+			if json.Unmarshal(sc.Bytes(), &e) != nil || e.Sig == "" {
+				continue // 崩溃时写了一半的行
+			}
+			d.entries[e.Sig] = e
+			d.lines++
+		}
+		err := sc.Err()
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("读取决定文件 %s 失败: %w", path, err)
+		}
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	d.f, d.w = f, bufio.NewWriter(f)
+	return d, nil
+}
+
+// Keeper 返回签名 sig 上次记录的保留文件
+func (d *Decisions) Keeper(sig string) (string, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	e, ok := d.entries[sig]
+	return e.Keep, ok
+}
+
+// Record 记录签名 sig 的保留文件；与已有记录相同时不追加新行
+func (d *Decisions) Record(sig, keep string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if e, ok := d.entries[sig]; ok && e.Keep == keep {
+		return nil
+	}
+	e := Decision{Sig: sig, Keep: keep, Time: time.Now().Unix()}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	d.entries[sig] = e
+	d.lines++
+	_, err = d.w.Write(append(b, '\n'))
+	return err
+}
+
+// Applied 记一次按已有决定改换了保留文件（供运行结束时统计）
+func (d *Decisions) Applied() {
+	d.mu.Lock()
+	d.applied++
+	d.mu.Unlock()
+}
+
+// Stats 返回本次按已有决定改换保留文件的组数与文件中的决定总数
+func (d *Decisions) Stats() (applied, total int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.applied, len(d.entries)
+}
+
+// Close 写出缓冲中的记录；被覆盖的旧行超过有效记录数时重写文件
+func (d *Decisions) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	err := d.w.Flush()
+	if cerr := d.f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil || d.lines <= 2*len(d.entries) {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(d.path), ".decisions-*.jsonl")
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, e := range d.entries {
+		if err = enc.Encode(e); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	d.lines = len(d.entries)
+	return os.Rename(tmp.Name(), d.path)
+}
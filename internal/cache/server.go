@@ -0,0 +1,175 @@
+// file: internal/cache/server.go
+// package: cache
+//
+// 指纹缓存的 HTTP 服务：一台机器以 Handler 对外提供自己的缓存文件，其它机器用 Remote 查询与写入，
+// 家中几台机器因此共用一份指纹，不必各自重新解码。接口：
+//
+//	GET  /entry?path=...&sig=...  返回该路径在该参数签名下的记录（JSON），没有时 404
+//	POST /entry                   请求体为一条记录（JSON），写入缓存
+//	GET  /summary                 返回缓存统计（JSON）
+//
+// 服务不做身份验证，只应监听在可信的局域网内。记录仍以文件路径为键，各机器需以相同路径访问曲库。
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"deduplicateMusic/internal/fingerprint"
+)
+
+// IsRemote 返回 location 是否为缓存服务地址（http:// 或 https://）而不是本地文件
+func IsRemote(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// Handler 返回对外提供 c 的 HTTP 处理器
+func Handler(c *Cache) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /entry", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		c.mu.Lock()
+		e, ok := c.entries[Entry{Path: q.Get("path"), Sig: q.Get("sig")}.key()]
+		c.mu.Unlock()
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, e)
+	})
+	mux.HandleFunc("POST /entry", func(w http.ResponseWriter, r *http.Request) {
+		var e Entry
+		if err := json.NewDecoder(io.LimitReader(r.Body, 16<<20)).Decode(&e); err != nil || e.Path == "" {
+			http.Error(w, "无效的记录", http.StatusBadRequest)
+			return
+		}
+		if err := c.put(e); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /summary", func(w http.ResponseWriter, r *http.Request) {
+		s, err := c.Summary()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, s)
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// Remote 为通过 HTTP 访问的缓存服务，方法与 Cache 对应
+type Remote struct {
+	base   string
+	client *http.Client
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// OpenRemote 连接 base 处的缓存服务（见 Handler），连接不上时返回错误
+func OpenRemote(base string) (*Remote, error) {
+	r := &Remote{base: strings.TrimSuffix(base, "/"), client: &http.Client{Timeout: 30 * time.Second}}
+	if _, err := r.Summary(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Has 查询 path 在参数签名 sig 下是否有可用的缓存结果（不计入命中统计）
+func (r *Remote) Has(path string, size int64, mod time.Time, sig string) bool {
+	e, err := r.entry(path, sig)
+	return err == nil && e.Size == size && e.ModTime == mod.UnixNano()
+}
+
+// Get 查询 path 在参数签名 sig 下的分析结果；服务不可用时视为未命中
+func (r *Remote) Get(path string, size int64, mod time.Time, sig string) (fingerprint.Analysis, bool) {
+	e, err := r.entry(path, sig)
+	if err != nil || e.Size != size || e.ModTime != mod.UnixNano() {
+		r.misses.Add(1)
+		return fingerprint.Analysis{}, false
+	}
+	r.hits.Add(1)
+	return e.Analysis, true
+}
+
+// entry 取回 path 在参数签名 sig 下的记录
+func (r *Remote) entry(path, sig string) (Entry, error) {
+	var e Entry
+	err := r.do(http.MethodGet, "/entry?"+url.Values{"path": {path}, "sig": {sig}}.Encode(), nil, &e)
+	return e, err
+}
+
+// Put 把 path 在参数签名 sig 下的分析结果写入服务
+func (r *Remote) Put(path string, size int64, mod time.Time, sig string, a fingerprint.Analysis) error {
+	return r.put(Entry{Path: path, Size: size, ModTime: mod.UnixNano(), Sig: sig, Analysis: a})
+}
+
+func (r *Remote) put(e Entry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return r.do(http.MethodPost, "/entry", b, nil)
+}
+
+// Stats 返回本次运行的命中与未命中次数
+func (r *Remote) Stats() (hits, misses int) {
+	return int(r.hits.Load()), int(r.misses.Load())
+}
+
+// Summary 返回服务端缓存的统计信息
+func (r *Remote) Summary() (Summary, error) {
+	var s Summary
+	err := r.do(http.MethodGet, "/summary", nil, &s)
+	return s, err
+}
+
+// Close 释放空闲连接；记录已在 Put 时写入服务端
+func (r *Remote) Close() error {
+	r.client.CloseIdleConnections()
+	return nil
+}
+
+// errNotFound 表示服务端没有所查询的记录
+var errNotFound = errors.New("缓存中没有该记录")
+
+// do 发送请求，out 不为 nil 时把 JSON 响应解码到 out
+func (r *Remote) do(method, path string, body []byte, out any) error {
+	req, err := http.NewRequest(method, r.base+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return errNotFound
+	case resp.StatusCode >= 300:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("缓存服务 %s %s: %s %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+	case out != nil:
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+// file: internal/calibrate/calibrate.go
+// package: calibrate
+//
+// 阈值校准：跨运行累积用户对匹配的反馈——拒绝（误判清单、覆盖文件中的 apart）与确认
+// （确认清单、覆盖文件中固定保留文件所在的组）——连同每对文件的距离，按匹配配置（profile，
+// 距离度量与指纹参数，不同配置下的距离不可比）分别保存。Recommend 找出使
+// “阈值内的拒绝 + 阈值外的确认” 最少的阈值，与当前阈值不同时给出调整建议。
+package calibrate
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// FileName 为默认反馈文件名
+const FileName = "feedback.jsonl"
+
+// 反馈结论
+const (
+	Accepted = "accepted" // 确认为重复
+	Rejected = "rejected" // 标记为误判
+)
+
+// DefaultPath 返回默认反馈文件位置（用户配置目录下，与覆盖文件同目录）
+func DefaultPath() string {
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, "audio-dedup", FileName)
+	}
+	return ""
+}
+
+// Sample 为一对文件的反馈
+type Sample struct {
+	Profile  string
+	A, B     string // 两个路径（A < B）
+	Distance int
+	Verdict  string // Accepted 或 Rejected
+	Time     int64  // 最近一次记录的时间（Unix 秒）
+}
+
+func (s Sample) key() string { return s.Profile + "\x00" + s.A + "\x00" + s.B }
+
+// Store 为反馈存储：同一配置下同一对文件只保留最新的一条，重复运行不会重复计数
+type Store struct {
+	path    string
+	samples map[string]Sample
+	dirty   bool
+}
+
+// Open 读取 path 处的反馈（文件不存在时为空）
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, samples: map[string]Sample{}}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e Sample
+		if json.Unmarshal(sc.Bytes(), &e) != nil || e.A == "" {
+			continue
+		}
+		s.samples[e.key()] = e
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("读取反馈文件 %s 失败: %w", path, err)
+	}
+	return s, nil
+}
+
+// Record 记录一对文件的反馈（覆盖同一配置下同一对文件的旧记录）
+func (s *Store) Record(profile, a, b string, distance int, verdict string) {
+	if a > b {
+		a, b = b, a
+	}
+	e := Sample{Profile: profile, A: a, B: b, Distance: distance, Verdict: verdict, Time: time.Now().Unix()}
+	if old, ok := s.samples[e.key()]; ok && old.Distance == distance && old.Verdict == verdict {
+		return
+	}
+	s.samples[e.key()] = e
+	s.dirty = true
+}
+
+// Samples 返回 profile 下的全部反馈（按路径排序）
+func (s *Store) Samples(profile string) []Sample {
+	var out []Sample
+	for _, e := range s.samples {
+		if e.Profile == profile {
+			out = append(out, e)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].key() < out[j].key() })
+	return out
+}
+
+// Close 有新记录时把全部反馈重写到文件（先写临时文件再原子替换）
+func (s *Store) Close() error {
+	if !s.dirty {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".feedback-*.jsonl")
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(s.samples))
+	for k := range s.samples {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, k := range keys {
+		if err = enc.Encode(s.samples[k]); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	s.dirty = false
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// Advice 为阈值调整建议
+type Advice struct {
+	Threshold    int // 当前阈值
+	Suggested    int // 建议阈值
+	Rejected     int // 拒绝的对数
+	Accepted     int // 确认的对数
+	RejectedKept int // 建议阈值下仍会被合并的拒绝对数
+	AcceptedLost int // 建议阈值下不再合并的确认对数
+	MinRejected  int // 拒绝对的最小距离（没有拒绝时为 -1）
+}
+
+// Recommend 在 0..64 中找出误差（阈值内的拒绝 + 阈值外的确认）最少的阈值，误差相同时取离当前阈值最近者。
+// 只有误差严格小于当前阈值的误差时才返回建议。
+func Recommend(samples []Sample, threshold int) (Advice, bool) {
+	a := Advice{Threshold: threshold, MinRejected: -1}
+	var rej, acc [65]int // 各距离上的拒绝 / 确认对数
+	for _, s := range samples {
+		d := s.Distance
+		if d < 0 {
+			d = 0
+		} else if d > 64 {
+			d = 64
+		}
+		switch s.Verdict {
+		case Rejected:
+			rej[d]++
+			a.Rejected++
+			if a.MinRejected < 0 || d < a.MinRejected {
+				a.MinRejected = d
+			}
+		case Accepted:
+			acc[d]++
+			a.Accepted++
+		}
+	}
+	// errs(t) = 距离 <= t 的拒绝 + 距离 > t 的确认
+	errs := func(t int) (kept, lost int) {
+		for d := 0; d <= 64; d++ {
+			if d <= t {
+				kept += rej[d]
+			} else {
+				lost += acc[d]
+			}
+		}
+		return kept, lost
+	}
+	curKept, curLost := errs(threshold)
+	best, bestErr := threshold, curKept+curLost
+	for t := 0; t <= 64; t++ {
+		kept, lost := errs(t)
+		e := kept + lost
+		if e < bestErr || (e == bestErr && abs(t-threshold) < abs(best-threshold)) {
+			best, bestErr = t, e
+		}
+	}
+	a.Suggested = best
+	a.RejectedKept, a.AcceptedLost = errs(best)
+	return a, best != threshold && bestErr < curKept+curLost
+}
+
+// String 返回建议的说明，用于摘要
+func (a Advice) String() string {
+	if a.Suggested < a.Threshold {
+		return fmt.Sprintf("你标记为误判的 %d 对匹配集中在距离 %d 及以上——建议把阈值从 %d 调到 %d（之后仍会合并的误判 %d 对，会漏掉的已确认重复 %d 对）",
+			a.Rejected, a.MinRejected, a.Threshold, a.Suggested, a.RejectedKept, a.AcceptedLost)
+	}
+	return fmt.Sprintf("已确认的 %d 对重复中有部分距离超过当前阈值 %d——建议把阈值调到 %d（之后仍会合并的误判 %d 对，会漏掉的已确认重复 %d 对）",
+		a.Accepted, a.Threshold, a.Suggested, a.RejectedKept, a.AcceptedLost)
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
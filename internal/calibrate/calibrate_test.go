@@ -0,0 +1,62 @@
+// file: internal/calibrate/calibrate_test.go
+// package: calibrate
+//
+// 测试反馈跨 Open 持久化且同一对文件不重复计数，以及按拒绝 / 确认的距离分布给出阈值建议。
+package calibrate
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStorePersist(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "sub", FileName)
+	s, err := Open(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Record("p1", "/b", "/a", 7, Rejected)
+	s.Record("p1", "/a", "/b", 7, Rejected) // 同一对，不重复计数
+	s.Record("p1", "/a", "/c", 2, Accepted)
+	s.Record("p2", "/a", "/b", 3, Rejected)
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	s, err = Open(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := s.Samples("p1")
+	if len(got) != 2 || got[0].A != "/a" || got[0].B != "/b" || got[0].Verdict != Rejected {
+		t.Fatalf("Samples = %+v", got)
+	}
+}
+
+func TestRecommend(t *testing.T) {
+	var samples []Sample
+	for _, d := range []int{6, 7, 8, 8} {
+		samples = append(samples, Sample{Distance: d, Verdict: Rejected})
+	}
+	for _, d := range []int{0, 1, 3, 5, 7} {
+		samples = append(samples, Sample{Distance: d, Verdict: Accepted})
+	}
+	a, ok := Recommend(samples, 8)
+	if !ok || a.Suggested != 5 || a.RejectedKept != 0 || a.AcceptedLost != 1 || a.MinRejected != 6 {
+		t.Fatalf("建议不对: %+v %v", a, ok)
+	}
+	if !strings.Contains(a.String(), "从 8 调到 5") {
+		t.Fatalf("说明: %s", a.String())
+	}
+	if _, ok := Recommend(samples, 5); ok {
+		t.Fatal("当前阈值已是最优时不应给出建议")
+	}
+	if _, ok := Recommend(nil, 8); ok {
+		t.Fatal("没有反馈时不应给出建议")
+	}
+	// 确认的重复超出阈值时建议放宽
+	a, ok = Recommend([]Sample{{Distance: 10, Verdict: Accepted}, {Distance: 20, Verdict: Rejected}}, 8)
+	if !ok || a.Suggested != 10 {
+		t.Fatalf("应建议放宽到 10: %+v %v", a, ok)
+	}
+}
@@ -0,0 +1,267 @@
+// file: internal/checksum/checksum.go
+// package: checksum
+//
+// 完整性校验旁路文件：为去重后的目标目录逐目录写出
+//   - SHA256SUMS：与 sha256sum -c 兼容，检测任意文件的位衰减；
+//   - fingerprints.ffp：FLAC 指纹（STREAMINFO 中记录的解码后音频 MD5，格式 "文件名:md5"），
+//     与 metaflac --show-md5sum / 常见 ffp 校验工具兼容，标签改动不会使其失效。
+//
+// Verify 重新计算并比对这两类文件；ffp 校验需要 ffmpeg 解码，不可用时跳过。
+package checksum
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"deduplicateMusic/internal/audit"
+	"deduplicateMusic/internal/quota"
+)
+
+const (
+	SumsFile = "SHA256SUMS"
+	FFPFile  = "fingerprints.ffp"
+)
+
+// Write 为 root 下每个含文件的目录写出 SHA256SUMS，ffp 为 true 时对含 FLAC 的目录同时写出 fingerprints.ffp。
+// 以 "." 开头的文件（如运行锁）与旁路文件本身不参与。返回写出的目录数。
+func Write(root string, ffp bool) (int, error) {
+	dirs := 0
+	err := filepath.WalkDir(root, func(dir string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		names, err := dataFiles(dir)
+		if err != nil || len(names) == 0 {
+			return err
+		}
+		var sums, ffps bytes.Buffer
+		for _, name := range names {
+			p := filepath.Join(dir, name)
+			fd, err := audit.HashFile(p)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(&sums, "%s  %s\n", fd.SHA256, name)
+			if ffp && strings.EqualFold(filepath.Ext(name), ".flac") {
+				sum, _, err := FLACMD5(p)
+				if err != nil {
+					return fmt.Errorf("%s: %w", p, err)
+				}
+				if sum != "" {
+					fmt.Fprintf(&ffps, "%s:%s\n", name, sum)
+				}
+			}
+		}
+		if err := os.WriteFile(filepath.Join(dir, SumsFile), sums.Bytes(), 0o644); err != nil {
+			return err
+		}
+		if ffps.Len() > 0 {
+			if err := os.WriteFile(filepath.Join(dir, FFPFile), ffps.Bytes(), 0o644); err != nil {
+				return err
+			}
+		}
+		dirs++
+		return nil
+	})
+	return dirs, err
+}
+
+// dataFiles 返回目录中需要校验的普通文件名（已排序）
+func dataFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		n := e.Name()
+		if !e.Type().IsRegular() || strings.HasPrefix(n, ".") || n == SumsFile || n == FFPFile {
+			continue
+		}
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// FLACMD5 读取 FLAC STREAMINFO 中的音频 MD5 与采样位深；编码器未写入 MD5（全零）时返回空串
+func FLACMD5(path string) (string, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	var hdr [10]byte
+	if _, err := io.ReadFull(f, hdr[:4]); err != nil {
+		return "", 0, err
+	}
+	if string(hdr[:3]) == "ID3" {
+		// 部分工具会在 FLAC 前面加 ID3v2 标签，跳过
+		if _, err := io.ReadFull(f, hdr[4:]); err != nil {
+			return "", 0, err
+		}
+		size := int64(hdr[6])<<21 | int64(hdr[7])<<14 | int64(hdr[8])<<7 | int64(hdr[9])
+		if hdr[5]&0x10 != 0 {
+			size += 10
+		}
+		if _, err := f.Seek(10+size, io.SeekStart); err != nil {
+			return "", 0, err
+		}
+		if _, err := io.ReadFull(f, hdr[:4]); err != nil {
+			return "", 0, err
+		}
+	}
+	if string(hdr[:4]) != "fLaC" {
+		return "", 0, errors.New("不是 FLAC 文件")
+	}
+	var block [4 + 34]byte
+	if _, err := io.ReadFull(f, block[:]); err != nil {
+		return "", 0, err
+	}
+	if block[0]&0x7f != 0 {
+		return "", 0, errors.New("缺少 STREAMINFO")
+	}
+	info := block[4:]
+	bits := int(binary.BigEndian.Uint64(info[10:18])>>36&0x1f) + 1
+	sum := info[18:34]
+	if bytes.Equal(sum, make([]byte, 16)) {
+		return "", bits, nil
+	}
+	return hex.EncodeToString(sum), bits, nil
+}
+
+// decodedMD5 用 ffmpeg 解码 FLAC，按原位深输出 PCM 并计算 MD5（与 FLAC 编码器的计算方式一致）
+func decodedMD5(path string, bits int) (string, error) {
+	codec := map[int]string{8: "pcm_s8", 16: "pcm_s16le", 24: "pcm_s24le", 32: "pcm_s32le"}[bits]
+	if codec == "" {
+		return "", fmt.Errorf("不支持的位深 %d", bits)
+	}
+	release := quota.AcquireFFmpeg()
+	out, err := exec.Command("ffmpeg", "-v", "error", "-i", path, "-map", "0:a:0", "-c:a", codec, "-f", "md5", "-").Output()
+	release()
+	if err != nil {
+		return "", fmt.Errorf("ffmpeg 解码失败: %v", err)
+	}
+	return strings.TrimPrefix(strings.TrimSpace(string(out)), "MD5="), nil
+}
+
+// FLAC 解码校验结果
+const (
+	FLACOK      = "ok"      // 解码后 MD5 与 STREAMINFO 一致
+	FLACCorrupt = "corrupt" // 解码失败或 MD5 不一致
+	FLACNoMD5   = "no-md5"  // 编码器未写入 MD5，无法校验
+)
+
+// VerifyFLAC 完整解码 FLAC 并与 STREAMINFO 中的 MD5 比对（相当于 flac -t）。
+// 只有 ffmpeg 不可用等环境问题才返回 error；文件本身的问题体现在结果中。
+func VerifyFLAC(path string) (string, error) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return "", errors.New("ffmpeg 未找到，无法校验 FLAC")
+	}
+	want, bits, err := FLACMD5(path)
+	if err != nil {
+		return FLACCorrupt, nil
+	}
+	if want == "" {
+		return FLACNoMD5, nil
+	}
+	got, err := decodedMD5(path, bits)
+	if err != nil || !strings.EqualFold(got, want) {
+		return FLACCorrupt, nil
+	}
+	return FLACOK, nil
+}
+
+// Problem 为一个校验失败的文件
+type Problem struct {
+	Path   string
+	Reason string
+}
+
+// Result 为校验结果
+type Result struct {
+	Checked    int
+	Problems   []Problem
+	FFPSkipped bool // 存在 ffp 文件但 ffmpeg 不可用
+}
+
+// Verify 校验 root 下所有 SHA256SUMS 与 fingerprints.ffp
+func Verify(root string) (Result, error) {
+	var res Result
+	_, lookErr := exec.LookPath("ffmpeg")
+	err := filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		dir := filepath.Dir(p)
+		switch d.Name() {
+		case SumsFile:
+			return eachLine(p, func(line string) {
+				sum, name, ok := strings.Cut(line, " ")
+				if !ok {
+					return
+				}
+				name = strings.TrimPrefix(strings.TrimPrefix(name, " "), "*")
+				res.Checked++
+				fd, err := audit.HashFile(filepath.Join(dir, name))
+				switch {
+				case err != nil:
+					res.Problems = append(res.Problems, Problem{filepath.Join(dir, name), err.Error()})
+				case !strings.EqualFold(fd.SHA256, sum):
+					res.Problems = append(res.Problems, Problem{filepath.Join(dir, name), "SHA-256 不匹配"})
+				}
+			})
+		case FFPFile:
+			if lookErr != nil {
+				res.FFPSkipped = true
+				return nil
+			}
+			return eachLine(p, func(line string) {
+				i := strings.LastIndex(line, ":")
+				if i < 0 {
+					return
+				}
+				fp := filepath.Join(dir, line[:i])
+				res.Checked++
+				_, bits, err := FLACMD5(fp)
+				var got string
+				if err == nil {
+					got, err = decodedMD5(fp, bits)
+				}
+				switch {
+				case err != nil:
+					res.Problems = append(res.Problems, Problem{fp, err.Error()})
+				case !strings.EqualFold(got, line[i+1:]):
+					res.Problems = append(res.Problems, Problem{fp, "FLAC 音频 MD5 不匹配"})
+				}
+			})
+		}
+		return nil
+	})
+	return res, err
+}
+
+func eachLine(path string, fn func(string)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if line := strings.TrimRight(sc.Text(), "\r"); line != "" && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, ";") {
+			fn(line)
+		}
+	}
+	return sc.Err()
+}
@@ -0,0 +1,99 @@
+// file: internal/checksum/checksum_test.go
+// package: checksum
+//
+// 测试旁路文件的写出、SHA-256 校验发现被改动的文件，以及 STREAMINFO 中 MD5/位深的读取。
+package checksum
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeFLAC 构造只含 STREAMINFO 的最小 FLAC 头（16 bit，MD5 为 0x01..0x10）
+func fakeFLAC() []byte {
+	b := []byte("fLaC")
+	b = append(b, 0x80, 0, 0, 34) // 最后一个元数据块，类型 0，长度 34
+	info := make([]byte, 34)
+	// 采样率 44100(20 bit) | 声道-1=1(3 bit) | 位深-1=15(5 bit) | 样本数(36 bit)
+	v := uint64(44100)<<44 | uint64(1)<<41 | uint64(15)<<36
+	for i := 0; i < 8; i++ {
+		info[10+i] = byte(v >> (56 - 8*i))
+	}
+	for i := 0; i < 16; i++ {
+		info[18+i] = byte(i + 1)
+	}
+	return append(b, info...)
+}
+
+func TestFLACMD5(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "a.flac")
+	if err := os.WriteFile(p, fakeFLAC(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sum, bits, err := FLACMD5(p)
+	if err != nil || bits != 16 || sum != "0102030405060708090a0b0c0d0e0f10" {
+		t.Fatalf("FLACMD5 = %q, %d, %v", sum, bits, err)
+	}
+}
+
+func TestWriteVerify(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "album")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{
+		filepath.Join(root, "a.mp3"):    []byte("aaa"),
+		filepath.Join(root, ".lock"):    []byte("x"),
+		filepath.Join(sub, "b.flac"):    fakeFLAC(),
+		filepath.Join(sub, "cover.jpg"): []byte("jpg"),
+	}
+	for p, b := range files {
+		if err := os.WriteFile(p, b, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	n, err := Write(root, true)
+	if err != nil || n != 2 {
+		t.Fatalf("Write = %d, %v", n, err)
+	}
+	sums, _ := os.ReadFile(filepath.Join(root, SumsFile))
+	if strings.Contains(string(sums), ".lock") || !strings.HasSuffix(string(sums), "  a.mp3\n") {
+		t.Fatalf("SHA256SUMS 内容:\n%s", sums)
+	}
+	ffp, _ := os.ReadFile(filepath.Join(sub, FFPFile))
+	if string(ffp) != "b.flac:0102030405060708090a0b0c0d0e0f10\n" {
+		t.Fatalf("ffp 内容: %q", ffp)
+	}
+
+	// 移除 ffp 以免测试依赖 ffmpeg，只校验 SHA-256
+	os.Remove(filepath.Join(sub, FFPFile))
+	res, err := Verify(root)
+	if err != nil || res.Checked != 3 || len(res.Problems) != 0 {
+		t.Fatalf("Verify = %+v, %v", res, err)
+	}
+	if err := os.WriteFile(filepath.Join(sub, "cover.jpg"), []byte("rot"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	res, _ = Verify(root)
+	if len(res.Problems) != 1 || filepath.Base(res.Problems[0].Path) != "cover.jpg" {
+		t.Fatalf("应发现 cover.jpg 被改动: %+v", res)
+	}
+}
+
+func TestVerifyFLACHeaderOnly(t *testing.T) {
+	// 只有头部、没有音频帧的文件：解码结果与记录的 MD5 不可能一致
+	p := filepath.Join(t.TempDir(), "broken.flac")
+	if err := os.WriteFile(p, fakeFLAC(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	status, err := VerifyFLAC(p)
+	if err != nil {
+		t.Skipf("跳过：%v", err)
+	}
+	if status != FLACCorrupt {
+		t.Fatalf("status = %s，期望 %s", status, FLACCorrupt)
+	}
+}
@@ -0,0 +1,197 @@
+// file: internal/config/config.go
+// package: config
+//
+// 配置来源：命令行参数之外，参数值还可以来自配置文件（-config，扁平的 YAML "名称: 值"）
+// 与环境变量（AUDIO_DEDUP_<参数名>，如 AUDIO_DEDUP_THRESHOLD=6）。优先级从低到高为
+// 默认值 < 配置文件 < 环境变量 < 命令行。Write 把解析后的完整配置写成同样格式的 YAML，
+// 并注明每个值的来源，可直接另存为配置文件。
+package config
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix 为环境变量名前缀
+const EnvPrefix = "AUDIO_DEDUP_"
+
+// Source 为参数值的来源
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "config"  // 配置文件
+	SourceEnv     Source = "env"     // 环境变量
+	SourceFlag    Source = "flag"    // 命令行
+	SourceDerived Source = "derived" // 未显式指定，由其它参数推导（如 -upgrade-only 隐含 -upgrade-dst）
+)
+
+// EnvName 返回参数 name 对应的环境变量名
+func EnvName(name string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// Apply 在命令行解析之后依次应用配置文件（path 为空时跳过）与环境变量中的值，
+// 已在命令行指定的参数不受影响。返回显式指定的参数及其来源。
+func Apply(fs *flag.FlagSet, path string) (map[string]Source, error) {
+	sources := map[string]Source{}
+	fs.Visit(func(f *flag.Flag) { sources[f.Name] = SourceFlag })
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取配置文件失败: %w", err)
+		}
+		values, err := Parse(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for _, kv := range values {
+			if fs.Lookup(kv.Name) == nil {
+				return nil, fmt.Errorf("%s 第 %d 行: 未知参数 %q", path, kv.Line, kv.Name)
+			}
+			if sources[kv.Name] == SourceFlag {
+				continue
+			}
+			if err := fs.Set(kv.Name, kv.Value); err != nil {
+				return nil, fmt.Errorf("%s 第 %d 行: %s: %w", path, kv.Line, kv.Name, err)
+			}
+			sources[kv.Name] = SourceFile
+		}
+	}
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		v, ok := os.LookupEnv(EnvName(f.Name))
+		if !ok || err != nil || sources[f.Name] == SourceFlag {
+			return
+		}
+		if serr := fs.Set(f.Name, v); serr != nil {
+			err = fmt.Errorf("环境变量 %s: %w", EnvName(f.Name), serr)
+			return
+		}
+		sources[f.Name] = SourceEnv
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+// KeyValue 为配置文件中的一项
+type KeyValue struct {
+	Name  string
+	Value string
+	Line  int
+}
+
+// Parse 解析扁平的 YAML：每行 "名称: 值"，值可用单引号或双引号括起，# 之后为注释。
+// 不支持嵌套与列表（参数都是标量）。
+func Parse(r io.Reader) ([]KeyValue, error) {
+	var out []KeyValue
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' || line == "---" {
+			continue
+		}
+		name, rest, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("第 %d 行: 缺少 \":\"", n)
+		}
+		value, err := parseScalar(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 行: %w", n, err)
+		}
+		out = append(out, KeyValue{Name: strings.TrimSpace(name), Value: value, Line: n})
+	}
+	return out, sc.Err()
+}
+
+func parseScalar(s string) (string, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		q, err := strconv.QuotedPrefix(s)
+		if err != nil {
+			return "", fmt.Errorf("引号不匹配: %s", s)
+		}
+		if tail := strings.TrimSpace(s[len(q):]); tail != "" && tail[0] != '#' {
+			return "", fmt.Errorf("值之后有多余内容: %s", tail)
+		}
+		return strconv.Unquote(q)
+	case strings.HasPrefix(s, "'"):
+		// 单引号内 '' 表示一个单引号
+		var b strings.Builder
+		for i := 1; i < len(s); i++ {
+			if s[i] != '\'' {
+				b.WriteByte(s[i])
+				continue
+			}
+			if i+1 < len(s) && s[i+1] == '\'' {
+				b.WriteByte('\'')
+				i++
+				continue
+			}
+			if tail := strings.TrimSpace(s[i+1:]); tail != "" && tail[0] != '#' {
+				return "", fmt.Errorf("值之后有多余内容: %s", tail)
+			}
+			return b.String(), nil
+		}
+		return "", fmt.Errorf("引号不匹配: %s", s)
+	}
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = s[:i]
+	}
+	if s == "~" || s == "null" {
+		return "", nil
+	}
+	return strings.TrimSpace(s), nil
+}
+
+// Write 把 fs 中所有参数（skip 中的除外）的当前值写成 YAML，按名称排序；
+// 非默认值在行尾注释中标明来源（sources 中没有、但值与默认值不同的记为 SourceDerived）。
+func Write(w io.Writer, fs *flag.FlagSet, sources map[string]Source, skip ...string) error {
+	skipped := map[string]bool{}
+	for _, s := range skip {
+		skipped[s] = true
+	}
+	var flags []*flag.Flag
+	fs.VisitAll(func(f *flag.Flag) {
+		if !skipped[f.Name] {
+			flags = append(flags, f)
+		}
+	})
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# audio-dedup 生效配置（%s）；可另存为文件后用 -config 加载\n", time.Now().Format(time.DateTime))
+	for _, f := range flags {
+		src := sources[f.Name]
+		value := f.Value.String()
+		if src == "" && value != f.DefValue {
+			src = SourceDerived
+		}
+		fmt.Fprintf(bw, "%s: %s", f.Name, formatScalar(f, value))
+		if src != "" {
+			fmt.Fprintf(bw, " # %s", src)
+		}
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
+
+// formatScalar 把布尔与数值参数原样写出，其余（字符串、时长等）写成双引号字符串
+func formatScalar(f *flag.Flag, value string) string {
+	if g, ok := f.Value.(flag.Getter); ok {
+		switch g.Get().(type) {
+		case bool, int, int64, uint, uint64, float64:
+			return value
+		}
+	}
+	return strconv.Quote(value)
+}
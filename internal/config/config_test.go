@@ -0,0 +1,87 @@
+// file: internal/config/config_test.go
+// package: config
+//
+// 测试配置来源的优先级（配置文件 < 环境变量 < 命令行）、YAML 解析，以及 Write 的输出可被重新加载。
+package config
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newFlags() (*flag.FlagSet, *int, *string, *bool, *time.Duration) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	th := fs.Int("threshold", 8, "")
+	name := fs.String("keep-policy", "largest", "")
+	v := fs.Bool("v", false, "")
+	d := fs.Duration("max-runtime", 0, "")
+	return fs, th, name, v, d
+}
+
+func TestApplyPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "c.yaml")
+	cfg := "# 注释\nthreshold: 4\nkeep-policy: 'size desc, path asc' # 行尾注释\nv: true\nmax-runtime: \"2h\"\n"
+	if err := os.WriteFile(path, []byte(cfg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fs, th, name, v, d := newFlags()
+	if err := fs.Parse([]string{"-v=false"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvName("threshold"), "6")
+	sources, err := Apply(fs, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *th != 6 || sources["threshold"] != SourceEnv {
+		t.Errorf("环境变量应覆盖配置文件: %d %s", *th, sources["threshold"])
+	}
+	if *name != "size desc, path asc" || sources["keep-policy"] != SourceFile || *d != 2*time.Hour {
+		t.Errorf("配置文件的值未生效: %q %s %v", *name, sources["keep-policy"], *d)
+	}
+	if *v || sources["v"] != SourceFlag {
+		t.Errorf("命令行应覆盖配置文件: %v %s", *v, sources["v"])
+	}
+
+	if err := os.WriteFile(path, []byte("nosuch: 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Apply(fs, path); err == nil || !strings.Contains(err.Error(), "nosuch") {
+		t.Errorf("未知参数应报错: %v", err)
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	fs, th, name, _, _ := newFlags()
+	if err := fs.Parse([]string{"-threshold", "3", "-keep-policy", `say "hi" # x`}); err != nil {
+		t.Fatal(err)
+	}
+	sources, err := Apply(fs, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, fs, sources, "v"); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "threshold: 3 # flag\n") || strings.Contains(out, "\nv:") {
+		t.Fatalf("输出不正确:\n%s", out)
+	}
+	path := filepath.Join(t.TempDir(), "c.yaml")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fs2, th2, name2, _, _ := newFlags()
+	if _, err := Apply(fs2, path); err != nil {
+		t.Fatal(err)
+	}
+	if *th2 != *th || *name2 != *name {
+		t.Fatalf("重新加载后不一致: %d %q", *th2, *name2)
+	}
+}
@@ -0,0 +1,448 @@
+// file: internal/config/options.go
+// package: config
+//
+// 运行配置：主程序的全部参数集中在 Options 一个结构体中，由 Register 绑定到命令行参数，
+// Load 依次应用命令行、配置文件与环境变量，Validate 检查参数之间的冲突并补上隐含的取值。
+// 服务模式、库 API 与预设配置共用同一份配置，不必各自解析参数。
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"deduplicateMusic/internal/cache"
+	"deduplicateMusic/internal/calibrate"
+	"deduplicateMusic/internal/copyutil"
+	"deduplicateMusic/internal/dedup"
+	"deduplicateMusic/internal/errbudget"
+	"deduplicateMusic/internal/overrides"
+	"deduplicateMusic/internal/provenance"
+	"deduplicateMusic/internal/removal"
+	"deduplicateMusic/internal/report"
+)
+
+// Options 为一次运行的全部配置，字段与同名（见注释）命令行参数一一对应
+type Options struct {
+	Src                   string        // -src
+	Dst                   string        // -dst
+	Ref                   string        // -ref
+	Batch                 bool          // -batch
+	BatchParallel         int           // -batch-parallel
+	InPlace               bool          // -in-place
+	Delete                bool          // -delete
+	Trash                 bool          // -trash
+	Yes                   bool          // -yes
+	Quarantine            string        // -quarantine
+	UndoQuarantine        string        // -undo-quarantine
+	Provenance            bool          // -provenance
+	ProvenanceXattr       bool          // -provenance-xattr
+	ProvenanceOf          string        // -provenance-of
+	Mode                  string        // -mode
+	PreserveStructure     bool          // -preserve-structure
+	OnCollision           string        // -on-collision
+	Workers               int           // -workers
+	Threshold             int           // -threshold
+	Seconds               int           // -seconds
+	Verbose               bool          // -v
+	LogLevel              string        // -log-level
+	LogFormat             string        // -log-format
+	Quiet                 bool          // -quiet
+	Top                   int           // -top
+	ReportColumns         string        // -report-columns
+	ReportFormat          string        // -report-format
+	Report                string        // -report
+	NoReport              bool          // -no-report
+	ReportLang            string        // -report-lang
+	SummaryJSON           bool          // -summary-json
+	GzipReports           bool          // -gzip-reports
+	DuOut                 string        // -du-out
+	DuFormat              string        // -du-format
+	Previews              string        // -previews
+	Thumbnails            string        // -thumbnails
+	SpectroDiff           string        // -spectro-diff
+	PreviewSeconds        int           // -preview-seconds
+	Audit                 bool          // -audit
+	AuditKey              string        // -audit-key
+	VerifyAudit           string        // -verify-audit
+	VerifyFLAC            bool          // -verify-flac
+	CompilationPreference string        // -compilation-preference
+	Review                bool          // -review
+	GroupBy               string        // -group-by
+	Classical             bool          // -classical
+	Covers                bool          // -covers
+	CoverThreshold        float64       // -cover-threshold
+	CoverSeconds          int           // -cover-seconds
+	DurationTolerance     float64       // -duration-tolerance
+	SpeedTolerant         bool          // -speed-tolerant
+	Gapless               bool          // -gapless
+	ScanMP3               bool          // -scan-mp3
+	Checksums             bool          // -checksums
+	VerifyChecksums       string        // -verify-checksums
+	KeepPolicy            string        // -keep-policy
+	PathPriority          string        // -path-priority
+	Cache                 string        // -cache
+	NoCache               bool          // -no-cache
+	ExactHash             bool          // -exact-hash
+	Decisions             string        // -decisions
+	RecomputeDecisions    bool          // -recompute-decisions
+	Segments              int           // -segments
+	Decoder               string        // -decoder
+	Strict                bool          // -strict
+	Matcher               string        // -matcher
+	Metric                string        // -metric
+	Overrides             string        // -overrides
+	NeverMatch            string        // -never-match
+	MarkFalsePositive     string        // -mark-false-positive
+	Confirmed             string        // -confirmed
+	MarkConfirmed         string        // -mark-confirmed
+	Feedback              string        // -feedback
+	Rules                 string        // -rules
+	TitlePatterns         string        // -title-patterns
+	Plugins               string        // -plugin
+	OnKeep                string        // -on-keep
+	OnDuplicate           string        // -on-duplicate
+	OnError               string        // -on-error
+	MaxMemory             string        // -max-memory
+	MaxFFmpeg             int           // -max-ffmpeg
+	IORate                string        // -io-rate
+	AutoTune              bool          // -auto-tune
+	ShardBits             int           // -shard-bits
+	BKTreeMinFiles        int           // -bktree-min-files
+	Exhaustive            bool          // -exhaustive
+	SpillDir              string        // -spill-dir
+	MaxRuntime            time.Duration // -max-runtime
+	Order                 string        // -order
+	Resume                bool          // -resume
+	StageBudget           string        // -stage-budget
+	MaxErrors             string        // -max-errors
+	Force                 bool          // -force
+	Device                string        // -device
+	DeviceRemoveList      string        // -device-remove-list
+	SyncPlan              string        // -sync-plan
+	SyncDirection         string        // -sync-direction
+	AllowManagedLibrary   bool          // -allow-managed-library
+	MusicPlan             string        // -music-plan
+	UpgradeDst            bool          // -upgrade-dst
+	DstScanWorkers        int           // -dst-scan-workers
+	UpgradeOnly           bool          // -upgrade-only
+	MinAlbumCompleteness  float64       // -min-album-completeness
+	Folders               bool          // -folders
+	FolderKeep            bool          // -folder-keep
+	Sidecars              bool          // -sidecars
+	MergeLyrics           bool          // -merge-lyrics
+	SpotCheck             int           // -spot-check
+	DetectChanges         bool          // -detect-changes
+	AssertReadOnlySrc     bool          // -assert-readonly-src
+	Config                string        // -config
+	PrintConfig           bool          // -print-config
+}
+
+// Defaults 返回默认配置（与各参数的默认值一致）
+func Defaults() Options {
+	return Options{
+		BatchParallel:         1,
+		Provenance:            true,
+		Mode:                  "copy",
+		OnCollision:           string(copyutil.CollisionRename),
+		Workers:               runtime.NumCPU(),
+		Threshold:             8,
+		Seconds:               8,
+		LogLevel:              "info",
+		LogFormat:             "text",
+		Top:                   10,
+		ReportFormat:          "csv",
+		ReportLang:            "en",
+		PreviewSeconds:        10,
+		CompilationPreference: "none",
+		GroupBy:               "fingerprint",
+		CoverThreshold:        0.85,
+		CoverSeconds:          60,
+		Gapless:               true,
+		KeepPolicy:            "largest",
+		Cache:                 cache.DefaultPath(),
+		ExactHash:             true,
+		Decisions:             cache.DefaultDecisionsPath(),
+		Segments:              1,
+		Decoder:               "auto",
+		Matcher:               "hamming",
+		Metric:                "hamming",
+		Overrides:             overrides.DefaultPath(),
+		NeverMatch:            overrides.NeverMatchPath(),
+		Confirmed:             overrides.ConfirmedPath(),
+		Feedback:              calibrate.DefaultPath(),
+		Order:                 "path",
+		SyncDirection:         "both",
+		DstScanWorkers:        1,
+		DetectChanges:         true,
+	}
+}
+
+// Register 把 o 的各字段绑定到 fs 中的同名参数，参数默认值取 o 的当前值
+func (o *Options) Register(fs *flag.FlagSet) {
+	fs.StringVar(&o.Src, "src", o.Src, "源目录，包含待去重的音频文件；也可以是 http(s):// 的 WebDAV / 目录索引 URL")
+	fs.StringVar(&o.Dst, "dst", o.Dst, "目标输出目录，保留的文件会被复制到此处")
+	fs.StringVar(&o.Ref, "ref", o.Ref, "参考资料库目录：其中的文件参与比对并总是作为保留文件，但从不复制、移动或删除；-src 中与参考库重复的文件不再放入目标目录（-in-place 时按重复文件移除）。用于把新下载目录并入已整理好的资料库而不改动资料库")
+	fs.BoolVar(&o.Batch, "batch", o.Batch, "批量模式：把 -src 的每个直接子目录（如按艺术家或年份整理的下载目录）作为独立的去重任务依次运行，保留文件放到 -dst 下的同名目录，各任务的报告与日志写到当前目录的 audio_dedup_batch_<时间戳>/<子目录名>/ 中（相对路径的输出文件参数也相对于该目录），最后输出合并摘要")
+	fs.IntVar(&o.BatchParallel, "batch-parallel", o.BatchParallel, "-batch 时同时运行的任务数；大于 1 且未指定 -workers 时每个任务的 worker 数为 CPU 核数除以该值，避免任务之间争抢 CPU")
+	fs.BoolVar(&o.InPlace, "in-place", o.InPlace, "原地去重：不复制保留文件，直接从源目录移除重复文件（需配合 -delete、-trash 或 -quarantine 之一，无需 -dst）")
+	fs.BoolVar(&o.Delete, "delete", o.Delete, "配合 -in-place：直接删除重复文件（无法撤销）")
+	fs.BoolVar(&o.Trash, "trash", o.Trash, "配合 -in-place：把重复文件移入系统回收站（Linux / macOS）")
+	fs.BoolVar(&o.Yes, "yes", o.Yes, "不再确认破坏性操作：原地移除重复文件（-in-place）或移动保留文件（-mode move）前默认显示将移除的文件数与释放空间并等待输入 y；标准输入不是终端时必须指定")
+	fs.StringVar(&o.Quarantine, "quarantine", o.Quarantine, "配合 -in-place：把重复文件移入该隔离目录（按运行分批，附清单，可用 -undo-quarantine 还原）")
+	fs.StringVar(&o.UndoQuarantine, "undo-quarantine", o.UndoQuarantine, "按隔离批次目录（或其中的 manifest.jsonl）把文件放回原处后退出")
+	fs.BoolVar(&o.Provenance, "provenance", o.Provenance, "在目标目录的 "+provenance.ManifestFile+" 中记录每个放入的文件来自哪个源目录、源内路径与哪次运行")
+	fs.BoolVar(&o.ProvenanceXattr, "provenance-xattr", o.ProvenanceXattr, "同时把来源写入目标文件的扩展属性 user.audio_dedup.*（仅 Linux；hardlink / symlink 方式与源文件共用数据，不写入）")
+	fs.StringVar(&o.ProvenanceOf, "provenance-of", o.ProvenanceOf, "查询目标目录中某个文件的来源记录后退出")
+	fs.StringVar(&o.Mode, "mode", o.Mode, "保留文件放入目标目录的方式：copy、move（同一文件系统内重命名，跨文件系统时复制后删除）、hardlink 或 symlink；远程源总是复制")
+	fs.BoolVar(&o.PreserveStructure, "preserve-structure", o.PreserveStructure, "在目标目录中按源目录内的相对路径（子目录结构）保存保留文件；默认平铺到目标目录，同名文件追加 \" (2)\"、\" (3)\" 等后缀")
+	fs.StringVar(&o.OnCollision, "on-collision", o.OnCollision, "目标文件名冲突（与本次运行的其它文件同名，或目标目录中已有内容不同的同名文件）时的处理：rename（追加 \" (2)\" 等后缀）、skip（不放入）、error（不放入并按失败记录）或 overwrite（覆盖）；不区分大小写的文件系统上大小写不同也算同名")
+	fs.IntVar(&o.Workers, "workers", o.Workers, "并发工作数量（默认：CPU 核数）")
+	fs.IntVar(&o.Threshold, "threshold", o.Threshold, "相似度阈值（哈希汉明距离），越小越严格，默认8")
+	fs.IntVar(&o.Seconds, "seconds", o.Seconds, "用于指纹的音频时长（秒）— 从文件开头读取多少秒用于指纹计算，默认8秒")
+	fs.BoolVar(&o.Verbose, "v", o.Verbose, "是否打印详细进度信息")
+	fs.StringVar(&o.LogLevel, "log-level", o.LogLevel, "日志级别：debug、info、warn、error")
+	fs.StringVar(&o.LogFormat, "log-format", o.LogFormat, "日志格式：text（key=value）或 json（每行一个对象，便于日志收集工具导入）")
+	fs.BoolVar(&o.Quiet, "quiet", o.Quiet, "不显示进度条（扫描、指纹、比较、处理各阶段的完成数、吞吐量与预计剩余时间）")
+	fs.IntVar(&o.Top, "top", o.Top, "控制台摘要中每个统计列表显示的条目数（完整列表见摘要文件）")
+	fs.StringVar(&o.ReportColumns, "report-columns", o.ReportColumns, "报告 CSV 的列及顺序（逗号分隔），可选 "+strings.Join(report.ColumnNames(), ", ")+"；默认 "+strings.Join(report.DefaultColumns, ","))
+	fs.StringVar(&o.ReportFormat, "report-format", o.ReportFormat, "报告格式，可逗号分隔多个：csv（逐行写出）、json（按分组组织，供程序处理）、html（每组一张可排序表格，供浏览器审阅）；json/html 在结束时一次性写出")
+	fs.StringVar(&o.Report, "report", o.Report, "报告输出路径，默认在当前目录生成带时间戳的文件；\"-\" 写到标准输出（此时其余控制台输出改写到 stderr）；以 .gz 结尾时压缩；多种格式时其余格式写到同名、扩展名换成格式名的文件")
+	fs.BoolVar(&o.NoReport, "no-report", o.NoReport, "不生成去重报告（摘要、跳过列表等其它报告不受影响）")
+	fs.StringVar(&o.ReportLang, "report-lang", o.ReportLang, "报告表头语言："+strings.Join(report.Languages, "、"))
+	fs.BoolVar(&o.SummaryJSON, "summary-json", o.SummaryJSON, "结束时向 stdout 输出单个 JSON 对象（计数、字节数、耗时、报告路径、错误数），供脚本解析；此时其余控制台输出改写到 stderr")
+	fs.BoolVar(&o.GzipReports, "gzip-reports", o.GzipReports, "带时间戳的报告以 gzip 压缩写出（文件名追加 .gz）；-music-plan、-sync-plan、-du-out 等输出路径以 .gz 结尾时也会压缩")
+	fs.StringVar(&o.DuOut, "du-out", o.DuOut, "输出重复空间按目录归属的磁盘占用文件（.json 为 ncdu 导出格式，可用 ncdu -f 查看；其余为 du 风格文本）")
+	fs.StringVar(&o.DuFormat, "du-format", o.DuFormat, "磁盘占用输出格式：du 或 ncdu（默认按 -du-out 扩展名推断）")
+	fs.StringVar(&o.Previews, "previews", o.Previews, "为含重复文件的分组中每个成员生成响度归一化的 OGG 试听片段，写到该目录（索引见 index.csv）")
+	fs.StringVar(&o.Thumbnails, "thumbnails", o.Thumbnails, "为含重复文件的分组中每个成员生成 SVG 波形缩略图，写到该目录（由指纹解码的 PCM 计算，不额外解码）")
+	fs.StringVar(&o.SpectroDiff, "spectro-diff", o.SpectroDiff, "为边缘匹配（距离 >= 阈值一半）的重复文件绘制与保留文件对齐后的频谱差异图（PNG），写到该目录")
+	fs.IntVar(&o.PreviewSeconds, "preview-seconds", o.PreviewSeconds, "试听片段时长（秒），从文件中点截取")
+	fs.BoolVar(&o.Audit, "audit", o.Audit, "生成审计记录（所有输入/输出及报告的 SHA-256），写到当前目录")
+	fs.StringVar(&o.AuditKey, "audit-key", o.AuditKey, "审计记录签名密钥文件（HMAC-SHA256）；指定后自动启用 -audit")
+	fs.StringVar(&o.VerifyAudit, "verify-audit", o.VerifyAudit, "校验指定审计记录的签名（需配合 -audit-key）后退出")
+	fs.BoolVar(&o.VerifyFLAC, "verify-flac", o.VerifyFLAC, "完整解码 FLAC 并与其内部 MD5 比对；损坏的 FLAC 即使体积更大也不会被保留，结果记录在报告中")
+	fs.StringVar(&o.CompilationPreference, "compilation-preference", o.CompilationPreference, "同一曲目同时存在于原专辑与合辑（Various Artists）时优先保留哪份：original、compilation 或 none")
+	fs.BoolVar(&o.Review, "review", o.Review, "复制或移除任何文件之前，在终端逐组审核：确认保留文件、改选其它成员或把整组排除（不是重复）；改选记入保留决定（-decisions），排除的组写入误判清单（-never-match），重新运行时沿用")
+	fs.StringVar(&o.GroupBy, "group-by", o.GroupBy, "分组方式：fingerprint（只按指纹）、both（按规范化的艺术家+标题预分组，组内再按指纹判定，误判与比较次数都大幅减少）、tags（同艺术家+标题即视为重复）；缺少艺术家或标题的文件仍只按指纹比较")
+	fs.BoolVar(&o.Classical, "classical", o.Classical, "古典音乐配置：指纹时长默认 30 秒、阈值默认 4，并要求作曲家/作品/乐章标签一致才判为重复")
+	fs.BoolVar(&o.Covers, "covers", o.Covers, "实验性：用色度相似度找出疑似翻唱/同曲不同录音，单独输出参考报告（不会被当作重复处理）")
+	fs.Float64Var(&o.CoverThreshold, "cover-threshold", o.CoverThreshold, "疑似翻唱的色度相似度阈值（0..1）")
+	fs.IntVar(&o.CoverSeconds, "cover-seconds", o.CoverSeconds, "用于翻唱检测的音频时长（秒）")
+	fs.Float64Var(&o.DurationTolerance, "duration-tolerance", o.DurationTolerance, "时长容差（秒）：时长相差超过该值的文件即使指纹相近也不合并（需读取每个文件的时长，读不到时不限制）；0 表示不检查。与 -speed-tolerant 同用时应放宽到时长的约 3%")
+	fs.BoolVar(&o.SpeedTolerant, "speed-tolerant", o.SpeedTolerant, "容忍约 ±3% 的速度/音高差异（黑胶翻录、PAL 加速）：额外计算变速指纹并使用 speed 匹配器，较慢")
+	fs.BoolVar(&o.Gapless, "gapless", o.Gapless, "比较 MP3 时考虑编码器延迟：没有 LAME 无缝信息的文件跳过估算的开头延迟，使其与带标签的同一翻录对齐")
+	fs.BoolVar(&o.ScanMP3, "scan-mp3", o.ScanMP3, "扫描 MP3 帧完整性（损坏帧/截断），选择保留文件时错误最少者优先；也可在排序表达式中使用 errors 键")
+	fs.BoolVar(&o.Checksums, "checksums", o.Checksums, "在目标目录逐目录写出 SHA256SUMS 与 FLAC 指纹 fingerprints.ffp，便于日后检测位衰减")
+	fs.StringVar(&o.VerifyChecksums, "verify-checksums", o.VerifyChecksums, "校验指定目录下的 SHA256SUMS / fingerprints.ffp 后退出")
+	fs.StringVar(&o.KeepPolicy, "keep-policy", o.KeepPolicy, "保留策略：largest（体积最大）、bitrate（码率最高）、lossless-first（无损优先，其次编码质量与码率）、duration（时长最长）、tagged（标签最完整，其次编码质量与体积）、oldest / newest（修改时间最早 / 最晚）、path-priority（按 -path-priority 的目录顺序），或排序表达式如 \"size desc, path asc\"；quality / lossless 按格式注册表中的典型编码质量 / 是否无损比较，如 \"quality desc, size desc\"")
+	fs.StringVar(&o.PathPriority, "path-priority", o.PathPriority, "path-priority 保留策略（或排序表达式中的 priority 属性）使用的目录，逗号分隔，靠前的目录中的文件优先保留")
+	fs.StringVar(&o.Cache, "cache", o.Cache, "指纹缓存文件或缓存服务地址（http://主机:端口，见 db serve）：按 路径+大小+修改时间 复用上次运行的解码结果")
+	fs.BoolVar(&o.NoCache, "no-cache", o.NoCache, "不读取也不写入指纹缓存")
+	fs.BoolVar(&o.ExactHash, "exact-hash", o.ExactHash, "解码前先按内容 SHA-256 找出逐字节相同的文件，每组只解码一个，其余直接复用其指纹（只对大小与其它文件相同的本地文件计算哈希）")
+	fs.StringVar(&o.Decisions, "decisions", o.Decisions, "保留决定文件：记录每组（按成员与保留策略签名）选出的保留文件，相同输入再次运行时沿用，避免保留文件来回变化；为空则不记录")
+	fs.BoolVar(&o.RecomputeDecisions, "recompute-decisions", o.RecomputeDecisions, "忽略已记录的保留决定，按当前策略重新选择并覆盖记录")
+	fs.IntVar(&o.Segments, "segments", o.Segments, "每个文件计算指纹的窗口数：1 只取开头 -seconds 秒；>1 时在开头（跳过前导静音）、中段、结尾之间均匀取窗口，按各窗口距离的平均值判定重复（即 -metric segments）")
+	fs.StringVar(&o.Decoder, "decoder", o.Decoder, "解码后端：auto（WAV/FLAC 在进程内解码，其余格式用 ffmpeg）、native（不调用 ffmpeg）或 ffmpeg")
+	fs.BoolVar(&o.Strict, "strict", o.Strict, "启动时的解码器检查发现扫描到的格式无法解码（未找到 ffmpeg，或 ffmpeg 缺少 ALAC、Opus 等解码器）时报错退出；默认只警告并继续")
+	fs.StringVar(&o.Matcher, "matcher", o.Matcher, "重复判定匹配器名称（可由插件注册）")
+	fs.StringVar(&o.Metric, "metric", o.Metric, "指纹距离度量："+strings.Join(dedup.MetricNames(), "、")+"（可由插件注册），距离统一换算到 0..64 与 -threshold 比较")
+	fs.StringVar(&o.Overrides, "overrides", o.Overrides, "人工覆盖文件：每行 \"keep: 路径\"（该文件所在的组总是保留它）或 \"apart: 路径 | 路径\"（两者永不合并），每次运行在自动选择之前生效；默认位置的文件不存在时忽略，为空则不使用")
+	fs.StringVar(&o.NeverMatch, "never-match", o.NeverMatch, "误判清单（CSV）：每行两个或更多路径，行内文件之后永不合并；默认位置的文件不存在时忽略，为空则不使用")
+	fs.StringVar(&o.MarkFalsePositive, "mark-false-positive", o.MarkFalsePositive, "把用 \"|\" 分隔的两个或更多路径（如报告中被误判为重复的一组）追加到 -never-match 误判清单后退出")
+	fs.StringVar(&o.Confirmed, "confirmed", o.Confirmed, "确认清单（CSV）：每行两个或更多确认为真重复的路径，只用于阈值校准建议，不影响分组；默认位置的文件不存在时忽略")
+	fs.StringVar(&o.MarkConfirmed, "mark-confirmed", o.MarkConfirmed, "把用 \"|\" 分隔的两个或更多路径（确认为真重复的一组）追加到 -confirmed 确认清单后退出")
+	fs.StringVar(&o.Feedback, "feedback", o.Feedback, "阈值校准反馈文件：跨运行按匹配配置累积误判与确认的文件对及其距离，据此在摘要中给出阈值调整建议；为空则不记录")
+	fs.StringVar(&o.Rules, "rules", o.Rules, "规则文件：每行 \"prefer: 表达式\" 或 \"protect: 表达式\"，如 prefer: ext == \"flac\"")
+	fs.StringVar(&o.TitlePatterns, "title-patterns", o.TitlePatterns, "标题后缀模式文件：每行一个正则，追加到内置的 (feat. X) / [Explicit] / (Album Version) 等模式之后，用于比较标题")
+	fs.StringVar(&o.Plugins, "plugin", o.Plugins, "逗号分隔的 Go 插件(.so)路径，插件在 init 中注册自定义策略/匹配器")
+	fs.StringVar(&o.OnKeep, "on-keep", o.OnKeep, "每个保留文件复制后执行的 shell 命令（文件元数据以 JSON 写入 stdin）")
+	fs.StringVar(&o.OnDuplicate, "on-duplicate", o.OnDuplicate, "每个被判定为重复的文件执行的 shell 命令（JSON 写入 stdin）")
+	fs.StringVar(&o.OnError, "on-error", o.OnError, "每个处理失败的文件执行的 shell 命令（JSON 写入 stdin）")
+	fs.StringVar(&o.MaxMemory, "max-memory", o.MaxMemory, "内存上限（如 2GB、512MB）：超过时自动减少同时进行的解码数量，避免在小内存 NAS 上被 OOM")
+	fs.IntVar(&o.MaxFFmpeg, "max-ffmpeg", o.MaxFFmpeg, "同时运行的 ffmpeg / ffprobe 子进程上限，0 表示不限制；-batch 时对每个任务分别生效")
+	fs.StringVar(&o.IORate, "io-rate", o.IORate, "本程序读取文件（复制、计算哈希、内置解码器解码）的速率上限，每秒字节数（如 50MB），为空表示不限制；不含 ffmpeg 自行读取的数据，-batch 时对每个任务分别生效")
+	fs.BoolVar(&o.AutoTune, "auto-tune", o.AutoTune, "运行中根据解码吞吐量自动调整并发（-workers 为初始值，上限为 2 倍 CPU 核数）")
+	fs.IntVar(&o.ShardBits, "shard-bits", o.ShardBits, "按指纹高 N 位分片聚类（0 表示使用默认的分段索引）；N 应明显大于 -threshold 才能有效减少比较")
+	fs.IntVar(&o.BKTreeMinFiles, "bktree-min-files", o.BKTreeMinFiles, "文件数达到该值时改用 BK 树查找指纹近邻（0 表示不使用）；随机分布的指纹上通常慢于默认的分段索引，适合指纹高度聚集的资料库试用")
+	fs.BoolVar(&o.Exhaustive, "exhaustive", o.Exhaustive, "强制全量两两比较指纹（默认把指纹切成 阈值+1 段建立索引，只比较至少一段相同的候选对，结果相同但快得多；-threshold 大于 15 时总是全量比较）")
+	fs.StringVar(&o.SpillDir, "spill-dir", o.SpillDir, "超大规模运行时把文件元数据溢出到该目录下的临时文件，内存中只保留紧凑指纹索引")
+	fs.DurationVar(&o.MaxRuntime, "max-runtime", o.MaxRuntime, "整次运行的时限（如 6h）：到时在两个文件/分组之间停止，完成报告并在目标目录写出检查点，退出码 3；0 表示不限")
+	fs.StringVar(&o.Order, "order", o.Order, "处理顺序："+strings.Join(dedup.ProcessOrders, "|")+"。newest 时最近修改（新下载）的文件最先计算指纹、所在的组最先处理并写入报告，配合 -max-runtime 时新文件不必等整个资料库；只影响先后，不影响分组结果")
+	fs.BoolVar(&o.Resume, "resume", o.Resume, "从上次未完成（被终止或超出时间预算）的运行继续：已处理完的分组沿用上次的结果，不再复制或移除文件，已计算的指纹从缓存读取")
+	fs.StringVar(&o.StageBudget, "stage-budget", o.StageBudget, "各阶段的时限，如 fingerprint=4h,process=1h（fingerprint：扫描与计算指纹；process：分组与复制），超出时与 -max-runtime 一样停止")
+	fs.StringVar(&o.MaxErrors, "max-errors", o.MaxErrors, "计算指纹失败的文件超过该数量（如 50）或占全部文件的比例（如 5%）时提前中止，按失败原因汇总后以退出码 1 退出，不分组也不复制；大量失败通常意味着缺少解码器或挂载点掉线。为空表示不限制")
+	fs.BoolVar(&o.Force, "force", o.Force, "忽略目标目录中已有的运行锁（仅在确认没有其它运行时使用）")
+	fs.StringVar(&o.Device, "device", o.Device, "实验性：把手机等设备上的音乐与 -src 一起比对（adb:///sdcard/Music，或 MTP 挂载后的本地目录）；重复时优先保留 -src 中的文件")
+	fs.StringVar(&o.DeviceRemoveList, "device-remove-list", o.DeviceRemoveList, "把设备上可删除的重复文件（设备端路径，每行一个）写到该文件")
+	fs.StringVar(&o.SyncPlan, "sync-plan", o.SyncPlan, "配合 -device：把只存在于一侧的曲目写成同步计划 CSV（方向、源、目标路径），不执行复制")
+	fs.StringVar(&o.SyncDirection, "sync-direction", o.SyncDirection, "同步计划方向：both、to-device（主库→设备）或 to-library（设备→主库）")
+	fs.BoolVar(&o.AllowManagedLibrary, "allow-managed-library", o.AllowManagedLibrary, "src 为音乐/iTunes 管理的媒体文件夹时仍按普通模式运行（默认切换到只报告的安全模式）")
+	fs.StringVar(&o.MusicPlan, "music-plan", o.MusicPlan, "导出去重计划（m3u8 播放列表，列出待删除的重复文件），可导入音乐 App/iTunes 后由应用删除")
+	fs.BoolVar(&o.UpgradeDst, "upgrade-dst", o.UpgradeDst, "把目标目录中已有的文件一起比对：同一曲目在目标目录中已有且质量不如新副本时，旧文件移入备份目录后原位替换；已有最佳版本时不再导入")
+	fs.IntVar(&o.DstScanWorkers, "dst-scan-workers", o.DstScanWorkers, "-upgrade-dst 时目标目录中尚无缓存指纹的文件（如首次对大型目标目录运行）只用这么多个 worker 在后台计算，源文件优先，源目录内部的比对结果先行输出，源文件完成后其余 worker 一并接手；0 表示不区分先后")
+	fs.BoolVar(&o.UpgradeOnly, "upgrade-only", o.UpgradeOnly, "只升级不导入：只用 src 中质量更好的版本替换目标目录中已有的曲目，目标目录中没有的曲目不复制（隐含 -upgrade-dst）")
+	fs.Float64Var(&o.MinAlbumCompleteness, "min-album-completeness", o.MinAlbumCompleteness, "只导入完整度不低于该比例（0..1）的专辑中的曲目：按标签中的曲目总数统计 src 与目标目录中已有的曲号，没有总数的专辑不受限制；0 表示不限制")
+	fs.BoolVar(&o.Folders, "folders", o.Folders, "报告整目录重复：所有曲目在其它目录中都有重复的目录（如重复抓轨的专辑）")
+	fs.BoolVar(&o.FolderKeep, "folder-keep", o.FolderKeep, "按目录选择保留文件：可整目录删除的目录中的文件总是让位于其它目录，避免一张专辑的保留文件散落在两个目录（隐含 -folders；-spill-dir 下不生效）")
+	fs.BoolVar(&o.Sidecars, "sidecars", o.Sidecars, "处理伴随文件：复制保留文件时一并复制同名歌词/CUE/日志与目录封面，替换目标目录文件时一并备份旧的伴随文件，报告中列出重复文件的伴随文件")
+	fs.BoolVar(&o.MergeLyrics, "merge-lyrics", o.MergeLyrics, "保留文件没有歌词（同名 .lrc 或内嵌歌词）而重复文件有时，把歌词写成目标目录中保留文件旁的 .lrc")
+	fs.IntVar(&o.SpotCheck, "spot-check", o.SpotCheck, "处理完成后随机抽查 N 对（保留文件, 重复文件）：重新解码磁盘上的最终文件并比对指纹/大小/距离，报告运行期间被改动的文件")
+	fs.BoolVar(&o.DetectChanges, "detect-changes", o.DetectChanges, "计算指纹时记录文件大小/修改时间/首尾哈希，复制、替换或列入删除清单前再次比对，跳过期间被改动的文件")
+	fs.BoolVar(&o.AssertReadOnlySrc, "assert-readonly-src", o.AssertReadOnlySrc, "只读源保证：拒绝任何会写入源目录的操作（目标目录/报告不能位于源目录内）")
+	fs.StringVar(&o.Config, "config", o.Config, "配置文件（扁平 YAML，每行 \"参数名: 值\"）；优先级：默认值 < 配置文件 < 环境变量 "+EnvPrefix+"<参数名> < 命令行")
+	fs.BoolVar(&o.PrintConfig, "print-config", o.PrintConfig, "以 YAML 输出解析后的完整配置（注明每个值来自配置文件、环境变量还是命令行）后退出，可另存为 -config 配置文件")
+}
+
+// ErrNoDirs 表示缺少 -src 或 -dst（-in-place 时 -dst 默认为 -src）
+var ErrNoDirs = errors.New("需要指定 -src 与 -dst")
+
+// Load 在 fs 上注册默认配置的参数并解析 args，再应用 -config 配置文件与环境变量，
+// 返回生效的配置与各参数的来源（见 Apply）。参数之间的检查见 Validate。
+func Load(fs *flag.FlagSet, args []string) (*Options, map[string]Source, error) {
+	o := Defaults()
+	o.Register(fs)
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	sources, err := Apply(fs, o.Config)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &o, sources, nil
+}
+
+// RemoveMethod 返回 -delete、-trash、-quarantine 选择的移除方式，都未指定时为空
+func (o *Options) RemoveMethod() removal.Method {
+	switch {
+	case o.Delete:
+		return removal.MethodDelete
+	case o.Trash:
+		return removal.MethodTrash
+	case o.Quarantine != "":
+		return removal.MethodQuarantine
+	}
+	return ""
+}
+
+// Validate 检查参数之间的冲突，并补上隐含的取值（-in-place 时 -dst 默认为 -src、-upgrade-only 隐含
+// -upgrade-dst、-folder-keep 隐含 -folders、-classical 调整未显式指定的 -seconds 与 -threshold）。
+// explicit 为显式指定过的参数，通常是 Load 返回的来源表。
+func (o *Options) Validate(explicit map[string]Source) error {
+	if o.InPlace && o.Dst == "" {
+		o.Dst = o.Src // 运行锁与检查点放在源目录
+	}
+	if !o.PrintConfig && (o.Src == "" || o.Dst == "") {
+		return ErrNoDirs
+	}
+	n := 0
+	for _, on := range []bool{o.Delete, o.Trash, o.Quarantine != ""} {
+		if on {
+			n++
+		}
+	}
+	mode, err := copyutil.ParseMode(o.Mode)
+	if err == nil {
+		_, err = copyutil.ParseCollisionPolicy(o.OnCollision)
+	}
+	if err == nil {
+		_, err = errbudget.Parse(o.MaxErrors)
+	}
+	switch {
+	case err != nil:
+		return err
+	case n > 1:
+		return errors.New("-delete、-trash、-quarantine 只能选择一个")
+	case o.InPlace && n == 0:
+		return errors.New("-in-place 需要配合 -delete、-trash 或 -quarantine 之一")
+	case !o.InPlace && n > 0:
+		return errors.New("-delete、-trash、-quarantine 需要配合 -in-place")
+	case o.InPlace && o.Dst != o.Src:
+		return errors.New("-in-place 不复制保留文件，不能同时指定 -dst")
+	case o.InPlace && (o.UpgradeDst || o.UpgradeOnly || o.Device != "" || o.AssertReadOnlySrc):
+		return errors.New("-in-place 不能与 -upgrade-dst / -upgrade-only、-device、-assert-readonly-src 同时使用")
+	case o.Ref != "" && (copyutil.IsWithin(o.Ref, o.Src) || copyutil.IsWithin(o.Src, o.Ref)):
+		return fmt.Errorf("参考目录 %s 与源目录 %s 重叠，请指定互不包含的两个目录", o.Ref, o.Src)
+	case o.Ref != "" && copyutil.IsWithin(o.Ref, o.Dst):
+		return fmt.Errorf("目标目录 %s 位于参考目录 %s 内，-ref 的内容不能被改动", o.Dst, o.Ref)
+	case o.Quarantine != "" && copyutil.IsWithin(o.Src, o.Quarantine):
+		return fmt.Errorf("隔离目录 %s 位于源目录内，下次扫描会把隔离的文件当作源文件，请换到源目录之外", o.Quarantine)
+	case o.SummaryJSON && o.Report == report.StdoutPath && !o.NoReport:
+		return errors.New("-summary-json 与 -report - 都要占用标准输出，只能选择其一")
+	case o.SyncPlan != "" && o.Device == "":
+		return errors.New("-sync-plan 需要同时指定 -device")
+	case o.AssertReadOnlySrc && mode.ModifiesSource():
+		return fmt.Errorf("-assert-readonly-src 与 -mode %s 冲突：移动会改动源目录", mode)
+	case o.Batch && strings.Contains(o.Src, "://"):
+		return errors.New("-batch 需要本地源目录")
+	case o.Batch && (o.Review || o.Device != "" || o.Report != ""):
+		return errors.New("-batch 不能与 -review、-device、-report 同时使用（各子目录的报告写在各自的报告目录中）")
+	case o.Batch && (o.InPlace || mode == copyutil.ModeMove) && !o.Yes:
+		return errors.New("-batch 下无法逐个确认，-in-place / -mode move 需要加 -yes")
+	case o.BatchParallel < 1:
+		return errors.New("-batch-parallel 至少为 1")
+	case o.MaxFFmpeg < 0:
+		return errors.New("-max-ffmpeg 不能为负数（0 表示不限制）")
+	}
+	if o.UpgradeOnly {
+		o.UpgradeDst = true
+	}
+	if o.FolderKeep {
+		o.Folders = true
+	}
+	if o.Classical {
+		// 只调整用户未显式指定的参数
+		if explicit["seconds"] == "" {
+			o.Seconds = 30
+		}
+		if explicit["threshold"] == "" {
+			o.Threshold = 4
+		}
+	}
+	return nil
+}
+
+// StdoutTaken 返回 stdout 是否被 JSON 摘要或报告占用（此时人类可读的输出改写到 stderr）
+func (o *Options) StdoutTaken() bool {
+	return o.SummaryJSON || (o.Report == report.StdoutPath && !o.NoReport)
+}
+
+// inputPaths 为取值是输入文件或目录（运行前已存在、各任务共用）的参数；-plugin 为逗号分隔的多个路径
+var inputPaths = map[string]bool{
+	"cache": true, "decisions": true, "overrides": true, "never-match": true, "confirmed": true, "feedback": true,
+	"rules": true, "title-patterns": true, "plugin": true, "audit-key": true, "spill-dir": true, "quarantine": true,
+	"ref": true,
+}
+
+// Args 返回在另一个工作目录中重现 fs 里显式指定的参数（skip 中的除外）所需的命令行参数，
+// 如批量模式为每个子目录启动的子进程。输入文件与目录参数的相对路径转为绝对路径；
+// 输出文件参数保持原样，即相对于新的工作目录。
+func Args(fs *flag.FlagSet, skip ...string) ([]string, error) {
+	skipped := map[string]bool{}
+	for _, s := range skip {
+		skipped[s] = true
+	}
+	var args []string
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		if skipped[f.Name] || err != nil {
+			return
+		}
+		value := f.Value.String()
+		if inputPaths[f.Name] && value != "" {
+			parts := strings.Split(value, ",")
+			for i, p := range parts {
+				if parts[i], err = filepath.Abs(strings.TrimSpace(p)); err != nil {
+					return
+				}
+			}
+			value = strings.Join(parts, ",")
+		}
+		args = append(args, "-"+f.Name+"="+value)
+	})
+	return args, err
+}
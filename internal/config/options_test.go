@@ -0,0 +1,108 @@
+// file: internal/config/options_test.go
+// package: config
+//
+// 测试 Options：Load 合并命令行、配置文件与环境变量，Validate 检查冲突并补上隐含的取值，
+// Args 为子进程重建参数。
+package config
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"deduplicateMusic/internal/removal"
+)
+
+func load(t *testing.T, args ...string) (*Options, map[string]Source) {
+	t.Helper()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	o, sources, err := Load(fs, args)
+	if err != nil {
+		t.Fatalf("Load(%v): %v", args, err)
+	}
+	return o, sources
+}
+
+func TestLoadOptions(t *testing.T) {
+	o, _ := load(t)
+	if d := Defaults(); *o != d || o.Threshold != 8 || o.Mode != "copy" || !o.Gapless {
+		t.Fatalf("未指定参数时应为默认配置: %+v", *o)
+	}
+
+	path := filepath.Join(t.TempDir(), "c.yaml")
+	if err := os.WriteFile(path, []byte("threshold: 4\nkeep-policy: bitrate\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvName("workers"), "3")
+	o, sources := load(t, "-config", path, "-src", "in", "-dst", "out", "-threshold", "6")
+	if o.Src != "in" || o.Dst != "out" || o.Threshold != 6 || o.KeepPolicy != "bitrate" || o.Workers != 3 {
+		t.Fatalf("配置来源未正确合并: %+v", *o)
+	}
+	if sources["threshold"] != SourceFlag || sources["keep-policy"] != SourceFile || sources["workers"] != SourceEnv {
+		t.Fatalf("来源 = %v", sources)
+	}
+}
+
+func TestValidateOptions(t *testing.T) {
+	o, sources := load(t, "-src", "in", "-in-place", "-trash", "-classical", "-threshold", "6", "-folder-keep")
+	if err := o.Validate(sources); err != nil {
+		t.Fatal(err)
+	}
+	if o.Dst != "in" || o.RemoveMethod() != removal.MethodTrash || !o.Folders {
+		t.Fatalf("隐含的取值未补上: %+v", *o)
+	}
+	if o.Seconds != 30 || o.Threshold != 6 {
+		t.Fatalf("-classical 只应调整未显式指定的参数: seconds=%d threshold=%d", o.Seconds, o.Threshold)
+	}
+
+	o, sources = load(t)
+	if err := o.Validate(sources); !errors.Is(err, ErrNoDirs) {
+		t.Fatalf("缺少 -src/-dst 时应返回 ErrNoDirs，实际 %v", err)
+	}
+	for _, args := range [][]string{
+		{"-src", "in", "-dst", "out", "-delete"},
+		{"-src", "in", "-in-place"},
+		{"-src", "in", "-in-place", "-delete", "-trash"},
+		{"-src", "in", "-in-place", "-delete", "-upgrade-only"},
+		{"-src", "in", "-dst", "out", "-mode", "rename"},
+		{"-src", "in", "-dst", "out", "-mode", "move", "-assert-readonly-src"},
+		{"-src", "in", "-dst", "out", "-summary-json", "-report", "-"},
+		{"-src", "in", "-dst", "out", "-sync-plan", "plan.csv"},
+		{"-src", "in", "-dst", "out", "-batch", "-review"},
+		{"-src", "in", "-dst", "out", "-batch", "-mode", "move"},
+		{"-src", "in", "-dst", "out", "-ref", "in/library"},
+		{"-src", "in", "-dst", "lib/new", "-ref", "lib"},
+	} {
+		o, sources := load(t, args...)
+		if err := o.Validate(sources); err == nil {
+			t.Fatalf("%v 应校验失败", args)
+		}
+	}
+}
+
+func TestArgs(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	o := Defaults()
+	o.Register(fs)
+	if err := fs.Parse([]string{"-src", "in", "-threshold", "5", "-never-match", "nm.csv", "-du-out", "du.txt", "-batch"}); err != nil {
+		t.Fatal(err)
+	}
+	args, err := Args(fs, "src", "batch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	abs, _ := filepath.Abs("nm.csv")
+	want := []string{"-du-out=du.txt", "-never-match=" + abs, "-threshold=5"}
+	if len(args) != len(want) {
+		t.Fatalf("Args = %v", args)
+	}
+	for i := range want {
+		if args[i] != want[i] {
+			t.Fatalf("Args = %v，期望 %v", args, want)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+// file: internal/copyutil/backup.go
+// package: copyutil
+//
+// 替换目标目录中的文件前先备份：把旧文件移动到备份目录，保留其相对目标目录的路径。
+package copyutil
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// BackupDirName 为目标目录下存放备份的子目录名，扫描目标目录时应跳过
+const BackupDirName = ".audio-dedup-backup"
+
+// InBackupDir 判断 path 是否位于某个备份目录内
+func InBackupDir(path string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(path), "/") {
+		if part == BackupDirName {
+			return true
+		}
+	}
+	return false
+}
+
+// Backup 把 root 下的文件 path 移动到 backupRoot 下的相同相对路径，返回备份后的路径。
+// 不能直接重命名（如跨设备）时先复制再删除原文件（见 MoveFile）。
+func Backup(root, path, backupRoot string, ro ReadOnly) (string, error) {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = filepath.Base(path)
+	}
+	dst := filepath.Join(backupRoot, rel)
+	if err := MoveFile(path, dst, ro); err != nil {
+		return "", err
+	}
+	return dst, nil
+}
@@ -8,20 +8,34 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+
+	"deduplicateMusic/internal/quota"
 )
 
-// CopyFile 将 src 文件复制到 dst（若 dst 存在会被覆盖）。
+// CopyFile 将 src 文件复制到 dst（若 dst 存在会被覆盖），dst 位于 ro 内时拒绝写入。
 // 1) 确保 dst 目录存在
 // 2) 使用 io.Copy 复制内容并尝试复制权限
-func CopyFile(src, dst string) error {
-	if err := ensureDir(filepath.Dir(dst)); err != nil {
+func CopyFile(src, dst string, ro ReadOnly) error {
+	if err := ro.CheckWritable(dst); err != nil {
 		return err
 	}
-	in, err := os.Open(src)
+	// 源文件始终以只读方式打开
+	in, err := os.OpenFile(src, os.O_RDONLY, 0)
 	if err != nil {
 		return err
 	}
 	defer in.Close()
+	return CopyFromReader(in, dst, ro)
+}
+
+// CopyFromReader 把 in 的内容写入 dst（先写临时文件再重命名），用于远程源等非本地文件。
+func CopyFromReader(in io.Reader, dst string, ro ReadOnly) error {
+	if err := ro.CheckWritable(dst); err != nil {
+		return err
+	}
+	if err := ensureDir(filepath.Dir(dst)); err != nil {
+		return err
+	}
 
 	// 创建临时文件然后重命名，降低写出中途失败的风险
 	tmp := dst + ".tmp"
@@ -29,7 +43,7 @@ func CopyFile(src, dst string) error {
 	if err != nil {
 		return err
 	}
-	_, err = io.Copy(out, in)
+	_, err = io.Copy(out, quota.Reader(in))
 	if cerr := out.Close(); err == nil {
 		err = cerr
 	}
@@ -0,0 +1,114 @@
+// file: internal/copyutil/copy_test.go
+// package: copyutil
+//
+// 测试只读目录保护：写入受保护目录应失败，写入其它目录正常。
+package copyutil
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyFileRespectsReadOnlyDir(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	srcFile := filepath.Join(src, "a.mp3")
+	if err := os.WriteFile(srcFile, []byte("dummy"), 0o644); err != nil {
+		t.Fatalf("写临时文件失败: %v", err)
+	}
+	ro, err := ReadOnly(nil).Protect(src)
+	if err != nil {
+		t.Fatalf("Protect 错误: %v", err)
+	}
+
+	err = CopyFile(srcFile, filepath.Join(src, "sub", "b.mp3"), ro)
+	if !errors.Is(err, ErrReadOnlySource) {
+		t.Fatalf("期望 ErrReadOnlySource，实际 %v", err)
+	}
+	if err := CopyFile(srcFile, filepath.Join(dst, "a.mp3"), ro); err != nil {
+		t.Fatalf("复制到非保护目录失败: %v", err)
+	}
+	// 只读目录只对传入它的调用生效
+	if err := CopyFile(srcFile, filepath.Join(src, "c.mp3"), nil); err != nil {
+		t.Fatalf("未传入只读目录时复制失败: %v", err)
+	}
+}
+
+func TestIsWithin(t *testing.T) {
+	if !IsWithin("/music", "/music/out") || !IsWithin("/music", "/music") {
+		t.Fatalf("子目录应判定为位于 root 内")
+	}
+	if IsWithin("/music", "/music2/a.mp3") || IsWithin("/music/out", "/music") {
+		t.Fatalf("兄弟/父目录不应判定为位于 root 内")
+	}
+}
+
+func TestBackup(t *testing.T) {
+	root := t.TempDir()
+	old := filepath.Join(root, "Artist", "a.mp3")
+	if err := os.MkdirAll(filepath.Dir(old), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(old, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	backupRoot := filepath.Join(root, BackupDirName, "run1")
+	got, err := Backup(root, old, backupRoot, nil)
+	if err != nil {
+		t.Fatalf("Backup 错误: %v", err)
+	}
+	if want := filepath.Join(backupRoot, "Artist", "a.mp3"); got != want {
+		t.Fatalf("备份路径 = %s，期望 %s", got, want)
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Fatalf("原文件应已移走: %v", err)
+	}
+	if b, _ := os.ReadFile(got); string(b) != "old" {
+		t.Fatalf("备份内容 = %q", b)
+	}
+	if !InBackupDir(got) || InBackupDir(old) {
+		t.Fatal("InBackupDir 判断错误")
+	}
+}
+
+func TestTransferModes(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	for _, mode := range []Mode{ModeCopy, ModeHardlink, ModeSymlink, ModeMove} {
+		in := filepath.Join(src, string(mode)+".flac")
+		if err := os.WriteFile(in, []byte("audio"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		out := filepath.Join(dst, "sub", string(mode)+".flac")
+		if err := Transfer(mode, in, out, nil); err != nil {
+			t.Fatalf("%s: %v", mode, err)
+		}
+		if b, err := os.ReadFile(out); err != nil || string(b) != "audio" {
+			t.Fatalf("%s: 目标内容 = %q, %v", mode, b, err)
+		}
+		_, err := os.Stat(in)
+		if mode.ModifiesSource() != os.IsNotExist(err) {
+			t.Fatalf("%s: 源文件状态不正确: %v", mode, err)
+		}
+	}
+	if fi, err := os.Lstat(filepath.Join(dst, "sub", "symlink.flac")); err != nil || fi.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("symlink 方式应生成符号链接: %v", err)
+	}
+
+	// 移动受保护目录中的文件应被拒绝
+	in := filepath.Join(src, "ro.flac")
+	if err := os.WriteFile(in, []byte("audio"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ro, err := ReadOnly(nil).Protect(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Transfer(ModeMove, in, filepath.Join(dst, "ro.flac"), ro); !errors.Is(err, ErrReadOnlySource) {
+		t.Fatalf("期望 ErrReadOnlySource，实际 %v", err)
+	}
+	if _, err := ParseMode("reflink"); err == nil {
+		t.Fatal("未知方式应报错")
+	}
+}
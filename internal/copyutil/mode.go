@@ -0,0 +1,95 @@
+// file: internal/copyutil/mode.go
+// package: copyutil
+//
+// 输出方式：保留文件除复制外还可以移动、硬链接或符号链接到目标目录，
+// 大型资料库无需占用双倍磁盘空间。
+package copyutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// Mode 为保留文件放入目标目录的方式
+type Mode string
+
+const (
+	ModeCopy     Mode = "copy"     // 复制（默认）
+	ModeMove     Mode = "move"     // 移动：同一文件系统内原子重命名，跨文件系统时复制后删除源文件
+	ModeHardlink Mode = "hardlink" // 硬链接：不占用额外空间，要求与源文件位于同一文件系统
+	ModeSymlink  Mode = "symlink"  // 符号链接：指向源文件的绝对路径
+)
+
+// ParseMode 解析 -mode 参数
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(s); m {
+	case ModeCopy, ModeMove, ModeHardlink, ModeSymlink:
+		return m, nil
+	}
+	return "", fmt.Errorf("无效的输出方式 %q（可选 copy、move、hardlink、symlink）", s)
+}
+
+// ModifiesSource 返回该方式是否会改动源目录
+func (m Mode) ModifiesSource() bool { return m == ModeMove }
+
+// Transfer 按 mode 把 src 放到 dst（dst 已存在时被替换），写入 ro 内的路径时返回 ErrReadOnlySource
+func Transfer(mode Mode, src, dst string, ro ReadOnly) error {
+	switch mode {
+	case ModeMove:
+		return MoveFile(src, dst, ro)
+	case ModeHardlink:
+		return linkFile(src, dst, ro, os.Link)
+	case ModeSymlink:
+		abs, err := filepath.Abs(src)
+		if err != nil {
+			return err
+		}
+		return linkFile(abs, dst, ro, os.Symlink)
+	}
+	return CopyFile(src, dst, ro)
+}
+
+// MoveFile 把 src 移动到 dst（两者都不能位于 ro 内）：先尝试重命名（同一文件系统内为原子操作），
+// 只有跨文件系统（EXDEV）时才复制到 dst 后再删除 src，其它重命名错误原样返回。
+func MoveFile(src, dst string, ro ReadOnly) error {
+	if err := ro.CheckWritable(src); err != nil {
+		return err
+	}
+	if err := ro.CheckWritable(dst); err != nil {
+		return err
+	}
+	if err := ensureDir(filepath.Dir(dst)); err != nil {
+		return err
+	}
+	err := os.Rename(src, dst)
+	if err == nil || !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	if err := CopyFile(src, dst, ro); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// linkFile 先在临时名上建立链接再重命名为 dst，避免替换已有文件的中途留下空缺
+func linkFile(src, dst string, ro ReadOnly, link func(oldname, newname string) error) error {
+	if err := ro.CheckWritable(dst); err != nil {
+		return err
+	}
+	if err := ensureDir(filepath.Dir(dst)); err != nil {
+		return err
+	}
+	tmp := dst + ".tmp"
+	_ = os.Remove(tmp)
+	if err := link(src, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
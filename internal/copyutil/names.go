@@ -0,0 +1,175 @@
+// file: internal/copyutil/names.go
+// package: copyutil
+//
+// 目标文件名冲突：保留文件平铺到目标目录时，不同来源的同名文件会互相覆盖。
+// 是否 “同名” 取决于目标文件系统：macOS（APFS/HFS+ 默认）与 Windows（NTFS）不区分大小写，
+// "Track.MP3" 与 "track.mp3" 是同一个文件；Linux 上则是两个文件。CaseSensitive 实际探测目标目录，
+// Names 按探测结果记录本次运行已使用的目标路径，Resolve 按冲突策略（-on-collision）处理
+// 与本次运行的其它文件或目标目录中已有的不同文件同名的情况。
+package copyutil
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CollisionPolicy 为目标文件名冲突时的处理方式
+type CollisionPolicy string
+
+const (
+	CollisionRename    CollisionPolicy = "rename"    // 在扩展名前追加 " (2)"、" (3)" 等（默认）
+	CollisionSkip      CollisionPolicy = "skip"      // 不放入该文件
+	CollisionError     CollisionPolicy = "error"     // 不放入该文件并按处理失败记录
+	CollisionOverwrite CollisionPolicy = "overwrite" // 覆盖已有文件
+)
+
+// ParseCollisionPolicy 解析 -on-collision 参数
+func ParseCollisionPolicy(s string) (CollisionPolicy, error) {
+	switch p := CollisionPolicy(s); p {
+	case CollisionRename, CollisionSkip, CollisionError, CollisionOverwrite:
+		return p, nil
+	}
+	return "", fmt.Errorf("无效的冲突处理方式 %q（可选 rename、skip、error、overwrite）", s)
+}
+
+// CaseSensitive 探测 dir 所在文件系统是否区分文件名大小写：在 dir 中创建一个小写名字的临时文件，
+// 再以大写名字访问，能访问到同一文件即不区分。dir 须已存在且可写。
+func CaseSensitive(dir string) (bool, error) {
+	f, err := os.CreateTemp(dir, ".audio-dedup-case-*")
+	if err != nil {
+		return true, err
+	}
+	name := f.Name()
+	f.Close()
+	defer os.Remove(name)
+	fi, err := os.Stat(name)
+	if err != nil {
+		return true, err
+	}
+	upper := filepath.Join(filepath.Dir(name), strings.ToUpper(filepath.Base(name)))
+	ui, err := os.Stat(upper)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return true, nil
+		}
+		return true, err
+	}
+	return !os.SameFile(fi, ui), nil
+}
+
+// Names 记录已分配的目标路径，按文件系统的大小写规则判断冲突。非并发安全。
+type Names struct {
+	fold bool // 不区分大小写：比较前统一转为小写
+	used map[string]bool
+}
+
+// NewNames 返回空的目标路径记录；caseSensitive 通常取 CaseSensitive(目标目录) 的结果
+func NewNames(caseSensitive bool) *Names {
+	return &Names{fold: !caseSensitive, used: map[string]bool{}}
+}
+
+func (n *Names) key(p string) string {
+	p = filepath.Clean(p)
+	if n.fold {
+		p = strings.ToLower(p)
+	}
+	return p
+}
+
+// Taken 返回 p 是否与已分配的路径冲突
+func (n *Names) Taken(p string) bool { return n.used[n.key(p)] }
+
+// Add 把 p 记为已占用
+func (n *Names) Add(p string) { n.used[n.key(p)] = true }
+
+// Claim 分配目标路径：p 未被占用时原样返回，否则在扩展名前追加 " (2)"、" (3)" 等，
+// 返回第一个未被占用的路径。返回的路径随即记为已占用。
+func (n *Names) Claim(p string) string {
+	ext := filepath.Ext(p)
+	stem := strings.TrimSuffix(p, ext)
+	for i := 2; n.Taken(p); i++ {
+		p = fmt.Sprintf("%s (%d)%s", stem, i, ext)
+	}
+	n.Add(p)
+	return p
+}
+
+// Resolve 为源文件 src 分配目标路径 p。p 已分配给本次运行的其它文件，或 p 处已有内容不同的文件时
+// 视为冲突（内容相同的文件，如上次运行放入的同一文件，不算冲突），按 policy 处理：rename 返回
+// 第一个空闲的带后缀路径，overwrite 返回 p，skip 与 error 返回空串且不占用 p。
+// src 无法在本地打开（如远程文件）时只按大小 size 比较内容。
+func (n *Names) Resolve(p, src string, size int64, policy CollisionPolicy) (target string, collided bool) {
+	if !n.Taken(p) && !occupied(p, src, size) {
+		n.used[n.key(p)] = true
+		return p, false
+	}
+	switch policy {
+	case CollisionSkip, CollisionError:
+		return "", true
+	case CollisionOverwrite:
+		n.used[n.key(p)] = true
+		return p, true
+	}
+	ext := filepath.Ext(p)
+	stem := strings.TrimSuffix(p, ext)
+	for i := 2; ; i++ {
+		q := fmt.Sprintf("%s (%d)%s", stem, i, ext)
+		if !n.Taken(q) && !occupied(q, src, size) {
+			n.used[n.key(q)] = true
+			return q, true
+		}
+	}
+}
+
+// occupied 返回 p 处是否已有与 src 内容不同的文件
+func occupied(p, src string, size int64) bool {
+	fi, err := os.Lstat(p)
+	if err != nil {
+		return false
+	}
+	if !fi.Mode().IsRegular() || fi.Size() != size {
+		return true
+	}
+	if si, err := os.Stat(src); err == nil && os.SameFile(fi, si) {
+		return false // 如 hardlink 方式上次放入的同一文件
+	}
+	same, err := sameContent(p, src)
+	if err != nil {
+		return !strings.Contains(src, "://") // 远程文件无法在本地比较，大小相同即视为同一文件
+	}
+	return !same
+}
+
+// sameContent 逐块比较两个文件的内容
+func sameContent(a, b string) (bool, error) {
+	fa, err := os.Open(a)
+	if err != nil {
+		return false, err
+	}
+	defer fa.Close()
+	fb, err := os.Open(b)
+	if err != nil {
+		return false, err
+	}
+	defer fb.Close()
+	ba, bb := make([]byte, 64<<10), make([]byte, 64<<10)
+	for {
+		na, ea := io.ReadFull(fa, ba)
+		nb, eb := io.ReadFull(fb, bb)
+		if na != nb || !bytes.Equal(ba[:na], bb[:nb]) {
+			return false, nil
+		}
+		switch {
+		case ea == io.EOF || ea == io.ErrUnexpectedEOF:
+			return eb == ea, nil
+		case ea != nil:
+			return false, ea
+		case eb != nil:
+			return false, eb
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// file: internal/copyutil/names_test.go
+// package: copyutil
+//
+// 测试目标文件名冲突：大小写探测、Names 在区分 / 不区分大小写时的冲突判断与改名，以及各冲突策略。
+package copyutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCaseSensitive(t *testing.T) {
+	dir := t.TempDir()
+	got, err := CaseSensitive(dir)
+	if err != nil {
+		t.Fatalf("CaseSensitive 错误: %v", err)
+	}
+	// 用一个已知文件核对探测结果
+	if err := os.WriteFile(filepath.Join(dir, "probe.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = os.Stat(filepath.Join(dir, "PROBE.TXT"))
+	if want := os.IsNotExist(err); got != want {
+		t.Fatalf("CaseSensitive = %v，期望 %v", got, want)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Fatalf("探测文件未清理: %v", entries)
+	}
+	if _, err := CaseSensitive(filepath.Join(dir, "missing")); err == nil {
+		t.Fatalf("目录不存在时应返回错误")
+	}
+}
+
+func TestNamesTaken(t *testing.T) {
+	dst := filepath.Join("out", "Track.MP3")
+	sensitive := NewNames(true)
+	sensitive.Add(dst)
+	if !sensitive.Taken(dst) {
+		t.Fatalf("已记录的路径应视为冲突")
+	}
+	if sensitive.Taken(filepath.Join("out", "track.mp3")) {
+		t.Fatalf("区分大小写时 track.mp3 不应与 Track.MP3 冲突")
+	}
+	if sensitive.Taken(filepath.Join("other", "Track.MP3")) {
+		t.Fatalf("不同目录的同名文件不应冲突")
+	}
+
+	folded := NewNames(false)
+	folded.Add(dst)
+	for _, p := range []string{"out/TRACK.mp3", "out/track.mp3", "out/./Track.MP3"} {
+		if !folded.Taken(filepath.FromSlash(p)) {
+			t.Fatalf("不区分大小写时 %s 应与 Track.MP3 冲突", p)
+		}
+	}
+}
+
+func TestNamesClaim(t *testing.T) {
+	dst := filepath.Join("out", "Track.MP3")
+	sensitive := NewNames(true)
+	if p := sensitive.Claim(dst); p != dst {
+		t.Fatalf("首次分配不应改名: %s", p)
+	}
+	if p := sensitive.Claim(filepath.Join("out", "track.mp3")); p != filepath.Join("out", "track.mp3") {
+		t.Fatalf("区分大小写时 track.mp3 不应与 Track.MP3 冲突: %s", p)
+	}
+	if p := sensitive.Claim(dst); p != filepath.Join("out", "Track (2).MP3") {
+		t.Fatalf("同名应追加 (2): %s", p)
+	}
+
+	folded := NewNames(false)
+	folded.Claim(dst)
+	if p := folded.Claim(filepath.Join("out", "track.mp3")); p != filepath.Join("out", "track (2).mp3") {
+		t.Fatalf("期望 track (2).mp3，实际 %s", p)
+	}
+	if p := folded.Claim(filepath.Join("out", "TRACK.MP3")); p != filepath.Join("out", "TRACK (3).MP3") {
+		t.Fatalf("期望 TRACK (3).MP3，实际 %s", p)
+	}
+}
+
+func TestNamesResolve(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	write := func(p, s string) string {
+		if err := os.WriteFile(p, []byte(s), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	a := write(filepath.Join(src, "a.mp3"), "aaaa")
+	b := write(filepath.Join(src, "b.mp3"), "bbbb")
+	target := filepath.Join(dst, "t.mp3")
+	write(target, "aaaa")                        // 上次运行放入的同一文件
+	write(filepath.Join(dst, "t (2).mp3"), "cc") // 目标目录中已有的其它文件
+
+	n := NewNames(true)
+	if p, collided := n.Resolve(target, a, 4, CollisionRename); p != target || collided {
+		t.Fatalf("内容相同的已有文件不算冲突: %s %v", p, collided)
+	}
+	if p, collided := n.Resolve(target, b, 4, CollisionRename); p != filepath.Join(dst, "t (3).mp3") || !collided {
+		t.Fatalf("应跳过已分配与已存在的名字: %s %v", p, collided)
+	}
+	for _, c := range []struct {
+		policy CollisionPolicy
+		want   string
+	}{{CollisionSkip, ""}, {CollisionError, ""}, {CollisionOverwrite, target}} {
+		if p, collided := NewNames(true).Resolve(target, b, 4, c.policy); p != c.want || !collided {
+			t.Fatalf("%s: %q %v", c.policy, p, collided)
+		}
+	}
+	if p, collided := NewNames(true).Resolve(target, "http://host/t.mp3", 4, CollisionRename); p != target || collided {
+		t.Fatalf("远程文件大小相同时视为同一文件: %s %v", p, collided)
+	}
+	if _, err := ParseCollisionPolicy("merge"); err == nil {
+		t.Fatalf("未知策略应返回错误")
+	}
+}
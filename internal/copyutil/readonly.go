@@ -0,0 +1,59 @@
+// file: internal/copyutil/readonly.go
+// package: copyutil
+//
+// 只读源目录保护：只读目录集合（ReadOnly）中的目录下不允许任何写操作（复制目标、临时文件等），
+// 用于 -assert-readonly-src 模式与参考资料库，保证工具不会改动源归档。
+package copyutil
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ErrReadOnlySource 表示写操作命中了受保护的只读目录。
+var ErrReadOnlySource = errors.New("目标位于只读源目录内，拒绝写入")
+
+// ReadOnly 为不允许写入的目录（绝对路径），由调用方持有并传给各写操作；零值不保护任何目录
+type ReadOnly []string
+
+// Protect 返回追加了 dir（转换为绝对路径）的只读目录集合，ro 本身不变
+func (ro ReadOnly) Protect(dir string) (ReadOnly, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return ro, err
+	}
+	return append(ro[:len(ro):len(ro)], filepath.Clean(abs)), nil
+}
+
+// CheckWritable 检查 path 是否允许写入；若位于 ro 中任一目录内返回 ErrReadOnlySource。
+func (ro ReadOnly) CheckWritable(path string) error {
+	if len(ro) == 0 {
+		return nil
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	for _, root := range ro {
+		if IsWithin(root, abs) {
+			return fmt.Errorf("%w: %s", ErrReadOnlySource, path)
+		}
+	}
+	return nil
+}
+
+// IsWithin 判断 path 是否等于 root 或位于 root 之下（均按绝对路径比较）。
+func IsWithin(root, path string) bool {
+	ra, err1 := filepath.Abs(root)
+	pa, err2 := filepath.Abs(path)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	rel, err := filepath.Rel(ra, pa)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
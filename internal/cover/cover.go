@@ -0,0 +1,119 @@
+// file: internal/cover/cover.go
+// package: cover
+//
+// 翻唱/同曲不同录音检测（实验性）：计算色度（chroma，12 个音级的能量分布）序列，
+// 在 12 种移调与一定时间偏移范围内寻找两段序列的最大平均余弦相似度。
+// 结果只作为参考信息单独输出，绝不参与去重或任何文件操作。
+package cover
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+const (
+	frameSize  = 4096 // 8kHz 下约 0.5 秒，频率分辨率约 2Hz，足以区分低音区的半音
+	minFreq    = 55.0 // A1
+	maxFreq    = 3520.0
+	sampleRate = 8000
+)
+
+// Chroma 为按帧排列的 12 维音级向量（C=0 … B=11），每帧已做 L2 归一化
+type Chroma [][12]float64
+
+// Compute 计算 8kHz 单声道样本的色度序列
+func Compute(samples []int16) Chroma {
+	var c Chroma
+	buf := make([]complex128, frameSize)
+	for start := 0; start+frameSize <= len(samples); start += frameSize {
+		for i := range buf {
+			w := 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/frameSize)
+			buf[i] = complex(float64(samples[start+i])/32768*w, 0)
+		}
+		fft(buf)
+		var v [12]float64
+		for k := 1; k < frameSize/2; k++ {
+			f := float64(k) * sampleRate / frameSize
+			if f < minFreq || f > maxFreq {
+				continue
+			}
+			pc := (int(math.Round(12*math.Log2(f/440))) + 9 + 12*10) % 12 // A=9
+			m := cmplx.Abs(buf[k])
+			v[pc] += m * m
+		}
+		var norm float64
+		for _, x := range v {
+			norm += x * x
+		}
+		if norm == 0 {
+			continue // 静音帧不参与比较
+		}
+		norm = math.Sqrt(norm)
+		for i := range v {
+			v[i] /= norm
+		}
+		c = append(c, v)
+	}
+	return c
+}
+
+// fft 为原地基 2 FFT，len(x) 必须为 2 的幂
+func fft(x []complex128) {
+	n := len(x)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				a, b := x[start+k], x[start+k+size/2]*w
+				x[start+k], x[start+k+size/2] = a+b, a-b
+				w *= step
+			}
+		}
+	}
+}
+
+// Similarity 返回 a 与 b 在所有移调（0..11 半音）与 ±maxLag 帧偏移下的最大平均余弦相似度，
+// 以及取得最大值时 b 相对 a 的移调半音数。重叠帧数少于较短序列一半的偏移不计。
+func Similarity(a, b Chroma, maxLag int) (score float64, transpose int) {
+	minLen := len(a)
+	if len(b) < minLen {
+		minLen = len(b)
+	}
+	if minLen == 0 {
+		return 0, 0
+	}
+	for t := 0; t < 12; t++ {
+		for lag := -maxLag; lag <= maxLag; lag++ {
+			var sum float64
+			n := 0
+			for i := range a {
+				j := i - lag
+				if j < 0 || j >= len(b) {
+					continue
+				}
+				for p := 0; p < 12; p++ {
+					sum += a[i][p] * b[j][(p+t)%12]
+				}
+				n++
+			}
+			if n*2 < minLen {
+				continue
+			}
+			if s := sum / float64(n); s > score {
+				score, transpose = s, t
+			}
+		}
+	}
+	return score, transpose
+}
@@ -0,0 +1,37 @@
+// file: internal/cover/cover_test.go
+// package: cover
+//
+// 用合成的"旋律"测试：移调后的同一旋律得分高且能识别移调量，不同旋律得分明显更低。
+package cover
+
+import (
+	"math"
+	"testing"
+)
+
+// melody 把音符（相对 A4 的半音数）依次合成为每个 0.512 秒的正弦音
+func melody(notes []int, shift int) []int16 {
+	var s []int16
+	for _, n := range notes {
+		f := 440 * math.Pow(2, float64(n+shift)/12)
+		for i := 0; i < frameSize; i++ {
+			s = append(s, int16(8000*math.Sin(2*math.Pi*f*float64(i)/sampleRate)))
+		}
+	}
+	return s
+}
+
+func TestSimilarityTransposed(t *testing.T) {
+	notes := []int{0, 2, 4, 5, 7, 5, 4, 2, 0, -5, -3, -1}
+	a := Compute(melody(notes, 0))
+	b := Compute(melody(notes, 3)) // 升 3 个半音的翻唱
+	other := Compute(melody([]int{0, 0, 6, 6, 1, 1, 8, 8, 3, 3, 10, 10}, 0))
+
+	score, tr := Similarity(a, b, 2)
+	if score < 0.9 || tr != 3 {
+		t.Fatalf("移调旋律: score=%.2f transpose=%d", score, tr)
+	}
+	if s, _ := Similarity(a, other, 2); s >= score-0.2 {
+		t.Fatalf("不同旋律得分过高: %.2f（同曲 %.2f）", s, score)
+	}
+}
@@ -0,0 +1,74 @@
+// file: internal/dedup/apart.go
+// package: dedup
+//
+// 不可合并约束（Options.Apart）：并查集的传递性会让两个互不匹配的文件经由中间文件落入同一组，
+// 单纯让匹配器对这对文件返回 false 不够。对含有不可合并对的分量，按距离从小到大重新合并
+// 匹配的文件对（Kruskal 式），跳过会让任一不可合并对落入同组的合并。
+package dedup
+
+import "sort"
+
+// splitApart 把分量 idxs 拆成不含不可合并对的若干组；分量本身没有不可合并对时原样返回
+func splitApart(files []FileMeta, idxs []int, apart func(a, b FileMeta) bool, matcher Matcher) [][]int {
+	var banned [][2]int
+	for x, i := range idxs {
+		for _, j := range idxs[x+1:] {
+			if apart(files[i], files[j]) {
+				banned = append(banned, [2]int{i, j})
+			}
+		}
+	}
+	if len(banned) == 0 {
+		return [][]int{idxs}
+	}
+
+	type edge struct{ i, j, d int }
+	var edges []edge
+	for x, i := range idxs {
+		for _, j := range idxs[x+1:] {
+			if matcher.Match(files[i], files[j]) {
+				edges = append(edges, edge{i, j, memberDistance(matcher, files[i], files[j])})
+			}
+		}
+	}
+	sort.SliceStable(edges, func(a, b int) bool { return edges[a].d < edges[b].d })
+
+	parent := map[int]int{}
+	for _, i := range idxs {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(x int) int {
+		if parent[x] != x {
+			parent[x] = find(parent[x])
+		}
+		return parent[x]
+	}
+	for _, e := range edges {
+		ra, rb := find(e.i), find(e.j)
+		if ra == rb {
+			continue
+		}
+		ok := true
+		for _, p := range banned {
+			fa, fb := find(p[0]), find(p[1])
+			if (fa == ra && fb == rb) || (fa == rb && fb == ra) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			parent[rb] = ra
+		}
+	}
+	parts := map[int][]int{}
+	for _, i := range idxs {
+		r := find(i)
+		parts[r] = append(parts[r], i)
+	}
+	out := make([][]int, 0, len(parts))
+	for _, p := range parts {
+		out = append(out, p)
+	}
+	return out
+}
@@ -0,0 +1,92 @@
+// file: internal/dedup/bands.go
+// package: dedup
+//
+// 分段索引（multi-index hashing）：把 64 位指纹切成 threshold+1 段，按每段的取值分桶。
+// 由鸽巢原理，汉明距离 <= threshold 的两个指纹至少有一段完全相同，因此只需比较
+// 至少在一段上同桶的候选对，结果与全量比较一致，但比较次数从 N² 降到与桶大小相关。
+// 阈值很大时每段位数太少、桶太大，剪枝失效，此时退回全量比较。
+// 另可选用 BK 树（internal/index，见 Options.TreeMinFiles）。
+package dedup
+
+import (
+	"sort"
+
+	"deduplicateMusic/internal/index"
+)
+
+// maxBandedThreshold 为使用分段索引的最大阈值：超过时每段不足 4 位，剪枝效果很差
+const maxBandedThreshold = 15
+
+// bandLayout 返回把 64 位切成 threshold+1 段时每段的（起始位，位数），位数相差不超过 1
+func bandLayout(threshold int) [][2]uint {
+	n := threshold + 1
+	bands := make([][2]uint, n)
+	start := uint(0)
+	for b := 0; b < n; b++ {
+		width := uint(64 / n)
+		if b < 64%n {
+			width++
+		}
+		bands[b] = [2]uint{start, width}
+		start += width
+	}
+	return bands
+}
+
+// connectBanded 用分段索引找出候选对并用批量内核精确比较，返回 根 -> 成员下标 的分组
+func connectBanded(fps []uint64, threshold int) map[int][]int {
+	if threshold < 0 || threshold > maxBandedThreshold {
+		return connectFPs(fps, threshold)
+	}
+	// 每段各自分桶，只保留至少两个成员的桶；桶内下标升序
+	var buckets [][]int
+	for _, band := range bandLayout(threshold) {
+		mask := uint64(1)<<band[1] - 1
+		index := make(map[uint64][]int)
+		for i, fp := range fps {
+			k := fp >> band[0] & mask
+			index[k] = append(index[k], i)
+		}
+		keys := make([]uint64, 0, len(index))
+		for k, idxs := range index {
+			if len(idxs) > 1 {
+				keys = append(keys, k)
+			}
+		}
+		sort.Slice(keys, func(a, b int) bool { return keys[a] < keys[b] })
+		for _, k := range keys {
+			buckets = append(buckets, index[k])
+		}
+	}
+
+	uf := newUnionFind(len(fps))
+	parallelFor(len(buckets), func(b int) {
+		idxs := buckets[b]
+		bfps := make([]uint64, len(idxs))
+		for x, i := range idxs {
+			bfps[x] = fps[i]
+		}
+		var hits []int32
+		for x := range idxs {
+			hits = hammingWithin(bfps[x], bfps[x+1:], threshold, hits[:0])
+			for _, y := range hits {
+				uf.union(idxs[x], idxs[x+1+int(y)])
+			}
+		}
+	})
+	return uf.groups()
+}
+
+// connectTree 用 BK 树查询每个指纹的近邻，返回 根 -> 成员下标 的分组
+func connectTree(fps []uint64, threshold int) map[int][]int {
+	tree := index.Build(fps)
+	uf := newUnionFind(len(fps))
+	parallelFor(len(fps), func(i int) {
+		tree.Search(fps[i], threshold, func(j, _ int) {
+			if j > i {
+				uf.union(i, j)
+			}
+		})
+	})
+	return uf.groups()
+}
@@ -0,0 +1,27 @@
+// file: internal/dedup/classical.go
+// package: dedup
+//
+// 古典音乐：同一作品有大量录音，曲名与时长往往相同，仅凭指纹容易把不同乐章/不同作品误判为重复。
+// TagAgreement 在指纹匹配之外要求作曲家/作品/乐章标签一致。只有一方有值时视为不一致，
+// 否则缺标签的文件会在并查集中把不同乐章桥接到同一组。
+package dedup
+
+import "deduplicateMusic/internal/textnorm"
+
+// TagAgreement 包装 base：base 匹配且作曲家、作品、乐章标签一致（双方都没有该标签也算一致），才视为重复
+func TagAgreement(base Matcher) Matcher {
+	return MatcherFunc(func(a, b FileMeta) bool {
+		return tagsAgree(a.Tags.Composer, b.Tags.Composer) &&
+			tagsAgree(a.Tags.Work, b.Tags.Work) &&
+			tagsAgree(a.Tags.Movement, b.Tags.Movement) &&
+			base.Match(a, b)
+	})
+}
+
+// tagsAgree 规范化后比较（忽略大小写、空白、全角/半角与繁简差异，见 textnorm）
+func tagsAgree(x, y string) bool {
+	if x == "" || y == "" {
+		return x == y
+	}
+	return textnorm.Equal(x, y)
+}
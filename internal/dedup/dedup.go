@@ -5,12 +5,24 @@
 //   - 数据结构 FileMeta 保存文件路径、大小、指纹。
 //   - 使用 union-find（并查集）把“相似”文件（汉明距离 <= threshold）连成组件。
 //   - 对每个组件选择文件大小最大的作为保留（如果大小相同则按路径字典序保留第一个）。
+//   - 匹配规则与保留策略可通过 Options 替换（见 policy.go）。
+//   - 结果与输入顺序、并发度无关：组内排序在策略打平时按路径决定，组按保留文件路径排序。
 package dedup
 
 import (
+	"crypto/sha256"
+	"deduplicateMusic/internal/filestamp"
 	"deduplicateMusic/internal/fingerprint"
+	"deduplicateMusic/internal/tags"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/bits"
+	"runtime"
 	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // FileMeta 表示已计算指纹的文件信息
@@ -18,62 +30,399 @@ type FileMeta struct {
 	Path string
 	Size int64
 	FP   uint64
+	Tags tags.Tags // 文件标签（可能为空）
+
+	Envelope  []uint8 `json:",omitempty"` // 波形包络，仅在需要缩略图时计算
+	Integrity string  `json:",omitempty"` // FLAC 解码校验结果（ok / corrupt / no-md5），未校验时为空
+	// AltFPs 为变速版本的指纹（见 fingerprint.Options.SpeedFactors），仅速度容忍模式下计算
+	AltFPs []uint64 `json:",omitempty"`
+	// StreamErrors 为 MP3 帧扫描发现的错误数（损坏位置 + 截断），未扫描时为 0
+	StreamErrors int `json:",omitempty"`
+	// Stamp 为计算指纹时的文件快照，执行前用于发现期间被改动的文件（见 filestamp）
+	Stamp filestamp.Stamp
+	// Blocks 为量化前的块平均幅度，仅在使用需要它的距离度量（如 cosine）时保留
+	Blocks []float32 `json:",omitempty"`
+	// Segments 为多窗口指纹（开头、中段、结尾），仅在 -segments > 1 时计算
+	Segments []uint64 `json:",omitempty"`
+	// ModTime 为扫描时的修改时间（未知时为零值），供 oldest / newest 保留策略使用
+	ModTime time.Time `json:",omitempty"`
+	// Duration 为时长（秒），仅在保留策略需要时长或码率时读取（见 NeedsDuration），未知为 0
+	Duration float64 `json:",omitempty"`
+	// SHA256 为文件内容的 SHA-256（十六进制），只对大小与其它文件相同的文件计算（见 internal/exact）
+	SHA256 string `json:",omitempty"`
+}
+
+// Member 表示组内一个未被保留的重复文件
+type Member struct {
+	FileMeta
+	Distance int // 与保留文件的汉明距离
+}
+
+// Group 表示一个相似文件组件：Keep 为保留文件，Duplicates 为其余成员。
+// Protected 为受保护规则命中、因而与 Keep 一同保留的其余成员。
+type Group struct {
+	ID         int
+	Keep       FileMeta
+	Protected  []FileMeta
+	Duplicates []Member
 }
 
 // SelectKeep 接受文件列表与阈值（汉明距离），返回保留的文件列表。
 // 算法：对每对文件比较，若汉明距离 <= threshold 则 union(i,j)；最后对每个并查集选择最大文件。
 func SelectKeep(files []FileMeta, threshold int) []FileMeta {
+	groups := GroupFiles(files, threshold)
+	keeps := make([]FileMeta, 0, len(groups))
+	for _, g := range groups {
+		keeps = append(keeps, g.Keep)
+		keeps = append(keeps, g.Protected...)
+	}
+	return keeps
+}
+
+// Options 控制分组与保留选择
+type Options struct {
+	Threshold int        // 汉明距离阈值（默认匹配器使用）
+	Policy    KeepPolicy // 为 nil 时使用 DefaultPolicy
+	Matcher   Matcher    // 为 nil 时使用 HammingMatcher(Threshold)
+	// Protect 非 nil 时，返回 true 的文件永不作为重复被丢弃：
+	// 组内受保护文件优先成为保留文件，其余受保护文件进入 Group.Protected。
+	Protect func(FileMeta) bool
+	// ShardBits > 0 时按指纹高 ShardBits 位分片：分片内两两比较，分片之间仅比较
+	// 前缀汉明距离 <= Threshold 的分片对。要求匹配器满足“匹配 ⇒ 汉明距离 <= Threshold”
+	// （默认匹配器满足），此时结果与全量比较一致；分片位数明显大于阈值时剪枝效果才明显。
+	ShardBits int
+	// 默认匹配器默认使用分段索引（见 bands.go）只比较候选对，结果与全量比较一致；
+	// Exhaustive 为 true 时强制全量两两比较（用于核对或排查）。其它匹配器总是全量比较。
+	Exhaustive bool
+	// TreeMinFiles > 0 且文件数不少于该值时，默认匹配器改用 BK 树（internal/index）查找近邻。
+	// 随机分布的 64 位指纹上 BK 树通常不如分段索引与批量内核，默认不启用，供指纹高度聚集的资料库试用。
+	TreeMinFiles int
+	// Apart 非 nil 时，返回 true 的两个文件不会落入同一组（即使经由其它文件间接相连，见 apart.go）
+	Apart func(a, b FileMeta) bool
+	// Partition 非 nil 时先按其返回的键把文件分开，只在同一键内比较（见 tagkey.go）
+	Partition func(FileMeta) string
+}
+
+// GroupFiles 与 SelectKeep 相同的分组逻辑，但返回完整的分组（保留文件 + 重复文件），
+// 组按保留文件路径排序，ID 从 1 开始。
+func GroupFiles(files []FileMeta, threshold int) []Group {
+	return GroupWith(files, Options{Threshold: threshold})
+}
+
+// GroupWith 按 opts 中的匹配器分组、按保留策略选择每组的保留文件。
+func GroupWith(files []FileMeta, opts Options) []Group {
 	n := len(files)
 	if n == 0 {
 		return nil
 	}
+	if opts.Partition != nil {
+		return groupPartitioned(files, opts)
+	}
+	policy := opts.Policy
+	if policy == nil {
+		policy = DefaultPolicy()
+	}
+	matcher := opts.Matcher
+	if matcher == nil {
+		matcher = HammingMatcher(opts.Threshold)
+	}
+	match := func(i, j int) bool { return matcher.Match(files[i], files[j]) }
+	var members map[int][]int
+	_, fpOnly := matcher.(hammingMatcher)
+	switch {
+	case opts.ShardBits > 0:
+		members = connectSharded(fingerprints(files), opts.ShardBits, opts.Threshold, match)
+	case fpOnly && !opts.Exhaustive && opts.TreeMinFiles > 0 && n >= opts.TreeMinFiles:
+		members = connectTree(fingerprints(files), opts.Threshold)
+	case fpOnly && opts.Exhaustive:
+		// 默认匹配器只看指纹，走批量比较内核
+		members = connectFPs(fingerprints(files), opts.Threshold)
+	case fpOnly:
+		members = connectBanded(fingerprints(files), opts.Threshold)
+	default:
+		members = connect(n, match)
+	}
+
+	if opts.Apart != nil {
+		split := make(map[int][]int, len(members))
+		for _, idxs := range members {
+			for _, part := range splitApart(files, idxs, opts.Apart, matcher) {
+				split[part[0]] = part
+			}
+		}
+		members = split
+	}
+
+	// 按保留策略选出每组最优文件（默认：size 最大，否则按字典序最小）
+	groups := make([]Group, 0, len(members))
+	protected := make([]bool, n)
+	if opts.Protect != nil {
+		for i := range files {
+			protected[i] = opts.Protect(files[i])
+		}
+	}
+	for _, idxs := range members {
+		// 并查集的根与成员顺序随并发调度变化；策略打平（或不是严格全序）时按路径、再按下标决定，
+		// 保证相同输入在任意并发度下选出同一个保留文件
+		sort.Slice(idxs, func(i, j int) bool {
+			a, b := idxs[i], idxs[j]
+			if protected[a] != protected[b] {
+				return protected[a]
+			}
+			if policy.Better(files[a], files[b]) {
+				return true
+			}
+			if policy.Better(files[b], files[a]) {
+				return false
+			}
+			if files[a].Path != files[b].Path {
+				return files[a].Path < files[b].Path
+			}
+			return a < b
+		})
+		g := Group{Keep: files[idxs[0]]}
+		for _, k := range idxs[1:] {
+			if protected[k] {
+				g.Protected = append(g.Protected, files[k])
+				continue
+			}
+			g.Duplicates = append(g.Duplicates, Member{FileMeta: files[k], Distance: memberDistance(matcher, g.Keep, files[k])})
+		}
+		groups = append(groups, g)
+	}
+
+	// 按保留文件路径排序返回（方便查看），并分配组 ID
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Keep.Path < groups[j].Keep.Path })
+	for i := range groups {
+		groups[i].ID = i + 1
+	}
+	return groups
+}
+
+// memberDistance 返回成员与保留文件的距离：默认为汉明距离，距离度量匹配器下为度量距离
+func memberDistance(matcher Matcher, keep, m FileMeta) int {
+	if mm, ok := matcher.(metricMatcher); ok {
+		return int(math.Round(mm.metric.Distance(keep, m)))
+	}
+	return fingerprint.HammingDistance(keep.FP, m.FP)
+}
+
+// Distance 返回 a 与 b 在 opts 的匹配器下的距离（与 Member.Distance 的口径相同）
+func (o Options) Distance(a, b FileMeta) int {
+	matcher := o.Matcher
+	if matcher == nil {
+		matcher = HammingMatcher(o.Threshold)
+	}
+	return memberDistance(matcher, a, b)
+}
+
+// Signature 返回组的签名：成员（路径、大小、指纹）与 salt（如保留策略）的 SHA256 摘要，与成员顺序
+// 及当前保留文件无关。成员增减或内容变化都会得到新签名，供跨运行记录保留决定（见 internal/cache）。
+func (g Group) Signature(salt string) string {
+	members := make([]FileMeta, 0, 1+len(g.Protected)+len(g.Duplicates))
+	members = append(members, g.Keep)
+	members = append(members, g.Protected...)
+	for _, d := range g.Duplicates {
+		members = append(members, d.FileMeta)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].Path < members[j].Path })
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n", salt)
+	for _, m := range members {
+		fmt.Fprintf(h, "%s\x00%d\x00%016x\n", m.Path, m.Size, m.FP)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Promote 把重复成员 path 改为保留文件（原保留文件成为重复成员），并按 opts 的匹配器重新计算距离。
+// path 不是重复成员，或原保留文件由保护规则选出时不做改动，返回 false。
+func Promote(g *Group, path string, opts Options) bool {
+	if g.Keep.Path == path || (opts.Protect != nil && opts.Protect(g.Keep)) {
+		return false
+	}
+	idx := -1
+	for i, d := range g.Duplicates {
+		if d.Path == path {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return false
+	}
+	// 原保留文件是策略下的最优者，排在重复成员最前，其余成员保持原顺序
+	old := g.Keep
+	g.Keep = g.Duplicates[idx].FileMeta
+	copy(g.Duplicates[1:idx+1], g.Duplicates[:idx])
+	g.Duplicates[0] = Member{FileMeta: old}
+	for i := range g.Duplicates {
+		g.Duplicates[i].Distance = opts.Distance(g.Keep, g.Duplicates[i].FileMeta)
+	}
+	return true
+}
+
+// Components 只依据指纹（汉明距离 <= threshold）划分连通分量，返回每个分量的下标列表。
+// 分量内下标升序，分量按首个下标排序。用于内存受限时先在紧凑索引上聚类，再按需加载完整元数据。
+func Components(fps []uint64, threshold int) [][]int {
+	return ShardedComponents(fps, threshold, 0)
+}
+
+// ShardedComponents 同 Components，shardBits > 0 时使用前缀分片聚类（见 Options.ShardBits）。
+func ShardedComponents(fps []uint64, threshold, shardBits int) [][]int {
+	match := func(i, j int) bool {
+		return fingerprint.HammingDistance(fps[i], fps[j]) <= threshold
+	}
+	var members map[int][]int
+	if shardBits > 0 {
+		members = connectSharded(fps, shardBits, threshold, match)
+	} else {
+		members = connectFPs(fps, threshold)
+	}
+	return sortedComponents(members)
+}
+
+// sortedComponents 把 根 -> 成员 的分组整理为分量列表：分量内下标升序，分量按首个下标排序
+func sortedComponents(members map[int][]int) [][]int {
+	comps := make([][]int, 0, len(members))
+	for _, c := range members {
+		sort.Ints(c)
+		comps = append(comps, c)
+	}
+	sort.Slice(comps, func(i, j int) bool { return comps[i][0] < comps[j][0] })
+	return comps
+}
+
+// IndexedComponents 同 Components，按 opts 选择聚类方式：ShardBits > 0 时前缀分片，
+// Exhaustive 时全量比较，文件数达到 TreeMinFiles 时用 BK 树，否则使用分段索引。
+// 只使用 opts 的 Threshold、ShardBits、Exhaustive 与 TreeMinFiles。
+func IndexedComponents(fps []uint64, opts Options) [][]int {
+	switch {
+	case opts.ShardBits > 0 || opts.Exhaustive:
+		return ShardedComponents(fps, opts.Threshold, opts.ShardBits)
+	case opts.TreeMinFiles > 0 && len(fps) >= opts.TreeMinFiles:
+		return sortedComponents(connectTree(fps, opts.Threshold))
+	}
+	return sortedComponents(connectBanded(fps, opts.Threshold))
+}
+
+// OrderComponents 按分量内最小指纹对分量排序。指纹相同的文件必在同一分量，
+// 因此顺序只取决于指纹集合，与文件被追加的先后（即解码并发度）无关。
+func OrderComponents(comps [][]int, fps []uint64) {
+	minFP := make([]uint64, len(comps))
+	for c, comp := range comps {
+		minFP[c] = math.MaxUint64
+		for _, i := range comp {
+			if fps[i] < minFP[c] {
+				minFP[c] = fps[i]
+			}
+		}
+	}
+	idx := make([]int, len(comps))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(a, b int) bool { return minFP[idx[a]] < minFP[idx[b]] })
+	sorted := make([][]int, len(comps))
+	for i, c := range idx {
+		sorted[i] = comps[c]
+	}
+	copy(comps, sorted)
+}
+
+func fingerprints(files []FileMeta) []uint64 {
+	fps := make([]uint64, len(files))
+	for i := range files {
+		fps[i] = files[i].FP
+	}
+	return fps
+}
+
+// connect 对所有下标对调用 match，用并查集合并匹配的对，返回 根 -> 成员下标 的分组
+func connect(n int, match func(i, j int) bool) map[int][]int {
 	uf := newUnionFind(n)
 
-	// 并行比较所有对（简单的 N^2；对于数千文件可能慢，可进一步分桶优化）
+	// 并行比较所有对（简单的 N^2；对于数千文件可能慢，默认匹配器改用分段索引，见 bands.go）
+	parallelFor(n, func(i int) {
+		for j := i + 1; j < n; j++ {
+			if match(i, j) {
+				uf.union(i, j)
+			}
+		}
+	})
+	return uf.groups()
+}
+
+// connectSharded 按指纹高 shardBits 位分片后聚类：分片内全量比较，
+// 分片间只比较前缀距离不超过 threshold 的分片对（前缀距离是总距离的下界，因此不会漏配）。
+func connectSharded(fps []uint64, shardBits, threshold int, match func(i, j int) bool) map[int][]int {
+	if shardBits > 32 {
+		shardBits = 32
+	}
+	shift := uint(64 - shardBits)
+	shards := make(map[uint64][]int)
+	for i, fp := range fps {
+		k := fp >> shift
+		shards[k] = append(shards[k], i)
+	}
+	keys := make([]uint64, 0, len(shards))
+	for k := range shards {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	uf := newUnionFind(len(fps))
+	parallelFor(len(keys), func(a int) {
+		sa := shards[keys[a]]
+		for x := 0; x < len(sa); x++ {
+			for y := x + 1; y < len(sa); y++ {
+				if match(sa[x], sa[y]) {
+					uf.union(sa[x], sa[y])
+				}
+			}
+		}
+		for b := a + 1; b < len(keys); b++ {
+			if bits.OnesCount64(keys[a]^keys[b]) > threshold {
+				continue
+			}
+			for _, i := range sa {
+				for _, j := range shards[keys[b]] {
+					if match(i, j) {
+						uf.union(i, j)
+					}
+				}
+			}
+		}
+	})
+	return uf.groups()
+}
+
+// parallelFor 用 GOMAXPROCS 个 worker 对 [0, n) 逐个调用 fn（动态领取下标以平衡负载）
+func parallelFor(n int, fn func(i int)) {
+	workers := runtime.GOMAXPROCS(0)
+	if workers > n {
+		workers = n
+	}
+	var next atomic.Int64
 	var wg sync.WaitGroup
-	for i := 0; i < n; i++ {
-		i := i
+	for w := 0; w < workers; w++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			for j := i + 1; j < n; j++ {
-				dist := fingerprint.HammingDistance(files[i].FP, files[j].FP)
-				if dist <= threshold {
-					uf.union(i, j)
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= n {
+					return
 				}
+				fn(i)
 			}
 		}()
 	}
 	wg.Wait()
-
-	// group by root
-	groups := make(map[int][]int)
-	for i := 0; i < n; i++ {
-		r := uf.find(i)
-		groups[r] = append(groups[r], i)
-	}
-
-	// 选出每组中 size 最大的文件
-	var keeps []FileMeta
-	for _, idxs := range groups {
-		// 找最大 size，否则按字典序最小
-		sort.Slice(idxs, func(i, j int) bool {
-			a, b := files[idxs[i]], files[idxs[j]]
-			if a.Size != b.Size {
-				return a.Size > b.Size // 降序，方便取第0个
-			}
-			return a.Path < b.Path
-		})
-		keeps = append(keeps, files[idxs[0]])
-	}
-
-	// 可选：按路径排序返回（方便查看）
-	sort.Slice(keeps, func(i, j int) bool { return keeps[i].Path < keeps[j].Path })
-	return keeps
 }
 
 // ----------------- 并查集实现 -----------------
 type unionFind struct {
+	mu     sync.Mutex // 保护并发 union
 	parent []int
 	rank   []int
 }
@@ -96,6 +445,8 @@ func (u *unionFind) find(x int) int {
 }
 
 func (u *unionFind) union(a, b int) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
 	ar := u.find(a)
 	br := u.find(b)
 	if ar == br {
@@ -110,3 +461,13 @@ func (u *unionFind) union(a, b int) {
 		u.rank[ar]++
 	}
 }
+
+// groups 返回 根 -> 成员下标 的分组（须在所有 union 完成后调用）
+func (u *unionFind) groups() map[int][]int {
+	members := make(map[int][]int)
+	for i := range u.parent {
+		r := u.find(i)
+		members[r] = append(members[r], i)
+	}
+	return members
+}
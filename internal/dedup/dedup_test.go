@@ -5,7 +5,14 @@
 package dedup
 
 import (
+	"deduplicateMusic/internal/fingerprint"
+	"deduplicateMusic/internal/tags"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"runtime"
 	"testing"
+	"time"
 )
 
 func TestSelectKeepBasic(t *testing.T) {
@@ -28,3 +35,470 @@ func TestSelectKeepBasic(t *testing.T) {
 		t.Fatalf("保留文件不正确: %#v", keeps)
 	}
 }
+
+func TestGroupFilesDuplicates(t *testing.T) {
+	files := []FileMeta{
+		{Path: "a.mp3", Size: 1000, FP: 0x0f0f0f0f0f0f0f0f},
+		{Path: "b.mp3", Size: 2000, FP: 0x0f0f0f0f0f0f0f0e}, // 与 a 距离 1
+		{Path: "c.mp3", Size: 1500, FP: 0xf0f0f0f0f0f0f0f0},
+	}
+	groups := GroupFiles(files, 4)
+	if len(groups) != 2 {
+		t.Fatalf("期望 2 个组，实际 %d", len(groups))
+	}
+	g := groups[0] // 按保留路径排序：b.mp3 在前
+	if g.ID != 1 || g.Keep.Path != "b.mp3" || len(g.Duplicates) != 1 {
+		t.Fatalf("分组不正确: %#v", g)
+	}
+	if d := g.Duplicates[0]; d.Path != "a.mp3" || d.Distance != 1 {
+		t.Fatalf("重复成员不正确: %#v", d)
+	}
+}
+
+func TestParseOrderPolicy(t *testing.T) {
+	files := []FileMeta{
+		{Path: "b.mp3", Size: 2000, FP: 1},
+		{Path: "a.flac", Size: 1000, FP: 1},
+	}
+	// 按名称升序：a.flac 应被保留，即使体积更小
+	p, err := LookupKeepPolicy("name asc")
+	if err != nil {
+		t.Fatalf("解析表达式失败: %v", err)
+	}
+	groups := GroupWith(files, Options{Threshold: 0, Policy: p})
+	if len(groups) != 1 || groups[0].Keep.Path != "a.flac" {
+		t.Fatalf("保留文件不正确: %#v", groups)
+	}
+	// 按格式质量降序：无损的 a.flac 优先于体积更大的 b.mp3
+	p, _ = LookupKeepPolicy("quality desc, size desc")
+	if groups := GroupWith(files, Options{Threshold: 0, Policy: p}); len(groups) != 1 || groups[0].Keep.Path != "a.flac" {
+		t.Fatalf("quality 排序保留文件不正确: %#v", groups)
+	}
+	if _, err := ParseOrder("size sideways"); err == nil {
+		t.Fatalf("非法方向应报错")
+	}
+	if _, err := ParseOrder("nosuchkey desc"); err == nil {
+		t.Fatalf("未知属性应报错")
+	}
+}
+
+func TestComponents(t *testing.T) {
+	fps := []uint64{0xff, 0x0f00, 0xfe, 0x0f01}
+	comps := Components(fps, 1)
+	if len(comps) != 2 || len(comps[0]) != 2 || comps[0][0] != 0 || comps[0][1] != 2 || comps[1][0] != 1 {
+		t.Fatalf("连通分量不正确: %v", comps)
+	}
+}
+
+func TestShardedMatchesExhaustive(t *testing.T) {
+	// 构造若干簇：每个簇中心附近翻转少量位
+	rng := rand.New(rand.NewSource(1))
+	var fps []uint64
+	for c := 0; c < 40; c++ {
+		center := rng.Uint64()
+		for k := 0; k < 5; k++ {
+			fp := center
+			for f := 0; f < rng.Intn(4); f++ {
+				fp ^= 1 << uint(rng.Intn(64))
+			}
+			fps = append(fps, fp)
+		}
+	}
+	want := Components(fps, 4)
+	for _, bits := range []int{4, 12, 20} {
+		got := ShardedComponents(fps, 4, bits)
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("shardBits=%d 的结果与全量比较不一致", bits)
+		}
+	}
+}
+
+func TestBandedMatchesExhaustive(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	var fps []uint64
+	for c := 0; c < 60; c++ {
+		center := rng.Uint64()
+		for k := 0; k < 4; k++ {
+			fp := center
+			for f := 0; f < rng.Intn(12); f++ {
+				fp ^= 1 << uint(rng.Intn(64))
+			}
+			fps = append(fps, fp)
+		}
+	}
+	for _, th := range []int{0, 1, 4, 8, 10, 15, 20} {
+		want := Components(fps, th)
+		if got := IndexedComponents(fps, Options{Threshold: th}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("threshold=%d 分段索引结果与全量比较不一致", th)
+		}
+		if got := IndexedComponents(fps, Options{Threshold: th, TreeMinFiles: 1}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("threshold=%d BK 树结果与全量比较不一致", th)
+		}
+	}
+	// 每段位数相差不超过 1 且覆盖全部 64 位
+	for th := 0; th <= maxBandedThreshold; th++ {
+		total := uint(0)
+		for _, b := range bandLayout(th) {
+			if b[0] != total || b[1] < 64/uint(th+1) || b[1] > 64/uint(th+1)+1 {
+				t.Fatalf("threshold=%d 分段不正确: %v", th, bandLayout(th))
+			}
+			total += b[1]
+		}
+		if total != 64 {
+			t.Fatalf("threshold=%d 分段未覆盖 64 位", th)
+		}
+	}
+}
+
+func TestHammingWithinKernel(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	q := rng.Uint64()
+	// 非 8 的倍数，覆盖尾部处理；超过 1024 个时覆盖 SIMD 内核的分批
+	for _, n := range []int{37, 2051} {
+		fps := make([]uint64, n)
+		for i := range fps {
+			fps[i] = q ^ (rng.Uint64() & rng.Uint64() & rng.Uint64())
+		}
+		for _, threshold := range []int{0, 8, 64} {
+			var want []int32
+			for i, fp := range fps {
+				if fingerprint.HammingDistance(q, fp) <= threshold {
+					want = append(want, int32(i))
+				}
+			}
+			got := hammingWithin(q, fps, threshold, nil)
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("内核 %s（n=%d，阈值 %d）结果不正确: %v，期望 %v", KernelName(), n, threshold, got, want)
+			}
+		}
+	}
+}
+
+func TestSpeedMatcher(t *testing.T) {
+	files := []FileMeta{
+		{Path: "vinyl.flac", Size: 3, FP: 0xffff, AltFPs: []uint64{0x00ff}},
+		{Path: "cd.flac", Size: 2, FP: 0x00ff},
+	}
+	if g := GroupWith(files, Options{Threshold: 0}); len(g) != 2 {
+		t.Fatalf("默认匹配器不应合并: %#v", g)
+	}
+	m, err := LookupMatcher("speed", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := GroupWith(files, Options{Threshold: 0, Matcher: m})
+	if len(g) != 1 || g[0].Keep.Path != "vinyl.flac" {
+		t.Fatalf("变速指纹匹配时应合并: %#v", g)
+	}
+}
+
+func TestTagAgreement(t *testing.T) {
+	files := []FileMeta{
+		{Path: "bwv988-aria.flac", Size: 2, FP: 1, Tags: tags.Tags{Composer: "Bach", Movement: "Aria"}},
+		{Path: "bwv988-var1.flac", Size: 1, FP: 1, Tags: tags.Tags{Composer: "bach", Movement: "Variatio 1"}},
+		{Path: "aria-notags.flac", Size: 3, FP: 1},
+		{Path: "BWV988 aria.flac", Size: 4, FP: 1, Tags: tags.Tags{Composer: "Bach ", Movement: "aria"}},
+	}
+	groups := GroupWith(files, Options{Threshold: 0, Matcher: TagAgreement(HammingMatcher(0))})
+	// 无标签的文件不能把不同乐章桥接到一起；同一乐章（大小写/空白不同）应合并
+	if len(groups) != 3 {
+		t.Fatalf("分组数 = %d，期望 3: %#v", len(groups), groups)
+	}
+	for _, g := range groups {
+		if g.Keep.Path == "BWV988 aria.flac" && (len(g.Duplicates) != 1 || g.Duplicates[0].Path != "bwv988-aria.flac") {
+			t.Fatalf("同一乐章应合并: %#v", g)
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	a := FileMeta{Path: "a", FP: 0x0123456789abcdef}
+	for _, name := range MetricNames() {
+		m, err := LookupMetric(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := m.Distance(a, a); d != 0 {
+			t.Fatalf("%s: 自身距离应为 0，实际 %v", name, d)
+		}
+		if d := m.Distance(a, FileMeta{FP: ^a.FP}); d < 32 || d > 64 {
+			t.Fatalf("%s: 取反指纹距离应在 32..64，实际 %v", name, d)
+		}
+	}
+	if _, err := LookupMetric("nope"); err == nil {
+		t.Fatal("未知度量应报错")
+	}
+
+	// 首位（淡入）差异在加权汉明下只算半位
+	w, _ := LookupMetric("weighted-hamming")
+	if d := w.Distance(a, FileMeta{FP: a.FP ^ 1<<63}); d != 0.5 {
+		t.Fatalf("weighted-hamming 首位差异 = %v", d)
+	}
+
+	// 余弦对整体音量缩放不敏感
+	c, _ := LookupMetric("cosine")
+	x := FileMeta{Blocks: []float32{1, 5, 2, 8}}
+	y := FileMeta{Blocks: []float32{2, 10, 4, 16}}
+	if d := c.Distance(x, y); d > 1e-6 {
+		t.Fatalf("cosine 缩放后距离 = %v", d)
+	}
+
+	// 分组时 Distance 按度量计算
+	j, _ := LookupMetric("jaccard")
+	files := []FileMeta{{Path: "/x", Size: 2, FP: a.FP}, {Path: "/y", Size: 1, FP: a.FP ^ 1}}
+	groups := GroupWith(files, Options{Threshold: 10, Matcher: MetricMatcher(j, 10)})
+	if len(groups) != 1 || groups[0].Duplicates[0].Distance != 8 {
+		t.Fatalf("jaccard 分组结果不正确: %+v", groups)
+	}
+}
+
+func TestDeterministicAcrossConcurrency(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	var files []FileMeta
+	for c := 0; c < 40; c++ {
+		base := rng.Uint64()
+		for k := 0; k < 1+rng.Intn(5); k++ {
+			fp := base ^ 1<<uint(rng.Intn(64)) ^ 1<<uint(rng.Intn(64))
+			// 体积只取少数几个值，制造大量策略打平
+			files = append(files, FileMeta{Path: fmt.Sprintf("/m/%02d_%d.mp3", c, k), Size: int64(rng.Intn(3)), FP: fp})
+		}
+	}
+	sizeOnly := KeepPolicyFunc(func(a, b FileMeta) bool { return a.Size > b.Size })
+	optsList := []Options{
+		{Threshold: 4},
+		{Threshold: 4, Policy: sizeOnly},
+		{Threshold: 4, Policy: sizeOnly, ShardBits: 12},
+		{Threshold: 4, Policy: sizeOnly, Matcher: MatcherFunc(func(a, b FileMeta) bool {
+			return fingerprint.HammingDistance(a.FP, b.FP) <= 4
+		})},
+	}
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+	for oi, opts := range optsList {
+		runtime.GOMAXPROCS(1)
+		want := GroupWith(files, opts)
+		for _, procs := range []int{2, 8, 64} {
+			runtime.GOMAXPROCS(procs)
+			shuffled := append([]FileMeta(nil), files...)
+			rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+			if got := GroupWith(shuffled, opts); !reflect.DeepEqual(got, want) {
+				t.Fatalf("选项 %d 在 GOMAXPROCS=%d 时结果与单线程不一致", oi, procs)
+			}
+		}
+	}
+}
+
+func TestOrderComponents(t *testing.T) {
+	fps := []uint64{9, 3, 7, 1}
+	comps := [][]int{{0}, {1, 2}, {3}}
+	OrderComponents(comps, fps)
+	if want := [][]int{{3}, {1, 2}, {0}}; !reflect.DeepEqual(comps, want) {
+		t.Fatalf("OrderComponents = %v，期望 %v", comps, want)
+	}
+}
+
+func TestSegmentsMetric(t *testing.T) {
+	m, err := LookupMetric("segments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 前奏相同（整体指纹相同）但中段、结尾不同
+	a := FileMeta{FP: 1, Segments: []uint64{1, 0, 0}}
+	b := FileMeta{FP: 1, Segments: []uint64{1, ^uint64(0), ^uint64(0)}}
+	if d := m.Distance(a, b); d != 128.0/3 {
+		t.Fatalf("segments 距离 = %v", d)
+	}
+	// 缺少多窗口指纹时退回整体指纹
+	if d := m.Distance(a, FileMeta{FP: 3}); d != 1 {
+		t.Fatalf("退回汉明距离 = %v", d)
+	}
+}
+
+func TestBuiltinKeepPolicies(t *testing.T) {
+	old := time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+	files := []FileMeta{
+		{Path: "/lib/a.mp3", Size: 9000, FP: 1, Duration: 200, ModTime: old.Add(time.Hour)}, // 360 kbps
+		{Path: "/lib/b.flac", Size: 8000, FP: 1, Duration: 100, ModTime: old.Add(2 * time.Hour)},
+		{Path: "/inbox/c.m4a", Size: 7000, FP: 1, Duration: 210, ModTime: old},
+	}
+	defer SetPathPriority(nil)
+	SetPathPriority([]string{"/inbox"})
+	for spec, want := range map[string]string{
+		"largest":        "/lib/a.mp3",
+		"bitrate":        "/lib/b.flac",
+		"lossless-first": "/lib/b.flac",
+		"duration":       "/inbox/c.m4a",
+		"oldest":         "/inbox/c.m4a",
+		"newest":         "/lib/b.flac",
+		"path-priority":  "/inbox/c.m4a",
+	} {
+		p, err := LookupKeepPolicy(spec)
+		if err != nil {
+			t.Fatalf("%s: %v", spec, err)
+		}
+		if g := GroupWith(files, Options{Policy: p}); len(g) != 1 || g[0].Keep.Path != want {
+			t.Errorf("%s 保留了 %s，期望 %s", spec, g[0].Keep.Path, want)
+		}
+	}
+	if !NeedsDuration("bitrate") || !NeedsDuration("duration desc") || NeedsDuration("largest") || NeedsDuration("oldest") {
+		t.Error("NeedsDuration 判断不正确")
+	}
+	if !PolicyUses("path-priority", "priority") || PolicyUses("size desc", "priority") {
+		t.Error("PolicyUses 判断不正确")
+	}
+}
+
+func TestSignatureAndPromote(t *testing.T) {
+	files := []FileMeta{
+		{Path: "/a.mp3", Size: 100, FP: 0b1111},
+		{Path: "/b.mp3", Size: 100, FP: 0b0111},
+		{Path: "/c.mp3", Size: 90, FP: 0b0011},
+	}
+	opts := Options{Threshold: 4}
+	g := GroupWith(files, opts)[0]
+	rev := GroupWith([]FileMeta{files[2], files[1], files[0]}, opts)[0]
+	if g.Signature("largest") != rev.Signature("largest") {
+		t.Fatal("签名应与输入顺序无关")
+	}
+	if g.Signature("largest") == g.Signature("newest") {
+		t.Fatal("签名应包含 salt")
+	}
+	sig := g.Signature("largest")
+	if g.Keep.Path != "/a.mp3" {
+		t.Fatalf("打平时应按路径保留 /a.mp3，实际 %s", g.Keep.Path)
+	}
+	if Promote(&g, "/missing.mp3", opts) {
+		t.Fatal("非成员不应被提升")
+	}
+	if !Promote(&g, "/b.mp3", opts) || g.Keep.Path != "/b.mp3" {
+		t.Fatalf("应提升 /b.mp3: %+v", g)
+	}
+	if len(g.Duplicates) != 2 || g.Duplicates[0].Path != "/a.mp3" || g.Duplicates[0].Distance != 1 || g.Duplicates[1].Distance != 1 {
+		t.Fatalf("重复成员或距离不对: %+v", g.Duplicates)
+	}
+	if g.Signature("largest") != sig {
+		t.Fatal("改换保留文件不应改变签名")
+	}
+	opts.Protect = func(m FileMeta) bool { return m.Path == "/b.mp3" }
+	if Promote(&g, "/a.mp3", opts) {
+		t.Fatal("受保护的保留文件不应被替换")
+	}
+}
+
+func TestDurationApart(t *testing.T) {
+	files := []FileMeta{
+		{Path: "a.flac", Size: 30, FP: 0xABCD, Duration: 200},
+		{Path: "b.mp3", Size: 10, FP: 0xABCD, Duration: 201.5},
+		{Path: "c.mp3", Size: 20, FP: 0xABCD, Duration: 260},
+		{Path: "d.mp3", Size: 5, FP: 0xABCD}, // 时长未知，不加限制
+	}
+	groups := GroupWith(files, Options{Threshold: 0, Apart: DurationApart(2)})
+	if len(groups) != 2 {
+		t.Fatalf("期望 2 组，得到 %d: %+v", len(groups), groups)
+	}
+	for _, g := range groups {
+		for _, d := range g.Duplicates {
+			if apart := DurationApart(2); apart(g.Keep, d.FileMeta) {
+				t.Fatalf("组内出现时长不符的文件: %s 与 %s", g.Keep.Path, d.Path)
+			}
+		}
+	}
+	if JoinApart(nil, nil) != nil {
+		t.Fatal("两个 nil 约束合并后应为 nil")
+	}
+	never := func(a, b FileMeta) bool { return false }
+	if !JoinApart(never, DurationApart(2))(files[0], files[2]) {
+		t.Fatal("JoinApart 应在任一约束成立时返回 true")
+	}
+}
+
+func TestTaggedPolicy(t *testing.T) {
+	p, err := LookupKeepPolicy("tagged")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bare := FileMeta{Path: "a.mp3", Size: 100}
+	tagged := FileMeta{Path: "b.mp3", Size: 50, Tags: tags.Tags{Artist: "A", Title: "T", Album: "X"}}
+	if !p.Better(tagged, bare) || p.Better(bare, tagged) {
+		t.Fatal("标签更完整的文件应优先保留")
+	}
+}
+
+func TestOrderGroups(t *testing.T) {
+	old, recent := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	files := []FileMeta{
+		{Path: "a.mp3", Size: 10, FP: 0x1, ModTime: old},
+		{Path: "b.mp3", Size: 5, FP: 0x1, ModTime: old},
+		{Path: "c.mp3", Size: 3, FP: 0xFFFF0000, ModTime: old},
+		{Path: "d.mp3", Size: 1, FP: 0xFFFF0000, ModTime: recent}, // 新下载的重复文件
+	}
+	groups := GroupFiles(files, 0)
+	OrderGroups(groups, "path")
+	if groups[0].Keep.Path != "a.mp3" || groups[0].ID != 1 {
+		t.Fatalf("path 顺序应保持不变: %+v", groups)
+	}
+	OrderGroups(groups, "newest")
+	if groups[0].Keep.Path != "c.mp3" || groups[0].ID != 1 || groups[1].ID != 2 {
+		t.Fatalf("newest 应先处理含最新文件的组并重新编号: %+v", groups)
+	}
+	OrderGroups(groups, "largest")
+	if groups[0].Keep.Path != "a.mp3" {
+		t.Fatalf("largest 应先处理含最大文件的组: %+v", groups)
+	}
+	if _, err := ParseProcessOrder("Newest"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseProcessOrder("random"); err == nil {
+		t.Fatal("未知顺序应报错")
+	}
+}
+
+func TestGroupByTags(t *testing.T) {
+	song := func(path, artist, title string, fp uint64) FileMeta {
+		return FileMeta{Path: path, Size: 10, FP: fp, Tags: tags.Tags{Artist: artist, Title: title}}
+	}
+	files := []FileMeta{
+		song("a.mp3", "Faye Wong", "Red Bean", 0x1),
+		song("b.mp3", "ＦＡＹＥ  wong", "red bean", 0x3),  // 全角、大小写与空白差异
+		song("c.mp3", "Faye Wong", "Other Song", 0x1), // 指纹相撞的另一首歌
+		song("d.mp3", "Faye Wong", "Red Bean", 0xFFFFFFFF00000000),
+		song("e.mp3", "", "", 0x1), // 无标签
+		song("f.mp3", "", "", 0x1),
+	}
+	count := func(opts Options) (groups int) {
+		for _, g := range GroupWith(files, opts) {
+			if len(g.Duplicates) > 0 {
+				groups++
+			}
+		}
+		return groups
+	}
+	// 只按指纹：a b c e f 相连为一组
+	if n := count(Options{Threshold: 2}); n != 1 {
+		t.Fatalf("fingerprint: %d 组", n)
+	}
+	// both：a b 一组、e f 一组，c 与 d 单独
+	both := Options{Threshold: 2, Partition: TagKey}
+	if n := count(both); n != 2 {
+		t.Fatalf("both: %d 组", n)
+	}
+	// tags：a b d 一组（d 指纹不同但标签相同）、e f 一组
+	groups := GroupWith(files, Options{Threshold: 2, Partition: TagKey, Matcher: TagMatcher(HammingMatcher(2))})
+	sizes := map[string]int{}
+	for _, g := range groups {
+		sizes[g.Keep.Path] = 1 + len(g.Duplicates)
+	}
+	if sizes["a.mp3"] != 3 || sizes["e.mp3"] != 2 || sizes["c.mp3"] != 1 {
+		t.Fatalf("tags: %v", sizes)
+	}
+	for i, g := range groups {
+		if g.ID != i+1 {
+			t.Fatalf("组 ID 应按顺序重新编号: %+v", groups)
+		}
+	}
+	if _, err := ParseGroupBy("Both"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseGroupBy("album"); err == nil {
+		t.Fatal("未知分组方式应报错")
+	}
+}
@@ -0,0 +1,28 @@
+// file: internal/dedup/duration.go
+// package: dedup
+//
+// 时长约束：两首完全不同的曲目偶尔也会在汉明距离阈值内相撞（指纹只取开头若干秒时尤其如此），
+// 时长相差明显时几乎不可能是同一录音。DurationApart 作为 Options.Apart 使用，
+// 经由中间文件间接相连的两个文件同样会被分开。
+package dedup
+
+import "math"
+
+// DurationApart 返回时长约束：两个文件的时长都已知（> 0）且相差超过 tolerance 秒时不可合并。
+// 任一方时长未知时不加限制。
+func DurationApart(tolerance float64) func(a, b FileMeta) bool {
+	return func(a, b FileMeta) bool {
+		return a.Duration > 0 && b.Duration > 0 && math.Abs(a.Duration-b.Duration) > tolerance
+	}
+}
+
+// JoinApart 合并两个不可合并约束，任一返回 true 即不可合并；nil 视为没有约束
+func JoinApart(x, y func(a, b FileMeta) bool) func(a, b FileMeta) bool {
+	switch {
+	case x == nil:
+		return y
+	case y == nil:
+		return x
+	}
+	return func(a, b FileMeta) bool { return x(a, b) || y(a, b) }
+}
@@ -0,0 +1,33 @@
+// file: internal/dedup/kernel.go
+// package: dedup
+//
+// 批量汉明距离比较内核：给定一个查询指纹与一段连续的指纹数组，返回距离不超过阈值的下标。
+// 默认实现为朴素循环；使用 `-tags dedup_unrolled` 构建时换成 8 路展开版本
+// （减少边界检查与分支，便于编译器生成连续的 POPCNT 指令），适合百万级全量比较；
+// amd64 上使用 `-tags dedup_simd` 构建时换成 AVX2 汇编内核（CPU 不支持时自动退回朴素循环），两者同时指定时以后者为准。
+// GPU（OpenCL/CUDA）后端需要 cgo 与外部驱动，当前未提供。
+package dedup
+
+import "deduplicateMusic/internal/fingerprint"
+
+// KernelName 返回当前编译进来的比较内核名称（用于启动时打印）
+func KernelName() string { return kernelName }
+
+// hammingMatcher 为默认的纯指纹匹配器；GroupWith 识别该类型后走批量内核快速路径
+type hammingMatcher struct{ threshold int }
+
+func (h hammingMatcher) Match(a, b FileMeta) bool {
+	return fingerprint.HammingDistance(a.FP, b.FP) <= h.threshold
+}
+
+// connectFPs 使用批量内核对纯指纹做全量聚类
+func connectFPs(fps []uint64, threshold int) map[int][]int {
+	n := len(fps)
+	uf := newUnionFind(n)
+	parallelFor(n, func(i int) {
+		for _, j := range hammingWithin(fps[i], fps[i+1:], threshold, nil) {
+			uf.union(i, i+1+int(j))
+		}
+	})
+	return uf.groups()
+}
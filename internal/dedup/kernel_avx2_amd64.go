@@ -0,0 +1,67 @@
+//go:build dedup_simd && amd64
+
+// file: internal/dedup/kernel_avx2_amd64.go
+// package: dedup
+//
+// AVX2 比较内核（-tags dedup_simd，仅 amd64）：每次用两个 256 位寄存器处理 8 个指纹，
+// 按半字节查表（VPSHUFB）计算 popcount 后与阈值比较，得到 8 位命中掩码（见 kernel_avx2_amd64.s）。
+// 启动时用 CPUID / XGETBV 检查 CPU 与操作系统是否支持 AVX2，不支持时退回朴素循环。
+package dedup
+
+import "math/bits"
+
+var useAVX2 = hasAVX2()
+
+var kernelName = map[bool]string{true: "avx2", false: "generic"}[useAVX2]
+
+// hasAVX2 返回 CPU 是否支持 AVX2 且操作系统保存 YMM 寄存器状态
+func hasAVX2() bool {
+	_, _, ecx1, _ := cpuid(1, 0)
+	const osxsave, avx = 1 << 27, 1 << 28
+	if ecx1&osxsave == 0 || ecx1&avx == 0 {
+		return false
+	}
+	if xcr0, _ := xgetbv(); xcr0&6 != 6 { // XMM 与 YMM 状态
+		return false
+	}
+	maxID, _, _, _ := cpuid(0, 0)
+	if maxID < 7 {
+		return false
+	}
+	_, ebx7, _, _ := cpuid(7, 0)
+	return ebx7&(1<<5) != 0
+}
+
+// 以下函数由汇编实现
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+func xgetbv() (eax, edx uint32)
+
+// hammingMaskAVX2 按每 8 个指纹一组处理 fps 的前 len(masks)*8 个，masks[k] 的第 j 位
+// 表示 fps[8k+j] 与 q 的汉明距离 <= threshold；len(fps) 不能小于 len(masks)*8
+//
+//go:noescape
+func hammingMaskAVX2(q uint64, fps []uint64, threshold int, masks []uint8)
+
+// hammingWithin 把 fps 中与 q 的汉明距离 <= threshold 的下标追加到 out 并返回
+func hammingWithin(q uint64, fps []uint64, threshold int, out []int32) []int32 {
+	i := 0
+	if useAVX2 {
+		var masks [128]uint8 // 每批 1024 个指纹，掩码放在栈上
+		for len(fps)-i >= 8 {
+			n := min((len(fps)-i)/8, len(masks))
+			hammingMaskAVX2(q, fps[i:i+n*8], threshold, masks[:n])
+			for k, m := range masks[:n] {
+				for ; m != 0; m &= m - 1 {
+					out = append(out, int32(i+k*8+bits.TrailingZeros8(m)))
+				}
+			}
+			i += n * 8
+		}
+	}
+	for ; i < len(fps); i++ {
+		if bits.OnesCount64(q^fps[i]) <= threshold {
+			out = append(out, int32(i))
+		}
+	}
+	return out
+}
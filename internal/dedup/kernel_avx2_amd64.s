@@ -0,0 +1,85 @@
+//go:build dedup_simd && amd64
+
+// file: internal/dedup/kernel_avx2_amd64.s
+// package: dedup
+//
+// AVX2 批量汉明距离内核与 CPU 特性检测，Go 声明见 kernel_avx2_amd64.go。
+
+#include "textflag.h"
+
+// 0..15 每个半字节的置位数，两个 128 位通道各一份（VPSHUFB 按通道查表）
+DATA nibbleCount<>+0(SB)/8, $0x0302020102010100
+DATA nibbleCount<>+8(SB)/8, $0x0403030203020201
+DATA nibbleCount<>+16(SB)/8, $0x0302020102010100
+DATA nibbleCount<>+24(SB)/8, $0x0403030203020201
+GLOBL nibbleCount<>(SB), RODATA|NOPTR, $32
+
+// func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+TEXT ·cpuid(SB), NOSPLIT, $0-24
+	MOVL eaxArg+0(FP), AX
+	MOVL ecxArg+4(FP), CX
+	CPUID
+	MOVL AX, eax+8(FP)
+	MOVL BX, ebx+12(FP)
+	MOVL CX, ecx+16(FP)
+	MOVL DX, edx+20(FP)
+	RET
+
+// func xgetbv() (eax, edx uint32)
+TEXT ·xgetbv(SB), NOSPLIT, $0-8
+	MOVL $0, CX
+	XGETBV
+	MOVL AX, eax+0(FP)
+	MOVL DX, edx+4(FP)
+	RET
+
+// popcnt64 把 in 中 4 个 64 位整数的置位数写到 out 的对应通道
+// 使用 Y2（查表）、Y3（0x0f 掩码）、Y4（全零），tmp 为临时寄存器
+#define POPCNT64(in, out, tmp) \
+	VPAND   Y3, in, tmp   \
+	VPSRLQ  $4, in, out   \
+	VPAND   Y3, out, out  \
+	VPSHUFB tmp, Y2, tmp  \
+	VPSHUFB out, Y2, out  \
+	VPADDB  tmp, out, out \
+	VPSADBW Y4, out, out
+
+// func hammingMaskAVX2(q uint64, fps []uint64, threshold int, masks []uint8)
+TEXT ·hammingMaskAVX2(SB), NOSPLIT, $0-64
+	MOVQ masks_len+48(FP), CX
+	TESTQ CX, CX
+	JZ   done
+	MOVQ fps_base+8(FP), SI
+	MOVQ masks_base+40(FP), DI
+
+	VPBROADCASTQ q+0(FP), Y0
+	VPBROADCASTQ threshold+32(FP), Y1
+	VMOVDQU nibbleCount<>(SB), Y2
+	MOVQ $0x0f0f0f0f0f0f0f0f, AX
+	MOVQ AX, X3
+	VPBROADCASTQ X3, Y3
+	VPXOR Y4, Y4, Y4
+
+loop:
+	VPXOR (SI), Y0, Y5
+	VPXOR 32(SI), Y0, Y6
+	POPCNT64(Y5, Y7, Y8)
+	POPCNT64(Y6, Y9, Y10)
+	// 距离 > 阈值的通道置为全 1，取符号位后取反得到命中掩码
+	VPCMPGTQ Y1, Y7, Y7
+	VPCMPGTQ Y1, Y9, Y9
+	VMOVMSKPD Y7, AX
+	VMOVMSKPD Y9, BX
+	SHLQ $4, BX
+	ORQ  BX, AX
+	XORQ $0xff, AX
+	MOVB AX, (DI)
+
+	ADDQ $64, SI
+	INCQ DI
+	DECQ CX
+	JNZ  loop
+
+done:
+	VZEROUPPER
+	RET
@@ -0,0 +1,21 @@
+//go:build !dedup_unrolled && !(dedup_simd && amd64)
+
+// file: internal/dedup/kernel_generic.go
+// package: dedup
+//
+// 默认比较内核：朴素循环。
+package dedup
+
+import "math/bits"
+
+const kernelName = "generic"
+
+// hammingWithin 把 fps 中与 q 的汉明距离 <= threshold 的下标追加到 out 并返回
+func hammingWithin(q uint64, fps []uint64, threshold int, out []int32) []int32 {
+	for i, fp := range fps {
+		if bits.OnesCount64(q^fp) <= threshold {
+			out = append(out, int32(i))
+		}
+	}
+	return out
+}
@@ -0,0 +1,42 @@
+//go:build dedup_unrolled && !(dedup_simd && amd64)
+
+// file: internal/dedup/kernel_unrolled.go
+// package: dedup
+//
+// 8 路展开的比较内核（-tags dedup_unrolled）：每次处理 8 个指纹，先计算全部距离再统一判断。
+package dedup
+
+import "math/bits"
+
+const kernelName = "unrolled8"
+
+// hammingWithin 把 fps 中与 q 的汉明距离 <= threshold 的下标追加到 out 并返回
+func hammingWithin(q uint64, fps []uint64, threshold int, out []int32) []int32 {
+	i := 0
+	for ; i+8 <= len(fps); i += 8 {
+		blk := fps[i : i+8 : i+8]
+		d0 := bits.OnesCount64(q ^ blk[0])
+		d1 := bits.OnesCount64(q ^ blk[1])
+		d2 := bits.OnesCount64(q ^ blk[2])
+		d3 := bits.OnesCount64(q ^ blk[3])
+		d4 := bits.OnesCount64(q ^ blk[4])
+		d5 := bits.OnesCount64(q ^ blk[5])
+		d6 := bits.OnesCount64(q ^ blk[6])
+		d7 := bits.OnesCount64(q ^ blk[7])
+		// 大多数块没有命中，先用最小值快速跳过
+		if min(d0, d1, d2, d3, d4, d5, d6, d7) > threshold {
+			continue
+		}
+		for k, d := range [8]int{d0, d1, d2, d3, d4, d5, d6, d7} {
+			if d <= threshold {
+				out = append(out, int32(i+k))
+			}
+		}
+	}
+	for ; i < len(fps); i++ {
+		if bits.OnesCount64(q^fps[i]) <= threshold {
+			out = append(out, int32(i))
+		}
+	}
+	return out
+}
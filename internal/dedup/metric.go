@@ -0,0 +1,202 @@
+// file: internal/dedup/metric.go
+// package: dedup
+//
+// 距离度量注册表：把"两份指纹有多远"与匹配器/索引解耦，便于试验新的度量而不必改动 dedup.go。
+// 所有度量都换算到 0..64 的"汉明等价位数"，因此可以共用 -threshold：
+//   - hamming：指纹汉明距离（走批量比较内核）
+//   - weighted-hamming：首尾各 4 块（淡入淡出、截断最敏感）权重减半，总权重仍为 64
+//   - cosine：块平均幅度向量去均值后的余弦距离 (1-r)*32；没有块向量时退化为 ±1 位向量，与汉明一致
+//   - jaccard：子指纹集合（每隔 4 位取 8 位窗口，连同位置）的 Jaccard 距离 (1-J)*64
+//   - segments：多窗口指纹（FileMeta.Segments）逐窗口汉明距离的平均值；任一方没有时退回汉明距离
+package dedup
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+	"strings"
+)
+
+// Metric 计算两个文件之间的距离（0..64）
+type Metric interface {
+	Distance(a, b FileMeta) float64
+}
+
+// MetricFunc 把普通函数适配为 Metric
+type MetricFunc func(a, b FileMeta) float64
+
+// Distance 实现 Metric
+func (f MetricFunc) Distance(a, b FileMeta) float64 { return f(a, b) }
+
+var metrics = map[string]Metric{}
+
+func init() {
+	RegisterMetric("hamming", MetricFunc(func(a, b FileMeta) float64 { return float64(bits.OnesCount64(a.FP ^ b.FP)) }))
+	RegisterMetric("weighted-hamming", WeightedHamming(edgeWeights()))
+	RegisterMetric("cosine", MetricFunc(cosineDistance))
+	RegisterMetric("jaccard", MetricFunc(jaccardDistance))
+	RegisterMetric("segments", MetricFunc(segmentDistance))
+}
+
+// RegisterMetric 以 name 注册距离度量（同名覆盖）
+func RegisterMetric(name string, m Metric) {
+	regMu.Lock()
+	defer regMu.Unlock()
+	metrics[strings.ToLower(name)] = m
+}
+
+// LookupMetric 按名称查找距离度量
+func LookupMetric(name string) (Metric, error) {
+	regMu.RLock()
+	defer regMu.RUnlock()
+	m, ok := metrics[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, fmt.Errorf("未知的距离度量 %q（已注册: %s）", name, strings.Join(metricNamesLocked(), ", "))
+	}
+	return m, nil
+}
+
+// MetricNames 返回已注册的度量名（排序）
+func MetricNames() []string {
+	regMu.RLock()
+	defer regMu.RUnlock()
+	return metricNamesLocked()
+}
+
+func metricNamesLocked() []string {
+	names := make([]string, 0, len(metrics))
+	for n := range metrics {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MetricMatcher 返回按 m 的距离 <= threshold 判定重复的 Matcher；
+// 分组时重复文件的 Distance 也按该度量计算（四舍五入）。
+func MetricMatcher(m Metric, threshold int) Matcher {
+	return metricMatcher{metric: m, threshold: float64(threshold)}
+}
+
+type metricMatcher struct {
+	metric    Metric
+	threshold float64
+}
+
+func (m metricMatcher) Match(a, b FileMeta) bool { return m.metric.Distance(a, b) <= m.threshold }
+
+// WeightedHamming 返回按位加权的汉明距离；weights[0] 对应指纹最高位（第一个块），总和应为 64
+func WeightedHamming(weights [64]float64) Metric {
+	return MetricFunc(func(a, b FileMeta) float64 {
+		x := a.FP ^ b.FP
+		var d float64
+		for x != 0 {
+			i := bits.LeadingZeros64(x)
+			d += weights[i]
+			x &^= 1 << uint(63-i)
+		}
+		return d
+	})
+}
+
+// edgeWeights 首尾各 4 块权重 0.5，其余块均分剩余权重，总和为 64
+func edgeWeights() [64]float64 {
+	var w [64]float64
+	mid := (64 - 8*0.5) / 56
+	for i := range w {
+		if i < 4 || i >= 60 {
+			w[i] = 0.5
+		} else {
+			w[i] = mid
+		}
+	}
+	return w
+}
+
+// cosineDistance 为去均值后的块向量余弦距离，映射到 0..64
+func cosineDistance(a, b FileMeta) float64 {
+	va, vb := blockVector(a), blockVector(b)
+	if len(va) != len(vb) {
+		return 64
+	}
+	center := func(v []float64) {
+		var mean float64
+		for _, x := range v {
+			mean += x
+		}
+		mean /= float64(len(v))
+		for i := range v {
+			v[i] -= mean
+		}
+	}
+	center(va)
+	center(vb)
+	var dot, na, nb float64
+	for i := range va {
+		dot += va[i] * vb[i]
+		na += va[i] * va[i]
+		nb += vb[i] * vb[i]
+	}
+	if na == 0 || nb == 0 {
+		if na == nb {
+			return 0
+		}
+		return 32
+	}
+	return (1 - dot/math.Sqrt(na*nb)) * 32
+}
+
+// blockVector 返回块平均幅度；没有记录时用指纹位构造 ±1 向量
+func blockVector(m FileMeta) []float64 {
+	if len(m.Blocks) > 0 {
+		v := make([]float64, len(m.Blocks))
+		for i, x := range m.Blocks {
+			v[i] = float64(x)
+		}
+		return v
+	}
+	v := make([]float64, 64)
+	for i := range v {
+		v[i] = -1
+		if m.FP&(1<<uint(63-i)) != 0 {
+			v[i] = 1
+		}
+	}
+	return v
+}
+
+// subprints 返回子指纹集合：位置 0,4,...,56 起的 8 位窗口，编码为 位置<<8 | 窗口值
+func subprints(fp uint64) map[uint16]bool {
+	set := make(map[uint16]bool, 15)
+	for pos := 0; pos <= 56; pos += 4 {
+		set[uint16(pos)<<8|uint16(fp>>uint(56-pos)&0xff)] = true
+	}
+	return set
+}
+
+// jaccardDistance 为子指纹集合的 Jaccard 距离，映射到 0..64
+func jaccardDistance(a, b FileMeta) float64 {
+	sa, sb := subprints(a.FP), subprints(b.FP)
+	inter := 0
+	for k := range sa {
+		if sb[k] {
+			inter++
+		}
+	}
+	union := len(sa) + len(sb) - inter
+	return (1 - float64(inter)/float64(union)) * 64
+}
+
+// segmentDistance 为逐窗口汉明距离的平均值：前奏相同但之后不同的歌曲平均距离被拉大，
+// 开头静音长度不同的同一首歌在中段、结尾窗口仍然一致
+func segmentDistance(a, b FileMeta) float64 {
+	if len(a.Segments) == 0 || len(a.Segments) != len(b.Segments) {
+		return float64(bits.OnesCount64(a.FP ^ b.FP))
+	}
+	sum := 0
+	for i := range a.Segments {
+		sum += bits.OnesCount64(a.Segments[i] ^ b.Segments[i])
+	}
+	return float64(sum) / float64(len(a.Segments))
+}
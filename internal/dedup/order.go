@@ -0,0 +1,78 @@
+// file: internal/dedup/order.go
+// package: dedup
+//
+// 处理顺序（-order）：决定文件送去计算指纹、分组送去处理的先后。按 newest 时最近加入的文件最先算出指纹、
+// 所在的组最先处理并写入报告，配合时间预算或中途停止时，新下载的文件不必等整个资料库处理完。
+// 只影响先后，不影响分组与保留文件的选择。
+package dedup
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ProcessOrders 为支持的处理顺序，第一个为默认（按路径）
+var ProcessOrders = []string{"path", "newest", "oldest", "largest"}
+
+// ParseProcessOrder 校验处理顺序名（不区分大小写）
+func ParseProcessOrder(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return ProcessOrders[0], nil
+	}
+	for _, o := range ProcessOrders {
+		if s == o {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("未知的处理顺序 %q（可选: %s）", s, strings.Join(ProcessOrders, ", "))
+}
+
+// OrderBefore 返回 order 下 a 是否应排在 b 之前；相同时按路径
+func OrderBefore(order string, a, b FileMeta) bool {
+	switch order {
+	case "newest":
+		if !a.ModTime.Equal(b.ModTime) {
+			return a.ModTime.After(b.ModTime)
+		}
+	case "oldest":
+		if !a.ModTime.Equal(b.ModTime) {
+			return a.ModTime.Before(b.ModTime)
+		}
+	case "largest":
+		if a.Size != b.Size {
+			return a.Size > b.Size
+		}
+	}
+	return a.Path < b.Path
+}
+
+// OrderGroups 按 order 排列分组：以组内最靠前的成员（最新、最旧或最大的文件）为准，
+// 并按新顺序重新编号。order 为 path 时保持原顺序与编号。
+func OrderGroups(groups []Group, order string) {
+	if order == "" || order == ProcessOrders[0] {
+		return
+	}
+	lead := make(map[int]FileMeta, len(groups)) // 原 ID -> 组内最靠前的成员
+	for _, g := range groups {
+		best := g.Keep
+		for _, m := range g.Protected {
+			if OrderBefore(order, m, best) {
+				best = m
+			}
+		}
+		for _, d := range g.Duplicates {
+			if OrderBefore(order, d.FileMeta, best) {
+				best = d.FileMeta
+			}
+		}
+		lead[g.ID] = best
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return OrderBefore(order, lead[groups[i].ID], lead[groups[j].ID])
+	})
+	for i := range groups {
+		groups[i].ID = i + 1
+	}
+}
@@ -0,0 +1,367 @@
+// file: internal/dedup/policy.go
+// package: dedup
+//
+// 保留策略（KeepPolicy）与匹配器（Matcher）扩展点及其注册表：
+//   - KeepPolicy 决定组内哪个文件被保留；
+//   - Matcher 决定两个文件是否视为重复（用于构建并查集）；
+//   - 支持按名称注册，也支持排序表达式，如 "size desc, path asc"；
+//     内置策略 largest、bitrate、lossless-first、duration、tagged、oldest、newest、path-priority 都是排序表达式。
+package dedup
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"deduplicateMusic/internal/fingerprint"
+	"deduplicateMusic/internal/formats"
+)
+
+// KeepPolicy 决定组内保留顺序：Better(a, b) 为 true 表示 a 比 b 更应被保留。
+// 实现必须是严格弱序且可并发调用。
+type KeepPolicy interface {
+	Better(a, b FileMeta) bool
+}
+
+// Matcher 判定两个文件是否应被视为重复。实现必须可并发调用。
+type Matcher interface {
+	Match(a, b FileMeta) bool
+}
+
+// KeepPolicyFunc 把普通函数适配为 KeepPolicy
+type KeepPolicyFunc func(a, b FileMeta) bool
+
+// Better 实现 KeepPolicy
+func (f KeepPolicyFunc) Better(a, b FileMeta) bool { return f(a, b) }
+
+// MatcherFunc 把普通函数适配为 Matcher
+type MatcherFunc func(a, b FileMeta) bool
+
+// Match 实现 Matcher
+func (f MatcherFunc) Match(a, b FileMeta) bool { return f(a, b) }
+
+// MatcherFactory 按阈值构造 Matcher
+type MatcherFactory func(threshold int) Matcher
+
+// SortKey 比较两个文件的某个属性，返回 <0 / 0 / >0（升序语义）
+type SortKey func(a, b FileMeta) int
+
+var (
+	regMu    sync.RWMutex
+	policies = map[string]KeepPolicy{}
+	matchers = map[string]MatcherFactory{}
+	sortKeys = map[string]SortKey{}
+	// orderSpecs 为以排序表达式注册的策略名 -> 表达式
+	orderSpecs = map[string]string{}
+)
+
+func init() {
+	RegisterSortKey("size", func(a, b FileMeta) int { return cmpInt64(a.Size, b.Size) })
+	RegisterSortKey("path", func(a, b FileMeta) int { return strings.Compare(a.Path, b.Path) })
+	RegisterSortKey("name", func(a, b FileMeta) int {
+		return strings.Compare(filepath.Base(a.Path), filepath.Base(b.Path))
+	})
+	RegisterSortKey("ext", func(a, b FileMeta) int {
+		return strings.Compare(strings.ToLower(filepath.Ext(a.Path)), strings.ToLower(filepath.Ext(b.Path)))
+	})
+	// 按格式注册表中的典型编码质量比较，如 "quality desc, size desc"
+	RegisterSortKey("quality", func(a, b FileMeta) int {
+		return cmpInt64(int64(formats.RankOf(a.Path)), int64(formats.RankOf(b.Path)))
+	})
+	RegisterSortKey("lossless", func(a, b FileMeta) int {
+		fa, _ := formats.ByPath(a.Path)
+		fb, _ := formats.ByPath(b.Path)
+		return cmpBool(fa.Lossless, fb.Lossless)
+	})
+	RegisterSortKey("tagged", func(a, b FileMeta) int {
+		return cmpInt64(int64(a.Tags.Completeness()), int64(b.Tags.Completeness()))
+	})
+	RegisterSortKey("compilation", func(a, b FileMeta) int { return cmpBool(a.Tags.IsCompilation(), b.Tags.IsCompilation()) })
+	RegisterSortKey("errors", func(a, b FileMeta) int { return cmpInt64(int64(a.StreamErrors), int64(b.StreamErrors)) })
+
+	RegisterSortKey("mtime", func(a, b FileMeta) int { return a.ModTime.Compare(b.ModTime) })
+	RegisterSortKey("duration", func(a, b FileMeta) int { return cmpFloat(a.Duration, b.Duration) })
+	RegisterSortKey("bitrate", func(a, b FileMeta) int { return cmpFloat(Bitrate(a), Bitrate(b)) })
+	RegisterSortKey("priority", func(a, b FileMeta) int {
+		// 在 -path-priority 列表中越靠前越小，不在列表中的排在最后
+		return cmpInt64(int64(pathPriority(a.Path)), int64(pathPriority(b.Path)))
+	})
+
+	RegisterOrderPolicy("largest", "size desc, path asc")
+	RegisterOrderPolicy("bitrate", "bitrate desc, size desc, path asc")
+	RegisterOrderPolicy("lossless-first", "lossless desc, quality desc, bitrate desc, size desc, path asc")
+	RegisterOrderPolicy("duration", "duration desc, size desc, path asc")
+	RegisterOrderPolicy("tagged", "tagged desc, quality desc, size desc, path asc")
+	RegisterOrderPolicy("oldest", "mtime asc, size desc, path asc")
+	RegisterOrderPolicy("newest", "mtime desc, size desc, path asc")
+	RegisterOrderPolicy("path-priority", "priority asc, size desc, path asc")
+	RegisterMatcher("hamming", HammingMatcher)
+	RegisterMatcher("speed", SpeedMatcher)
+}
+
+// DefaultPolicy 为默认保留策略：体积最大者优先，体积相同按路径字典序。
+func DefaultPolicy() KeepPolicy {
+	p, _ := LookupKeepPolicy("largest")
+	return p
+}
+
+// HammingMatcher 返回按指纹汉明距离 <= threshold 判定重复的 Matcher
+func HammingMatcher(threshold int) Matcher {
+	return hammingMatcher{threshold: threshold}
+}
+
+// SpeedMatcher 返回容忍轻微速度差异的 Matcher：除原始指纹外，
+// 一方的任一变速指纹（AltFPs）与另一方原始指纹的汉明距离 <= threshold 也视为重复。
+// 不走批量内核，比 HammingMatcher 慢。
+func SpeedMatcher(threshold int) Matcher {
+	return MatcherFunc(func(a, b FileMeta) bool {
+		if fingerprint.HammingDistance(a.FP, b.FP) <= threshold {
+			return true
+		}
+		for _, fp := range a.AltFPs {
+			if fingerprint.HammingDistance(fp, b.FP) <= threshold {
+				return true
+			}
+		}
+		for _, fp := range b.AltFPs {
+			if fingerprint.HammingDistance(fp, a.FP) <= threshold {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// RegisterKeepPolicy 以 name 注册保留策略（同名覆盖）
+func RegisterKeepPolicy(name string, p KeepPolicy) {
+	regMu.Lock()
+	defer regMu.Unlock()
+	policies[strings.ToLower(name)] = p
+}
+
+// RegisterMatcher 以 name 注册匹配器工厂（同名覆盖）
+func RegisterMatcher(name string, f MatcherFactory) {
+	regMu.Lock()
+	defer regMu.Unlock()
+	matchers[strings.ToLower(name)] = f
+}
+
+// RegisterSortKey 注册可在排序表达式中使用的属性名
+func RegisterSortKey(name string, k SortKey) {
+	regMu.Lock()
+	defer regMu.Unlock()
+	sortKeys[strings.ToLower(name)] = k
+}
+
+// LookupKeepPolicy 先按名称查找已注册策略，找不到时把 spec 当作排序表达式解析。
+func LookupKeepPolicy(spec string) (KeepPolicy, error) {
+	regMu.RLock()
+	p, ok := policies[strings.ToLower(strings.TrimSpace(spec))]
+	regMu.RUnlock()
+	if ok {
+		return p, nil
+	}
+	return ParseOrder(spec)
+}
+
+// LookupMatcher 按名称查找匹配器并以 threshold 构造
+func LookupMatcher(name string, threshold int) (Matcher, error) {
+	regMu.RLock()
+	f, ok := matchers[strings.ToLower(strings.TrimSpace(name))]
+	regMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("未知匹配器: %s", name)
+	}
+	return f(threshold), nil
+}
+
+// KeepPolicyNames 返回已注册的策略名（排序后）
+func KeepPolicyNames() []string {
+	regMu.RLock()
+	defer regMu.RUnlock()
+	names := make([]string, 0, len(policies))
+	for n := range policies {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type orderTerm struct {
+	key  SortKey
+	desc bool
+}
+
+// ParseOrder 解析排序表达式，如 "size desc, bitrate desc, path asc"。
+// 每项为 "属性 [asc|desc]"，默认 asc；若未包含 path，则自动追加 "path asc" 保证结果确定。
+func ParseOrder(expr string) (KeepPolicy, error) {
+	var terms []orderTerm
+	hasPath := false
+	regMu.RLock()
+	defer regMu.RUnlock()
+	for _, part := range strings.Split(expr, ",") {
+		fields := strings.Fields(strings.ToLower(part))
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("排序项格式错误: %q", strings.TrimSpace(part))
+		}
+		key, ok := sortKeys[fields[0]]
+		if !ok {
+			return nil, fmt.Errorf("未知排序属性: %s", fields[0])
+		}
+		desc := false
+		if len(fields) == 2 {
+			switch fields[1] {
+			case "asc":
+			case "desc":
+				desc = true
+			default:
+				return nil, fmt.Errorf("排序方向只能是 asc 或 desc: %s", fields[1])
+			}
+		}
+		if fields[0] == "path" {
+			hasPath = true
+		}
+		terms = append(terms, orderTerm{key: key, desc: desc})
+	}
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("空的保留策略表达式")
+	}
+	if !hasPath {
+		terms = append(terms, orderTerm{key: sortKeys["path"]})
+	}
+	return KeepPolicyFunc(func(a, b FileMeta) bool {
+		for _, t := range terms {
+			c := t.key(a, b)
+			if c == 0 {
+				continue
+			}
+			if t.desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	}), nil
+}
+
+// RegisterOrderPolicy 以 name 注册由排序表达式定义的保留策略（同名覆盖），表达式可由 PolicyOrder 取回
+func RegisterOrderPolicy(name, expr string) {
+	p := MustParseOrder(expr)
+	regMu.Lock()
+	orderSpecs[strings.ToLower(name)] = expr
+	regMu.Unlock()
+	RegisterKeepPolicy(name, p)
+}
+
+// PolicyOrder 返回 spec 对应的排序表达式：已注册的表达式策略返回其表达式，
+// 其它已注册策略返回空串，未注册的 spec 本身即视为表达式
+func PolicyOrder(spec string) string {
+	name := strings.ToLower(strings.TrimSpace(spec))
+	regMu.RLock()
+	defer regMu.RUnlock()
+	if expr, ok := orderSpecs[name]; ok {
+		return expr
+	}
+	if _, ok := policies[name]; ok {
+		return ""
+	}
+	return spec
+}
+
+// PolicyUses 返回 spec 的排序表达式是否用到属性 key（如 duration、bitrate、priority）
+func PolicyUses(spec, key string) bool {
+	for _, part := range strings.Split(PolicyOrder(spec), ",") {
+		if f := strings.Fields(strings.ToLower(part)); len(f) > 0 && f[0] == key {
+			return true
+		}
+	}
+	return false
+}
+
+// NeedsDuration 返回保留策略 spec 是否需要文件时长（duration / bitrate 属性）
+func NeedsDuration(spec string) bool {
+	return PolicyUses(spec, "duration") || PolicyUses(spec, "bitrate")
+}
+
+// Bitrate 返回按大小与时长估算的平均码率（kbps），时长未知时为 0
+func Bitrate(m FileMeta) float64 {
+	if m.Duration <= 0 {
+		return 0
+	}
+	return float64(m.Size) * 8 / m.Duration / 1000
+}
+
+var pathPrefixes []string
+
+// SetPathPriority 设置 priority 属性（path-priority 策略）使用的目录优先级，靠前者优先（应在分组前调用）
+func SetPathPriority(dirs []string) {
+	pathPrefixes = pathPrefixes[:0]
+	for _, d := range dirs {
+		if d = strings.TrimSpace(d); d != "" {
+			pathPrefixes = append(pathPrefixes, absPath(d))
+		}
+	}
+}
+
+// pathPriority 返回 p 所在的第一个优先目录的序号，都不在时为目录数
+func pathPriority(p string) int {
+	p = absPath(p)
+	for i, d := range pathPrefixes {
+		if p == d || strings.HasPrefix(p, d+string(filepath.Separator)) {
+			return i
+		}
+	}
+	return len(pathPrefixes)
+}
+
+func absPath(p string) string {
+	if abs, err := filepath.Abs(p); err == nil {
+		return abs
+	}
+	return filepath.Clean(p)
+}
+
+// MustParseOrder 同 ParseOrder，出错时 panic（用于内置策略）
+func MustParseOrder(expr string) KeepPolicy {
+	p, err := ParseOrder(expr)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func cmpInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func cmpFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// cmpBool 按 false < true 比较
+func cmpBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case b:
+		return -1
+	}
+	return 1
+}
@@ -0,0 +1,81 @@
+// file: internal/dedup/tagkey.go
+// package: dedup
+//
+// 按标签预分组（-group-by）：以规范化的 艺术家 + 标题 为键把文件分成若干组，只在同一键内比较，
+// 比较次数随之大幅减少，不同歌曲即使指纹相撞也不会被合并。缺少艺术家或标题的文件归入同一个
+// “无标签” 组，仍只按指纹比较。
+//   - fingerprint：只按指纹（默认，不预分组）
+//   - both：按标签预分组，组内再按指纹判定
+//   - tags：按标签预分组，同键即视为重复（无标签组内仍按指纹）
+package dedup
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"deduplicateMusic/internal/textnorm"
+)
+
+// 分组方式
+const (
+	GroupByFingerprint = "fingerprint"
+	GroupByTags        = "tags"
+	GroupByBoth        = "both"
+)
+
+// ParseGroupBy 校验 -group-by（不区分大小写）
+func ParseGroupBy(s string) (string, error) {
+	switch s = strings.ToLower(strings.TrimSpace(s)); s {
+	case "":
+		return GroupByFingerprint, nil
+	case GroupByFingerprint, GroupByTags, GroupByBoth:
+		return s, nil
+	}
+	return "", fmt.Errorf("未知的分组方式 %q（可选: %s, %s, %s）", s, GroupByFingerprint, GroupByTags, GroupByBoth)
+}
+
+// TagKey 返回文件的标签键：规范化的艺术家（没有时用专辑艺术家）与标题键（见 textnorm.TitleKey）；
+// 缺少任一项时为空串
+func TagKey(m FileMeta) string {
+	artist := m.Tags.Artist
+	if strings.TrimSpace(artist) == "" {
+		artist = m.Tags.AlbumArtist
+	}
+	a, t := textnorm.Normalize(artist), textnorm.TitleKey(m.Tags.Title)
+	if a == "" || t == "" {
+		return ""
+	}
+	return a + "\x00" + t
+}
+
+// TagMatcher 包装 base：两个文件标签键相同（且非空）即视为重复，否则交给 base。
+// 与 Options.Partition = TagKey 配合时即 -group-by tags。
+func TagMatcher(base Matcher) Matcher {
+	return MatcherFunc(func(a, b FileMeta) bool {
+		if ka := TagKey(a); ka != "" && ka == TagKey(b) {
+			return true
+		}
+		return base.Match(a, b)
+	})
+}
+
+// groupPartitioned 按 opts.Partition 把文件分开，各自分组后合并，按保留文件路径重新编号
+func groupPartitioned(files []FileMeta, opts Options) []Group {
+	parts := map[string][]FileMeta{}
+	for _, f := range files {
+		k := opts.Partition(f)
+		parts[k] = append(parts[k], f)
+	}
+	inner := opts
+	inner.Partition = nil
+	var groups []Group
+	for _, part := range parts {
+		groups = append(groups, GroupWith(part, inner)...)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Keep.Path < groups[j].Keep.Path })
+	for i := range groups {
+		groups[i].ID = i + 1
+	}
+	return groups
+}
@@ -0,0 +1,157 @@
+// file: internal/duration/duration.go
+// package: duration
+//
+// 从容器头快速读取时长，不启动 ffprobe、不解码音频：
+// FLAC 读 STREAMINFO 的总样本数，MP4/M4A 读 moov/mvhd，WAV 读 fmt/data 块，
+// MP3 读 Xing/VBRI 头（没有时按 CBR 估算，见 mp3scan.ReadDuration）。
+// 无法识别的格式返回 ErrUnsupported，由调用方回退到 ffprobe。
+package duration
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"deduplicateMusic/internal/mp3scan"
+)
+
+// ErrUnsupported 表示无法从文件头得到时长
+var ErrUnsupported = errors.New("无法从文件头读取时长")
+
+// Read 返回文件时长
+func Read(path string) (time.Duration, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	head := make([]byte, 12)
+	if _, err := io.ReadFull(f, head); err != nil {
+		return 0, ErrUnsupported
+	}
+	switch {
+	case string(head[:4]) == "fLaC":
+		return flac(f)
+	case string(head[4:8]) == "ftyp":
+		return mp4(f)
+	case string(head[:4]) == "RIFF" && string(head[8:12]) == "WAVE":
+		return wav(f)
+	case string(head[:3]) == "ID3" || head[0] == 0xFF && head[1]&0xE0 == 0xE0:
+		d, err := mp3scan.ReadDuration(path)
+		if err != nil {
+			return 0, ErrUnsupported
+		}
+		return d, nil
+	}
+	return 0, ErrUnsupported
+}
+
+// flac 读取紧随 "fLaC" 的 STREAMINFO 块：采样率 20 位、声道 3 位、位深 5 位、总样本数 36 位
+func flac(f *os.File) (time.Duration, error) {
+	b := make([]byte, 4+34)
+	if _, err := f.ReadAt(b, 4); err != nil || b[0]&0x7f != 0 {
+		return 0, ErrUnsupported
+	}
+	si := b[4:]
+	rate := int64(si[10])<<12 | int64(si[11])<<4 | int64(si[12])>>4
+	total := int64(si[13]&0x0f)<<32 | int64(binary.BigEndian.Uint32(si[14:]))
+	if rate == 0 || total == 0 { // 总样本数为 0 表示未知
+		return 0, ErrUnsupported
+	}
+	return scale(total, rate), nil
+}
+
+// mp4 在顶层找到 moov，再在其中找到 mvhd
+func mp4(f *os.File) (time.Duration, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	moov, moovEnd, ok := findAtom(f, 0, fi.Size(), "moov")
+	if !ok {
+		return 0, ErrUnsupported
+	}
+	mvhd, _, ok := findAtom(f, moov, moovEnd, "mvhd")
+	if !ok {
+		return 0, ErrUnsupported
+	}
+	b := make([]byte, 32)
+	n, _ := f.ReadAt(b, mvhd)
+	b = b[:n]
+	var timescale, dur int64
+	switch {
+	case len(b) >= 20 && b[0] == 0: // 版本 0：创建/修改时间各 4 字节
+		timescale = int64(binary.BigEndian.Uint32(b[12:]))
+		dur = int64(binary.BigEndian.Uint32(b[16:]))
+	case len(b) >= 32 && b[0] == 1: // 版本 1：各 8 字节
+		timescale = int64(binary.BigEndian.Uint32(b[20:]))
+		dur = int64(binary.BigEndian.Uint64(b[24:]))
+	default:
+		return 0, ErrUnsupported
+	}
+	if timescale == 0 {
+		return 0, ErrUnsupported
+	}
+	return scale(dur, timescale), nil
+}
+
+// findAtom 在 [start, end) 内查找名为 name 的 atom，返回其内容的起止位置
+func findAtom(f *os.File, start, end int64, name string) (int64, int64, bool) {
+	h := make([]byte, 16)
+	for pos := start; pos+8 <= end; {
+		if _, err := f.ReadAt(h[:8], pos); err != nil {
+			return 0, 0, false
+		}
+		size, hdr := int64(binary.BigEndian.Uint32(h)), int64(8)
+		switch size {
+		case 0: // 延伸到结尾
+			size = end - pos
+		case 1: // 64 位长度
+			if _, err := f.ReadAt(h[8:16], pos+8); err != nil {
+				return 0, 0, false
+			}
+			size, hdr = int64(binary.BigEndian.Uint64(h[8:])), 16
+		}
+		if size < hdr {
+			return 0, 0, false
+		}
+		if string(h[4:8]) == name {
+			return pos + hdr, min(pos+size, end), true
+		}
+		pos += size
+	}
+	return 0, 0, false
+}
+
+// wav 读取 fmt 块的字节率与 data 块的长度
+func wav(f *os.File) (time.Duration, error) {
+	var byteRate, data int64
+	h := make([]byte, 8)
+	for pos := int64(12); byteRate == 0 || data == 0; {
+		if _, err := f.ReadAt(h, pos); err != nil {
+			return 0, ErrUnsupported
+		}
+		size := int64(binary.LittleEndian.Uint32(h[4:]))
+		switch string(h[:4]) {
+		case "fmt ":
+			b := make([]byte, 4)
+			if _, err := f.ReadAt(b, pos+8+8); err != nil {
+				return 0, ErrUnsupported
+			}
+			byteRate = int64(binary.LittleEndian.Uint32(b))
+		case "data":
+			data = size
+		}
+		pos += 8 + size + size&1
+	}
+	return scale(data, byteRate), nil
+}
+
+// scale 把 n / rate 秒换算为 time.Duration，避免溢出
+func scale(n, rate int64) time.Duration {
+	sec := n / rate
+	rem := n % rate
+	return time.Duration(sec)*time.Second + time.Duration(rem)*time.Second/time.Duration(rate)
+}
@@ -0,0 +1,92 @@
+// file: internal/duration/duration_test.go
+// package: duration
+//
+// 用手工构造的 FLAC / MP4 / WAV / MP3 文件头测试时长读取。
+package duration
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func write(t *testing.T, name string, b []byte) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(p, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func atom(name string, body []byte) []byte {
+	b := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(b, uint32(8+len(body)))
+	copy(b[4:], name)
+	return append(b, body...)
+}
+
+func TestRead(t *testing.T) {
+	// FLAC：44100Hz，441000 个样本 = 10 秒
+	si := make([]byte, 34)
+	si[10], si[11], si[12] = 44100>>12, 44100>>4&0xff, 44100&0x0f<<4|0x02
+	binary.BigEndian.PutUint32(si[14:], 441000)
+	fl := append([]byte("fLaC\x80\x00\x00\x22"), si...)
+
+	// MP4：moov 位于 mdat 之后，mvhd 版本 0，timescale 1000，时长 12500
+	mvhd := make([]byte, 20)
+	binary.BigEndian.PutUint32(mvhd[12:], 1000)
+	binary.BigEndian.PutUint32(mvhd[16:], 12500)
+	var m4a bytes.Buffer
+	m4a.Write(atom("ftyp", []byte("M4A \x00\x00\x00\x00")))
+	m4a.Write(atom("mdat", make([]byte, 100)))
+	m4a.Write(atom("moov", append(atom("trak", nil), atom("mvhd", mvhd)...)))
+
+	// WAV：字节率 16000，数据 48000 字节 = 3 秒
+	var wv bytes.Buffer
+	wv.WriteString("RIFF\x00\x00\x00\x00WAVE")
+	fmtChunk := make([]byte, 16)
+	binary.LittleEndian.PutUint32(fmtChunk[8:], 16000)
+	wv.WriteString("fmt \x10\x00\x00\x00")
+	wv.Write(fmtChunk)
+	wv.WriteString("data")
+	binary.Write(&wv, binary.LittleEndian, uint32(48000))
+	wv.Write(make([]byte, 48000))
+
+	// MP3：100 个 128kbps CBR 帧（每帧 1152 样本 @44.1kHz）
+	var mp3 bytes.Buffer
+	mp3.WriteString("ID3\x03\x00\x00\x00\x00\x00\x05abcde")
+	for i := 0; i < 100; i++ {
+		f := make([]byte, 417)
+		copy(f, []byte{0xFF, 0xFB, 0x90, 0x00})
+		mp3.Write(f)
+	}
+
+	cases := []struct {
+		name string
+		data []byte
+		want time.Duration
+		tol  time.Duration
+	}{
+		{"a.flac", fl, 10 * time.Second, 0},
+		{"a.m4a", m4a.Bytes(), 12500 * time.Millisecond, 0},
+		{"a.wav", wv.Bytes(), 3 * time.Second, 0},
+		{"a.mp3", mp3.Bytes(), 100 * 1152 * time.Second / 44100, 10 * time.Millisecond},
+	}
+	for _, c := range cases {
+		got, err := Read(write(t, c.name, c.data))
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if d := got - c.want; d < -c.tol || d > c.tol {
+			t.Errorf("%s: 时长 %v，期望 %v", c.name, got, c.want)
+		}
+	}
+
+	if _, err := Read(write(t, "x.bin", []byte("not audio at all"))); err != ErrUnsupported {
+		t.Errorf("未知格式应返回 ErrUnsupported，得到 %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+// file: internal/errbudget/errbudget.go
+// package: errbudget
+//
+// 失败阈值（-max-errors）：计算指纹时失败的文件超过上限（绝对数量，或占全部文件的百分比）时提前中止。
+// 大量文件解码失败往往是缺少解码器、挂载点掉线等整体问题，继续运行只会耗上几个小时得到一份满是失败的报告；
+// 中止时按失败原因归类汇总，便于直接定位问题。
+package errbudget
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Limit 为失败上限：Count > 0 为绝对数量，Percent > 0 为占全部文件的百分比；都为零表示不限制
+type Limit struct {
+	Count   int
+	Percent float64
+}
+
+// Parse 解析 -max-errors："50" 或 "5%"；空串表示不限制
+func Parse(s string) (Limit, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Limit{}, nil
+	}
+	if p, ok := strings.CutSuffix(s, "%"); ok {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil || v <= 0 || v > 100 {
+			return Limit{}, fmt.Errorf("无效的失败上限 %q：百分比应在 0 到 100 之间，如 5%%", s)
+		}
+		return Limit{Percent: v}, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("无效的失败上限 %q：应为正整数（如 50）或百分比（如 5%%）", s)
+	}
+	return Limit{Count: n}, nil
+}
+
+// Max 返回共 total 个文件时允许的失败数；不限制时返回 -1
+func (l Limit) Max(total int) int {
+	switch {
+	case l.Count > 0:
+		return l.Count
+	case l.Percent > 0:
+		return int(l.Percent * float64(total) / 100)
+	}
+	return -1
+}
+
+func (l Limit) String() string {
+	if l.Percent > 0 {
+		return strconv.FormatFloat(l.Percent, 'f', -1, 64) + "%"
+	}
+	return strconv.Itoa(l.Count)
+}
+
+// Cause 为一类失败原因及其文件数
+type Cause struct {
+	Reason  string
+	Count   int
+	Example string // 第一个因此失败的文件
+}
+
+// Budget 统计失败的文件；失败数超过上限后 Exceeded 返回 true。nil 表示不限制。并发安全。
+type Budget struct {
+	mu     sync.Mutex
+	limit  Limit
+	max    int
+	failed int
+	causes map[string]*Cause
+}
+
+// New 为共 total 个文件的运行创建失败统计；limit 不限制时返回 nil
+func New(limit Limit, total int) *Budget {
+	max := limit.Max(total)
+	if max < 0 {
+		return nil
+	}
+	return &Budget{limit: limit, max: max, causes: map[string]*Cause{}}
+}
+
+// Fail 记入一个失败的文件，返回失败数是否已超过上限。原因取错误信息，其中的文件路径替换为 <file>，
+// 同一原因（如 “ffmpeg 未找到”、某种格式的解码错误）的文件归为一类。
+func (b *Budget) Fail(path string, err error) bool {
+	if b == nil {
+		return false
+	}
+	reason := err.Error()
+	if path != "" {
+		reason = strings.ReplaceAll(reason, path, "<file>")
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failed++
+	c := b.causes[reason]
+	if c == nil {
+		c = &Cause{Reason: reason, Example: path}
+		b.causes[reason] = c
+	}
+	c.Count++
+	return b.failed > b.max
+}
+
+// Exceeded 返回失败数是否已超过上限
+func (b *Budget) Exceeded() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failed > b.max
+}
+
+// Causes 返回按文件数从多到少排列的失败原因，最多 n 类（n <= 0 时全部返回）
+func (b *Budget) Causes(n int) []Cause {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]Cause, 0, len(b.causes))
+	for _, c := range b.causes {
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Reason < out[j].Reason
+	})
+	if n > 0 && len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+// Summary 返回中止时的说明，如 "失败文件 51 个，超过 -max-errors 50"
+func (b *Budget) Summary() string {
+	if b == nil {
+		return ""
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.limit.Percent > 0 {
+		return fmt.Sprintf("失败文件 %d 个，超过 -max-errors %s（%d 个）", b.failed, b.limit, b.max)
+	}
+	return fmt.Sprintf("失败文件 %d 个，超过 -max-errors %s", b.failed, b.limit)
+}
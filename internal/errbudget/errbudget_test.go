@@ -0,0 +1,55 @@
+// file: internal/errbudget/errbudget_test.go
+// package: errbudget
+//
+// 测试失败上限的解析（绝对数量、百分比）、超限判断与按原因归类。
+package errbudget
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for _, c := range []struct {
+		in    string
+		total int
+		max   int
+	}{
+		{"", 100, -1},
+		{"50", 1000, 50},
+		{"5%", 1000, 50},
+		{" 2.5% ", 1000, 25},
+	} {
+		l, err := Parse(c.in)
+		if err != nil || l.Max(c.total) != c.max {
+			t.Fatalf("Parse(%q).Max(%d) = %d, %v；期望 %d", c.in, c.total, l.Max(c.total), err, c.max)
+		}
+	}
+	for _, in := range []string{"0", "-3", "abc", "0%", "150%"} {
+		if _, err := Parse(in); err == nil {
+			t.Fatalf("Parse(%q) 应报错", in)
+		}
+	}
+}
+
+func TestBudget(t *testing.T) {
+	if New(Limit{}, 10) != nil || (*Budget)(nil).Fail("a", errors.New("x")) {
+		t.Fatalf("不限制时应为 nil 且从不超限")
+	}
+	b := New(Limit{Count: 2}, 10)
+	missing := errors.New("ffmpeg 未找到")
+	if b.Fail("/m/a.ape", missing) || b.Fail("/m/b.ape", missing) || b.Exceeded() {
+		t.Fatalf("未超过上限")
+	}
+	if !b.Fail("/m/c.mp3", errors.New("打开 /m/c.mp3 失败: input/output error")) || !b.Exceeded() {
+		t.Fatalf("第 3 个失败应超过上限 2")
+	}
+	causes := b.Causes(0)
+	if len(causes) != 2 || causes[0].Count != 2 || causes[0].Example != "/m/a.ape" ||
+		causes[1].Reason != "打开 <file> 失败: input/output error" {
+		t.Fatalf("Causes = %+v", causes)
+	}
+	if got := b.Summary(); got != "失败文件 3 个，超过 -max-errors 2" {
+		t.Fatalf("Summary = %q", got)
+	}
+}
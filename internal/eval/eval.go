@@ -0,0 +1,220 @@
+// file: internal/eval/eval.go
+// package: eval
+//
+// 去重效果评估：给定带标注的数据集（哪些文件互为重复），按当前算法分组后
+// 以"文件对"为单位统计 TP / FP / FN，得到 precision / recall / F1。
+// 分组具有传递性（A~B、B~C 则 A、B、C 同组），因此预测的重复对取同组内的全部文件对。
+package eval
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+
+	"deduplicateMusic/internal/dedup"
+	"deduplicateMusic/internal/gzfile"
+)
+
+// Truth 为标注：Label 记录参与评估的文件及其分组，分组相同的文件互为重复
+type Truth struct {
+	Label map[string]string
+}
+
+// LoadGroups 读取 "path,group" 格式的标注 CSV（有无表头均可；表头首列为 path 时跳过）。
+// 相对路径相对于 base 目录。
+func LoadGroups(r io.Reader, base string) (Truth, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	t := Truth{Label: map[string]string{}}
+	for line := 1; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return t, nil
+		}
+		if err != nil {
+			return t, err
+		}
+		if len(rec) < 2 {
+			return t, fmt.Errorf("第 %d 行应为 path,group", line)
+		}
+		if line == 1 && rec[0] == "path" {
+			continue
+		}
+		t.Label[resolve(base, rec[0])] = rec[1]
+	}
+}
+
+// LoadGroupsFile 同 LoadGroups，从文件（可为 gzip 压缩）读取；相对路径相对于 CSV 所在目录
+func LoadGroupsFile(path string) (Truth, error) {
+	f, err := gzfile.Open(path)
+	if err != nil {
+		return Truth{}, err
+	}
+	defer f.Close()
+	return LoadGroups(f, filepath.Dir(path))
+}
+
+func resolve(base, p string) string {
+	if filepath.IsAbs(p) || base == "" {
+		return filepath.Clean(p)
+	}
+	return filepath.Join(base, p)
+}
+
+// LoadPairs 读取 "a,b" 格式的已知重复对 CSV（表头首列为 a 或 path_a 时跳过），按传递性合并为分组。
+// 相对路径相对于 base 目录。
+func LoadPairs(r io.Reader, base string) (Truth, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	parent := map[string]string{}
+	var find func(string) string
+	find = func(x string) string {
+		if parent[x] == x {
+			return x
+		}
+		parent[x] = find(parent[x])
+		return parent[x]
+	}
+	add := func(x string) {
+		if _, ok := parent[x]; !ok {
+			parent[x] = x
+		}
+	}
+	for line := 1; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Truth{}, err
+		}
+		if len(rec) < 2 {
+			return Truth{}, fmt.Errorf("第 %d 行应为 a,b", line)
+		}
+		if line == 1 && (rec[0] == "a" || rec[0] == "path_a") {
+			continue
+		}
+		a, b := resolve(base, rec[0]), resolve(base, rec[1])
+		add(a)
+		add(b)
+		parent[find(a)] = find(b)
+	}
+	t := Truth{Label: map[string]string{}}
+	for x := range parent {
+		t.Label[x] = find(x)
+	}
+	return t, nil
+}
+
+// LoadPairsFile 同 LoadPairs，从文件（可为 gzip 压缩）读取；相对路径相对于 CSV 所在目录
+func LoadPairsFile(path string) (Truth, error) {
+	f, err := gzfile.Open(path)
+	if err != nil {
+		return Truth{}, err
+	}
+	defer f.Close()
+	return LoadPairs(f, filepath.Dir(path))
+}
+
+// Score 为一次评估的结果
+type Score struct {
+	Threshold int
+	TP        int
+	FP        int
+	FN        int
+	Precision float64
+	Recall    float64
+	F1        float64
+}
+
+func (s *Score) finish() {
+	if s.TP+s.FP > 0 {
+		s.Precision = float64(s.TP) / float64(s.TP+s.FP)
+	}
+	if s.TP+s.FN > 0 {
+		s.Recall = float64(s.TP) / float64(s.TP+s.FN)
+	}
+	if s.Precision+s.Recall > 0 {
+		s.F1 = 2 * s.Precision * s.Recall / (s.Precision + s.Recall)
+	}
+}
+
+// Evaluate 按 opts 分组 metas，与标注比较。只统计标注中出现的文件之间的文件对。
+func Evaluate(metas []dedup.FileMeta, truth Truth, opts dedup.Options) Score {
+	var labeled []dedup.FileMeta
+	for _, m := range metas {
+		if _, ok := truth.Label[filepath.Clean(m.Path)]; ok {
+			labeled = append(labeled, m)
+		}
+	}
+	predicted := map[string]int{}
+	for _, g := range dedup.GroupWith(labeled, opts) {
+		predicted[filepath.Clean(g.Keep.Path)] = g.ID
+		for _, m := range g.Protected {
+			predicted[filepath.Clean(m.Path)] = g.ID
+		}
+		for _, d := range g.Duplicates {
+			predicted[filepath.Clean(d.Path)] = g.ID
+		}
+	}
+	s := Score{Threshold: opts.Threshold}
+	for i := range labeled {
+		for j := i + 1; j < len(labeled); j++ {
+			a, b := filepath.Clean(labeled[i].Path), filepath.Clean(labeled[j].Path)
+			same := truth.Label[a] == truth.Label[b]
+			pred := predicted[a] == predicted[b]
+			switch {
+			case same && pred:
+				s.TP++
+			case pred:
+				s.FP++
+			case same:
+				s.FN++
+			}
+		}
+	}
+	s.finish()
+	return s
+}
+
+// Sweep 对每个阈值评估一次，matcher 按阈值构造匹配器（为 nil 时使用默认匹配器）
+func Sweep(metas []dedup.FileMeta, truth Truth, thresholds []int, matcher func(threshold int) (dedup.Matcher, error)) ([]Score, error) {
+	out := make([]Score, 0, len(thresholds))
+	for _, th := range thresholds {
+		opts := dedup.Options{Threshold: th}
+		if matcher != nil {
+			m, err := matcher(th)
+			if err != nil {
+				return nil, err
+			}
+			opts.Matcher = m
+		}
+		out = append(out, Evaluate(metas, truth, opts))
+	}
+	return out, nil
+}
+
+// Best 返回 F1 最高的结果（相同时取阈值较小者）
+func Best(scores []Score) Score {
+	var best Score
+	for i, s := range scores {
+		if i == 0 || s.F1 > best.F1 {
+			best = s
+		}
+	}
+	return best
+}
+
+// WriteCurve 把阈值-指标曲线写为 CSV
+func WriteCurve(w io.Writer, scores []Score) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"Threshold", "TP", "FP", "FN", "Precision", "Recall", "F1"})
+	for _, s := range scores {
+		cw.Write([]string{strconv.Itoa(s.Threshold), strconv.Itoa(s.TP), strconv.Itoa(s.FP), strconv.Itoa(s.FN),
+			strconv.FormatFloat(s.Precision, 'f', 4, 64), strconv.FormatFloat(s.Recall, 'f', 4, 64), strconv.FormatFloat(s.F1, 'f', 4, 64)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
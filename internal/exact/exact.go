@@ -0,0 +1,95 @@
+// file: internal/exact/exact.go
+// package: exact
+//
+// 逐字节相同文件的快速路径：在声学指纹之前按内容 SHA-256 找出完全相同的文件，
+// 每组只需解码一个（代表文件），其余成员直接复用代表文件的分析结果。
+// 大小不同的文件不可能相同，因此只对大小与其它文件相同的文件计算哈希。
+package exact
+
+import (
+	"sort"
+	"sync"
+
+	"deduplicateMusic/internal/audit"
+)
+
+// File 为候选文件
+type File struct {
+	Path string
+	Size int64
+}
+
+// Result 为查找结果
+type Result struct {
+	Hash map[string]string // 路径 -> SHA-256（十六进制），只含实际计算了哈希的文件
+	Sets [][]string        // 内容完全相同的文件组（至少两个），组内按路径排序，首个为代表文件
+}
+
+// Find 用 workers 个并发计算大小相同的文件的哈希并按哈希分组；读取失败的文件不参与分组
+// （留给后续解码报告错误）。空文件不参与比较。
+func Find(files []File, workers int) Result {
+	bySize := map[int64][]string{}
+	for _, f := range files {
+		if f.Size > 0 {
+			bySize[f.Size] = append(bySize[f.Size], f.Path)
+		}
+	}
+	var candidates []string
+	for _, paths := range bySize {
+		if len(paths) > 1 {
+			candidates = append(candidates, paths...)
+		}
+	}
+	sort.Strings(candidates)
+
+	res := Result{Hash: make(map[string]string, len(candidates))}
+	if workers < 1 {
+		workers = 1
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	next := make(chan string)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range next {
+				d, err := audit.HashFile(p)
+				if err != nil {
+					continue
+				}
+				mu.Lock()
+				res.Hash[p] = d.SHA256
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, p := range candidates {
+		next <- p
+	}
+	close(next)
+	wg.Wait()
+
+	byHash := map[string][]string{}
+	for _, p := range candidates { // candidates 已排序，组内路径有序
+		if h, ok := res.Hash[p]; ok {
+			byHash[h] = append(byHash[h], p)
+		}
+	}
+	for _, paths := range byHash {
+		if len(paths) > 1 {
+			res.Sets = append(res.Sets, paths)
+		}
+	}
+	sort.Slice(res.Sets, func(i, j int) bool { return res.Sets[i][0] < res.Sets[j][0] })
+	return res
+}
+
+// Followers 返回 代表文件 -> 其余成员 的映射
+func (r Result) Followers() map[string][]string {
+	out := make(map[string][]string, len(r.Sets))
+	for _, s := range r.Sets {
+		out[s[0]] = s[1:]
+	}
+	return out
+}
@@ -0,0 +1,46 @@
+// file: internal/exact/exact_test.go
+// package: exact
+//
+// 测试只有内容完全相同的文件被分为一组，大小相同但内容不同、大小唯一的文件不分组。
+package exact
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) File {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return File{Path: p, Size: int64(len(content))}
+	}
+	files := []File{
+		write("c.mp3", "same bytes"),
+		write("a.mp3", "same bytes"),
+		write("b.mp3", "diff bytes"), // 大小相同、内容不同
+		write("d.mp3", "unique size"),
+		write("e.mp3", "same bytes"),
+		{Path: filepath.Join(dir, "missing.mp3"), Size: 10}, // 读取失败
+	}
+	r := Find(files, 2)
+	want := [][]string{{filepath.Join(dir, "a.mp3"), filepath.Join(dir, "c.mp3"), filepath.Join(dir, "e.mp3")}}
+	if !reflect.DeepEqual(r.Sets, want) {
+		t.Fatalf("Sets = %v", r.Sets)
+	}
+	if _, ok := r.Hash[filepath.Join(dir, "d.mp3")]; ok {
+		t.Fatal("大小唯一的文件不应计算哈希")
+	}
+	if r.Hash[filepath.Join(dir, "b.mp3")] == "" || len(r.Hash) != 4 {
+		t.Fatalf("Hash = %v", r.Hash)
+	}
+	f := r.Followers()
+	if len(f) != 1 || len(f[filepath.Join(dir, "a.mp3")]) != 2 {
+		t.Fatalf("Followers = %v", f)
+	}
+}
@@ -0,0 +1,71 @@
+// file: internal/filestamp/filestamp.go
+// package: filestamp
+//
+// 文件快照：在计算指纹（做出决策）时记录大小、修改时间与首尾各 64KB 的哈希，
+// 执行复制/替换或写出删除清单前再比对一次，跳过期间被重新打标签或替换的文件，
+// 避免按过期的决策处理文件。只读首尾数据，代价远小于解码。
+package filestamp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+)
+
+const partialSize = 64 << 10
+
+// Stamp 为文件快照；零值表示未记录
+type Stamp struct {
+	Size    int64  `json:"size,omitempty"`
+	ModTime int64  `json:"mtime,omitempty"` // UnixNano
+	Partial string `json:"partial,omitempty"`
+}
+
+// Recorded 返回是否记录过快照
+func (s Stamp) Recorded() bool { return s.Partial != "" }
+
+// Take 记录 path 的快照
+func Take(path string) (Stamp, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Stamp{}, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return Stamp{}, err
+	}
+	h := sha256.New()
+	if _, err := io.CopyN(h, f, partialSize); err != nil && err != io.EOF {
+		return Stamp{}, err
+	}
+	if tail := info.Size() - partialSize; tail > partialSize {
+		if _, err := io.Copy(h, io.NewSectionReader(f, tail, partialSize)); err != nil {
+			return Stamp{}, err
+		}
+	} else if tail > 0 {
+		if _, err := io.Copy(h, io.NewSectionReader(f, partialSize, tail)); err != nil {
+			return Stamp{}, err
+		}
+	}
+	return Stamp{Size: info.Size(), ModTime: info.ModTime().UnixNano(), Partial: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// Changed 重新读取 path 并与快照比较，返回变化说明；未变化时返回空串
+func (s Stamp) Changed(path string) (string, error) {
+	now, err := Take(path)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case now.Size != s.Size:
+		return fmt.Sprintf("大小由 %d 变为 %d", s.Size, now.Size), nil
+	case now.Partial != s.Partial:
+		return "内容已改变", nil
+	case now.ModTime != s.ModTime:
+		return "修改时间已改变", nil
+	}
+	return "", nil
+}
@@ -0,0 +1,45 @@
+// file: internal/filestamp/filestamp_test.go
+// package: filestamp
+//
+// 测试快照能发现追加、同长度改写（重新打标签）与仅修改时间的变化。
+package filestamp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestChanged(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "a.flac")
+	data := make([]byte, 200<<10)
+	if err := os.WriteFile(p, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Take(p)
+	if err != nil || !s.Recorded() {
+		t.Fatalf("Take: %+v, %v", s, err)
+	}
+	if why, err := s.Changed(p); err != nil || why != "" {
+		t.Fatalf("未改动的文件: %q, %v", why, err)
+	}
+
+	data[10] = 1 // 同长度改写开头（如重新写入标签）
+	os.WriteFile(p, data, 0o644)
+	if why, _ := s.Changed(p); why != "内容已改变" {
+		t.Fatalf("改写开头: %q", why)
+	}
+
+	os.WriteFile(p, append(data, 0), 0o644)
+	if why, _ := s.Changed(p); why == "" {
+		t.Fatal("追加数据应被发现")
+	}
+
+	data[10] = 0
+	os.WriteFile(p, data, 0o644)
+	os.Chtimes(p, time.Now(), time.Now().Add(time.Hour))
+	if why, _ := s.Changed(p); why != "修改时间已改变" {
+		t.Fatalf("仅修改时间: %q", why)
+	}
+}
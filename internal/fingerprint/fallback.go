@@ -0,0 +1,105 @@
+// file: internal/fingerprint/fallback.go
+// package: fingerprint
+//
+// 内置后备解码器：找不到 ffmpeg 时代替 ffmpeg 解码原生解码器不支持的格式（MP3、AAC 等），
+// 供无法安装外部程序的受限系统使用。本包自带纯 Go 的 MP3 解码器（见 mp3.go）；
+// 其它格式（如以 WASM 编译、随程序一起分发的 AAC 解码器）由嵌入方（或 Go 插件）通过 pkg/audiodedup 注册，
+// 注册的解码器优先于自带的。已安装 ffmpeg 时总是使用 ffmpeg。
+package fingerprint
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"deduplicateMusic/internal/formats"
+	"deduplicateMusic/internal/quota"
+)
+
+// FallbackDecoder 为后备解码器
+type FallbackDecoder struct {
+	Name    string   // 名称，启动时显示
+	Formats []string // 支持的格式名（见格式注册表），如 "mp3"、"aac"
+	// Decode 跳过开头 skip 后解码 r 的前 seconds 秒（<= 0 表示到结尾），
+	// 输出与 ffmpeg 后端相同：8kHz 单声道 int16 PCM
+	Decode func(r io.Reader, seconds int, skip time.Duration) ([]int16, error)
+}
+
+var (
+	fallbackMu       sync.RWMutex
+	fallbacks        []FallbackDecoder
+	builtinFallbacks []FallbackDecoder // 本包自带的后备解码器，排在注册的解码器之后
+)
+
+// RegisterFallbackDecoder 注册后备解码器；多个解码器支持同一格式时先注册者优先
+func RegisterFallbackDecoder(d FallbackDecoder) {
+	fallbackMu.Lock()
+	defer fallbackMu.Unlock()
+	fallbacks = append(fallbacks, d)
+}
+
+// fallbackList 返回按优先顺序排列的全部后备解码器；调用方须持有 fallbackMu
+func fallbackList() []FallbackDecoder {
+	return append(fallbacks[:len(fallbacks):len(fallbacks)], builtinFallbacks...)
+}
+
+// fallbackFor 返回支持格式 name 的后备解码器
+func fallbackFor(name string) (FallbackDecoder, bool) {
+	fallbackMu.RLock()
+	defer fallbackMu.RUnlock()
+	for _, d := range fallbackList() {
+		for _, f := range d.Formats {
+			if strings.EqualFold(f, name) {
+				return d, true
+			}
+		}
+	}
+	return FallbackDecoder{}, false
+}
+
+// fallbackSummary 描述已注册的后备解码器，如 "wasm（mp3、aac）"；没有时为空串
+func fallbackSummary() string {
+	fallbackMu.RLock()
+	defer fallbackMu.RUnlock()
+	var parts []string
+	for _, d := range fallbackList() {
+		parts = append(parts, fmt.Sprintf("%s（%s）", d.Name, strings.Join(d.Formats, "、")))
+	}
+	return strings.Join(parts, "，")
+}
+
+// decodeFallback 用后备解码器解码 input（stdin 非 nil 时从 stdin 读取）：按文件头识别格式，
+// 无法识别时按扩展名。ok 为 false 表示没有支持该格式的后备解码器。
+func decodeFallback(input string, stdin io.Reader, seconds int, skip time.Duration) (samples []int16, ok bool, err error) {
+	src := stdin
+	if src == nil {
+		f, err := os.Open(input)
+		if err != nil {
+			return nil, true, err
+		}
+		defer f.Close()
+		src = quota.Reader(f)
+	}
+	br := bufio.NewReaderSize(src, 64<<10)
+	head, _ := br.Peek(sniffLen)
+	format, found := formats.Sniff(head)
+	if !found {
+		format, found = formats.ByPath(input)
+	}
+	if !found {
+		return nil, false, nil
+	}
+	d, found := fallbackFor(format.Name)
+	if !found {
+		return nil, false, nil
+	}
+	samples, err = d.Decode(br, seconds, skip)
+	if err != nil {
+		return nil, true, fmt.Errorf("%s 解码失败: %v", d.Name, err)
+	}
+	return samples, true, nil
+}
@@ -0,0 +1,46 @@
+// file: internal/fingerprint/fallback_test.go
+// package: fingerprint
+//
+// 测试后备解码器按文件头 / 扩展名选用，以及解码器检查把后备解码器支持的格式视为可解码。
+package fingerprint
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDecodeFallback(t *testing.T) {
+	defer func() { fallbacks = nil }()
+	var got []byte
+	RegisterFallbackDecoder(FallbackDecoder{Name: "test", Formats: []string{"mp3"},
+		Decode: func(r io.Reader, seconds int, skip time.Duration) ([]int16, error) {
+			got, _ = io.ReadAll(r)
+			return []int16{1, 2, 3}, nil
+		}})
+
+	dir := t.TempDir()
+	mp3 := filepath.Join(dir, "a.bin") // 扩展名未知，按 ID3 文件头识别
+	if err := os.WriteFile(mp3, []byte("ID3\x04\x00\x00\x00\x00\x00\x00frames"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	samples, ok, err := decodeFallback(mp3, nil, 8, 0)
+	if !ok || err != nil || len(samples) != 3 || string(got) != "ID3\x04\x00\x00\x00\x00\x00\x00frames" {
+		t.Fatalf("decodeFallback = %v, %v, %v；解码器读到 %q", samples, ok, err, got)
+	}
+	ogg := filepath.Join(dir, "b.ogg")
+	if err := os.WriteFile(ogg, []byte("OggS\x00\x02"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := decodeFallback(ogg, nil, 8, 0); ok {
+		t.Fatalf("没有支持 ogg 的后备解码器")
+	}
+
+	// 未找到 ffmpeg 时，后备解码器支持的格式不算缺少
+	ms := missing(map[string]int{"mp3": 4, "ogg": 1}, BackendAuto, false, nil)
+	if len(ms) != 1 || ms[0].Format != "ogg" {
+		t.Fatalf("missing = %+v", ms)
+	}
+}
@@ -8,20 +8,28 @@
 //   - 返回 uint64 位掩码（若 bits <= 64）
 //
 // 这样的方法简单、轻量且对音量/编码差异有一定鲁棒性；不是最强的音频指纹（如Chromaprint/FP），但实现简单且易测试。
-// 依赖：要求系统安装 ffmpeg（可用 `ffmpeg -version` 验证）。
+// 依赖：WAV/FLAC 可由原生解码器在进程内解码（见 native.go），其余格式要求系统安装 ffmpeg
+// （可用 `ffmpeg -version` 验证）；未安装时 MP3 由内置后备解码器解码（见 mp3.go）。
 package fingerprint
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"math"
 	"os"
 	"os/exec"
 	"sort"
 	"strings"
 	"sync"
+	"time"
+
+	"deduplicateMusic/internal/duration"
+	"deduplicateMusic/internal/quota"
 )
 
 import "math/bits"
@@ -36,60 +44,299 @@ func FingerprintFromFile(path string, seconds int, bitsLen int) (uint64, int64,
 	if bitsLen <= 0 || bitsLen > 64 {
 		return 0, 0, fmt.Errorf("bitsLen must be 1..64")
 	}
-	// 检查 ffmpeg 是否存在（仅第一次检查即可）
+	samples, err := decodePCM(context.Background(), path, nil, seconds, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// 计算指纹
+	fp := FingerprintFromSamples(samples, bitsLen)
+
+	// 获取文件大小
+	info, err := exec.Command("stat", "-c", "%s", path).Output() // linux stat
+	if err != nil {
+		// 跨平台退回 go 的文件读取方式
+		fi, e2 := getFileSizeFallback(path)
+		if e2 != nil {
+			return fp, 0, nil // 返回 fingerprint，文件大小未知
+		}
+		return fp, fi, nil
+	}
+	var size int64
+	_, _ = fmt.Sscan(string(bytes.TrimSpace(info)), &size)
+	return fp, size, nil
+}
+
+// EnvelopePoints 为波形包络的采样点数
+const EnvelopePoints = 120
+
+// Analysis 为一次解码得到的分析结果
+type Analysis struct {
+	FP       uint64
+	Envelope []uint8   // 波形包络（每点为该段峰值，0..255），仅在 Options.Envelope 时计算
+	AltFPs   []uint64  // 按 Options.SpeedFactors 变速后的指纹，顺序与其一致
+	Blocks   []float32 // 量化前的各块平均幅度，仅在 Options.Blocks 时保留（供余弦等距离使用）
+	Segments []uint64  // 多窗口指纹（开头、中段、结尾），仅在 Options.Segments > 1 时计算
+}
+
+// Options 为 AnalyzeFile / AnalyzeReader 的参数
+type Options struct {
+	Seconds  int           // 用于指纹的音频时长（秒）
+	Bits     int           // 指纹位数（1..64）
+	Envelope bool          // 是否同时从同一份 PCM 计算波形包络（用于缩略图）
+	Skip     time.Duration // 先丢弃开头这段音频（如未记录无缝信息的 MP3 的编码器延迟），再取 Seconds 秒
+	Blocks   bool          // 是否保留量化前的块平均幅度
+	Segments int           // > 1 时额外计算这么多个窗口的指纹（见 segments.go），只对本地文件生效
+
+	// SpeedFactors 非空时额外计算变速版本的指纹（如 1.03 表示快 3%），用于容忍黑胶翻录/PAL 加速等速度差异
+	SpeedFactors []float64
+}
+
+// DefaultSpeedFactors 覆盖约 ±3% 的速度差异；步长与 64 块指纹对时间偏移的容忍度相当
+var DefaultSpeedFactors = []float64{0.97, 0.985, 1.015, 1.03}
+
+// decodeSeconds 返回需要解码的秒数：变速版本需要更长的原始音频
+func (o Options) decodeSeconds() int {
+	maxF := 1.0
+	for _, f := range o.SpeedFactors {
+		if f > maxF {
+			maxF = f
+		}
+	}
+	if maxF == 1 {
+		return o.Seconds
+	}
+	return int(math.Ceil(float64(o.Seconds)*maxF)) + 1
+}
+
+// Signature 返回影响分析结果的参数签名；参数相同的两次分析可以互相复用（见 internal/cache）
+func (o Options) Signature() string {
+	return fmt.Sprintf("v1 seconds=%d bits=%d skip=%d envelope=%t blocks=%t segments=%d speed=%v",
+		o.Seconds, o.Bits, o.Skip, o.Envelope, o.Blocks, o.Segments, o.SpeedFactors)
+}
+
+// AnalyzeFile 解码文件并计算指纹
+func AnalyzeFile(path string, o Options) (Analysis, error) {
+	return AnalyzeFileContext(context.Background(), path, o)
+}
+
+// AnalyzeFileContext 同 AnalyzeFile；ctx 取消时终止正在运行的 ffmpeg 并返回 ctx.Err()
+func AnalyzeFileContext(ctx context.Context, path string, o Options) (Analysis, error) {
+	if o.Bits <= 0 || o.Bits > 64 {
+		return Analysis{}, fmt.Errorf("bitsLen must be 1..64")
+	}
+	samples, err := decodePCM(ctx, path, nil, o.decodeSeconds(), o.Skip)
+	if err != nil {
+		return Analysis{}, err
+	}
+	a := analyze(samples, o)
+	if o.Segments > 1 {
+		// 取不到时长或某个窗口解码失败时不计算多窗口指纹，比较时退回整体指纹
+		a.Segments, _ = segmentFingerprints(ctx, path, o)
+	}
+	return a, nil
+}
+
+// AnalyzeReader 同 AnalyzeFile，音频数据从 r 经 stdin 送入 ffmpeg
+func AnalyzeReader(r io.Reader, o Options) (Analysis, error) {
+	return AnalyzeReaderContext(context.Background(), r, o)
+}
+
+// AnalyzeReaderContext 同 AnalyzeReader；ctx 取消时终止正在运行的 ffmpeg 并返回 ctx.Err()
+func AnalyzeReaderContext(ctx context.Context, r io.Reader, o Options) (Analysis, error) {
+	if o.Bits <= 0 || o.Bits > 64 {
+		return Analysis{}, fmt.Errorf("bitsLen must be 1..64")
+	}
+	samples, err := decodePCM(ctx, "pipe:0", r, o.decodeSeconds(), o.Skip)
+	if err != nil {
+		return Analysis{}, err
+	}
+	return analyze(samples, o), nil
+}
+
+func analyze(samples []int16, o Options) Analysis {
+	n := o.Seconds * SampleRate
+	base := samples
+	if len(base) > n {
+		base = base[:n] // 为变速版本多解码的部分不参与原始指纹
+	}
+	a := Analysis{FP: FingerprintFromSamples(base, o.Bits)}
+	if o.Blocks {
+		for _, v := range BlockAverages(base, o.Bits) {
+			a.Blocks = append(a.Blocks, float32(v))
+		}
+	}
+	if o.Envelope {
+		a.Envelope = Envelope(base, EnvelopePoints)
+	}
+	for _, f := range o.SpeedFactors {
+		a.AltFPs = append(a.AltFPs, FingerprintFromSamples(Resample(samples, f, n), o.Bits))
+	}
+	return a
+}
+
+// Resample 以 factor 倍速（线性插值）重采样，最多输出 n 个样本。
+// factor > 1 相当于加速播放：输出的前 n 个样本覆盖原始音频的 n*factor 个样本。
+func Resample(samples []int16, factor float64, n int) []int16 {
+	out := make([]int16, 0, n)
+	for i := 0; i < n; i++ {
+		pos := float64(i) * factor
+		j := int(pos)
+		if j+1 >= len(samples) {
+			break
+		}
+		frac := pos - float64(j)
+		out = append(out, int16(float64(samples[j])*(1-frac)+float64(samples[j+1])*frac))
+	}
+	return out
+}
+
+// Envelope 把样本分为 points 段，返回每段绝对值峰值（按 int16 满幅缩放到 0..255）
+func Envelope(samples []int16, points int) []uint8 {
+	env := make([]uint8, points)
+	if len(samples) == 0 || points <= 0 {
+		return env
+	}
+	for i := 0; i < points; i++ {
+		start := i * len(samples) / points
+		end := (i + 1) * len(samples) / points
+		peak := 0
+		for _, v := range samples[start:end] {
+			a := int(v)
+			if a < 0 {
+				a = -a
+			}
+			if a > peak {
+				peak = a
+			}
+		}
+		env[i] = uint8(peak * 255 / 32768)
+	}
+	return env
+}
+
+// FingerprintFromReader 与 FingerprintFromFile 相同，但音频数据从 r 读取，
+// 通过 stdin（-i pipe:0）送入 ffmpeg，适用于远程源 / 归档内文件等无本地路径的情况，无需写临时文件。
+// 注意：部分容器（如 moov 位于文件末尾的 MP4/M4A）无法从不可回溯的管道中解码。
+func FingerprintFromReader(r io.Reader, seconds int, bitsLen int) (uint64, error) {
+	if bitsLen <= 0 || bitsLen > 64 {
+		return 0, fmt.Errorf("bitsLen must be 1..64")
+	}
+	samples, err := decodePCM(context.Background(), "pipe:0", r, seconds, 0)
+	if err != nil {
+		return 0, err
+	}
+	return FingerprintFromSamples(samples, bitsLen), nil
+}
+
+// SampleRate 为解码输出的采样率（单声道 s16le）
+const SampleRate = 8000
+
+// DecodePCM 把文件前 seconds 秒解码为 8kHz 单声道样本；seconds <= 0 时解码整个文件
+func DecodePCM(path string, seconds int) ([]int16, error) {
+	return decodePCM(context.Background(), path, nil, seconds, 0)
+}
+
+// DecodeWindow 从 start 处开始解码 seconds 秒（seconds <= 0 表示到结尾），用于文件中段/尾段的指纹窗口。
+// 文件输入使用输入端精确定位，不必从头解码，长文件（DJ 混音、有声书）上也很快。
+func DecodeWindow(path string, start time.Duration, seconds int) ([]int16, error) {
+	return decodePCM(context.Background(), path, nil, seconds, start)
+}
+
+// decodePCM 把 input（文件路径或 pipe:0）的前 seconds 秒解码为 8kHz 单声道 int16 PCM：
+// 按当前后端（见 SetBackend）优先使用原生解码器，否则调用 ffmpeg。
+// stdin 非 nil 时作为输入流；skip > 0 时先丢弃开头这段音频。ctx 已取消时直接返回 ctx.Err()。
+func decodePCM(ctx context.Context, input string, stdin io.Reader, seconds int, skip time.Duration) ([]int16, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if b := currentBackend(); b != BackendFFmpeg {
+		samples, handled, rest, err := decodeNative(input, stdin, seconds, skip)
+		switch {
+		case handled && err == nil:
+			return samples, nil
+		case handled && (b == BackendNative || stdin != nil):
+			return nil, err // 管道数据已被读走，无法再交给 ffmpeg
+		case !handled && b == BackendNative:
+			return nil, fmt.Errorf("原生解码器不支持该格式（可用: %s）: %s", strings.Join(NativeFormats(), "、"), input)
+		case !handled:
+			stdin = rest
+		default:
+			// 文件输入的原生解码失败（少见的编码变体、轻微损坏）时交给更宽容的 ffmpeg 重试
+			if _, lerr := exec.LookPath("ffmpeg"); lerr != nil {
+				return nil, err
+			}
+		}
+	}
+	return decodeFFmpeg(ctx, input, stdin, seconds, skip)
+}
+
+// decodeFFmpeg 调用 ffmpeg 解码，参数见 decodeArgs；ctx 取消时终止 ffmpeg
+func decodeFFmpeg(ctx context.Context, input string, stdin io.Reader, seconds int, skip time.Duration) ([]int16, error) {
+	// 检查 ffmpeg 是否存在（仅第一次检查即可）；不存在时改用支持该格式的后备解码器
 	if _, err := exec.LookPath("ffmpeg"); err != nil {
-		return 0, 0, errors.New("ffmpeg 未找到，请先安装 ffmpeg 并确保其在 PATH 中")
+		if samples, ok, err := decodeFallback(input, stdin, seconds, skip); ok {
+			return samples, err
+		}
+		return nil, errors.New("ffmpeg 未找到，请先安装 ffmpeg 并确保其在 PATH 中")
 	}
 
-	// ffmpeg 参数：-t seconds 限定时长，-f s16le -ac 1 -ar 8000 输出为 PCM
-	args := []string{"-v", "error", "-i", path, "-f", "s16le", "-ac", "1", "-ar", "8000", "-t", fmt.Sprintf("%d", seconds), "-"}
-	cmd := exec.Command("ffmpeg", args...)
+	args := decodeArgs(input, stdin != nil, seconds, skip)
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
 	var out bytes.Buffer
 	cmd.Stdout = &out
 	// 把 stderr 合并到输出以便错误信息查看
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 
-	if err := cmd.Run(); err != nil {
+	release := quota.AcquireFFmpeg()
+	err := cmd.Run()
+	release()
+	if ctx.Err() != nil {
+		return nil, ctx.Err() // 被取消的 ffmpeg 的错误信息没有意义
+	}
+	if err != nil {
 		// 包括 ffmpeg 的 stderr 输出用于调试
 		msg := strings.TrimSpace(stderr.String())
 		if msg == "" {
 			msg = err.Error()
 		}
-		return 0, 0, fmt.Errorf("ffmpeg 解码失败: %s", msg)
+		return nil, fmt.Errorf("ffmpeg 解码失败: %s", msg)
+	}
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		// 解码成功但 ffmpeg 报告了错误（如个别损坏的帧），记入日志而不是丢弃
+		slog.Warn("ffmpeg 解码时报告错误", "input", input, "stderr", msg)
 	}
 
-	// 解析 s16le 数据为 int16 切片
+	// 解析 s16le 数据为 int16 切片（直接按字节解码，避免逐样本反射读取带来的额外分配）
 	raw := out.Bytes()
-	samples := make([]int16, 0, len(raw)/2)
-	reader := bytes.NewReader(raw)
-	for {
-		var s int16
-		if err := binary.Read(reader, binary.LittleEndian, &s); err != nil {
-			if err == io.EOF {
-				break
-			}
-			return 0, 0, fmt.Errorf("解析 PCM 数据失败: %v", err)
-		}
-		samples = append(samples, s)
+	samples := make([]int16, len(raw)/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(raw[2*i:]))
 	}
+	return samples, nil
+}
 
-	// 计算指纹
-	fp := FingerprintFromSamples(samples, bitsLen)
-
-	// 获取文件大小
-	info, err := exec.Command("stat", "-c", "%s", path).Output() // linux stat
-	if err != nil {
-		// 跨平台退回 go 的文件读取方式
-		fi, e2 := getFileSizeFallback(path)
-		if e2 != nil {
-			return fp, 0, nil // 返回 fingerprint，文件大小未知
-		}
-		return fp, fi, nil
+// decodeArgs 构造 ffmpeg 参数：-t seconds 限定时长（<=0 时解码整个文件），-f s16le -ac 1 -ar 8000 输出为 PCM。
+// skip 对文件输入放在 -i 之前（输入端 -ss 加 -accurate_seek：跳到附近的定位点后只解码到目标位置，结果按样本精确）；
+// 管道无法定位，只能放在输出端，由 ffmpeg 从头解码后丢弃。
+func decodeArgs(input string, piped bool, seconds int, skip time.Duration) []string {
+	args := []string{"-v", "error"}
+	ss := fmt.Sprintf("%.6f", skip.Seconds())
+	if skip > 0 && !piped {
+		args = append(args, "-accurate_seek", "-ss", ss)
 	}
-	var size int64
-	_, _ = fmt.Sscan(string(bytes.TrimSpace(info)), &size)
-	return fp, size, nil
+	args = append(args, "-i", input, "-f", "s16le", "-ac", "1", "-ar", "8000")
+	if skip > 0 && piped {
+		args = append(args, "-ss", ss)
+	}
+	if seconds > 0 {
+		args = append(args, "-t", fmt.Sprintf("%d", seconds))
+	}
+	return append(args, "-")
 }
 
 // getFileSizeFallback 使用标准库获得文件大小（跨平台备用）
@@ -184,8 +431,33 @@ func FingerprintFromSamples(samples []int16, bitsLen int) uint64 {
 	if len(samples) == 0 {
 		return 0
 	}
-	// 取样块数 = bitsLen
-	blockCount := bitsLen
+	averages := BlockAverages(samples, bitsLen)
+	blockCount := len(averages)
+
+	// 计算中位数作为阈值
+	tmp := make([]float64, len(averages))
+	copy(tmp, averages)
+	sort.Float64s(tmp)
+	var median float64
+	n := len(tmp)
+	if n%2 == 0 {
+		median = (tmp[n/2-1] + tmp[n/2]) / 2.0
+	} else {
+		median = tmp[n/2]
+	}
+
+	// 根据中位数生成位掩码（高位对应 averages[0]）
+	var mask uint64 = 0
+	for i := 0; i < blockCount; i++ {
+		if averages[i] > median {
+			mask |= (1 << uint(blockCount-1-i))
+		}
+	}
+	return mask
+}
+
+// BlockAverages 把样本均分为 blockCount 块，返回每块的平均绝对幅度（指纹量化前的向量）
+func BlockAverages(samples []int16, blockCount int) []float64 {
 	blockSize := (len(samples) + blockCount - 1) / blockCount
 
 	// 并行计算每块平均绝对值
@@ -222,30 +494,32 @@ func FingerprintFromSamples(samples []int16, bitsLen int) uint64 {
 		}(i)
 	}
 	wg.Wait()
-
-	// 计算中位数作为阈值
-	tmp := make([]float64, len(averages))
-	copy(tmp, averages)
-	sort.Float64s(tmp)
-	var median float64
-	n := len(tmp)
-	if n%2 == 0 {
-		median = (tmp[n/2-1] + tmp[n/2]) / 2.0
-	} else {
-		median = tmp[n/2]
-	}
-
-	// 根据中位数生成位掩码（高位对应 averages[0]）
-	var mask uint64 = 0
-	for i := 0; i < blockCount; i++ {
-		if averages[i] > median {
-			mask |= (1 << uint(blockCount-1-i))
-		}
-	}
-	return mask
+	return averages
 }
 
 // HammingDistance 计算两个 uint64 的汉明距离（用于指纹相似度判定）
 func HammingDistance(a, b uint64) int {
 	return bits.OnesCount64(a ^ b)
 }
+
+// ProbeDuration 读取音频时长（秒）：优先解析容器头（见 duration.Read），无法识别时才调用 ffprobe
+func ProbeDuration(path string) (float64, error) {
+	if d, err := duration.Read(path); err == nil {
+		return d.Seconds(), nil
+	}
+	if _, err := exec.LookPath("ffprobe"); err != nil {
+		return 0, errors.New("ffprobe 未找到，请先安装 ffmpeg（包含 ffprobe）并确保其在 PATH 中")
+	}
+	release := quota.AcquireFFmpeg()
+	out, err := exec.Command("ffprobe", "-v", "error", "-show_entries", "format=duration",
+		"-of", "default=noprint_wrappers=1:nokey=1", path).Output()
+	release()
+	if err != nil {
+		return 0, fmt.Errorf("ffprobe 读取时长失败: %v", err)
+	}
+	var d float64
+	if _, err := fmt.Sscan(strings.TrimSpace(string(out)), &d); err != nil {
+		return 0, fmt.Errorf("无法解析时长 %q", strings.TrimSpace(string(out)))
+	}
+	return d, nil
+}
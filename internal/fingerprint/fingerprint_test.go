@@ -5,7 +5,12 @@
 package fingerprint
 
 import (
+	"bytes"
+	"encoding/binary"
+	"os/exec"
+	"strings"
 	"testing"
+	"time"
 )
 
 func makeConstantSamples(val int16, n int) []int16 {
@@ -36,3 +41,88 @@ func TestHammingDistanceBasic(t *testing.T) {
 		t.Fatalf("期望汉明距离 2，实际 %d", dist)
 	}
 }
+
+func TestFingerprintFromReaderPipe(t *testing.T) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		t.Skip("未安装 ffmpeg")
+	}
+	// 构造 1 秒 8kHz 单声道 16-bit WAV：前半静音、后半方波
+	const rate = 8000
+	pcm := make([]byte, rate*2)
+	for i := rate / 2; i < rate; i++ {
+		v := int16(8000)
+		if (i/20)%2 == 0 {
+			v = -8000
+		}
+		binary.LittleEndian.PutUint16(pcm[2*i:], uint16(v))
+	}
+	var wav bytes.Buffer
+	wav.WriteString("RIFF")
+	_ = binary.Write(&wav, binary.LittleEndian, uint32(36+len(pcm)))
+	wav.WriteString("WAVEfmt ")
+	_ = binary.Write(&wav, binary.LittleEndian, []any{uint32(16), uint16(1), uint16(1), uint32(rate), uint32(rate * 2), uint16(2), uint16(16)})
+	wav.WriteString("data")
+	_ = binary.Write(&wav, binary.LittleEndian, uint32(len(pcm)))
+	wav.Write(pcm)
+
+	fp, err := FingerprintFromReader(bytes.NewReader(wav.Bytes()), 8, 64)
+	if err != nil {
+		t.Fatalf("FingerprintFromReader 错误: %v", err)
+	}
+	// 后半段响度高于中位数 -> 低位应有置位、高位应为 0
+	if fp == 0 || fp>>63 != 0 {
+		t.Fatalf("指纹不符合预期: %064b", fp)
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	s := make([]int16, 1000)
+	for i := 500; i < 1000; i++ {
+		s[i] = -32768
+	}
+	env := Envelope(s, 4)
+	if env[0] != 0 || env[1] != 0 || env[2] != 255 || env[3] != 255 {
+		t.Fatalf("包络不正确: %v", env)
+	}
+}
+
+func TestSpeedVariantFingerprint(t *testing.T) {
+	// 8+ 秒的"音乐"：每 50ms 随机一个音量
+	orig := make([]int16, 10*SampleRate)
+	vol := int16(0)
+	seed := uint32(7)
+	for i := range orig {
+		if i%400 == 0 {
+			seed = seed*1664525 + 1013904223
+			vol = int16(seed >> 18)
+		}
+		if i%2 == 0 {
+			orig[i] = vol
+		} else {
+			orig[i] = -vol
+		}
+	}
+	o := Options{Seconds: 8, Bits: 64, SpeedFactors: []float64{0.97, 1.03}}
+	fast := Resample(orig, 1.03, len(orig)) // 快 3% 的版本
+	a := analyze(orig, o)
+	b := analyze(fast, Options{Seconds: 8, Bits: 64})
+	direct := HammingDistance(a.FP, b.FP)
+	alt := HammingDistance(a.AltFPs[1], b.FP)
+	if alt != 0 || direct <= alt {
+		t.Fatalf("变速指纹应与加速版本一致：直接距离 %d，变速距离 %d", direct, alt)
+	}
+}
+
+func TestDecodeArgsSeek(t *testing.T) {
+	file := strings.Join(decodeArgs("a.flac", false, 8, 90*time.Second), " ")
+	if !strings.HasPrefix(file, "-v error -accurate_seek -ss 90.000000 -i a.flac") {
+		t.Errorf("文件输入应在 -i 之前定位: %s", file)
+	}
+	pipe := strings.Join(decodeArgs("pipe:0", true, 8, time.Second), " ")
+	if !strings.Contains(pipe, "-ar 8000 -ss 1.000000 -t 8") {
+		t.Errorf("管道输入应在输出端定位: %s", pipe)
+	}
+	if whole := strings.Join(decodeArgs("a.mp3", false, 0, 0), " "); strings.Contains(whole, "-ss") || strings.Contains(whole, "-t") {
+		t.Errorf("不应包含定位/时长参数: %s", whole)
+	}
+}
@@ -0,0 +1,511 @@
+// file: internal/fingerprint/flac.go
+// package: fingerprint
+//
+// FLAC 原生解码：解析 STREAMINFO 后逐帧解码（常量 / 原样 / 固定预测 / LPC 子帧，
+// Rice 残差，左-侧 / 侧-右 / 中-侧声道去相关），下混为单声道。
+// 不校验帧 CRC（只用于计算指纹）；采样位数超过 24 位的流交给 ffmpeg。
+package fingerprint
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+// flacBitReader 为按位读取的大端位流
+type flacBitReader struct {
+	r   io.ByteReader
+	buf uint64
+	n   uint // buf 低 n 位为未读数据
+}
+
+func (b *flacBitReader) fill() error {
+	c, err := b.r.ReadByte()
+	if err != nil {
+		return err
+	}
+	b.buf = b.buf<<8 | uint64(c)
+	b.n += 8
+	return nil
+}
+
+// read 读取 n（<= 56）位无符号数
+func (b *flacBitReader) read(n uint) (uint64, error) {
+	for b.n < n {
+		if err := b.fill(); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+	}
+	b.n -= n
+	return b.buf >> b.n & (1<<n - 1), nil
+}
+
+// signed 读取 n 位有符号数（二进制补码）
+func (b *flacBitReader) signed(n uint) (int64, error) {
+	v, err := b.read(n)
+	if err != nil || n == 0 {
+		return 0, err
+	}
+	if v&(1<<(n-1)) != 0 {
+		return int64(v) - 1<<n, nil
+	}
+	return int64(v), nil
+}
+
+// unary 读取一元码：返回 1 之前的 0 的个数
+func (b *flacBitReader) unary() (uint64, error) {
+	var count uint64
+	for {
+		if b.n == 0 {
+			if err := b.fill(); err != nil {
+				if err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				return 0, err
+			}
+		}
+		x := b.buf & (1<<b.n - 1)
+		if x == 0 {
+			count += uint64(b.n)
+			b.n = 0
+			continue
+		}
+		l := uint(bits.Len64(x))
+		count += uint64(b.n - l)
+		b.n = l - 1
+		return count, nil
+	}
+}
+
+// align 丢弃当前字节中剩余的位
+func (b *flacBitReader) align() { b.n -= b.n % 8 }
+
+type flacStreamInfo struct {
+	rate, channels, bps int
+}
+
+// decodeFLAC 实现 NativeDecoder
+func decodeFLAC(r io.Reader, emit func(rate int, mono []int16) bool) error {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return err
+	}
+	if string(magic) != "fLaC" {
+		return fmt.Errorf("不是 FLAC 文件")
+	}
+	var info flacStreamInfo
+	hdr := make([]byte, 4)
+	for last := false; !last; {
+		if _, err := io.ReadFull(br, hdr); err != nil {
+			return err
+		}
+		last = hdr[0]&0x80 != 0
+		size := int64(hdr[1])<<16 | int64(hdr[2])<<8 | int64(hdr[3])
+		if hdr[0]&0x7f == 0 && size >= 18 {
+			b := make([]byte, size)
+			if _, err := io.ReadFull(br, b); err != nil {
+				return err
+			}
+			info.rate = int(b[10])<<12 | int(b[11])<<4 | int(b[12])>>4
+			info.channels = int(b[12]>>1&0x7) + 1
+			info.bps = (int(b[12]&1)<<4 | int(b[13])>>4) + 1
+			continue
+		}
+		if _, err := io.CopyN(io.Discard, br, size); err != nil {
+			return err
+		}
+	}
+
+	bitr := &flacBitReader{r: br}
+	var chans [][]int32
+	var mono []int16
+	for {
+		rate, bps, blockSize, assign, err := readFLACFrameHeader(bitr, info)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		nch := assign + 1
+		if assign >= 8 {
+			nch = 2
+		}
+		if bps > 24 || rate <= 0 {
+			return errNativeUnsupported
+		}
+		for len(chans) < nch {
+			chans = append(chans, nil)
+		}
+		for c := 0; c < nch; c++ {
+			if cap(chans[c]) < blockSize {
+				chans[c] = make([]int32, blockSize)
+			}
+			chans[c] = chans[c][:blockSize]
+			sbps := bps
+			// 侧声道多 1 位
+			if (assign == 8 && c == 1) || (assign == 9 && c == 0) || (assign == 10 && c == 1) {
+				sbps++
+			}
+			if err := decodeFLACSubframe(bitr, chans[c], uint(sbps)); err != nil {
+				return truncatedOK(err)
+			}
+		}
+		bitr.align()
+		if _, err := bitr.read(16); err != nil { // 帧 CRC-16
+			return truncatedOK(err)
+		}
+
+		switch assign {
+		case 8: // 左 / 侧
+			for i := range chans[0][:blockSize] {
+				chans[1][i] = chans[0][i] - chans[1][i]
+			}
+		case 9: // 侧 / 右
+			for i := range chans[0][:blockSize] {
+				chans[0][i] += chans[1][i]
+			}
+		case 10: // 中 / 侧
+			for i := range chans[0][:blockSize] {
+				mid := chans[0][i]<<1 | chans[1][i]&1
+				side := chans[1][i]
+				chans[0][i], chans[1][i] = (mid+side)>>1, (mid-side)>>1
+			}
+		}
+
+		if cap(mono) < blockSize {
+			mono = make([]int16, blockSize)
+		}
+		mono = mono[:blockSize]
+		for i := range mono {
+			var sum int64
+			for c := 0; c < nch; c++ {
+				sum += int64(chans[c][i])
+			}
+			v := sum / int64(nch)
+			if bps > 16 {
+				v >>= uint(bps - 16)
+			} else {
+				v <<= uint(16 - bps)
+			}
+			mono[i] = int16(v)
+		}
+		if !emit(rate, mono) {
+			return nil
+		}
+	}
+}
+
+// truncatedOK 把文件末尾截断视为正常结束（已解码的帧仍然有效）
+func truncatedOK(err error) error {
+	if err == io.ErrUnexpectedEOF {
+		return nil
+	}
+	return err
+}
+
+var flacRates = [...]int{0, 88200, 176400, 192000, 8000, 16000, 22050, 24000, 32000, 44100, 48000, 96000}
+var flacSampleSizes = [...]int{0, 8, 12, 0, 16, 20, 24, 32}
+
+// readFLACFrameHeader 读取帧头；流正常结束时返回 io.EOF。遇到非同步码的数据时向后搜索下一个帧。
+func readFLACFrameHeader(b *flacBitReader, info flacStreamInfo) (rate, bps, blockSize, assign int, err error) {
+	b.align()
+	for {
+		v, err := b.read(8)
+		if err == io.ErrUnexpectedEOF {
+			return 0, 0, 0, 0, io.EOF
+		}
+		if err != nil {
+			return 0, 0, 0, 0, err
+		}
+		if v != 0xFF {
+			continue
+		}
+		v, err = b.read(8)
+		if err != nil {
+			return 0, 0, 0, 0, truncatedEOF(err)
+		}
+		if v&0xFE == 0xF8 {
+			break
+		}
+	}
+	h, err := b.read(16)
+	if err != nil {
+		return 0, 0, 0, 0, truncatedEOF(err)
+	}
+	bsCode, rateCode := int(h>>12), int(h>>8&0xF)
+	assign, sizeCode := int(h>>4&0xF), int(h>>1&0x7)
+	if assign > 10 {
+		return 0, 0, 0, 0, fmt.Errorf("无效的声道分配 %d", assign)
+	}
+
+	// 帧号 / 样本号（类 UTF-8 变长编码），只需跳过
+	first, err := b.read(8)
+	if err != nil {
+		return 0, 0, 0, 0, truncatedEOF(err)
+	}
+	extra := bits.LeadingZeros8(^uint8(first))
+	if extra > 1 {
+		if _, err := b.read(uint(8 * (extra - 1))); err != nil {
+			return 0, 0, 0, 0, truncatedEOF(err)
+		}
+	}
+
+	switch {
+	case bsCode == 1:
+		blockSize = 192
+	case bsCode >= 2 && bsCode <= 5:
+		blockSize = 576 << uint(bsCode-2)
+	case bsCode == 6:
+		v, err := b.read(8)
+		if err != nil {
+			return 0, 0, 0, 0, truncatedEOF(err)
+		}
+		blockSize = int(v) + 1
+	case bsCode == 7:
+		v, err := b.read(16)
+		if err != nil {
+			return 0, 0, 0, 0, truncatedEOF(err)
+		}
+		blockSize = int(v) + 1
+	case bsCode >= 8:
+		blockSize = 256 << uint(bsCode-8)
+	default:
+		return 0, 0, 0, 0, fmt.Errorf("无效的块大小编码")
+	}
+
+	switch {
+	case rateCode == 0:
+		rate = info.rate
+	case rateCode < len(flacRates):
+		rate = flacRates[rateCode]
+	case rateCode == 12:
+		v, err := b.read(8)
+		if err != nil {
+			return 0, 0, 0, 0, truncatedEOF(err)
+		}
+		rate = int(v) * 1000
+	case rateCode == 13 || rateCode == 14:
+		v, err := b.read(16)
+		if err != nil {
+			return 0, 0, 0, 0, truncatedEOF(err)
+		}
+		rate = int(v)
+		if rateCode == 14 {
+			rate *= 10
+		}
+	default:
+		return 0, 0, 0, 0, fmt.Errorf("无效的采样率编码")
+	}
+
+	bps = flacSampleSizes[sizeCode]
+	if sizeCode == 0 {
+		bps = info.bps
+	}
+	if bps == 0 {
+		return 0, 0, 0, 0, fmt.Errorf("无效的采样位数编码")
+	}
+	if _, err := b.read(8); err != nil { // 帧头 CRC-8
+		return 0, 0, 0, 0, truncatedEOF(err)
+	}
+	return rate, bps, blockSize, assign, nil
+}
+
+// truncatedEOF 帧头读到一半文件结束时视为流结束
+func truncatedEOF(err error) error {
+	if err == io.ErrUnexpectedEOF {
+		return io.EOF
+	}
+	return err
+}
+
+// decodeFLACSubframe 把一个子帧解码到 out（长度为块大小）
+func decodeFLACSubframe(b *flacBitReader, out []int32, bps uint) error {
+	h, err := b.read(8)
+	if err != nil {
+		return err
+	}
+	typ := int(h >> 1 & 0x3F)
+	var wasted uint
+	if h&1 != 0 {
+		k, err := b.unary()
+		if err != nil {
+			return err
+		}
+		wasted = uint(k) + 1
+		if wasted >= bps {
+			return fmt.Errorf("无效的空位数")
+		}
+		bps -= wasted
+	}
+
+	switch {
+	case typ == 0: // 常量
+		v, err := b.signed(bps)
+		if err != nil {
+			return err
+		}
+		for i := range out {
+			out[i] = int32(v)
+		}
+	case typ == 1: // 原样
+		for i := range out {
+			v, err := b.signed(bps)
+			if err != nil {
+				return err
+			}
+			out[i] = int32(v)
+		}
+	case typ >= 8 && typ <= 12: // 固定预测
+		order := typ - 8
+		if err := readWarmup(b, out, order, bps); err != nil {
+			return err
+		}
+		if err := readResidual(b, out, order); err != nil {
+			return err
+		}
+		restoreFixed(out, order)
+	case typ >= 32: // LPC
+		order := typ - 31
+		if err := readWarmup(b, out, order, bps); err != nil {
+			return err
+		}
+		p, err := b.read(4)
+		if err != nil {
+			return err
+		}
+		if p == 15 {
+			return fmt.Errorf("无效的 LPC 精度")
+		}
+		precision := uint(p) + 1
+		shift, err := b.signed(5)
+		if err != nil {
+			return err
+		}
+		if shift < 0 {
+			return fmt.Errorf("无效的 LPC 移位 %d", shift)
+		}
+		coefs := make([]int64, order)
+		for i := range coefs {
+			if coefs[i], err = b.signed(precision); err != nil {
+				return err
+			}
+		}
+		if err := readResidual(b, out, order); err != nil {
+			return err
+		}
+		for i := order; i < len(out); i++ {
+			var sum int64
+			for j, c := range coefs {
+				sum += c * int64(out[i-1-j])
+			}
+			out[i] += int32(sum >> uint(shift))
+		}
+	default:
+		return fmt.Errorf("保留的子帧类型 %d", typ)
+	}
+
+	if wasted > 0 {
+		for i := range out {
+			out[i] <<= wasted
+		}
+	}
+	return nil
+}
+
+func readWarmup(b *flacBitReader, out []int32, order int, bps uint) error {
+	if order > len(out) {
+		return fmt.Errorf("预测阶数 %d 大于块大小", order)
+	}
+	for i := 0; i < order; i++ {
+		v, err := b.signed(bps)
+		if err != nil {
+			return err
+		}
+		out[i] = int32(v)
+	}
+	return nil
+}
+
+// readResidual 读取 Rice 编码的残差到 out[order:]
+func readResidual(b *flacBitReader, out []int32, order int) error {
+	method, err := b.read(2)
+	if err != nil {
+		return err
+	}
+	paramBits, escape := uint(4), uint64(15)
+	switch method {
+	case 0:
+	case 1:
+		paramBits, escape = 5, 31
+	default:
+		return fmt.Errorf("保留的残差编码方式 %d", method)
+	}
+	po, err := b.read(4)
+	if err != nil {
+		return err
+	}
+	parts := 1 << po
+	if len(out)%parts != 0 || len(out)/parts < order {
+		return fmt.Errorf("无效的分区阶数 %d", po)
+	}
+	i := order
+	for p := 0; p < parts; p++ {
+		end := (p + 1) * len(out) / parts
+		k, err := b.read(paramBits)
+		if err != nil {
+			return err
+		}
+		if k == escape {
+			n, err := b.read(5)
+			if err != nil {
+				return err
+			}
+			for ; i < end; i++ {
+				v, err := b.signed(uint(n))
+				if err != nil {
+					return err
+				}
+				out[i] = int32(v)
+			}
+			continue
+		}
+		for ; i < end; i++ {
+			q, err := b.unary()
+			if err != nil {
+				return err
+			}
+			low, err := b.read(uint(k))
+			if err != nil {
+				return err
+			}
+			v := q<<k | low
+			out[i] = int32(v>>1) ^ -int32(v&1)
+		}
+	}
+	return nil
+}
+
+// restoreFixed 按固定预测器（阶数 0..4）从残差恢复样本
+func restoreFixed(out []int32, order int) {
+	for i := order; i < len(out); i++ {
+		switch order {
+		case 1:
+			out[i] += out[i-1]
+		case 2:
+			out[i] += 2*out[i-1] - out[i-2]
+		case 3:
+			out[i] += 3*out[i-1] - 3*out[i-2] + out[i-3]
+		case 4:
+			out[i] += 4*out[i-1] - 6*out[i-2] + 4*out[i-3] - out[i-4]
+		}
+	}
+}
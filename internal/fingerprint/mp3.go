@@ -0,0 +1,942 @@
+// file: internal/fingerprint/mp3.go
+// package: fingerprint
+//
+// MP3（MPEG-1 / 2 / 2.5 Layer III）纯 Go 解码：作为内置后备解码器，找不到 ffmpeg 时解码 MP3。
+// 完整实现 Huffman 解码、反量化、中-侧 / 强度立体声、短块重排、抗混叠、IMDCT 与合成滤波器组；
+// 各声道在合成前相加，只做一次单声道合成。跳过 ID3v2 标签与 Xing / Info / VBRI 帧，
+// 并按 LAME 标签去掉编码器延迟（与 ffmpeg 一致）。
+// 只用于计算指纹：不校验 CRC，不支持自由比特率；主数据缺失或损坏的帧输出静音。
+package fingerprint
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
+func init() {
+	builtinFallbacks = append(builtinFallbacks, FallbackDecoder{Name: "纯 Go MP3", Formats: []string{"mp3"},
+		Decode: func(r io.Reader, seconds int, skip time.Duration) ([]int16, error) {
+			return collectNative(decodeMP3, r, seconds, skip)
+		}})
+}
+
+// mp3Header 为解析后的帧头
+type mp3Header struct {
+	lsf     bool // MPEG-2 / 2.5：每帧一个颗粒，缩放因子编码不同
+	rateIdx int  // 采样率序号（见 mp3LongBands）
+	rate    int
+	crc     bool
+	mode    int // 0 立体声，1 联合立体声，2 双声道，3 单声道
+	modeExt int
+	size    int // 整帧字节数（含帧头）
+}
+
+var (
+	mp3Bitrates = [2][15]int{
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	}
+	mp3Rates = [9]int{44100, 48000, 32000, 22050, 24000, 16000, 11025, 12000, 8000}
+)
+
+// parseMP3Header 解析 4 字节帧头；不是 Layer III 帧头（或为自由比特率）时 ok 为 false
+func parseMP3Header(b []byte) (h mp3Header, ok bool) {
+	if b[0] != 0xFF || b[1]&0xE0 != 0xE0 {
+		return h, false
+	}
+	version := int(b[1]>>3) & 3 // 0 MPEG-2.5，1 保留，2 MPEG-2，3 MPEG-1
+	layer := int(b[1]>>1) & 3
+	bitrate := int(b[2] >> 4)
+	rate := int(b[2]>>2) & 3
+	if version == 1 || layer != 1 || bitrate == 0 || bitrate == 15 || rate == 3 {
+		return h, false
+	}
+	h.lsf = version != 3
+	h.rateIdx = rate + [4]int{6, 0, 3, 0}[version]
+	h.rate = mp3Rates[h.rateIdx]
+	h.crc = b[1]&1 == 0
+	h.mode = int(b[3] >> 6)
+	h.modeExt = int(b[3]>>4) & 3
+	pad := int(b[2]>>1) & 1
+	if h.lsf {
+		h.size = 72000*mp3Bitrates[1][bitrate]/h.rate + pad
+	} else {
+		h.size = 144000*mp3Bitrates[0][bitrate]/h.rate + pad
+	}
+	return h, true
+}
+
+func (h mp3Header) channels() int {
+	if h.mode == 3 {
+		return 1
+	}
+	return 2
+}
+
+func (h mp3Header) granules() int {
+	if h.lsf {
+		return 1
+	}
+	return 2
+}
+
+// sideInfoLen 返回边信息的字节数
+func (h mp3Header) sideInfoLen() int {
+	switch {
+	case h.lsf && h.mode == 3:
+		return 9
+	case h.lsf:
+		return 17
+	case h.mode == 3:
+		return 17
+	}
+	return 32
+}
+
+// mp3Granule 为一个颗粒中一个声道的边信息
+type mp3Granule struct {
+	part23     int // 缩放因子与 Huffman 数据的总位数
+	bigValues  int
+	globalGain int
+	sfCompress int
+	blockType  int // 0 普通，1 起始，2 短块，3 结束
+	mixed      bool
+	table      [3]int
+	subGain    [3]int
+	region0    int
+	region1    int
+	preflag    int
+	sfScale    int
+	count1     int
+}
+
+type mp3SideInfo struct {
+	mainBegin int // 主数据从本帧之前多少字节开始（比特储备）
+	scfsi     [2][4]int
+	gr        [2][2]mp3Granule
+}
+
+// mp3Bits 为按位读取的大端位流；读过结尾时返回 0
+type mp3Bits struct {
+	data []byte
+	pos  int
+}
+
+func (b *mp3Bits) read(n int) int {
+	v := 0
+	for ; n > 0; n-- {
+		v <<= 1
+		if i := b.pos >> 3; i < len(b.data) {
+			v |= int(b.data[i]>>(7-uint(b.pos&7))) & 1
+		}
+		b.pos++
+	}
+	return v
+}
+
+func parseMP3SideInfo(h mp3Header, b []byte) mp3SideInfo {
+	var s mp3SideInfo
+	r := &mp3Bits{data: b}
+	nch := h.channels()
+	if h.lsf {
+		s.mainBegin = r.read(8)
+		r.read(nch) // 私有位
+	} else {
+		s.mainBegin = r.read(9)
+		r.read(7 - 2*nch) // 私有位
+		for ch := 0; ch < nch; ch++ {
+			for i := range s.scfsi[ch] {
+				s.scfsi[ch][i] = r.read(1)
+			}
+		}
+	}
+	for gr := 0; gr < h.granules(); gr++ {
+		for ch := 0; ch < nch; ch++ {
+			g := &s.gr[gr][ch]
+			g.part23 = r.read(12)
+			g.bigValues = r.read(9)
+			g.globalGain = r.read(8)
+			if h.lsf {
+				g.sfCompress = r.read(9)
+			} else {
+				g.sfCompress = r.read(4)
+			}
+			if r.read(1) == 1 { // 窗口切换
+				g.blockType = r.read(2)
+				g.mixed = r.read(1) == 1
+				g.table[0], g.table[1] = r.read(5), r.read(5)
+				for w := range g.subGain {
+					g.subGain[w] = r.read(3)
+				}
+				g.region0, g.region1 = 7, 36
+				if g.blockType == 2 && !g.mixed {
+					g.region0 = 8
+				}
+			} else {
+				for i := range g.table {
+					g.table[i] = r.read(5)
+				}
+				g.region0, g.region1 = r.read(4), r.read(3)
+			}
+			if !h.lsf {
+				g.preflag = r.read(1)
+			}
+			g.sfScale = r.read(1)
+			g.count1 = r.read(1)
+		}
+	}
+	return s
+}
+
+// mp3Bands 返回颗粒按存储顺序排列的频带宽度（短块的每个频带按 3 个窗口各出现一次）
+// 以及其中按长块处理的频带数；缩放因子与强度立体声位置都按这一顺序存放
+func mp3Bands(h mp3Header, g *mp3Granule) (widths []int, nLong int) {
+	long, short := &mp3LongBands[h.rateIdx], &mp3ShortBands[h.rateIdx]
+	if g.blockType != 2 {
+		for i := 0; i < 22; i++ {
+			widths = append(widths, long[i+1]-long[i])
+		}
+		return widths, 22
+	}
+	b := 0
+	if g.mixed {
+		// 混合块：最低的 36 条谱线（2 个子带）按长块处理
+		for i := 0; long[i] < 36; i++ {
+			widths = append(widths, long[i+1]-long[i])
+		}
+		for short[b]*3 < 36 {
+			b++
+		}
+		if rest := short[b]*3 - 36; rest > 0 { // 8kHz：36 条谱线落在短块频带中间
+			widths = append(widths, rest/3, rest/3, rest/3)
+		}
+		nLong = 8
+		if h.lsf {
+			nLong = 6
+		}
+	}
+	for ; b < 13; b++ {
+		w := short[b+1] - short[b]
+		widths = append(widths, w, w, w)
+	}
+	return widths, nLong
+}
+
+var (
+	mp3Slen   = [16][2]int{{0, 0}, {0, 1}, {0, 2}, {0, 3}, {3, 0}, {1, 1}, {1, 2}, {1, 3}, {2, 1}, {2, 2}, {2, 3}, {3, 1}, {3, 2}, {3, 3}, {4, 2}, {4, 3}}
+	mp3Pretab = [22]int{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 3, 3, 3, 2, 0}
+	// mp3LSFCounts 为 MPEG-2 各缩放因子分组的个数：[编码方式][长块、短块、混合块][分组]
+	mp3LSFCounts = [6][3][4]int{
+		{{6, 5, 5, 5}, {9, 9, 9, 9}, {6, 9, 9, 9}},
+		{{6, 5, 7, 3}, {9, 9, 12, 6}, {6, 9, 12, 6}},
+		{{11, 10, 0, 0}, {18, 18, 0, 0}, {15, 18, 0, 0}},
+		{{7, 7, 7, 0}, {12, 12, 12, 0}, {6, 15, 12, 0}},
+		{{6, 6, 6, 3}, {12, 9, 9, 6}, {6, 12, 9, 6}},
+		{{8, 8, 5, 0}, {15, 12, 9, 0}, {6, 18, 9, 0}},
+	}
+)
+
+// mp3BadPos 标记 MPEG-2 强度立体声的非法位置（该频带按普通 / 中-侧立体声处理）
+const mp3BadPos = 64
+
+// mp3Decoder 保存跨帧的解码状态
+type mp3Decoder struct {
+	r       *bufio.Reader
+	synced  bool
+	res     []byte // 比特储备：之前各帧的主数据
+	scf     [2][39]int
+	ist     [39]int // 右声道的强度立体声位置
+	is      [576]int
+	xr      [2][576]float64
+	overlap [2][32][18]float64
+	sub     [2][18][32]float64
+	v       [1024]float64
+}
+
+// mp3MaxReservoir 为保留的比特储备字节数（main_data_begin 最大为 511）
+const mp3MaxReservoir = 4096
+
+var mp3Once sync.Once
+
+// decodeMP3 实现 NativeDecoder
+func decodeMP3(r io.Reader, emit func(rate int, mono []int16) bool) error {
+	mp3Once.Do(initMP3)
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReaderSize(r, 64<<10)
+	}
+	d := &mp3Decoder{r: br}
+	skip := 0
+	for first := true; ; first = false {
+		h, frame, err := d.nextFrame()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if first {
+			if delay, ok := mp3InfoFrame(h, frame); ok {
+				skip = delay
+				continue
+			}
+		}
+		mono := d.decodeFrame(h, frame)
+		if skip > 0 {
+			n := min(skip, len(mono))
+			mono, skip = mono[n:], skip-n
+			if len(mono) == 0 {
+				continue
+			}
+		}
+		if !emit(h.rate, mono) {
+			return nil
+		}
+	}
+}
+
+// nextFrame 读取下一帧；跳过 ID3v2 标签与帧之间无法识别的数据，流结束时返回 io.EOF
+func (d *mp3Decoder) nextFrame() (mp3Header, []byte, error) {
+	for {
+		b, err := d.r.Peek(4)
+		if err != nil {
+			return mp3Header{}, nil, peekEOF(err)
+		}
+		if string(b[:3]) == "ID3" {
+			tag, err := d.r.Peek(10)
+			if err != nil {
+				return mp3Header{}, nil, peekEOF(err)
+			}
+			size := int(tag[6]&0x7f)<<21 | int(tag[7]&0x7f)<<14 | int(tag[8]&0x7f)<<7 | int(tag[9]&0x7f) + 10
+			if tag[5]&0x10 != 0 {
+				size += 10 // 标签尾
+			}
+			if _, err := d.r.Discard(size); err != nil {
+				return mp3Header{}, nil, peekEOF(err)
+			}
+			continue
+		}
+		h, ok := parseMP3Header(b)
+		if ok && !d.synced {
+			// 失步时要求紧随其后的也是同类帧头，避免把数据中的伪同步字当成帧
+			if next, err := d.r.Peek(h.size + 4); err == nil {
+				nh, nok := parseMP3Header(next[h.size:])
+				ok = nok && nh.lsf == h.lsf && nh.rate == h.rate
+			}
+		}
+		if !ok {
+			d.synced = false
+			if _, err := d.r.Discard(1); err != nil {
+				return mp3Header{}, nil, peekEOF(err)
+			}
+			continue
+		}
+		frame := make([]byte, h.size)
+		if _, err := io.ReadFull(d.r, frame); err != nil {
+			return mp3Header{}, nil, peekEOF(err) // 最后一帧被截断
+		}
+		d.synced = true
+		return h, frame, nil
+	}
+}
+
+// peekEOF 把读到文件末尾（含截断）统一为 io.EOF，其它读取错误原样返回
+func peekEOF(err error) error {
+	if err == io.ErrUnexpectedEOF {
+		return io.EOF
+	}
+	return err
+}
+
+// mp3InfoFrame 判断首帧是否为 Xing / Info / VBRI 信息帧（不含音频，应跳过）；
+// 带 LAME 标签时同时返回应去掉的开头样本数（编码器延迟 + 解码器延迟 529，与 ffmpeg 一致）
+func mp3InfoFrame(h mp3Header, frame []byte) (delay int, ok bool) {
+	if len(frame) >= 40 && string(frame[36:40]) == "VBRI" {
+		return 0, true
+	}
+	off := 4 + h.sideInfoLen()
+	if h.crc {
+		off += 2
+	}
+	if len(frame) < off+8 || (string(frame[off:off+4]) != "Xing" && string(frame[off:off+4]) != "Info") {
+		return 0, false
+	}
+	flags := frame[off+7]
+	lame := off + 8
+	for bit, n := range [4]int{4, 4, 100, 4} { // 帧数、字节数、TOC、质量
+		if flags&(1<<uint(bit)) != 0 {
+			lame += n
+		}
+	}
+	if len(frame) < lame+24 {
+		return 0, true
+	}
+	switch string(frame[lame : lame+4]) {
+	case "LAME", "Lavf", "Lavc":
+		return (int(frame[lame+21])<<4 | int(frame[lame+22])>>4) + 529, true
+	}
+	return 0, true
+}
+
+// decodeFrame 解码一帧，返回单声道样本（每颗粒 576 个）
+func (d *mp3Decoder) decodeFrame(h mp3Header, frame []byte) []int16 {
+	ngr, nch := h.granules(), h.channels()
+	out := make([]int16, 576*ngr)
+	side := frame[4:]
+	if h.crc {
+		side = side[min(2, len(side)):]
+	}
+	if len(side) < h.sideInfoLen() {
+		return out
+	}
+	s := parseMP3SideInfo(h, side)
+	main := side[h.sideInfoLen():]
+
+	// 主数据可能从之前帧的数据开始；比特储备不足（如从流中间开始）时本帧输出静音
+	data := append(d.res[:len(d.res):len(d.res)], main...)
+	start := len(d.res) - s.mainBegin
+	if len(data) > mp3MaxReservoir {
+		d.res = append(d.res[:0], data[len(data)-mp3MaxReservoir:]...)
+	} else {
+		d.res = data
+	}
+	if start < 0 {
+		return out
+	}
+
+	bits := &mp3Bits{data: data, pos: start * 8}
+	for gr := 0; gr < ngr; gr++ {
+		var widths [2][]int
+		var nLong [2]int
+		for ch := 0; ch < nch; ch++ {
+			g := &s.gr[gr][ch]
+			widths[ch], nLong[ch] = mp3Bands(h, g)
+			end := bits.pos + g.part23
+			if end > len(data)*8 {
+				return out
+			}
+			d.readScalefactors(h, &s, gr, ch, bits, widths[ch])
+			if !d.readHuffman(bits, g, widths[ch], end) {
+				d.is = [576]int{}
+			}
+			bits.pos = end
+			d.requantize(g, widths[ch], nLong[ch], &d.scf[ch], &d.xr[ch])
+		}
+		if nch == 2 && h.mode == 1 {
+			d.stereo(h, &s.gr[gr][1], widths[1], nLong[1])
+		}
+		for ch := 0; ch < nch; ch++ {
+			g := &s.gr[gr][ch]
+			if g.blockType == 2 {
+				mp3Reorder(&d.xr[ch], widths[ch], nLong[ch])
+			}
+			mp3Antialias(&d.xr[ch], g)
+			d.hybrid(ch, g)
+		}
+		if nch == 2 {
+			for t := range d.sub[0] {
+				for sb := range d.sub[0][t] {
+					d.sub[0][t][sb] = (d.sub[0][t][sb] + d.sub[1][t][sb]) / 2
+				}
+			}
+		}
+		d.synth(out[576*gr : 576*(gr+1)])
+	}
+	return out
+}
+
+// readScalefactors 读取声道 ch 的缩放因子到 d.scf[ch]（与 mp3Bands 的频带一一对应）；
+// 右声道同时记下强度立体声位置
+func (d *mp3Decoder) readScalefactors(h mp3Header, s *mp3SideInfo, gr, ch int, b *mp3Bits, widths []int) {
+	g := &s.gr[gr][ch]
+	scf := &d.scf[ch]
+	n := len(widths) - 3 // 最高的频带没有缩放因子
+	if g.blockType != 2 {
+		n = 21
+	}
+	if !h.lsf {
+		s1, s2 := mp3Slen[g.sfCompress][0], mp3Slen[g.sfCompress][1]
+		if g.blockType == 2 {
+			n1 := 18 // 短块频带 0-5
+			if g.mixed {
+				n1 = 17 // 长块频带 0-7 与短块频带 3-5
+			}
+			for i := 0; i < n; i++ {
+				if i < n1 {
+					scf[i] = b.read(s1)
+				} else {
+					scf[i] = b.read(s2)
+				}
+			}
+		} else {
+			groups := [5]int{0, 6, 11, 16, 21}
+			for k := 0; k < 4; k++ {
+				if gr == 1 && s.scfsi[ch][k] == 1 {
+					continue // 沿用第一个颗粒的缩放因子
+				}
+				bits := s1
+				if k >= 2 {
+					bits = s2
+				}
+				for i := groups[k]; i < groups[k+1]; i++ {
+					scf[i] = b.read(bits)
+				}
+			}
+		}
+		for i := n; i < len(scf); i++ {
+			scf[i] = 0
+		}
+		if ch == 1 {
+			d.ist = *scf
+		}
+		return
+	}
+
+	sfc := g.sfCompress
+	var slen [4]int
+	var tbl int
+	if ch == 1 && h.mode == 1 && h.modeExt&1 != 0 { // 强度立体声的右声道
+		sfc >>= 1
+		switch {
+		case sfc < 180:
+			slen, tbl = [4]int{sfc / 36, sfc % 36 / 6, sfc % 6, 0}, 3
+		case sfc < 244:
+			sfc -= 180
+			slen, tbl = [4]int{sfc & 63 >> 4, sfc & 15 >> 2, sfc & 3, 0}, 4
+		default:
+			sfc -= 244
+			slen, tbl = [4]int{sfc / 3, sfc % 3, 0, 0}, 5
+		}
+	} else {
+		switch {
+		case sfc < 400:
+			slen, tbl = [4]int{sfc >> 4 / 5, sfc >> 4 % 5, sfc & 15 >> 2, sfc & 3}, 0
+		case sfc < 500:
+			sfc -= 400
+			slen, tbl = [4]int{sfc >> 2 / 5, sfc >> 2 % 5, sfc & 3, 0}, 1
+		default:
+			sfc -= 500
+			slen, tbl = [4]int{sfc / 3, sfc % 3, 0, 0}, 2
+			g.preflag = 1
+		}
+	}
+	kind := 0
+	if g.blockType == 2 {
+		kind = 1
+		if g.mixed {
+			kind = 2
+		}
+	}
+	i := 0
+	for k, cnt := range mp3LSFCounts[tbl][kind] {
+		for j := 0; j < cnt && i < len(scf); j++ {
+			v := b.read(slen[k])
+			scf[i], d.ist[i] = v, v
+			if slen[k] > 0 && v == 1<<uint(slen[k])-1 {
+				d.ist[i] = mp3BadPos
+			}
+			i++
+		}
+	}
+	for ; i < len(scf); i++ {
+		scf[i], d.ist[i] = 0, 0
+	}
+}
+
+// mp3BigTables 按 table_select 给出大值区使用的码表与 linbits（表 4、14 未使用）
+var mp3BigTables = [32]struct{ tab, linbits int }{
+	{0, 0}, {1, 0}, {2, 0}, {3, 0}, {0, 0}, {5, 0}, {6, 0}, {7, 0},
+	{8, 0}, {9, 0}, {10, 0}, {11, 0}, {12, 0}, {13, 0}, {0, 0}, {15, 0},
+	{16, 1}, {16, 2}, {16, 3}, {16, 4}, {16, 6}, {16, 8}, {16, 10}, {16, 13},
+	{24, 4}, {24, 5}, {24, 6}, {24, 7}, {24, 8}, {24, 9}, {24, 11}, {24, 13},
+}
+
+// readHuffman 把颗粒的大值区与 count1 区解码为量化值 d.is，读到 end 位为止；码流错误时返回 false
+func (d *mp3Decoder) readHuffman(b *mp3Bits, g *mp3Granule, widths []int, end int) bool {
+	d.is = [576]int{}
+	n := g.bigValues * 2
+	if n > 576 {
+		return false
+	}
+	r1, r2, pos := 576, 576, 0
+	for k, w := range widths {
+		pos += w
+		if k == g.region0 {
+			r1 = pos
+		}
+		if k == g.region0+g.region1+1 {
+			r2 = pos
+		}
+	}
+	for i := 0; i < n; i += 2 {
+		sel := g.table[2]
+		if i < r1 {
+			sel = g.table[0]
+		} else if i < r2 {
+			sel = g.table[1]
+		}
+		t := mp3BigTables[sel]
+		if t.tab == 0 {
+			continue
+		}
+		sym, ok := mp3Trees[t.tab].decode(b)
+		if !ok || b.pos > end {
+			return false
+		}
+		x, y := sym>>4, sym&15
+		if x == 15 && t.linbits > 0 {
+			x += b.read(t.linbits)
+		}
+		if x != 0 && b.read(1) == 1 {
+			x = -x
+		}
+		if y == 15 && t.linbits > 0 {
+			y += b.read(t.linbits)
+		}
+		if y != 0 && b.read(1) == 1 {
+			y = -y
+		}
+		d.is[i], d.is[i+1] = x, y
+	}
+	tree := mp3Trees[32+g.count1]
+	for i := n; i+4 <= 576 && b.pos < end; i += 4 {
+		sym, ok := tree.decode(b)
+		if !ok {
+			return false
+		}
+		q := [4]int{sym >> 3 & 1, sym >> 2 & 1, sym >> 1 & 1, sym & 1}
+		for k := range q {
+			if q[k] != 0 && b.read(1) == 1 {
+				q[k] = -1
+			}
+		}
+		if b.pos > end {
+			break // 最后一组越过了颗粒末尾，丢弃
+		}
+		copy(d.is[i:i+4], q[:])
+	}
+	return true
+}
+
+// requantize 按全局增益、缩放因子与子块增益把量化值还原为频谱 xr
+func (d *mp3Decoder) requantize(g *mp3Granule, widths []int, nLong int, scf *[39]int, xr *[576]float64) {
+	mult := 0.5 * float64(1+g.sfScale)
+	i := 0
+	for k, w := range widths {
+		var exp float64
+		if k < nLong {
+			sf := scf[k]
+			if g.preflag == 1 {
+				sf += mp3Pretab[k]
+			}
+			exp = float64(g.globalGain-210)/4 - mult*float64(sf)
+		} else {
+			win := (k - nLong) % 3
+			exp = float64(g.globalGain-210-8*g.subGain[win])/4 - mult*float64(scf[k])
+		}
+		m := math.Exp2(exp)
+		for j := 0; j < w && i < 576; j++ {
+			switch v := d.is[i]; {
+			case v > 0:
+				xr[i] = mp3Pow43(v) * m
+			case v < 0:
+				xr[i] = -mp3Pow43(-v) * m
+			default:
+				xr[i] = 0
+			}
+			i++
+		}
+	}
+}
+
+// stereo 处理联合立体声：强度立体声区（右声道各窗口最后一个非零频带之后）由左声道按位置分配到两个声道，
+// 其余频带按中-侧立体声还原
+func (d *mp3Decoder) stereo(h mp3Header, right *mp3Granule, widths []int, nLong int) {
+	ms, intensity := h.modeExt&2 != 0, h.modeExt&1 != 0
+	l, r := &d.xr[0], &d.xr[1]
+	if !intensity {
+		if ms {
+			mp3MidSide(l[:], r[:])
+		}
+		return
+	}
+	maxBand := [3]int{-1, -1, -1}
+	pos := 0
+	for k, w := range widths {
+		for j := pos; j < pos+w; j++ {
+			if r[j] != 0 {
+				maxBand[k%3] = k
+				break
+			}
+		}
+		pos += w
+	}
+	if nLong > 0 {
+		m := max(maxBand[0], maxBand[1], maxBand[2])
+		maxBand = [3]int{m, m, m}
+	}
+	// 最高的频带没有缩放因子：沿用下一层频带的位置（该频带仍有非零值时取默认位置）
+	ist := d.ist
+	n, blocks := len(widths), 1
+	if nLong < n {
+		blocks = 3
+	}
+	def, maxPos := 3, 7
+	if h.lsf {
+		def, maxPos = 0, mp3BadPos
+	}
+	for i := 0; i < blocks; i++ {
+		top, prev := n-blocks+i, n-2*blocks+i
+		if maxBand[i] >= prev {
+			ist[top] = def
+		} else {
+			ist[top] = ist[prev]
+		}
+	}
+
+	pos = 0
+	for k, w := range widths {
+		if p := ist[k]; k > maxBand[k%3] && p < maxPos {
+			var kl, kr float64
+			if h.lsf {
+				kl, kr = 1, math.Exp2(-float64((p+1)>>1<<uint(right.sfCompress&1))/4)
+				if p&1 == 1 {
+					kl, kr = kr, 1
+				}
+			} else {
+				sin, cos := math.Sincos(float64(p) * math.Pi / 12)
+				kl, kr = sin/(sin+cos), cos/(sin+cos)
+			}
+			for j := pos; j < pos+w; j++ {
+				l[j], r[j] = l[j]*kl, l[j]*kr
+			}
+		} else if ms {
+			mp3MidSide(l[pos:pos+w], r[pos:pos+w])
+		}
+		pos += w
+	}
+}
+
+func mp3MidSide(l, r []float64) {
+	for i := range l {
+		l[i], r[i] = (l[i]+r[i])*math.Sqrt2/2, (l[i]-r[i])*math.Sqrt2/2
+	}
+}
+
+// mp3Reorder 把短块频带内按窗口连续存放的谱线重排为按频率交错（每条谱线的 3 个窗口相邻）
+func mp3Reorder(xr *[576]float64, widths []int, nLong int) {
+	pos := 0
+	for _, w := range widths[:nLong] {
+		pos += w
+	}
+	var tmp [576]float64
+	for k := nLong; k+2 < len(widths); k += 3 {
+		w := widths[k]
+		for win := 0; win < 3; win++ {
+			for j := 0; j < w; j++ {
+				tmp[3*j+win] = xr[pos+win*w+j]
+			}
+		}
+		copy(xr[pos:pos+3*w], tmp[:3*w])
+		pos += 3 * w
+	}
+}
+
+// mp3Antialias 对相邻子带边界做抗混叠蝶形运算（短块不做，混合块只做长块部分）
+func mp3Antialias(xr *[576]float64, g *mp3Granule) {
+	n := 31
+	if g.blockType == 2 {
+		if !g.mixed {
+			return
+		}
+		n = 1
+	}
+	for sb := 1; sb <= n; sb++ {
+		for i := 0; i < 8; i++ {
+			a, b := xr[18*sb-1-i], xr[18*sb+i]
+			xr[18*sb-1-i] = a*mp3CS[i] - b*mp3CA[i]
+			xr[18*sb+i] = b*mp3CS[i] + a*mp3CA[i]
+		}
+	}
+}
+
+// hybrid 对声道 ch 的每个子带做 IMDCT、加窗与重叠相加，结果（已做频率翻转）写入 d.sub[ch]
+func (d *mp3Decoder) hybrid(ch int, g *mp3Granule) {
+	xr := &d.xr[ch]
+	for sb := 0; sb < 32; sb++ {
+		bt := g.blockType
+		if g.mixed && sb < 2 {
+			bt = 0
+		}
+		in := xr[18*sb : 18*sb+18]
+		var raw [36]float64
+		zero := true
+		for _, v := range in {
+			if v != 0 {
+				zero = false
+				break
+			}
+		}
+		switch {
+		case zero:
+		case bt == 2:
+			for w := 0; w < 3; w++ {
+				for i := 0; i < 12; i++ {
+					var s float64
+					for k := 0; k < 6; k++ {
+						s += in[3*k+w] * mp3CosShort[i][k]
+					}
+					raw[6+6*w+i] += s * mp3WinShort[i]
+				}
+			}
+		default:
+			for i := 0; i < 36; i++ {
+				var s float64
+				for k := 0; k < 18; k++ {
+					s += in[k] * mp3CosLong[i][k]
+				}
+				raw[i] = s * mp3Win[bt][i]
+			}
+		}
+		ov := &d.overlap[ch][sb]
+		for i := 0; i < 18; i++ {
+			v := raw[i] + ov[i]
+			ov[i] = raw[18+i]
+			if sb&1 == 1 && i&1 == 1 {
+				v = -v
+			}
+			d.sub[ch][i][sb] = v
+		}
+	}
+}
+
+// synth 用多相合成滤波器组把 d.sub[0] 的 18 个时隙合成为 576 个 PCM 样本
+func (d *mp3Decoder) synth(out []int16) {
+	for t := 0; t < 18; t++ {
+		copy(d.v[64:], d.v[:1024-64])
+		s := &d.sub[0][t]
+		for i := 0; i < 64; i++ {
+			var sum float64
+			for k, x := range s {
+				sum += mp3SynthN[i][k] * x
+			}
+			d.v[i] = sum
+		}
+		for j := 0; j < 32; j++ {
+			var sum float64
+			for i := 0; i < 8; i++ {
+				sum += d.v[128*i+j]*mp3SynthWindow[64*i+j] + d.v[128*i+96+j]*mp3SynthWindow[64*i+32+j]
+			}
+			v := math.Round(sum * 32767)
+			out[32*t+j] = int16(max(-32768, min(32767, v)))
+		}
+	}
+}
+
+// mp3Tree 为由码表构造的二叉解码树：正数为子节点下标，负数为 -(解码值+1)，0 表示无此码字
+type mp3Tree [][2]int32
+
+func buildMP3Tree(codes []mp3Code) mp3Tree {
+	t := mp3Tree{{0, 0}}
+	for _, c := range codes {
+		n := 0
+		for k := int(c.bits) - 1; k >= 0; k-- {
+			bit := c.code >> uint(k) & 1
+			if k == 0 {
+				t[n][bit] = -int32(c.sym) - 1
+				break
+			}
+			if t[n][bit] == 0 {
+				t = append(t, [2]int32{})
+				t[n][bit] = int32(len(t) - 1)
+			}
+			n = int(t[n][bit])
+		}
+	}
+	return t
+}
+
+func (t mp3Tree) decode(b *mp3Bits) (int, bool) {
+	n := int32(0)
+	for {
+		c := t[n][b.read(1)]
+		if c < 0 {
+			return int(-c - 1), true
+		}
+		if c == 0 {
+			return 0, false
+		}
+		n = c
+	}
+}
+
+// 由初始化计算的表
+var (
+	mp3Trees    [34]mp3Tree
+	mp3Pow43Tab [8207]float64 // |x|^(4/3)，x 最大为 15 + 2^13 - 1
+	mp3CS       [8]float64
+	mp3CA       [8]float64
+	mp3Win      [4][36]float64
+	mp3WinShort [12]float64
+	mp3CosLong  [36][18]float64
+	mp3CosShort [12][6]float64
+	mp3SynthN   [64][32]float64
+)
+
+func mp3Pow43(v int) float64 {
+	if v < len(mp3Pow43Tab) {
+		return mp3Pow43Tab[v]
+	}
+	return math.Pow(float64(v), 4.0/3)
+}
+
+func initMP3() {
+	for n, codes := range mp3HuffCodes {
+		mp3Trees[n] = buildMP3Tree(codes)
+	}
+	for i := range mp3Pow43Tab {
+		mp3Pow43Tab[i] = math.Pow(float64(i), 4.0/3)
+	}
+	for i, c := range [8]float64{-0.6, -0.535, -0.33, -0.185, -0.095, -0.041, -0.0142, -0.0037} {
+		sq := math.Sqrt(1 + c*c)
+		mp3CS[i], mp3CA[i] = 1/sq, c/sq
+	}
+	for i := 0; i < 36; i++ {
+		long := math.Sin(math.Pi / 36 * (float64(i) + 0.5))
+		mp3Win[0][i] = long
+		switch {
+		case i < 18:
+			mp3Win[1][i] = long
+		case i < 24:
+			mp3Win[1][i] = 1
+		case i < 30:
+			mp3Win[1][i] = math.Sin(math.Pi / 12 * (float64(i-18) + 0.5))
+		}
+		switch {
+		case i < 6:
+		case i < 12:
+			mp3Win[3][i] = math.Sin(math.Pi / 12 * (float64(i-6) + 0.5))
+		case i < 18:
+			mp3Win[3][i] = 1
+		default:
+			mp3Win[3][i] = long
+		}
+		for k := 0; k < 18; k++ {
+			mp3CosLong[i][k] = math.Cos(math.Pi / 72 * float64((2*i+1+18)*(2*k+1)))
+		}
+	}
+	for i := 0; i < 12; i++ {
+		mp3WinShort[i] = math.Sin(math.Pi / 12 * (float64(i) + 0.5))
+		for k := 0; k < 6; k++ {
+			mp3CosShort[i][k] = math.Cos(math.Pi / 24 * float64((2*i+1+6)*(2*k+1)))
+		}
+	}
+	for i := 0; i < 64; i++ {
+		for k := 0; k < 32; k++ {
+			mp3SynthN[i][k] = math.Cos(math.Pi / 64 * float64((16+i)*(2*k+1)))
+		}
+	}
+}
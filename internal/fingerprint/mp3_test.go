@@ -0,0 +1,148 @@
+// file: internal/fingerprint/mp3_test.go
+// package: fingerprint
+//
+// 测试内置 MP3 解码器：帧同步（ID3v2 标签、帧间杂数据）、Xing / LAME 信息帧与编码器延迟，
+// 对仓库自带示例文件的解码，以及与参考解码器输出（testdata/*.s16）的一致性。
+package fingerprint
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io/fs"
+	"math"
+	"os"
+	"testing"
+)
+
+// silentMP3Frame 返回一个 MPEG-1 Layer III、128kbps、44.1kHz 单声道的静音帧（边信息全为 0）
+func silentMP3Frame() []byte {
+	f := make([]byte, 417)
+	copy(f, []byte{0xFF, 0xFB, 0x90, 0xC0})
+	return f
+}
+
+func TestDecodeMP3Frames(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString("ID3\x04\x00\x00\x00\x00\x00\x05")
+	buf.WriteString("\xFF\xFB\x00\x00\x00") // 标签内容看起来像帧头，应随标签一起跳过
+	buf.WriteString("junk\xFF\xFB")         // 标签后的无法识别的数据与伪同步字
+	info := silentMP3Frame()
+	off := 4 + 17
+	copy(info[off:], "Info\x00\x00\x00\x0F")
+	lame := off + 8 + 4 + 4 + 100 + 4
+	copy(info[lame:], "LAME3.100")
+	info[lame+21], info[lame+22] = 0x24, 0x00 // 编码器延迟 576
+	buf.Write(info)
+	for i := 0; i < 4; i++ {
+		buf.Write(silentMP3Frame())
+	}
+
+	n := 0
+	err := decodeMP3(&buf, func(rate int, mono []int16) bool {
+		if rate != 44100 {
+			t.Fatalf("rate = %d", rate)
+		}
+		for _, v := range mono {
+			if v != 0 {
+				t.Fatalf("静音帧解码出非零样本 %d", v)
+			}
+		}
+		n += len(mono)
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 信息帧不输出；开头去掉 576 + 529 个样本
+	if want := 4*1152 - 576 - 529; n != want {
+		t.Fatalf("样本数 = %d，期望 %d", n, want)
+	}
+}
+
+func TestDecodeMP3File(t *testing.T) {
+	f, err := os.Open("../../testMusic/a.mp3")
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Skip("没有示例文件 testMusic/a.mp3")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d, ok := fallbackFor("mp3")
+	if !ok {
+		t.Fatal("应自带 MP3 后备解码器")
+	}
+	samples, err := d.Decode(f, 8, 0)
+	if err != nil || len(samples) != 8*SampleRate {
+		t.Fatalf("Decode = %d 个样本, %v", len(samples), err)
+	}
+	// 示例曲目开头约 3 秒静音，之后是钢琴
+	avg := BlockAverages(samples, 16)
+	if avg[0] > 1 || avg[15] < 100 {
+		t.Fatalf("块平均振幅异常: %v", avg)
+	}
+}
+
+// 参考 PCM 由独立的 MP3 解码器（github.com/hajimehoshi/go-mp3）解码示例文件得到：
+// 取左右声道平均后的单声道 16 位小端样本，从第 granule 个颗粒（576 个样本）开始，覆盖含短块的片段。
+// 参考解码器不去掉编码器延迟且把信息帧解码为静音，因此参考的第 granule*576+1152 个样本
+// 对应本解码器输出的第 granule*576-(576+529) 个样本。
+func TestDecodeMP3Reference(t *testing.T) {
+	for _, tc := range []struct {
+		mp3, ref string
+		granule  int
+	}{
+		{"../../testMusic/a.mp3", "testdata/a_g604.s16", 604},
+		{"../../testMusic/c.mp3", "testdata/c_g380.s16", 380},
+	} {
+		t.Run(tc.ref, func(t *testing.T) {
+			raw, err := os.ReadFile(tc.ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ref := make([]int16, len(raw)/2)
+			for i := range ref {
+				ref[i] = int16(binary.LittleEndian.Uint16(raw[2*i:]))
+			}
+			f, err := os.Open(tc.mp3)
+			if errors.Is(err, fs.ErrNotExist) {
+				t.Skipf("没有示例文件 %s", tc.mp3)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			start := tc.granule*576 - 576 - 529
+			var got []int16
+			err = decodeMP3(bufio.NewReader(f), func(rate int, mono []int16) bool {
+				got = append(got, mono...)
+				return len(got) < start+len(ref)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) < start+len(ref) {
+				t.Fatalf("只解码出 %d 个样本", len(got))
+			}
+			got = got[start : start+len(ref)]
+			var sig, noise float64
+			maxErr := 0.0
+			for i, r := range ref {
+				d := float64(got[i]) - float64(r)
+				sig += float64(r) * float64(r)
+				noise += d * d
+				maxErr = math.Max(maxErr, math.Abs(d))
+			}
+			rms, rmsErr := math.Sqrt(sig/float64(len(ref))), math.Sqrt(noise/float64(len(ref)))
+			if rms < 50 {
+				t.Fatalf("参考片段几乎是静音（RMS %.1f），起点不对", rms)
+			}
+			// 两个解码器只在浮点舍入与声道平均的取整上不同：误差应在 1 个最低有效位左右
+			if rmsErr > 1 || maxErr > 2 {
+				t.Fatalf("与参考输出不一致: RMS 误差 %.2f，最大误差 %.0f（信号 RMS %.0f）", rmsErr, maxErr, rms)
+			}
+		})
+	}
+}
@@ -0,0 +1,394 @@
+// file: internal/fingerprint/mp3tables.go
+// package: fingerprint
+//
+// MP3 解码器的常量表（ISO/IEC 11172-3 与 13818-3）：Huffman 码表、各采样率的缩放因子频带边界、
+// 合成滤波器组的窗口系数。
+package fingerprint
+
+// mp3Code 为一条 Huffman 码字：code 的低 bits 位为码字，sym 为解码值
+// （大值区为 x<<4 | y，count1 区为 v<<3 | w<<2 | x<<1 | y）
+type mp3Code struct {
+	code uint32
+	bits uint8
+	sym  uint8
+}
+
+// mp3HuffCodes 按表号给出 Huffman 码表；32、33 为 count1 区的表 A、B
+var mp3HuffCodes = map[int][]mp3Code{
+	1: {
+		{0x1, 1, 0x00}, {0x1, 3, 0x01}, {0x1, 2, 0x10}, {0x0, 3, 0x11},
+	},
+	2: {
+		{0x1, 1, 0x00}, {0x2, 3, 0x01}, {0x1, 6, 0x02}, {0x3, 3, 0x10}, {0x1, 3, 0x11}, {0x1, 5, 0x12},
+		{0x3, 5, 0x20}, {0x2, 5, 0x21}, {0x0, 6, 0x22},
+	},
+	3: {
+		{0x3, 2, 0x00}, {0x2, 2, 0x01}, {0x1, 6, 0x02}, {0x1, 3, 0x10}, {0x1, 2, 0x11}, {0x1, 5, 0x12},
+		{0x3, 5, 0x20}, {0x2, 5, 0x21}, {0x0, 6, 0x22},
+	},
+	5: {
+		{0x1, 1, 0x00}, {0x2, 3, 0x01}, {0x6, 6, 0x02}, {0x5, 7, 0x03}, {0x3, 3, 0x10}, {0x1, 3, 0x11},
+		{0x4, 6, 0x12}, {0x4, 7, 0x13}, {0x7, 6, 0x20}, {0x5, 6, 0x21}, {0x7, 7, 0x22}, {0x1, 8, 0x23},
+		{0x6, 7, 0x30}, {0x1, 6, 0x31}, {0x1, 7, 0x32}, {0x0, 8, 0x33},
+	},
+	6: {
+		{0x7, 3, 0x00}, {0x3, 3, 0x01}, {0x5, 5, 0x02}, {0x1, 7, 0x03}, {0x6, 3, 0x10}, {0x2, 2, 0x11},
+		{0x3, 4, 0x12}, {0x2, 5, 0x13}, {0x5, 4, 0x20}, {0x4, 4, 0x21}, {0x4, 5, 0x22}, {0x1, 6, 0x23},
+		{0x3, 6, 0x30}, {0x3, 5, 0x31}, {0x2, 6, 0x32}, {0x0, 7, 0x33},
+	},
+	7: {
+		{0x1, 1, 0x00}, {0x2, 3, 0x01}, {0xa, 6, 0x02}, {0x13, 8, 0x03}, {0x10, 8, 0x04}, {0xa, 9, 0x05},
+		{0x3, 3, 0x10}, {0x3, 4, 0x11}, {0x7, 6, 0x12}, {0xa, 7, 0x13}, {0x5, 7, 0x14}, {0x3, 8, 0x15},
+		{0xb, 6, 0x20}, {0x4, 5, 0x21}, {0xd, 7, 0x22}, {0x11, 8, 0x23}, {0x8, 8, 0x24}, {0x4, 9, 0x25},
+		{0xc, 7, 0x30}, {0xb, 7, 0x31}, {0x12, 8, 0x32}, {0xf, 9, 0x33}, {0xb, 9, 0x34}, {0x2, 9, 0x35},
+		{0x7, 7, 0x40}, {0x6, 7, 0x41}, {0x9, 8, 0x42}, {0xe, 9, 0x43}, {0x3, 9, 0x44}, {0x1, 10, 0x45},
+		{0x6, 8, 0x50}, {0x4, 8, 0x51}, {0x5, 9, 0x52}, {0x3, 10, 0x53}, {0x2, 10, 0x54}, {0x0, 10, 0x55},
+	},
+	8: {
+		{0x3, 2, 0x00}, {0x4, 3, 0x01}, {0x6, 6, 0x02}, {0x12, 8, 0x03}, {0xc, 8, 0x04}, {0x5, 9, 0x05},
+		{0x5, 3, 0x10}, {0x1, 2, 0x11}, {0x2, 4, 0x12}, {0x10, 8, 0x13}, {0x9, 8, 0x14}, {0x3, 8, 0x15},
+		{0x7, 6, 0x20}, {0x3, 4, 0x21}, {0x5, 6, 0x22}, {0xe, 8, 0x23}, {0x7, 8, 0x24}, {0x3, 9, 0x25},
+		{0x13, 8, 0x30}, {0x11, 8, 0x31}, {0xf, 8, 0x32}, {0xd, 9, 0x33}, {0xa, 9, 0x34}, {0x4, 10, 0x35},
+		{0xd, 8, 0x40}, {0x5, 7, 0x41}, {0x8, 8, 0x42}, {0xb, 9, 0x43}, {0x5, 10, 0x44}, {0x1, 10, 0x45},
+		{0xc, 9, 0x50}, {0x4, 8, 0x51}, {0x4, 9, 0x52}, {0x1, 9, 0x53}, {0x1, 11, 0x54}, {0x0, 11, 0x55},
+	},
+	9: {
+		{0x7, 3, 0x00}, {0x5, 3, 0x01}, {0x9, 5, 0x02}, {0xe, 6, 0x03}, {0xf, 8, 0x04}, {0x7, 9, 0x05},
+		{0x6, 3, 0x10}, {0x4, 3, 0x11}, {0x5, 4, 0x12}, {0x5, 5, 0x13}, {0x6, 6, 0x14}, {0x7, 8, 0x15},
+		{0x7, 4, 0x20}, {0x6, 4, 0x21}, {0x8, 5, 0x22}, {0x8, 6, 0x23}, {0x8, 7, 0x24}, {0x5, 8, 0x25},
+		{0xf, 6, 0x30}, {0x6, 5, 0x31}, {0x9, 6, 0x32}, {0xa, 7, 0x33}, {0x5, 7, 0x34}, {0x1, 8, 0x35},
+		{0xb, 7, 0x40}, {0x7, 6, 0x41}, {0x9, 7, 0x42}, {0x6, 7, 0x43}, {0x4, 8, 0x44}, {0x1, 9, 0x45},
+		{0xe, 8, 0x50}, {0x4, 7, 0x51}, {0x6, 8, 0x52}, {0x2, 8, 0x53}, {0x6, 9, 0x54}, {0x0, 9, 0x55},
+	},
+	10: {
+		{0x1, 1, 0x00}, {0x2, 3, 0x01}, {0xa, 6, 0x02}, {0x17, 8, 0x03}, {0x23, 9, 0x04}, {0x1e, 9, 0x05},
+		{0xc, 9, 0x06}, {0x11, 10, 0x07}, {0x3, 3, 0x10}, {0x3, 4, 0x11}, {0x8, 6, 0x12}, {0xc, 7, 0x13},
+		{0x12, 8, 0x14}, {0x15, 9, 0x15}, {0xc, 8, 0x16}, {0x7, 8, 0x17}, {0xb, 6, 0x20}, {0x9, 6, 0x21},
+		{0xf, 7, 0x22}, {0x15, 8, 0x23}, {0x20, 9, 0x24}, {0x28, 10, 0x25}, {0x13, 9, 0x26}, {0x6, 9, 0x27},
+		{0xe, 7, 0x30}, {0xd, 7, 0x31}, {0x16, 8, 0x32}, {0x22, 9, 0x33}, {0x2e, 10, 0x34}, {0x17, 10, 0x35},
+		{0x12, 9, 0x36}, {0x7, 10, 0x37}, {0x14, 8, 0x40}, {0x13, 8, 0x41}, {0x21, 9, 0x42}, {0x2f, 10, 0x43},
+		{0x1b, 10, 0x44}, {0x16, 10, 0x45}, {0x9, 10, 0x46}, {0x3, 10, 0x47}, {0x1f, 9, 0x50}, {0x16, 9, 0x51},
+		{0x29, 10, 0x52}, {0x1a, 10, 0x53}, {0x15, 11, 0x54}, {0x14, 11, 0x55}, {0x5, 10, 0x56}, {0x3, 11, 0x57},
+		{0xe, 8, 0x60}, {0xd, 8, 0x61}, {0xa, 9, 0x62}, {0xb, 10, 0x63}, {0x10, 10, 0x64}, {0x6, 10, 0x65},
+		{0x5, 11, 0x66}, {0x1, 11, 0x67}, {0x9, 9, 0x70}, {0x8, 8, 0x71}, {0x7, 9, 0x72}, {0x8, 10, 0x73},
+		{0x4, 10, 0x74}, {0x4, 11, 0x75}, {0x2, 11, 0x76}, {0x0, 11, 0x77},
+	},
+	11: {
+		{0x3, 2, 0x00}, {0x4, 3, 0x01}, {0xa, 5, 0x02}, {0x18, 7, 0x03}, {0x22, 8, 0x04}, {0x21, 9, 0x05},
+		{0x15, 8, 0x06}, {0xf, 9, 0x07}, {0x5, 3, 0x10}, {0x3, 3, 0x11}, {0x4, 4, 0x12}, {0xa, 6, 0x13},
+		{0x20, 8, 0x14}, {0x11, 8, 0x15}, {0xb, 7, 0x16}, {0xa, 8, 0x17}, {0xb, 5, 0x20}, {0x7, 5, 0x21},
+		{0xd, 6, 0x22}, {0x12, 7, 0x23}, {0x1e, 8, 0x24}, {0x1f, 9, 0x25}, {0x14, 8, 0x26}, {0x5, 8, 0x27},
+		{0x19, 7, 0x30}, {0xb, 6, 0x31}, {0x13, 7, 0x32}, {0x3b, 9, 0x33}, {0x1b, 8, 0x34}, {0x12, 10, 0x35},
+		{0xc, 8, 0x36}, {0x5, 9, 0x37}, {0x23, 8, 0x40}, {0x21, 8, 0x41}, {0x1f, 8, 0x42}, {0x3a, 9, 0x43},
+		{0x1e, 9, 0x44}, {0x10, 10, 0x45}, {0x7, 9, 0x46}, {0x5, 10, 0x47}, {0x1c, 8, 0x50}, {0x1a, 8, 0x51},
+		{0x20, 9, 0x52}, {0x13, 10, 0x53}, {0x11, 10, 0x54}, {0xf, 11, 0x55}, {0x8, 10, 0x56}, {0xe, 11, 0x57},
+		{0xe, 8, 0x60}, {0xc, 7, 0x61}, {0x9, 7, 0x62}, {0xd, 8, 0x63}, {0xe, 9, 0x64}, {0x9, 10, 0x65},
+		{0x4, 10, 0x66}, {0x1, 10, 0x67}, {0xb, 8, 0x70}, {0x4, 7, 0x71}, {0x6, 8, 0x72}, {0x6, 9, 0x73},
+		{0x6, 10, 0x74}, {0x3, 10, 0x75}, {0x2, 10, 0x76}, {0x0, 10, 0x77},
+	},
+	12: {
+		{0x9, 4, 0x00}, {0x6, 3, 0x01}, {0x10, 5, 0x02}, {0x21, 7, 0x03}, {0x29, 8, 0x04}, {0x27, 9, 0x05},
+		{0x26, 9, 0x06}, {0x1a, 9, 0x07}, {0x7, 3, 0x10}, {0x5, 3, 0x11}, {0x6, 4, 0x12}, {0x9, 5, 0x13},
+		{0x17, 7, 0x14}, {0x10, 7, 0x15}, {0x1a, 8, 0x16}, {0xb, 8, 0x17}, {0x11, 5, 0x20}, {0x7, 4, 0x21},
+		{0xb, 5, 0x22}, {0xe, 6, 0x23}, {0x15, 7, 0x24}, {0x1e, 8, 0x25}, {0xa, 7, 0x26}, {0x7, 8, 0x27},
+		{0x11, 6, 0x30}, {0xa, 5, 0x31}, {0xf, 6, 0x32}, {0xc, 6, 0x33}, {0x12, 7, 0x34}, {0x1c, 8, 0x35},
+		{0xe, 8, 0x36}, {0x5, 8, 0x37}, {0x20, 7, 0x40}, {0xd, 6, 0x41}, {0x16, 7, 0x42}, {0x13, 7, 0x43},
+		{0x12, 8, 0x44}, {0x10, 8, 0x45}, {0x9, 8, 0x46}, {0x5, 9, 0x47}, {0x28, 8, 0x50}, {0x11, 7, 0x51},
+		{0x1f, 8, 0x52}, {0x1d, 8, 0x53}, {0x11, 8, 0x54}, {0xd, 9, 0x55}, {0x4, 8, 0x56}, {0x2, 9, 0x57},
+		{0x1b, 8, 0x60}, {0xc, 7, 0x61}, {0xb, 7, 0x62}, {0xf, 8, 0x63}, {0xa, 8, 0x64}, {0x7, 9, 0x65},
+		{0x4, 9, 0x66}, {0x1, 10, 0x67}, {0x1b, 9, 0x70}, {0xc, 8, 0x71}, {0x8, 8, 0x72}, {0xc, 9, 0x73},
+		{0x6, 9, 0x74}, {0x3, 9, 0x75}, {0x1, 9, 0x76}, {0x0, 10, 0x77},
+	},
+	13: {
+		{0x1, 1, 0x00}, {0x5, 4, 0x01}, {0xe, 6, 0x02}, {0x15, 7, 0x03}, {0x22, 8, 0x04}, {0x33, 9, 0x05},
+		{0x2e, 9, 0x06}, {0x47, 10, 0x07}, {0x2a, 9, 0x08}, {0x34, 10, 0x09}, {0x44, 11, 0x0a}, {0x34, 11, 0x0b},
+		{0x43, 12, 0x0c}, {0x2c, 12, 0x0d}, {0x2b, 13, 0x0e}, {0x13, 13, 0x0f}, {0x3, 3, 0x10}, {0x4, 4, 0x11},
+		{0xc, 6, 0x12}, {0x13, 7, 0x13}, {0x1f, 8, 0x14}, {0x1a, 8, 0x15}, {0x2c, 9, 0x16}, {0x21, 9, 0x17},
+		{0x1f, 9, 0x18}, {0x18, 9, 0x19}, {0x20, 10, 0x1a}, {0x18, 10, 0x1b}, {0x1f, 11, 0x1c}, {0x23, 12, 0x1d},
+		{0x16, 12, 0x1e}, {0xe, 12, 0x1f}, {0xf, 6, 0x20}, {0xd, 6, 0x21}, {0x17, 7, 0x22}, {0x24, 8, 0x23},
+		{0x3b, 9, 0x24}, {0x31, 9, 0x25}, {0x4d, 10, 0x26}, {0x41, 10, 0x27}, {0x1d, 9, 0x28}, {0x28, 10, 0x29},
+		{0x1e, 10, 0x2a}, {0x28, 11, 0x2b}, {0x1b, 11, 0x2c}, {0x21, 12, 0x2d}, {0x2a, 13, 0x2e}, {0x10, 13, 0x2f},
+		{0x16, 7, 0x30}, {0x14, 7, 0x31}, {0x25, 8, 0x32}, {0x3d, 9, 0x33}, {0x38, 9, 0x34}, {0x4f, 10, 0x35},
+		{0x49, 10, 0x36}, {0x40, 10, 0x37}, {0x2b, 10, 0x38}, {0x4c, 11, 0x39}, {0x38, 11, 0x3a}, {0x25, 11, 0x3b},
+		{0x1a, 11, 0x3c}, {0x1f, 12, 0x3d}, {0x19, 13, 0x3e}, {0xe, 13, 0x3f}, {0x23, 8, 0x40}, {0x10, 7, 0x41},
+		{0x3c, 9, 0x42}, {0x39, 9, 0x43}, {0x61, 10, 0x44}, {0x4b, 10, 0x45}, {0x72, 11, 0x46}, {0x5b, 11, 0x47},
+		{0x36, 10, 0x48}, {0x49, 11, 0x49}, {0x37, 11, 0x4a}, {0x29, 12, 0x4b}, {0x30, 12, 0x4c}, {0x35, 13, 0x4d},
+		{0x17, 13, 0x4e}, {0x18, 14, 0x4f}, {0x3a, 9, 0x50}, {0x1b, 8, 0x51}, {0x32, 9, 0x52}, {0x60, 10, 0x53},
+		{0x4c, 10, 0x54}, {0x46, 10, 0x55}, {0x5d, 11, 0x56}, {0x54, 11, 0x57}, {0x4d, 11, 0x58}, {0x3a, 11, 0x59},
+		{0x4f, 12, 0x5a}, {0x1d, 11, 0x5b}, {0x4a, 13, 0x5c}, {0x31, 13, 0x5d}, {0x29, 14, 0x5e}, {0x11, 14, 0x5f},
+		{0x2f, 9, 0x60}, {0x2d, 9, 0x61}, {0x4e, 10, 0x62}, {0x4a, 10, 0x63}, {0x73, 11, 0x64}, {0x5e, 11, 0x65},
+		{0x5a, 11, 0x66}, {0x4f, 11, 0x67}, {0x45, 11, 0x68}, {0x53, 12, 0x69}, {0x47, 12, 0x6a}, {0x32, 12, 0x6b},
+		{0x3b, 13, 0x6c}, {0x26, 13, 0x6d}, {0x24, 14, 0x6e}, {0xf, 14, 0x6f}, {0x48, 10, 0x70}, {0x22, 9, 0x71},
+		{0x38, 10, 0x72}, {0x5f, 11, 0x73}, {0x5c, 11, 0x74}, {0x55, 11, 0x75}, {0x5b, 12, 0x76}, {0x5a, 12, 0x77},
+		{0x56, 12, 0x78}, {0x49, 12, 0x79}, {0x4d, 13, 0x7a}, {0x41, 13, 0x7b}, {0x33, 13, 0x7c}, {0x2c, 14, 0x7d},
+		{0x2b, 16, 0x7e}, {0x2a, 16, 0x7f}, {0x2b, 9, 0x80}, {0x14, 8, 0x81}, {0x1e, 9, 0x82}, {0x2c, 10, 0x83},
+		{0x37, 10, 0x84}, {0x4e, 11, 0x85}, {0x48, 11, 0x86}, {0x57, 12, 0x87}, {0x4e, 12, 0x88}, {0x3d, 12, 0x89},
+		{0x2e, 12, 0x8a}, {0x36, 13, 0x8b}, {0x25, 13, 0x8c}, {0x1e, 14, 0x8d}, {0x14, 15, 0x8e}, {0x10, 15, 0x8f},
+		{0x35, 10, 0x90}, {0x19, 9, 0x91}, {0x29, 10, 0x92}, {0x25, 10, 0x93}, {0x2c, 11, 0x94}, {0x3b, 11, 0x95},
+		{0x36, 11, 0x96}, {0x51, 13, 0x97}, {0x42, 12, 0x98}, {0x4c, 13, 0x99}, {0x39, 13, 0x9a}, {0x36, 14, 0x9b},
+		{0x25, 14, 0x9c}, {0x12, 14, 0x9d}, {0x27, 16, 0x9e}, {0xb, 15, 0x9f}, {0x23, 10, 0xa0}, {0x21, 10, 0xa1},
+		{0x1f, 10, 0xa2}, {0x39, 11, 0xa3}, {0x2a, 11, 0xa4}, {0x52, 12, 0xa5}, {0x48, 12, 0xa6}, {0x50, 13, 0xa7},
+		{0x2f, 12, 0xa8}, {0x3a, 13, 0xa9}, {0x37, 14, 0xaa}, {0x15, 13, 0xab}, {0x16, 14, 0xac}, {0x1a, 15, 0xad},
+		{0x26, 16, 0xae}, {0x16, 17, 0xaf}, {0x35, 11, 0xb0}, {0x19, 10, 0xb1}, {0x17, 10, 0xb2}, {0x26, 11, 0xb3},
+		{0x46, 12, 0xb4}, {0x3c, 12, 0xb5}, {0x33, 12, 0xb6}, {0x24, 12, 0xb7}, {0x37, 13, 0xb8}, {0x1a, 13, 0xb9},
+		{0x22, 13, 0xba}, {0x17, 14, 0xbb}, {0x1b, 15, 0xbc}, {0xe, 15, 0xbd}, {0x9, 15, 0xbe}, {0x7, 16, 0xbf},
+		{0x22, 11, 0xc0}, {0x20, 11, 0xc1}, {0x1c, 11, 0xc2}, {0x27, 12, 0xc3}, {0x31, 12, 0xc4}, {0x4b, 13, 0xc5},
+		{0x1e, 12, 0xc6}, {0x34, 13, 0xc7}, {0x30, 14, 0xc8}, {0x28, 14, 0xc9}, {0x34, 15, 0xca}, {0x1c, 15, 0xcb},
+		{0x12, 15, 0xcc}, {0x11, 16, 0xcd}, {0x9, 16, 0xce}, {0x5, 16, 0xcf}, {0x2d, 12, 0xd0}, {0x15, 11, 0xd1},
+		{0x22, 12, 0xd2}, {0x40, 13, 0xd3}, {0x38, 13, 0xd4}, {0x32, 13, 0xd5}, {0x31, 14, 0xd6}, {0x2d, 14, 0xd7},
+		{0x1f, 14, 0xd8}, {0x13, 14, 0xd9}, {0xc, 14, 0xda}, {0xf, 15, 0xdb}, {0xa, 16, 0xdc}, {0x7, 15, 0xdd},
+		{0x6, 16, 0xde}, {0x3, 16, 0xdf}, {0x30, 13, 0xe0}, {0x17, 12, 0xe1}, {0x14, 12, 0xe2}, {0x27, 13, 0xe3},
+		{0x24, 13, 0xe4}, {0x23, 13, 0xe5}, {0x35, 15, 0xe6}, {0x15, 14, 0xe7}, {0x10, 14, 0xe8}, {0x17, 17, 0xe9},
+		{0xd, 15, 0xea}, {0xa, 15, 0xeb}, {0x6, 15, 0xec}, {0x1, 17, 0xed}, {0x4, 16, 0xee}, {0x2, 16, 0xef},
+		{0x10, 12, 0xf0}, {0xf, 12, 0xf1}, {0x11, 13, 0xf2}, {0x1b, 14, 0xf3}, {0x19, 14, 0xf4}, {0x14, 14, 0xf5},
+		{0x1d, 15, 0xf6}, {0xb, 14, 0xf7}, {0x11, 15, 0xf8}, {0xc, 15, 0xf9}, {0x10, 16, 0xfa}, {0x8, 16, 0xfb},
+		{0x1, 19, 0xfc}, {0x1, 18, 0xfd}, {0x0, 19, 0xfe}, {0x1, 16, 0xff},
+	},
+	15: {
+		{0x7, 3, 0x00}, {0xc, 4, 0x01}, {0x12, 5, 0x02}, {0x35, 7, 0x03}, {0x2f, 7, 0x04}, {0x4c, 8, 0x05},
+		{0x7c, 9, 0x06}, {0x6c, 9, 0x07}, {0x59, 9, 0x08}, {0x7b, 10, 0x09}, {0x6c, 10, 0x0a}, {0x77, 11, 0x0b},
+		{0x6b, 11, 0x0c}, {0x51, 11, 0x0d}, {0x7a, 12, 0x0e}, {0x3f, 13, 0x0f}, {0xd, 4, 0x10}, {0x5, 3, 0x11},
+		{0x10, 5, 0x12}, {0x1b, 6, 0x13}, {0x2e, 7, 0x14}, {0x24, 7, 0x15}, {0x3d, 8, 0x16}, {0x33, 8, 0x17},
+		{0x2a, 8, 0x18}, {0x46, 9, 0x19}, {0x34, 9, 0x1a}, {0x53, 10, 0x1b}, {0x41, 10, 0x1c}, {0x29, 10, 0x1d},
+		{0x3b, 11, 0x1e}, {0x24, 11, 0x1f}, {0x13, 5, 0x20}, {0x11, 5, 0x21}, {0xf, 5, 0x22}, {0x18, 6, 0x23},
+		{0x29, 7, 0x24}, {0x22, 7, 0x25}, {0x3b, 8, 0x26}, {0x30, 8, 0x27}, {0x28, 8, 0x28}, {0x40, 9, 0x29},
+		{0x32, 9, 0x2a}, {0x4e, 10, 0x2b}, {0x3e, 10, 0x2c}, {0x50, 11, 0x2d}, {0x38, 11, 0x2e}, {0x21, 11, 0x2f},
+		{0x1d, 6, 0x30}, {0x1c, 6, 0x31}, {0x19, 6, 0x32}, {0x2b, 7, 0x33}, {0x27, 7, 0x34}, {0x3f, 8, 0x35},
+		{0x37, 8, 0x36}, {0x5d, 9, 0x37}, {0x4c, 9, 0x38}, {0x3b, 9, 0x39}, {0x5d, 10, 0x3a}, {0x48, 10, 0x3b},
+		{0x36, 10, 0x3c}, {0x4b, 11, 0x3d}, {0x32, 11, 0x3e}, {0x1d, 11, 0x3f}, {0x34, 7, 0x40}, {0x16, 6, 0x41},
+		{0x2a, 7, 0x42}, {0x28, 7, 0x43}, {0x43, 8, 0x44}, {0x39, 8, 0x45}, {0x5f, 9, 0x46}, {0x4f, 9, 0x47},
+		{0x48, 9, 0x48}, {0x39, 9, 0x49}, {0x59, 10, 0x4a}, {0x45, 10, 0x4b}, {0x31, 10, 0x4c}, {0x42, 11, 0x4d},
+		{0x2e, 11, 0x4e}, {0x1b, 11, 0x4f}, {0x4d, 8, 0x50}, {0x25, 7, 0x51}, {0x23, 7, 0x52}, {0x42, 8, 0x53},
+		{0x3a, 8, 0x54}, {0x34, 8, 0x55}, {0x5b, 9, 0x56}, {0x4a, 9, 0x57}, {0x3e, 9, 0x58}, {0x30, 9, 0x59},
+		{0x4f, 10, 0x5a}, {0x3f, 10, 0x5b}, {0x5a, 11, 0x5c}, {0x3e, 11, 0x5d}, {0x28, 11, 0x5e}, {0x26, 12, 0x5f},
+		{0x7d, 9, 0x60}, {0x20, 7, 0x61}, {0x3c, 8, 0x62}, {0x38, 8, 0x63}, {0x32, 8, 0x64}, {0x5c, 9, 0x65},
+		{0x4e, 9, 0x66}, {0x41, 9, 0x67}, {0x37, 9, 0x68}, {0x57, 10, 0x69}, {0x47, 10, 0x6a}, {0x33, 10, 0x6b},
+		{0x49, 11, 0x6c}, {0x33, 11, 0x6d}, {0x46, 12, 0x6e}, {0x1e, 12, 0x6f}, {0x6d, 9, 0x70}, {0x35, 8, 0x71},
+		{0x31, 8, 0x72}, {0x5e, 9, 0x73}, {0x58, 9, 0x74}, {0x4b, 9, 0x75}, {0x42, 9, 0x76}, {0x7a, 10, 0x77},
+		{0x5b, 10, 0x78}, {0x49, 10, 0x79}, {0x38, 10, 0x7a}, {0x2a, 10, 0x7b}, {0x40, 11, 0x7c}, {0x2c, 11, 0x7d},
+		{0x15, 11, 0x7e}, {0x19, 12, 0x7f}, {0x5a, 9, 0x80}, {0x2b, 8, 0x81}, {0x29, 8, 0x82}, {0x4d, 9, 0x83},
+		{0x49, 9, 0x84}, {0x3f, 9, 0x85}, {0x38, 9, 0x86}, {0x5c, 10, 0x87}, {0x4d, 10, 0x88}, {0x42, 10, 0x89},
+		{0x2f, 10, 0x8a}, {0x43, 11, 0x8b}, {0x30, 11, 0x8c}, {0x35, 12, 0x8d}, {0x24, 12, 0x8e}, {0x14, 12, 0x8f},
+		{0x47, 9, 0x90}, {0x22, 8, 0x91}, {0x43, 9, 0x92}, {0x3c, 9, 0x93}, {0x3a, 9, 0x94}, {0x31, 9, 0x95},
+		{0x58, 10, 0x96}, {0x4c, 10, 0x97}, {0x43, 10, 0x98}, {0x6a, 11, 0x99}, {0x47, 11, 0x9a}, {0x36, 11, 0x9b},
+		{0x26, 11, 0x9c}, {0x27, 12, 0x9d}, {0x17, 12, 0x9e}, {0xf, 12, 0x9f}, {0x6d, 10, 0xa0}, {0x35, 9, 0xa1},
+		{0x33, 9, 0xa2}, {0x2f, 9, 0xa3}, {0x5a, 10, 0xa4}, {0x52, 10, 0xa5}, {0x3a, 10, 0xa6}, {0x39, 10, 0xa7},
+		{0x30, 10, 0xa8}, {0x48, 11, 0xa9}, {0x39, 11, 0xaa}, {0x29, 11, 0xab}, {0x17, 11, 0xac}, {0x1b, 12, 0xad},
+		{0x3e, 13, 0xae}, {0x9, 12, 0xaf}, {0x56, 10, 0xb0}, {0x2a, 9, 0xb1}, {0x28, 9, 0xb2}, {0x25, 9, 0xb3},
+		{0x46, 10, 0xb4}, {0x40, 10, 0xb5}, {0x34, 10, 0xb6}, {0x2b, 10, 0xb7}, {0x46, 11, 0xb8}, {0x37, 11, 0xb9},
+		{0x2a, 11, 0xba}, {0x19, 11, 0xbb}, {0x1d, 12, 0xbc}, {0x12, 12, 0xbd}, {0xb, 12, 0xbe}, {0xb, 13, 0xbf},
+		{0x76, 11, 0xc0}, {0x44, 10, 0xc1}, {0x1e, 9, 0xc2}, {0x37, 10, 0xc3}, {0x32, 10, 0xc4}, {0x2e, 10, 0xc5},
+		{0x4a, 11, 0xc6}, {0x41, 11, 0xc7}, {0x31, 11, 0xc8}, {0x27, 11, 0xc9}, {0x18, 11, 0xca}, {0x10, 11, 0xcb},
+		{0x16, 12, 0xcc}, {0xd, 12, 0xcd}, {0xe, 13, 0xce}, {0x7, 13, 0xcf}, {0x5b, 11, 0xd0}, {0x2c, 10, 0xd1},
+		{0x27, 10, 0xd2}, {0x26, 10, 0xd3}, {0x22, 10, 0xd4}, {0x3f, 11, 0xd5}, {0x34, 11, 0xd6}, {0x2d, 11, 0xd7},
+		{0x1f, 11, 0xd8}, {0x34, 12, 0xd9}, {0x1c, 12, 0xda}, {0x13, 12, 0xdb}, {0xe, 12, 0xdc}, {0x8, 12, 0xdd},
+		{0x9, 13, 0xde}, {0x3, 13, 0xdf}, {0x7b, 12, 0xe0}, {0x3c, 11, 0xe1}, {0x3a, 11, 0xe2}, {0x35, 11, 0xe3},
+		{0x2f, 11, 0xe4}, {0x2b, 11, 0xe5}, {0x20, 11, 0xe6}, {0x16, 11, 0xe7}, {0x25, 12, 0xe8}, {0x18, 12, 0xe9},
+		{0x11, 12, 0xea}, {0xc, 12, 0xeb}, {0xf, 13, 0xec}, {0xa, 13, 0xed}, {0x2, 12, 0xee}, {0x1, 13, 0xef},
+		{0x47, 12, 0xf0}, {0x25, 11, 0xf1}, {0x22, 11, 0xf2}, {0x1e, 11, 0xf3}, {0x1c, 11, 0xf4}, {0x14, 11, 0xf5},
+		{0x11, 11, 0xf6}, {0x1a, 12, 0xf7}, {0x15, 12, 0xf8}, {0x10, 12, 0xf9}, {0xa, 12, 0xfa}, {0x6, 12, 0xfb},
+		{0x8, 13, 0xfc}, {0x6, 13, 0xfd}, {0x2, 13, 0xfe}, {0x0, 13, 0xff},
+	},
+	16: {
+		{0x1, 1, 0x00}, {0x5, 4, 0x01}, {0xe, 6, 0x02}, {0x2c, 8, 0x03}, {0x4a, 9, 0x04}, {0x3f, 9, 0x05},
+		{0x6e, 10, 0x06}, {0x5d, 10, 0x07}, {0xac, 11, 0x08}, {0x95, 11, 0x09}, {0x8a, 11, 0x0a}, {0xf2, 12, 0x0b},
+		{0xe1, 12, 0x0c}, {0xc3, 12, 0x0d}, {0x178, 13, 0x0e}, {0x11, 9, 0x0f}, {0x3, 3, 0x10}, {0x4, 4, 0x11},
+		{0xc, 6, 0x12}, {0x14, 7, 0x13}, {0x23, 8, 0x14}, {0x3e, 9, 0x15}, {0x35, 9, 0x16}, {0x2f, 9, 0x17},
+		{0x53, 10, 0x18}, {0x4b, 10, 0x19}, {0x44, 10, 0x1a}, {0x77, 11, 0x1b}, {0xc9, 12, 0x1c}, {0x6b, 11, 0x1d},
+		{0xcf, 12, 0x1e}, {0x9, 8, 0x1f}, {0xf, 6, 0x20}, {0xd, 6, 0x21}, {0x17, 7, 0x22}, {0x26, 8, 0x23},
+		{0x43, 9, 0x24}, {0x3a, 9, 0x25}, {0x67, 10, 0x26}, {0x5a, 10, 0x27}, {0xa1, 11, 0x28}, {0x48, 10, 0x29},
+		{0x7f, 11, 0x2a}, {0x75, 11, 0x2b}, {0x6e, 11, 0x2c}, {0xd1, 12, 0x2d}, {0xce, 12, 0x2e}, {0x10, 9, 0x2f},
+		{0x2d, 8, 0x30}, {0x15, 7, 0x31}, {0x27, 8, 0x32}, {0x45, 9, 0x33}, {0x40, 9, 0x34}, {0x72, 10, 0x35},
+		{0x63, 10, 0x36}, {0x57, 10, 0x37}, {0x9e, 11, 0x38}, {0x8c, 11, 0x39}, {0xfc, 12, 0x3a}, {0xd4, 12, 0x3b},
+		{0xc7, 12, 0x3c}, {0x183, 13, 0x3d}, {0x16d, 13, 0x3e}, {0x1a, 10, 0x3f}, {0x4b, 9, 0x40}, {0x24, 8, 0x41},
+		{0x44, 9, 0x42}, {0x41, 9, 0x43}, {0x73, 10, 0x44}, {0x65, 10, 0x45}, {0xb3, 11, 0x46}, {0xa4, 11, 0x47},
+		{0x9b, 11, 0x48}, {0x108, 12, 0x49}, {0xf6, 12, 0x4a}, {0xe2, 12, 0x4b}, {0x18b, 13, 0x4c},
+		{0x17e, 13, 0x4d}, {0x16a, 13, 0x4e}, {0x9, 9, 0x4f}, {0x42, 9, 0x50}, {0x1e, 8, 0x51}, {0x3b, 9, 0x52},
+		{0x38, 9, 0x53}, {0x66, 10, 0x54}, {0xb9, 11, 0x55}, {0xad, 11, 0x56}, {0x109, 12, 0x57}, {0x8e, 11, 0x58},
+		{0xfd, 12, 0x59}, {0xe8, 12, 0x5a}, {0x190, 13, 0x5b}, {0x184, 13, 0x5c}, {0x17a, 13, 0x5d},
+		{0x1bd, 14, 0x5e}, {0x10, 10, 0x5f}, {0x6f, 10, 0x60}, {0x36, 9, 0x61}, {0x34, 9, 0x62}, {0x64, 10, 0x63},
+		{0xb8, 11, 0x64}, {0xb2, 11, 0x65}, {0xa0, 11, 0x66}, {0x85, 11, 0x67}, {0x101, 12, 0x68},
+		{0xf4, 12, 0x69}, {0xe4, 12, 0x6a}, {0xd9, 12, 0x6b}, {0x181, 13, 0x6c}, {0x16e, 13, 0x6d},
+		{0x2cb, 14, 0x6e}, {0xa, 10, 0x6f}, {0x62, 10, 0x70}, {0x30, 9, 0x71}, {0x5b, 10, 0x72}, {0x58, 10, 0x73},
+		{0xa5, 11, 0x74}, {0x9d, 11, 0x75}, {0x94, 11, 0x76}, {0x105, 12, 0x77}, {0xf8, 12, 0x78},
+		{0x197, 13, 0x79}, {0x18d, 13, 0x7a}, {0x174, 13, 0x7b}, {0x17c, 13, 0x7c}, {0x379, 15, 0x7d},
+		{0x374, 15, 0x7e}, {0x8, 10, 0x7f}, {0x55, 10, 0x80}, {0x54, 10, 0x81}, {0x51, 10, 0x82}, {0x9f, 11, 0x83},
+		{0x9c, 11, 0x84}, {0x8f, 11, 0x85}, {0x104, 12, 0x86}, {0xf9, 12, 0x87}, {0x1ab, 13, 0x88},
+		{0x191, 13, 0x89}, {0x188, 13, 0x8a}, {0x17f, 13, 0x8b}, {0x2d7, 14, 0x8c}, {0x2c9, 14, 0x8d},
+		{0x2c4, 14, 0x8e}, {0x7, 10, 0x8f}, {0x9a, 11, 0x90}, {0x4c, 10, 0x91}, {0x49, 10, 0x92}, {0x8d, 11, 0x93},
+		{0x83, 11, 0x94}, {0x100, 12, 0x95}, {0xf5, 12, 0x96}, {0x1aa, 13, 0x97}, {0x196, 13, 0x98},
+		{0x18a, 13, 0x99}, {0x180, 13, 0x9a}, {0x2df, 14, 0x9b}, {0x167, 13, 0x9c}, {0x2c6, 14, 0x9d},
+		{0x160, 13, 0x9e}, {0xb, 11, 0x9f}, {0x8b, 11, 0xa0}, {0x81, 11, 0xa1}, {0x43, 10, 0xa2}, {0x7d, 11, 0xa3},
+		{0xf7, 12, 0xa4}, {0xe9, 12, 0xa5}, {0xe5, 12, 0xa6}, {0xdb, 12, 0xa7}, {0x189, 13, 0xa8},
+		{0x2e7, 14, 0xa9}, {0x2e1, 14, 0xaa}, {0x2d0, 14, 0xab}, {0x375, 15, 0xac}, {0x372, 15, 0xad},
+		{0x1b7, 14, 0xae}, {0x4, 10, 0xaf}, {0xf3, 12, 0xb0}, {0x78, 11, 0xb1}, {0x76, 11, 0xb2}, {0x73, 11, 0xb3},
+		{0xe3, 12, 0xb4}, {0xdf, 12, 0xb5}, {0x18c, 13, 0xb6}, {0x2ea, 14, 0xb7}, {0x2e6, 14, 0xb8},
+		{0x2e0, 14, 0xb9}, {0x2d1, 14, 0xba}, {0x2c8, 14, 0xbb}, {0x2c2, 14, 0xbc}, {0xdf, 13, 0xbd},
+		{0x1b4, 14, 0xbe}, {0x6, 11, 0xbf}, {0xca, 12, 0xc0}, {0xe0, 12, 0xc1}, {0xde, 12, 0xc2}, {0xda, 12, 0xc3},
+		{0xd8, 12, 0xc4}, {0x185, 13, 0xc5}, {0x182, 13, 0xc6}, {0x17d, 13, 0xc7}, {0x16c, 13, 0xc8},
+		{0x378, 15, 0xc9}, {0x1bb, 14, 0xca}, {0x2c3, 14, 0xcb}, {0x1b8, 14, 0xcc}, {0x1b5, 14, 0xcd},
+		{0x6c0, 16, 0xce}, {0x4, 11, 0xcf}, {0x2eb, 14, 0xd0}, {0xd3, 12, 0xd1}, {0xd2, 12, 0xd2},
+		{0xd0, 12, 0xd3}, {0x172, 13, 0xd4}, {0x17b, 13, 0xd5}, {0x2de, 14, 0xd6}, {0x2d3, 14, 0xd7},
+		{0x2ca, 14, 0xd8}, {0x6c7, 16, 0xd9}, {0x373, 15, 0xda}, {0x36d, 15, 0xdb}, {0x36c, 15, 0xdc},
+		{0xd83, 17, 0xdd}, {0x361, 15, 0xde}, {0x2, 11, 0xdf}, {0x179, 13, 0xe0}, {0x171, 13, 0xe1},
+		{0x66, 11, 0xe2}, {0xbb, 12, 0xe3}, {0x2d6, 14, 0xe4}, {0x2d2, 14, 0xe5}, {0x166, 13, 0xe6},
+		{0x2c7, 14, 0xe7}, {0x2c5, 14, 0xe8}, {0x362, 15, 0xe9}, {0x6c6, 16, 0xea}, {0x367, 15, 0xeb},
+		{0xd82, 17, 0xec}, {0x366, 15, 0xed}, {0x1b2, 14, 0xee}, {0x0, 11, 0xef}, {0xc, 9, 0xf0}, {0xa, 8, 0xf1},
+		{0x7, 8, 0xf2}, {0xb, 9, 0xf3}, {0xa, 9, 0xf4}, {0x11, 10, 0xf5}, {0xb, 10, 0xf6}, {0x9, 10, 0xf7},
+		{0xd, 11, 0xf8}, {0xc, 11, 0xf9}, {0xa, 11, 0xfa}, {0x7, 11, 0xfb}, {0x5, 11, 0xfc}, {0x3, 11, 0xfd},
+		{0x1, 11, 0xfe}, {0x3, 8, 0xff},
+	},
+	24: {
+		{0xf, 4, 0x00}, {0xd, 4, 0x01}, {0x2e, 6, 0x02}, {0x50, 7, 0x03}, {0x92, 8, 0x04}, {0x106, 9, 0x05},
+		{0xf8, 9, 0x06}, {0x1b2, 10, 0x07}, {0x1aa, 10, 0x08}, {0x29d, 11, 0x09}, {0x28d, 11, 0x0a},
+		{0x289, 11, 0x0b}, {0x26d, 11, 0x0c}, {0x205, 11, 0x0d}, {0x408, 12, 0x0e}, {0x58, 9, 0x0f},
+		{0xe, 4, 0x10}, {0xc, 4, 0x11}, {0x15, 5, 0x12}, {0x26, 6, 0x13}, {0x47, 7, 0x14}, {0x82, 8, 0x15},
+		{0x7a, 8, 0x16}, {0xd8, 9, 0x17}, {0xd1, 9, 0x18}, {0xc6, 9, 0x19}, {0x147, 10, 0x1a}, {0x159, 10, 0x1b},
+		{0x13f, 10, 0x1c}, {0x129, 10, 0x1d}, {0x117, 10, 0x1e}, {0x2a, 8, 0x1f}, {0x2f, 6, 0x20}, {0x16, 5, 0x21},
+		{0x29, 6, 0x22}, {0x4a, 7, 0x23}, {0x44, 7, 0x24}, {0x80, 8, 0x25}, {0x78, 8, 0x26}, {0xdd, 9, 0x27},
+		{0xcf, 9, 0x28}, {0xc2, 9, 0x29}, {0xb6, 9, 0x2a}, {0x154, 10, 0x2b}, {0x13b, 10, 0x2c}, {0x127, 10, 0x2d},
+		{0x21d, 11, 0x2e}, {0x12, 7, 0x2f}, {0x51, 7, 0x30}, {0x27, 6, 0x31}, {0x4b, 7, 0x32}, {0x46, 7, 0x33},
+		{0x86, 8, 0x34}, {0x7d, 8, 0x35}, {0x74, 8, 0x36}, {0xdc, 9, 0x37}, {0xcc, 9, 0x38}, {0xbe, 9, 0x39},
+		{0xb2, 9, 0x3a}, {0x145, 10, 0x3b}, {0x137, 10, 0x3c}, {0x125, 10, 0x3d}, {0x10f, 10, 0x3e},
+		{0x10, 7, 0x3f}, {0x93, 8, 0x40}, {0x48, 7, 0x41}, {0x45, 7, 0x42}, {0x87, 8, 0x43}, {0x7f, 8, 0x44},
+		{0x76, 8, 0x45}, {0x70, 8, 0x46}, {0xd2, 9, 0x47}, {0xc8, 9, 0x48}, {0xbc, 9, 0x49}, {0x160, 10, 0x4a},
+		{0x143, 10, 0x4b}, {0x132, 10, 0x4c}, {0x11d, 10, 0x4d}, {0x21c, 11, 0x4e}, {0xe, 7, 0x4f},
+		{0x107, 9, 0x50}, {0x42, 7, 0x51}, {0x81, 8, 0x52}, {0x7e, 8, 0x53}, {0x77, 8, 0x54}, {0x72, 8, 0x55},
+		{0xd6, 9, 0x56}, {0xca, 9, 0x57}, {0xc0, 9, 0x58}, {0xb4, 9, 0x59}, {0x155, 10, 0x5a}, {0x13d, 10, 0x5b},
+		{0x12d, 10, 0x5c}, {0x119, 10, 0x5d}, {0x106, 10, 0x5e}, {0xc, 7, 0x5f}, {0xf9, 9, 0x60}, {0x7b, 8, 0x61},
+		{0x79, 8, 0x62}, {0x75, 8, 0x63}, {0x71, 8, 0x64}, {0xd7, 9, 0x65}, {0xce, 9, 0x66}, {0xc3, 9, 0x67},
+		{0xb9, 9, 0x68}, {0x15b, 10, 0x69}, {0x14a, 10, 0x6a}, {0x134, 10, 0x6b}, {0x123, 10, 0x6c},
+		{0x110, 10, 0x6d}, {0x208, 11, 0x6e}, {0xa, 7, 0x6f}, {0x1b3, 10, 0x70}, {0x73, 8, 0x71}, {0x6f, 8, 0x72},
+		{0x6d, 8, 0x73}, {0xd3, 9, 0x74}, {0xcb, 9, 0x75}, {0xc4, 9, 0x76}, {0xbb, 9, 0x77}, {0x161, 10, 0x78},
+		{0x14c, 10, 0x79}, {0x139, 10, 0x7a}, {0x12a, 10, 0x7b}, {0x11b, 10, 0x7c}, {0x213, 11, 0x7d},
+		{0x17d, 11, 0x7e}, {0x11, 8, 0x7f}, {0x1ab, 10, 0x80}, {0xd4, 9, 0x81}, {0xd0, 9, 0x82}, {0xcd, 9, 0x83},
+		{0xc9, 9, 0x84}, {0xc1, 9, 0x85}, {0xba, 9, 0x86}, {0xb1, 9, 0x87}, {0xa9, 9, 0x88}, {0x140, 10, 0x89},
+		{0x12f, 10, 0x8a}, {0x11e, 10, 0x8b}, {0x10c, 10, 0x8c}, {0x202, 11, 0x8d}, {0x179, 11, 0x8e},
+		{0x10, 8, 0x8f}, {0x14f, 10, 0x90}, {0xc7, 9, 0x91}, {0xc5, 9, 0x92}, {0xbf, 9, 0x93}, {0xbd, 9, 0x94},
+		{0xb5, 9, 0x95}, {0xae, 9, 0x96}, {0x14d, 10, 0x97}, {0x141, 10, 0x98}, {0x131, 10, 0x99},
+		{0x121, 10, 0x9a}, {0x113, 10, 0x9b}, {0x209, 11, 0x9c}, {0x17b, 11, 0x9d}, {0x173, 11, 0x9e},
+		{0xb, 8, 0x9f}, {0x29c, 11, 0xa0}, {0xb8, 9, 0xa1}, {0xb7, 9, 0xa2}, {0xb3, 9, 0xa3}, {0xaf, 9, 0xa4},
+		{0x158, 10, 0xa5}, {0x14b, 10, 0xa6}, {0x13a, 10, 0xa7}, {0x130, 10, 0xa8}, {0x122, 10, 0xa9},
+		{0x115, 10, 0xaa}, {0x212, 11, 0xab}, {0x17f, 11, 0xac}, {0x175, 11, 0xad}, {0x16e, 11, 0xae},
+		{0xa, 8, 0xaf}, {0x28c, 11, 0xb0}, {0x15a, 10, 0xb1}, {0xab, 9, 0xb2}, {0xa8, 9, 0xb3}, {0xa4, 9, 0xb4},
+		{0x13e, 10, 0xb5}, {0x135, 10, 0xb6}, {0x12b, 10, 0xb7}, {0x11f, 10, 0xb8}, {0x114, 10, 0xb9},
+		{0x107, 10, 0xba}, {0x201, 11, 0xbb}, {0x177, 11, 0xbc}, {0x170, 11, 0xbd}, {0x16a, 11, 0xbe},
+		{0x6, 8, 0xbf}, {0x288, 11, 0xc0}, {0x142, 10, 0xc1}, {0x13c, 10, 0xc2}, {0x138, 10, 0xc3},
+		{0x133, 10, 0xc4}, {0x12e, 10, 0xc5}, {0x124, 10, 0xc6}, {0x11c, 10, 0xc7}, {0x10d, 10, 0xc8},
+		{0x105, 10, 0xc9}, {0x200, 11, 0xca}, {0x178, 11, 0xcb}, {0x172, 11, 0xcc}, {0x16c, 11, 0xcd},
+		{0x167, 11, 0xce}, {0x4, 8, 0xcf}, {0x26c, 11, 0xd0}, {0x12c, 10, 0xd1}, {0x128, 10, 0xd2},
+		{0x126, 10, 0xd3}, {0x120, 10, 0xd4}, {0x11a, 10, 0xd5}, {0x111, 10, 0xd6}, {0x10a, 10, 0xd7},
+		{0x203, 11, 0xd8}, {0x17c, 11, 0xd9}, {0x176, 11, 0xda}, {0x171, 11, 0xdb}, {0x16d, 11, 0xdc},
+		{0x169, 11, 0xdd}, {0x165, 11, 0xde}, {0x2, 8, 0xdf}, {0x409, 12, 0xe0}, {0x118, 10, 0xe1},
+		{0x116, 10, 0xe2}, {0x112, 10, 0xe3}, {0x10b, 10, 0xe4}, {0x108, 10, 0xe5}, {0x103, 10, 0xe6},
+		{0x17e, 11, 0xe7}, {0x17a, 11, 0xe8}, {0x174, 11, 0xe9}, {0x16f, 11, 0xea}, {0x16b, 11, 0xeb},
+		{0x168, 11, 0xec}, {0x166, 11, 0xed}, {0x164, 11, 0xee}, {0x0, 8, 0xef}, {0x2b, 8, 0xf0}, {0x14, 7, 0xf1},
+		{0x13, 7, 0xf2}, {0x11, 7, 0xf3}, {0xf, 7, 0xf4}, {0xd, 7, 0xf5}, {0xb, 7, 0xf6}, {0x9, 7, 0xf7},
+		{0x7, 7, 0xf8}, {0x6, 7, 0xf9}, {0x4, 7, 0xfa}, {0x7, 8, 0xfb}, {0x5, 8, 0xfc}, {0x3, 8, 0xfd},
+		{0x1, 8, 0xfe}, {0x3, 4, 0xff},
+	},
+	32: {
+		{0x1, 1, 0x00}, {0x5, 4, 0x01}, {0x4, 4, 0x02}, {0x5, 5, 0x03}, {0x6, 4, 0x04}, {0x5, 6, 0x05},
+		{0x4, 5, 0x06}, {0x4, 6, 0x07}, {0x7, 4, 0x08}, {0x3, 5, 0x09}, {0x6, 5, 0x0a}, {0x0, 6, 0x0b},
+		{0x7, 5, 0x0c}, {0x2, 6, 0x0d}, {0x3, 6, 0x0e}, {0x1, 6, 0x0f},
+	},
+	33: {
+		{0xf, 4, 0x00}, {0xe, 4, 0x01}, {0xd, 4, 0x02}, {0xc, 4, 0x03}, {0xb, 4, 0x04}, {0xa, 4, 0x05},
+		{0x9, 4, 0x06}, {0x8, 4, 0x07}, {0x7, 4, 0x08}, {0x6, 4, 0x09}, {0x5, 4, 0x0a}, {0x4, 4, 0x0b},
+		{0x3, 4, 0x0c}, {0x2, 4, 0x0d}, {0x1, 4, 0x0e}, {0x0, 4, 0x0f},
+	},
+}
+
+// mp3LongBands、mp3ShortBands 为长块 / 短块的缩放因子频带边界，按采样率序号
+// （44100、48000、32000、22050、24000、16000、11025、12000、8000）排列
+var mp3LongBands = [9][23]int{
+	{0, 4, 8, 12, 16, 20, 24, 30, 36, 44, 52, 62, 74, 90, 110, 134, 162, 196, 238, 288, 342, 418, 576},         // 44100
+	{0, 4, 8, 12, 16, 20, 24, 30, 36, 42, 50, 60, 72, 88, 106, 128, 156, 190, 230, 276, 330, 384, 576},         // 48000
+	{0, 4, 8, 12, 16, 20, 24, 30, 36, 44, 54, 66, 82, 102, 126, 156, 194, 240, 296, 364, 448, 550, 576},        // 32000
+	{0, 6, 12, 18, 24, 30, 36, 44, 54, 66, 80, 96, 116, 140, 168, 200, 238, 284, 336, 396, 464, 522, 576},      // 22050
+	{0, 6, 12, 18, 24, 30, 36, 44, 54, 66, 80, 96, 114, 136, 162, 194, 232, 278, 332, 394, 464, 540, 576},      // 24000
+	{0, 6, 12, 18, 24, 30, 36, 44, 54, 66, 80, 96, 116, 140, 168, 200, 238, 284, 336, 396, 464, 522, 576},      // 16000
+	{0, 6, 12, 18, 24, 30, 36, 44, 54, 66, 80, 96, 116, 140, 168, 200, 238, 284, 336, 396, 464, 522, 576},      // 11025
+	{0, 6, 12, 18, 24, 30, 36, 44, 54, 66, 80, 96, 116, 140, 168, 200, 238, 284, 336, 396, 464, 522, 576},      // 12000
+	{0, 12, 24, 36, 48, 60, 72, 88, 108, 132, 160, 192, 232, 280, 336, 400, 476, 566, 568, 570, 572, 574, 576}, // 8000
+}
+
+var mp3ShortBands = [9][14]int{
+	{0, 4, 8, 12, 16, 22, 30, 40, 52, 66, 84, 106, 136, 192},     // 44100
+	{0, 4, 8, 12, 16, 22, 28, 38, 50, 64, 80, 100, 126, 192},     // 48000
+	{0, 4, 8, 12, 16, 22, 30, 42, 58, 78, 104, 138, 180, 192},    // 32000
+	{0, 4, 8, 12, 18, 24, 32, 42, 56, 74, 100, 132, 174, 192},    // 22050
+	{0, 4, 8, 12, 18, 26, 36, 48, 62, 80, 104, 136, 180, 192},    // 24000
+	{0, 4, 8, 12, 18, 26, 36, 48, 62, 80, 104, 134, 174, 192},    // 16000
+	{0, 4, 8, 12, 18, 26, 36, 48, 62, 80, 104, 134, 174, 192},    // 11025
+	{0, 4, 8, 12, 18, 26, 36, 48, 62, 80, 104, 134, 174, 192},    // 12000
+	{0, 8, 16, 24, 36, 52, 72, 96, 124, 160, 162, 164, 166, 192}, // 8000
+}
+
+// mp3SynthWindow 为合成滤波器组的窗口系数 D[i]
+var mp3SynthWindow = [512]float64{
+	0.000000000, -0.000015259, -0.000015259, -0.000015259, -0.000015259, -0.000015259, -0.000015259, -0.000030518,
+	-0.000030518, -0.000030518, -0.000030518, -0.000045776, -0.000045776, -0.000061035, -0.000061035, -0.000076294,
+	-0.000076294, -0.000091553, -0.000106812, -0.000106812, -0.000122070, -0.000137329, -0.000152588, -0.000167847,
+	-0.000198364, -0.000213623, -0.000244141, -0.000259399, -0.000289917, -0.000320435, -0.000366211, -0.000396729,
+	-0.000442505, -0.000473022, -0.000534058, -0.000579834, -0.000625610, -0.000686646, -0.000747681, -0.000808716,
+	-0.000885010, -0.000961304, -0.001037598, -0.001113892, -0.001205444, -0.001296997, -0.001388550, -0.001480103,
+	-0.001586914, -0.001693726, -0.001785278, -0.001907349, -0.002014160, -0.002120972, -0.002243042, -0.002349854,
+	-0.002456665, -0.002578735, -0.002685547, -0.002792358, -0.002899170, -0.002990723, -0.003082275, -0.003173828,
+	0.003250122, 0.003326416, 0.003387451, 0.003433228, 0.003463745, 0.003479004, 0.003479004, 0.003463745,
+	0.003417969, 0.003372192, 0.003280640, 0.003173828, 0.003051758, 0.002883911, 0.002700806, 0.002487183,
+	0.002227783, 0.001937866, 0.001617432, 0.001266479, 0.000869751, 0.000442505, -0.000030518, -0.000549316,
+	-0.001098633, -0.001693726, -0.002334595, -0.003005981, -0.003723145, -0.004486084, -0.005294800, -0.006118774,
+	-0.007003784, -0.007919312, -0.008865356, -0.009841919, -0.010848999, -0.011886597, -0.012939453, -0.014022827,
+	-0.015121460, -0.016235352, -0.017349243, -0.018463135, -0.019577026, -0.020690918, -0.021789551, -0.022857666,
+	-0.023910522, -0.024932861, -0.025909424, -0.026840210, -0.027725220, -0.028533936, -0.029281616, -0.029937744,
+	-0.030532837, -0.031005859, -0.031387329, -0.031661987, -0.031814575, -0.031845093, -0.031738281, -0.031478882,
+	0.031082153, 0.030517578, 0.029785156, 0.028884888, 0.027801514, 0.026535034, 0.025085449, 0.023422241,
+	0.021575928, 0.019531250, 0.017257690, 0.014801025, 0.012115479, 0.009231567, 0.006134033, 0.002822876,
+	-0.000686646, -0.004394531, -0.008316040, -0.012420654, -0.016708374, -0.021179199, -0.025817871, -0.030609131,
+	-0.035552979, -0.040634155, -0.045837402, -0.051132202, -0.056533813, -0.061996460, -0.067520142, -0.073059082,
+	-0.078628540, -0.084182739, -0.089706421, -0.095169067, -0.100540161, -0.105819702, -0.110946655, -0.115921021,
+	-0.120697021, -0.125259399, -0.129562378, -0.133590698, -0.137298584, -0.140670776, -0.143676758, -0.146255493,
+	-0.148422241, -0.150115967, -0.151306152, -0.151962280, -0.152069092, -0.151596069, -0.150497437, -0.148773193,
+	-0.146362305, -0.143264771, -0.139450073, -0.134887695, -0.129577637, -0.123474121, -0.116577148, -0.108856201,
+	0.100311279, 0.090927124, 0.080688477, 0.069595337, 0.057617188, 0.044784546, 0.031082153, 0.016510010,
+	0.001068115, -0.015228271, -0.032379150, -0.050354004, -0.069168091, -0.088775635, -0.109161377, -0.130310059,
+	-0.152206421, -0.174789429, -0.198059082, -0.221984863, -0.246505737, -0.271591187, -0.297210693, -0.323318481,
+	-0.349868774, -0.376800537, -0.404083252, -0.431655884, -0.459472656, -0.487472534, -0.515609741, -0.543823242,
+	-0.572036743, -0.600219727, -0.628295898, -0.656219482, -0.683914185, -0.711318970, -0.738372803, -0.765029907,
+	-0.791213989, -0.816864014, -0.841949463, -0.866363525, -0.890090942, -0.913055420, -0.935195923, -0.956481934,
+	-0.976852417, -0.996246338, -1.014617920, -1.031936646, -1.048156738, -1.063217163, -1.077117920, -1.089782715,
+	-1.101211548, -1.111373901, -1.120223999, -1.127746582, -1.133926392, -1.138763428, -1.142211914, -1.144287109,
+	1.144989014, 1.144287109, 1.142211914, 1.138763428, 1.133926392, 1.127746582, 1.120223999, 1.111373901,
+	1.101211548, 1.089782715, 1.077117920, 1.063217163, 1.048156738, 1.031936646, 1.014617920, 0.996246338,
+	0.976852417, 0.956481934, 0.935195923, 0.913055420, 0.890090942, 0.866363525, 0.841949463, 0.816864014,
+	0.791213989, 0.765029907, 0.738372803, 0.711318970, 0.683914185, 0.656219482, 0.628295898, 0.600219727,
+	0.572036743, 0.543823242, 0.515609741, 0.487472534, 0.459472656, 0.431655884, 0.404083252, 0.376800537,
+	0.349868774, 0.323318481, 0.297210693, 0.271591187, 0.246505737, 0.221984863, 0.198059082, 0.174789429,
+	0.152206421, 0.130310059, 0.109161377, 0.088775635, 0.069168091, 0.050354004, 0.032379150, 0.015228271,
+	-0.001068115, -0.016510010, -0.031082153, -0.044784546, -0.057617188, -0.069595337, -0.080688477, -0.090927124,
+	0.100311279, 0.108856201, 0.116577148, 0.123474121, 0.129577637, 0.134887695, 0.139450073, 0.143264771,
+	0.146362305, 0.148773193, 0.150497437, 0.151596069, 0.152069092, 0.151962280, 0.151306152, 0.150115967,
+	0.148422241, 0.146255493, 0.143676758, 0.140670776, 0.137298584, 0.133590698, 0.129562378, 0.125259399,
+	0.120697021, 0.115921021, 0.110946655, 0.105819702, 0.100540161, 0.095169067, 0.089706421, 0.084182739,
+	0.078628540, 0.073059082, 0.067520142, 0.061996460, 0.056533813, 0.051132202, 0.045837402, 0.040634155,
+	0.035552979, 0.030609131, 0.025817871, 0.021179199, 0.016708374, 0.012420654, 0.008316040, 0.004394531,
+	0.000686646, -0.002822876, -0.006134033, -0.009231567, -0.012115479, -0.014801025, -0.017257690, -0.019531250,
+	-0.021575928, -0.023422241, -0.025085449, -0.026535034, -0.027801514, -0.028884888, -0.029785156, -0.030517578,
+	0.031082153, 0.031478882, 0.031738281, 0.031845093, 0.031814575, 0.031661987, 0.031387329, 0.031005859,
+	0.030532837, 0.029937744, 0.029281616, 0.028533936, 0.027725220, 0.026840210, 0.025909424, 0.024932861,
+	0.023910522, 0.022857666, 0.021789551, 0.020690918, 0.019577026, 0.018463135, 0.017349243, 0.016235352,
+	0.015121460, 0.014022827, 0.012939453, 0.011886597, 0.010848999, 0.009841919, 0.008865356, 0.007919312,
+	0.007003784, 0.006118774, 0.005294800, 0.004486084, 0.003723145, 0.003005981, 0.002334595, 0.001693726,
+	0.001098633, 0.000549316, 0.000030518, -0.000442505, -0.000869751, -0.001266479, -0.001617432, -0.001937866,
+	-0.002227783, -0.002487183, -0.002700806, -0.002883911, -0.003051758, -0.003173828, -0.003280640, -0.003372192,
+	-0.003417969, -0.003463745, -0.003479004, -0.003479004, -0.003463745, -0.003433228, -0.003387451, -0.003326416,
+	0.003250122, 0.003173828, 0.003082275, 0.002990723, 0.002899170, 0.002792358, 0.002685547, 0.002578735,
+	0.002456665, 0.002349854, 0.002243042, 0.002120972, 0.002014160, 0.001907349, 0.001785278, 0.001693726,
+	0.001586914, 0.001480103, 0.001388550, 0.001296997, 0.001205444, 0.001113892, 0.001037598, 0.000961304,
+	0.000885010, 0.000808716, 0.000747681, 0.000686646, 0.000625610, 0.000579834, 0.000534058, 0.000473022,
+	0.000442505, 0.000396729, 0.000366211, 0.000320435, 0.000289917, 0.000259399, 0.000244141, 0.000213623,
+	0.000198364, 0.000167847, 0.000152588, 0.000137329, 0.000122070, 0.000106812, 0.000106812, 0.000091553,
+	0.000076294, 0.000076294, 0.000061035, 0.000061035, 0.000045776, 0.000045776, 0.000030518, 0.000030518,
+	0.000030518, 0.000030518, 0.000015259, 0.000015259, 0.000015259, 0.000015259, 0.000015259, 0.000015259,
+}
@@ -0,0 +1,232 @@
+// file: internal/fingerprint/native.go
+// package: fingerprint
+//
+// 原生（纯 Go）解码后端：常见的无需专利解码器的格式（WAV、FLAC）直接在进程内解码，
+// 不必启动 ffmpeg；原生解码器不支持的格式（MP3、AAC、Ogg 等）仍交给 ffmpeg。
+// 解码结果同样转换为 8kHz 单声道 int16，与 ffmpeg 后端的输出可直接比较（重采样方式不同，
+// 同一文件两种后端的指纹可能相差一两位，远小于常用阈值）。
+package fingerprint
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"deduplicateMusic/internal/formats"
+	"deduplicateMusic/internal/quota"
+)
+
+// Backend 选择解码后端
+type Backend string
+
+const (
+	BackendAuto   Backend = "auto"   // 原生解码器支持的格式在进程内解码，其余（或原生解码失败时）使用 ffmpeg
+	BackendNative Backend = "native" // 只使用原生解码器，不调用 ffmpeg
+	BackendFFmpeg Backend = "ffmpeg" // 总是调用 ffmpeg（旧行为）
+)
+
+// NativeDecoder 解码 r，按顺序把单声道样本（多声道取平均，按 16 位满幅缩放）与采样率交给 emit；
+// emit 返回 false 时解码器应立即停止并返回 nil。
+type NativeDecoder func(r io.Reader, emit func(rate int, mono []int16) bool) error
+
+type nativeFormat struct {
+	name   string
+	sniff  func(head []byte) bool
+	decode NativeDecoder
+}
+
+var (
+	nativeMu      sync.RWMutex
+	nativeFormats []nativeFormat
+	backend       = BackendAuto
+)
+
+// sniffLen 为识别格式时预读的字节数
+const sniffLen = 12
+
+func init() {
+	for _, n := range []struct {
+		name string
+		d    NativeDecoder
+	}{{"wav", decodeWAV}, {"flac", decodeFLAC}} {
+		f, _ := formats.Lookup(n.name)
+		RegisterNativeDecoder(n.name, f.Sniff, n.d)
+	}
+}
+
+// RegisterNativeDecoder 注册原生解码器：sniff 根据文件开头 12 字节判断是否支持该格式。
+// 按注册顺序匹配，先注册者优先；同时在格式注册表中把 name 标记为可原生解码。
+func RegisterNativeDecoder(name string, sniff func(head []byte) bool, d NativeDecoder) {
+	nativeMu.Lock()
+	defer nativeMu.Unlock()
+	nativeFormats = append(nativeFormats, nativeFormat{name: name, sniff: sniff, decode: d})
+	formats.MarkNative(name)
+}
+
+// NativeFormats 返回已注册的原生解码格式名
+func NativeFormats() []string {
+	nativeMu.RLock()
+	defer nativeMu.RUnlock()
+	names := make([]string, len(nativeFormats))
+	for i, f := range nativeFormats {
+		names[i] = f.name
+	}
+	return names
+}
+
+// SetBackend 设置解码后端（应在开始解码前调用）
+func SetBackend(b Backend) error {
+	switch b {
+	case BackendAuto, BackendNative, BackendFFmpeg:
+	default:
+		return fmt.Errorf("未知的解码后端 %q（可选 auto、native、ffmpeg）", b)
+	}
+	nativeMu.Lock()
+	backend = b
+	nativeMu.Unlock()
+	return nil
+}
+
+func currentBackend() Backend {
+	nativeMu.RLock()
+	defer nativeMu.RUnlock()
+	return backend
+}
+
+// BackendSummary 描述当前将使用的解码后端（用于启动时打印）
+func BackendSummary() string {
+	native := strings.Join(NativeFormats(), "、")
+	_, err := exec.LookPath("ffmpeg")
+	hasFFmpeg := err == nil
+	switch currentBackend() {
+	case BackendNative:
+		return fmt.Sprintf("仅原生解码（%s），其它格式将报错", native)
+	case BackendFFmpeg:
+		if !hasFFmpeg {
+			return "ffmpeg（未找到 ffmpeg，解码将失败）"
+		}
+		return "ffmpeg"
+	}
+	if !hasFFmpeg {
+		if fb := fallbackSummary(); fb != "" {
+			return fmt.Sprintf("原生解码（%s）；未找到 ffmpeg，使用内置后备解码器 %s，其它格式将失败", native, fb)
+		}
+		return fmt.Sprintf("原生解码（%s）；未找到 ffmpeg，其它格式将失败", native)
+	}
+	return fmt.Sprintf("原生解码（%s），其余格式使用 ffmpeg", native)
+}
+
+// lookupNative 返回能处理 head 的原生解码器
+func lookupNative(head []byte) (nativeFormat, bool) {
+	nativeMu.RLock()
+	defer nativeMu.RUnlock()
+	for _, f := range nativeFormats {
+		if f.sniff(head) {
+			return f, true
+		}
+	}
+	return nativeFormat{}, false
+}
+
+// decodeNative 尝试用原生解码器解码 input（stdin 非 nil 时从 stdin 读取）。
+// handled 为 false 表示没有匹配的原生解码器，此时 rest 为可交给 ffmpeg 的输入（已预读的字节不会丢失）。
+func decodeNative(input string, stdin io.Reader, seconds int, skip time.Duration) (samples []int16, handled bool, rest io.Reader, err error) {
+	var src io.Reader = stdin
+	if stdin == nil {
+		// 扩展名表明原生解码器不支持时不必打开文件探测（auto 后端直接交给 ffmpeg）
+		if f, ok := formats.ByPath(input); ok && !f.Native && currentBackend() == BackendAuto {
+			return nil, false, nil, nil
+		}
+		f, err := os.Open(input)
+		if err != nil {
+			return nil, true, nil, err
+		}
+		defer f.Close()
+		src = quota.Reader(f)
+	}
+	br := bufio.NewReaderSize(src, 64<<10)
+	head, _ := br.Peek(sniffLen)
+	format, ok := lookupNative(head)
+	if !ok {
+		if stdin == nil {
+			return nil, false, nil, nil
+		}
+		return nil, false, br, nil
+	}
+
+	samples, err = collectNative(format.decode, br, seconds, skip)
+	if err != nil {
+		return nil, true, nil, fmt.Errorf("%s 原生解码失败: %w", format.name, err)
+	}
+	return samples, true, nil, nil
+}
+
+// collectNative 用 decode 解码 r，跳过开头 skip 后取前 seconds 秒（<= 0 表示到结尾），转换为 8kHz
+func collectNative(decode NativeDecoder, r io.Reader, seconds int, skip time.Duration) ([]int16, error) {
+	skipOut := int(skip.Seconds() * SampleRate)
+	want := -1
+	if seconds > 0 {
+		want = skipOut + seconds*SampleRate
+	}
+	var d *decimator
+	err := decode(r, func(rate int, mono []int16) bool {
+		if d == nil {
+			d = newDecimator(rate, SampleRate)
+		}
+		d.push(mono)
+		return want < 0 || len(d.out) < want
+	})
+	if err != nil || d == nil {
+		return nil, err
+	}
+	out := d.out
+	if want >= 0 && len(out) > want {
+		out = out[:want]
+	}
+	if skipOut >= len(out) {
+		return nil, nil
+	}
+	return out[skipOut:], nil
+}
+
+// decimator 把任意采样率的单声道样本转换到目标采样率：降采样时对每个输出样本覆盖的输入取平均
+// （简单的盒式低通，足以保证块平均幅度不受混叠影响），升采样时重复最近的样本。
+type decimator struct {
+	step float64 // 每个输出样本对应的输入样本数
+	next float64 // 下一个输出样本的输入边界
+	pos  int
+	sum  int64
+	n    int64
+	last int16
+	out  []int16
+}
+
+func newDecimator(from, to int) *decimator {
+	step := float64(from) / float64(to)
+	return &decimator{step: step, next: step}
+}
+
+func (d *decimator) push(in []int16) {
+	for _, v := range in {
+		d.sum += int64(v)
+		d.n++
+		d.pos++
+		for float64(d.pos) >= d.next {
+			if d.n > 0 {
+				d.last = int16(d.sum / d.n)
+				d.sum, d.n = 0, 0
+			}
+			d.out = append(d.out, d.last)
+			d.next += d.step
+		}
+	}
+}
+
+// errNativeUnsupported 表示格式可识别但具体编码变体不受原生解码器支持
+var errNativeUnsupported = errors.New("不支持的编码参数")
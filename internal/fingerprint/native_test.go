@@ -0,0 +1,260 @@
+// file: internal/fingerprint/native_test.go
+// package: fingerprint
+//
+// 原生解码测试：用测试内的最小编码器构造 WAV / FLAC（覆盖常量、原样、固定预测、LPC 子帧、
+// 空位与各种声道去相关），验证解码结果与原始样本一致，以及时长截取与重采样。
+package fingerprint
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testWAV(rate, channels int, frames [][]int16) []byte {
+	var data bytes.Buffer
+	for _, f := range frames {
+		for _, v := range f {
+			_ = binary.Write(&data, binary.LittleEndian, v)
+		}
+	}
+	var b bytes.Buffer
+	le32 := func(v int) { _ = binary.Write(&b, binary.LittleEndian, uint32(v)) }
+	le16 := func(v int) { _ = binary.Write(&b, binary.LittleEndian, uint16(v)) }
+	b.WriteString("RIFF")
+	le32(4 + 8 + 16 + 8 + 8 + data.Len())
+	b.WriteString("WAVE")
+	b.WriteString("LIST") // 无关的块应被跳过
+	le32(3)
+	b.Write([]byte{1, 2, 3, 0})
+	b.WriteString("fmt ")
+	le32(16)
+	le16(wavFormatPCM)
+	le16(channels)
+	le32(rate)
+	le32(rate * channels * 2)
+	le16(channels * 2)
+	le16(16)
+	b.WriteString("data")
+	le32(data.Len())
+	b.Write(data.Bytes())
+	return b.Bytes()
+}
+
+func TestDecodeWAV(t *testing.T) {
+	var frames [][]int16
+	for i := 0; i < 16000; i++ {
+		frames = append(frames, []int16{int16(i % 1000), int16(i%1000) + 2})
+	}
+	p := filepath.Join(t.TempDir(), "a.wav")
+	if err := os.WriteFile(p, testWAV(16000, 2, frames), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecodePCM(p, 0)
+	if err != nil {
+		t.Fatalf("DecodePCM 错误: %v", err)
+	}
+	// 16kHz → 8kHz：每两个输入样本取平均；下混为 (L+R)/2
+	if len(got) != 8000 {
+		t.Fatalf("期望 8000 个样本，实际 %d", len(got))
+	}
+	for k := 0; k < 10; k++ {
+		a, b := (2*k)%1000+1, (2*k+1)%1000+1
+		if want := int16((a + b) / 2); got[k] != want {
+			t.Fatalf("样本 %d = %d，期望 %d", k, got[k], want)
+		}
+	}
+
+	w, err := DecodeWindow(p, 250*time.Millisecond, 0)
+	if err != nil || len(w) != 6000 || w[0] != got[2000] {
+		t.Fatalf("DecodeWindow 截取不正确: len=%d err=%v", len(w), err)
+	}
+}
+
+// flacWriter 为测试用的位写入器
+type flacWriter struct {
+	out  []byte
+	cur  uint64
+	bits uint
+}
+
+func (w *flacWriter) write(v uint64, n uint) {
+	for i := int(n) - 1; i >= 0; i-- {
+		w.cur = w.cur<<1 | v>>uint(i)&1
+		w.bits++
+		if w.bits == 8 {
+			w.out = append(w.out, byte(w.cur))
+			w.cur, w.bits = 0, 0
+		}
+	}
+}
+
+func (w *flacWriter) signed(v int64, n uint) { w.write(uint64(v)&(1<<n-1), n) }
+
+func (w *flacWriter) align() {
+	for w.bits != 0 {
+		w.write(0, 1)
+	}
+}
+
+func (w *flacWriter) rice(res []int32, k uint) {
+	w.write(0, 2) // Rice，4 位参数
+	w.write(0, 4) // 分区阶数 0
+	w.write(uint64(k), 4)
+	for _, r := range res {
+		v := uint64(uint32(r<<1) ^ uint32(r>>31))
+		for q := v >> k; q > 0; q-- {
+			w.write(0, 1)
+		}
+		w.write(1, 1)
+		w.write(v&(1<<k-1), k)
+	}
+}
+
+func (w *flacWriter) frameHeader(blockSize, assign int) {
+	w.write(0xFFF8, 16)
+	w.write(7, 4) // 块大小在帧头后以 16 位给出
+	w.write(0, 4) // 采样率取 STREAMINFO
+	w.write(uint64(assign), 4)
+	w.write(4, 3) // 16 位
+	w.write(0, 1)
+	w.write(0, 8) // 帧号 0
+	w.write(uint64(blockSize-1), 16)
+	w.write(0, 8) // CRC-8（解码器不校验）
+}
+
+func (w *flacWriter) verbatim(x []int32, bps uint, wasted uint) {
+	if wasted == 0 {
+		w.write(1<<1, 8)
+	} else {
+		w.write(1<<1|1, 8)
+		w.write(1, wasted) // 一元码 wasted-1 个 0 后接 1
+	}
+	for _, v := range x {
+		w.signed(int64(v>>wasted), bps-wasted)
+	}
+}
+
+func (w *flacWriter) fixed2(x []int32, bps uint) {
+	w.write(10<<1, 8)
+	w.signed(int64(x[0]), bps)
+	w.signed(int64(x[1]), bps)
+	res := make([]int32, 0, len(x))
+	for i := 2; i < len(x); i++ {
+		res = append(res, x[i]-2*x[i-1]+x[i-2])
+	}
+	w.rice(res, 6)
+}
+
+func (w *flacWriter) lpc2(x []int32, bps uint) {
+	coefs, shift := []int64{3, -2}, 1
+	w.write((31+2)<<1, 8)
+	w.signed(int64(x[0]), bps)
+	w.signed(int64(x[1]), bps)
+	w.write(7, 4) // 精度 8 位
+	w.signed(int64(shift), 5)
+	for _, c := range coefs {
+		w.signed(c, 8)
+	}
+	res := make([]int32, 0, len(x))
+	for i := 2; i < len(x); i++ {
+		pred := (coefs[0]*int64(x[i-1]) + coefs[1]*int64(x[i-2])) >> uint(shift)
+		res = append(res, x[i]-int32(pred))
+	}
+	w.rice(res, 8)
+}
+
+func TestDecodeFLAC(t *testing.T) {
+	const block = 4096
+	left := make([]int32, 3*block)
+	right := make([]int32, 3*block)
+	for i := range left {
+		left[i] = int32(i*37%2000 - 1000)
+		right[i] = int32(i*53%1500-700) &^ 3 // 低 2 位为 0，用于测试空位
+	}
+	w := &flacWriter{}
+	w.out = append(w.out, "fLaC"...)
+	si := make([]byte, 34)
+	si[10], si[11], si[12] = 8000>>12, 8000>>4&0xFF, byte(8000&0xF)<<4|1<<1 // 8kHz，2 声道
+	si[13] = 15 << 4                                                        // 16 位
+	w.out = append(w.out, 0x80, 0, 0, 34)
+	w.out = append(w.out, si...)
+
+	// 帧 1：独立声道，左为原样、右为原样 + 空位
+	l, r := left[:block], right[:block]
+	w.frameHeader(block, 1)
+	w.verbatim(l, 16, 0)
+	w.verbatim(r, 16, 2)
+	w.align()
+	w.write(0, 16)
+	// 帧 2：中 / 侧，固定二阶预测
+	l, r = left[block:2*block], right[block:2*block]
+	mid, side := make([]int32, block), make([]int32, block)
+	for i := range l {
+		mid[i], side[i] = (l[i]+r[i])>>1, l[i]-r[i]
+	}
+	w.frameHeader(block, 10)
+	w.fixed2(mid, 16)
+	w.fixed2(side, 17)
+	w.align()
+	w.write(0, 16)
+	// 帧 3：左 / 侧，LPC
+	l, r = left[2*block:], right[2*block:]
+	for i := range l {
+		side[i] = l[i] - r[i]
+	}
+	w.frameHeader(block, 8)
+	w.lpc2(l, 16)
+	w.lpc2(side, 17)
+	w.align()
+	w.write(0, 16)
+
+	p := filepath.Join(t.TempDir(), "a.flac")
+	if err := os.WriteFile(p, w.out, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecodePCM(p, 0)
+	if err != nil {
+		t.Fatalf("DecodePCM 错误: %v", err)
+	}
+	if len(got) != len(left) {
+		t.Fatalf("期望 %d 个样本，实际 %d", len(left), len(got))
+	}
+	for i := range got {
+		if want := int16((int64(left[i]) + int64(right[i])) / 2); got[i] != want {
+			t.Fatalf("样本 %d（帧 %d）= %d，期望 %d", i, i/block+1, got[i], want)
+		}
+	}
+
+	// 只取 1 秒时提前停止
+	if head, err := DecodePCM(p, 1); err != nil || len(head) != SampleRate {
+		t.Fatalf("截取 1 秒: len=%d err=%v", len(head), err)
+	}
+	// 截断的文件：已完整的帧仍可用
+	if err := os.WriteFile(p, w.out[:len(w.out)-100], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if part, err := DecodePCM(p, 0); err != nil || len(part) != 2*block {
+		t.Fatalf("截断文件: len=%d err=%v", len(part), err)
+	}
+}
+
+func TestNativeOnlyRejectsUnknown(t *testing.T) {
+	defer SetBackend(currentBackend())
+	if err := SetBackend(BackendNative); err != nil {
+		t.Fatal(err)
+	}
+	p := filepath.Join(t.TempDir(), "a.mp3")
+	if err := os.WriteFile(p, []byte("ID3\x04\x00\x00\x00\x00\x00\x00"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecodePCM(p, 1); err == nil {
+		t.Fatal("native 后端遇到不支持的格式应报错")
+	}
+	if err := SetBackend("gstreamer"); err == nil {
+		t.Fatal("未知后端应报错")
+	}
+}
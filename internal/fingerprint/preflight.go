@@ -0,0 +1,115 @@
+// file: internal/fingerprint/preflight.go
+// package: fingerprint
+//
+// 启动时的解码器检查：按扫描到的文件扩展名统计各格式，对照当前解码后端与本机 ffmpeg 支持的解码器
+// （ffmpeg -codecs），在计算指纹之前指出哪些格式无法解码（如精简编译的 ffmpeg 缺少 ALAC 或 Opus），
+// 而不是在几千个文件上逐个报出解码失败。
+package fingerprint
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"deduplicateMusic/internal/formats"
+)
+
+// Missing 为扫描到的一种格式缺少的解码支持
+type Missing struct {
+	Format string   // 格式名
+	Files  int      // 该格式的文件数
+	Codecs []string // ffmpeg 缺少的解码器；为空表示该格式完全无法解码，原因见 Reason
+	Reason string   // 完全无法解码的原因
+}
+
+func (m Missing) String() string {
+	if len(m.Codecs) == 0 {
+		return fmt.Sprintf("%d 个 %s 文件无法解码：%s", m.Files, m.Format, m.Reason)
+	}
+	return fmt.Sprintf("%d 个 %s 文件中使用 %s 编码的将无法解码：ffmpeg 缺少相应的解码器", m.Files, m.Format, strings.Join(m.Codecs, "、"))
+}
+
+// Preflight 检查 paths（按扩展名归类）在当前解码后端下能否解码，返回缺少解码支持的格式（按格式名排序）。
+// 只有需要查询 ffmpeg 支持的解码器而查询失败时才返回错误。
+func Preflight(paths []string) ([]Missing, error) {
+	counts := map[string]int{}
+	for _, p := range paths {
+		if f, ok := formats.ByPath(p); ok {
+			counts[f.Name]++
+		}
+	}
+	b := currentBackend()
+	_, err := exec.LookPath("ffmpeg")
+	hasFFmpeg := err == nil
+	var codecs map[string]bool
+	if hasFFmpeg && b != BackendNative && needsFFmpeg(counts, b) {
+		out, err := exec.Command("ffmpeg", "-hide_banner", "-codecs").Output()
+		if err != nil {
+			return nil, fmt.Errorf("查询 ffmpeg 支持的解码器失败: %v", err)
+		}
+		codecs = parseCodecs(string(out))
+	}
+	return missing(counts, b, hasFFmpeg, codecs), nil
+}
+
+// needsFFmpeg 返回是否有格式需要 ffmpeg 解码
+func needsFFmpeg(counts map[string]int, b Backend) bool {
+	for name := range counts {
+		if f, ok := formats.Lookup(name); ok && (!f.Native || b == BackendFFmpeg) {
+			return true
+		}
+	}
+	return false
+}
+
+// missing 按各格式的文件数 counts、解码后端 b、是否找到 ffmpeg 与其支持的解码器 codecs 列出缺少的解码支持
+func missing(counts map[string]int, b Backend, hasFFmpeg bool, codecs map[string]bool) []Missing {
+	var out []Missing
+	for name, n := range counts {
+		f, ok := formats.Lookup(name)
+		if !ok || (f.Native && b != BackendFFmpeg) {
+			continue // 进程内解码，不依赖 ffmpeg
+		}
+		switch {
+		case b == BackendNative:
+			out = append(out, Missing{Format: name, Files: n, Reason: "-decoder native 只能解码 " + strings.Join(NativeFormats(), "、")})
+			continue
+		case !hasFFmpeg:
+			if _, ok := fallbackFor(name); !ok {
+				out = append(out, Missing{Format: name, Files: n, Reason: "未找到 ffmpeg"})
+			}
+			continue
+		case !f.FFmpeg:
+			out = append(out, Missing{Format: name, Files: n, Reason: "没有可用的解码器"})
+			continue
+		}
+		m := Missing{Format: name, Files: n}
+		for _, c := range f.Codecs {
+			if !codecs[c] {
+				m.Codecs = append(m.Codecs, c)
+			}
+		}
+		if len(m.Codecs) > 0 {
+			out = append(out, m)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Format < out[j].Format })
+	return out
+}
+
+// parseCodecs 解析 ffmpeg -codecs 的输出，返回支持解码的音频编码名。每行形如
+// " DEA.LS flac   FLAC (Free Lossless Audio Codec)"：第 1 个标志 D 表示可解码，第 3 个 A 表示音频。
+func parseCodecs(out string) map[string]bool {
+	codecs := map[string]bool{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || len(fields[0]) != 6 || fields[0] == "------" {
+			continue
+		}
+		if flags := fields[0]; flags[0] == 'D' && flags[2] == 'A' {
+			codecs[fields[1]] = true
+		}
+	}
+	return codecs
+}
@@ -0,0 +1,49 @@
+// file: internal/fingerprint/preflight_test.go
+// package: fingerprint
+//
+// 测试 ffmpeg -codecs 输出的解析，以及按解码后端与可用解码器列出缺少的解码支持。
+package fingerprint
+
+import (
+	"fmt"
+	"testing"
+)
+
+const codecsOutput = `Codecs:
+ D..... = Decoding supported
+ .E.... = Encoding supported
+ ..V... = Video codec
+ ..A... = Audio codec
+ -------
+ DEA.L. aac                  AAC (Advanced Audio Coding) (decoders: aac aac_fixed )
+ DEA.L. mp3                  MP3 (MPEG audio layer 3) (decoders: mp3float mp3 )
+ DEA..S flac                 FLAC (Free Lossless Audio Codec)
+ .EA..S alac                 ALAC (Apple Lossless Audio Codec)
+ DEV.L. h264                 H.264 / AVC / MPEG-4 AVC / MPEG-4 part 10
+ DEA.L. vorbis               Vorbis
+`
+
+func TestParseCodecs(t *testing.T) {
+	c := parseCodecs(codecsOutput)
+	if !c["aac"] || !c["mp3"] || !c["flac"] || !c["vorbis"] || c["alac"] || c["h264"] || len(c) != 4 {
+		t.Fatalf("parseCodecs = %v", c)
+	}
+}
+
+func TestMissing(t *testing.T) {
+	counts := map[string]int{"mp3": 10, "m4a": 5, "ogg": 2, "flac": 3}
+	codecs := parseCodecs(codecsOutput)
+	got := fmt.Sprint(missing(counts, BackendAuto, true, codecs))
+	if want := "[5 个 m4a 文件中使用 alac 编码的将无法解码：ffmpeg 缺少相应的解码器 2 个 ogg 文件中使用 opus 编码的将无法解码：ffmpeg 缺少相应的解码器]"; got != want {
+		t.Fatalf("auto:\n%s\n期望\n%s", got, want)
+	}
+	// 未找到 ffmpeg：原生解码的 flac 与内置后备解码器解码的 mp3 不受影响，其余格式整体无法解码
+	ms := missing(counts, BackendAuto, false, nil)
+	if len(ms) != 2 || ms[0].String() != "5 个 m4a 文件无法解码：未找到 ffmpeg" {
+		t.Fatalf("无 ffmpeg: %+v", ms)
+	}
+	// -decoder ffmpeg：flac 也交给 ffmpeg
+	if ms := missing(map[string]int{"flac": 1}, BackendFFmpeg, true, map[string]bool{}); len(ms) != 1 || ms[0].Codecs[0] != "flac" {
+		t.Fatalf("ffmpeg 后端: %+v", ms)
+	}
+}
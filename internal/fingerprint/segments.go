@@ -0,0 +1,85 @@
+// file: internal/fingerprint/segments.go
+// package: fingerprint
+//
+// 多窗口指纹：只看开头 N 秒时，前奏相同的不同歌曲会被误判为重复，开头多了几秒静音的
+// 同一首歌又会漏判。多窗口模式在开头（跳过前导静音）、中段、结尾之间均匀取若干窗口，
+// 每个窗口各算一个 64 位指纹，由 dedup 按各窗口距离的平均值比较。
+package fingerprint
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// MaxLeadingSilence 为开头窗口最多跳过的前导静音（秒）
+const MaxLeadingSilence = 10
+
+// silenceLevel 为静音判定阈值（10ms 平均绝对幅度，约 -40 dBFS）
+const silenceLevel = 328
+
+// TrimLeadingSilence 去掉开头连续的静音（以 10ms 为单位判定），全为静音时返回空切片
+func TrimLeadingSilence(samples []int16) []int16 {
+	const frame = SampleRate / 100
+	for start := 0; start < len(samples); start += frame {
+		end := start + frame
+		if end > len(samples) {
+			end = len(samples)
+		}
+		var sum int
+		for _, v := range samples[start:end] {
+			if v < 0 {
+				sum -= int(v)
+			} else {
+				sum += int(v)
+			}
+		}
+		if sum/(end-start) >= silenceLevel {
+			return samples[start:]
+		}
+	}
+	return samples[len(samples):]
+}
+
+// SegmentStarts 返回 n 个长度为 seconds 的窗口在总时长 total 内的起点：
+// 第一个在开头、最后一个在结尾，其余均匀分布。文件短于一个窗口时全部从 0 开始。
+func SegmentStarts(total time.Duration, seconds, n int) []time.Duration {
+	span := total - time.Duration(seconds)*time.Second
+	if span < 0 {
+		span = 0
+	}
+	starts := make([]time.Duration, n)
+	for i := 1; i < n; i++ {
+		starts[i] = span * time.Duration(i) / time.Duration(n-1)
+	}
+	return starts
+}
+
+// segmentFingerprints 计算 path 的 o.Segments 个窗口指纹
+func segmentFingerprints(ctx context.Context, path string, o Options) ([]uint64, error) {
+	secs, err := ProbeDuration(path)
+	if err != nil {
+		return nil, err
+	}
+	total := time.Duration(secs*float64(time.Second)) - o.Skip
+	n := o.Seconds * SampleRate
+	fps := make([]uint64, 0, o.Segments)
+	for i, start := range SegmentStarts(total, o.Seconds, o.Segments) {
+		length := o.Seconds
+		if i == 0 {
+			length += MaxLeadingSilence // 为跳过前导静音多解码一段
+		}
+		samples, err := decodePCM(ctx, path, nil, length, o.Skip+start)
+		if err != nil {
+			return nil, fmt.Errorf("窗口 %d: %w", i+1, err)
+		}
+		if i == 0 {
+			samples = TrimLeadingSilence(samples)
+		}
+		if len(samples) > n {
+			samples = samples[:n]
+		}
+		fps = append(fps, FingerprintFromSamples(samples, o.Bits))
+	}
+	return fps, nil
+}
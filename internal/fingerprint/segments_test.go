@@ -0,0 +1,82 @@
+// file: internal/fingerprint/segments_test.go
+// package: fingerprint
+//
+// 多窗口指纹测试：开头多出静音的同一段音频，开头（跳过静音后）与结尾窗口指纹应一致。
+package fingerprint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSegmentsSkipLeadingSilence(t *testing.T) {
+	// 30 秒内容：每 100ms 随机幅度的方波
+	var content [][]int16
+	seed := uint32(1)
+	for i := 0; i < 300; i++ {
+		seed = seed*1664525 + 1013904223
+		amp := int16(seed>>20) + 1000
+		for k := 0; k < SampleRate/10; k++ {
+			v := amp
+			if k%2 == 1 {
+				v = -amp
+			}
+			content = append(content, []int16{v})
+		}
+	}
+	silence := make([][]int16, 3*SampleRate)
+	for i := range silence {
+		silence[i] = []int16{0}
+	}
+	dir := t.TempDir()
+	plain, padded := filepath.Join(dir, "plain.wav"), filepath.Join(dir, "padded.wav")
+	if err := os.WriteFile(plain, testWAV(SampleRate, 1, content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(padded, testWAV(SampleRate, 1, append(silence, content...)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	o := Options{Seconds: 4, Bits: 64, Segments: 3}
+	a, err := AnalyzeFile(plain, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := AnalyzeFile(padded, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Segments) != 3 || len(b.Segments) != 3 {
+		t.Fatalf("期望 3 个窗口指纹: %v %v", a.Segments, b.Segments)
+	}
+	if a.FP == b.FP {
+		t.Fatal("整体指纹（含静音）不应相同")
+	}
+	// 开头窗口跳过静音后对齐，结尾窗口本就对齐
+	if a.Segments[0] != b.Segments[0] || a.Segments[2] != b.Segments[2] {
+		t.Fatalf("开头/结尾窗口应一致: %016x %016x", a.Segments, b.Segments)
+	}
+}
+
+func TestSegmentStarts(t *testing.T) {
+	got := SegmentStarts(64*time.Second, 4, 3)
+	if want := []time.Duration{0, 30 * time.Second, 60 * time.Second}; !equalDurations(got, want) {
+		t.Fatalf("SegmentStarts = %v", got)
+	}
+	if got := SegmentStarts(2*time.Second, 4, 3); !equalDurations(got, make([]time.Duration, 3)) {
+		t.Fatalf("短文件窗口应全部从 0 开始: %v", got)
+	}
+}
+
+func equalDurations(a, b []time.Duration) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,145 @@
+// file: internal/fingerprint/wav.go
+// package: fingerprint
+//
+// WAV（RIFF）原生解码：支持 8/16/24/32 位整数 PCM、32/64 位浮点以及 WAVE_FORMAT_EXTENSIBLE，
+// 逐块读取 data 块并下混为单声道。
+package fingerprint
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	wavFormatPCM        = 1
+	wavFormatFloat      = 3
+	wavFormatExtensible = 0xFFFE
+)
+
+// decodeWAV 实现 NativeDecoder
+func decodeWAV(r io.Reader, emit func(rate int, mono []int16) bool) error {
+	hdr := make([]byte, 12)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return err
+	}
+	var (
+		format, channels, bitsPer int
+		rate                      int
+		haveFmt                   bool
+	)
+	chunk := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			if err == io.EOF && haveFmt {
+				return nil // 没有 data 块
+			}
+			return err
+		}
+		id, size := string(chunk[:4]), int64(binary.LittleEndian.Uint32(chunk[4:]))
+		switch id {
+		case "fmt ":
+			if size < 16 || size > 1<<16 {
+				return fmt.Errorf("fmt 块长度异常: %d", size)
+			}
+			b := make([]byte, size+size&1)
+			if _, err := io.ReadFull(r, b); err != nil {
+				return err
+			}
+			format = int(binary.LittleEndian.Uint16(b[0:]))
+			channels = int(binary.LittleEndian.Uint16(b[2:]))
+			rate = int(binary.LittleEndian.Uint32(b[4:]))
+			bitsPer = int(binary.LittleEndian.Uint16(b[14:]))
+			if format == wavFormatExtensible && size >= 40 {
+				format = int(binary.LittleEndian.Uint16(b[24:])) // 子格式 GUID 的前两个字节
+			}
+			haveFmt = true
+		case "data":
+			if !haveFmt {
+				return fmt.Errorf("data 块出现在 fmt 块之前")
+			}
+			return readWAVData(r, size, format, channels, bitsPer, rate, emit)
+		default:
+			if _, err := io.CopyN(io.Discard, r, size+size&1); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// readWAVData 读取 data 块（size 为 0 或 0xFFFFFFFF 时读到流结束，兼容流式写出的文件）
+func readWAVData(r io.Reader, size int64, format, channels, bitsPer, rate int, emit func(int, []int16) bool) error {
+	width := (bitsPer + 7) / 8
+	switch {
+	case channels <= 0 || rate <= 0:
+		return fmt.Errorf("声道数或采样率无效")
+	case format == wavFormatPCM && (width < 1 || width > 4):
+		return errNativeUnsupported
+	case format == wavFormatFloat && width != 4 && width != 8:
+		return errNativeUnsupported
+	case format != wavFormatPCM && format != wavFormatFloat:
+		return errNativeUnsupported
+	}
+	sample := func(b []byte) int {
+		switch {
+		case format == wavFormatFloat && width == 4:
+			return clampFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(b))))
+		case format == wavFormatFloat:
+			return clampFloat(math.Float64frombits(binary.LittleEndian.Uint64(b)))
+		case width == 1:
+			return (int(b[0]) - 128) << 8 // 8 位 PCM 为无符号
+		case width == 2:
+			return int(int16(binary.LittleEndian.Uint16(b)))
+		case width == 3:
+			return int(int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 16)
+		}
+		return int(int32(binary.LittleEndian.Uint32(b)) >> 16)
+	}
+
+	if size == 0 || size == 0xFFFFFFFF {
+		size = math.MaxInt64
+	}
+	frame := width * channels
+	const framesPerRead = 4096
+	buf := make([]byte, frame*framesPerRead)
+	mono := make([]int16, framesPerRead)
+	for size >= int64(frame) {
+		want := len(buf)
+		if int64(want) > size {
+			want = int(size) / frame * frame
+		}
+		n, err := io.ReadFull(r, buf[:want])
+		n -= n % frame
+		for i := 0; i < n/frame; i++ {
+			sum := 0
+			for c := 0; c < channels; c++ {
+				sum += sample(buf[i*frame+c*width:])
+			}
+			mono[i] = int16(sum / channels)
+		}
+		if n > 0 && !emit(rate, mono[:n/frame]) {
+			return nil
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil // 截断的文件：已读到的部分仍然有效
+		}
+		if err != nil {
+			return err
+		}
+		size -= int64(n)
+	}
+	return nil
+}
+
+// clampFloat 把 [-1, 1] 的浮点样本换算为 16 位整数
+func clampFloat(v float64) int {
+	v *= 32767
+	switch {
+	case v > 32767:
+		return 32767
+	case v < -32768:
+		return -32768
+	}
+	return int(v)
+}
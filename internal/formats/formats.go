@@ -0,0 +1,189 @@
+// file: internal/formats/formats.go
+// package: formats
+//
+// 音频格式能力注册表：扩展名 / 文件头魔数到格式能力（原生解码、ffmpeg 解码、标签读取、
+// 典型编码质量等级）的映射。扫描器据此决定扫描哪些扩展名，指纹后端据此决定是否尝试原生解码，
+// 保留策略据此比较编码质量，取代原先散落在各处的硬编码列表。新增格式只需 Register。
+package formats
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// TagKind 为格式支持的标签类型
+type TagKind string
+
+const (
+	TagNone   TagKind = ""       // 不读取标签
+	TagID3    TagKind = "id3"    // ID3v2 / ID3v1
+	TagVorbis TagKind = "vorbis" // FLAC 元数据块中的 Vorbis 注释
+	TagOgg    TagKind = "ogg"    // Ogg 流（Vorbis、Opus）中的 Vorbis 注释
+	TagMP4    TagKind = "mp4"    // MP4 的 iTunes 元数据（ilst）
+)
+
+// 典型编码质量等级（Rank），越大越好；同一格式的实际质量还取决于码率
+const (
+	RankUnknown  = 0
+	RankLossy    = 1 // 早期有损编码（MP3）
+	RankModern   = 2 // 同码率下更好的有损编码（AAC、Vorbis）
+	RankLossless = 3
+)
+
+// Format 描述一种音频格式的能力
+type Format struct {
+	Name     string                 // 格式名（小写），如 "flac"
+	Exts     []string               // 扩展名（小写，含点）
+	Sniff    func(head []byte) bool // 按文件开头 12 字节识别该格式，nil 表示不按内容识别
+	Native   bool                   // 进程内原生解码器可以解码（由 MarkNative 设置）
+	FFmpeg   bool                   // ffmpeg 可以解码
+	Codecs   []string               // 该格式可能使用的编码（ffmpeg 中的解码器名），如 m4a 可能是 aac 或 alac
+	Tags     TagKind                // 可读取的标签类型
+	Lossless bool
+	Rank     int // 典型编码质量等级，见 Rank* 常量
+}
+
+var (
+	mu      sync.RWMutex
+	byName  = map[string]*Format{}
+	byExt   = map[string]*Format{}
+	ordered []*Format // 注册顺序，Sniff 按此顺序匹配
+)
+
+func init() {
+	Register(Format{Name: "mp3", Exts: []string{".mp3"}, Sniff: sniffMP3, FFmpeg: true, Codecs: []string{"mp3"}, Tags: TagID3, Rank: RankLossy})
+	Register(Format{Name: "wav", Exts: []string{".wav"}, Sniff: func(h []byte) bool {
+		return len(h) >= 12 && string(h[:4]) == "RIFF" && string(h[8:12]) == "WAVE"
+	}, FFmpeg: true, Codecs: []string{"pcm_s16le"}, Lossless: true, Rank: RankLossless})
+	Register(Format{Name: "flac", Exts: []string{".flac"}, Sniff: func(h []byte) bool {
+		return len(h) >= 4 && string(h[:4]) == "fLaC"
+	}, FFmpeg: true, Codecs: []string{"flac"}, Tags: TagVorbis, Lossless: true, Rank: RankLossless})
+	Register(Format{Name: "aac", Exts: []string{".aac"}, Sniff: func(h []byte) bool {
+		return len(h) >= 2 && h[0] == 0xFF && h[1]&0xF6 == 0xF0 // ADTS 同步字（layer 为 0）
+	}, FFmpeg: true, Codecs: []string{"aac"}, Tags: TagID3, Rank: RankModern})
+	Register(Format{Name: "m4a", Exts: []string{".m4a"}, Sniff: func(h []byte) bool {
+		return len(h) >= 8 && string(h[4:8]) == "ftyp"
+	}, FFmpeg: true, Codecs: []string{"aac", "alac"}, Tags: TagMP4, Rank: RankModern})
+	Register(Format{Name: "ogg", Exts: []string{".ogg"}, Sniff: func(h []byte) bool {
+		return len(h) >= 4 && string(h[:4]) == "OggS"
+	}, FFmpeg: true, Codecs: []string{"vorbis", "opus"}, Tags: TagOgg, Rank: RankModern})
+}
+
+// sniffMP3 识别以 ID3v2 标签或 MPEG 音频帧同步字（layer III）开头的文件
+func sniffMP3(h []byte) bool {
+	if len(h) >= 3 && string(h[:3]) == "ID3" {
+		return true
+	}
+	return len(h) >= 2 && h[0] == 0xFF && h[1]&0xE0 == 0xE0 && h[1]&0x06 == 0x02
+}
+
+// Register 注册格式（同名覆盖已有格式，包括其扩展名映射）
+func Register(f Format) {
+	mu.Lock()
+	defer mu.Unlock()
+	f.Name = strings.ToLower(f.Name)
+	exts := make([]string, len(f.Exts))
+	for i, e := range f.Exts {
+		exts[i] = normExt(e)
+	}
+	f.Exts = exts
+	if old, ok := byName[f.Name]; ok {
+		for _, e := range old.Exts {
+			delete(byExt, e)
+		}
+		*old = f
+		for _, e := range old.Exts {
+			byExt[e] = old
+		}
+		return
+	}
+	p := &f
+	byName[f.Name] = p
+	ordered = append(ordered, p)
+	for _, e := range f.Exts {
+		byExt[e] = p
+	}
+}
+
+// MarkNative 标记 name 格式可由原生解码器解码（未注册的格式忽略）
+func MarkNative(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if f, ok := byName[strings.ToLower(name)]; ok {
+		f.Native = true
+	}
+}
+
+// Lookup 按格式名查找
+func Lookup(name string) (Format, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if f, ok := byName[strings.ToLower(name)]; ok {
+		return *f, true
+	}
+	return Format{}, false
+}
+
+// ByExt 按扩展名（带不带点、大小写均可）查找
+func ByExt(ext string) (Format, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if f, ok := byExt[normExt(ext)]; ok {
+		return *f, true
+	}
+	return Format{}, false
+}
+
+// ByPath 按文件扩展名查找
+func ByPath(path string) (Format, bool) { return ByExt(filepath.Ext(path)) }
+
+// Sniff 按文件开头的字节识别格式（按注册顺序匹配）
+func Sniff(head []byte) (Format, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, f := range ordered {
+		if f.Sniff != nil && f.Sniff(head) {
+			return *f, true
+		}
+	}
+	return Format{}, false
+}
+
+// Extensions 返回所有已注册格式的扩展名（排序），即扫描器要收集的文件
+func Extensions() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	exts := make([]string, 0, len(byExt))
+	for e := range byExt {
+		exts = append(exts, e)
+	}
+	sort.Strings(exts)
+	return exts
+}
+
+// All 返回所有已注册格式（注册顺序）
+func All() []Format {
+	mu.RLock()
+	defer mu.RUnlock()
+	out := make([]Format, len(ordered))
+	for i, f := range ordered {
+		out[i] = *f
+	}
+	return out
+}
+
+// RankOf 返回 path 按扩展名的典型编码质量等级，未知格式为 RankUnknown
+func RankOf(path string) int {
+	f, _ := ByPath(path)
+	return f.Rank
+}
+
+func normExt(e string) string {
+	e = strings.ToLower(strings.TrimSpace(e))
+	if e != "" && !strings.HasPrefix(e, ".") {
+		e = "." + e
+	}
+	return e
+}
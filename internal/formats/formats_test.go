@@ -0,0 +1,57 @@
+// file: internal/formats/formats_test.go
+// package: formats
+//
+// 测试格式注册表：按扩展名 / 文件头查找、扩展名列表，以及注册新格式。
+package formats
+
+import "testing"
+
+func TestLookup(t *testing.T) {
+	f, ok := ByPath("/music/A.FLAC")
+	if !ok || f.Name != "flac" || !f.Lossless || f.Tags != TagVorbis || f.Rank != RankLossless {
+		t.Fatalf("ByPath(.FLAC) = %+v, %v", f, ok)
+	}
+	if RankOf("a.mp3") >= RankOf("a.m4a") || RankOf("a.txt") != RankUnknown {
+		t.Fatal("质量等级顺序不正确")
+	}
+	if f, ok := Sniff([]byte("RIFF\x00\x00\x00\x00WAVE")); !ok || f.Name != "wav" {
+		t.Fatalf("Sniff(wav) = %+v, %v", f, ok)
+	}
+	if f, ok := Sniff([]byte("ID3\x04\x00")); !ok || f.Name != "mp3" {
+		t.Fatalf("Sniff(mp3) = %+v, %v", f, ok)
+	}
+	if _, ok := Sniff([]byte("hello world!")); ok {
+		t.Fatal("不应识别普通文本")
+	}
+	want := []string{".aac", ".flac", ".m4a", ".mp3", ".ogg", ".wav"}
+	got := Extensions()
+	if len(got) != len(want) {
+		t.Fatalf("Extensions = %v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Extensions = %v", got)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	defer func() {
+		mu.Lock()
+		delete(byName, "opus")
+		delete(byExt, ".opus")
+		ordered = ordered[:len(ordered)-1]
+		mu.Unlock()
+	}()
+	Register(Format{Name: "Opus", Exts: []string{"OPUS"}, FFmpeg: true, Rank: RankModern})
+	MarkNative("opus")
+	f, ok := ByExt(".opus")
+	if !ok || f.Name != "opus" || !f.Native {
+		t.Fatalf("ByExt(.opus) = %+v, %v", f, ok)
+	}
+	// 同名重新注册覆盖原有能力
+	Register(Format{Name: "opus", Exts: []string{".opus"}, Rank: RankLossy})
+	if f, _ := ByExt("opus"); f.Rank != RankLossy || f.Native || len(All()) != 7 {
+		t.Fatalf("覆盖注册后 = %+v", f)
+	}
+}
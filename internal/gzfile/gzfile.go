@@ -0,0 +1,126 @@
+// file: internal/gzfile/gzfile.go
+// package: gzfile
+//
+// 报告与计划文件的透明压缩：
+//   - Create 按扩展名决定是否压缩（以 .gz 结尾时写 gzip），调用方无需关心；
+//   - Open 按文件头魔数识别 gzip，读取时自动解压，未压缩的文件原样读取。
+//
+// 百万级曲库的报告可达数百 MB，压缩后通常只有十分之一左右。
+package gzfile
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Ext 为压缩文件的扩展名
+const Ext = ".gz"
+
+// flushInterval 为压缩输出两次同步刷新之间的最短间隔：逐行刷新会严重影响压缩率，
+// 按时间刷新则最多丢失最后这段时间内写入的内容
+const flushInterval = time.Second
+
+// IsCompressed 返回 path 是否按扩展名应写成 gzip
+func IsCompressed(path string) bool { return strings.HasSuffix(strings.ToLower(path), Ext) }
+
+// File 为 Create 返回的可写文件
+type File struct {
+	f         io.WriteCloser
+	gz        *gzip.Writer
+	lastFlush time.Time
+}
+
+// Create 创建 path；path 以 .gz 结尾时写入的内容会被 gzip 压缩
+func Create(path string) (*File, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	out := &File{f: f, lastFlush: time.Now()}
+	if IsCompressed(path) {
+		out.gz = gzip.NewWriter(f)
+	}
+	return out, nil
+}
+
+// NewWriter 包装已打开的 w（如 stdout），compress 为真时写 gzip；Close 只结束压缩流，不关闭 w
+func NewWriter(w io.Writer, compress bool) *File {
+	out := &File{f: nopCloser{w}, lastFlush: time.Now()}
+	if compress {
+		out.gz = gzip.NewWriter(w)
+	}
+	return out
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+// Write 实现 io.Writer
+func (w *File) Write(p []byte) (int, error) {
+	if w.gz != nil {
+		return w.gz.Write(p)
+	}
+	return w.f.Write(p)
+}
+
+// Flush 把已写入的内容推到文件，使进程中途崩溃时已写部分仍可解压读取。
+// 压缩输出按 flushInterval 节流，未压缩输出无需操作。
+func (w *File) Flush() error {
+	if w.gz == nil || time.Since(w.lastFlush) < flushInterval {
+		return nil
+	}
+	w.lastFlush = time.Now()
+	return w.gz.Flush()
+}
+
+// Close 结束压缩流并关闭文件
+func (w *File) Close() error {
+	var err error
+	if w.gz != nil {
+		err = w.gz.Close()
+	}
+	if cerr := w.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Open 打开 path 供读取，gzip 文件（按魔数识别，与扩展名无关）自动解压
+func Open(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(f)
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		return readCloser{Reader: gz, close: func() error { gz.Close(); return f.Close() }}, nil
+	}
+	return readCloser{Reader: br, close: f.Close}, nil
+}
+
+// ReadFile 同 os.ReadFile，gzip 文件自动解压
+func ReadFile(path string) ([]byte, error) {
+	r, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (r readCloser) Close() error { return r.close() }
@@ -0,0 +1,62 @@
+// file: internal/gzfile/gzfile_test.go
+// package: gzfile
+//
+// 验证按扩展名压缩写出、按魔数透明解压读取，以及压缩流在 Close 前刷新的内容可读。
+package gzfile
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateOpen(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.csv", "a.csv.gz", "A.CSV.GZ"} {
+		p := filepath.Join(dir, name)
+		w, err := Create(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(w, "x,y\n"); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		raw, _ := os.ReadFile(p)
+		if compressed := raw[0] == 0x1f && raw[1] == 0x8b; compressed != IsCompressed(name) {
+			t.Fatalf("%s: 压缩=%v", name, compressed)
+		}
+		if got, err := ReadFile(p); err != nil || string(got) != "x,y\n" {
+			t.Fatalf("%s: 读回 %q, %v", name, got, err)
+		}
+	}
+}
+
+func TestFlushedPartIsReadable(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "partial.csv.gz")
+	w, err := Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	io.WriteString(w, "row1\n")
+	w.lastFlush = w.lastFlush.Add(-flushInterval) // 跳过节流
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	// 模拟崩溃：不 Close，直接读取已落盘的部分
+	f, _ := os.Open(p)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := io.ReadAll(gz) // 缺少结尾时返回 ErrUnexpectedEOF，但已刷新的数据完整
+	if string(got) != "row1\n" {
+		t.Fatalf("读到 %q", got)
+	}
+}
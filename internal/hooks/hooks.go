@@ -0,0 +1,95 @@
+// file: internal/hooks/hooks.go
+// package: hooks
+//
+// 用户自定义钩子：针对每个文件的决策（保留 / 重复 / 出错）执行一条 shell 命令，
+// 并把文件元数据以 JSON 形式写入该命令的 stdin，方便接入用户自己的数据库或工作流。
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// 事件类型
+const (
+	EventKeep      = "keep"
+	EventDuplicate = "duplicate"
+	EventError     = "error"
+)
+
+// Event 是传给钩子命令的 JSON 负载
+type Event struct {
+	Event       string `json:"event"`
+	Path        string `json:"path"`
+	Size        int64  `json:"size,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"` // 十六进制
+	GroupID     int    `json:"group_id,omitempty"`
+	KeptPath    string `json:"kept_path,omitempty"` // 重复文件所对应的保留文件
+	NewPath     string `json:"new_path,omitempty"`  // 保留文件复制到的新路径
+	Distance    int    `json:"distance,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// Runner 保存三类事件对应的命令；命令为空表示不启用该钩子
+type Runner struct {
+	OnKeep      string
+	OnDuplicate string
+	OnError     string
+}
+
+// Enabled 返回是否配置了任意钩子
+func (r *Runner) Enabled() bool {
+	return r != nil && (r.OnKeep != "" || r.OnDuplicate != "" || r.OnError != "")
+}
+
+// Fire 执行 ev 对应的钩子命令；未配置时直接返回 nil。
+// 命令通过系统 shell 执行（Unix: sh -c，Windows: cmd /C），
+// 事件类型与路径同时通过环境变量 AUDIO_DEDUP_EVENT / AUDIO_DEDUP_PATH 提供。
+func (r *Runner) Fire(ev Event) error {
+	if r == nil {
+		return nil
+	}
+	var command string
+	switch ev.Event {
+	case EventKeep:
+		command = r.OnKeep
+	case EventDuplicate:
+		command = r.OnDuplicate
+	case EventError:
+		command = r.OnError
+	}
+	if command == "" {
+		return nil
+	}
+
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	cmd := shellCommand(command)
+	cmd.Stdin = bytes.NewReader(append(payload, '\n'))
+	cmd.Env = append(os.Environ(), "AUDIO_DEDUP_EVENT="+ev.Event, "AUDIO_DEDUP_PATH="+ev.Path)
+	var stderr bytes.Buffer
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return fmt.Errorf("钩子 on-%s 执行失败: %s", ev.Event, msg)
+	}
+	return nil
+}
+
+func shellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
@@ -0,0 +1,42 @@
+// file: internal/hooks/hooks_test.go
+// package: hooks
+//
+// 测试钩子命令能从 stdin 读到事件 JSON；未配置的事件不执行。
+package hooks
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestFireWritesJSONToStdin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("依赖 sh")
+	}
+	out := filepath.Join(t.TempDir(), "event.json")
+	r := &Runner{OnDuplicate: "cat > " + out}
+
+	ev := Event{Event: EventDuplicate, Path: "a.mp3", KeptPath: "b.mp3", Distance: 2}
+	if err := r.Fire(ev); err != nil {
+		t.Fatalf("Fire 错误: %v", err)
+	}
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("读取钩子输出失败: %v", err)
+	}
+	var got Event
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("钩子收到的不是合法 JSON: %v", err)
+	}
+	if got != ev {
+		t.Fatalf("期望 %#v，实际 %#v", ev, got)
+	}
+
+	// on-keep 未配置，不应执行任何命令
+	if err := r.Fire(Event{Event: EventKeep, Path: "b.mp3"}); err != nil {
+		t.Fatalf("未配置的钩子不应报错: %v", err)
+	}
+}
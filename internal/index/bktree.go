@@ -0,0 +1,104 @@
+// file: internal/index/bktree.go
+// package: index
+//
+// BK 树：按汉明距离组织 64 位指纹的度量树，查询与某指纹距离不超过半径的所有指纹时，
+// 借助三角不等式只访问距离在 [d-r, d+r] 范围内的子树，不必逐个比较。
+// 适合阈值较大（分段索引剪枝失效）时的近邻查询；建树后可并发查询。
+package index
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// BKTree 为指纹上的 BK 树。零值可直接使用。
+type BKTree struct {
+	root *bkNode
+	size int
+}
+
+type bkNode struct {
+	fp       uint64
+	ids      []int // 指纹相同的所有条目
+	children []bkChild
+}
+
+type bkChild struct {
+	dist uint8
+	node *bkNode
+}
+
+// Add 插入一个指纹及其编号
+func (t *BKTree) Add(fp uint64, id int) {
+	t.size++
+	if t.root == nil {
+		t.root = &bkNode{fp: fp, ids: []int{id}}
+		return
+	}
+	n := t.root
+	for {
+		d := uint8(bits.OnesCount64(n.fp ^ fp))
+		if d == 0 {
+			n.ids = append(n.ids, id)
+			return
+		}
+		next := n.child(d)
+		if next == nil {
+			n.children = append(n.children, bkChild{dist: d, node: &bkNode{fp: fp, ids: []int{id}}})
+			return
+		}
+		n = next
+	}
+}
+
+func (n *bkNode) child(d uint8) *bkNode {
+	for _, c := range n.children {
+		if c.dist == d {
+			return c.node
+		}
+	}
+	return nil
+}
+
+// Len 返回已插入的条目数
+func (t *BKTree) Len() int { return t.size }
+
+// Search 对与 fp 的汉明距离 <= radius 的每个条目调用 fn（顺序不确定）
+func (t *BKTree) Search(fp uint64, radius int, fn func(id, dist int)) {
+	if t.root == nil || radius < 0 {
+		return
+	}
+	stack := []*bkNode{t.root}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		d := bits.OnesCount64(n.fp ^ fp)
+		if d <= radius {
+			for _, id := range n.ids {
+				fn(id, d)
+			}
+		}
+		for _, c := range n.children {
+			if cd := int(c.dist); cd >= d-radius && cd <= d+radius {
+				stack = append(stack, c.node)
+			}
+		}
+	}
+}
+
+// Within 返回与 fp 的汉明距离 <= radius 的所有条目编号（升序）
+func (t *BKTree) Within(fp uint64, radius int) []int {
+	var ids []int
+	t.Search(fp, radius, func(id, _ int) { ids = append(ids, id) })
+	sort.Ints(ids)
+	return ids
+}
+
+// Build 以 fps 的下标为编号建树
+func Build(fps []uint64) *BKTree {
+	t := &BKTree{}
+	for i, fp := range fps {
+		t.Add(fp, i)
+	}
+	return t
+}
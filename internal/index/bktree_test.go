@@ -0,0 +1,49 @@
+// file: internal/index/bktree_test.go
+// package: index
+//
+// 测试 BK 树的近邻查询与逐个比较的结果一致（含重复指纹与空树）。
+package index
+
+import (
+	"math/bits"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestBKTreeWithin(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	var fps []uint64
+	for c := 0; c < 50; c++ {
+		center := rng.Uint64()
+		for k := 0; k < 6; k++ {
+			fp := center
+			for f := 0; f < rng.Intn(10); f++ {
+				fp ^= 1 << uint(rng.Intn(64))
+			}
+			fps = append(fps, fp)
+		}
+	}
+	fps = append(fps, fps[0], fps[0]) // 相同指纹
+	tree := Build(fps)
+	if tree.Len() != len(fps) {
+		t.Fatalf("Len = %d", tree.Len())
+	}
+	for _, r := range []int{0, 3, 8, 20} {
+		for q := 0; q < len(fps); q += 7 {
+			var want []int
+			for i, fp := range fps {
+				if bits.OnesCount64(fp^fps[q]) <= r {
+					want = append(want, i)
+				}
+			}
+			if got := tree.Within(fps[q], r); !reflect.DeepEqual(got, want) {
+				t.Fatalf("r=%d q=%d: %v，期望 %v", r, q, got, want)
+			}
+		}
+	}
+	var empty BKTree
+	if got := empty.Within(1, 64); len(got) != 0 {
+		t.Fatalf("空树查询结果 %v", got)
+	}
+}
@@ -0,0 +1,92 @@
+// file: internal/lock/lock.go
+// package: lock
+//
+// 运行锁：在目标目录中创建锁文件，防止两个进程同时写同一目标目录互相破坏。
+// 锁文件内容为持有者信息（PID / 主机名 / 开始时间），便于给出清晰的提示。
+package lock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// FileName 为目标目录中的锁文件名
+const FileName = ".audio-dedup.lock"
+
+// ErrLocked 表示锁已被其它进程持有
+var ErrLocked = errors.New("锁已被占用")
+
+// Info 为锁持有者信息
+type Info struct {
+	PID     int       `json:"pid"`
+	Host    string    `json:"host"`
+	Started time.Time `json:"started"`
+}
+
+// Lock 表示已获取的锁
+type Lock struct {
+	path string
+}
+
+// Acquire 以独占方式创建锁文件。锁已存在时返回包含持有者信息的 ErrLocked；
+// force 为 true 时直接接管已有的锁（用于上次运行异常退出留下的陈旧锁）。
+func Acquire(path string, force bool) (*Lock, error) {
+	host, _ := os.Hostname()
+	info := Info{PID: os.Getpid(), Host: host, Started: time.Now()}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	f, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			if holder, rerr := ReadInfo(path); rerr == nil {
+				return nil, fmt.Errorf("%w: %s 由 PID %d（主机 %s）于 %s 创建；若确认该进程已不在运行，可使用 -force 接管",
+					ErrLocked, path, holder.PID, holder.Host, holder.Started.Format(time.DateTime))
+			}
+			return nil, fmt.Errorf("%w: %s（无法读取持有者信息）；确认无其它运行后可使用 -force 接管", ErrLocked, path)
+		}
+		return nil, err
+	}
+	_, werr := f.Write(data)
+	if cerr := f.Close(); werr == nil {
+		werr = cerr
+	}
+	if werr != nil {
+		_ = os.Remove(path)
+		return nil, werr
+	}
+	return &Lock{path: path}, nil
+}
+
+// ReadInfo 读取锁文件中的持有者信息
+func ReadInfo(path string) (Info, error) {
+	var info Info
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return info, err
+	}
+	err = json.Unmarshal(b, &info)
+	return info, err
+}
+
+// Release 释放锁（删除锁文件），可重复调用
+func (l *Lock) Release() error {
+	if l == nil || l.path == "" {
+		return nil
+	}
+	err := os.Remove(l.path)
+	l.path = ""
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
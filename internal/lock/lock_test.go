@@ -0,0 +1,43 @@
+// file: internal/lock/lock_test.go
+// package: lock
+//
+// 测试锁的互斥、-force 接管与释放。
+package lock
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestAcquireExclusive(t *testing.T) {
+	p := filepath.Join(t.TempDir(), FileName)
+	l1, err := Acquire(p, false)
+	if err != nil {
+		t.Fatalf("首次获取锁失败: %v", err)
+	}
+	_, err = Acquire(p, false)
+	if !errors.Is(err, ErrLocked) {
+		t.Fatalf("期望 ErrLocked，实际 %v", err)
+	}
+	if !strings.Contains(err.Error(), strconv.Itoa(os.Getpid())) {
+		t.Fatalf("错误信息应包含持有者 PID: %v", err)
+	}
+
+	l2, err := Acquire(p, true)
+	if err != nil {
+		t.Fatalf("-force 接管失败: %v", err)
+	}
+	if err := l2.Release(); err != nil {
+		t.Fatalf("释放锁失败: %v", err)
+	}
+	if err := l1.Release(); err != nil {
+		t.Fatalf("重复释放不应报错: %v", err)
+	}
+	if _, err := os.Stat(p); !os.IsNotExist(err) {
+		t.Fatalf("释放后锁文件应被删除")
+	}
+}
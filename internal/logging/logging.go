@@ -0,0 +1,58 @@
+// file: internal/logging/logging.go
+// package: logging
+//
+// 结构化日志：基于 log/slog，支持按级别过滤（-log-level）与 text / json 两种输出格式（-log-format），
+// json 格式每行一个对象，便于被日志收集工具导入。Setup 同时设为 slog 与标准库 log 的默认输出，
+// 尚未改写的 log.Printf 调用以 info 级别经由同一个 handler 输出。
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Formats 为支持的输出格式
+var Formats = []string{"text", "json"}
+
+// ParseLevel 解析级别名：debug、info、warn（warning）、error，不区分大小写
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("未知的日志级别 %q（可选: debug, info, warn, error）", s)
+}
+
+// New 返回写入 w 的 logger
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+	switch strings.ToLower(format) {
+	case "", "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	}
+	return nil, fmt.Errorf("未知的日志格式 %q（可选: %s）", format, strings.Join(Formats, ", "))
+}
+
+// Setup 创建 logger 并设为默认（slog 与标准库 log）
+func Setup(w io.Writer, level, format string) error {
+	l, err := New(w, level, format)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(l)
+	return nil
+}
@@ -0,0 +1,48 @@
+// file: internal/logging/logging_test.go
+// package: logging
+//
+// 测试级别过滤、json 格式输出，以及标准库 log 的输出经由同一个 handler。
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSetupJSON(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	var buf bytes.Buffer
+	if err := Setup(&buf, "warn", "json"); err != nil {
+		t.Fatal(err)
+	}
+	slog.Info("不应输出")
+	slog.Warn("处理文件失败", "path", "/a.flac")
+	log.Printf("旧式日志\n") // info 级别，被过滤
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("应只有 1 行: %q", buf.String())
+	}
+	var rec map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec["level"] != "WARN" || rec["msg"] != "处理文件失败" || rec["path"] != "/a.flac" {
+		t.Fatalf("记录不对: %v", rec)
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	if _, err := New(&bytes.Buffer{}, "loud", "text"); err == nil {
+		t.Fatal("未知级别应报错")
+	}
+	if _, err := New(&bytes.Buffer{}, "info", "xml"); err == nil {
+		t.Fatal("未知格式应报错")
+	}
+	if l, _ := ParseLevel("WARNING"); l != slog.LevelWarn {
+		t.Fatalf("WARNING 应为 warn，实际 %v", l)
+	}
+}
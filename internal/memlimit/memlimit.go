@@ -0,0 +1,119 @@
+// file: internal/memlimit/memlimit.go
+// package: memlimit
+//
+// 内存上限与自适应背压：在开始每个解码任务前检查本进程 RSS 加上在途解码的估算开销
+// （ffmpeg 子进程 + PCM 缓冲），超过上限时阻塞等待，直到有任务结束或内存回落。
+// 至少允许 1 个任务在途，保证总能推进。
+package memlimit
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDecodeCost 为单个在途解码的估算内存（ffmpeg 子进程常驻内存 + PCM 缓冲）
+const DefaultDecodeCost = 32 << 20
+
+// Governor 按内存上限控制并发
+type Governor struct {
+	limit    uint64
+	cost     uint64
+	rss      func() uint64
+	mu       sync.Mutex
+	inflight int
+}
+
+// New 创建上限为 limit 字节的 Governor，并把 Go 运行时的软内存上限设为其一半，
+// 让 GC 更早回收 PCM 缓冲（剩余一半留给 ffmpeg 子进程）。
+func New(limit uint64) *Governor {
+	debug.SetMemoryLimit(int64(limit / 2))
+	return &Governor{limit: limit, cost: DefaultDecodeCost, rss: RSS}
+}
+
+// Acquire 阻塞直到允许开始一个新的解码任务
+func (g *Governor) Acquire() {
+	if g == nil {
+		return
+	}
+	waited := 0
+	for !g.TryAcquire() {
+		waited++
+		if waited%20 == 0 {
+			// 长时间等待时主动归还空闲内存，帮助 RSS 回落
+			debug.FreeOSMemory()
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// TryAcquire 不阻塞地尝试开始一个任务
+func (g *Governor) TryAcquire() bool {
+	if g == nil {
+		return true
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.inflight > 0 && g.rss()+uint64(g.inflight+1)*g.cost > g.limit {
+		return false
+	}
+	g.inflight++
+	return true
+}
+
+// Release 结束一个任务
+func (g *Governor) Release() {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	g.inflight--
+	g.mu.Unlock()
+}
+
+// RSS 返回本进程常驻内存（Linux 读取 /proc/self/statm，其他平台退回 Go 运行时统计）
+func RSS() uint64 {
+	if b, err := os.ReadFile("/proc/self/statm"); err == nil {
+		fields := strings.Fields(string(b))
+		if len(fields) >= 2 {
+			if pages, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+				return pages * uint64(os.Getpagesize())
+			}
+		}
+	}
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return ms.Sys - ms.HeapReleased
+}
+
+// ParseSize 解析 "2GB"、"512M"、"1048576" 这类大小（1024 进制）
+func ParseSize(s string) (uint64, error) {
+	t := strings.ToUpper(strings.TrimSpace(s))
+	t = strings.TrimSuffix(t, "B")
+	mult := uint64(1)
+	if t != "" {
+		switch t[len(t)-1] {
+		case 'K':
+			mult = 1 << 10
+		case 'M':
+			mult = 1 << 20
+		case 'G':
+			mult = 1 << 30
+		case 'T':
+			mult = 1 << 40
+		}
+		if mult != 1 {
+			t = t[:len(t)-1]
+		}
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(t), 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("无法解析大小 %q（示例：2GB、512MB）", s)
+	}
+	return uint64(n * float64(mult)), nil
+}
@@ -0,0 +1,46 @@
+// file: internal/memlimit/memlimit_test.go
+// package: memlimit
+//
+// 使用假的 RSS 测试背压行为与大小解析。
+package memlimit
+
+import "testing"
+
+func TestGovernorBackpressure(t *testing.T) {
+	rss := uint64(60)
+	g := &Governor{limit: 100, cost: 10, rss: func() uint64 { return rss }}
+
+	// 60 + 1*10, 60 + 2*10... 允许到第 4 个（60+40=100）
+	for i := 0; i < 4; i++ {
+		if !g.TryAcquire() {
+			t.Fatalf("第 %d 个任务应被允许", i+1)
+		}
+	}
+	if g.TryAcquire() {
+		t.Fatalf("超过上限时应阻塞")
+	}
+	g.Release()
+	if !g.TryAcquire() {
+		t.Fatalf("释放后应允许新任务")
+	}
+
+	// 即使 RSS 已超上限，也至少允许 1 个在途任务
+	rss = 1000
+	g2 := &Governor{limit: 100, cost: 10, rss: func() uint64 { return rss }}
+	if !g2.TryAcquire() || g2.TryAcquire() {
+		t.Fatalf("超限时应只允许 1 个在途任务")
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	cases := map[string]uint64{"2GB": 2 << 30, "512m": 512 << 20, "1024": 1024, "1.5K": 1536}
+	for in, want := range cases {
+		got, err := ParseSize(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseSize(%q) = %d, %v，期望 %d", in, got, err, want)
+		}
+	}
+	if _, err := ParseSize("lots"); err == nil {
+		t.Fatalf("非法输入应报错")
+	}
+}
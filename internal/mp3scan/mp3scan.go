@@ -0,0 +1,343 @@
+// file: internal/mp3scan/mp3scan.go
+// package: mp3scan
+//
+// MP3 帧完整性扫描：逐帧解析帧头，统计需要重新同步的损坏位置以及末尾被截断的帧；
+// 若首帧带 Xing/Info 头，还会与其中记录的帧数比对以发现截断。只解析帧头，不解码音频。
+package mp3scan
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// Stats 为扫描结果
+type Stats struct {
+	Frames    int  // 完整的帧数（含 Xing/Info 帧）
+	BadSpots  int  // 帧之间出现无法解析数据、需要重新同步的次数
+	Truncated bool // 最后一帧不完整，或帧数少于 Xing/Info 头记录的数量
+}
+
+// Errors 返回错误计数（截断计为 1），用于保留文件选择
+func (s Stats) Errors() int {
+	n := s.BadSpots
+	if s.Truncated {
+		n++
+	}
+	return n
+}
+
+var bitrates = [2][3][16]int{
+	{ // MPEG1：Layer I / II / III
+		{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448, 0},
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384, 0},
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0},
+	},
+	{ // MPEG2 / 2.5
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256, 0},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+	},
+}
+
+var sampleRates = [4][3]int{
+	{11025, 12000, 8000},  // MPEG2.5
+	{0, 0, 0},             // 保留
+	{22050, 24000, 16000}, // MPEG2
+	{44100, 48000, 32000}, // MPEG1
+}
+
+type header struct {
+	mpeg1      bool
+	layer      int // 1..3
+	mono       bool
+	length     int
+	bitrate    int // bit/s
+	sampleRate int
+}
+
+// samples 返回每帧的样本数
+func (h header) samples() int {
+	switch {
+	case h.layer == 1:
+		return 384
+	case h.layer == 3 && !h.mpeg1:
+		return 576
+	}
+	return 1152
+}
+
+// parseHeader 解析 b 开头的帧头，无效时返回 ok=false
+func parseHeader(b []byte) (h header, ok bool) {
+	if len(b) < 4 || b[0] != 0xFF || b[1]&0xE0 != 0xE0 {
+		return h, false
+	}
+	version := int(b[1]>>3) & 3
+	layerBits := int(b[1]>>1) & 3
+	brIdx := int(b[2] >> 4)
+	srIdx := int(b[2]>>2) & 3
+	if version == 1 || layerBits == 0 || brIdx == 0 || brIdx == 15 || srIdx == 3 {
+		return h, false
+	}
+	h.mpeg1 = version == 3
+	h.layer = 4 - layerBits
+	h.mono = b[3]>>6 == 3
+	pad := int(b[2]>>1) & 1
+	table := 1
+	if h.mpeg1 {
+		table = 0
+	}
+	br := bitrates[table][h.layer-1][brIdx] * 1000
+	sr := sampleRates[version][srIdx]
+	h.bitrate, h.sampleRate = br, sr
+	switch {
+	case h.layer == 1:
+		h.length = (12*br/sr + pad) * 4
+	case h.layer == 3 && !h.mpeg1:
+		h.length = 72*br/sr + pad
+	default:
+		h.length = 144*br/sr + pad
+	}
+	return h, h.length > 4
+}
+
+// xingOffset 返回首帧中 Xing/Info 头的位置（紧随帧头与 side info），不存在时返回 -1
+func xingOffset(frame []byte, h header) int {
+	off := 4 + 17
+	switch {
+	case h.mpeg1 && !h.mono:
+		off = 4 + 32
+	case !h.mpeg1 && h.mono:
+		off = 4 + 9
+	}
+	if len(frame) < off+8 {
+		return -1
+	}
+	if tag := string(frame[off : off+4]); tag != "Xing" && tag != "Info" {
+		return -1
+	}
+	return off
+}
+
+// xingFrames 读取首帧中的 Xing/Info 帧数，不存在时返回 -1
+func xingFrames(frame []byte, h header) int {
+	off := xingOffset(frame, h)
+	if off < 0 || len(frame) < off+12 || binary.BigEndian.Uint32(frame[off+4:])&1 == 0 {
+		return -1
+	}
+	return int(binary.BigEndian.Uint32(frame[off+8:]))
+}
+
+// Gapless 为无缝播放相关信息
+type Gapless struct {
+	Tagged     bool // 存在 LAME 标签（ffmpeg 会据此自动去掉编码器延迟与填充）
+	Delay      int  // 编码器延迟（样本数）
+	Padding    int  // 末尾填充（样本数）
+	SampleRate int
+}
+
+// lameDefaultDelay 为没有 LAME 标签时假定的开头多余样本数：LAME 默认编码延迟 576 + 解码器延迟 529
+const lameDefaultDelay = 576 + 529
+
+// ExtraLeading 返回与带 LAME 标签的同一编码相比，ffmpeg 解码此文件时开头多出的音频时长。
+// 带标签时 ffmpeg 已去掉延迟，返回 0；无标签时按 LAME 默认延迟估算。
+func (g Gapless) ExtraLeading() time.Duration {
+	if g.Tagged || g.SampleRate == 0 {
+		return 0
+	}
+	return time.Duration(lameDefaultDelay) * time.Second / time.Duration(g.SampleRate)
+}
+
+// ReadGapless 读取首帧中的 LAME 标签。只读取文件开头（跳过 ID3v2 标签后 8KB）。
+func ReadGapless(path string) (Gapless, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Gapless{}, err
+	}
+	defer f.Close()
+	buf, _, _, err := readHead(f)
+	if err != nil {
+		return Gapless{}, err
+	}
+	return parseGapless(buf), nil
+}
+
+// readHead 跳过 ID3v2 标签后读取 8KB，同时返回标签结束位置与文件大小
+func readHead(f *os.File) (buf []byte, start, size int64, err error) {
+	head := make([]byte, 10)
+	if _, err := io.ReadFull(f, head); err != nil {
+		return nil, 0, 0, err
+	}
+	if string(head[:3]) == "ID3" {
+		start = 10 + (int64(head[6])<<21 | int64(head[7])<<14 | int64(head[8])<<7 | int64(head[9]))
+		if head[5]&0x10 != 0 {
+			start += 10
+		}
+	}
+	buf = make([]byte, 8192)
+	n, err := f.ReadAt(buf, start)
+	if n == 0 && err != nil {
+		return nil, 0, 0, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return buf[:n], start, fi.Size(), nil
+}
+
+// ReadDuration 只根据帧头估算时长：首帧带 Xing/Info 或 VBRI 头时按其中的帧数计算，
+// 否则按首帧码率视为 CBR，用数据长度换算。
+func ReadDuration(path string) (time.Duration, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	buf, start, size, err := readHead(f)
+	if err != nil {
+		return 0, err
+	}
+	tail := make([]byte, 3)
+	if size-start >= 128 {
+		if _, err := f.ReadAt(tail, size-128); err == nil && string(tail) == "TAG" {
+			size -= 128 // ID3v1
+		}
+	}
+	d, ok := parseDuration(buf, size-start)
+	if !ok {
+		return 0, errors.New("未找到有效的 MP3 帧")
+	}
+	return d, nil
+}
+
+// parseDuration 在 b（ID3v2 之后的开头数据）中找到首帧并计算时长，audioBytes 为 ID3v2 之后的数据总长
+func parseDuration(b []byte, audioBytes int64) (time.Duration, bool) {
+	for pos := 0; pos+4 <= len(b); pos++ {
+		h, ok := parseHeader(b[pos:])
+		if !ok {
+			continue
+		}
+		if next := pos + h.length; next+4 <= len(b) {
+			if _, ok := parseHeader(b[next:]); !ok {
+				continue
+			}
+		}
+		frame := b[pos:min(pos+h.length, len(b))]
+		frames := xingFrames(frame, h)
+		if frames < 0 && len(frame) >= 4+32+18 && string(frame[4+32:4+36]) == "VBRI" {
+			// VBRI 头固定位于帧头后 32 字节：标识(4) 版本(2) 延迟(2) 质量(2) 字节数(4) 帧数(4)
+			frames = int(binary.BigEndian.Uint32(frame[4+32+14:]))
+		}
+		if frames >= 0 {
+			samples := int64(frames) * int64(h.samples())
+			return time.Duration(samples) * time.Second / time.Duration(h.sampleRate), true
+		}
+		bytes := audioBytes - int64(pos)
+		return time.Duration(bytes*8) * time.Second / time.Duration(h.bitrate), true
+	}
+	return 0, false
+}
+
+// parseGapless 在 b 中找到首帧并解析 LAME 标签
+func parseGapless(b []byte) Gapless {
+	for pos := 0; pos+4 <= len(b); pos++ {
+		h, ok := parseHeader(b[pos:])
+		if !ok {
+			continue
+		}
+		if next := pos + h.length; next+4 <= len(b) {
+			if _, ok := parseHeader(b[next:]); !ok {
+				continue
+			}
+		}
+		version := int(b[pos+1]>>3) & 3
+		g := Gapless{SampleRate: sampleRates[version][int(b[pos+2]>>2)&3]}
+		frame := b[pos:min(pos+h.length, len(b))]
+		off := xingOffset(frame, h)
+		if off < 0 {
+			return g
+		}
+		// Xing 头：标识(4) + 标志(4) + 可选的帧数(4)/字节数(4)/TOC(100)/质量(4)，其后为 LAME 标签
+		flags := binary.BigEndian.Uint32(frame[off+4:])
+		lame := off + 8
+		for bit, size := range []int{4, 4, 100, 4} {
+			if flags&(1<<bit) != 0 {
+				lame += size
+			}
+		}
+		if lame+24 > len(frame) || string(frame[lame:lame+4]) != "LAME" && string(frame[lame:lame+4]) != "Lavf" && string(frame[lame:lame+4]) != "Lavc" {
+			return g
+		}
+		d := frame[lame+21 : lame+24]
+		g.Tagged = true
+		g.Delay = int(d[0])<<4 | int(d[1])>>4
+		g.Padding = int(d[1]&0x0f)<<8 | int(d[2])
+		return g
+	}
+	return Gapless{}
+}
+
+// ScanFile 扫描文件
+func ScanFile(path string) (Stats, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Stats{}, err
+	}
+	return Scan(b), nil
+}
+
+// Scan 扫描内存中的 MP3 数据
+func Scan(b []byte) Stats {
+	var st Stats
+	pos, end := 0, len(b)
+	if end >= 10 && string(b[:3]) == "ID3" {
+		size := int(b[6])<<21 | int(b[7])<<14 | int(b[8])<<7 | int(b[9])
+		pos = 10 + size
+		if b[5]&0x10 != 0 {
+			pos += 10
+		}
+	}
+	if end-pos >= 128 && string(b[end-128:end-125]) == "TAG" {
+		end -= 128 // ID3v1
+	}
+
+	expected := -1
+	synced := false // 找到第一帧之前的填充不计为错误
+	for pos+4 <= end {
+		h, ok := parseHeader(b[pos:end])
+		if ok && !synced {
+			// 首帧要求紧随其后还有一个有效帧头（或恰好到结尾），避免把数据中的 0xFF 误当作帧头
+			next := pos + h.length
+			if next < end {
+				if _, ok2 := parseHeader(b[next:end]); !ok2 {
+					ok = false
+				}
+			}
+		}
+		if !ok {
+			if synced {
+				st.BadSpots++
+				synced = false
+			}
+			pos++
+			continue
+		}
+		if pos+h.length > end {
+			st.Truncated = true
+			break
+		}
+		if st.Frames == 0 {
+			expected = xingFrames(b[pos:pos+h.length], h)
+		}
+		st.Frames++
+		synced = true
+		pos += h.length
+	}
+	if expected >= 0 && st.Frames-1 < expected {
+		st.Truncated = true
+	}
+	return st
+}
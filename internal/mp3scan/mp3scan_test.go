@@ -0,0 +1,100 @@
+// file: internal/mp3scan/mp3scan_test.go
+// package: mp3scan
+//
+// 用合成的 MPEG1 Layer III 帧测试：完整流、中间插入垃圾数据、末帧截断、Xing 帧数不足。
+package mp3scan
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// frame 返回一个 128kbps / 44.1kHz / 立体声的帧（长度 417 字节）
+func frame() []byte {
+	f := make([]byte, 417)
+	copy(f, []byte{0xFF, 0xFB, 0x90, 0x00})
+	return f
+}
+
+func stream(n int) []byte {
+	var b bytes.Buffer
+	b.Write([]byte("ID3\x03\x00\x00\x00\x00\x00\x05abcde"))
+	for i := 0; i < n; i++ {
+		b.Write(frame())
+	}
+	return b.Bytes()
+}
+
+func TestScanClean(t *testing.T) {
+	st := Scan(stream(10))
+	if st.Frames != 10 || st.Errors() != 0 {
+		t.Fatalf("完整流: %+v", st)
+	}
+}
+
+func TestScanDamaged(t *testing.T) {
+	b := stream(4)
+	b = append(b, []byte("garbage!")...)
+	b = append(b, stream(3)[15:]...)
+	st := Scan(b)
+	if st.Frames != 7 || st.BadSpots != 1 || st.Truncated {
+		t.Fatalf("中间损坏: %+v", st)
+	}
+
+	st = Scan(stream(5)[:15+4*417+100])
+	if st.Frames != 4 || !st.Truncated || st.Errors() != 1 {
+		t.Fatalf("末帧截断: %+v", st)
+	}
+}
+
+func TestScanXingTruncation(t *testing.T) {
+	b := stream(6)
+	x := 15 + 4 + 32
+	copy(b[x:], "Xing")
+	binary.BigEndian.PutUint32(b[x+4:], 1)
+	binary.BigEndian.PutUint32(b[x+8:], 9) // 声称 9 个音频帧，实际只有 5 个
+	if st := Scan(b); !st.Truncated {
+		t.Fatalf("应根据 Xing 帧数判定截断: %+v", st)
+	}
+	binary.BigEndian.PutUint32(b[x+8:], 5)
+	if st := Scan(b); st.Errors() != 0 {
+		t.Fatalf("帧数一致时不应有错误: %+v", st)
+	}
+}
+
+func TestGapless(t *testing.T) {
+	b := stream(3)[15:]
+	x := 4 + 32
+	copy(b[x:], "Info")
+	binary.BigEndian.PutUint32(b[x+4:], 0x0f) // 帧数、字节数、TOC、质量都存在
+	lame := x + 8 + 4 + 4 + 100 + 4
+	copy(b[lame:], "LAME3.100")
+	// 延迟 576 (0x240)，填充 1000 (0x3E8)：0x24 0x03 0xE8
+	copy(b[lame+21:], []byte{0x24, 0x03, 0xE8})
+	g := parseGapless(b)
+	if !g.Tagged || g.Delay != 576 || g.Padding != 1000 || g.SampleRate != 44100 || g.ExtraLeading() != 0 {
+		t.Fatalf("LAME 标签解析错误: %+v", g)
+	}
+
+	g = parseGapless(stream(3)[15:])
+	if g.Tagged || g.ExtraLeading() != 1105*time.Second/44100 {
+		t.Fatalf("无标签时应估算默认延迟: %+v %v", g, g.ExtraLeading())
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	b := stream(6)[15:]
+	// 无 Xing 头：按 128kbps CBR 换算
+	if d, ok := parseDuration(b, int64(len(b))); !ok || d != time.Duration(len(b)*8)*time.Second/128000 {
+		t.Fatalf("CBR 时长 = %v, %v", d, ok)
+	}
+	// Xing 头记录 1000 帧，与文件实际长度无关
+	copy(b[4+32:], "Xing")
+	binary.BigEndian.PutUint32(b[4+36:], 1)
+	binary.BigEndian.PutUint32(b[4+40:], 1000)
+	if d, _ := parseDuration(b, int64(len(b))); d != 1000*1152*time.Second/44100 {
+		t.Fatalf("Xing 时长 = %v", d)
+	}
+}
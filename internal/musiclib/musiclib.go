@@ -0,0 +1,85 @@
+// file: internal/musiclib/musiclib.go
+// package: musiclib
+//
+// 识别 macOS「音乐」/ iTunes 管理的媒体文件夹。这类目录中的文件由应用的资料库数据库索引，
+// 绕过应用直接删除/移动文件会让资料库出现大量"找不到原始文件"的条目。
+// 检测到后主程序切换到安全模式：不改动任何文件，只生成报告，并可导出一个 m3u8 播放列表
+// 作为去重计划——在应用中"文件 > 导入"该列表，全选后"从资料库中删除"即可由应用自己完成清理。
+package musiclib
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"deduplicateMusic/internal/gzfile"
+)
+
+// markers 为资料库根目录下的标志文件/目录
+var markers = []string{
+	"Music Library.musiclibrary", // 音乐 App（macOS 10.15+）
+	"iTunes Library.itl",
+	"iTunes Music Library.xml",
+	"iTunes Library.xml",
+}
+
+// Detect 从 src 向上查找资料库标志，找到时返回资料库根目录。
+// 典型布局：~/Music/Music/Media.localized 或 ~/Music/iTunes/iTunes Media，
+// 标志文件位于媒体文件夹的上一级。
+func Detect(src string) (root string, ok bool) {
+	dir, err := filepath.Abs(src)
+	if err != nil {
+		return "", false
+	}
+	for {
+		for _, m := range markers {
+			if _, err := os.Stat(filepath.Join(dir, m)); err == nil {
+				return dir, true
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// PlanEntry 为去重计划中的一个待删除文件
+type PlanEntry struct {
+	Path     string
+	Seconds  int    // 时长（秒），未知时为 -1
+	Title    string // 显示名，为空时使用文件名
+	KeptPath string
+}
+
+// WritePlan 把待删除文件写成 m3u8 播放列表（UTF-8、绝对路径），可直接导入音乐 App / iTunes
+func WritePlan(path string, entries []PlanEntry) error {
+	f, err := gzfile.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	fmt.Fprintln(w, "#EXTM3U")
+	for _, e := range entries {
+		p, err := filepath.Abs(e.Path)
+		if err != nil {
+			p = e.Path
+		}
+		title := e.Title
+		if title == "" {
+			title = filepath.Base(e.Path)
+		}
+		if e.KeptPath != "" {
+			// 普通播放器会忽略以 # 开头的未知行
+			fmt.Fprintf(w, "# kept: %s\n", e.KeptPath)
+		}
+		fmt.Fprintf(w, "#EXTINF:%d,%s\n%s\n", e.Seconds, title, p)
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
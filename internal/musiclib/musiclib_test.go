@@ -0,0 +1,42 @@
+// file: internal/musiclib/musiclib_test.go
+// package: musiclib
+//
+// 测试资料库检测（向上查找标志文件）以及计划文件格式。
+package musiclib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	root := t.TempDir()
+	media := filepath.Join(root, "iTunes", "iTunes Media", "Music")
+	if err := os.MkdirAll(media, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := Detect(media); ok {
+		t.Fatal("没有标志文件时不应识别为资料库")
+	}
+	if err := os.WriteFile(filepath.Join(root, "iTunes", "iTunes Library.itl"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := Detect(media)
+	if !ok || got != filepath.Join(root, "iTunes") {
+		t.Fatalf("Detect = %q, %v", got, ok)
+	}
+}
+
+func TestWritePlan(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "plan.m3u8")
+	err := WritePlan(out, []PlanEntry{{Path: "/m/a.mp3", Seconds: -1, KeptPath: "/m/b.flac"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := os.ReadFile(out)
+	want := "#EXTM3U\n# kept: /m/b.flac\n#EXTINF:-1,a.mp3\n/m/a.mp3\n"
+	if string(b) != want {
+		t.Fatalf("计划内容:\n%s", b)
+	}
+}
@@ -0,0 +1,129 @@
+// file: internal/overrides/nevermatch.go
+// package: overrides
+//
+// 误判清单（never-match）：用户标记为误判的文件对或整组，以 CSV 保存，每行两个或更多路径，
+// 行内任意两个路径之后都不会再被合并（与覆盖文件中的 apart 等效）。可以手工编辑，
+// 也可以用 AppendRow（-mark-false-positive）追加，每次标记都在之后的运行中持续生效。
+// 确认清单（confirmed）格式相同，记录用户确认为真重复的文件，只用于阈值校准（见 internal/calibrate），不影响分组。
+package overrides
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// NeverMatchFile 为默认误判清单文件名
+const NeverMatchFile = "never-match.csv"
+
+// ConfirmedFile 为默认确认清单文件名
+const ConfirmedFile = "confirmed.csv"
+
+// NeverMatchPath 返回默认误判清单位置（与覆盖文件同目录）
+func NeverMatchPath() string { return besideDefault(NeverMatchFile) }
+
+// ConfirmedPath 返回默认确认清单位置（与覆盖文件同目录）
+func ConfirmedPath() string { return besideDefault(ConfirmedFile) }
+
+func besideDefault(name string) string {
+	if p := DefaultPath(); p != "" {
+		return filepath.Join(filepath.Dir(p), name)
+	}
+	return ""
+}
+
+// LoadNeverMatch 读取误判清单，把每行中的路径两两设为不可合并。# 开头的行为注释。
+func (s *Set) LoadNeverMatch(path string) error {
+	return readRows(path, s.addApart)
+}
+
+// LoadConfirmed 读取确认清单，把每行中的路径两两记入 Confirmed
+func (s *Set) LoadConfirmed(path string) error {
+	return readRows(path, func(a, b string) error {
+		a, b = absPath(a), absPath(b)
+		if a == b {
+			return fmt.Errorf("路径重复: %s", a)
+		}
+		s.Confirmed = append(s.Confirmed, [2]string{a, b})
+		return nil
+	})
+}
+
+// readRows 读取每行两个或更多路径的 CSV，对行内每对路径调用 pair
+func readRows(path string, pair func(a, b string) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.Comment = '#'
+	for {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		line, _ := r.FieldPos(0)
+		paths := nonEmpty(rec)
+		if len(paths) < 2 {
+			return fmt.Errorf("%s:%d: 每行至少需要两个路径", path, line)
+		}
+		for i := range paths {
+			for _, q := range paths[i+1:] {
+				if err := pair(paths[i], q); err != nil {
+					return fmt.Errorf("%s:%d: %w", path, line, err)
+				}
+			}
+		}
+	}
+}
+
+// AppendRow 把一组路径（转为绝对路径）作为一行追加到误判或确认清单，文件不存在时创建
+func AppendRow(path string, paths []string) error {
+	paths = nonEmpty(paths)
+	if len(paths) < 2 {
+		return fmt.Errorf("至少需要两个路径")
+	}
+	rec := make([]string, len(paths))
+	seen := map[string]bool{}
+	for i, p := range paths {
+		rec[i] = absPath(p)
+		if seen[rec[i]] {
+			return fmt.Errorf("路径重复: %s", rec[i])
+		}
+		seen[rec[i]] = true
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	w.Write(rec)
+	w.Flush()
+	err = w.Error()
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func nonEmpty(fields []string) []string {
+	var out []string
+	for _, f := range fields {
+		if f = strings.TrimSpace(f); f != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
@@ -0,0 +1,205 @@
+// file: internal/overrides/overrides.go
+// package: overrides
+//
+// 人工覆盖文件：用户固定的分组决定，每次运行都在自动选择之前生效。每行一条 "类型: 路径"，# 开头为注释：
+//   - keep:  该文件所在的组总是保留它（优先于保护规则、保留策略与已记录的决定）
+//   - apart: 用 " | " 分隔的两个路径永远不合并到同一组
+//
+// 示例：
+//
+//	keep: /music/Masters/x.flac
+//	apart: /music/live/x.flac | /music/studio/x.flac
+//
+// 路径按绝对路径比较，相对路径相对于运行时的当前目录。分组 ID 每次运行都可能变化，
+// 因此覆盖以文件路径而不是组号表示。
+package overrides
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"deduplicateMusic/internal/dedup"
+)
+
+// FileName 为默认覆盖文件名
+const FileName = "overrides.txt"
+
+// DefaultPath 返回默认覆盖文件位置（用户配置目录下）
+func DefaultPath() string {
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, "audio-dedup", FileName)
+	}
+	return ""
+}
+
+// Set 为一组覆盖
+type Set struct {
+	Keep  map[string]bool     // 固定保留的文件（绝对路径）
+	Apart map[string][]string // 路径 -> 不可与之合并的路径（双向记录，绝对路径）
+	// Confirmed 为确认清单中的文件对（绝对路径），只用于阈值校准
+	Confirmed [][2]string
+	pairs     int
+}
+
+// Empty 返回是否没有任何覆盖
+func (s *Set) Empty() bool { return s == nil || (len(s.Keep) == 0 && s.pairs == 0) }
+
+// Len 返回 keep 与 apart 条目数
+func (s *Set) Len() (keep, apart int) {
+	if s == nil {
+		return 0, 0
+	}
+	return len(s.Keep), s.pairs
+}
+
+// Load 从文件读取覆盖
+func Load(path string) (*Set, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	set := &Set{}
+	sc := bufio.NewScanner(f)
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kind, arg, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: 缺少 \"类型:\" 前缀", path, lineNo)
+		}
+		if err := set.Add(strings.TrimSpace(kind), strings.TrimSpace(arg)); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNo, err)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// Add 加入一条覆盖，kind 为 keep 或 apart
+func (s *Set) Add(kind, arg string) error {
+	if s.Keep == nil {
+		s.Keep, s.Apart = map[string]bool{}, map[string][]string{}
+	}
+	switch strings.ToLower(kind) {
+	case "keep":
+		if arg == "" {
+			return fmt.Errorf("keep 缺少路径")
+		}
+		s.Keep[absPath(arg)] = true
+	case "apart":
+		a, b, ok := strings.Cut(arg, "|")
+		a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+		if !ok || a == "" || b == "" {
+			return fmt.Errorf("apart 应为 \"路径 | 路径\"")
+		}
+		return s.addApart(a, b)
+	default:
+		return fmt.Errorf("未知覆盖类型: %s（应为 keep 或 apart）", kind)
+	}
+	return nil
+}
+
+// addApart 记录一对不可合并的路径
+func (s *Set) addApart(a, b string) error {
+	if s.Keep == nil {
+		s.Keep, s.Apart = map[string]bool{}, map[string][]string{}
+	}
+	a, b = absPath(a), absPath(b)
+	if a == b {
+		return fmt.Errorf("apart 的两个路径相同: %s", a)
+	}
+	s.Apart[a] = append(s.Apart[a], b)
+	s.Apart[b] = append(s.Apart[b], a)
+	s.pairs++
+	return nil
+}
+
+// Pinned 返回文件是否被 keep 固定
+func (s *Set) Pinned(m dedup.FileMeta) bool {
+	return s != nil && len(s.Keep) > 0 && s.Keep[absPath(m.Path)]
+}
+
+// Separated 返回两个文件是否被 apart 分开
+func (s *Set) Separated(a, b dedup.FileMeta) bool {
+	if s == nil || s.pairs == 0 {
+		return false
+	}
+	others := s.Apart[absPath(a.Path)]
+	if len(others) == 0 {
+		return false
+	}
+	pb := absPath(b.Path)
+	for _, o := range others {
+		if o == pb {
+			return true
+		}
+	}
+	return false
+}
+
+// ApartPairs 返回所有不可合并的文件对（每对一次，按路径排序）
+func (s *Set) ApartPairs() [][2]string {
+	if s == nil {
+		return nil
+	}
+	var out [][2]string
+	for a, others := range s.Apart {
+		for _, b := range others {
+			if a < b {
+				out = append(out, [2]string{a, b})
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i][0] != out[j][0] {
+			return out[i][0] < out[j][0]
+		}
+		return out[i][1] < out[j][1]
+	})
+	return out
+}
+
+// Apply 把覆盖应用到去重选项：固定保留的文件视为受保护并排在策略最前，apart 对设为不可合并（与已有的约束叠加）
+func (s *Set) Apply(opts *dedup.Options) {
+	if s.Empty() {
+		return
+	}
+	if len(s.Keep) > 0 {
+		protect, base := opts.Protect, opts.Policy
+		if base == nil {
+			base = dedup.DefaultPolicy()
+		}
+		opts.Protect = func(m dedup.FileMeta) bool {
+			return s.Pinned(m) || (protect != nil && protect(m))
+		}
+		opts.Policy = dedup.KeepPolicyFunc(func(a, b dedup.FileMeta) bool {
+			pa, pb := s.Pinned(a), s.Pinned(b)
+			if pa != pb {
+				return pa
+			}
+			return base.Better(a, b)
+		})
+	}
+	if s.pairs > 0 {
+		opts.Apart = dedup.JoinApart(opts.Apart, s.Separated)
+	}
+}
+
+func absPath(p string) string {
+	if abs, err := filepath.Abs(p); err == nil {
+		return abs
+	}
+	return filepath.Clean(p)
+}
@@ -0,0 +1,110 @@
+// file: internal/overrides/overrides_test.go
+// package: overrides
+//
+// 测试覆盖文件的解析，以及 keep 固定保留文件、apart 拆开经由中间文件相连的两个文件。
+package overrides
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"deduplicateMusic/internal/dedup"
+)
+
+func TestLoad(t *testing.T) {
+	p := filepath.Join(t.TempDir(), FileName)
+	content := "# 注释\nkeep: /music/Masters/x.flac\napart: /a.flac | /b.flac\n"
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k, a := s.Len(); k != 1 || a != 1 {
+		t.Fatalf("Len = %d, %d", k, a)
+	}
+	if !s.Pinned(dedup.FileMeta{Path: "/music/Masters/x.flac"}) || s.Pinned(dedup.FileMeta{Path: "/a.flac"}) {
+		t.Fatal("Pinned 结果不对")
+	}
+	if !s.Separated(dedup.FileMeta{Path: "/b.flac"}, dedup.FileMeta{Path: "/a.flac"}) {
+		t.Fatal("apart 应双向生效")
+	}
+	for _, bad := range []string{"keep:", "apart: /a.flac", "apart: /a.flac | /a.flac", "drop: /a.flac", "/a.flac"} {
+		if err := os.WriteFile(p, []byte(bad+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(p); err == nil || !strings.Contains(err.Error(), ":1:") {
+			t.Fatalf("%q 应报错并给出行号: %v", bad, err)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	// a~b、b~c 相连，a 与 c 不直接匹配
+	files := []dedup.FileMeta{
+		{Path: "/a.flac", Size: 300, FP: 0b0000},
+		{Path: "/b.mp3", Size: 100, FP: 0b0011},
+		{Path: "/c.mp3", Size: 200, FP: 0b1111},
+	}
+	s := &Set{}
+	s.Add("keep", "/b.mp3")
+	opts := dedup.Options{Threshold: 2}
+	s.Apply(&opts)
+	groups := dedup.GroupWith(files, opts)
+	if len(groups) != 1 || groups[0].Keep.Path != "/b.mp3" || len(groups[0].Duplicates) != 2 {
+		t.Fatalf("keep 应固定保留 /b.mp3: %+v", groups)
+	}
+
+	s.Add("apart", "/a.flac | /c.mp3")
+	opts = dedup.Options{Threshold: 2}
+	s.Apply(&opts)
+	groups = dedup.GroupWith(files, opts)
+	if len(groups) != 2 {
+		t.Fatalf("apart 应拆成两组: %+v", groups)
+	}
+	for _, g := range groups {
+		paths := []string{g.Keep.Path}
+		for _, d := range g.Duplicates {
+			paths = append(paths, d.Path)
+		}
+		joined := strings.Join(paths, ",")
+		if strings.Contains(joined, "/a.flac") && strings.Contains(joined, "/c.mp3") {
+			t.Fatalf("/a.flac 与 /c.mp3 不应同组: %s", joined)
+		}
+	}
+}
+
+func TestNeverMatch(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "sub", NeverMatchFile)
+	if err := AppendRow(p, []string{"/a.flac", " /b.flac "}); err != nil {
+		t.Fatal(err)
+	}
+	if err := AppendRow(p, []string{"/c.flac", "/d,1.flac", "/e.flac"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := AppendRow(p, []string{"/a.flac"}); err == nil {
+		t.Fatal("只有一个路径应报错")
+	}
+	if err := AppendRow(p, []string{"/a.flac", "/a.flac"}); err == nil {
+		t.Fatal("路径重复应报错")
+	}
+	s := &Set{}
+	if err := s.LoadNeverMatch(p); err != nil {
+		t.Fatal(err)
+	}
+	if _, aparts := s.Len(); aparts != 4 {
+		t.Fatalf("应有 1 + 3 对，实际 %d", aparts)
+	}
+	if !s.Separated(dedup.FileMeta{Path: "/e.flac"}, dedup.FileMeta{Path: "/d,1.flac"}) || s.Separated(dedup.FileMeta{Path: "/a.flac"}, dedup.FileMeta{Path: "/c.flac"}) {
+		t.Fatal("Separated 结果不对")
+	}
+	if err := os.WriteFile(p, []byte("# 注释\n/a.flac\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&Set{}).LoadNeverMatch(p); err == nil || !strings.Contains(err.Error(), ":2:") {
+		t.Fatalf("单个路径的行应报错并给出行号: %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+// file: internal/preview/preview.go
+// package: preview
+//
+// 试听片段：为需要人工核对的分组（含重复文件的组）中每个成员，从文件中点截取一小段，
+// 做响度归一化后编码为 OGG，放到 previews 目录。即使原文件是慢速存储上的大体积 FLAC，
+// 对比试听也只需读取几十 KB 的小文件。索引写在 previews/index.csv。
+package preview
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"deduplicateMusic/internal/fingerprint"
+	"deduplicateMusic/internal/quota"
+)
+
+// Clip 为生成的一个试听片段
+type Clip struct {
+	GroupID int
+	Source  string // 原文件
+	Role    string // keep / duplicate
+	File    string // 片段文件路径
+}
+
+var unsafeChars = regexp.MustCompile(`[^\p{L}\p{N}._-]+`)
+
+// ClipName 返回片段文件名，如 g0003_2_dup_song.ogg
+func ClipName(groupID, index int, role, source string) string {
+	base := strings.TrimSuffix(filepath.Base(source), filepath.Ext(source))
+	base = strings.Trim(unsafeChars.ReplaceAllString(base, "_"), "_")
+	if r := []rune(base); len(r) > 40 {
+		base = string(r[:40])
+	}
+	return fmt.Sprintf("g%04d_%d_%s_%s.ogg", groupID, index, role, base)
+}
+
+// Render 从 src 中点截取 seconds 秒，做 loudnorm 响度归一化后编码为 OGG 写到 dst
+func Render(src, dst string, seconds int) error {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return errors.New("ffmpeg 未找到，无法生成试听片段")
+	}
+	start := 0.0
+	if d, err := fingerprint.ProbeDuration(src); err == nil && d > float64(seconds) {
+		start = (d - float64(seconds)) / 2
+	}
+	args := []string{"-v", "error", "-y", "-ss", fmt.Sprintf("%.2f", start), "-t", fmt.Sprint(seconds),
+		"-i", src, "-vn", "-af", "loudnorm", "-ac", "2", "-ar", "44100", "-c:a", "libvorbis", "-q:a", "3", dst}
+	cmd := exec.Command("ffmpeg", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	release := quota.AcquireFFmpeg()
+	err := cmd.Run()
+	release()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return fmt.Errorf("生成试听片段失败 %s: %s", src, msg)
+	}
+	return nil
+}
+
+// WriteIndex 把片段列表写到 dir/index.csv
+func WriteIndex(dir string, clips []Clip) error {
+	f, err := os.Create(filepath.Join(dir, "index.csv"))
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	_ = w.Write([]string{"GroupID", "Role", "Source", "Preview"})
+	for _, c := range clips {
+		_ = w.Write([]string{fmt.Sprint(c.GroupID), c.Role, c.Source, filepath.Base(c.File)})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,28 @@
+// file: internal/preview/preview_test.go
+// package: preview
+//
+// 测试片段文件名的清理与截断。
+package preview
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClipName(t *testing.T) {
+	got := ClipName(3, 2, "duplicate", "/music/A/01 - 晴天 (Live)?.flac")
+	if got != "g0003_2_duplicate_01_-_晴天_Live.ogg" {
+		t.Fatalf("片段文件名不正确: %s", got)
+	}
+	long := ClipName(1, 0, "keep", "/x/"+strings.Repeat("a", 100)+".mp3")
+	if len(long) > 80 {
+		t.Fatalf("过长的文件名应被截断: %s", long)
+	}
+}
+
+func TestWaveformSVG(t *testing.T) {
+	svg := string(WaveformSVG([]uint8{0, 255, 128}, 30, 10))
+	if !strings.HasPrefix(svg, "<svg") || !strings.Contains(svg, `d="M0.0 5.0L10.0 0.0L20.0 2.5`) {
+		t.Fatalf("SVG 不正确: %s", svg)
+	}
+}
@@ -0,0 +1,46 @@
+// file: internal/preview/waveform.go
+// package: preview
+//
+// 波形缩略图：用指纹计算时已解码的 PCM 得到的包络（见 fingerprint.Envelope）生成小尺寸 SVG，
+// 无需再次解码。注意包络只覆盖用于指纹的那段音频（-seconds）。
+package preview
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+)
+
+// WaveformSVG 把包络渲染为 width x height 的对称波形 SVG
+func WaveformSVG(env []uint8, width, height int) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, width, height, width, height)
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="#f4f4f4"/>`)
+	if len(env) > 0 {
+		mid := float64(height) / 2
+		step := float64(width) / float64(len(env))
+		b.WriteString(`<path fill="#3b6ea5" d="`)
+		// 上半部分从左到右，下半部分从右到左，闭合成对称波形
+		for i, v := range env {
+			x := float64(i) * step
+			y := mid - float64(v)/255*mid
+			if i == 0 {
+				fmt.Fprintf(&b, "M%.1f %.1f", x, y)
+			} else {
+				fmt.Fprintf(&b, "L%.1f %.1f", x, y)
+			}
+		}
+		for i := len(env) - 1; i >= 0; i-- {
+			x := float64(i) * step
+			fmt.Fprintf(&b, "L%.1f %.1f", x, mid+float64(env[i])/255*mid)
+		}
+		b.WriteString(`Z"/>`)
+	}
+	b.WriteString(`</svg>`)
+	return b.Bytes()
+}
+
+// WriteWaveform 把波形 SVG 写到 path
+func WriteWaveform(path string, env []uint8) error {
+	return os.WriteFile(path, WaveformSVG(env, 240, 48), 0o644)
+}
@@ -0,0 +1,194 @@
+// file: internal/progress/progress.go
+// package: progress
+//
+// 进度显示：按阶段（扫描、指纹、比较、处理）统计完成数量，输出进度条、吞吐量与预计剩余时间。
+// 输出到终端时在同一行原地刷新；输出被重定向（日志文件、管道）时每隔一段时间打印一行，避免刷屏。
+// 日志与进度条共用 stderr，Writer 返回的包装在写日志前先清除进度条（下次刷新时重画），两者不会交错。
+// 所有方法对 nil *Bar 是空操作（-quiet 时即为 nil）。
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ttyInterval  = 200 * time.Millisecond // 终端刷新间隔
+	lineInterval = 10 * time.Second       // 非终端时打印间隔
+	barWidth     = 24
+)
+
+// Bar 为一个分阶段的进度条
+type Bar struct {
+	w   io.Writer
+	tty bool
+
+	mu    sync.Mutex
+	stage string
+	total int64 // 0 表示总数未知
+	done  int64
+	start time.Time
+	shown bool // 终端上当前行是否有进度条
+	lines bool // 非终端时本阶段是否已打印过进度行
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New 返回输出到 f 的进度条，quiet 为 true 时返回 nil
+func New(f *os.File, quiet bool) *Bar {
+	if quiet {
+		return nil
+	}
+	return &Bar{w: f, tty: IsTerminal(f)}
+}
+
+// IsTerminal 返回 f 是否为终端（字符设备）
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Stage 结束上一阶段并开始新阶段，total 为该阶段的总数（未知时为 0）
+func (b *Bar) Stage(name string, total int) {
+	if b == nil {
+		return
+	}
+	b.Finish()
+	b.mu.Lock()
+	b.stage, b.total, b.done = name, int64(total), 0
+	b.start, b.lines = time.Now(), false
+	b.stop = make(chan struct{})
+	b.mu.Unlock()
+	b.wg.Add(1)
+	go b.run(b.stop)
+}
+
+// Add 记录当前阶段完成了 n 项
+func (b *Bar) Add(n int) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	b.done += int64(n)
+	b.mu.Unlock()
+}
+
+// Finish 结束当前阶段并打印该阶段的最终一行（没有进行中的阶段时为空操作）。
+// 非终端时只有打印过进度行的阶段才打印最终一行，很快完成的阶段不在日志中留下记录。
+func (b *Bar) Finish() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	stop := b.stop
+	b.stop = nil
+	b.mu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	b.wg.Wait()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.clear()
+	if b.tty || b.lines {
+		fmt.Fprintln(b.w, Line(b.stage, b.done, b.total, time.Since(b.start), true))
+	}
+}
+
+func (b *Bar) run(stop chan struct{}) {
+	defer b.wg.Done()
+	interval := ttyInterval
+	if !b.tty {
+		interval = lineInterval
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-t.C:
+			b.mu.Lock()
+			b.draw()
+			b.mu.Unlock()
+		}
+	}
+}
+
+// draw 画出当前进度（调用方持有 mu）
+func (b *Bar) draw() {
+	line := Line(b.stage, b.done, b.total, time.Since(b.start), false)
+	if !b.tty {
+		fmt.Fprintln(b.w, line)
+		b.lines = true
+		return
+	}
+	fmt.Fprint(b.w, "\r\x1b[K"+line)
+	b.shown = true
+}
+
+// clear 清除终端上的进度条（调用方持有 mu）
+func (b *Bar) clear() {
+	if b.shown {
+		fmt.Fprint(b.w, "\r\x1b[K")
+		b.shown = false
+	}
+}
+
+// Writer 返回写入 w 的包装：每次写入前清除进度条，之后由下次刷新重画。用于 log.SetOutput。
+func (b *Bar) Writer(w io.Writer) io.Writer {
+	if b == nil || !b.tty {
+		return w
+	}
+	return writerFunc(func(p []byte) (int, error) {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.clear()
+		return w.Write(p)
+	})
+}
+
+type writerFunc func([]byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
+
+// Line 格式化一行进度：阶段、进度条（总数已知时）、完成数、吞吐量与预计剩余时间；final 为阶段结束时的汇总行
+func Line(stage string, done, total int64, elapsed time.Duration, final bool) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%-4s ", stage)
+	rate := 0.0
+	if secs := elapsed.Seconds(); secs > 0 {
+		rate = float64(done) / secs
+	}
+	if total > 0 {
+		frac := float64(done) / float64(total)
+		if frac > 1 {
+			frac = 1
+		}
+		filled := int(frac * barWidth)
+		fmt.Fprintf(&sb, "[%s%s] %d/%d %5.1f%%", strings.Repeat("#", filled), strings.Repeat(".", barWidth-filled), done, total, frac*100)
+	} else if done > 0 {
+		fmt.Fprintf(&sb, "%d", done)
+	}
+	if final {
+		fmt.Fprintf(&sb, "  用时 %s", elapsed.Round(time.Second))
+		return sb.String()
+	}
+	if rate > 0 {
+		fmt.Fprintf(&sb, "  %.1f 个/秒", rate)
+	}
+	switch {
+	case total > 0 && rate > 0 && done < total:
+		eta := time.Duration(float64(total-done) / rate * float64(time.Second))
+		fmt.Fprintf(&sb, "  剩余 %s", eta.Round(time.Second))
+	case total == 0:
+		fmt.Fprintf(&sb, "  已用 %s", elapsed.Round(time.Second))
+	}
+	return sb.String()
+}
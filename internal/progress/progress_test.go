@@ -0,0 +1,37 @@
+// file: internal/progress/progress_test.go
+// package: progress
+//
+// 测试进度行的格式（进度条、吞吐量、预计剩余时间）与 nil 进度条的空操作。
+package progress
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLine(t *testing.T) {
+	got := Line("指纹", 250, 1000, 50*time.Second, false)
+	for _, want := range []string{"[######..................]", "250/1000", "25.0%", "5.0 个/秒", "剩余 2m30s"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("%q 缺少 %q", got, want)
+		}
+	}
+	if got := Line("扫描", 0, 0, 3*time.Second, false); !strings.Contains(got, "已用 3s") || strings.Contains(got, "[") {
+		t.Fatalf("总数未知时不应有进度条: %q", got)
+	}
+	if got := Line("处理", 10, 10, 90*time.Second, true); !strings.HasSuffix(got, "用时 1m30s") || strings.Contains(got, "剩余") {
+		t.Fatalf("最终行: %q", got)
+	}
+}
+
+func TestNilBar(t *testing.T) {
+	var b *Bar
+	b.Stage("扫描", 1)
+	b.Add(1)
+	b.Finish()
+	var sb strings.Builder
+	if b.Writer(&sb) != &sb {
+		t.Fatal("nil 进度条应原样返回 Writer")
+	}
+}
@@ -0,0 +1,149 @@
+// file: internal/provenance/provenance.go
+// package: provenance
+//
+// 来源追溯：为每个放入目标目录的文件记录它来自哪个源根目录、源内的相对路径以及哪一次运行。
+// 记录追加到目标目录下的 ManifestFile（JSON Lines，多次运行累积，不改写已有行），
+// 日后可用 Lookup（-provenance-of）回答“这个文件是从哪里来的”。
+// 可选地把同样的信息写入文件的扩展属性（见 SetXattr），文件被移出目标目录后仍可追溯。
+package provenance
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ManifestFile 为目标目录中的来源清单文件名
+const ManifestFile = ".audio-dedup-provenance.jsonl"
+
+// Record 为一个目标文件的来源
+type Record struct {
+	Path       string    `json:"path"`        // 目标文件相对目标目录的路径（/ 分隔）
+	SourceRoot string    `json:"source_root"` // 源根目录（或远程源 URL）
+	SourcePath string    `json:"source_path"` // 相对源根目录的路径（/ 分隔）；不在源根目录下时为完整路径
+	RunID      string    `json:"run_id"`
+	Mode       string    `json:"mode,omitempty"` // copy / move / hardlink / symlink
+	Size       int64     `json:"size"`
+	Time       time.Time `json:"time"`
+}
+
+// ErrXattrUnsupported 表示当前系统不支持写入扩展属性
+var ErrXattrUnsupported = errors.New("当前系统不支持写入扩展属性")
+
+// Rel 返回 p 相对 root 的路径（/ 分隔），p 不在 root 下时原样返回
+func Rel(root, p string) string {
+	if strings.Contains(root, "://") {
+		if rest, ok := strings.CutPrefix(p, strings.TrimSuffix(root, "/")+"/"); ok {
+			return rest
+		}
+		return p
+	}
+	rel, err := filepath.Rel(root, p)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return p
+	}
+	return filepath.ToSlash(rel)
+}
+
+// Source 返回记录用的源根目录与相对路径：本地根目录换算为绝对路径，换了工作目录后仍可追溯
+func Source(root, p string) (string, string) {
+	if !strings.Contains(root, "://") {
+		if abs, err := filepath.Abs(root); err == nil {
+			if absP, err := filepath.Abs(p); err == nil {
+				return abs, Rel(abs, absP)
+			}
+		}
+	}
+	return root, Rel(root, p)
+}
+
+// Writer 向目标目录的来源清单追加记录
+type Writer struct {
+	root string
+	f    *os.File
+	w    *bufio.Writer
+}
+
+// Open 以追加方式打开 dstRoot 下的来源清单
+func Open(dstRoot string) (*Writer, error) {
+	f, err := os.OpenFile(filepath.Join(dstRoot, ManifestFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("打开来源清单失败: %w", err)
+	}
+	return &Writer{root: dstRoot, f: f, w: bufio.NewWriter(f)}, nil
+}
+
+// Add 追加一条记录；r.Path 为目标文件路径（绝对或相对当前目录均可），写出时换算为相对目标目录
+func (w *Writer) Add(r Record) error {
+	r.Path = Rel(w.root, r.Path)
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	if _, err := w.w.Write(b); err != nil {
+		return err
+	}
+	return w.w.Flush() // 逐条刷新，运行中途中断时已写出的记录仍然有效
+}
+
+// Close 关闭清单文件
+func (w *Writer) Close() error {
+	err := w.w.Flush()
+	if cerr := w.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Lookup 在 dstRoot 的来源清单中查找 path（绝对路径、相对当前目录或相对目标目录均可）的所有记录，按写入顺序返回
+func Lookup(dstRoot, path string) ([]Record, error) {
+	f, err := os.Open(filepath.Join(dstRoot, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	want := map[string]bool{filepath.ToSlash(path): true, Rel(dstRoot, path): true}
+	if absRoot, err := filepath.Abs(dstRoot); err == nil {
+		if abs, err := filepath.Abs(path); err == nil {
+			want[Rel(absRoot, abs)] = true
+		}
+	}
+	var out []Record
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64<<10), 1<<20)
+	for sc.Scan() {
+		var r Record
+		if json.Unmarshal(sc.Bytes(), &r) != nil {
+			continue // 跳过中断时写了一半的行
+		}
+		if want[r.Path] {
+			out = append(out, r)
+		}
+	}
+	return out, sc.Err()
+}
+
+// FindRoot 从 path 所在目录向上查找包含来源清单的目录
+func FindRoot(path string) (string, bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	for dir := filepath.Dir(abs); ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, ManifestFile)); err == nil {
+			return dir, true
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return "", false
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// file: internal/provenance/provenance_test.go
+// package: provenance
+//
+// 测试来源清单的追加与查询（多次运行累积、按各种路径写法查询），以及相对路径的换算。
+package provenance
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManifestAppendAndLookup(t *testing.T) {
+	dst := t.TempDir()
+	for _, run := range []string{"run1", "run2"} {
+		w, err := Open(dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		root, rel := Source("/music/src", "/music/src/Artist/a.flac")
+		if err := w.Add(Record{Path: filepath.Join(dst, "a.flac"), SourceRoot: root, SourcePath: rel, RunID: run}); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Add(Record{Path: filepath.Join(dst, "b.flac"), SourceRoot: "http://nas/music", SourcePath: Rel("http://nas/music", "http://nas/music/b.flac"), RunID: run}); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	recs, err := Lookup(dst, filepath.Join(dst, "a.flac"))
+	if err != nil || len(recs) != 2 || recs[0].RunID != "run1" || recs[1].RunID != "run2" {
+		t.Fatalf("Lookup = %+v, %v", recs, err)
+	}
+	if r := recs[0]; r.Path != "a.flac" || r.SourcePath != "Artist/a.flac" || r.Time.IsZero() {
+		t.Fatalf("记录不正确: %+v", r)
+	}
+	if recs, _ := Lookup(dst, "b.flac"); len(recs) != 2 || recs[0].SourcePath != "b.flac" {
+		t.Fatalf("按相对目标目录的路径查询失败: %+v", recs)
+	}
+	sub := filepath.Join(dst, "x", "y")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if root, ok := FindRoot(filepath.Join(sub, "c.flac")); !ok || root != dst {
+		t.Fatalf("FindRoot = %q, %v", root, ok)
+	}
+}
+
+func TestRel(t *testing.T) {
+	for _, c := range [][3]string{
+		{"/a", "/a/b/c.mp3", "b/c.mp3"},
+		{"/a", "/other/c.mp3", "/other/c.mp3"},
+		{"http://h/m/", "http://h/m/x.mp3", "x.mp3"},
+	} {
+		if got := Rel(c[0], filepath.FromSlash(c[1])); got != filepath.FromSlash(c[2]) && got != c[2] {
+			t.Errorf("Rel(%q, %q) = %q，期望 %q", c[0], c[1], got, c[2])
+		}
+	}
+}
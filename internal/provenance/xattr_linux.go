@@ -0,0 +1,21 @@
+// file: internal/provenance/xattr_linux.go
+// package: provenance
+//
+// Linux：来源写入 user.audio_dedup.* 扩展属性（需要文件系统支持 user xattr，如 ext4、xfs、btrfs）。
+package provenance
+
+import "syscall"
+
+// SetXattr 把来源写入 path 的扩展属性
+func SetXattr(path string, r Record) error {
+	for name, value := range map[string]string{
+		"user.audio_dedup.source_root": r.SourceRoot,
+		"user.audio_dedup.source_path": r.SourcePath,
+		"user.audio_dedup.run_id":      r.RunID,
+	} {
+		if err := syscall.Setxattr(path, name, []byte(value), 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
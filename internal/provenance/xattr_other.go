@@ -0,0 +1,10 @@
+//go:build !linux
+
+// file: internal/provenance/xattr_other.go
+// package: provenance
+//
+// 其它系统暂不支持写入扩展属性（标准库没有对应的系统调用），来源只记录在清单中。
+package provenance
+
+// SetXattr 在当前系统上不受支持
+func SetXattr(path string, r Record) error { return ErrXattrUnsupported }
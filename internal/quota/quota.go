@@ -0,0 +1,108 @@
+// file: internal/quota/quota.go
+// package: quota
+//
+// 进程级资源配额：同时运行的 ffmpeg / ffprobe 子进程数（-max-ffmpeg）与本进程自身读取文件的速率
+// （-io-rate：复制、计算哈希、进程内解码）。批量模式下每个任务是一个独立进程，配额即按任务生效，
+// 一个大任务不会占满 CPU 与磁盘而拖住同时运行的其它任务。ffmpeg 自行读取的数据不计入读取速率，
+// 由子进程数间接限制。未设置时不做任何限制。
+package quota
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+var (
+	ffmpegSlots chan struct{} // nil 表示不限制
+	ioLimiter   *Limiter
+)
+
+// SetMaxFFmpeg 设置同时运行的 ffmpeg / ffprobe 子进程上限；n <= 0 表示不限制。应在启动任何解码前调用。
+func SetMaxFFmpeg(n int) {
+	ffmpegSlots = nil
+	if n > 0 {
+		ffmpegSlots = make(chan struct{}, n)
+	}
+}
+
+// AcquireFFmpeg 阻塞直到可以启动一个 ffmpeg 子进程，返回的函数在子进程结束后调用
+func AcquireFFmpeg() (release func()) {
+	slots := ffmpegSlots
+	if slots == nil {
+		return func() {}
+	}
+	slots <- struct{}{}
+	return func() { <-slots }
+}
+
+// SetIORate 设置本进程读取文件的速率上限（字节/秒）；bytesPerSec <= 0 表示不限制
+func SetIORate(bytesPerSec int64) {
+	ioLimiter = nil
+	if bytesPerSec > 0 {
+		ioLimiter = NewLimiter(bytesPerSec)
+	}
+}
+
+// Reader 返回按 -io-rate 限速读取 r 的 Reader；未设置速率时原样返回 r
+func Reader(r io.Reader) io.Reader {
+	if l := ioLimiter; l != nil {
+		return &limitedReader{r: r, l: l}
+	}
+	return r
+}
+
+// Limiter 为字节速率的令牌桶：最多积攒 1 秒的额度，超出额度的读取先记账，由调用方睡眠补齐
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64 // 字节/秒
+	tokens float64 // 可为负（欠账）
+	last   time.Time
+	now    func() time.Time
+	sleep  func(time.Duration)
+}
+
+// NewLimiter 创建速率为 bytesPerSec 的令牌桶
+func NewLimiter(bytesPerSec int64) *Limiter {
+	return &Limiter{rate: float64(bytesPerSec), now: time.Now, sleep: time.Sleep}
+}
+
+// Wait 记入 n 字节，必要时阻塞到速率允许为止
+func (l *Limiter) Wait(n int) {
+	if l == nil || n <= 0 {
+		return
+	}
+	l.mu.Lock()
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+	}
+	if l.tokens > l.rate {
+		l.tokens = l.rate
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	var d time.Duration
+	if l.tokens < 0 {
+		d = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+	if d > 0 {
+		l.sleep(d)
+	}
+}
+
+type limitedReader struct {
+	r io.Reader
+	l *Limiter
+}
+
+func (lr *limitedReader) Read(p []byte) (int, error) {
+	// 单次读取不超过 1 秒的额度，避免一次大块读取造成长时间停顿
+	if max := int(lr.l.rate); max > 0 && len(p) > max {
+		p = p[:max]
+	}
+	n, err := lr.r.Read(p)
+	lr.l.Wait(n)
+	return n, err
+}
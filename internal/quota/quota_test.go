@@ -0,0 +1,64 @@
+// file: internal/quota/quota_test.go
+// package: quota
+//
+// 使用假时钟测试令牌桶的限速，以及 ffmpeg 子进程上限。
+package quota
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	var slept time.Duration
+	l := NewLimiter(1000)
+	l.now = func() time.Time { return now }
+	l.sleep = func(d time.Duration) { slept += d; now = now.Add(d) }
+
+	l.Wait(500) // 首次读取从零额度开始：欠 500 字节，等 0.5 秒
+	if slept != 500*time.Millisecond {
+		t.Fatalf("slept = %v", slept)
+	}
+	now = now.Add(10 * time.Second) // 空闲期最多积攒 1 秒的额度
+	slept = 0
+	l.Wait(1500)
+	if slept != 500*time.Millisecond {
+		t.Fatalf("积攒额度后 slept = %v", slept)
+	}
+
+	SetIORate(1000)
+	defer SetIORate(0)
+	ioLimiter.now, ioLimiter.sleep = l.now, l.sleep
+	slept = 0
+	data, err := io.ReadAll(Reader(bytes.NewReader(make([]byte, 3000))))
+	if err != nil || len(data) != 3000 || slept != 3*time.Second {
+		t.Fatalf("读取 %d 字节，slept = %v, err = %v", len(data), slept, err)
+	}
+}
+
+func TestMaxFFmpeg(t *testing.T) {
+	SetMaxFFmpeg(2)
+	defer SetMaxFFmpeg(0)
+	r1, r2 := AcquireFFmpeg(), AcquireFFmpeg()
+	acquired := make(chan struct{})
+	go func() {
+		AcquireFFmpeg()()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatalf("超过上限时应阻塞")
+	case <-time.After(20 * time.Millisecond):
+	}
+	r1()
+	<-acquired
+	r2()
+
+	SetMaxFFmpeg(0)
+	for i := 0; i < 10; i++ {
+		AcquireFFmpeg() // 不限制时从不阻塞
+	}
+}
@@ -0,0 +1,165 @@
+// file: internal/removal/removal.go
+// package: removal
+//
+// 原地去重时删除重复文件的三种方式：直接删除、移入系统回收站，或移入隔离目录。
+// 隔离目录下每次运行一个批次子目录，按源目录内的相对路径存放被移走的文件，
+// 并逐行记录清单（manifest.jsonl），可用 Undo 把整批文件放回原处。
+package removal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"deduplicateMusic/internal/copyutil"
+)
+
+// Method 为删除方式
+type Method string
+
+const (
+	MethodDelete     Method = "delete"     // 直接删除，无法撤销
+	MethodTrash      Method = "trash"      // 移入系统回收站，可在文件管理器中还原
+	MethodQuarantine Method = "quarantine" // 移入隔离目录并记录清单，可用 Undo 还原
+)
+
+// ManifestFile 为隔离批次目录中的清单文件名
+const ManifestFile = "manifest.jsonl"
+
+// ErrTrashUnsupported 表示当前系统不支持移入回收站
+var ErrTrashUnsupported = errors.New("当前系统不支持移入回收站，请改用 -quarantine")
+
+// Entry 为隔离清单中的一行
+type Entry struct {
+	Original string    `json:"original"`       // 原路径
+	Stored   string    `json:"stored"`         // 隔离目录中的路径
+	Size     int64     `json:"size,omitempty"` // 文件大小
+	Kept     string    `json:"kept,omitempty"` // 该文件所重复的保留文件
+	Time     time.Time `json:"time"`
+}
+
+// Remover 按选定的方式删除文件；并发安全
+type Remover struct {
+	method   Method
+	root     string // 源目录，隔离时据此计算相对路径
+	batch    string // 隔离批次目录
+	ro       copyutil.ReadOnly
+	mu       sync.Mutex
+	manifest *os.File
+}
+
+// New 创建 Remover。quarantine 方式下 dir 为隔离目录，本次运行的文件放在其下的 batch 子目录中；
+// ro 内的文件不会被移除。
+func New(method Method, root, dir, batch string, ro copyutil.ReadOnly) (*Remover, error) {
+	r := &Remover{method: method, root: root, ro: ro}
+	switch method {
+	case MethodDelete, MethodTrash:
+		return r, nil
+	case MethodQuarantine:
+	default:
+		return nil, fmt.Errorf("未知的删除方式 %q", method)
+	}
+	r.batch = filepath.Join(dir, batch)
+	if err := os.MkdirAll(r.batch, 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(r.batch, ManifestFile), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	r.manifest = f
+	return r, nil
+}
+
+// Method 返回删除方式
+func (r *Remover) Method() Method { return r.method }
+
+// Batch 返回隔离批次目录（其它方式为空）
+func (r *Remover) Batch() string { return r.batch }
+
+// Remove 删除 path，返回文件被移到的位置（直接删除时为空）。kept 与 size 只用于记录清单。
+func (r *Remover) Remove(path, kept string, size int64) (string, error) {
+	switch r.method {
+	case MethodDelete:
+		if err := r.ro.CheckWritable(path); err != nil {
+			return "", err
+		}
+		return "", os.Remove(path)
+	case MethodTrash:
+		if err := r.ro.CheckWritable(path); err != nil {
+			return "", err
+		}
+		return moveToTrash(path, r.ro)
+	}
+	stored, err := copyutil.Backup(r.root, path, r.batch, r.ro)
+	if err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(Entry{Original: path, Stored: stored, Size: size, Kept: kept, Time: time.Now()})
+	if err != nil {
+		return stored, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// 每行立即写出：运行中途中断时已移走的文件也能还原
+	if _, err := r.manifest.Write(append(b, '\n')); err != nil {
+		return stored, fmt.Errorf("写隔离清单失败（文件已移到 %s）: %w", stored, err)
+	}
+	return stored, nil
+}
+
+// Close 关闭隔离清单
+func (r *Remover) Close() error {
+	if r.manifest == nil {
+		return nil
+	}
+	return r.manifest.Close()
+}
+
+// UndoResult 为 Undo 的结果
+type UndoResult struct {
+	Restored int
+	Skipped  []string // 无法还原的文件及原因
+}
+
+// Undo 按隔离清单把文件放回原处。path 可以是批次目录或其中的清单文件；
+// 原位置已有文件、或隔离目录中的文件已不存在时跳过。
+func Undo(path string) (UndoResult, error) {
+	var res UndoResult
+	if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+		path = filepath.Join(path, ManifestFile)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return res, err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64<<10), 1<<20)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal([]byte(text), &e); err != nil {
+			return res, fmt.Errorf("%s 第 %d 行: %w", path, line, err)
+		}
+		if _, err := os.Lstat(e.Original); err == nil {
+			res.Skipped = append(res.Skipped, fmt.Sprintf("%s: 原位置已有文件", e.Original))
+			continue
+		}
+		if err := copyutil.MoveFile(e.Stored, e.Original, nil); err != nil {
+			res.Skipped = append(res.Skipped, fmt.Sprintf("%s: %v", e.Original, err))
+			continue
+		}
+		res.Restored++
+	}
+	return res, sc.Err()
+}
@@ -0,0 +1,104 @@
+// file: internal/removal/removal_test.go
+// package: removal
+//
+// 测试隔离目录（含清单与撤销）、直接删除，以及 Linux 回收站的 .trashinfo。
+package removal
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, p, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQuarantineAndUndo(t *testing.T) {
+	src, q := t.TempDir(), t.TempDir()
+	a, b := filepath.Join(src, "A", "x.mp3"), filepath.Join(src, "B", "x.mp3")
+	writeFile(t, a, "a")
+	writeFile(t, b, "b")
+
+	r, err := New(MethodQuarantine, src, q, "run1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{a, b} {
+		stored, err := r.Remove(p, "keep.flac", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(stored, r.Batch()) {
+			t.Fatalf("隔离位置 %s 不在批次目录内", stored)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(a); !os.IsNotExist(err) {
+		t.Fatal("原文件应已移走")
+	}
+
+	writeFile(t, b, "new") // 原位置已有新文件时不覆盖
+	res, err := Undo(r.Batch())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Restored != 1 || len(res.Skipped) != 1 {
+		t.Fatalf("Undo 结果不正确: %+v", res)
+	}
+	if got, _ := os.ReadFile(a); string(got) != "a" {
+		t.Fatalf("还原内容 = %q", got)
+	}
+	if got, _ := os.ReadFile(b); string(got) != "new" {
+		t.Fatalf("已有文件被覆盖: %q", got)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "x.mp3")
+	writeFile(t, p, "x")
+	r, err := New(MethodDelete, "", "", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if where, err := r.Remove(p, "", 0); err != nil || where != "" {
+		t.Fatalf("Remove = %q, %v", where, err)
+	}
+	if _, err := os.Stat(p); !os.IsNotExist(err) {
+		t.Fatal("文件应已删除")
+	}
+}
+
+func TestTrashLinux(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("仅 Linux")
+	}
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	dir := t.TempDir()
+	r, _ := New(MethodTrash, "", "", "", nil)
+	for _, sub := range []string{"a", "b"} {
+		p := filepath.Join(dir, sub, "song 1.flac")
+		writeFile(t, p, sub)
+		where, err := r.Remove(p, "", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.ReadFile(filepath.Join(filepath.Dir(filepath.Dir(where)), "info", filepath.Base(where)+".trashinfo"))
+		if err != nil || !strings.Contains(string(info), "Path="+filepath.ToSlash(filepath.Dir(p))+"/song%201.flac") {
+			t.Fatalf("trashinfo 不正确: %q, %v", info, err)
+		}
+		if sub == "b" && filepath.Base(where) != "song 1.2.flac" {
+			t.Fatalf("重名文件应改名，实际 %s", where)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+// file: internal/removal/trash_darwin.go
+// package: removal
+//
+// macOS 回收站：移入 ~/.Trash（不记录"放回原处"信息，原路径见报告）。
+package removal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"deduplicateMusic/internal/copyutil"
+)
+
+func moveToTrash(path string, ro copyutil.ReadOnly) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(home, ".Trash")
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	for i := 1; ; i++ {
+		target := filepath.Join(dir, base)
+		if i > 1 {
+			target = filepath.Join(dir, fmt.Sprintf("%s %d%s", stem, i, ext))
+		}
+		if _, err := os.Lstat(target); err == nil {
+			continue
+		}
+		if err := copyutil.MoveFile(path, target, ro); err != nil {
+			return "", err
+		}
+		return target, nil
+	}
+}
@@ -0,0 +1,79 @@
+// file: internal/removal/trash_linux.go
+// package: removal
+//
+// Linux 回收站：按 freedesktop.org Trash 规范移入 $XDG_DATA_HOME/Trash（默认 ~/.local/share/Trash），
+// 同时写 .trashinfo，文件管理器可以还原到原位置。
+package removal
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"deduplicateMusic/internal/copyutil"
+)
+
+func trashDir() (string, error) {
+	if d := os.Getenv("XDG_DATA_HOME"); d != "" {
+		return filepath.Join(d, "Trash"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "Trash"), nil
+}
+
+func moveToTrash(path string, ro copyutil.ReadOnly) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	dir, err := trashDir()
+	if err != nil {
+		return "", err
+	}
+	files, info := filepath.Join(dir, "files"), filepath.Join(dir, "info")
+	if err := os.MkdirAll(files, 0o700); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(info, 0o700); err != nil {
+		return "", err
+	}
+	// 先以独占方式创建 .trashinfo 占住名字，再移动文件
+	base := filepath.Base(abs)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	u := url.URL{Path: abs}
+	content := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n", u.EscapedPath(), time.Now().Format("2006-01-02T15:04:05"))
+	for i := 1; ; i++ {
+		name := base
+		if i > 1 {
+			name = fmt.Sprintf("%s.%d%s", stem, i, ext)
+		}
+		infoPath := filepath.Join(info, name+".trashinfo")
+		f, err := os.OpenFile(infoPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		_, err = f.WriteString(content)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		target := filepath.Join(files, name)
+		if err == nil {
+			err = copyutil.MoveFile(abs, target, ro)
+		}
+		if err != nil {
+			_ = os.Remove(infoPath)
+			return "", err
+		}
+		return target, nil
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+
+// file: internal/removal/trash_other.go
+// package: removal
+//
+// 其它系统（含 Windows 回收站）暂不支持，调用方应改用隔离目录。
+package removal
+
+import "deduplicateMusic/internal/copyutil"
+
+func moveToTrash(path string, ro copyutil.ReadOnly) (string, error) { return "", ErrTrashUnsupported }
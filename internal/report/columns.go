@@ -0,0 +1,148 @@
+// file: internal/report/columns.go
+// package: report
+//
+// CSV 报告的列：可用 -report-columns 选择并排序输出列，用 -report-lang 选择表头语言，
+// 方便把报告导入不同的下游表格。列名（英文表头）在各语言下都可用于选择。
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Column 为报告中的一列
+type Column struct {
+	Name    string            // 列名，即英文表头
+	Headers map[string]string // 其它语言的表头（语言代码 -> 表头）
+	Value   func(ReportItem) string
+}
+
+// DefaultColumns 为未指定 -report-columns 时的输出列
+var DefaultColumns = []string{"FilePath", "Kept", "Size", "NewPath", "FLACVerify", "Action", "Sidecars", "GroupID", "KeptPath", "Distance", "Error"}
+
+// Languages 为支持的表头语言
+var Languages = []string{"en", "zh"}
+
+var columns = map[string]Column{}
+
+func init() {
+	for _, c := range []Column{
+		{"FilePath", map[string]string{"zh": "文件路径"}, func(it ReportItem) string { return it.FilePath }},
+		{"Kept", map[string]string{"zh": "保留"}, func(it ReportItem) string {
+			if it.Kept {
+				return "Yes"
+			}
+			return "No"
+		}},
+		{"Size", map[string]string{"zh": "大小"}, func(it ReportItem) string { return strconv.FormatInt(it.Size, 10) }},
+		{"NewPath", map[string]string{"zh": "新路径"}, func(it ReportItem) string { return it.NewPath }},
+		{"FLACVerify", map[string]string{"zh": "FLAC校验"}, func(it ReportItem) string { return it.Verify }},
+		{"Action", map[string]string{"zh": "处理"}, func(it ReportItem) string { return it.Action }},
+		{"Sidecars", map[string]string{"zh": "伴随文件"}, func(it ReportItem) string { return strings.Join(it.Sidecars, ";") }},
+		{"GroupID", map[string]string{"zh": "组号"}, func(it ReportItem) string {
+			if it.GroupID == 0 {
+				return ""
+			}
+			return strconv.Itoa(it.GroupID)
+		}},
+		{"KeptPath", map[string]string{"zh": "保留文件"}, func(it ReportItem) string { return it.KeptPath }},
+		{"Distance", map[string]string{"zh": "距离"}, func(it ReportItem) string {
+			if it.Kept || it.Action == ActionFailed {
+				return "" // 只对重复文件有意义
+			}
+			return strconv.Itoa(it.Distance)
+		}},
+		{"Error", map[string]string{"zh": "错误"}, func(it ReportItem) string { return it.Error }},
+		{"SourceRoot", map[string]string{"zh": "源目录"}, func(it ReportItem) string { return it.SourceRoot }},
+		{"RunID", map[string]string{"zh": "运行编号"}, func(it ReportItem) string { return it.RunID }},
+		{"SHA256", nil, func(it ReportItem) string { return it.SHA256 }},
+		{"Bitrate", map[string]string{"zh": "码率(kbps)"}, func(it ReportItem) string {
+			if it.Bitrate == 0 {
+				return ""
+			}
+			return strconv.Itoa(it.Bitrate)
+		}},
+		{"Artist", map[string]string{"zh": "艺术家"}, func(it ReportItem) string { return it.Tags.Artist }},
+		{"Title", map[string]string{"zh": "标题"}, func(it ReportItem) string { return it.Tags.Title }},
+		{"Album", map[string]string{"zh": "专辑"}, func(it ReportItem) string { return it.Tags.Album }},
+		{"Track", map[string]string{"zh": "曲号"}, func(it ReportItem) string { return it.Tags.Track }},
+		{"Duration", map[string]string{"zh": "时长(秒)"}, func(it ReportItem) string {
+			if it.Duration == 0 {
+				return ""
+			}
+			return strconv.FormatFloat(it.Duration, 'f', 1, 64)
+		}},
+	} {
+		columns[strings.ToLower(c.Name)] = c
+	}
+}
+
+// ColumnNames 返回所有可选的列名（排序）
+func ColumnNames() []string {
+	names := make([]string, 0, len(columns))
+	for _, c := range columns {
+		names = append(names, c.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseColumns 解析逗号分隔的列名（不区分大小写），空串返回 DefaultColumns
+func ParseColumns(spec string) ([]string, error) {
+	if strings.TrimSpace(spec) == "" {
+		return DefaultColumns, nil
+	}
+	var out []string
+	for _, f := range strings.Split(spec, ",") {
+		c, ok := columns[strings.ToLower(strings.TrimSpace(f))]
+		if !ok {
+			return nil, fmt.Errorf("未知的报告列 %q（可选: %s）", strings.TrimSpace(f), strings.Join(ColumnNames(), ", "))
+		}
+		out = append(out, c.Name)
+	}
+	return out, nil
+}
+
+// NeedsMedia 返回 cols 是否包含需要读取音频时长的列（Bitrate / Duration）
+func NeedsMedia(cols []string) bool {
+	for _, c := range cols {
+		if c == "Bitrate" || c == "Duration" {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveColumns 按名称取列并生成 lang 语言的表头
+func resolveColumns(names []string, lang string) ([]Column, []string, error) {
+	if lang == "" {
+		lang = "en"
+	}
+	known := false
+	for _, l := range Languages {
+		known = known || l == lang
+	}
+	if !known {
+		return nil, nil, fmt.Errorf("不支持的报告语言 %q（可选: %s）", lang, strings.Join(Languages, ", "))
+	}
+	if len(names) == 0 {
+		names = DefaultColumns
+	}
+	cols := make([]Column, 0, len(names))
+	header := make([]string, 0, len(names))
+	for _, n := range names {
+		c, ok := columns[strings.ToLower(n)]
+		if !ok {
+			return nil, nil, fmt.Errorf("未知的报告列 %q", n)
+		}
+		h := c.Name
+		if l, ok := c.Headers[lang]; ok {
+			h = l
+		}
+		cols = append(cols, c)
+		header = append(header, h)
+	}
+	return cols, header, nil
+}
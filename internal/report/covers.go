@@ -0,0 +1,69 @@
+// file: internal/report/covers.go
+// package: report
+//
+// 疑似翻唱/同曲不同录音的参考报告。与重复文件报告分开，仅供人工浏览，程序不据此做任何处理。
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+
+	"deduplicateMusic/internal/gzfile"
+)
+
+// CoverPair 为一对疑似同一作品的不同录音
+type CoverPair struct {
+	A, B      string
+	Score     float64 // 色度相似度（0..1）
+	Transpose int     // B 相对 A 的移调半音数
+}
+
+// WriteCoverText 把疑似翻唱列表写为易读文本（最多 topN 条，topN <= 0 表示全部）
+func WriteCoverText(w io.Writer, pairs []CoverPair, topN int) {
+	fmt.Fprintf(w, "疑似翻唱/同曲不同录音（仅供参考，不是重复文件，不会被处理）：%d 对\n", len(pairs))
+	for i, p := range pairs {
+		if topN > 0 && i >= topN {
+			fmt.Fprintf(w, "  …… 其余 %d 对见报告文件\n", len(pairs)-topN)
+			break
+		}
+		fmt.Fprintf(w, "  %.2f  %s  <->  %s", p.Score, filepath.Base(p.A), filepath.Base(p.B))
+		if p.Transpose != 0 {
+			fmt.Fprintf(w, "（移调 %+d 半音）", signedSemitones(p.Transpose))
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// signedSemitones 把 0..11 的移调量换算到 -5..+6
+func signedSemitones(t int) int {
+	if t > 6 {
+		return t - 12
+	}
+	return t
+}
+
+// WriteCoverReport 把疑似翻唱写入 audio_dedup_covers_<时间戳>.csv，返回文件名
+func WriteCoverReport(pairs []CoverPair) (string, error) {
+	filename := reportName("covers", "csv")
+	f, err := gzfile.Create(filename)
+	if err != nil {
+		return "", fmt.Errorf("create cover report error: %w", err)
+	}
+	w := csv.NewWriter(f)
+	w.Write([]string{"FileA", "FileB", "Score", "TransposeSemitones"})
+	for _, p := range pairs {
+		w.Write([]string{p.A, p.B, strconv.FormatFloat(p.Score, 'f', 3, 64), strconv.Itoa(signedSemitones(p.Transpose))})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return "", fmt.Errorf("write cover report error: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("write cover report error: %w", err)
+	}
+	return filename, nil
+}
@@ -0,0 +1,24 @@
+// file: internal/report/covers_test.go
+// package: report
+//
+// 测试疑似翻唱文本输出中的移调换算与条数截断。
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteCoverText(t *testing.T) {
+	pairs := []CoverPair{
+		{A: "/m/a.flac", B: "/m/live/a.mp3", Score: 0.93, Transpose: 10},
+		{A: "/m/b.flac", B: "/m/c.flac", Score: 0.88},
+	}
+	var buf bytes.Buffer
+	WriteCoverText(&buf, pairs, 1)
+	out := buf.String()
+	if !strings.Contains(out, "0.93  a.flac  <->  a.mp3（移调 -2 半音）") || !strings.Contains(out, "其余 1 对") {
+		t.Fatalf("输出不正确:\n%s", out)
+	}
+}
@@ -0,0 +1,138 @@
+// file: internal/report/diskusage.go
+// package: report
+//
+// 重复空间的磁盘占用视图：把每个重复文件的字节数归属到其所在目录（并累加到各级父目录），
+// 输出 du 风格文本或 ncdu 导出 JSON，便于用现有工具（ncdu -f、各类 treemap）可视化冗余分布。
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"deduplicateMusic/internal/gzfile"
+)
+
+// 磁盘占用输出格式
+const (
+	DUFormatDu   = "du"
+	DUFormatNcdu = "ncdu"
+)
+
+// duDir 为目录树节点
+type duDir struct {
+	name  string
+	bytes int64
+	files map[string]int64
+	dirs  map[string]*duDir
+}
+
+func newDuDir(name string) *duDir {
+	return &duDir{name: name, files: map[string]int64{}, dirs: map[string]*duDir{}}
+}
+
+// buildDuTree 以 root 为根构建只包含重复文件的目录树
+func buildDuTree(root string, dups []Reclaimable) *duDir {
+	top := newDuDir(root)
+	for _, d := range dups {
+		rel, err := filepath.Rel(root, d.Path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			rel = d.Path // 不在 root 下时按原路径挂在根上
+		}
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		node := top
+		node.bytes += d.Size
+		for _, p := range parts[:len(parts)-1] {
+			if p == "" {
+				continue
+			}
+			child, ok := node.dirs[p]
+			if !ok {
+				child = newDuDir(p)
+				node.dirs[p] = child
+			}
+			child.bytes += d.Size
+			node = child
+		}
+		node.files[parts[len(parts)-1]] += d.Size
+	}
+	return top
+}
+
+// WriteDiskUsage 把重复文件按目录汇总写入 w。format 为 du 或 ncdu。
+func WriteDiskUsage(w io.Writer, root string, dups []Reclaimable, format string) error {
+	tree := buildDuTree(root, dups)
+	switch format {
+	case DUFormatDu:
+		return writeDu(w, tree, root)
+	case DUFormatNcdu:
+		doc := []any{1, 0, map[string]any{
+			"progname":  "audio-dedup",
+			"progver":   "1",
+			"timestamp": time.Now().Unix(),
+		}, ncduDir(tree)}
+		enc := json.NewEncoder(w)
+		return enc.Encode(doc)
+	}
+	return fmt.Errorf("未知的磁盘占用格式: %s（可选 du / ncdu）", format)
+}
+
+// writeDu 按 du 的习惯先输出子目录再输出父目录，每行 "字节数<TAB>目录"
+func writeDu(w io.Writer, d *duDir, path string) error {
+	for _, name := range sortedKeys(d.dirs) {
+		if err := writeDu(w, d.dirs[name], filepath.Join(path, name)); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d\t%s\n", d.bytes, path)
+	return err
+}
+
+// ncduDir 把目录节点转为 ncdu 导出格式：[{目录信息}, 文件对象..., 子目录数组...]
+func ncduDir(d *duDir) []any {
+	out := []any{map[string]any{"name": d.name}}
+	fileNames := make([]string, 0, len(d.files))
+	for n := range d.files {
+		fileNames = append(fileNames, n)
+	}
+	sort.Strings(fileNames)
+	for _, n := range fileNames {
+		out = append(out, map[string]any{"name": n, "asize": d.files[n], "dsize": d.files[n]})
+	}
+	for _, name := range sortedKeys(d.dirs) {
+		out = append(out, ncduDir(d.dirs[name]))
+	}
+	return out
+}
+
+func sortedKeys(m map[string]*duDir) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WriteDiskUsageFile 写到 path；format 为空时按扩展名推断（.json 为 ncdu，否则 du），以 .gz 结尾时压缩
+func WriteDiskUsageFile(path, root string, dups []Reclaimable, format string) error {
+	if format == "" {
+		format = DUFormatDu
+		if strings.EqualFold(filepath.Ext(strings.TrimSuffix(path, gzfile.Ext)), ".json") {
+			format = DUFormatNcdu
+		}
+	}
+	f, err := gzfile.Create(path)
+	if err != nil {
+		return err
+	}
+	werr := WriteDiskUsage(f, root, dups, format)
+	if cerr := f.Close(); werr == nil {
+		werr = cerr
+	}
+	return werr
+}
@@ -0,0 +1,45 @@
+// file: internal/report/diskusage_test.go
+// package: report
+//
+// 测试重复空间按目录归属及 du / ncdu 输出。
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteDiskUsage(t *testing.T) {
+	root := filepath.FromSlash("/music")
+	dups := []Reclaimable{
+		{Path: filepath.FromSlash("/music/A/x.mp3"), Size: 100},
+		{Path: filepath.FromSlash("/music/A/B/y.mp3"), Size: 50},
+		{Path: filepath.FromSlash("/music/C/z.mp3"), Size: 7},
+	}
+	var buf bytes.Buffer
+	if err := WriteDiskUsage(&buf, root, dups, DUFormatDu); err != nil {
+		t.Fatalf("du 输出错误: %v", err)
+	}
+	want := "50\t" + filepath.FromSlash("/music/A/B") + "\n" +
+		"150\t" + filepath.FromSlash("/music/A") + "\n" +
+		"7\t" + filepath.FromSlash("/music/C") + "\n" +
+		"157\t" + root + "\n"
+	if buf.String() != want {
+		t.Fatalf("du 输出不正确:\n%s\n期望:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := WriteDiskUsage(&buf, root, dups, DUFormatNcdu); err != nil {
+		t.Fatalf("ncdu 输出错误: %v", err)
+	}
+	var doc []any
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil || len(doc) != 4 {
+		t.Fatalf("ncdu 输出不是合法的导出格式: %v", err)
+	}
+	top := doc[3].([]any)
+	if len(top) != 3 { // 根信息 + 目录 A + 目录 C
+		t.Fatalf("ncdu 根目录结构不正确: %#v", top)
+	}
+}
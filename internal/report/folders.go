@@ -0,0 +1,152 @@
+// file: internal/report/folders.go
+// package: report
+//
+// 目录级重复：找出所有曲目都在其它目录中有重复的目录（例如同一专辑抓了两遍），
+// 按目录汇报，更符合用户"删掉旧的那份专辑"的习惯。互为镜像的目录只建议删除其中较小的一个。
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"deduplicateMusic/internal/dedup"
+	"deduplicateMusic/internal/gzfile"
+	"deduplicateMusic/internal/source"
+)
+
+// DupFolder 为一个所有曲目都在其它目录中有重复的目录
+type DupFolder struct {
+	Dir         string
+	Files       int
+	Bytes       int64
+	DuplicateOf string // 共有曲目最多的其它目录
+	Shared      int    // 与 DuplicateOf 共有的曲目数
+	Redundant   bool   // 建议整目录删除；互为镜像的目录中体积较大者为 false
+}
+
+// FolderBuilder 逐组累积目录间的重复关系
+type FolderBuilder struct {
+	files   map[string]int
+	bytes   map[string]int64
+	covered map[string]int            // 在其它目录中有重复的文件数
+	shared  map[string]map[string]int // shared[d][e]：d 中在 e 里有重复的文件数
+}
+
+// DirOf 返回文件所在目录（URL / adb 路径按 "/" 处理）
+func DirOf(p string) string {
+	if source.IsLocalPath(p) {
+		return filepath.Dir(p)
+	}
+	return path.Dir(p)
+}
+
+// Add 累积一个分组（包括没有重复的单文件分组，以便统计目录中的文件总数）
+func (b *FolderBuilder) Add(g dedup.Group) {
+	if b.files == nil {
+		b.files = map[string]int{}
+		b.bytes = map[string]int64{}
+		b.covered = map[string]int{}
+		b.shared = map[string]map[string]int{}
+	}
+	members := append([]dedup.FileMeta{g.Keep}, g.Protected...)
+	for _, d := range g.Duplicates {
+		members = append(members, d.FileMeta)
+	}
+	perDir := map[string]int{}
+	for _, m := range members {
+		d := DirOf(m.Path)
+		perDir[d]++
+		b.files[d]++
+		b.bytes[d] += m.Size
+	}
+	for d, n := range perDir {
+		for e := range perDir {
+			if e == d {
+				continue
+			}
+			if b.shared[d] == nil {
+				b.shared[d] = map[string]int{}
+			}
+			b.shared[d][e] += n
+		}
+		if len(perDir) > 1 {
+			b.covered[d] += n
+		}
+	}
+}
+
+// Folders 返回所有曲目（至少 2 首）都有重复的目录，按体积降序
+func (b *FolderBuilder) Folders() []DupFolder {
+	full := func(d string) bool { return b.files[d] >= 2 && b.covered[d] == b.files[d] }
+	var out []DupFolder
+	for d := range b.files {
+		if !full(d) {
+			continue
+		}
+		f := DupFolder{Dir: d, Files: b.files[d], Bytes: b.bytes[d], Redundant: true}
+		for e, n := range b.shared[d] {
+			if n > f.Shared || n == f.Shared && e < f.DuplicateOf {
+				f.DuplicateOf, f.Shared = e, n
+			}
+		}
+		e := f.DuplicateOf
+		if full(e) && f.Shared == b.files[d] && b.shared[e][d] == b.files[e] {
+			// 互为镜像：保留体积较大的一份（相同时保留路径较小者）
+			if b.bytes[d] > b.bytes[e] || b.bytes[d] == b.bytes[e] && d < e {
+				f.Redundant = false
+			}
+		}
+		out = append(out, f)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Bytes != out[j].Bytes {
+			return out[i].Bytes > out[j].Bytes
+		}
+		return out[i].Dir < out[j].Dir
+	})
+	return out
+}
+
+// WriteFolderText 以文本形式输出重复目录（最多 topN 条，topN <= 0 表示全部）
+func WriteFolderText(w io.Writer, folders []DupFolder, topN int) {
+	fmt.Fprintf(w, "== 整目录重复（所有曲目在其它目录中都有重复）：%d 个 ==\n", len(folders))
+	for i, f := range folders {
+		if topN > 0 && i >= topN {
+			fmt.Fprintf(w, "  ……其余 %d 项省略\n", len(folders)-topN)
+			break
+		}
+		mark := "可整目录删除"
+		if !f.Redundant {
+			mark = "镜像，建议保留"
+		}
+		fmt.Fprintf(w, "  %s（%d 首，%s）→ %s 共有 %d 首 [%s]\n", f.Dir, f.Files, HumanBytes(f.Bytes), f.DuplicateOf, f.Shared, mark)
+	}
+}
+
+// WriteFolderReport 把重复目录写入 audio_dedup_folders_<时间戳>.csv，返回文件名
+func WriteFolderReport(folders []DupFolder) (string, error) {
+	filename := reportName("folders", "csv")
+	f, err := gzfile.Create(filename)
+	if err != nil {
+		return "", fmt.Errorf("create folder report error: %w", err)
+	}
+	w := csv.NewWriter(f)
+	w.Write([]string{"Folder", "Files", "Size", "DuplicateOf", "SharedFiles", "Redundant"})
+	for _, d := range folders {
+		w.Write([]string{d.Dir, strconv.Itoa(d.Files), strconv.FormatInt(d.Bytes, 10), d.DuplicateOf, strconv.Itoa(d.Shared), strconv.FormatBool(d.Redundant)})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return "", fmt.Errorf("write folder report error: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("write folder report error: %w", err)
+	}
+	return filename, nil
+}
@@ -0,0 +1,53 @@
+// file: internal/report/folders_test.go
+// package: report
+//
+// 测试目录级重复：镜像目录只建议删除较小的一份，子集目录整目录可删，部分重复的目录不报告。
+package report
+
+import (
+	"testing"
+
+	"deduplicateMusic/internal/dedup"
+)
+
+func group(size int64, paths ...string) dedup.Group {
+	g := dedup.Group{Keep: dedup.FileMeta{Path: paths[0], Size: size}}
+	for _, p := range paths[1:] {
+		g.Duplicates = append(g.Duplicates, dedup.Member{FileMeta: dedup.FileMeta{Path: p, Size: size - 1}})
+	}
+	return g
+}
+
+func TestFolders(t *testing.T) {
+	var b FolderBuilder
+	for _, g := range []dedup.Group{
+		// /flac 与 /mp3 互为镜像；/best 只有其中两首
+		group(100, "/flac/1.flac", "/mp3/1.mp3", "/best/1.mp3"),
+		group(100, "/flac/2.flac", "/mp3/2.mp3", "/best/2.mp3"),
+		group(100, "/flac/3.flac", "/mp3/3.mp3"),
+		// /misc 只有一首有重复
+		group(50, "/misc/a.mp3", "/other/a.mp3"),
+		group(50, "/misc/b.mp3"),
+	} {
+		b.Add(g)
+	}
+	got := map[string]DupFolder{}
+	for _, f := range b.Folders() {
+		got[f.Dir] = f
+	}
+	if len(got) != 3 {
+		t.Fatalf("期望 3 个重复目录，得到 %+v", got)
+	}
+	if f := got["/flac"]; f.Redundant || f.DuplicateOf != "/mp3" || f.Shared != 3 {
+		t.Errorf("/flac: %+v", f)
+	}
+	if f := got["/mp3"]; !f.Redundant || f.DuplicateOf != "/flac" {
+		t.Errorf("/mp3: %+v", f)
+	}
+	if f := got["/best"]; !f.Redundant || f.Files != 2 {
+		t.Errorf("/best: %+v", f)
+	}
+	if _, ok := got["/misc"]; ok {
+		t.Error("/misc 只有部分重复，不应报告")
+	}
+}
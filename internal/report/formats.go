@@ -0,0 +1,228 @@
+// file: internal/report/formats.go
+// package: report
+//
+// 报告格式：除逐行写出的 CSV 外，还可以输出按分组组织的 JSON（便于程序处理）与 HTML
+// （每组一张可点击表头排序的表格，便于在浏览器中审阅）。JSON / HTML 需要完整的分组结构，
+// 在内存中累积记录并于 Close 时一次性写出。
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"deduplicateMusic/internal/tags"
+)
+
+// Format 为报告格式
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+	FormatHTML Format = "html"
+)
+
+// Formats 为支持的报告格式
+var Formats = []Format{FormatCSV, FormatJSON, FormatHTML}
+
+// ParseFormats 解析逗号分隔的报告格式（去重，保持顺序），空串返回仅 CSV
+func ParseFormats(spec string) ([]Format, error) {
+	var out []Format
+	seen := map[Format]bool{}
+	for _, f := range strings.Split(spec, ",") {
+		name := Format(strings.ToLower(strings.TrimSpace(f)))
+		if name == "" || seen[name] {
+			continue
+		}
+		known := false
+		for _, k := range Formats {
+			known = known || k == name
+		}
+		if !known {
+			return nil, fmt.Errorf("未知的报告格式 %q（可选 csv、json、html）", name)
+		}
+		seen[name] = true
+		out = append(out, name)
+	}
+	if len(out) == 0 {
+		out = []Format{FormatCSV}
+	}
+	return out, nil
+}
+
+// JSONMember 为 JSON 报告中分组的一个成员
+type JSONMember struct {
+	Path     string     `json:"path"`
+	Kept     bool       `json:"kept"`
+	Size     int64      `json:"size"`
+	Distance *int       `json:"distance,omitempty"` // 与保留文件的距离，保留文件省略
+	Action   string     `json:"action,omitempty"`
+	NewPath  string     `json:"new_path,omitempty"`
+	Verify   string     `json:"verify,omitempty"`
+	Sidecars []string   `json:"sidecars,omitempty"`
+	Source   string     `json:"source_root,omitempty"`
+	Bitrate  int        `json:"bitrate,omitempty"`
+	Duration float64    `json:"duration,omitempty"`
+	SHA256   string     `json:"sha256,omitempty"`
+	Tags     *tags.Tags `json:"tags,omitempty"`
+}
+
+// JSONGroup 为 JSON 报告中的一个分组
+type JSONGroup struct {
+	ID      int          `json:"id"`
+	Keep    string       `json:"keep"` // 保留文件
+	Members []JSONMember `json:"members"`
+}
+
+// JSONFailure 为处理失败、未参与去重的文件
+type JSONFailure struct {
+	Path  string `json:"path"`
+	Size  int64  `json:"size,omitempty"`
+	Error string `json:"error"`
+}
+
+// JSONReport 为 JSON 报告的顶层结构
+type JSONReport struct {
+	Generated time.Time     `json:"generated"`
+	RunID     string        `json:"run_id,omitempty"`
+	Files     int           `json:"files"`
+	Groups    []JSONGroup   `json:"groups"`
+	Failed    []JSONFailure `json:"failed,omitempty"`
+}
+
+// BuildJSONReport 把逐条记录整理为按分组组织的报告（分组按 ID 排序，成员保持记录顺序）
+func BuildJSONReport(items []ReportItem) JSONReport {
+	r := JSONReport{Generated: time.Now(), Files: len(items), Groups: []JSONGroup{}}
+	index := map[int]int{}
+	for _, it := range items {
+		if r.RunID == "" {
+			r.RunID = it.RunID
+		}
+		if it.Action == ActionFailed {
+			r.Failed = append(r.Failed, JSONFailure{Path: it.FilePath, Size: it.Size, Error: it.Error})
+			continue
+		}
+		gi, ok := index[it.GroupID]
+		if !ok {
+			gi = len(r.Groups)
+			index[it.GroupID] = gi
+			r.Groups = append(r.Groups, JSONGroup{ID: it.GroupID})
+		}
+		m := JSONMember{Path: it.FilePath, Kept: it.Kept, Size: it.Size, Action: it.Action, NewPath: it.NewPath,
+			Verify: it.Verify, Sidecars: it.Sidecars, Source: it.SourceRoot, Bitrate: it.Bitrate, Duration: it.Duration, SHA256: it.SHA256}
+		if !it.Tags.Empty() {
+			t := it.Tags
+			m.Tags = &t
+		}
+		if !it.Kept {
+			d := it.Distance
+			m.Distance = &d
+			if r.Groups[gi].Keep == "" {
+				r.Groups[gi].Keep = it.KeptPath
+			}
+		} else if r.Groups[gi].Keep == "" {
+			r.Groups[gi].Keep = it.FilePath
+		}
+		r.Groups[gi].Members = append(r.Groups[gi].Members, m)
+	}
+	sort.SliceStable(r.Groups, func(i, j int) bool { return r.Groups[i].ID < r.Groups[j].ID })
+	return r
+}
+
+// WriteJSON 以缩进格式写出 JSON 报告
+func (r JSONReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+var htmlTmpl = template.Must(template.New("report").Funcs(template.FuncMap{
+	"human": HumanBytes,
+	"dupes": func(g JSONGroup) int { return len(g.Members) - 1 },
+}).Parse(`<!DOCTYPE html>
+<html lang="zh">
+<head>
+<meta charset="utf-8">
+<title>audio-dedup 去重报告</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; width: 100%; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+th { background: #f0f0f0; cursor: pointer; user-select: none; }
+th.asc::after { content: " ▲"; } th.desc::after { content: " ▼"; }
+tr.kept { background: #e8f5e9; }
+tr.failed { background: #ffebee; }
+td.num { text-align: right; font-variant-numeric: tabular-nums; }
+</style>
+</head>
+<body>
+<h1>去重报告</h1>
+<p>生成于 {{.Generated.Format "2006-01-02 15:04:05"}}：{{.Files}} 个文件，{{len .Groups}} 个分组{{if .Failed}}，{{len .Failed}} 个处理失败{{end}}。点击表头排序。</p>
+{{range .Groups}}{{if gt (len .Members) 1}}
+<h2 id="g{{.ID}}">组 {{.ID}}（{{dupes .}} 个重复）</h2>
+<table class="sortable">
+<thead><tr><th>文件</th><th>保留</th><th>大小</th><th>距离</th><th>处理</th><th>新路径</th></tr></thead>
+<tbody>
+{{range .Members}}<tr{{if .Kept}} class="kept"{{end}}><td>{{.Path}}</td><td>{{if .Kept}}是{{else}}否{{end}}</td><td class="num" data-sort="{{.Size}}">{{human .Size}}</td><td class="num">{{with .Distance}}{{.}}{{end}}</td><td>{{.Action}}</td><td>{{.NewPath}}</td></tr>
+{{end}}</tbody>
+</table>
+{{end}}{{end}}
+{{if .Failed}}<h2>处理失败</h2>
+<table class="sortable">
+<thead><tr><th>文件</th><th>大小</th><th>错误</th></tr></thead>
+<tbody>
+{{range .Failed}}<tr class="failed"><td>{{.Path}}</td><td class="num" data-sort="{{.Size}}">{{human .Size}}</td><td>{{.Error}}</td></tr>
+{{end}}</tbody>
+</table>
+{{end}}
+<script>
+document.querySelectorAll("table.sortable th").forEach(function (th) {
+  th.addEventListener("click", function () {
+    var table = th.closest("table"), body = table.tBodies[0], col = th.cellIndex;
+    var asc = !th.classList.contains("asc");
+    table.querySelectorAll("th").forEach(function (h) { h.classList.remove("asc", "desc"); });
+    th.classList.add(asc ? "asc" : "desc");
+    var key = function (tr) {
+      var td = tr.cells[col], v = td.dataset.sort !== undefined ? td.dataset.sort : td.textContent;
+      return v !== "" && !isNaN(v) ? Number(v) : v;
+    };
+    Array.from(body.rows).sort(function (a, b) {
+      var x = key(a), y = key(b), r = x < y ? -1 : x > y ? 1 : 0;
+      return asc ? r : -r;
+    }).forEach(function (tr) { body.appendChild(tr); });
+  });
+});
+</script>
+</body>
+</html>
+`))
+
+// WriteHTML 写出 HTML 报告：每个含重复文件的分组一张表格，表头可点击排序
+func (r JSONReport) WriteHTML(w io.Writer) error { return htmlTmpl.Execute(w, r) }
+
+// writeGrouped 把累积的记录写成 JSON 或 HTML 报告文件，返回文件名；main 表示该格式为主报告
+func (w *Writer) writeGrouped(format Format, main bool) (string, error) {
+	filename := w.fileName(format, main)
+	f, err := w.create(filename)
+	if err != nil {
+		return "", fmt.Errorf("create %s report error: %w", format, err)
+	}
+	r := BuildJSONReport(w.items)
+	if format == FormatHTML {
+		err = r.WriteHTML(f)
+	} else {
+		err = r.WriteJSON(f)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", fmt.Errorf("write %s report error: %w", format, err)
+	}
+	return filename, nil
+}
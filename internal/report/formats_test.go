@@ -0,0 +1,71 @@
+// file: internal/report/formats_test.go
+// package: report
+//
+// 测试报告格式解析、按分组整理的 JSON 结构，以及 HTML 输出的转义与只写出多成员分组。
+package report
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseFormats(t *testing.T) {
+	got, err := ParseFormats(" JSON, csv ,json")
+	if err != nil || len(got) != 2 || got[0] != FormatJSON || got[1] != FormatCSV {
+		t.Fatalf("ParseFormats = %v, %v", got, err)
+	}
+	if got, _ := ParseFormats(""); len(got) != 1 || got[0] != FormatCSV {
+		t.Fatalf("默认应只有 CSV: %v", got)
+	}
+	if _, err := ParseFormats("xml"); err == nil {
+		t.Fatal("未知格式应报错")
+	}
+}
+
+func TestGroupedReport(t *testing.T) {
+	items := []ReportItem{
+		{FilePath: "/b/k.flac", Kept: true, GroupID: 2},
+		{FilePath: "/b/<d>.mp3", GroupID: 2, KeptPath: "/b/k.flac", Distance: 4},
+		{FilePath: "/a/solo.mp3", Kept: true, GroupID: 1},
+		{FilePath: "/x.ogg", Action: ActionFailed, Error: "decode error"},
+	}
+	r := BuildJSONReport(items)
+	if len(r.Groups) != 2 || r.Groups[0].ID != 1 || r.Groups[1].Keep != "/b/k.flac" || len(r.Failed) != 1 {
+		t.Fatalf("分组不正确: %+v", r)
+	}
+	if m := r.Groups[1].Members; m[0].Distance != nil || m[1].Distance == nil || *m[1].Distance != 4 {
+		t.Fatalf("距离不正确: %+v", m)
+	}
+
+	var b bytes.Buffer
+	if err := r.WriteHTML(&b); err != nil {
+		t.Fatal(err)
+	}
+	html := b.String()
+	if !strings.Contains(html, "/b/&lt;d&gt;.mp3") || strings.Contains(html, "solo.mp3") || !strings.Contains(html, "decode error") {
+		t.Fatalf("HTML 输出不正确:\n%s", html)
+	}
+}
+
+func TestWriterWithoutCSV(t *testing.T) {
+	wd, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	w, err := CreateCSVReport(CSVOptions{Formats: []Format{FormatJSON}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Add(ReportItem{FilePath: "/k.flac", Kept: true, GroupID: 1})
+	name, err := w.Close()
+	if err != nil || !strings.HasSuffix(name, ".json") || len(w.Names()) != 1 {
+		t.Fatalf("Close = %s, %v, names=%v", name, err, w.Names())
+	}
+	if b, err := os.ReadFile(name); err != nil || !strings.Contains(string(b), `"keep": "/k.flac"`) {
+		t.Fatalf("JSON 报告内容不正确: %s, %v", b, err)
+	}
+}
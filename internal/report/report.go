@@ -4,53 +4,285 @@ package report
 import (
 	"encoding/csv"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
+
+	"deduplicateMusic/internal/gzfile"
+	"deduplicateMusic/internal/tags"
+)
+
+var (
+	stampOnce sync.Once
+	stamp     string
+	gzipOut   bool
 )
 
-// ReportItem 表示每个音频文件的处理记录
+// Stamp 返回本次运行的报告时间戳（首次调用时确定），同一次运行的各报告文件共用
+func Stamp() string {
+	stampOnce.Do(func() { stamp = time.Now().Format("20060102_150405") })
+	return stamp
+}
+
+// SetGzip 设置带时间戳的报告文件是否以 .gz 压缩写出（应在写任何报告前调用）
+func SetGzip(on bool) { gzipOut = on }
+
+// reportName 返回本次运行的报告文件名 audio_dedup_<kind>_<时间戳>.<ext>[.gz]
+func reportName(kind, ext string) string {
+	name := fmt.Sprintf("audio_dedup_%s_%s.%s", kind, Stamp(), ext)
+	if gzipOut {
+		name += gzfile.Ext
+	}
+	return name
+}
+
+// ReportItem 表示每个扫描到的音频文件的处理记录（保留、重复或处理失败）
 type ReportItem struct {
-	FilePath string // 原始文件路径
-	Kept     bool   // 是否保留
-	Size     int64  // 文件大小
-	NewPath  string // 如果保留，复制到的新路径
+	FilePath string    // 原始文件路径
+	Kept     bool      // 是否保留
+	Size     int64     // 文件大小
+	NewPath  string    // 如果保留，复制到的新路径
+	Verify   string    // FLAC 解码校验结果（ok / corrupt / no-md5），未校验时为空
+	Action   string    // 与目标目录已有文件相关的处理，见 Action* 常量；普通复制/重复时为空
+	Sidecars []string  // 重复文件的专属伴随文件（歌词、CUE 等），删除重复文件时应一并删除
+	GroupID  int       // 所属分组，处理失败的文件为 0
+	KeptPath string    // 重复文件所重复的保留文件（保留文件为空）
+	Distance int       // 重复文件与保留文件的距离（保留文件为 0）
+	Error    string    // 处理失败（Action 为 ActionFailed）时的错误
+	Bitrate  int       // 平均码率（kbps），仅在报告包含 Bitrate 列时计算，未知为 0
+	Duration float64   // 时长（秒），仅在报告包含 Bitrate / Duration 列时读取，未知为 0
+	SHA256   string    // 内容的 SHA-256，只对大小与其它文件相同的文件计算，其余为空
+	Tags     tags.Tags // 文件的标签（艺术家、标题、专辑、曲号等），便于核对分组是否合理
+
+	SourceRoot string // 文件所在的源根目录（-src、设备或目标目录），来源追溯用
+	RunID      string // 本次运行的编号（见 Stamp），与来源清单中的 run_id 一致
+}
+
+// 目标目录升级（-upgrade-dst）相关的处理动作
+const (
+	ActionUpgraded = "upgraded" // 替换了目标目录中质量较低的旧版本
+	ActionBackup   = "backup"   // 被替换的旧版本，NewPath 为其备份位置
+	ActionInDst    = "in-dst"   // 目标目录中已有最佳版本，未导入
+	ActionSkipped  = "skipped"  // -upgrade-only 模式下目标目录中没有的新曲目，未导入
+	ActionInRef    = "in-ref"   // 参考资料库（-ref）中的文件：只参与比对，不复制、不删除
+
+	ActionIncomplete    = "incomplete-album" // 所在专辑的完整度低于 -min-album-completeness，未导入
+	ActionChanged       = "changed"          // 文件在决策后被改动，已跳过（不复制，也不列入删除清单）
+	ActionKeeperChanged = "keeper-changed"   // 保留文件在移除重复文件前已不存在或被改动，重复文件未移除
+	ActionFailed        = "failed"           // 计算指纹失败，未参与去重，原因见 Error
+
+	// 目标文件名冲突（-on-collision）时保留文件的处理
+	ActionRenamed          = "renamed"           // 改名保存，NewPath 为带后缀的新名字
+	ActionOverwritten      = "overwritten"       // 覆盖了目标目录中同名的其它文件
+	ActionCollisionSkipped = "collision-skipped" // 未放入目标目录
+	ActionCollisionError   = "collision-error"   // 未放入目标目录，按处理失败记录
+
+	// 原地去重（-in-place）对重复文件的处理，NewPath 为文件被移到的位置
+	ActionDeleted     = "deleted"
+	ActionTrashed     = "trashed"
+	ActionQuarantined = "quarantined"
+)
+
+// FooterMarker 为报告结束标记行的第一列；没有结束标记的报告是中途中断的部分报告
+const FooterMarker = "#complete"
+
+// IsFooter 返回 CSV 记录是否为结束标记行
+func IsFooter(record []string) bool { return len(record) > 0 && record[0] == FooterMarker }
+
+// Writer 边处理边写出 CSV 报告：每条记录写入后立即刷新到文件，不在内存中累积，
+// 长时间运行中途崩溃时已写出的行仍然可用；Close 时追加结束标记行（见 FooterMarker）。
+// 同时要求 JSON / HTML 格式时记录另在内存中累积，Close 时写出。
+type Writer struct {
+	cols    []Column
+	file    *gzfile.File
+	csv     *csv.Writer
+	name    string
+	rows    int
+	grouped []Format     // 需要在 Close 时写出的 JSON / HTML 格式
+	items   []ReportItem // grouped 非空时累积的记录
+	names   []string
+	path    string    // 见 CSVOptions.Path
+	stdout  io.Writer // path 为 StdoutPath 时的输出
+}
+
+// fileName 返回 format 格式报告的文件名，main 表示主报告（使用 Path 本身）
+func (w *Writer) fileName(format Format, main bool) string {
+	switch {
+	case w.path == "":
+		return reportName("report", string(format))
+	case main:
+		return w.path
+	}
+	base, gz := w.path, ""
+	if gzfile.IsCompressed(base) {
+		base, gz = base[:len(base)-len(gzfile.Ext)], gzfile.Ext
+	}
+	return strings.TrimSuffix(base, filepath.Ext(base)) + "." + string(format) + gz
+}
+
+// create 创建报告文件，name 为 StdoutPath 时写到标准输出
+func (w *Writer) create(name string) (*gzfile.File, error) {
+	if name == StdoutPath {
+		return gzfile.NewWriter(w.stdout, false), nil
+	}
+	if dir := filepath.Dir(name); w.path != "" && dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return gzfile.Create(name)
+}
+
+// StdoutPath 作为 CSVOptions.Path 时表示把报告写到标准输出
+const StdoutPath = "-"
+
+// CSVOptions 控制报告的列、表头语言、输出格式与位置
+type CSVOptions struct {
+	Columns []string // 列名（见 ParseColumns），为空时使用 DefaultColumns
+	Lang    string   // 表头语言（见 Languages），为空时为 en
+	Formats []Format // 输出格式（见 ParseFormats），为空时只输出 CSV
+
+	// Path 为主报告（第一个格式）的路径，为空时在当前目录生成带时间戳的文件名；
+	// 其余格式写到同名、扩展名换成格式名的文件。以 .gz 结尾时压缩写出。
+	// 为 StdoutPath 时写到 Stdout（未设置时为 os.Stdout），此时只能有一个格式。
+	Path     string
+	Stdout   io.Writer
+	Disabled bool // 不生成报告：Add 只计数，Close 不写任何文件
+}
+
+// Validate 检查列名、语言与输出位置是否有效（用于在长时间运行开始前尽早报错）
+func (o CSVOptions) Validate() error {
+	if o.Path == StdoutPath && len(o.Formats) > 1 {
+		return fmt.Errorf("报告写到标准输出时只能指定一种格式")
+	}
+	_, _, err := resolveColumns(o.Columns, o.Lang)
+	return err
 }
 
-// WriteCSVReport 将报告写入 CSV 文件
-func WriteCSVReport(items []ReportItem) error {
-	// 当前目录下生成去重报告，文件名带时间戳
-	filename := fmt.Sprintf("audio_dedup_report_%s.csv", time.Now().Format("20060102_150405"))
-	file, err := os.Create(filename)
+// CreateCSVReport 创建报告文件（默认在当前目录生成带时间戳的文件名，见 CSVOptions.Path）并写入表头
+func CreateCSVReport(opt CSVOptions) (*Writer, error) {
+	if err := opt.Validate(); err != nil {
+		return nil, err
+	}
+	cols, header, err := resolveColumns(opt.Columns, opt.Lang)
 	if err != nil {
-		return fmt.Errorf("create report file error: %w", err)
+		return nil, err
+	}
+	w := &Writer{cols: cols, path: opt.Path, stdout: opt.Stdout}
+	if w.stdout == nil {
+		w.stdout = os.Stdout
+	}
+	if opt.Disabled {
+		return w, nil
 	}
-	defer file.Close()
+	wantCSV := len(opt.Formats) == 0
+	for _, f := range opt.Formats {
+		if f == FormatCSV {
+			wantCSV = true
+		} else {
+			w.grouped = append(w.grouped, f)
+		}
+	}
+	if !wantCSV {
+		w.name = w.fileName(w.grouped[0], true)
+		return w, nil
+	}
+	w.name = w.fileName(FormatCSV, true)
+	file, err := w.create(w.name)
+	if err != nil {
+		return nil, fmt.Errorf("create report file error: %w", err)
+	}
+	w.file, w.csv = file, csv.NewWriter(file)
+	if err := w.write(header); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("write csv header error: %w", err)
+	}
+	return w, nil
+}
+
+// Name 返回主报告文件名（有 CSV 时为 CSV，否则为第一个格式；写到标准输出时为 StdoutPath，不生成报告时为空）
+func (w *Writer) Name() string { return w.name }
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+// Names 返回 Close 后写出的全部报告文件名
+func (w *Writer) Names() []string { return w.names }
 
-	// 写入表头
-	if err := writer.Write([]string{"FilePath", "Kept", "Size", "NewPath"}); err != nil {
-		return fmt.Errorf("write csv header error: %w", err)
+// Add 写入一条记录并刷新到文件
+func (w *Writer) Add(item ReportItem) error {
+	if len(w.grouped) > 0 {
+		w.items = append(w.items, item)
 	}
+	if w.csv == nil {
+		w.rows++
+		return nil
+	}
+	record := make([]string, len(w.cols))
+	for i, c := range w.cols {
+		record[i] = c.Value(item)
+	}
+	if err := w.write(record); err != nil {
+		return fmt.Errorf("write csv record error: %w", err)
+	}
+	w.rows++
+	return nil
+}
 
-	// 写入每行记录
-	for _, item := range items {
-		kept := "No"
-		if item.Kept {
-			kept = "Yes"
+func (w *Writer) write(record []string) error {
+	if err := w.csv.Write(record); err != nil {
+		return err
+	}
+	w.csv.Flush()
+	if err := w.csv.Error(); err != nil {
+		return err
+	}
+	return w.file.Flush()
+}
+
+// Close 写入结束标记行（记录数、完成时间）并关闭文件，再写出 JSON / HTML 报告，返回主报告文件名
+func (w *Writer) Close() (string, error) {
+	if w.csv != nil {
+		// 结束标记行与表头同宽（兼容要求每行列数一致的读取方），列数不足时依次省略完成时间、记录数
+		footer := make([]string, len(w.cols))
+		copy(footer, []string{FooterMarker, fmt.Sprintf("%d", w.rows), time.Now().Format(time.RFC3339)})
+		err := w.write(footer)
+		if cerr := w.file.Close(); err == nil {
+			err = cerr
 		}
-		record := []string{
-			item.FilePath,
-			kept,
-			fmt.Sprintf("%d", item.Size),
-			item.NewPath,
+		if err != nil {
+			return "", fmt.Errorf("finalize report error: %w", err)
 		}
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("write csv record error: %w", err)
+		w.names = append(w.names, w.name)
+	}
+	for i, f := range w.grouped {
+		name, err := w.writeGrouped(f, w.csv == nil && i == 0)
+		if err != nil {
+			return "", err
 		}
+		w.names = append(w.names, name)
 	}
+	return w.name, nil
+}
 
+// WriteCSVReport 将报告一次性写入 CSV 文件，返回生成的文件名
+func WriteCSVReport(items []ReportItem) (string, error) {
+	w, err := CreateCSVReport(CSVOptions{})
+	if err != nil {
+		return "", err
+	}
+	for _, item := range items {
+		if err := w.Add(item); err != nil {
+			if w.file != nil {
+				w.file.Close()
+			}
+			return "", err
+		}
+	}
+	filename, err := w.Close()
+	if err != nil {
+		return "", err
+	}
 	fmt.Printf("去重报告已生成: %s\n", filename)
-	return nil
+	return filename, nil
 }
@@ -0,0 +1,187 @@
+// file: internal/report/report_test.go
+// package: report
+//
+// 验证流式报告：每条记录写入后即可从文件读到，Close 后追加结束标记行；以及报告路径、标准输出与不生成报告。
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStreamingWriter(t *testing.T) {
+	wd, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	w, err := CreateCSVReport(CSVOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := func() [][]string {
+		f, err := os.Open(w.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		recs, err := csv.NewReader(f).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return recs
+	}
+	if err := w.Add(ReportItem{FilePath: "/a.flac", Kept: true, Size: 3}); err != nil {
+		t.Fatal(err)
+	}
+	// 未 Close 时（模拟中途崩溃）已写出的行可读，且没有结束标记
+	recs := read()
+	if len(recs) != 2 || recs[1][0] != "/a.flac" || IsFooter(recs[len(recs)-1]) {
+		t.Fatalf("部分报告不正确: %v", recs)
+	}
+	if err := w.Add(ReportItem{FilePath: "/b.mp3", Sidecars: []string{"/b.lrc", "/b.cue"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	recs = read()
+	last := recs[len(recs)-1]
+	if len(recs) != 4 || !IsFooter(last) || last[1] != "2" || recs[2][6] != "/b.lrc;/b.cue" {
+		t.Fatalf("完整报告不正确: %v", recs)
+	}
+}
+
+func TestGzipReportName(t *testing.T) {
+	defer SetGzip(false)
+	SetGzip(true)
+	if name := reportName("report", "csv"); name != "audio_dedup_report_"+Stamp()+".csv.gz" {
+		t.Fatalf("reportName = %s", name)
+	}
+}
+
+func TestReportColumns(t *testing.T) {
+	wd, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	cols, err := ParseColumns(" groupid, FilePath ,distance,Duration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseColumns("FilePath,Nope"); err == nil {
+		t.Fatal("未知列应报错")
+	}
+	if err := (CSVOptions{Lang: "fr"}).Validate(); err == nil {
+		t.Fatal("未知语言应报错")
+	}
+	w, err := CreateCSVReport(CSVOptions{Columns: cols, Lang: "zh"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Add(ReportItem{FilePath: "/k.flac", Kept: true, GroupID: 3, Duration: 215.04})
+	w.Add(ReportItem{FilePath: "/d.mp3", GroupID: 3, Distance: 0})
+	name, err := w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, _ := os.Open(name)
+	defer f.Close()
+	recs, err := csv.NewReader(f).ReadAll() // 默认要求每行列数一致
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"组号", "文件路径", "距离", "时长(秒)"},
+		{"3", "/k.flac", "", "215.0"},
+		{"3", "/d.mp3", "0", ""},
+	}
+	for i, row := range want {
+		for j := range row {
+			if recs[i][j] != row[j] {
+				t.Fatalf("第 %d 行: %v，期望 %v", i, recs[i], row)
+			}
+		}
+	}
+	if !IsFooter(recs[3]) || recs[3][1] != "2" {
+		t.Fatalf("结束标记行不正确: %v", recs[3])
+	}
+}
+
+func TestDefaultColumnsCoverDuplicatesAndFailures(t *testing.T) {
+	cols, header, err := resolveColumns(nil, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx := map[string]int{}
+	for i, h := range header {
+		idx[h] = i
+	}
+	row := func(it ReportItem) []string {
+		out := make([]string, len(cols))
+		for i, c := range cols {
+			out[i] = c.Value(it)
+		}
+		return out
+	}
+	dup := row(ReportItem{FilePath: "/d.mp3", GroupID: 2, KeptPath: "/k.flac", Distance: 3})
+	if dup[idx["Kept"]] != "No" || dup[idx["GroupID"]] != "2" || dup[idx["KeptPath"]] != "/k.flac" || dup[idx["Distance"]] != "3" {
+		t.Fatalf("重复文件行不正确: %v", dup)
+	}
+	bad := row(ReportItem{FilePath: "/x.ogg", Action: ActionFailed, Error: "decode error"})
+	if bad[idx["Action"]] != ActionFailed || bad[idx["Error"]] != "decode error" || bad[idx["Distance"]] != "" || bad[idx["GroupID"]] != "" {
+		t.Fatalf("处理失败行不正确: %v", bad)
+	}
+}
+
+func TestReportPathOptions(t *testing.T) {
+	// 指定路径：主报告写到 Path（自动创建目录），其余格式换扩展名
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out", "r.csv")
+	w, err := CreateCSVReport(CSVOptions{Path: path, Formats: []Format{FormatCSV, FormatJSON}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Add(ReportItem{FilePath: "/a.flac", Kept: true})
+	if name, err := w.Close(); err != nil || name != path {
+		t.Fatalf("Close = %q, %v", name, err)
+	}
+	if names := w.Names(); len(names) != 2 || names[1] != filepath.Join(dir, "out", "r.json") {
+		t.Fatalf("Names = %v", names)
+	}
+
+	// 标准输出
+	var buf bytes.Buffer
+	w, err = CreateCSVReport(CSVOptions{Path: StdoutPath, Stdout: &buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Add(ReportItem{FilePath: "/a.flac", Kept: true})
+	if name, err := w.Close(); err != nil || name != StdoutPath {
+		t.Fatalf("Close = %q, %v", name, err)
+	}
+	if recs, err := csv.NewReader(&buf).ReadAll(); err != nil || len(recs) != 3 || !IsFooter(recs[2]) {
+		t.Fatalf("标准输出报告不正确: %v, %v", recs, err)
+	}
+	if _, err := CreateCSVReport(CSVOptions{Path: StdoutPath, Formats: []Format{FormatCSV, FormatHTML}}); err == nil {
+		t.Fatal("标准输出不应允许多种格式")
+	}
+
+	// 不生成报告
+	w, err = CreateCSVReport(CSVOptions{Path: path + ".unused", Disabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Add(ReportItem{FilePath: "/a.flac"})
+	if name, err := w.Close(); err != nil || name != "" || len(w.Names()) != 0 {
+		t.Fatalf("Close = %q, %v, %v", name, w.Names(), err)
+	}
+	if _, err := os.Stat(path + ".unused"); !os.IsNotExist(err) {
+		t.Fatal("不应创建报告文件")
+	}
+}
@@ -0,0 +1,47 @@
+// file: internal/report/runsummary.go
+// package: report
+//
+// 退出摘要：一次运行的计数、字节数、耗时与报告路径，以单个 JSON 对象输出，供包装脚本解析
+// （-summary-json）。字段名一经发布即保持稳定，新增字段只追加。
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// RunSummary 为 -summary-json 输出的退出摘要
+type RunSummary struct {
+	Files             int    `json:"files"`              // 扫描到的音频文件数
+	Fingerprinted     int    `json:"fingerprinted"`      // 成功计算指纹的文件数
+	Errors            int    `json:"errors"`             // 处理失败的文件数
+	Skipped           int    `json:"skipped"`            // 无法访问而跳过的目录 / 文件数
+	SkippedPermission int    `json:"skipped_permission"` // 其中因权限不足的数量
+	Groups            int    `json:"groups"`             // 分组数（含没有重复的单文件组）
+	Kept              int    `json:"kept"`               // 保留文件数（含受保护的成员）
+	Duplicates        int    `json:"duplicates"`         // 重复文件数
+	Copied            int    `json:"copied"`             // 实际复制到目标目录的文件数
+	Upgraded          int    `json:"upgraded"`           // 替换了目标目录旧版本的文件数
+	Changed           int    `json:"changed"`            // 决策后被改动而跳过的文件数
+	Removed           int    `json:"removed"`            // -in-place 时移除的重复文件数
+	KeptBytes         int64  `json:"kept_bytes"`         // 保留文件总字节数
+	DuplicateBytes    int64  `json:"duplicate_bytes"`    // 重复文件总字节数（即可回收空间）
+	CopiedBytes       int64  `json:"copied_bytes"`       // 复制到目标目录的字节数
+	RemovedBytes      int64  `json:"removed_bytes"`      // -in-place 时移除的字节数
+	CacheHits         int    `json:"cache_hits"`         // 指纹缓存命中数
+	CacheMisses       int    `json:"cache_misses"`       // 指纹缓存未命中（重新解码）数
+	Report            string `json:"report,omitempty"`   // CSV 报告路径，生成失败时为空
+	Summary           string `json:"summary,omitempty"`
+	Stopped           string `json:"stopped,omitempty"` // 因时间预算提前停止的原因，完整运行时为空
+
+	Started            time.Time `json:"started"`
+	ElapsedSeconds     float64   `json:"elapsed_seconds"`
+	FingerprintSeconds float64   `json:"fingerprint_seconds"` // 扫描与计算指纹阶段
+	ProcessSeconds     float64   `json:"process_seconds"`     // 分组、复制与生成报告阶段
+}
+
+// WriteJSON 把摘要写成单行 JSON（以换行结尾）
+func (s RunSummary) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(s)
+}
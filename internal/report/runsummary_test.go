@@ -0,0 +1,32 @@
+// file: internal/report/runsummary_test.go
+// package: report
+//
+// 测试退出摘要输出为单行 JSON，字段名稳定。
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRunSummaryJSON(t *testing.T) {
+	var b bytes.Buffer
+	if err := (RunSummary{Files: 3, Errors: 1, DuplicateBytes: 42, Report: "r.csv"}).WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(b.String(), "\n") != 1 {
+		t.Fatalf("应为单行 JSON: %q", b.String())
+	}
+	var m map[string]any
+	if err := json.Unmarshal(b.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["files"] != 3.0 || m["errors"] != 1.0 || m["duplicate_bytes"] != 42.0 || m["report"] != "r.csv" {
+		t.Fatalf("字段不正确: %v", m)
+	}
+	if _, ok := m["summary"]; ok {
+		t.Fatalf("空的摘要路径应省略: %v", m)
+	}
+}
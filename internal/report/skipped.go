@@ -0,0 +1,98 @@
+// file: internal/report/skipped.go
+// package: report
+//
+// 跳过的文件：扫描时无法访问的目录、计算指纹前无法读取的文件单独归类报告，
+// 权限不足与其它错误分开计数，并给出提升权限重试的提示。
+package report
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"deduplicateMusic/internal/gzfile"
+)
+
+// 跳过发生的阶段
+const (
+	SkipStageScan        = "scan"        // 扫描目录时（整个目录被跳过）
+	SkipStageFingerprint = "fingerprint" // 打开文件计算指纹时
+)
+
+// Skipped 为一个被跳过的路径
+type Skipped struct {
+	Path       string
+	Stage      string // 见 SkipStage* 常量
+	Permission bool   // 是否因权限不足
+	Reason     string
+}
+
+// NewSkipped 根据错误构造跳过记录
+func NewSkipped(path, stage string, err error) Skipped {
+	return Skipped{Path: path, Stage: stage, Permission: errors.Is(err, fs.ErrPermission), Reason: err.Error()}
+}
+
+// CountPermission 返回因权限不足而跳过的数量
+func CountPermission(items []Skipped) int {
+	n := 0
+	for _, s := range items {
+		if s.Permission {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteSkippedText 以文本形式输出跳过的路径（最多 topN 条，topN <= 0 表示全部）；
+// 存在权限问题时附上 retry 提示（如以 sudo 重新运行的命令）
+func WriteSkippedText(w io.Writer, items []Skipped, topN int, retry string) {
+	perm := CountPermission(items)
+	fmt.Fprintf(w, "== 跳过的路径：%d 个（权限不足 %d，其它错误 %d）==\n", len(items), perm, len(items)-perm)
+	for i, s := range items {
+		if topN > 0 && i >= topN {
+			fmt.Fprintf(w, "  ……其余 %d 项省略\n", len(items)-topN)
+			break
+		}
+		kind := "错误"
+		if s.Permission {
+			kind = "无权限"
+		}
+		what := "文件"
+		if s.Stage == SkipStageScan {
+			what = "目录"
+		}
+		fmt.Fprintf(w, "  [%s] %s（%s）\n", kind, s.Path, what)
+	}
+	if perm > 0 && retry != "" {
+		fmt.Fprintf(w, "  提示：这些文件没有被比较，可能与已保留的文件重复。修正权限或提升权限后重新运行：\n    %s\n", retry)
+	}
+}
+
+// WriteSkippedReport 把跳过的路径写入 audio_dedup_skipped_<时间戳>.csv，返回文件名
+func WriteSkippedReport(items []Skipped) (string, error) {
+	filename := reportName("skipped", "csv")
+	f, err := gzfile.Create(filename)
+	if err != nil {
+		return "", fmt.Errorf("create skipped report error: %w", err)
+	}
+	w := csv.NewWriter(f)
+	w.Write([]string{"Path", "Stage", "Category", "Reason"})
+	for _, s := range items {
+		category := "error"
+		if s.Permission {
+			category = "permission"
+		}
+		w.Write([]string{s.Path, s.Stage, category, s.Reason})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return "", fmt.Errorf("write skipped report error: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("write skipped report error: %w", err)
+	}
+	return filename, nil
+}
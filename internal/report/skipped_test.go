@@ -0,0 +1,37 @@
+// file: internal/report/skipped_test.go
+// package: report
+//
+// 测试跳过路径的权限分类与文本输出中的重试提示。
+package report
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSkippedText(t *testing.T) {
+	items := []Skipped{
+		NewSkipped("/m/locked", SkipStageScan, &fs.PathError{Op: "open", Path: "/m/locked", Err: os.ErrPermission}),
+		NewSkipped("/m/bad.mp3", SkipStageFingerprint, errors.New("io error")),
+	}
+	if CountPermission(items) != 1 || !items[0].Permission || items[1].Permission {
+		t.Fatalf("权限分类不正确: %+v", items)
+	}
+	var b bytes.Buffer
+	WriteSkippedText(&b, items, 0, "sudo audio-dedup -src /m")
+	out := b.String()
+	for _, want := range []string{"权限不足 1，其它错误 1", "[无权限] /m/locked（目录）", "sudo audio-dedup -src /m"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("输出缺少 %q:\n%s", want, out)
+		}
+	}
+	b.Reset()
+	WriteSkippedText(&b, items[1:], 0, "sudo x")
+	if strings.Contains(b.String(), "sudo") {
+		t.Fatalf("没有权限问题时不应提示提升权限:\n%s", b.String())
+	}
+}
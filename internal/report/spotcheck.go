@@ -0,0 +1,56 @@
+// file: internal/report/spotcheck.go
+// package: report
+//
+// 运行后抽查结果：每对抽查的决策一行，列出重新计算的距离与发现的不一致。
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"deduplicateMusic/internal/gzfile"
+	"deduplicateMusic/internal/spotcheck"
+)
+
+// WriteSpotCheckText 输出抽查结论，只列出不一致的决策
+func WriteSpotCheckText(w io.Writer, results []spotcheck.Result) {
+	bad := 0
+	for _, r := range results {
+		if len(r.Problems) > 0 {
+			bad++
+		}
+	}
+	fmt.Fprintf(w, "== 抽查 %d 对决策，%d 对不一致 ==\n", len(results), bad)
+	for _, r := range results {
+		if len(r.Problems) > 0 {
+			fmt.Fprintf(w, "  组 %d：%s <-> %s：%s\n", r.GroupID, r.KeepFile, r.Dup, strings.Join(r.Problems, "；"))
+		}
+	}
+}
+
+// WriteSpotCheckReport 把抽查结果写入 audio_dedup_spotcheck_<时间戳>.csv，返回文件名
+func WriteSpotCheckReport(results []spotcheck.Result) (string, error) {
+	filename := reportName("spotcheck", "csv")
+	f, err := gzfile.Create(filename)
+	if err != nil {
+		return "", fmt.Errorf("create spot-check report error: %w", err)
+	}
+	w := csv.NewWriter(f)
+	w.Write([]string{"GroupID", "Keep", "KeepFile", "Duplicate", "Distance", "NewDistance", "Problems"})
+	for _, r := range results {
+		w.Write([]string{strconv.Itoa(r.GroupID), r.Keep, r.KeepFile, r.Dup, strconv.Itoa(r.Distance),
+			strconv.Itoa(r.NewDistance), strings.Join(r.Problems, "; ")})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return "", fmt.Errorf("write spot-check report error: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("write spot-check report error: %w", err)
+	}
+	return filename, nil
+}
@@ -0,0 +1,195 @@
+// file: internal/report/summary.go
+// package: report
+//
+// 运行摘要：按艺术家 / 专辑统计重复文件数量与可回收空间，列出可回收空间最大的重复文件，
+// 打印到控制台并写入与 CSV 报告同批次的摘要文本文件。
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"deduplicateMusic/internal/dedup"
+	"deduplicateMusic/internal/gzfile"
+	"deduplicateMusic/internal/textnorm"
+)
+
+const (
+	unknownArtist = "（未知艺术家）"
+	unknownAlbum  = "（未知专辑）"
+)
+
+// DupStat 表示某个艺术家或专辑下的重复统计
+type DupStat struct {
+	Name  string
+	Files int   // 重复文件数
+	Bytes int64 // 重复文件总字节数（即可回收空间）
+}
+
+// Reclaimable 表示一个可删除以回收空间的重复文件
+type Reclaimable struct {
+	Path     string
+	Size     int64
+	KeptPath string // 该文件所重复的保留文件
+	GroupID  int
+}
+
+// Summary 汇总一次运行的重复统计
+type Summary struct {
+	ByArtist    []DupStat
+	ByAlbum     []DupStat
+	Reclaimable []Reclaimable // 按体积降序
+	Advice      []string      // 参数调整建议（如阈值校准，见 internal/calibrate），没有时不输出该节
+}
+
+// Summarize 根据分组结果统计每个艺术家 / 专辑的重复文件（按字节数降序）
+func Summarize(groups []dedup.Group) Summary {
+	var b SummaryBuilder
+	for _, g := range groups {
+		b.Add(g)
+	}
+	return b.Summary()
+}
+
+// SummaryBuilder 逐组累积统计，适合分组结果以流的方式产生（不必一次性持有全部分组）
+type SummaryBuilder struct {
+	artists map[string]*DupStat
+	albums  map[string]*DupStat
+	reclaim []Reclaimable
+}
+
+// Add 累积一个分组
+func (b *SummaryBuilder) Add(g dedup.Group) {
+	if b.artists == nil {
+		b.artists = map[string]*DupStat{}
+		b.albums = map[string]*DupStat{}
+	}
+	add := func(m map[string]*DupStat, name string, size int64) {
+		// 按规范化后的名称合并（如 "周杰倫" 与 "周杰伦"），显示第一次出现的写法
+		key := textnorm.Normalize(name)
+		st, ok := m[key]
+		if !ok {
+			st = &DupStat{Name: name}
+			m[key] = st
+		}
+		st.Files++
+		st.Bytes += size
+	}
+	for _, d := range g.Duplicates {
+		artist := artistOf(d.FileMeta)
+		album := d.Tags.Album
+		if album == "" {
+			album = unknownAlbum
+		}
+		add(b.artists, artist, d.Size)
+		if d.Tags.IsCompilation() {
+			add(b.albums, "Various Artists - "+album, d.Size)
+		} else {
+			add(b.albums, artist+" - "+album, d.Size)
+		}
+		b.reclaim = append(b.reclaim, Reclaimable{Path: d.Path, Size: d.Size, KeptPath: g.Keep.Path, GroupID: g.ID})
+	}
+}
+
+// Summary 返回排序后的统计结果
+func (b *SummaryBuilder) Summary() Summary {
+	reclaim := append([]Reclaimable(nil), b.reclaim...)
+	sort.Slice(reclaim, func(i, j int) bool {
+		if reclaim[i].Size != reclaim[j].Size {
+			return reclaim[i].Size > reclaim[j].Size
+		}
+		return reclaim[i].Path < reclaim[j].Path
+	})
+	return Summary{ByArtist: sortStats(b.artists), ByAlbum: sortStats(b.albums), Reclaimable: reclaim}
+}
+
+// artistOf 返回统计用的艺术家；合辑（Various Artists）按曲目艺术家统计
+func artistOf(m dedup.FileMeta) string {
+	if a := m.Tags.TrackArtist(); a != "" {
+		return a
+	}
+	return unknownArtist
+}
+
+func sortStats(m map[string]*DupStat) []DupStat {
+	out := make([]DupStat, 0, len(m))
+	for _, st := range m {
+		out = append(out, *st)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Bytes != out[j].Bytes {
+			return out[i].Bytes > out[j].Bytes
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// WriteText 以文本形式输出摘要，每个分类最多 topN 行（<=0 表示全部）
+func (s Summary) WriteText(w io.Writer, topN int) {
+	section := func(title string, stats []DupStat) {
+		fmt.Fprintf(w, "== %s ==\n", title)
+		if len(stats) == 0 {
+			fmt.Fprintln(w, "  （无重复）")
+			return
+		}
+		for i, st := range stats {
+			if topN > 0 && i >= topN {
+				fmt.Fprintf(w, "  ……其余 %d 项省略\n", len(stats)-topN)
+				break
+			}
+			fmt.Fprintf(w, "  %s: %d 个重复文件, %s\n", st.Name, st.Files, HumanBytes(st.Bytes))
+		}
+	}
+	section("按艺术家统计重复", s.ByArtist)
+	section("按专辑统计重复", s.ByAlbum)
+
+	var total int64
+	for _, r := range s.Reclaimable {
+		total += r.Size
+	}
+	fmt.Fprintf(w, "== 可回收空间最大的重复文件（共 %d 个，%s）==\n", len(s.Reclaimable), HumanBytes(total))
+	for i, r := range s.Reclaimable {
+		if topN > 0 && i >= topN {
+			fmt.Fprintf(w, "  ……其余 %d 项省略\n", len(s.Reclaimable)-topN)
+			break
+		}
+		fmt.Fprintf(w, "  %10s  %s  (组 %d，保留 %s)\n", HumanBytes(r.Size), r.Path, r.GroupID, r.KeptPath)
+	}
+	if len(s.Advice) > 0 {
+		fmt.Fprintln(w, "== 建议 ==")
+		for _, a := range s.Advice {
+			fmt.Fprintf(w, "  %s\n", a)
+		}
+	}
+}
+
+// WriteSummaryReport 把完整摘要写到当前目录下带时间戳的文本文件，返回文件名
+func WriteSummaryReport(s Summary) (string, error) {
+	filename := reportName("summary", "txt")
+	f, err := gzfile.Create(filename)
+	if err != nil {
+		return "", fmt.Errorf("create summary file error: %w", err)
+	}
+	s.WriteText(f, 0)
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("write summary file error: %w", err)
+	}
+	return filename, nil
+}
+
+// HumanBytes 把字节数格式化为易读形式，如 4.2 GB
+func HumanBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %s", float64(n)/float64(div), strings.Split("KB MB GB TB PB EB", " ")[exp])
+}
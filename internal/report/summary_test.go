@@ -0,0 +1,63 @@
+// file: internal/report/summary_test.go
+// package: report
+//
+// 测试按艺术家/专辑统计重复，以及字节数格式化。
+package report
+
+import (
+	"testing"
+
+	"deduplicateMusic/internal/dedup"
+	"deduplicateMusic/internal/tags"
+)
+
+func TestSummarize(t *testing.T) {
+	groups := []dedup.Group{{
+		Keep: dedup.FileMeta{Path: "k.mp3"},
+		Duplicates: []dedup.Member{
+			{FileMeta: dedup.FileMeta{Path: "a.mp3", Size: 100, Tags: tags.Tags{Artist: "X", Album: "A"}}},
+			{FileMeta: dedup.FileMeta{Path: "b.mp3", Size: 300, Tags: tags.Tags{Artist: "X", Album: "B"}}},
+			{FileMeta: dedup.FileMeta{Path: "c.mp3", Size: 50}},
+		},
+	}}
+	s := Summarize(groups)
+	if len(s.ByArtist) != 2 || s.ByArtist[0] != (DupStat{Name: "X", Files: 2, Bytes: 400}) {
+		t.Fatalf("按艺术家统计不正确: %#v", s.ByArtist)
+	}
+	if len(s.ByAlbum) != 3 || s.ByAlbum[0].Name != "X - B" {
+		t.Fatalf("按专辑统计不正确: %#v", s.ByAlbum)
+	}
+	if len(s.Reclaimable) != 3 || s.Reclaimable[0].Path != "b.mp3" || s.Reclaimable[2].Path != "c.mp3" {
+		t.Fatalf("可回收文件排序不正确: %#v", s.Reclaimable)
+	}
+	if s.Reclaimable[0].KeptPath != "k.mp3" {
+		t.Fatalf("可回收文件应记录保留文件: %#v", s.Reclaimable[0])
+	}
+}
+
+func TestSummarizeCompilation(t *testing.T) {
+	groups := []dedup.Group{{
+		Keep: dedup.FileMeta{Path: "orig.flac", Tags: tags.Tags{Artist: "Y", AlbumArtist: "Y", Album: "Debut"}},
+		Duplicates: []dedup.Member{
+			{FileMeta: dedup.FileMeta{Path: "va.mp3", Size: 10, Tags: tags.Tags{Artist: "Y", AlbumArtist: "Various Artists", Album: "Hits 2001"}}},
+			{FileMeta: dedup.FileMeta{Path: "va2.mp3", Size: 5, Tags: tags.Tags{Artist: "Ｙ", Album: "Hits 2002", Compilation: true}}},
+		},
+	}}
+	s := Summarize(groups)
+	// 合辑中的曲目按曲目艺术家统计（全角写法与半角合并），专辑归到 Various Artists 下
+	if len(s.ByArtist) != 1 || s.ByArtist[0].Name != "Y" || s.ByArtist[0].Files != 2 {
+		t.Fatalf("按艺术家统计不正确: %#v", s.ByArtist)
+	}
+	if s.ByAlbum[0].Name != "Various Artists - Hits 2001" || s.ByAlbum[1].Name != "Various Artists - Hits 2002" {
+		t.Fatalf("按专辑统计不正确: %#v", s.ByAlbum)
+	}
+}
+
+func TestHumanBytes(t *testing.T) {
+	cases := map[int64]string{512: "512 B", 2048: "2.0 KB", 4509715661: "4.2 GB"}
+	for n, want := range cases {
+		if got := HumanBytes(n); got != want {
+			t.Fatalf("HumanBytes(%d) = %s，期望 %s", n, got, want)
+		}
+	}
+}
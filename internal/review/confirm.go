@@ -0,0 +1,31 @@
+// file: internal/review/confirm.go
+// package: review
+//
+// 破坏性操作前的确认：原地移除重复文件或移动保留文件之前，显示按分组结果汇总的影响并要求输入 y，
+// 可用 -yes 跳过。
+package review
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Confirm 输出 question 并读取一行回答：y / yes 返回 true，其它输入或输入结束返回 false
+func Confirm(in io.Reader, out io.Writer, question string) (bool, error) {
+	fmt.Fprintf(out, "%s [y/N] ", question)
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	if errors.Is(err, io.EOF) && line == "" {
+		fmt.Fprintln(out)
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,122 @@
+// file: internal/review/review.go
+// package: review
+//
+// 交互式审核（-review）：在复制或移除任何文件之前，逐组在终端列出成员（大小、码率、距离、路径），
+// 由用户确认保留文件、改选其它成员或把整组排除（不是重复）。只依赖标准库，按行读取输入，
+// 在任何终端与 SSH 会话中都能使用。审核结果由调用方保存（保留决定、误判清单），重新运行时沿用。
+package review
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"deduplicateMusic/internal/dedup"
+	"deduplicateMusic/internal/report"
+)
+
+// Action 为用户对一组的处理
+type Action int
+
+const (
+	Accept    Action = iota // 接受（Keep 非空时改为保留该文件）
+	Exclude                 // 不是重复：整组排除
+	AcceptAll               // 接受本组及其余所有组
+	Quit                    // 放弃本次运行
+)
+
+// Result 为一组的审核结果
+type Result struct {
+	Action Action
+	Keep   string // 用户改选的保留文件，未改选时为空
+}
+
+// Session 为一次审核
+type Session struct {
+	in    *bufio.Reader
+	out   io.Writer
+	total int // 待审核的组数
+	n     int // 已显示的组数
+}
+
+// New 返回从 in 读取、向 out 输出的审核会话，total 为待审核的组数
+func New(in io.Reader, out io.Writer, total int) *Session {
+	return &Session{in: bufio.NewReader(in), out: out, total: total}
+}
+
+// Members 返回组内全部成员：保留文件、受保护文件、重复文件，与显示的编号一致
+func Members(g dedup.Group) []dedup.FileMeta {
+	ms := append([]dedup.FileMeta{g.Keep}, g.Protected...)
+	for _, d := range g.Duplicates {
+		ms = append(ms, d.FileMeta)
+	}
+	return ms
+}
+
+// Group 显示一组并读取用户的选择；输入结束（EOF）时视为放弃
+func (s *Session) Group(g dedup.Group) (Result, error) {
+	s.n++
+	s.render(g)
+	members := Members(g)
+	for {
+		fmt.Fprintf(s.out, "[回车] 接受  [1-%d] 改为保留该文件  [x] 不是重复  [a] 接受其余全部  [q] 放弃 > ", len(members))
+		line, err := s.in.ReadString('\n')
+		if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+			if errors.Is(err, io.EOF) {
+				fmt.Fprintln(s.out)
+				return Result{Action: Quit}, nil
+			}
+			return Result{}, err
+		}
+		switch in := strings.ToLower(strings.TrimSpace(line)); in {
+		case "", "y":
+			return Result{Action: Accept}, nil
+		case "x", "n":
+			return Result{Action: Exclude}, nil
+		case "a":
+			return Result{Action: AcceptAll}, nil
+		case "q":
+			return Result{Action: Quit}, nil
+		default:
+			i, err := strconv.Atoi(in)
+			if err != nil || i < 1 || i > len(members) {
+				fmt.Fprintf(s.out, "无效的输入 %q\n", in)
+				continue
+			}
+			if i == 1 {
+				return Result{Action: Accept}, nil
+			}
+			if i <= 1+len(g.Protected) {
+				fmt.Fprintln(s.out, "受保护的文件总会保留，无需改选")
+				continue
+			}
+			return Result{Action: Accept, Keep: members[i-1].Path}, nil
+		}
+	}
+}
+
+// render 输出一组的成员表
+func (s *Session) render(g dedup.Group) {
+	var reclaim int64
+	for _, d := range g.Duplicates {
+		reclaim += d.Size
+	}
+	fmt.Fprintf(s.out, "\n组 %d（%d/%d）：%d 个重复，可回收 %s\n", g.ID, s.n, s.total, len(g.Duplicates), report.HumanBytes(reclaim))
+	row := func(i int, mark string, m dedup.FileMeta, note string) {
+		rate := "-"
+		if b := dedup.Bitrate(m); b > 0 {
+			rate = fmt.Sprintf("%.0f kbps", b)
+		}
+		fmt.Fprintf(s.out, "  %s%2d) %10s  %9s  %-8s %s\n", mark, i, report.HumanBytes(m.Size), rate, note, m.Path)
+	}
+	row(1, "*", g.Keep, "保留")
+	for i, m := range g.Protected {
+		row(2+i, " ", m, "受保护")
+	}
+	for i, d := range g.Duplicates {
+		row(2+len(g.Protected)+i, " ", d.FileMeta, fmt.Sprintf("距离 %d", d.Distance))
+	}
+}
@@ -0,0 +1,61 @@
+// file: internal/review/review_test.go
+// package: review
+//
+// 测试审核输入的解析：接受、改选保留文件、拒绝改选受保护文件、排除、无效输入重试与输入结束，
+// 以及破坏性操作前的确认。
+package review
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"deduplicateMusic/internal/dedup"
+)
+
+func TestSessionGroup(t *testing.T) {
+	g := dedup.Group{ID: 1,
+		Keep:       dedup.FileMeta{Path: "a.flac", Size: 30 << 20, Duration: 240},
+		Protected:  []dedup.FileMeta{{Path: "p.flac", Size: 20}},
+		Duplicates: []dedup.Member{{FileMeta: dedup.FileMeta{Path: "b.mp3", Size: 8 << 20}, Distance: 3}},
+	}
+	cases := []struct {
+		input string
+		want  Result
+	}{
+		{"\n", Result{Action: Accept}},
+		{"1\n", Result{Action: Accept}},
+		{"3\n", Result{Action: Accept, Keep: "b.mp3"}},
+		{"2\n9\nfoo\nx\n", Result{Action: Exclude}}, // 受保护与越界的编号都要求重新输入
+		{"A\n", Result{Action: AcceptAll}},
+		{"q\n", Result{Action: Quit}},
+		{"", Result{Action: Quit}},
+		{"3", Result{Action: Accept, Keep: "b.mp3"}}, // 最后一行没有换行
+	}
+	for _, c := range cases {
+		var out bytes.Buffer
+		got, err := New(strings.NewReader(c.input), &out, 1).Group(g)
+		if err != nil || got != c.want {
+			t.Fatalf("输入 %q: got %+v, %v", c.input, got, err)
+		}
+		if !strings.Contains(out.String(), "b.mp3") || !strings.Contains(out.String(), "距离 3") {
+			t.Fatalf("输出缺少成员信息:\n%s", out.String())
+		}
+	}
+	if ms := Members(g); len(ms) != 3 || ms[1].Path != "p.flac" || ms[2].Path != "b.mp3" {
+		t.Fatalf("Members = %+v", ms)
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	for input, want := range map[string]bool{"y\n": true, "YES\n": true, "y": true, "\n": false, "n\n": false, "": false} {
+		var out bytes.Buffer
+		got, err := Confirm(strings.NewReader(input), &out, "将删除 2 个重复文件，释放 10 MB，继续？")
+		if err != nil || got != want {
+			t.Fatalf("输入 %q: got %v, %v", input, got, err)
+		}
+		if !strings.Contains(out.String(), "[y/N]") {
+			t.Fatalf("缺少提示: %q", out.String())
+		}
+	}
+}
@@ -0,0 +1,425 @@
+// file: internal/rules/expr.go
+// package: rules
+//
+// 一个很小的表达式语言，用于按文件属性编写规则，例如：
+//
+//	bitrate > 256 && ext == "flac"
+//	path contains "/Masters/" || !(size < 1000000)
+//
+// 支持：字符串/数字/布尔字面量、属性名、比较运算 == != < <= > >=、
+// 字符串运算 contains / startswith / endswith、逻辑运算 && || ! 以及括号。
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Env 为求值环境：属性名 -> 值（string / float64 / bool）
+type Env map[string]any
+
+// Expr 为编译后的表达式
+type Expr struct {
+	src  string
+	root node
+}
+
+// String 返回原始表达式文本
+func (e *Expr) String() string { return e.src }
+
+// Eval 在 env 中求值，结果必须为布尔值
+func (e *Expr) Eval(env Env) (bool, error) {
+	v, err := e.root.eval(env)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("表达式 %q 的结果不是布尔值", e.src)
+	}
+	return b, nil
+}
+
+// Compile 解析表达式；known 非 nil 时校验引用的属性名都在其中
+func Compile(src string, known map[string]bool) (*Expr, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks, known: known}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tkEOF {
+		return nil, fmt.Errorf("表达式 %q 在 %q 处有多余内容", src, p.peek().text)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// ----------------- 词法分析 -----------------
+
+type tokKind int
+
+const (
+	tkEOF tokKind = iota
+	tkIdent
+	tkNumber
+	tkString
+	tkOp
+	tkLParen
+	tkRParen
+)
+
+type token struct {
+	kind tokKind
+	text string
+	num  float64
+}
+
+func lex(src string) ([]token, error) {
+	var toks []token
+	rs := []rune(src)
+	for i := 0; i < len(rs); {
+		c := rs[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(':
+			toks = append(toks, token{kind: tkLParen, text: "("})
+			i++
+		case c == ')':
+			toks = append(toks, token{kind: tkRParen, text: ")"})
+			i++
+		case c == '"' || c == '\'':
+			j := i + 1
+			var sb strings.Builder
+			for j < len(rs) && rs[j] != c {
+				if rs[j] == '\\' && j+1 < len(rs) {
+					j++
+				}
+				sb.WriteRune(rs[j])
+				j++
+			}
+			if j >= len(rs) {
+				return nil, fmt.Errorf("表达式 %q 中字符串未闭合", src)
+			}
+			toks = append(toks, token{kind: tkString, text: sb.String()})
+			i = j + 1
+		case unicode.IsDigit(c) || (c == '.' && i+1 < len(rs) && unicode.IsDigit(rs[i+1])):
+			j := i
+			for j < len(rs) && (unicode.IsDigit(rs[j]) || rs[j] == '.') {
+				j++
+			}
+			f, err := strconv.ParseFloat(string(rs[i:j]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("非法数字 %q", string(rs[i:j]))
+			}
+			toks = append(toks, token{kind: tkNumber, text: string(rs[i:j]), num: f})
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i
+			for j < len(rs) && (unicode.IsLetter(rs[j]) || unicode.IsDigit(rs[j]) || rs[j] == '_') {
+				j++
+			}
+			toks = append(toks, token{kind: tkIdent, text: string(rs[i:j])})
+			i = j
+		default:
+			// 运算符：优先匹配两个字符
+			if i+1 < len(rs) {
+				two := string(rs[i : i+2])
+				switch two {
+				case "==", "!=", "<=", ">=", "&&", "||":
+					toks = append(toks, token{kind: tkOp, text: two})
+					i += 2
+					continue
+				}
+			}
+			switch c {
+			case '<', '>', '!':
+				toks = append(toks, token{kind: tkOp, text: string(c)})
+				i++
+			default:
+				return nil, fmt.Errorf("表达式 %q 中有非法字符 %q", src, c)
+			}
+		}
+	}
+	return append(toks, token{kind: tkEOF}), nil
+}
+
+// ----------------- 语法分析 -----------------
+
+type parser struct {
+	toks  []token
+	pos   int
+	known map[string]bool
+}
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tkEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tkOp && p.peek().text == "||" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicNode{op: "||", l: left, r: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tkOp && p.peek().text == "&&" {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicNode{op: "&&", l: left, r: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if t := p.peek(); (t.kind == tkOp && t.text == "!") || (t.kind == tkIdent && strings.EqualFold(t.text, "not")) {
+		p.next()
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{x: x}, nil
+	}
+	return p.parseCmp()
+}
+
+var cmpOps = map[string]bool{
+	"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true,
+	"contains": true, "startswith": true, "endswith": true,
+}
+
+func (p *parser) parseCmp() (node, error) {
+	left, err := p.parseAtom()
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	op := t.text
+	if t.kind == tkIdent {
+		op = strings.ToLower(op)
+	}
+	if (t.kind == tkOp || t.kind == tkIdent) && cmpOps[op] {
+		p.next()
+		right, err := p.parseAtom()
+		if err != nil {
+			return nil, err
+		}
+		return &cmpNode{op: op, l: left, r: right}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseAtom() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tkLParen:
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != tkRParen {
+			return nil, fmt.Errorf("缺少右括号")
+		}
+		return x, nil
+	case tkNumber:
+		return &litNode{v: t.num}, nil
+	case tkString:
+		return &litNode{v: t.text}, nil
+	case tkIdent:
+		switch strings.ToLower(t.text) {
+		case "true":
+			return &litNode{v: true}, nil
+		case "false":
+			return &litNode{v: false}, nil
+		}
+		name := strings.ToLower(t.text)
+		if p.known != nil && !p.known[name] {
+			return nil, fmt.Errorf("未知属性: %s", t.text)
+		}
+		return &identNode{name: name}, nil
+	case tkEOF:
+		return nil, fmt.Errorf("表达式意外结束")
+	}
+	return nil, fmt.Errorf("意外的符号 %q", t.text)
+}
+
+// ----------------- 求值 -----------------
+
+type node interface {
+	eval(env Env) (any, error)
+}
+
+type litNode struct{ v any }
+
+func (n *litNode) eval(Env) (any, error) { return n.v, nil }
+
+type identNode struct{ name string }
+
+func (n *identNode) eval(env Env) (any, error) {
+	v, ok := env[n.name]
+	if !ok {
+		return nil, fmt.Errorf("未知属性: %s", n.name)
+	}
+	return v, nil
+}
+
+type notNode struct{ x node }
+
+func (n *notNode) eval(env Env) (any, error) {
+	v, err := n.x.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return nil, fmt.Errorf("! 只能作用于布尔值")
+	}
+	return !b, nil
+}
+
+type logicNode struct {
+	op   string
+	l, r node
+}
+
+func (n *logicNode) eval(env Env) (any, error) {
+	lv, err := n.l.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	lb, ok := lv.(bool)
+	if !ok {
+		return nil, fmt.Errorf("%s 的左侧不是布尔值", n.op)
+	}
+	// 短路求值
+	if n.op == "&&" && !lb {
+		return false, nil
+	}
+	if n.op == "||" && lb {
+		return true, nil
+	}
+	rv, err := n.r.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	rb, ok := rv.(bool)
+	if !ok {
+		return nil, fmt.Errorf("%s 的右侧不是布尔值", n.op)
+	}
+	return rb, nil
+}
+
+type cmpNode struct {
+	op   string
+	l, r node
+}
+
+func (n *cmpNode) eval(env Env) (any, error) {
+	lv, err := n.l.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	rv, err := n.r.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch l := lv.(type) {
+	case float64:
+		r, ok := toFloat(rv)
+		if !ok {
+			return nil, fmt.Errorf("无法比较数字与 %v", rv)
+		}
+		switch n.op {
+		case "==":
+			return l == r, nil
+		case "!=":
+			return l != r, nil
+		case "<":
+			return l < r, nil
+		case "<=":
+			return l <= r, nil
+		case ">":
+			return l > r, nil
+		case ">=":
+			return l >= r, nil
+		}
+	case string:
+		r, ok := rv.(string)
+		if !ok {
+			return nil, fmt.Errorf("无法比较字符串与 %v", rv)
+		}
+		// 字符串比较大小写不敏感，便于写 ext == "FLAC" 之类的规则
+		ll, rl := strings.ToLower(l), strings.ToLower(r)
+		switch n.op {
+		case "==":
+			return ll == rl, nil
+		case "!=":
+			return ll != rl, nil
+		case "<":
+			return ll < rl, nil
+		case "<=":
+			return ll <= rl, nil
+		case ">":
+			return ll > rl, nil
+		case ">=":
+			return ll >= rl, nil
+		case "contains":
+			return strings.Contains(ll, rl), nil
+		case "startswith":
+			return strings.HasPrefix(ll, rl), nil
+		case "endswith":
+			return strings.HasSuffix(ll, rl), nil
+		}
+	case bool:
+		r, ok := rv.(bool)
+		if !ok {
+			return nil, fmt.Errorf("无法比较布尔值与 %v", rv)
+		}
+		switch n.op {
+		case "==":
+			return l == r, nil
+		case "!=":
+			return l != r, nil
+		}
+	}
+	return nil, fmt.Errorf("运算符 %s 不支持操作数 %v", n.op, lv)
+}
+
+func toFloat(v any) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case int:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	}
+	return 0, false
+}
@@ -0,0 +1,153 @@
+// file: internal/rules/rules.go
+// package: rules
+//
+// 规则文件：每行一条 "类型: 表达式"，# 开头为注释。支持的类型：
+//   - prefer:  满足的规则越多，越优先被保留（优先于 -keep-policy）
+//   - protect: 满足的文件永远不会被当作重复丢弃
+//
+// 示例：
+//
+//	prefer: ext == "flac"
+//	protect: path contains "/Masters/"
+//	prefer: track_id == "1-01"
+//
+// disc / track / track_id 为规范化后的碟号与曲号（见 tags.ParseTrackID），多碟专辑的不同编号写法会得到相同的值。
+// artist / album / title 为原始标签；title_key 去掉了 "(feat. X)"、"[Explicit]" 等后缀（见 textnorm.TitleKey）。
+package rules
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"deduplicateMusic/internal/dedup"
+	"deduplicateMusic/internal/tags"
+	"deduplicateMusic/internal/textnorm"
+)
+
+// knownFields 为规则中可引用的属性名
+var knownFields = map[string]bool{
+	"path": true, "name": true, "ext": true, "dir": true,
+	"size": true, "size_mb": true,
+	"disc": true, "track": true, "track_id": true,
+	"artist": true, "album": true, "title": true, "title_key": true,
+}
+
+// FieldsFor 把 FileMeta 转为求值环境
+func FieldsFor(m dedup.FileMeta) Env {
+	id := tags.ParseTrackID(m.Tags.Track, m.Tags.Disc, m.Path)
+	return Env{
+		"disc":      float64(id.Disc),
+		"track":     float64(id.Track),
+		"track_id":  id.String(),
+		"artist":    m.Tags.TrackArtist(),
+		"album":     m.Tags.Album,
+		"title":     m.Tags.Title,
+		"title_key": textnorm.TitleKey(m.Tags.Title),
+		"path":      filepath.ToSlash(m.Path),
+		"name":      filepath.Base(m.Path),
+		"ext":       strings.TrimPrefix(strings.ToLower(filepath.Ext(m.Path)), "."),
+		"dir":       filepath.ToSlash(filepath.Dir(m.Path)),
+		"size":      float64(m.Size),
+		"size_mb":   float64(m.Size) / (1 << 20),
+	}
+}
+
+// Set 为一组已编译的规则
+type Set struct {
+	Prefer  []*Expr
+	Protect []*Expr
+}
+
+// Empty 返回是否没有任何规则
+func (s *Set) Empty() bool { return s == nil || (len(s.Prefer) == 0 && len(s.Protect) == 0) }
+
+// Load 从文件读取规则
+func Load(path string) (*Set, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	set := &Set{}
+	sc := bufio.NewScanner(f)
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kind, src, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: 缺少 \"类型:\" 前缀", path, lineNo)
+		}
+		if err := set.Add(strings.TrimSpace(kind), strings.TrimSpace(src)); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNo, err)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// Add 编译并加入一条规则，kind 为 prefer 或 protect
+func (s *Set) Add(kind, src string) error {
+	e, err := Compile(src, knownFields)
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(kind) {
+	case "prefer":
+		s.Prefer = append(s.Prefer, e)
+	case "protect":
+		s.Protect = append(s.Protect, e)
+	default:
+		return fmt.Errorf("未知规则类型: %s（应为 prefer 或 protect）", kind)
+	}
+	return nil
+}
+
+// score 返回文件满足的 prefer 规则数量（求值出错的规则视为不满足）
+func (s *Set) score(m dedup.FileMeta) int {
+	env := FieldsFor(m)
+	n := 0
+	for _, e := range s.Prefer {
+		if ok, err := e.Eval(env); err == nil && ok {
+			n++
+		}
+	}
+	return n
+}
+
+// Protected 返回文件是否满足任一 protect 规则
+func (s *Set) Protected(m dedup.FileMeta) bool {
+	if s == nil {
+		return false
+	}
+	env := FieldsFor(m)
+	for _, e := range s.Protect {
+		if ok, err := e.Eval(env); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// PreferPolicy 包装 base：先比较满足的 prefer 规则数量（多者优先），相同时交给 base。
+func (s *Set) PreferPolicy(base dedup.KeepPolicy) dedup.KeepPolicy {
+	if s == nil || len(s.Prefer) == 0 {
+		return base
+	}
+	return dedup.KeepPolicyFunc(func(a, b dedup.FileMeta) bool {
+		sa, sb := s.score(a), s.score(b)
+		if sa != sb {
+			return sa > sb
+		}
+		return base.Better(a, b)
+	})
+}
@@ -0,0 +1,74 @@
+// file: internal/rules/rules_test.go
+// package: rules
+//
+// 测试表达式求值与 prefer/protect 规则的效果。
+package rules
+
+import (
+	"testing"
+
+	"deduplicateMusic/internal/dedup"
+)
+
+func TestExprEval(t *testing.T) {
+	env := FieldsFor(dedup.FileMeta{Path: "/music/Masters/a.FLAC", Size: 3 << 20})
+	cases := map[string]bool{
+		`ext == "flac"`:                           true,
+		`ext == "flac" && size_mb > 2`:            true,
+		`path contains "/masters/" || size < 10`:  true,
+		`!(name startswith "a") || ext == "mp3"`:  false,
+		`(size >= 1) && not (dir endswith "tmp")`: true,
+	}
+	for src, want := range cases {
+		e, err := Compile(src, knownFields)
+		if err != nil {
+			t.Fatalf("编译 %q 失败: %v", src, err)
+		}
+		got, err := e.Eval(env)
+		if err != nil || got != want {
+			t.Fatalf("%q: 期望 %v，实际 %v (err=%v)", src, want, got, err)
+		}
+	}
+	for _, bad := range []string{`bogus > 1`, `size >`, `"abc`, `(size > 1`} {
+		if _, err := Compile(bad, knownFields); err == nil {
+			t.Fatalf("非法表达式 %q 应报错", bad)
+		}
+	}
+}
+
+func TestPreferAndProtect(t *testing.T) {
+	set := &Set{}
+	if err := set.Add("prefer", `ext == "flac"`); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Add("protect", `path contains "/Masters/"`); err != nil {
+		t.Fatal(err)
+	}
+	files := []dedup.FileMeta{
+		{Path: "/lib/a.mp3", Size: 9000, FP: 1},
+		{Path: "/lib/a.flac", Size: 5000, FP: 1},
+		{Path: "/Masters/a.wav", Size: 100, FP: 1},
+		{Path: "/Masters/b.wav", Size: 50, FP: 1},
+	}
+	groups := dedup.GroupWith(files, dedup.Options{
+		Policy:  set.PreferPolicy(dedup.DefaultPolicy()),
+		Protect: set.Protected,
+	})
+	if len(groups) != 1 {
+		t.Fatalf("期望 1 个组，实际 %d", len(groups))
+	}
+	g := groups[0]
+	// 受保护文件优先成为保留文件，其余受保护文件也一并保留
+	if g.Keep.Path != "/Masters/a.wav" || len(g.Protected) != 1 || g.Protected[0].Path != "/Masters/b.wav" {
+		t.Fatalf("受保护文件应全部保留，实际 keep=%s protected=%#v", g.Keep.Path, g.Protected)
+	}
+	if len(g.Duplicates) != 2 {
+		t.Fatalf("期望 2 个重复成员，实际 %#v", g.Duplicates)
+	}
+
+	// 没有受保护文件时，prefer 使 flac 胜过更大的 mp3
+	groups = dedup.GroupWith(files[:2], dedup.Options{Policy: set.PreferPolicy(dedup.DefaultPolicy())})
+	if groups[0].Keep.Path != "/lib/a.flac" {
+		t.Fatalf("prefer 规则未生效，保留了 %s", groups[0].Keep.Path)
+	}
+}
@@ -1,44 +1,117 @@
 // file: internal/scanner/scanner.go
 // package: scanner
 //
-// 提供目录扫描功能，按扩展名筛选音频文件。
+// 提供目录扫描功能，按扩展名筛选音频文件；无法访问的路径（如没有权限的目录）被跳过并记录下来。
 package scanner
 
 import (
+	"context"
+	"errors"
 	"io/fs"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
+// Skip 为扫描时无法访问而跳过的路径（目录被跳过时其下的文件都不会出现在结果中）
+type Skip struct {
+	Path string
+	Err  error
+}
+
+// Permission 返回是否因权限不足而跳过
+func (s Skip) Permission() bool { return errors.Is(s.Err, fs.ErrPermission) }
+
 // ScanDir 扫描 root 目录，返回匹配 exts 中扩展名（小写）的文件路径列表。
 // exts 样例：[]string{".mp3", ".wav"}
 func ScanDir(root string, exts []string) ([]string, error) {
+	files, _, err := Scan(root, exts)
+	return files, err
+}
+
+// Scan 与 ScanDir 相同，另外返回无法访问而跳过的路径
+func Scan(root string, exts []string) ([]string, []Skip, error) {
+	entries, skipped, err := ScanEntries(root, exts)
+	if err != nil {
+		return nil, skipped, err
+	}
+	files := make([]string, len(entries))
+	for i, e := range entries {
+		files[i] = e.Path
+	}
+	return files, skipped, nil
+}
+
+// Entry 为扫描到的文件及遍历时取得的元数据，后续阶段无需再逐个 stat（网络文件系统上代价很高）
+type Entry struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+	Mode    fs.FileMode // 符号链接已解析为目标文件的模式
+	Dir     fs.DirEntry // 遍历时的目录项（符号链接时为链接本身）
+	Info    fs.FileInfo // 文件元数据（符号链接已解析），可用 os.SameFile 判断是否为同一物理文件
+}
+
+// ScanEntries 与 Scan 相同，但返回带大小、修改时间等元数据的条目。
+// 元数据来自遍历时的目录项，只有符号链接需要额外 stat 以取得目标文件的信息；无法取得元数据的文件记入跳过列表。
+func ScanEntries(root string, exts []string) ([]Entry, []Skip, error) {
+	return ScanEntriesContext(context.Background(), root, exts)
+}
+
+// ScanEntriesContext 同 ScanEntries；ctx 取消后停止遍历并返回 ctx.Err()
+func ScanEntriesContext(ctx context.Context, root string, exts []string) ([]Entry, []Skip, error) {
 	if len(exts) == 0 {
-		return nil, nil
+		return nil, nil, nil
 	}
 	extMap := make(map[string]struct{}, len(exts))
 	for _, e := range exts {
 		extMap[strings.ToLower(e)] = struct{}{}
 	}
 
-	var files []string
+	var entries []Entry
+	var skipped []Skip
 	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if cerr := ctx.Err(); cerr != nil {
+			return cerr
+		}
 		if err != nil {
-			// 如果单路径访问错误，继续其他路径
+			if path == root {
+				return err // 根目录本身无法访问
+			}
+			// 如果单路径访问错误，记录后继续其他路径
+			skipped = append(skipped, Skip{Path: path, Err: err})
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
 			return nil
 		}
 		if d.IsDir() {
 			return nil
 		}
 		ext := strings.ToLower(filepath.Ext(path))
-		if _, ok := extMap[ext]; ok {
-			// 确认为常见音频扩展
-			files = append(files, path)
+		if _, ok := extMap[ext]; !ok {
+			return nil
+		}
+		// 确认为常见音频扩展
+		var fi fs.FileInfo
+		if d.Type()&fs.ModeSymlink != 0 {
+			fi, err = os.Stat(path)
+		} else {
+			fi, err = d.Info()
+		}
+		if err != nil {
+			skipped = append(skipped, Skip{Path: path, Err: err})
+			return nil
+		}
+		if fi.IsDir() {
+			return nil // 指向目录的符号链接
 		}
+		entries = append(entries, Entry{Path: path, Size: fi.Size(), ModTime: fi.ModTime(), Mode: fi.Mode(), Dir: d, Info: fi})
 		return nil
 	})
 	if err != nil {
-		return nil, err
+		return nil, skipped, err
 	}
-	return files, nil
+	return entries, skipped, nil
 }
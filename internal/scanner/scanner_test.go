@@ -1,13 +1,17 @@
 // file: internal/scanner/scanner_test.go
 // package: scanner
 //
-// 测试 ScanDir：创建临时目录并生成带不同扩展名的文件，确保只返回预期扩展的文件。
+// 测试 ScanDir：创建临时目录并生成带不同扩展名的文件，确保只返回预期扩展的文件；
+// 以及 ScanEntries 在遍历时带回的大小与修改时间。
 package scanner
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestScanDirBasic(t *testing.T) {
@@ -29,3 +33,72 @@ func TestScanDirBasic(t *testing.T) {
 		t.Fatalf("期望 3 个文件，实际 %d: %#v", len(found), found)
 	}
 }
+
+func TestScanRecordsUnreadableDirs(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root 不受目录权限限制")
+	}
+	td := t.TempDir()
+	locked := filepath.Join(td, "locked")
+	if err := os.MkdirAll(locked, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{filepath.Join(td, "a.mp3"), filepath.Join(locked, "b.mp3")} {
+		if err := os.WriteFile(p, []byte("dummy"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chmod(locked, 0); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(locked, 0o755) })
+
+	files, skipped, err := Scan(td, []string{".mp3"})
+	if err != nil {
+		t.Fatalf("Scan 错误: %v", err)
+	}
+	if len(files) != 1 || len(skipped) != 1 || skipped[0].Path != locked || !skipped[0].Permission() {
+		t.Fatalf("files=%v skipped=%+v", files, skipped)
+	}
+}
+
+func TestScanEntriesCarryMetadata(t *testing.T) {
+	td := t.TempDir()
+	p := filepath.Join(td, "a.mp3")
+	if err := os.WriteFile(p, []byte("12345"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(p, mt, mt); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(p, filepath.Join(td, "link.mp3")); err != nil {
+		t.Skipf("无法创建符号链接: %v", err)
+	}
+	entries, skipped, err := ScanEntries(td, []string{".mp3"})
+	if err != nil || len(skipped) != 0 {
+		t.Fatalf("ScanEntries: %v, skipped=%v", err, skipped)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("期望 2 个条目，实际 %d", len(entries))
+	}
+	for _, e := range entries {
+		// 符号链接应报告目标文件的大小与修改时间
+		if e.Size != 5 || !e.ModTime.Equal(mt) || !e.Mode.IsRegular() || e.Dir == nil {
+			t.Errorf("%s: 元数据不符 %+v", e.Path, e)
+		}
+	}
+}
+
+func TestScanEntriesContextCanceled(t *testing.T) {
+	td := t.TempDir()
+	if err := os.WriteFile(filepath.Join(td, "a.mp3"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	entries, _, err := ScanEntriesContext(ctx, td, []string{".mp3"})
+	if !errors.Is(err, context.Canceled) || len(entries) != 0 {
+		t.Fatalf("期望 context.Canceled 且无条目，实际 %v, %d 个条目", err, len(entries))
+	}
+}
@@ -0,0 +1,226 @@
+// file: internal/service/service.go
+// package: service
+//
+// 生成定时运行的服务单元：Linux 为 systemd 用户单元（.service + .timer），macOS 为 launchd
+// LaunchAgent，Windows 为任务计划程序 XML。单元以安装时的可执行文件、参数与工作目录
+// （报告写在工作目录）每天定时运行一次去重。
+package service
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Config 描述要安装的定时任务
+type Config struct {
+	Name    string   // 单元 / 任务名
+	Exe     string   // 可执行文件绝对路径
+	Args    []string // 运行参数（不含可执行文件）
+	WorkDir string   // 工作目录，报告写在这里
+	Hour    int      // 每天运行的时刻
+	Minute  int
+}
+
+// File 为要写出的单元文件
+type File struct {
+	Path    string
+	Content string
+}
+
+// Targets 为支持的目标系统（与 runtime.GOOS 同名）
+var Targets = []string{"linux", "darwin", "windows"}
+
+// ParseSchedule 解析 "HH:MM" 形式的每日运行时刻
+func ParseSchedule(s string) (hour, minute int, err error) {
+	if _, err := fmt.Sscanf(s, "%d:%d", &hour, &minute); err != nil || hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return 0, 0, fmt.Errorf("无效的运行时刻 %q（格式 HH:MM，如 03:30）", s)
+	}
+	return hour, minute, nil
+}
+
+// Generate 返回 target 系统上要写出的文件以及启用任务的命令。
+// dir 非空时文件写到 dir，否则写到该系统的默认位置（home 下的用户单元目录；Windows 为工作目录）。
+func Generate(target string, c Config, home, dir string) ([]File, []string, error) {
+	switch target {
+	case "linux":
+		if dir == "" {
+			dir = filepath.Join(home, ".config", "systemd", "user")
+		}
+		svc, timer := Systemd(c)
+		return []File{{filepath.Join(dir, c.Name+".service"), svc}, {filepath.Join(dir, c.Name+".timer"), timer}},
+			[]string{"systemctl --user daemon-reload", "systemctl --user enable --now " + c.Name + ".timer"}, nil
+	case "darwin":
+		if dir == "" {
+			dir = filepath.Join(home, "Library", "LaunchAgents")
+		}
+		p := filepath.Join(dir, launchdLabel(c.Name)+".plist")
+		return []File{{p, Launchd(c)}}, []string{"launchctl load -w " + shellQuote(p)}, nil
+	case "windows":
+		if dir == "" {
+			dir = c.WorkDir
+		}
+		p := filepath.Join(dir, c.Name+".xml")
+		return []File{{p, WindowsTask(c)}}, []string{fmt.Sprintf(`schtasks /Create /TN %s /XML "%s"`, windowsQuote(c.Name), p)}, nil
+	}
+	return nil, nil, fmt.Errorf("不支持的目标系统 %q（可选 %s）", target, strings.Join(Targets, "、"))
+}
+
+// Systemd 返回 systemd 用户单元（service 与 timer）
+func Systemd(c Config) (service, timer string) {
+	words := []string{systemdQuote(c.Exe)}
+	for _, a := range c.Args {
+		words = append(words, systemdQuote(a))
+	}
+	service = fmt.Sprintf(`[Unit]
+Description=audio-dedup 定时去重（%[1]s）
+
+[Service]
+Type=oneshot
+WorkingDirectory=%[2]s
+ExecStart=%[3]s
+Nice=10
+IOSchedulingClass=idle
+# 因 -max-runtime / -stage-budget 提前停止时退出码为 3，不视为失败
+SuccessExitStatus=3
+`, c.Name, strings.ReplaceAll(c.WorkDir, "%", "%%"), strings.Join(words, " ")) // WorkingDirectory 不接受引号
+	timer = fmt.Sprintf(`[Unit]
+Description=每天 %02[2]d:%02[3]d 运行 %[1]s
+
+[Timer]
+OnCalendar=*-*-* %02[2]d:%02[3]d:00
+Persistent=true
+
+[Install]
+WantedBy=timers.target
+`, c.Name, c.Hour, c.Minute)
+	return service, timer
+}
+
+// systemdQuote 按 systemd 的命令行规则加引号；% 需写成 %%
+func systemdQuote(s string) string {
+	s = strings.ReplaceAll(s, "%", "%%")
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\;") {
+		return s
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + r.Replace(s) + `"`
+}
+
+func launchdLabel(name string) string { return "local." + name }
+
+// Launchd 返回 launchd LaunchAgent plist
+func Launchd(c Config) string {
+	var b strings.Builder
+	esc := func(s string) string {
+		var e strings.Builder
+		_ = xml.EscapeText(&e, []byte(s))
+		return e.String()
+	}
+	fmt.Fprintf(&b, `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>%s</string>
+	<key>ProgramArguments</key>
+	<array>
+`, esc(launchdLabel(c.Name)))
+	for _, a := range append([]string{c.Exe}, c.Args...) {
+		fmt.Fprintf(&b, "\t\t<string>%s</string>\n", esc(a))
+	}
+	fmt.Fprintf(&b, `	</array>
+	<key>WorkingDirectory</key>
+	<string>%s</string>
+	<key>StartCalendarInterval</key>
+	<dict>
+		<key>Hour</key>
+		<integer>%d</integer>
+		<key>Minute</key>
+		<integer>%d</integer>
+	</dict>
+	<key>LowPriorityIO</key>
+	<true/>
+	<key>StandardOutPath</key>
+	<string>%s</string>
+	<key>StandardErrorPath</key>
+	<string>%s</string>
+</dict>
+</plist>
+`, esc(c.WorkDir), c.Hour, c.Minute, esc(filepath.Join(c.WorkDir, c.Name+".log")), esc(filepath.Join(c.WorkDir, c.Name+".log")))
+	return b.String()
+}
+
+// WindowsTask 返回任务计划程序的任务 XML（schtasks /Create /XML 导入）
+func WindowsTask(c Config) string {
+	args := make([]string, len(c.Args))
+	for i, a := range c.Args {
+		args[i] = windowsQuote(a)
+	}
+	esc := func(s string) string {
+		var e strings.Builder
+		_ = xml.EscapeText(&e, []byte(s))
+		return e.String()
+	}
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<Task version="1.2" xmlns="http://schemas.microsoft.com/windows/2004/02/mit/task">
+  <RegistrationInfo>
+    <Description>audio-dedup 定时去重（%s）</Description>
+  </RegistrationInfo>
+  <Triggers>
+    <CalendarTrigger>
+      <StartBoundary>2000-01-01T%02d:%02d:00</StartBoundary>
+      <ScheduleByDay>
+        <DaysInterval>1</DaysInterval>
+      </ScheduleByDay>
+    </CalendarTrigger>
+  </Triggers>
+  <Settings>
+    <MultipleInstancesPolicy>IgnoreNew</MultipleInstancesPolicy>
+    <StartWhenAvailable>true</StartWhenAvailable>
+    <Priority>7</Priority>
+  </Settings>
+  <Actions>
+    <Exec>
+      <Command>%s</Command>
+      <Arguments>%s</Arguments>
+      <WorkingDirectory>%s</WorkingDirectory>
+    </Exec>
+  </Actions>
+</Task>
+`, esc(c.Name), c.Hour, c.Minute, esc(c.Exe), esc(strings.Join(args, " ")), esc(c.WorkDir))
+}
+
+// windowsQuote 按 Windows 命令行规则加引号（与 syscall.EscapeArg 相同）
+func windowsQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"") {
+		return s
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	slashes := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			slashes++
+		case '"':
+			b.WriteString(strings.Repeat(`\`, slashes+1))
+			slashes = 0
+		default:
+			slashes = 0
+		}
+		b.WriteByte(s[i])
+	}
+	b.WriteString(strings.Repeat(`\`, slashes))
+	b.WriteByte('"')
+	return b.String()
+}
+
+// shellQuote 为 POSIX shell 加单引号
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t'\"\\$`;&|<>()*?!#~") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
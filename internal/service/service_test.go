@@ -0,0 +1,69 @@
+// file: internal/service/service_test.go
+// package: service
+//
+// 测试运行时刻解析、各系统单元的生成与参数转义。
+package service
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testConfig() Config {
+	return Config{Name: "audio-dedup", Exe: "/usr/local/bin/audio-dedup",
+		Args: []string{"-src", "/mnt/My Music", "-dst", "/out", "-report-columns", "FilePath,50%"}, WorkDir: "/var/lib/ad", Hour: 3, Minute: 5}
+}
+
+func TestParseSchedule(t *testing.T) {
+	if h, m, err := ParseSchedule("03:30"); err != nil || h != 3 || m != 30 {
+		t.Fatalf("ParseSchedule = %d:%d, %v", h, m, err)
+	}
+	for _, bad := range []string{"24:00", "3", "ab:cd", "12:60"} {
+		if _, _, err := ParseSchedule(bad); err == nil {
+			t.Fatalf("%q 应报错", bad)
+		}
+	}
+}
+
+func TestSystemd(t *testing.T) {
+	svc, timer := Systemd(testConfig())
+	if !strings.Contains(svc, `ExecStart=/usr/local/bin/audio-dedup -src "/mnt/My Music" -dst /out -report-columns FilePath,50%%`) {
+		t.Fatalf("ExecStart 转义不正确:\n%s", svc)
+	}
+	if !strings.Contains(timer, "OnCalendar=*-*-* 03:05:00") {
+		t.Fatalf("timer 不正确:\n%s", timer)
+	}
+}
+
+func TestLaunchdAndWindows(t *testing.T) {
+	c := testConfig()
+	c.Args = append(c.Args, "-rules", `a<b & "c"`)
+	if p := Launchd(c); !strings.Contains(p, "<string>a&lt;b &amp; &#34;c&#34;</string>") || !strings.Contains(p, "<integer>5</integer>") {
+		t.Fatalf("plist 不正确:\n%s", p)
+	}
+	if x := WindowsTask(c); !strings.Contains(x, "<StartBoundary>2000-01-01T03:05:00</StartBoundary>") {
+		t.Fatalf("任务 XML 不正确:\n%s", x)
+	}
+	for in, want := range map[string]string{`C:\Music`: `C:\Music`, `C:\My Music\`: `"C:\My Music\\"`, `say "hi"`: `"say \"hi\""`, "": `""`} {
+		if got := windowsQuote(in); got != want {
+			t.Fatalf("windowsQuote(%q) = %s，期望 %s", in, got, want)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	files, cmds, err := Generate("linux", testConfig(), "/home/u", "")
+	if err != nil || len(files) != 2 || files[1].Path != filepath.Join("/home/u", ".config/systemd/user/audio-dedup.timer") {
+		t.Fatalf("Generate(linux) = %v, %v", files, err)
+	}
+	if len(cmds) != 2 || !strings.HasSuffix(cmds[1], "audio-dedup.timer") {
+		t.Fatalf("启用命令不正确: %v", cmds)
+	}
+	if files, _, _ := Generate("darwin", testConfig(), "/Users/u", ""); files[0].Path != "/Users/u/Library/LaunchAgents/local.audio-dedup.plist" {
+		t.Fatalf("plist 路径不正确: %s", files[0].Path)
+	}
+	if _, _, err := Generate("plan9", testConfig(), "", ""); err == nil {
+		t.Fatal("未知系统应报错")
+	}
+}
@@ -0,0 +1,48 @@
+// file: internal/sidecar/lyrics.go
+// package: sidecar
+//
+// 歌词合并：保留文件没有歌词（既无同名 .lrc 也无内嵌歌词）而被丢弃的重复文件有时，
+// 把歌词写成保留文件副本旁的 .lrc。只写伴随文件，不改写音频文件本身的标签。
+package sidecar
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"deduplicateMusic/internal/tags"
+)
+
+// Lyrics 返回 audio 的歌词：优先同名 .lrc（带时间轴），其次内嵌歌词；都没有时返回空串
+func Lyrics(audio string) (string, error) {
+	stem := strings.TrimSuffix(audio, filepath.Ext(audio))
+	for _, ext := range []string{".lrc", ".LRC"} {
+		if b, err := os.ReadFile(stem + ext); err == nil && strings.TrimSpace(string(b)) != "" {
+			return string(b), nil
+		}
+	}
+	return tags.ReadLyrics(audio)
+}
+
+// MergeLyrics 在 dstAudio 旁写出歌词：keeper 没有歌词时依次尝试 donors，写入第一个找到的。
+// 返回歌词来源文件，没有写入时为空串。dstAudio 旁已有 .lrc 时不覆盖。
+func MergeLyrics(keeper, dstAudio string, donors []string) (string, error) {
+	if text, err := Lyrics(keeper); err == nil && text != "" {
+		return "", nil
+	}
+	target := strings.TrimSuffix(dstAudio, filepath.Ext(dstAudio)) + ".lrc"
+	if _, err := os.Stat(target); err == nil {
+		return "", nil
+	}
+	for _, d := range donors {
+		text, err := Lyrics(d)
+		if err != nil || text == "" {
+			continue
+		}
+		if err := os.WriteFile(target, []byte(text), 0o644); err != nil {
+			return "", err
+		}
+		return d, nil
+	}
+	return "", nil
+}
@@ -0,0 +1,89 @@
+// file: internal/sidecar/sidecar.go
+// package: sidecar
+//
+// 伴随文件发现：音频文件旁边的歌词、CUE、抓轨日志与封面图。
+//   - 专属伴随文件：与音频同名（扩展名不同）的 .lrc / .cue / .log / 图片，只属于这一个文件，
+//     复制保留文件时随之改名复制，删除重复文件时应一起删除；
+//   - 共享伴随文件：目录级的封面（cover / folder / front 等图片）以及不与任何音频同名的 .cue / .log，
+//     属于整张专辑，只随保留文件复制（目标已存在时不覆盖），不随单个重复文件删除。
+package sidecar
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// OwnExts 为专属伴随文件的扩展名（小写）
+var OwnExts = []string{".lrc", ".cue", ".log", ".jpg", ".jpeg", ".png"}
+
+// CoverNames 为目录级封面图的文件名（不含扩展名，小写）
+var CoverNames = []string{"cover", "folder", "front", "albumart", "album"}
+
+var imageExts = map[string]bool{".jpg": true, ".jpeg": true, ".png": true}
+
+// Set 为一个音频文件的伴随文件
+type Set struct {
+	Own    []string
+	Shared []string
+}
+
+// Find 查找 audio 的伴随文件；audioExts 为音频扩展名（小写，含点），用于判断 .cue / .log 是否属于某个音频文件
+func Find(audio string, audioExts []string) (Set, error) {
+	dir := filepath.Dir(audio)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return Set{}, err
+	}
+	isAudio := map[string]bool{}
+	for _, e := range audioExts {
+		isAudio[e] = true
+	}
+	stems := map[string]bool{} // 目录中所有音频文件的文件名主干（小写）
+	for _, e := range entries {
+		if ext := strings.ToLower(filepath.Ext(e.Name())); !e.IsDir() && isAudio[ext] {
+			stems[strings.ToLower(strings.TrimSuffix(e.Name(), filepath.Ext(e.Name())))] = true
+		}
+	}
+	stem := strings.ToLower(strings.TrimSuffix(filepath.Base(audio), filepath.Ext(audio)))
+
+	var s Set
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		name := e.Name()
+		ext := strings.ToLower(filepath.Ext(name))
+		base := strings.ToLower(strings.TrimSuffix(name, filepath.Ext(name)))
+		p := filepath.Join(dir, name)
+		switch {
+		case isAudio[ext]:
+		case base == stem && contains(OwnExts, ext):
+			s.Own = append(s.Own, p)
+		case imageExts[ext] && contains(CoverNames, base):
+			s.Shared = append(s.Shared, p)
+		case (ext == ".cue" || ext == ".log") && !stems[base]:
+			s.Shared = append(s.Shared, p)
+		}
+	}
+	return s, nil
+}
+
+// Target 返回伴随文件复制到 dstAudio 旁边时的路径：专属文件随音频改名，共享文件保持原名
+func Target(sidecar, dstAudio string, own bool) string {
+	dir := filepath.Dir(dstAudio)
+	if !own {
+		return filepath.Join(dir, filepath.Base(sidecar))
+	}
+	stem := strings.TrimSuffix(filepath.Base(dstAudio), filepath.Ext(dstAudio))
+	return filepath.Join(dir, stem+filepath.Ext(sidecar))
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
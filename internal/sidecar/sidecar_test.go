@@ -0,0 +1,76 @@
+// file: internal/sidecar/sidecar_test.go
+// package: sidecar
+//
+// 测试伴随文件的分类：同名歌词/CUE 为专属，封面与专辑级日志为共享，其它音频的同名文件不算在内。
+package sidecar
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"01 Intro.flac", "01 Intro.lrc", "01 Intro.cue",
+		"02 Song.flac", "02 Song.lrc",
+		"Cover.jpg", "Album (EAC).log", "notes.txt",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s, err := Find(filepath.Join(dir, "01 Intro.flac"), []string{".flac", ".mp3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := func(paths []string) []string {
+		var out []string
+		for _, p := range paths {
+			out = append(out, filepath.Base(p))
+		}
+		sort.Strings(out)
+		return out
+	}
+	if got := names(s.Own); len(got) != 2 || got[0] != "01 Intro.cue" || got[1] != "01 Intro.lrc" {
+		t.Errorf("专属伴随文件 = %v", got)
+	}
+	if got := names(s.Shared); len(got) != 2 || got[0] != "Album (EAC).log" || got[1] != "Cover.jpg" {
+		t.Errorf("共享伴随文件 = %v", got)
+	}
+
+	if got := Target(filepath.Join(dir, "01 Intro.lrc"), "/dst/Intro.mp3", true); got != filepath.Join("/dst", "Intro.lrc") {
+		t.Errorf("专属文件目标 = %s", got)
+	}
+	if got := Target(filepath.Join(dir, "Cover.jpg"), "/dst/Intro.mp3", false); got != filepath.Join("/dst", "Cover.jpg") {
+		t.Errorf("共享文件目标 = %s", got)
+	}
+}
+
+func TestMergeLyrics(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	keep := filepath.Join(src, "keep.flac")
+	dup := filepath.Join(src, "dup.mp3")
+	for _, p := range []string{keep, dup} {
+		if err := os.WriteFile(p, make([]byte, 64), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(src, "dup.lrc"), []byte("[00:01.00]歌词"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dst, "keep.flac")
+	from, err := MergeLyrics(keep, out, []string{dup})
+	if err != nil || from != dup {
+		t.Fatalf("MergeLyrics = %q, %v", from, err)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dst, "keep.lrc")); string(b) != "[00:01.00]歌词" {
+		t.Fatalf("写出的歌词 = %q", b)
+	}
+	// 保留文件自己有歌词时不合并
+	if from, _ := MergeLyrics(dup, filepath.Join(dst, "other.mp3"), []string{keep}); from != "" {
+		t.Fatalf("不应合并: %q", from)
+	}
+}
@@ -0,0 +1,129 @@
+// file: internal/source/adb.go
+// package: source
+//
+// adb 源（实验性）：通过 adb 访问已连接的 Android 手机，形如 adb:///sdcard/Music 或
+// adb://SERIAL/sdcard/Music（多台设备时指定序列号）。列目录用 adb shell find + stat，
+// 读取用 adb exec-out cat 流式传输，不在本地落临时文件。
+// MTP 没有可用的标准库实现；用 jmtpfs / gvfs 等把手机挂载为本地目录后按普通目录使用即可。
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type adbSource struct {
+	serial string // 为空时使用 adb 默认设备
+	root   string // 设备上的目录
+}
+
+func newADBSource(spec string) (*adbSource, error) {
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	if u.Path == "" {
+		return nil, fmt.Errorf("adb 源缺少设备上的目录，如 adb:///sdcard/Music")
+	}
+	if _, err := exec.LookPath("adb"); err != nil {
+		return nil, errors.New("adb 未找到，请安装 Android platform-tools 并确保其在 PATH 中")
+	}
+	return &adbSource{serial: u.Host, root: path.Clean(u.Path)}, nil
+}
+
+func (a *adbSource) Root() string { return "adb://" + a.serial + a.root }
+func (a *adbSource) Local() bool  { return false }
+
+// DevicePath 把 adb://SERIAL/路径 还原为设备上的路径；不是 adb 路径时返回空串
+func DevicePath(p string) string {
+	if !strings.HasPrefix(p, "adb://") {
+		return ""
+	}
+	rest := strings.TrimPrefix(p, "adb://")
+	if i := strings.Index(rest, "/"); i >= 0 {
+		return rest[i:]
+	}
+	return ""
+}
+
+func (a *adbSource) command(args ...string) *exec.Cmd {
+	if a.serial != "" {
+		args = append([]string{"-s", a.serial}, args...)
+	}
+	return exec.Command("adb", args...)
+}
+
+func (a *adbSource) List(exts []string) ([]Entry, error) {
+	cmd := a.command("shell", "find "+shellQuote(a.root)+" -type f -exec stat -c '%s %Y %n' {} +")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("adb 列目录失败: %v %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return parseStatLines(out, "adb://"+a.serial, exts), nil
+}
+
+// parseStatLines 解析 "大小 修改时间 路径" 形式的行，按扩展名过滤
+func parseStatLines(out []byte, prefix string, exts []string) []Entry {
+	extMap := make(map[string]bool, len(exts))
+	for _, e := range exts {
+		extMap[strings.ToLower(e)] = true
+	}
+	var entries []Entry
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		fields := strings.SplitN(strings.TrimRight(sc.Text(), "\r"), " ", 3)
+		if len(fields) != 3 || !extMap[strings.ToLower(path.Ext(fields[2]))] {
+			continue
+		}
+		size, err1 := strconv.ParseInt(fields[0], 10, 64)
+		mtime, err2 := strconv.ParseInt(fields[1], 10, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		entries = append(entries, Entry{Path: prefix + fields[2], Size: size, ModTime: time.Unix(mtime, 0)})
+	}
+	return entries
+}
+
+// adbReader 在 Close 时等待 adb 进程退出
+type adbReader struct {
+	io.ReadCloser
+	cmd *exec.Cmd
+}
+
+func (r *adbReader) Close() error {
+	r.ReadCloser.Close()
+	return r.cmd.Wait()
+}
+
+func (a *adbSource) Open(p string) (io.ReadCloser, error) {
+	dp := DevicePath(p)
+	if dp == "" {
+		return nil, fmt.Errorf("不是 adb 路径: %s", p)
+	}
+	cmd := a.command("exec-out", "cat "+shellQuote(dp))
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &adbReader{ReadCloser: stdout, cmd: cmd}, nil
+}
+
+// shellQuote 为设备端 sh 加单引号
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,190 @@
+// file: internal/source/http.go
+// package: source
+//
+// HTTP 源：优先用 WebDAV PROPFIND（Depth: 1）列目录，服务器不支持时退回解析 HTML 目录索引页中的链接。
+// 只会进入根 URL 之下的子目录，不会沿 "../" 等链接跳出。
+package source
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type httpSource struct {
+	root   *url.URL
+	client *http.Client
+}
+
+func newHTTPSource(spec string) (*httpSource, error) {
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return &httpSource{root: u, client: &http.Client{Timeout: 60 * time.Second}}, nil
+}
+
+func (h *httpSource) Root() string { return h.root.String() }
+func (h *httpSource) Local() bool  { return false }
+
+func (h *httpSource) Open(p string) (io.ReadCloser, error) {
+	resp, err := http.Get(p) // 下载可能很长，不使用带超时的 client
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", p, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+func (h *httpSource) List(exts []string) ([]Entry, error) {
+	extMap := make(map[string]bool, len(exts))
+	for _, e := range exts {
+		extMap[strings.ToLower(e)] = true
+	}
+	var entries []Entry
+	visited := map[string]bool{}
+	queue := []*url.URL{h.root}
+	for len(queue) > 0 {
+		dir := queue[0]
+		queue = queue[1:]
+		if visited[dir.String()] {
+			continue
+		}
+		visited[dir.String()] = true
+
+		items, err := h.listDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, it := range items {
+			if !strings.HasPrefix(it.u.Path, h.root.Path) || it.u.Host != h.root.Host {
+				continue // 只处理根 URL 之下的条目
+			}
+			if it.dir {
+				if it.u.Path != dir.Path {
+					queue = append(queue, it.u)
+				}
+				continue
+			}
+			if extMap[strings.ToLower(path.Ext(it.u.Path))] {
+				entries = append(entries, Entry{Path: it.u.String(), Size: it.size, ModTime: it.mod})
+			}
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries, nil
+}
+
+type httpItem struct {
+	u    *url.URL
+	dir  bool
+	size int64
+	mod  time.Time
+}
+
+func (h *httpSource) listDir(dir *url.URL) ([]httpItem, error) {
+	if items, ok := h.propfind(dir); ok {
+		return items, nil
+	}
+	return h.autoindex(dir)
+}
+
+// ----------------- WebDAV -----------------
+
+type davMultistatus struct {
+	Responses []struct {
+		Href string `xml:"href"`
+		Prop struct {
+			Length       string `xml:"getcontentlength"`
+			LastModified string `xml:"getlastmodified"`
+			ResourceType struct {
+				Collection *struct{} `xml:"collection"`
+			} `xml:"resourcetype"`
+		} `xml:"propstat>prop"`
+	} `xml:"response"`
+}
+
+func (h *httpSource) propfind(dir *url.URL) ([]httpItem, bool) {
+	req, err := http.NewRequest("PROPFIND", dir.String(), strings.NewReader(
+		`<?xml version="1.0"?><d:propfind xmlns:d="DAV:"><d:prop><d:getcontentlength/><d:getlastmodified/><d:resourcetype/></d:prop></d:propfind>`))
+	if err != nil {
+		return nil, false
+	}
+	req.Header.Set("Depth", "1")
+	req.Header.Set("Content-Type", "application/xml")
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, false
+	}
+	var ms davMultistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, false
+	}
+	var items []httpItem
+	for _, r := range ms.Responses {
+		u, err := dir.Parse(r.Href)
+		if err != nil {
+			continue
+		}
+		it := httpItem{u: u, dir: r.Prop.ResourceType.Collection != nil}
+		it.size, _ = strconv.ParseInt(r.Prop.Length, 10, 64)
+		it.mod, _ = http.ParseTime(r.Prop.LastModified)
+		items = append(items, it)
+	}
+	return items, true
+}
+
+// ----------------- HTML 目录索引 -----------------
+
+var hrefRe = regexp.MustCompile(`(?i)href\s*=\s*["']([^"'#?]+)["']`)
+
+func (h *httpSource) autoindex(dir *url.URL) ([]httpItem, error) {
+	resp, err := h.client.Get(dir.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", dir, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return nil, err
+	}
+	var items []httpItem
+	for _, m := range hrefRe.FindAllStringSubmatch(string(body), -1) {
+		u, err := dir.Parse(m[1])
+		if err != nil {
+			continue
+		}
+		it := httpItem{u: u, dir: strings.HasSuffix(u.Path, "/")}
+		if !it.dir {
+			// 目录页不带大小信息，用 HEAD 取 Content-Length / Last-Modified
+			if hr, err := h.client.Head(u.String()); err == nil {
+				it.size = hr.ContentLength
+				it.mod, _ = http.ParseTime(hr.Header.Get("Last-Modified"))
+				hr.Body.Close()
+			}
+		}
+		items = append(items, it)
+	}
+	return items, nil
+}
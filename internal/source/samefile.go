@@ -0,0 +1,82 @@
+// file: internal/source/samefile.go
+// package: source
+//
+// 同一物理文件的多个路径：符号链接、硬链接、绑定挂载以及大小写不敏感文件系统上大小写不同的路径
+// 都会让同一个文件在扫描列表中出现多次，计算指纹后形成“与自身重复”的假分组（删除其一
+// 也释放不了空间，甚至会删掉唯一的一份）。DedupeSameFile 在计算指纹前按文件标识去重。
+package source
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Alias 为被去掉的重复路径：Path 与 Of 指向同一个物理文件
+type Alias struct {
+	Path string
+	Of   string
+}
+
+// DedupeSameFile 去掉指向同一物理文件（os.SameFile）的重复条目，保持其余条目的顺序。
+// 每组别名保留规范路径（路径本身不经过符号链接者优先，其次为最先出现者）。没有 Info 的条目（远程源）原样保留。
+func DedupeSameFile(entries []Entry) ([]Entry, []Alias) {
+	bySize := map[int64][]int{}
+	for i, e := range entries {
+		if e.Info != nil {
+			bySize[e.Size] = append(bySize[e.Size], i)
+		}
+	}
+	drop := map[int]string{} // 下标 -> 保留的路径
+	for _, idxs := range bySize {
+		if len(idxs) < 2 {
+			continue
+		}
+		done := make([]bool, len(idxs))
+		for a := range idxs {
+			if done[a] {
+				continue
+			}
+			same := []int{idxs[a]}
+			for b := a + 1; b < len(idxs); b++ {
+				if !done[b] && os.SameFile(entries[idxs[a]].Info, entries[idxs[b]].Info) {
+					done[b] = true
+					same = append(same, idxs[b])
+				}
+			}
+			if len(same) == 1 {
+				continue
+			}
+			keep := same[0]
+			for _, i := range same {
+				if isCanonical(entries[i].Path) {
+					keep = i
+					break
+				}
+			}
+			for _, i := range same {
+				if i != keep {
+					drop[i] = entries[keep].Path
+				}
+			}
+		}
+	}
+	if len(drop) == 0 {
+		return entries, nil
+	}
+	out := make([]Entry, 0, len(entries)-len(drop))
+	var aliases []Alias
+	for i, e := range entries {
+		if of, ok := drop[i]; ok {
+			aliases = append(aliases, Alias{Path: e.Path, Of: of})
+			continue
+		}
+		out = append(out, e)
+	}
+	return out, aliases
+}
+
+// isCanonical 返回 p 是否不经过任何符号链接（解析后与自身相同）
+func isCanonical(p string) bool {
+	real, err := filepath.EvalSymlinks(p)
+	return err == nil && real == filepath.Clean(p)
+}
@@ -0,0 +1,196 @@
+// file: internal/source/source.go
+// package: source
+//
+// 源后端抽象：把“列出音频文件 / 打开文件读取字节”与具体存储解耦。
+// 目前提供：
+//   - 本地目录（默认）
+//   - http:// / https://：WebDAV（PROPFIND）或普通 HTTP 目录索引页（如 nginx autoindex）
+//   - adb://：通过 adb 连接的 Android 手机（实验性）
+//
+// smb:// 与 nfs:// 需要第三方协议库，当前不支持，请先挂载到本地再使用。
+package source
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"deduplicateMusic/internal/scanner"
+)
+
+// Entry 为列出的一个文件
+type Entry struct {
+	Path    string // 本地路径或 URL
+	Size    int64
+	ModTime time.Time
+	Info    fs.FileInfo // 本地文件扫描时的元数据（远程源为 nil），见 DedupeSameFile
+}
+
+// Source 为源后端
+type Source interface {
+	// Root 返回源的根（本地目录或 URL）
+	Root() string
+	// List 列出所有扩展名在 exts 中的文件
+	List(exts []string) ([]Entry, error)
+	// Open 打开文件读取内容
+	Open(path string) (io.ReadCloser, error)
+	// Local 返回 Entry.Path 是否为本地文件路径（可直接交给 os / ffmpeg / 标签读取）
+	Local() bool
+}
+
+// New 根据 spec（本地目录或 URL）创建源后端
+func New(spec string) (Source, error) {
+	u, err := url.Parse(spec)
+	if err != nil || u.Scheme == "" || len(u.Scheme) == 1 { // 单字母 scheme 视为 Windows 盘符
+		return &localSource{root: spec}, nil
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "file":
+		return &localSource{root: u.Path}, nil
+	case "http", "https":
+		return newHTTPSource(spec)
+	case "adb":
+		return newADBSource(spec)
+	case "smb", "cifs", "nfs":
+		return nil, fmt.Errorf("暂不支持 %s:// 源（需要第三方协议库），请先挂载到本地目录后使用", u.Scheme)
+	}
+	return nil, fmt.Errorf("未知的源类型: %s", spec)
+}
+
+// IsLocalPath 判断 Entry.Path 是否为本地文件路径（不含 "scheme://" 前缀；单字母视为 Windows 盘符）。
+// 不用 url.Parse：远程文件名中的 % 等字符可能使解析失败。
+func IsLocalPath(p string) bool {
+	i := strings.Index(p, "://")
+	if i <= 1 {
+		return true
+	}
+	for _, r := range p[:i] {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '+' || r == '-' || r == '.') {
+			return true
+		}
+	}
+	return false
+}
+
+// ContextLister 由遍历可以中途取消的源实现（目前为本地目录）
+type ContextLister interface {
+	ListContext(ctx context.Context, exts []string) ([]Entry, error)
+}
+
+// List 列出 s 中扩展名在 exts 中的文件；s 支持时 ctx 取消后中途停止并返回 ctx.Err()，
+// 否则列完后再检查 ctx
+func List(ctx context.Context, s Source, exts []string) ([]Entry, error) {
+	if cl, ok := s.(ContextLister); ok {
+		return cl.ListContext(ctx, exts)
+	}
+	entries, err := s.List(exts)
+	if err == nil {
+		err = ctx.Err()
+	}
+	return entries, err
+}
+
+// Skipper 由能报告扫描时跳过的路径（如没有权限的目录）的源实现
+type Skipper interface {
+	Skipped() []scanner.Skip
+}
+
+// SkippedOf 返回源在最近一次 List 中跳过的路径；不支持时为空
+func SkippedOf(s Source) []scanner.Skip {
+	if sk, ok := s.(Skipper); ok {
+		return sk.Skipped()
+	}
+	return nil
+}
+
+// Multi 把多个源合并为一个：List 依次列出，Open 把本地路径交给第一个本地源、远程路径按前缀分派
+func Multi(srcs ...Source) Source { return multiSource(srcs) }
+
+type multiSource []Source
+
+func (m multiSource) Root() string {
+	roots := make([]string, len(m))
+	for i, s := range m {
+		roots[i] = s.Root()
+	}
+	return strings.Join(roots, ", ")
+}
+
+func (m multiSource) Local() bool {
+	for _, s := range m {
+		if !s.Local() {
+			return false
+		}
+	}
+	return true
+}
+
+func (m multiSource) Skipped() []scanner.Skip {
+	var all []scanner.Skip
+	for _, s := range m {
+		all = append(all, SkippedOf(s)...)
+	}
+	return all
+}
+
+func (m multiSource) List(exts []string) ([]Entry, error) {
+	return m.ListContext(context.Background(), exts)
+}
+
+func (m multiSource) ListContext(ctx context.Context, exts []string) ([]Entry, error) {
+	var all []Entry
+	for _, s := range m {
+		entries, err := List(ctx, s, exts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s.Root(), err)
+		}
+		all = append(all, entries...)
+	}
+	return all, nil
+}
+
+func (m multiSource) Open(p string) (io.ReadCloser, error) {
+	for _, s := range m {
+		if local := IsLocalPath(p); s.Local() == local && (local || strings.HasPrefix(p, s.Root())) {
+			return s.Open(p)
+		}
+	}
+	return nil, fmt.Errorf("路径不属于任何源: %s", p)
+}
+
+// ----------------- 本地目录 -----------------
+
+type localSource struct {
+	root    string
+	skipped []scanner.Skip
+}
+
+func (l *localSource) Root() string            { return l.root }
+func (l *localSource) Local() bool             { return true }
+func (l *localSource) Skipped() []scanner.Skip { return l.skipped }
+
+func (l *localSource) List(exts []string) ([]Entry, error) {
+	return l.ListContext(context.Background(), exts)
+}
+
+func (l *localSource) ListContext(ctx context.Context, exts []string) ([]Entry, error) {
+	found, skipped, err := scanner.ScanEntriesContext(ctx, l.root, exts)
+	l.skipped = skipped
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, len(found))
+	for i, f := range found {
+		entries[i] = Entry{Path: f.Path, Size: f.Size, ModTime: f.ModTime, Info: f.Info}
+	}
+	return entries, nil
+}
+
+func (l *localSource) Open(path string) (io.ReadCloser, error) {
+	return os.OpenFile(path, os.O_RDONLY, 0)
+}
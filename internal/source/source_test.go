@@ -0,0 +1,158 @@
+// file: internal/source/source_test.go
+// package: source
+//
+// 用 httptest 模拟 HTML 目录索引，测试 HTTP 源的递归列举、大小获取与读取；并测试 smb 源给出明确错误，
+// 以及按物理文件去掉符号链接 / 硬链接别名。
+package source
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHTTPAutoindexSource(t *testing.T) {
+	files := map[string]string{"/music/a.mp3": "aaaa", "/music/sub/b.flac": "bb", "/other/c.mp3": "c"}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/music/":
+			fmt.Fprint(w, `<a href="../">..</a><a href="a.mp3">a</a><a href="notes.txt">n</a><a href="sub/">sub</a><a href="/other/c.mp3">c</a>`)
+		case "/music/sub/":
+			fmt.Fprint(w, `<a href="../">..</a><a href='b.flac'>b</a>`)
+		default:
+			body, ok := files[r.URL.Path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+			if r.Method != http.MethodHead {
+				io.WriteString(w, body)
+			}
+		}
+	}))
+	defer srv.Close()
+
+	src, err := New(srv.URL + "/music")
+	if err != nil {
+		t.Fatalf("New 错误: %v", err)
+	}
+	entries, err := src.List([]string{".mp3", ".flac"})
+	if err != nil {
+		t.Fatalf("List 错误: %v", err)
+	}
+	if len(entries) != 2 || !strings.HasSuffix(entries[0].Path, "/music/a.mp3") || entries[0].Size != 4 ||
+		!strings.HasSuffix(entries[1].Path, "/music/sub/b.flac") {
+		t.Fatalf("列举结果不正确: %#v", entries)
+	}
+	rc, err := src.Open(entries[1].Path)
+	if err != nil {
+		t.Fatalf("Open 错误: %v", err)
+	}
+	defer rc.Close()
+	if b, _ := io.ReadAll(rc); string(b) != "bb" {
+		t.Fatalf("读取内容不正确: %q", b)
+	}
+}
+
+func TestUnsupportedScheme(t *testing.T) {
+	if _, err := New("smb://nas/music"); err == nil || !strings.Contains(err.Error(), "挂载") {
+		t.Fatalf("smb 源应给出挂载提示，实际 %v", err)
+	}
+	if s, err := New("testdata/music"); err != nil || !s.Local() {
+		t.Fatalf("普通路径应为本地源")
+	}
+}
+
+func TestParseADBStat(t *testing.T) {
+	out := []byte("1234 1700000000 /sdcard/Music/a b.MP3\r\n9 1 /sdcard/Music/cover.jpg\nbad line\n")
+	entries := parseStatLines(out, "adb://", []string{".mp3"})
+	if len(entries) != 1 || entries[0].Path != "adb:///sdcard/Music/a b.MP3" || entries[0].Size != 1234 {
+		t.Fatalf("解析结果: %+v", entries)
+	}
+	if dp := DevicePath(entries[0].Path); dp != "/sdcard/Music/a b.MP3" {
+		t.Fatalf("DevicePath = %q", dp)
+	}
+	if q := shellQuote("it's"); q != `'it'\''s'` {
+		t.Fatalf("shellQuote = %s", q)
+	}
+}
+
+func TestMultiSource(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.mp3"), []byte("local"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/m/" {
+			fmt.Fprint(w, `<a href="b.mp3">b.mp3</a>`)
+			return
+		}
+		fmt.Fprint(w, "remote")
+	}))
+	defer srv.Close()
+	remote, err := New(srv.URL + "/m/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := Multi(&localSource{root: dir}, remote)
+	if m.Local() {
+		t.Fatal("含远程源时 Local 应为 false")
+	}
+	entries, err := m.List([]string{".mp3"})
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("List = %+v, %v", entries, err)
+	}
+	for _, e := range entries {
+		rc, err := m.Open(e.Path)
+		if err != nil {
+			t.Fatalf("Open(%s): %v", e.Path, err)
+		}
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		want := "remote"
+		if IsLocalPath(e.Path) {
+			want = "local"
+		}
+		if string(b) != want {
+			t.Fatalf("%s 内容 = %q", e.Path, b)
+		}
+	}
+}
+
+func TestDedupeSameFile(t *testing.T) {
+	td := t.TempDir()
+	real := filepath.Join(td, "b.mp3")
+	if err := os.WriteFile(real, []byte("same"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(td, "c.mp3"), []byte("diff"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// 符号链接排在真实文件之前，仍应保留真实路径；硬链接同样是同一物理文件
+	if err := os.Symlink(real, filepath.Join(td, "a.mp3")); err != nil {
+		t.Skipf("无法创建符号链接: %v", err)
+	}
+	if err := os.Link(real, filepath.Join(td, "d.mp3")); err != nil {
+		t.Skipf("无法创建硬链接: %v", err)
+	}
+	s, _ := New(td)
+	entries, err := s.List([]string{".mp3"})
+	if err != nil || len(entries) != 4 {
+		t.Fatalf("List = %d, %v", len(entries), err)
+	}
+	entries = append(entries, Entry{Path: "http://host/x.mp3", Size: 4}) // 远程条目原样保留
+	got, aliases := DedupeSameFile(entries)
+	var paths []string
+	for _, e := range got {
+		paths = append(paths, filepath.Base(e.Path))
+	}
+	if strings.Join(paths, ",") != "b.mp3,c.mp3,x.mp3" || len(aliases) != 2 || aliases[0].Of != real {
+		t.Fatalf("去重结果 %v，别名 %v", paths, aliases)
+	}
+}
@@ -0,0 +1,213 @@
+// file: internal/spectro/spectro.go
+// package: spectro
+//
+// 频谱对比图：对于距离接近阈值的"边缘"匹配，把两份文件的频谱对齐后绘制差异图，
+// 用来解释为什么两者仍被判为重复（例如其中一份多了一段尾奏、或高频被截掉）。
+// 频谱直接在 Go 中对 8kHz 单声道 PCM 做短时 DFT，不依赖 ffmpeg 的 showspectrum 滤镜。
+package spectro
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+)
+
+const (
+	frameSize = 256  // 每帧样本数（8kHz 下 32ms），得到 128 个频点
+	hopSize   = 1024 // 帧移（128ms），5 分钟音频约 2300 列
+	bins      = frameSize / 2
+	floorDB   = -90.0 // 频谱幅度下限（dB）
+)
+
+// Spectrogram 为按帧排列的对数幅度谱，Frames[i][k] 为第 i 帧第 k 个频点（dB，<=0）
+type Spectrogram struct {
+	Frames [][]float64
+}
+
+var cosTab, sinTab, window = tables()
+
+func tables() (c, s, w []float64) {
+	c = make([]float64, frameSize)
+	s = make([]float64, frameSize)
+	w = make([]float64, frameSize)
+	for i := 0; i < frameSize; i++ {
+		a := 2 * math.Pi * float64(i) / frameSize
+		c[i], s[i] = math.Cos(a), math.Sin(a)
+		w[i] = 0.5 - 0.5*math.Cos(a) // Hann 窗
+	}
+	return c, s, w
+}
+
+// Compute 计算样本的频谱
+func Compute(samples []int16) Spectrogram {
+	var sp Spectrogram
+	buf := make([]float64, frameSize)
+	for start := 0; start+frameSize <= len(samples); start += hopSize {
+		for i := range buf {
+			buf[i] = float64(samples[start+i]) / 32768 * window[i]
+		}
+		frame := make([]float64, bins)
+		for k := 0; k < bins; k++ {
+			var re, im float64
+			for n, v := range buf {
+				idx := (k * n) % frameSize
+				re += v * cosTab[idx]
+				im -= v * sinTab[idx]
+			}
+			mag := math.Sqrt(re*re+im*im) / (frameSize / 4)
+			db := floorDB
+			if mag > 0 {
+				db = math.Max(20*math.Log10(mag), floorDB)
+			}
+			frame[k] = db
+		}
+		sp.Frames = append(sp.Frames, frame)
+	}
+	return sp
+}
+
+// energy 返回每帧的平均幅度（dB 高于下限的部分），用于对齐
+func (sp Spectrogram) energy() []float64 {
+	e := make([]float64, len(sp.Frames))
+	for i, f := range sp.Frames {
+		for _, v := range f {
+			e[i] += v - floorDB
+		}
+		e[i] /= bins
+	}
+	return e
+}
+
+// Align 在 ±maxLag 帧范围内寻找使两份能量曲线（去均值后）相关性最高的偏移。
+// 返回值 lag 表示 b 的第 i 帧与 a 的第 i+lag 帧对应。
+func Align(a, b Spectrogram, maxLag int) int {
+	ea, eb := center(a.energy()), center(b.energy())
+	best, bestLag := math.Inf(-1), 0
+	for lag := -maxLag; lag <= maxLag; lag++ {
+		var sum, na, nb float64
+		n := 0
+		for i := range eb {
+			j := i + lag
+			if j < 0 || j >= len(ea) {
+				continue
+			}
+			sum += ea[j] * eb[i]
+			na += ea[j] * ea[j]
+			nb += eb[i] * eb[i]
+			n++
+		}
+		if n == 0 || na == 0 || nb == 0 {
+			continue
+		}
+		if c := sum / math.Sqrt(na*nb); c > best {
+			best, bestLag = c, lag
+		}
+	}
+	return bestLag
+}
+
+func center(e []float64) []float64 {
+	var mean float64
+	for _, v := range e {
+		mean += v
+	}
+	mean /= float64(maxInt(len(e), 1))
+	for i := range e {
+		e[i] -= mean
+	}
+	return e
+}
+
+// MaxLagFrames 把秒数换算为对齐搜索的帧数
+func MaxLagFrames(seconds, sampleRate int) int {
+	return seconds * sampleRate / hopSize
+}
+
+// DiffImage 绘制三段纵向排列的图：上为 a 的频谱，中为对齐后的 b，下为差异。
+// 差异图中红色表示 a 更强，蓝色表示 b 更强；只有一方有内容的时间段（如多出的尾奏）标为黄色。
+func DiffImage(a, b Spectrogram, lag int) image.Image {
+	// 以 a 的帧为坐标，b 平移 lag 后覆盖的范围
+	start := minInt(0, lag)
+	end := maxInt(len(a.Frames), len(b.Frames)+lag)
+	width := maxInt(end-start, 1)
+	img := image.NewRGBA(image.Rect(0, 0, width, 3*bins+2))
+	sep := color.RGBA{255, 255, 255, 255}
+	for x := 0; x < width; x++ {
+		img.Set(x, bins, sep)
+		img.Set(x, 2*bins+1, sep)
+	}
+	frameAt := func(sp Spectrogram, i int) []float64 {
+		if i < 0 || i >= len(sp.Frames) {
+			return nil
+		}
+		return sp.Frames[i]
+	}
+	for x := 0; x < width; x++ {
+		t := x + start
+		fa, fb := frameAt(a, t), frameAt(b, t-lag)
+		for k := 0; k < bins; k++ {
+			y := bins - 1 - k // 低频在下
+			if fa != nil {
+				img.Set(x, y, heat(fa[k]))
+			}
+			if fb != nil {
+				img.Set(x, bins+1+y, heat(fb[k]))
+			}
+			var c color.RGBA
+			switch {
+			case fa == nil && fb == nil:
+			case fa == nil || fb == nil:
+				c = color.RGBA{230, 200, 40, 255}
+			default:
+				c = diffColor(fa[k] - fb[k])
+			}
+			img.Set(x, 2*bins+2+y, c)
+		}
+	}
+	return img
+}
+
+// heat 把 dB 映射为灰度
+func heat(db float64) color.RGBA {
+	v := uint8(math.Min((db-floorDB)/-floorDB, 1) * 255)
+	return color.RGBA{v, v, v, 255}
+}
+
+// diffColor 把差值（dB）映射为红/蓝，20dB 及以上为饱和色
+func diffColor(d float64) color.RGBA {
+	m := math.Min(math.Abs(d)/20, 1)
+	v := uint8(m * 255)
+	if d > 0 {
+		return color.RGBA{v, 0, 0, 255}
+	}
+	return color.RGBA{0, 0, v, 255}
+}
+
+// WritePNG 把图片写到 path
+func WritePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,59 @@
+// file: internal/spectro/spectro_test.go
+// package: spectro
+//
+// 测试纯音的频点位置、带偏移信号的对齐，以及多出尾段时差异图的尺寸。
+package spectro
+
+import (
+	"math"
+	"testing"
+)
+
+func tone(freq float64, n int) []int16 {
+	s := make([]int16, n)
+	for i := range s {
+		s[i] = int16(8000 * math.Sin(2*math.Pi*freq*float64(i)/8000))
+	}
+	return s
+}
+
+func TestComputePeakBin(t *testing.T) {
+	sp := Compute(tone(1000, 8000))
+	if len(sp.Frames) == 0 {
+		t.Fatal("没有帧")
+	}
+	peak := 0
+	for k, v := range sp.Frames[0] {
+		if v > sp.Frames[0][peak] {
+			peak = k
+		}
+	}
+	// 1000Hz 在 8kHz/256 点下对应第 32 个频点
+	if peak != 32 {
+		t.Fatalf("峰值频点 = %d，期望 32", peak)
+	}
+}
+
+func TestAlignAndDiff(t *testing.T) {
+	// a 为 4 秒不规则的断续信号；b 在前面多了 1 秒静音
+	a := make([]int16, 4*8000)
+	for i := range a {
+		if i < 2000 || (i >= 6000 && i < 14000) || (i >= 20000 && i < 21000) {
+			a[i] = int16(8000 * math.Sin(float64(i)))
+		}
+	}
+	b := append(make([]int16, 8000), a...)
+	sa, sb := Compute(a), Compute(b)
+	lag := Align(sa, sb, MaxLagFrames(3, 8000))
+	want := -8000 / hopSize
+	if lag < want-1 || lag > want+1 {
+		t.Fatalf("lag = %d，期望约 %d", lag, want)
+	}
+	img := DiffImage(sa, sb, lag)
+	if h := img.Bounds().Dy(); h != 3*bins+2 {
+		t.Fatalf("高度 = %d", h)
+	}
+	if w := img.Bounds().Dx(); w < len(sb.Frames) {
+		t.Fatalf("宽度 %d 应覆盖 b 的 %d 帧", w, len(sb.Frames))
+	}
+}
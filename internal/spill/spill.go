@@ -0,0 +1,89 @@
+// file: internal/spill/spill.go
+// package: spill
+//
+// 磁盘溢出存储：超大规模运行时把完整的 FileMeta（路径、标签等）追加写入临时文件，
+// 内存中只保留紧凑索引（指纹 + 文件偏移，每个文件 16 字节），聚类完成后再按需读回。
+package spill
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"deduplicateMusic/internal/dedup"
+)
+
+// Store 为追加写入、按偏移随机读取的 FileMeta 存储
+type Store struct {
+	f   *os.File
+	w   *bufio.Writer
+	off int64
+
+	// 紧凑索引：第 i 个文件的指纹与记录偏移
+	FPs     []uint64
+	Offsets []int64
+}
+
+// Create 在 dir 下创建临时溢出文件（dir 为空时使用系统临时目录）
+func Create(dir string) (*Store, error) {
+	f, err := os.CreateTemp(dir, "audio-dedup-spill-*.bin")
+	if err != nil {
+		return nil, err
+	}
+	return &Store{f: f, w: bufio.NewWriterSize(f, 1<<20)}, nil
+}
+
+// Append 写入一条记录并登记到紧凑索引，返回其下标
+func (s *Store) Append(m dedup.FileMeta) (int, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return 0, err
+	}
+	var hdr [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(hdr[:], uint64(len(b)))
+	if _, err := s.w.Write(hdr[:n]); err != nil {
+		return 0, err
+	}
+	if _, err := s.w.Write(b); err != nil {
+		return 0, err
+	}
+	s.FPs = append(s.FPs, m.FP)
+	s.Offsets = append(s.Offsets, s.off)
+	s.off += int64(n + len(b))
+	return len(s.FPs) - 1, nil
+}
+
+// Len 返回记录数
+func (s *Store) Len() int { return len(s.FPs) }
+
+// Load 读回第 i 条记录
+func (s *Store) Load(i int) (dedup.FileMeta, error) {
+	var m dedup.FileMeta
+	if err := s.w.Flush(); err != nil {
+		return m, err
+	}
+	r := bufio.NewReader(io.NewSectionReader(s.f, s.Offsets[i], s.off-s.Offsets[i]))
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return m, fmt.Errorf("读取溢出记录失败: %w", err)
+	}
+	b := make([]byte, size)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return m, fmt.Errorf("读取溢出记录失败: %w", err)
+	}
+	err = json.Unmarshal(b, &m)
+	return m, err
+}
+
+// Close 关闭并删除溢出文件
+func (s *Store) Close() error {
+	name := s.f.Name()
+	err := s.f.Close()
+	if rerr := os.Remove(name); err == nil {
+		err = rerr
+	}
+	return err
+}
@@ -0,0 +1,46 @@
+// file: internal/spill/spill_test.go
+// package: spill
+//
+// 测试记录写入后可按下标读回，关闭后临时文件被删除。
+package spill
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"deduplicateMusic/internal/dedup"
+	"deduplicateMusic/internal/tags"
+)
+
+func TestAppendLoad(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Create(dir)
+	if err != nil {
+		t.Fatalf("Create 错误: %v", err)
+	}
+	in := []dedup.FileMeta{
+		{Path: "a.mp3", Size: 1, FP: 0xaa, Tags: tags.Tags{Artist: "X"}},
+		{Path: "目录/b.flac", Size: 2, FP: 0xbb, Envelope: []uint8{0, 9, 255}},
+	}
+	for _, m := range in {
+		if _, err := s.Append(m); err != nil {
+			t.Fatalf("Append 错误: %v", err)
+		}
+	}
+	if s.Len() != 2 || s.FPs[1] != 0xbb {
+		t.Fatalf("紧凑索引不正确: %#v", s.FPs)
+	}
+	for i := len(in) - 1; i >= 0; i-- {
+		got, err := s.Load(i)
+		if err != nil || !reflect.DeepEqual(got, in[i]) {
+			t.Fatalf("Load(%d) = %#v, %v，期望 %#v", i, got, err, in[i])
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close 错误: %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("关闭后应删除溢出文件")
+	}
+}
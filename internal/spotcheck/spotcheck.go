@@ -0,0 +1,101 @@
+// file: internal/spotcheck/spotcheck.go
+// package: spotcheck
+//
+// 运行后抽查：在处理过程中用蓄水池抽样随机保留 N 对（保留文件, 重复文件），
+// 全部处理完后重新解码磁盘上的最终文件（保留文件取复制后的副本）并重新计算指纹，
+// 与决策时记录的指纹、大小与距离比对。不一致说明运行期间有其它程序改动了文件，
+// 或复制出的副本与源文件不同。
+package spotcheck
+
+import (
+	"fmt"
+	"math/bits"
+	"math/rand"
+	"os"
+)
+
+// Pair 为一对被抽查的决策
+type Pair struct {
+	GroupID  int
+	Keep     string // 保留文件的原路径
+	KeepFile string // 磁盘上的最终文件（复制后的副本；未复制时同 Keep）
+	KeepFP   uint64
+	KeepSize int64
+	Dup      string
+	DupFP    uint64
+	DupSize  int64
+	Distance int // 决策时两者指纹的汉明距离
+}
+
+// Sampler 以蓄水池抽样保留至多 n 对
+type Sampler struct {
+	n     int
+	seen  int
+	rng   *rand.Rand
+	pairs []Pair
+}
+
+// NewSampler 创建抽样器
+func NewSampler(n int, seed int64) *Sampler {
+	return &Sampler{n: n, rng: rand.New(rand.NewSource(seed))}
+}
+
+// Offer 提交一对候选
+func (s *Sampler) Offer(p Pair) {
+	s.seen++
+	if len(s.pairs) < s.n {
+		s.pairs = append(s.pairs, p)
+		return
+	}
+	if i := s.rng.Intn(s.seen); i < s.n {
+		s.pairs[i] = p
+	}
+}
+
+// Pairs 返回抽中的决策
+func (s *Sampler) Pairs() []Pair { return s.pairs }
+
+// Result 为一对决策的抽查结果
+type Result struct {
+	Pair
+	NewDistance int      // 重新计算的距离；无法计算时为 -1
+	Problems    []string // 发现的不一致，空表示通过
+}
+
+// Check 重新计算每对文件的指纹并比对；fp 为与决策时相同参数的指纹函数
+func Check(pairs []Pair, fp func(path string) (uint64, error)) []Result {
+	out := make([]Result, 0, len(pairs))
+	for _, p := range pairs {
+		r := Result{Pair: p, NewDistance: -1}
+		kfp, kerr := verify(&r, "保留文件", p.KeepFile, p.KeepFP, p.KeepSize, fp)
+		dfp, derr := verify(&r, "重复文件", p.Dup, p.DupFP, p.DupSize, fp)
+		if kerr == nil && derr == nil {
+			r.NewDistance = bits.OnesCount64(kfp ^ dfp)
+			if r.NewDistance != p.Distance {
+				r.Problems = append(r.Problems, fmt.Sprintf("距离由 %d 变为 %d", p.Distance, r.NewDistance))
+			}
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func verify(r *Result, role, path string, wantFP uint64, wantSize int64, fp func(string) (uint64, error)) (uint64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		r.Problems = append(r.Problems, fmt.Sprintf("%s无法访问: %v", role, err))
+		return 0, err
+	}
+	if info.Size() != wantSize {
+		r.Problems = append(r.Problems, fmt.Sprintf("%s大小由 %d 变为 %d", role, wantSize, info.Size()))
+	}
+	got, err := fp(path)
+	if err != nil {
+		r.Problems = append(r.Problems, fmt.Sprintf("%s解码失败: %v", role, err))
+		return 0, err
+	}
+	if got != wantFP {
+		r.Problems = append(r.Problems, fmt.Sprintf("%s指纹已改变（%d 位不同）", role, bits.OnesCount64(got^wantFP)))
+	}
+	return got, nil
+}
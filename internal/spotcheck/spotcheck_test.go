@@ -0,0 +1,43 @@
+// file: internal/spotcheck/spotcheck_test.go
+// package: spotcheck
+//
+// 测试蓄水池抽样的数量上限，以及文件大小/指纹改变时报告不一致。
+package spotcheck
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSampler(t *testing.T) {
+	s := NewSampler(3, 1)
+	for i := 0; i < 100; i++ {
+		s.Offer(Pair{GroupID: i})
+	}
+	if len(s.Pairs()) != 3 {
+		t.Fatalf("抽样数量 = %d", len(s.Pairs()))
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	keep, dup := filepath.Join(dir, "k.flac"), filepath.Join(dir, "d.mp3")
+	os.WriteFile(keep, make([]byte, 10), 0o644)
+	os.WriteFile(dup, make([]byte, 20), 0o644)
+	fps := map[string]uint64{keep: 0b1111, dup: 0b0111}
+	fp := func(p string) (uint64, error) { return fps[p], nil }
+
+	ok := Pair{KeepFile: keep, KeepFP: 0b1111, KeepSize: 10, Dup: dup, DupFP: 0b0111, DupSize: 20, Distance: 1}
+	if r := Check([]Pair{ok}, fp)[0]; len(r.Problems) != 0 || r.NewDistance != 1 {
+		t.Fatalf("未改变的文件应通过: %+v", r)
+	}
+
+	fps[dup] = 0 // 运行期间被改写
+	changed := ok
+	changed.DupSize = 30
+	r := Check([]Pair{changed}, fp)[0]
+	if len(r.Problems) != 3 || r.NewDistance != 4 {
+		t.Fatalf("应报告大小、指纹与距离的变化: %+v", r)
+	}
+}
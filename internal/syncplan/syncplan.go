@@ -0,0 +1,124 @@
+// file: internal/syncplan/syncplan.go
+// package: syncplan
+//
+// 同步计划：比对主库（-src）与设备（-device）后，找出只存在于一侧的曲目
+// （按指纹分组后组内没有另一侧的成员），给出"从哪里复制到哪里"的清单。
+// 只生成计划，不执行复制；目标路径沿用源文件相对于其根目录的路径。
+package syncplan
+
+import (
+	"encoding/csv"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"deduplicateMusic/internal/gzfile"
+	"deduplicateMusic/internal/source"
+)
+
+// Direction 为同步方向
+type Direction string
+
+const (
+	ToDevice  Direction = "to-device"  // 主库有、设备缺少
+	ToLibrary Direction = "to-library" // 设备有、主库缺少
+)
+
+// ParseDirections 解析 -sync-direction：both / to-device / to-library
+func ParseDirections(s string) (map[Direction]bool, bool) {
+	switch s {
+	case "", "both":
+		return map[Direction]bool{ToDevice: true, ToLibrary: true}, true
+	case string(ToDevice), string(ToLibrary):
+		return map[Direction]bool{Direction(s): true}, true
+	}
+	return nil, false
+}
+
+// Item 为一条复制计划
+type Item struct {
+	Direction Direction
+	Source    string
+	Target    string
+	Size      int64
+}
+
+// Target 计算 p（位于 fromRoot 下）复制到 toRoot 后的路径。
+// adb 根目录返回设备端路径（可直接用于 adb push），URL 根目录按段转义后拼接，其余按本地路径拼接。
+func Target(fromRoot, toRoot, p string) string {
+	rel := relative(fromRoot, p)
+	if dp := source.DevicePath(toRoot); dp != "" {
+		return path.Join(dp, rel)
+	}
+	if !source.IsLocalPath(toRoot) {
+		segs := strings.Split(rel, "/")
+		for i, s := range segs {
+			segs[i] = url.PathEscape(s)
+		}
+		return strings.TrimSuffix(toRoot, "/") + "/" + strings.Join(segs, "/")
+	}
+	return filepath.Join(toRoot, filepath.FromSlash(rel))
+}
+
+// relative 返回 p 相对于 root 的未转义路径（"/" 分隔）；不在 root 之下，或 URL 的相对路径含
+// "."、".." 等无法安全映射到目录的段时退化为文件名
+func relative(root, p string) string {
+	if source.IsLocalPath(p) {
+		if rel, err := filepath.Rel(root, p); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return filepath.ToSlash(rel)
+		}
+		return filepath.Base(p)
+	}
+	web := strings.HasPrefix(p, "http://") || strings.HasPrefix(p, "https://")
+	prefix := strings.TrimSuffix(root, "/") + "/"
+	if rel, ok := strings.CutPrefix(p, prefix); ok {
+		if !web {
+			return rel // adb 路径本身未转义
+		}
+		if rel, ok := unescapeRel(rel); ok {
+			return rel
+		}
+	}
+	base := path.Base(p)
+	if web {
+		if u, err := url.Parse(p); err == nil {
+			base = path.Base(u.Path)
+		}
+	}
+	return base
+}
+
+// unescapeRel 逐段反转义 URL 的相对路径；有空段、"."、".." 或反转义后含路径分隔符的段时返回 false
+func unescapeRel(rel string) (string, bool) {
+	rel, _, _ = strings.Cut(rel, "?")
+	segs := strings.Split(rel, "/")
+	for i, s := range segs {
+		u, err := url.PathUnescape(s)
+		if err != nil || u == "" || u == "." || u == ".." || strings.ContainsAny(u, `/\`) {
+			return "", false
+		}
+		segs[i] = u
+	}
+	return strings.Join(segs, "/"), true
+}
+
+// Write 把计划写成 CSV：direction,source,target,size
+func Write(out string, items []Item) error {
+	f, err := gzfile.Create(out)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	w.Write([]string{"direction", "source", "target", "size"})
+	for _, it := range items {
+		w.Write([]string{string(it.Direction), it.Source, it.Target, strconv.FormatInt(it.Size, 10)})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,72 @@
+// file: internal/syncplan/syncplan_test.go
+// package: syncplan
+//
+// 测试各种根目录组合下目标路径的计算（含 URL 反转义、拒绝 ".." 段与同名时的后缀）与方向解析。
+package syncplan
+
+import (
+	"path/filepath"
+	"testing"
+
+	"deduplicateMusic/internal/copyutil"
+)
+
+func TestTarget(t *testing.T) {
+	lib := filepath.Join("home", "me", "Music")
+	cases := []struct{ from, to, p, want string }{
+		{lib, "adb://SER/sdcard/Music", filepath.Join(lib, "A", "x.flac"), "/sdcard/Music/A/x.flac"},
+		{"adb:///sdcard/Music", lib, "adb:///sdcard/Music/B/y.mp3", filepath.Join(lib, "B", "y.mp3")},
+		{"http://nas/m/", lib, "http://nas/m/C/z.mp3", filepath.Join(lib, "C", "z.mp3")},
+		{lib, "http://nas/m", filepath.Join("elsewhere", "w.mp3"), "http://nas/m/w.mp3"},
+		// 本地根目录：保留子目录，根目录之外的文件退化为文件名
+		{lib, "out", filepath.Join(lib, "A", "B", "x.flac"), filepath.Join("out", "A", "B", "x.flac")},
+		{lib, "out", filepath.Join(lib, "..x", "y.mp3"), filepath.Join("out", "..x", "y.mp3")},
+		{lib, "out", filepath.Join("home", "me", "z.mp3"), filepath.Join("out", "z.mp3")},
+		// URL 根目录：相对路径逐段反转义
+		{"http://nas/m", "out", "http://nas/m/The%20Band/01%20Song.mp3", filepath.Join("out", "The Band", "01 Song.mp3")},
+		{"https://nas/dav/", "out", "https://nas/dav/%E6%AD%8C/a%25b.flac", filepath.Join("out", "歌", "a%b.flac")},
+		// ".."、"." 与转义的分隔符不能映射到目标目录之外，退化为（反转义的）文件名
+		{"http://nas/m", "out", "http://nas/m/A/%2E%2E/%2E%2E/x%20y.mp3", filepath.Join("out", "x y.mp3")},
+		{"http://nas/m", "out", "http://nas/m/A/../x.mp3", filepath.Join("out", "x.mp3")},
+		{"http://nas/m", "out", "http://nas/m/A%2F..%2F..%2Fetc/x.mp3", filepath.Join("out", "x.mp3")},
+		{"http://nas/m", "out", "http://other/n/q%20r.mp3", filepath.Join("out", "q r.mp3")},
+		// 写到 URL 根目录时重新转义
+		{"http://nas/m", "http://nas/copy", "http://nas/m/The%20Band/a.mp3", "http://nas/copy/The%20Band/a.mp3"},
+	}
+	for _, c := range cases {
+		if got := Target(c.from, c.to, c.p); got != c.want {
+			t.Errorf("Target(%q, %q, %q) = %q，期望 %q", c.from, c.to, c.p, got, c.want)
+		}
+	}
+}
+
+func TestParseDirections(t *testing.T) {
+	if d, ok := ParseDirections("both"); !ok || !d[ToDevice] || !d[ToLibrary] {
+		t.Fatalf("both 解析错误: %v", d)
+	}
+	if d, ok := ParseDirections("to-device"); !ok || d[ToLibrary] {
+		t.Fatalf("to-device 解析错误: %v", d)
+	}
+	if _, ok := ParseDirections("sideways"); ok {
+		t.Fatal("未知方向应报错")
+	}
+}
+
+func TestTargetCollisionSuffix(t *testing.T) {
+	dst := t.TempDir()
+	lib := t.TempDir()
+	// 远程文件与本地文件的相对路径反转义后相同，第二个文件应得到带后缀的目标
+	a := Target("http://nas/m", dst, "http://nas/m/A/a%20b.mp3")
+	b := Target(lib, dst, filepath.Join(lib, "A", "a b.mp3"))
+	if a != b || a != filepath.Join(dst, "A", "a b.mp3") {
+		t.Fatalf("目标路径应相同: %q %q", a, b)
+	}
+	names := copyutil.NewNames(true)
+	if got, collided := names.Resolve(a, "http://nas/m/A/a%20b.mp3", 10, copyutil.CollisionRename); got != a || collided {
+		t.Fatalf("第一个文件 = %q, %v", got, collided)
+	}
+	want := filepath.Join(dst, "A", "a b (2).mp3")
+	if got, collided := names.Resolve(b, filepath.Join(lib, "A", "a b.mp3"), 20, copyutil.CollisionRename); got != want || !collided {
+		t.Fatalf("第二个文件 = %q, %v，期望 %q", got, collided, want)
+	}
+}
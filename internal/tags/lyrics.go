@@ -0,0 +1,61 @@
+// file: internal/tags/lyrics.go
+// package: tags
+//
+// 内嵌歌词：ID3v2 的 USLT（2.2 为 ULT）帧，FLAC 的 LYRICS / UNSYNCEDLYRICS 注释。
+// 歌词可能较长，不放进 Tags（元数据会随溢出文件落盘），需要时单独读取。
+package tags
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// ReadLyrics 读取 path 的内嵌歌词，没有时返回空串
+func ReadLyrics(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var lyrics string
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(f, magic); err == nil && string(magic) == "fLaC" {
+		err := readVorbisComments(f, func(k, v string) {
+			if k = strings.ToUpper(k); (k == "LYRICS" || k == "UNSYNCEDLYRICS") && lyrics == "" {
+				lyrics = strings.TrimSpace(v)
+			}
+		})
+		return lyrics, err
+	}
+	err = readID3v2Frames(f, func(id string, data []byte) {
+		if (id == "USLT" || id == "ULT") && lyrics == "" {
+			lyrics = decodeUSLT(data)
+		}
+	})
+	if errors.Is(err, errNoTag) {
+		err = nil
+	}
+	return lyrics, err
+}
+
+// decodeUSLT 解析 USLT 帧：编码(1) + 语言(3) + 以 0 结尾的描述 + 歌词正文
+func decodeUSLT(b []byte) string {
+	if len(b) < 4 {
+		return ""
+	}
+	enc, rest := b[0], b[4:]
+	wide := enc == 1 || enc == 2
+	for i := 0; i < len(rest); i++ {
+		if wide {
+			if i%2 == 0 && i+1 < len(rest) && rest[i] == 0 && rest[i+1] == 0 {
+				return decodeText(append([]byte{enc}, rest[i+2:]...))
+			}
+		} else if rest[i] == 0 {
+			return decodeText(append([]byte{enc}, rest[i+1:]...))
+		}
+	}
+	return ""
+}
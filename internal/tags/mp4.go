@@ -0,0 +1,143 @@
+// file: internal/tags/mp4.go
+// package: tags
+//
+// MP4 / M4A 的 iTunes 元数据：moov/udta/meta/ilst 下每个条目（如 ©ART、trkn）含一个 data 原子，
+// 文本为 UTF-8，曲号与碟号为二进制（编号、总数各 2 字节）。moov 可能位于 mdat 之后，
+// 按原子大小跳过而不读取音频数据。
+package tags
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// mp4MaxItem 为单个 ilst 条目的读取上限，封面（covr）等大条目直接跳过
+const mp4MaxItem = 1 << 20
+
+// mp4Items 为 ilst 条目名到字段的映射（文本条目）
+var mp4Items = map[string]func(*Tags, string){
+	"\xa9ART": func(t *Tags, v string) { t.Artist = v },
+	"aART":    func(t *Tags, v string) { t.AlbumArtist = v },
+	"\xa9alb": func(t *Tags, v string) { t.Album = v },
+	"\xa9nam": func(t *Tags, v string) { t.Title = v },
+	"\xa9wrt": func(t *Tags, v string) { t.Composer = v },
+	"\xa9wrk": func(t *Tags, v string) { t.Work = v },
+	"\xa9mvn": func(t *Tags, v string) { t.Movement = v },
+}
+
+// readMP4 读取 MP4 文件的 iTunes 元数据
+func readMP4(r io.ReadSeeker) (Tags, error) {
+	var t Tags
+	end, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return t, err
+	}
+	start, stop, err := findAtom(r, 0, end, "moov", "udta", "meta", "ilst")
+	if err != nil {
+		return t, err
+	}
+	err = eachAtom(r, start, stop, func(name string, body, bodyEnd int64) error {
+		if bodyEnd-body > mp4MaxItem {
+			return nil
+		}
+		ds, de, err := findAtom(r, body, bodyEnd, "data")
+		if err != nil || de-ds < 8 {
+			return nil // 没有 data 原子的条目（如 ----）忽略
+		}
+		data := make([]byte, de-ds)
+		if _, err := r.Seek(ds, io.SeekStart); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(r, data); err != nil {
+			return err
+		}
+		value := data[8:] // 类型（版本 + 标志）与语言各 4 字节
+		switch name {
+		case "trkn", "disk":
+			if len(value) < 6 {
+				return nil
+			}
+			num, total := binary.BigEndian.Uint16(value[2:]), binary.BigEndian.Uint16(value[4:])
+			s := ""
+			if num > 0 {
+				s = strconv.Itoa(int(num))
+				if total > 0 {
+					s += "/" + strconv.Itoa(int(total))
+				}
+			}
+			if name == "trkn" {
+				t.Track = s
+			} else {
+				t.Disc = s
+			}
+		case "cpil":
+			t.Compilation = len(value) > 0 && value[0] == 1
+		default:
+			if set, ok := mp4Items[name]; ok && len(value) > 0 {
+				set(&t, string(value))
+			}
+		}
+		return nil
+	})
+	return t, err
+}
+
+// findAtom 在 [start, end) 中按 path 逐层查找原子，返回最后一层原子内容的范围
+func findAtom(r io.ReadSeeker, start, end int64, path ...string) (int64, int64, error) {
+	found := false
+	err := eachAtom(r, start, end, func(name string, body, bodyEnd int64) error {
+		if name != path[0] {
+			return nil
+		}
+		if name == "meta" {
+			body += 4 // meta 为 full box：版本与标志
+		}
+		start, end, found = body, bodyEnd, true
+		return errStop
+	})
+	if err != nil && err != errStop {
+		return 0, 0, err
+	}
+	if !found {
+		return 0, 0, errNoTag
+	}
+	if len(path) == 1 {
+		return start, end, nil
+	}
+	return findAtom(r, start, end, path[1:]...)
+}
+
+var errStop = errors.New("stop")
+
+// eachAtom 依次对 [start, end) 中的每个原子调用 fn(名称, 内容起点, 内容终点)
+func eachAtom(r io.ReadSeeker, start, end int64, fn func(name string, body, bodyEnd int64) error) error {
+	hdr := make([]byte, 16)
+	for pos := start; pos+8 <= end; {
+		if _, err := r.Seek(pos, io.SeekStart); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(r, hdr[:8]); err != nil {
+			return err
+		}
+		size, body := int64(binary.BigEndian.Uint32(hdr)), pos+8
+		switch size {
+		case 0: // 延伸到父原子末尾
+			size = end - pos
+		case 1: // 64 位大小
+			if _, err := io.ReadFull(r, hdr[8:16]); err != nil {
+				return err
+			}
+			size, body = int64(binary.BigEndian.Uint64(hdr[8:])), pos+16
+		}
+		if size < body-pos || pos+size > end {
+			return errors.New("MP4 原子大小无效")
+		}
+		if err := fn(string(hdr[4:8]), body, pos+size); err != nil {
+			return err
+		}
+		pos += size
+	}
+	return nil
+}
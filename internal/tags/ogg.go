@@ -0,0 +1,87 @@
+// file: internal/tags/ogg.go
+// package: tags
+//
+// Ogg 流（Vorbis、Opus）中的 Vorbis 注释：第一个逻辑流的第二个数据包即注释包，
+// Vorbis 以 "\x03vorbis" 开头，Opus 以 "OpusTags" 开头，其后与 FLAC 的 VORBIS_COMMENT 块格式相同。
+// 数据包可能跨越多个页，按页内分段表拼接。
+package tags
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+)
+
+// oggMaxPacket 为注释包的读取上限（内嵌封面的注释包可能较大）
+const oggMaxPacket = 16 << 20
+
+// readOgg 读取 Ogg 文件的 Vorbis 注释
+func readOgg(r io.Reader) (Tags, error) {
+	var t Tags
+	packet, err := oggPacket(r, 1)
+	if err != nil {
+		return t, err
+	}
+	switch {
+	case bytes.HasPrefix(packet, []byte("\x03vorbis")):
+		packet = packet[7:]
+	case bytes.HasPrefix(packet, []byte("OpusTags")):
+		packet = packet[8:]
+	default:
+		return t, errNoTag
+	}
+	parseVorbisComment(packet, func(k, v string) {
+		if set, known := vorbisFields[strings.ToUpper(k)]; known && v != "" {
+			set(&t, strings.TrimSpace(v))
+		}
+	})
+	return t, nil
+}
+
+// oggPacket 返回第一个逻辑流中序号为 index（从 0 开始）的数据包
+func oggPacket(r io.Reader, index int) ([]byte, error) {
+	hdr := make([]byte, 27)
+	var serial uint32
+	var packet []byte
+	n := 0
+	for first := true; ; first = false {
+		if _, err := io.ReadFull(r, hdr); err != nil {
+			return nil, err
+		}
+		if string(hdr[:4]) != "OggS" {
+			return nil, errors.New("无效的 Ogg 页")
+		}
+		segs := make([]byte, hdr[26])
+		if _, err := io.ReadFull(r, segs); err != nil {
+			return nil, err
+		}
+		if first {
+			serial = binary.LittleEndian.Uint32(hdr[14:])
+		}
+		other := binary.LittleEndian.Uint32(hdr[14:]) != serial
+		for _, l := range segs {
+			if other || n != index {
+				if _, err := io.CopyN(io.Discard, r, int64(l)); err != nil {
+					return nil, err
+				}
+			} else {
+				if len(packet)+int(l) > oggMaxPacket {
+					return nil, errors.New("Ogg 注释包过大")
+				}
+				buf := make([]byte, l)
+				if _, err := io.ReadFull(r, buf); err != nil {
+					return nil, err
+				}
+				packet = append(packet, buf...)
+			}
+			if l < 255 && !other {
+				if n == index {
+					return packet, nil
+				}
+				n++
+			}
+		}
+	}
+}
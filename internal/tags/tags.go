@@ -0,0 +1,365 @@
+// file: internal/tags/tags.go
+// package: tags
+//
+// 读取音频文件的基础标签（艺术家/专辑/标题等），目前支持 ID3v2（2.2/2.3/2.4）、ID3v1、
+// FLAC 与 Ogg（Vorbis、Opus）的 Vorbis 注释，以及 MP4 / M4A 的 iTunes 元数据（见 mp4.go、ogg.go）。
+// 读取失败或无标签时返回空 Tags，不视为致命错误。
+package tags
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf16"
+
+	"deduplicateMusic/internal/formats"
+)
+
+// Tags 为从文件中读取到的标签
+type Tags struct {
+	Artist      string `json:"artist,omitempty"`
+	AlbumArtist string `json:"album_artist,omitempty"`
+	Album       string `json:"album,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Track       string `json:"track,omitempty"`       // 原始曲号标签，规范化见 ParseTrackID
+	TrackTotal  string `json:"track_total,omitempty"` // Vorbis TRACKTOTAL；ID3 的总数写在 Track 的 "/" 之后
+	Disc        string `json:"disc,omitempty"`
+	Compilation bool   `json:"compilation,omitempty"` // iTunes TCMP / Vorbis COMPILATION 标记
+
+	// 古典音乐相关
+	Composer string `json:"composer,omitempty"`
+	Work     string `json:"work,omitempty"`
+	Movement string `json:"movement,omitempty"`
+}
+
+// Empty 返回是否未读取到任何标签
+func (t Tags) Empty() bool { return t == Tags{} }
+
+// Completeness 返回艺术家、标题、专辑、曲号四项中有值的项数，用于优先保留标签更完整的文件
+func (t Tags) Completeness() int {
+	n := 0
+	for _, v := range []string{t.Artist, t.Title, t.Album, t.Track} {
+		if strings.TrimSpace(v) != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// variousArtists 为常见的合辑专辑艺术家写法（小写）
+var variousArtists = map[string]bool{
+	"various artists": true, "various": true, "va": true, "v.a.": true, "v/a": true,
+	"群星": true, "合辑": true, "合輯": true, "verschiedene interpreten": true, "artistes divers": true,
+}
+
+// IsCompilation 返回是否属于多艺术家合辑（有合辑标记，或专辑艺术家为 "Various Artists" 等）
+func (t Tags) IsCompilation() bool {
+	return t.Compilation || variousArtists[strings.ToLower(strings.TrimSpace(t.AlbumArtist))]
+}
+
+// TrackArtist 返回曲目本身的艺术家：合辑按曲目艺术家，其余优先专辑艺术家
+func (t Tags) TrackArtist() string {
+	if t.IsCompilation() || t.AlbumArtist == "" {
+		return t.Artist
+	}
+	return t.AlbumArtist
+}
+
+// ReadFile 读取 path 的标签
+func ReadFile(path string) (Tags, error) {
+	if f, ok := formats.ByPath(path); ok && f.Tags == formats.TagNone {
+		return Tags{}, nil // 格式注册表表明该格式没有可读取的标签
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return Tags{}, err
+	}
+	defer f.Close()
+
+	head := make([]byte, 12)
+	n, _ := io.ReadFull(f, head)
+	head = head[:n]
+	switch {
+	case bytes.HasPrefix(head, []byte("fLaC")):
+		f.Seek(4, io.SeekStart)
+		return readVorbis(f)
+	case bytes.HasPrefix(head, []byte("OggS")):
+		f.Seek(0, io.SeekStart)
+		return readOgg(f)
+	case len(head) >= 8 && string(head[4:8]) == "ftyp":
+		return readMP4(f)
+	}
+	t, err := readID3v2(f)
+	if err != nil && !errors.Is(err, errNoTag) {
+		return Tags{}, err
+	}
+	if t.Empty() {
+		// 退回 ID3v1（文件末尾 128 字节）
+		if v1, err := readID3v1(f); err == nil {
+			t = v1
+		}
+	}
+	return t, nil
+}
+
+var errNoTag = errors.New("no tag")
+
+// id3 帧 ID（2.3/2.4 四字符，2.2 三字符）到字段的映射
+var id3Frames = map[string]func(*Tags, string){
+	"TPE1": func(t *Tags, v string) { t.Artist = v },
+	"TP1":  func(t *Tags, v string) { t.Artist = v },
+	"TPE2": func(t *Tags, v string) { t.AlbumArtist = v },
+	"TP2":  func(t *Tags, v string) { t.AlbumArtist = v },
+	"TALB": func(t *Tags, v string) { t.Album = v },
+	"TAL":  func(t *Tags, v string) { t.Album = v },
+	"TIT2": func(t *Tags, v string) { t.Title = v },
+	"TT2":  func(t *Tags, v string) { t.Title = v },
+	"TRCK": func(t *Tags, v string) { t.Track = v },
+	"TRK":  func(t *Tags, v string) { t.Track = v },
+	"TPOS": func(t *Tags, v string) { t.Disc = v },
+	"TPA":  func(t *Tags, v string) { t.Disc = v },
+	"TCMP": func(t *Tags, v string) { t.Compilation = v == "1" },
+	"TCP":  func(t *Tags, v string) { t.Compilation = v == "1" },
+	"TCOM": func(t *Tags, v string) { t.Composer = v },
+	"TCM":  func(t *Tags, v string) { t.Composer = v },
+	"TIT1": func(t *Tags, v string) { t.Work = v }, // iTunes 12.5+ 把"作品"写在 TIT1
+	"TT1":  func(t *Tags, v string) { t.Work = v },
+	"MVNM": func(t *Tags, v string) { t.Movement = v },
+}
+
+// vorbisFields 为 FLAC Vorbis 注释字段名（大写）到字段的映射
+var vorbisFields = map[string]func(*Tags, string){
+	"ARTIST":       func(t *Tags, v string) { t.Artist = v },
+	"ALBUMARTIST":  func(t *Tags, v string) { t.AlbumArtist = v },
+	"ALBUM ARTIST": func(t *Tags, v string) { t.AlbumArtist = v },
+	"ALBUM":        func(t *Tags, v string) { t.Album = v },
+	"TITLE":        func(t *Tags, v string) { t.Title = v },
+	"TRACKNUMBER":  func(t *Tags, v string) { t.Track = v },
+	"DISCNUMBER":   func(t *Tags, v string) { t.Disc = v },
+	"TRACKTOTAL":   func(t *Tags, v string) { t.TrackTotal = v },
+	"TOTALTRACKS":  func(t *Tags, v string) { t.TrackTotal = v },
+	"COMPILATION":  func(t *Tags, v string) { t.Compilation = v == "1" },
+	"COMPOSER":     func(t *Tags, v string) { t.Composer = v },
+	"WORK":         func(t *Tags, v string) { t.Work = v },
+	"MOVEMENTNAME": func(t *Tags, v string) { t.Movement = v },
+}
+
+// readVorbis 在 "fLaC" 之后逐个读取元数据块，解析 VORBIS_COMMENT（类型 4）
+func readVorbis(r io.Reader) (Tags, error) {
+	var t Tags
+	err := readVorbisComments(r, func(k, v string) {
+		if set, known := vorbisFields[strings.ToUpper(k)]; known && v != "" {
+			set(&t, strings.TrimSpace(v))
+		}
+	})
+	return t, err
+}
+
+// readVorbisComments 读取 VORBIS_COMMENT 块，对每条注释调用 fn(键, 值)
+func readVorbisComments(r io.Reader, fn func(k, v string)) error {
+	hdr := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(r, hdr); err != nil {
+			return err
+		}
+		last, typ := hdr[0]&0x80 != 0, hdr[0]&0x7f
+		size := int(hdr[1])<<16 | int(hdr[2])<<8 | int(hdr[3])
+		if typ != 4 {
+			if _, err := io.CopyN(io.Discard, r, int64(size)); err != nil {
+				return err
+			}
+			if last {
+				return nil
+			}
+			continue
+		}
+		body := make([]byte, size)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return err
+		}
+		parseVorbisComment(body, fn)
+		return nil
+	}
+}
+
+// parseVorbisComment 解析 Vorbis 注释结构（厂商字符串、条数、逐条 "键=值"），对每条注释调用 fn
+func parseVorbisComment(body []byte, fn func(k, v string)) {
+	next := func() (string, bool) {
+		if len(body) < 4 {
+			return "", false
+		}
+		n := int(binary.LittleEndian.Uint32(body))
+		if n > len(body)-4 {
+			return "", false
+		}
+		v := string(body[4 : 4+n])
+		body = body[4+n:]
+		return v, true
+	}
+	next() // vendor
+	if len(body) < 4 {
+		return
+	}
+	count := int(binary.LittleEndian.Uint32(body))
+	body = body[4:]
+	for i := 0; i < count; i++ {
+		c, ok := next()
+		if !ok {
+			break
+		}
+		if k, v, ok := strings.Cut(c, "="); ok {
+			fn(k, v)
+		}
+	}
+}
+
+func readID3v2(r io.ReadSeeker) (Tags, error) {
+	var t Tags
+	err := readID3v2Frames(r, func(id string, data []byte) {
+		if set, ok := id3Frames[id]; ok {
+			set(&t, decodeText(data))
+		}
+	})
+	return t, err
+}
+
+// readID3v2Frames 读取 ID3v2 标签，对每个帧调用 fn(帧 ID, 帧内容)；没有标签时返回 errNoTag
+func readID3v2Frames(r io.ReadSeeker, fn func(id string, data []byte)) error {
+	header := make([]byte, 10)
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(r, header); err != nil {
+		return errNoTag
+	}
+	if string(header[:3]) != "ID3" {
+		return errNoTag
+	}
+	major := header[3]
+	flags := header[5]
+	size := syncsafe(header[6:10])
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return err
+	}
+	// 整体 unsynchronisation（2.3 及以下）
+	if flags&0x80 != 0 && major < 4 {
+		body = bytes.ReplaceAll(body, []byte{0xFF, 0x00}, []byte{0xFF})
+	}
+	pos := 0
+	// 跳过扩展头
+	if flags&0x40 != 0 && major >= 3 && len(body) >= 4 {
+		ext := int(binary.BigEndian.Uint32(body[:4]))
+		if major == 4 {
+			ext = syncsafe(body[:4])
+		} else {
+			ext += 4
+		}
+		pos = ext
+	}
+
+	idLen, hdrLen := 4, 10
+	if major == 2 {
+		idLen, hdrLen = 3, 6
+	}
+	for pos+hdrLen <= len(body) {
+		id := string(body[pos : pos+idLen])
+		if id[0] == 0 {
+			break // padding
+		}
+		var fsize int
+		switch major {
+		case 2:
+			fsize = int(body[pos+3])<<16 | int(body[pos+4])<<8 | int(body[pos+5])
+		case 4:
+			fsize = syncsafe(body[pos+4 : pos+8])
+		default:
+			fsize = int(binary.BigEndian.Uint32(body[pos+4 : pos+8]))
+		}
+		pos += hdrLen
+		if fsize <= 0 || pos+fsize > len(body) {
+			break
+		}
+		fn(id, body[pos:pos+fsize])
+		pos += fsize
+	}
+	return nil
+}
+
+func readID3v1(r io.ReadSeeker) (Tags, error) {
+	var t Tags
+	if _, err := r.Seek(-128, io.SeekEnd); err != nil {
+		return t, err
+	}
+	b := make([]byte, 128)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return t, err
+	}
+	if string(b[:3]) != "TAG" {
+		return t, errNoTag
+	}
+	field := func(p []byte) string {
+		return strings.TrimSpace(latin1(bytes.TrimRight(p, "\x00")))
+	}
+	t.Title = field(b[3:33])
+	t.Artist = field(b[33:63])
+	t.Album = field(b[63:93])
+	return t, nil
+}
+
+// decodeText 解码 ID3 文本帧（首字节为编码）
+func decodeText(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	enc, data := b[0], b[1:]
+	var s string
+	switch enc {
+	case 0:
+		s = latin1(data)
+	case 1, 2:
+		s = utf16String(data, enc == 2)
+	default:
+		s = string(data)
+	}
+	// 多值以 \x00 分隔，只取第一个
+	if i := strings.IndexByte(s, 0); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+func latin1(b []byte) string {
+	rs := make([]rune, len(b))
+	for i, c := range b {
+		rs[i] = rune(c)
+	}
+	return string(rs)
+}
+
+func utf16String(b []byte, bigEndian bool) string {
+	if len(b) >= 2 {
+		switch {
+		case b[0] == 0xFF && b[1] == 0xFE:
+			bigEndian, b = false, b[2:]
+		case b[0] == 0xFE && b[1] == 0xFF:
+			bigEndian, b = true, b[2:]
+		}
+	}
+	u := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		if bigEndian {
+			u = append(u, uint16(b[i])<<8|uint16(b[i+1]))
+		} else {
+			u = append(u, uint16(b[i+1])<<8|uint16(b[i]))
+		}
+	}
+	return string(utf16.Decode(u))
+}
+
+func syncsafe(b []byte) int {
+	return int(b[0]&0x7f)<<21 | int(b[1]&0x7f)<<14 | int(b[2]&0x7f)<<7 | int(b[3]&0x7f)
+}
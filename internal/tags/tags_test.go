@@ -0,0 +1,207 @@
+// file: internal/tags/tags_test.go
+// package: tags
+//
+// 构造最小 ID3v2.3 / ID3v1 标签、FLAC / Ogg 的 Vorbis 注释与 MP4 的 ilst，验证能正确读出艺术家/专辑/标题等。
+package tags
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func id3Frame(id string, payload []byte) []byte {
+	var b bytes.Buffer
+	b.WriteString(id)
+	_ = binary.Write(&b, binary.BigEndian, uint32(len(payload)))
+	b.Write([]byte{0, 0})
+	b.Write(payload)
+	return b.Bytes()
+}
+
+func TestReadID3v23(t *testing.T) {
+	var frames []byte
+	frames = append(frames, id3Frame("TPE1", append([]byte{3}, "周杰伦"...))...)
+	// UTF-16 带 BOM
+	frames = append(frames, id3Frame("TALB", []byte{1, 0xFF, 0xFE, 'F', 0, 'a', 0, 'n', 0, 't', 0})...)
+	frames = append(frames, id3Frame("TIT2", append([]byte{0}, "Intro"...))...)
+	size := len(frames)
+	header := []byte{'I', 'D', '3', 3, 0, 0,
+		byte(size >> 21 & 0x7f), byte(size >> 14 & 0x7f), byte(size >> 7 & 0x7f), byte(size & 0x7f)}
+
+	p := filepath.Join(t.TempDir(), "a.mp3")
+	if err := os.WriteFile(p, append(append(header, frames...), make([]byte, 64)...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadFile(p)
+	if err != nil {
+		t.Fatalf("ReadFile 错误: %v", err)
+	}
+	want := Tags{Artist: "周杰伦", Album: "Fant", Title: "Intro"}
+	if got != want {
+		t.Fatalf("期望 %#v，实际 %#v", want, got)
+	}
+}
+
+func TestReadID3v1Fallback(t *testing.T) {
+	tag := make([]byte, 128)
+	copy(tag, "TAG")
+	copy(tag[3:], "Song")
+	copy(tag[33:], "Band")
+	copy(tag[63:], "Record")
+	p := filepath.Join(t.TempDir(), "b.mp3")
+	if err := os.WriteFile(p, append(make([]byte, 200), tag...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := ReadFile(p)
+	if got.Title != "Song" || got.Artist != "Band" || got.Album != "Record" {
+		t.Fatalf("ID3v1 读取不正确: %#v", got)
+	}
+}
+
+func TestReadFLACVorbis(t *testing.T) {
+	le := func(n int) []byte { return binary.LittleEndian.AppendUint32(nil, uint32(n)) }
+	var vc bytes.Buffer
+	vc.Write(le(len("ref")))
+	vc.WriteString("ref")
+	comments := []string{"composer=Johann Sebastian Bach", "WORK=Goldberg-Variationen, BWV 988", "MOVEMENTNAME=Aria", "TITLE=Aria", "junk"}
+	vc.Write(le(len(comments)))
+	for _, c := range comments {
+		vc.Write(le(len(c)))
+		vc.WriteString(c)
+	}
+	var b bytes.Buffer
+	b.WriteString("fLaC")
+	b.Write([]byte{0, 0, 0, 34}) // STREAMINFO
+	b.Write(make([]byte, 34))
+	n := vc.Len()
+	b.Write([]byte{0x84, byte(n >> 16), byte(n >> 8), byte(n)})
+	b.Write(vc.Bytes())
+
+	p := filepath.Join(t.TempDir(), "a.flac")
+	if err := os.WriteFile(p, b.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadFile(p)
+	if err != nil {
+		t.Fatalf("ReadFile 错误: %v", err)
+	}
+	want := Tags{Title: "Aria", Composer: "Johann Sebastian Bach", Work: "Goldberg-Variationen, BWV 988", Movement: "Aria"}
+	if got != want {
+		t.Fatalf("got %#v", got)
+	}
+}
+
+func TestReadLyrics(t *testing.T) {
+	uslt := append([]byte{3}, "eng"...)
+	uslt = append(uslt, "desc\x00第一行\n第二行"...)
+	frame := id3Frame("USLT", uslt)
+	size := len(frame)
+	header := []byte{'I', 'D', '3', 3, 0, 0, 0, 0, byte(size >> 7 & 0x7f), byte(size & 0x7f)}
+	p := filepath.Join(t.TempDir(), "a.mp3")
+	if err := os.WriteFile(p, append(header, frame...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ReadLyrics(p); err != nil || got != "第一行\n第二行" {
+		t.Fatalf("ReadLyrics = %q, %v", got, err)
+	}
+
+	// UTF-16：描述以两个 0 字节结尾
+	wide := []byte{1, 'e', 'n', 'g', 0xFF, 0xFE, 0, 0, 0xFF, 0xFE, 'L', 0, 'a', 0}
+	if got := decodeUSLT(wide); got != "La" {
+		t.Fatalf("decodeUSLT(UTF-16) = %q", got)
+	}
+
+	none := filepath.Join(t.TempDir(), "b.mp3")
+	if err := os.WriteFile(none, make([]byte, 64), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ReadLyrics(none); err != nil || got != "" {
+		t.Fatalf("无标签时应返回空串: %q, %v", got, err)
+	}
+}
+
+func TestReadMP4(t *testing.T) {
+	atom := func(name string, parts ...[]byte) []byte {
+		body := bytes.Join(parts, nil)
+		return append(append(binary.BigEndian.AppendUint32(nil, uint32(8+len(body))), name...), body...)
+	}
+	data := func(typ byte, v []byte) []byte { return atom("data", []byte{0, 0, 0, typ, 0, 0, 0, 0}, v) }
+	ilst := atom("ilst",
+		atom("\xa9ART", data(1, []byte("王菲"))),
+		atom("\xa9nam", data(1, []byte("红豆"))),
+		atom("\xa9alb", data(1, []byte("唱游"))),
+		atom("trkn", data(0, []byte{0, 0, 0, 3, 0, 12, 0, 0})),
+		atom("cpil", data(21, []byte{1})),
+		atom("covr", data(13, make([]byte, 64))),
+	)
+	meta := atom("meta", []byte{0, 0, 0, 0}, atom("hdlr", make([]byte, 25)), ilst)
+	file := bytes.Join([][]byte{
+		atom("ftyp", []byte("M4A \x00\x00\x00\x00")),
+		atom("mdat", make([]byte, 100)), // moov 位于音频数据之后
+		atom("moov", atom("mvhd", make([]byte, 100)), atom("udta", meta)),
+	}, nil)
+	p := filepath.Join(t.TempDir(), "a.m4a")
+	if err := os.WriteFile(p, file, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadFile(p)
+	if err != nil {
+		t.Fatalf("ReadFile 错误: %v", err)
+	}
+	want := Tags{Artist: "王菲", Title: "红豆", Album: "唱游", Track: "3/12", Compilation: true}
+	if got != want {
+		t.Fatalf("got %#v", got)
+	}
+	if got.Completeness() != 4 || (Tags{Title: "x"}).Completeness() != 1 {
+		t.Fatalf("Completeness = %d", got.Completeness())
+	}
+}
+
+func TestReadOggOpus(t *testing.T) {
+	le := func(n int) []byte { return binary.LittleEndian.AppendUint32(nil, uint32(n)) }
+	page := func(serial uint32, packet []byte) []byte {
+		var segs []byte
+		for n := len(packet); ; n -= 255 {
+			if n < 255 {
+				segs = append(segs, byte(n))
+				break
+			}
+			segs = append(segs, 255)
+		}
+		hdr := make([]byte, 27)
+		copy(hdr, "OggS")
+		binary.LittleEndian.PutUint32(hdr[14:], serial)
+		hdr[26] = byte(len(segs))
+		return bytes.Join([][]byte{hdr, segs, packet}, nil)
+	}
+	var tagsPkt bytes.Buffer
+	tagsPkt.WriteString("OpusTags")
+	tagsPkt.Write(le(3))
+	tagsPkt.WriteString("enc")
+	comments := []string{"ARTIST=Björk", "title=Jóga", "ALBUM=Homogenic", "TRACKNUMBER=2", "COMMENT=" + string(bytes.Repeat([]byte("x"), 300))}
+	tagsPkt.Write(le(len(comments)))
+	for _, c := range comments {
+		tagsPkt.Write(le(len(c)))
+		tagsPkt.WriteString(c)
+	}
+	file := bytes.Join([][]byte{
+		page(7, []byte("OpusHead\x01\x02")),
+		page(9, []byte("other stream")), // 其它逻辑流的页应被跳过
+		page(7, tagsPkt.Bytes()),        // 超过 255 字节，跨多个分段
+	}, nil)
+	p := filepath.Join(t.TempDir(), "a.ogg")
+	if err := os.WriteFile(p, file, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadFile(p)
+	if err != nil {
+		t.Fatalf("ReadFile 错误: %v", err)
+	}
+	want := Tags{Artist: "Björk", Title: "Jóga", Album: "Homogenic", Track: "2"}
+	if got != want {
+		t.Fatalf("got %#v", got)
+	}
+}
@@ -0,0 +1,109 @@
+// file: internal/tags/track.go
+// package: tags
+//
+// 曲目编号规范化：不同抓轨软件对多碟专辑的编号写法不一，例如
+// 标签 "1-01" / "101" / "3/12"（配合碟号 "1/2"、"CD1"），或只在路径里体现 "CD1/01 标题.flac"。
+// ParseTrackID 把这些写法统一为 (碟号, 曲号)，供按标签分组与重命名时对齐。
+package tags
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TrackID 为规范化后的曲目编号；Track 为 0 表示未知
+type TrackID struct {
+	Disc  int
+	Track int
+}
+
+// Known 返回曲号是否已知
+func (id TrackID) Known() bool { return id.Track > 0 }
+
+// String 返回 "碟号-两位曲号"，如 "1-01"；未知时为空串
+func (id TrackID) String() string {
+	if !id.Known() {
+		return ""
+	}
+	return fmt.Sprintf("%d-%02d", id.Disc, id.Track)
+}
+
+var (
+	reDiscTrack = regexp.MustCompile(`^(\d{1,2})\s*[-.]\s*(\d{1,3})$`)                         // 1-01、2.05
+	reTrackOf   = regexp.MustCompile(`^(\d{1,4})(?:\s*/\s*\d+)?$`)                             // 3、03/12、101
+	reDiscTag   = regexp.MustCompile(`(?i)^(?:cd|dis[ck])?\s*[-_ ]?(\d{1,2})(?:\s*/\s*\d+)?$`) // 1/2、CD1、Disc 2
+	reDiscDir   = regexp.MustCompile(`(?i)^(?:cd|dis[ck])\s*[-_ ]?(\d{1,2})$`)                 // 路径中的 CD1、Disc 2
+	reFileTrack = regexp.MustCompile(`^(?:(\d{1,2})\s*[-.]\s*)?(\d{1,3})(?:\s*[-.)_]|\s|$)`)   // 文件名开头的编号
+)
+
+// ParseTrackID 根据曲号标签、碟号标签与文件路径推断规范化的曲目编号。
+// 优先级：标签 > 文件名；碟号缺失时依次尝试三位数曲号（101 = 第 1 碟第 1 首）、
+// 曲号中的 "碟-曲" 写法、上级目录名（CD1、Disc 2），都没有时为 1。
+func ParseTrackID(track, disc, path string) TrackID {
+	var id TrackID
+	track, disc = strings.TrimSpace(track), strings.TrimSpace(disc)
+	if m := reDiscTag.FindStringSubmatch(disc); m != nil {
+		id.Disc, _ = strconv.Atoi(m[1])
+	}
+
+	d, t := splitTrack(track)
+	if t == 0 {
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if m := reFileTrack.FindStringSubmatch(name); m != nil {
+			d, _ = strconv.Atoi(m[1])
+			t, _ = strconv.Atoi(m[2])
+			if d == 0 && len(m[2]) == 3 {
+				d, t = t/100, t%100
+			}
+		}
+	}
+	if t == 0 {
+		return TrackID{}
+	}
+	id.Track = t
+	if id.Disc == 0 {
+		id.Disc = d
+	}
+	if id.Disc == 0 {
+		if m := reDiscDir.FindStringSubmatch(filepath.Base(filepath.Dir(path))); m != nil {
+			id.Disc, _ = strconv.Atoi(m[1])
+		}
+	}
+	if id.Disc == 0 {
+		id.Disc = 1
+	}
+	return id
+}
+
+// splitTrack 解析曲号标签，返回 (碟号, 曲号)，碟号未体现时为 0
+func splitTrack(s string) (int, int) {
+	if m := reDiscTrack.FindStringSubmatch(s); m != nil {
+		d, _ := strconv.Atoi(m[1])
+		t, _ := strconv.Atoi(m[2])
+		return d, t
+	}
+	if m := reTrackOf.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if n >= 100 && n%100 != 0 {
+			return n / 100, n % 100 // 101 = 第 1 碟第 1 首
+		}
+		return 0, n
+	}
+	return 0, 0
+}
+
+// TotalTracks 返回标签记录的本碟曲目总数（TRACKTOTAL，或曲号 "3/12" 中的 12），未知时为 0
+func (t Tags) TotalTracks() int {
+	if n, err := strconv.Atoi(strings.TrimSpace(t.TrackTotal)); err == nil && n > 0 {
+		return n
+	}
+	if i := strings.Index(t.Track, "/"); i >= 0 {
+		if n, err := strconv.Atoi(strings.TrimSpace(t.Track[i+1:])); err == nil && n > 0 {
+			return n
+		}
+	}
+	return 0
+}
@@ -0,0 +1,51 @@
+// file: internal/tags/track_test.go
+// package: tags
+//
+// 测试各种抓轨软件的曲目编号写法能规范化为相同的 (碟号, 曲号)。
+package tags
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestParseTrackID(t *testing.T) {
+	cases := []struct {
+		track, disc, path string
+		want              string
+	}{
+		{"1-01", "", "x.flac", "1-01"},
+		{"101", "", "x.flac", "1-01"},
+		{"03/12", "2/2", "x.flac", "2-03"},
+		{"3", "CD2", "x.flac", "2-03"},
+		{"", "", filepath.Join("Album", "CD1", "01 Intro.flac"), "1-01"},
+		{"", "", filepath.Join("Album", "Disc 2", "05. Song.mp3"), "2-05"},
+		{"7", "", filepath.Join("Album", "CD3", "track.mp3"), "3-07"},
+		{"", "", "2-11 Finale.flac", "2-11"},
+		{"", "", "211 Finale.flac", "2-11"},
+		{"", "", "Finale.flac", ""},
+		{"100", "", "x.flac", "1-100"},
+	}
+	for _, c := range cases {
+		if got := ParseTrackID(c.track, c.disc, c.path).String(); got != c.want {
+			t.Errorf("ParseTrackID(%q, %q, %q) = %q，期望 %q", c.track, c.disc, c.path, got, c.want)
+		}
+	}
+}
+
+func TestTotalTracks(t *testing.T) {
+	cases := []struct {
+		tags Tags
+		want int
+	}{
+		{Tags{Track: "3/12"}, 12},
+		{Tags{Track: "3", TrackTotal: "10"}, 10},
+		{Tags{Track: "3"}, 0},
+		{Tags{Track: "1-01"}, 0},
+	}
+	for _, c := range cases {
+		if got := c.tags.TotalTracks(); got != c.want {
+			t.Errorf("%+v.TotalTracks() = %d，期望 %d", c.tags, got, c.want)
+		}
+	}
+}
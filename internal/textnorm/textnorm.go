@@ -0,0 +1,104 @@
+// file: internal/textnorm/textnorm.go
+// package: textnorm
+//
+// 标签文本规范化，用于模糊比较标签（不改动文件中的标签）：
+//   - 全角 ASCII / 全角空格转半角，半角片假名转全角；
+//   - 片假名统一为平假名；
+//   - 常用繁体字转简体（内置约 600 个音乐标签中常见的字，不是完整的繁简转换表）；
+//   - 拉丁字母去掉常见变音符号并转小写；
+//   - 连续空白合并为一个空格。
+//
+// 不做音译（如 "周杰伦" 与 "Jay Chou"），需要词典，超出本包范围。
+package textnorm
+
+import (
+	"strings"
+	"unicode"
+)
+
+var tradToSimp = pairMap(
+	"愛罷備貝筆畢邊變標別賓並補財參慘蒼層產長場嘗廠車徹塵陳稱誠遲齒衝蟲醜處觸傳創純詞從聰錯達帶單擔當黨導"+
+		"燈鄧敵遞點電調動凍鬥獨讀斷隊對頓奪兒爾發髮範飛費紛風豐鳳婦復複該蓋趕剛鋼綱個給宮鞏溝夠購穀顧關觀館歸"+
+		"貴國過漢號轟後護畫劃話歡環還換喚黃揮輝會繪獲擊機積極級幾際記紀繼濟價駕堅間簡見將獎講醬膠驕嬌腳覺較階"+
+		"節結潔緊僅進盡勁驚經鏡淨競舊劇據決絕軍開殼課懇塊寬礦虧擴闊蠟來藍蘭攔爛勞樂淚類離禮裡裏歷麗兩憐聯戀臉"+
+		"練糧涼輛遼療獵鄰臨靈齡嶺領劉龍樓爐蘆陸錄綠亂輪論羅邏蘿驢媽馬嗎買賣麥滿貓們夢彌謎綿麵滅廟鳴銘謀畝難腦"+
+		"惱鬧內擬鳥寧農濃諾歐盤賠噴鵬騙飄頻貧評蘋憑撲樸齊騎豈啟氣棄牽鉛錢淺槍牆強橋親輕傾請慶窮區驅權勸確讓熱"+
+		"認榮軟銳潤灑賽傘喪掃殺曬傷賞燒紹捨設攝紳審聲勝繩聖師詩獅濕時實識勢視試適釋壽書輸屬數樹帥雙誰稅順說碩"+
+		"絲飼鬆頌訴肅雖隨歲孫損縮瑣鎖態攤灘談歎嘆湯濤討騰題體條鐵聽廳頭圖團脫襪灣萬網為圍違偉衛溫聞穩問蝸烏無"+
+		"霧誤犧襲戲細蝦嚇鮮纖閒顯險縣現線鄉詳響項蕭銷曉協寫謝興選學尋訊詢壓鴉啞亞煙顏嚴鹽艷驗陽養樣藥爺頁業葉"+
+		"醫儀億憶藝義議異譯陰銀飲隱櫻嬰應鷹營贏擁湧優憂郵遊猶魚漁與語獄預淵園員圓緣遠願約躍閱雲運韻雜災載讚贊"+
+		"髒棗責擇澤賊贈齋戰張漲帳賬趙這貞針陣鎮爭徵證織職執紙誌製質鐘鍾種眾週晝豬諸燭囑築專轉賺裝壯狀準濁資總"+
+		"縱鄒組鑽蹤臺颱檯幣夥隻於嶽蓮傑倫鄭楊吳韋蘇譚閻龔馮賴盧許鄺華紅懷淒寶誕詠輯麼遙蟬",
+	"爱罢备贝笔毕边变标别宾并补财参惨苍层产长场尝厂车彻尘陈称诚迟齿冲虫丑处触传创纯词从聪错达带单担当党导"+
+		"灯邓敌递点电调动冻斗独读断队对顿夺儿尔发发范飞费纷风丰凤妇复复该盖赶刚钢纲个给宫巩沟够购谷顾关观馆归"+
+		"贵国过汉号轰后护画划话欢环还换唤黄挥辉会绘获击机积极级几际记纪继济价驾坚间简见将奖讲酱胶骄娇脚觉较阶"+
+		"节结洁紧仅进尽劲惊经镜净竞旧剧据决绝军开壳课恳块宽矿亏扩阔蜡来蓝兰拦烂劳乐泪类离礼里里历丽两怜联恋脸"+
+		"练粮凉辆辽疗猎邻临灵龄岭领刘龙楼炉芦陆录绿乱轮论罗逻萝驴妈马吗买卖麦满猫们梦弥谜绵面灭庙鸣铭谋亩难脑"+
+		"恼闹内拟鸟宁农浓诺欧盘赔喷鹏骗飘频贫评苹凭扑朴齐骑岂启气弃牵铅钱浅枪墙强桥亲轻倾请庆穷区驱权劝确让热"+
+		"认荣软锐润洒赛伞丧扫杀晒伤赏烧绍舍设摄绅审声胜绳圣师诗狮湿时实识势视试适释寿书输属数树帅双谁税顺说硕"+
+		"丝饲松颂诉肃虽随岁孙损缩琐锁态摊滩谈叹叹汤涛讨腾题体条铁听厅头图团脱袜湾万网为围违伟卫温闻稳问蜗乌无"+
+		"雾误牺袭戏细虾吓鲜纤闲显险县现线乡详响项萧销晓协写谢兴选学寻讯询压鸦哑亚烟颜严盐艳验阳养样药爷页业叶"+
+		"医仪亿忆艺义议异译阴银饮隐樱婴应鹰营赢拥涌优忧邮游犹鱼渔与语狱预渊园员圆缘远愿约跃阅云运韵杂灾载赞赞"+
+		"脏枣责择泽贼赠斋战张涨帐账赵这贞针阵镇争征证织职执纸志制质钟钟种众周昼猪诸烛嘱筑专转赚装壮状准浊资总"+
+		"纵邹组钻踪台台台币伙只于岳莲杰伦郑杨吴韦苏谭阎龚冯赖卢许邝华红怀凄宝诞咏辑么遥蝉",
+)
+
+// latinFold 为常见带变音符号的拉丁字母到基本字母的映射
+var latinFold = pairMap(
+	"ÀÁÂÃÄÅàáâãäåÇçÈÉÊËèéêëÌÍÎÏìíîïÑñÒÓÔÕÖØòóôõöøÙÚÛÜùúûüÝýÿŠšŽžČčĆćŁłŃńŚśŹźŻżĘęĄąŘřŮůĚěŇňŤťĎďŐőŰű",
+	"AAAAAAaaaaaaCcEEEEeeeeIIIIiiiiNnOOOOOOooooooUUUUuuuuYyySsZzCcCcLlNnSsZzZzEeAaRrUuEeNnTtDdOoUu",
+)
+
+// halfKana 为半角片假名（U+FF66..U+FF9D）对应的全角片假名
+var halfKana = []rune("ヲァィゥェォャュョッーアイウエオカキクケコサシスセソタチツテトナニヌネノハヒフヘホマミムメモヤユヨラリルレロワン")
+
+func pairMap(from, to string) map[rune]rune {
+	f, t := []rune(from), []rune(to)
+	if len(f) != len(t) {
+		panic("textnorm: 映射表长度不一致")
+	}
+	m := make(map[rune]rune, len(f))
+	for i, r := range f {
+		m[r] = t[i]
+	}
+	return m
+}
+
+// Normalize 返回用于比较的规范化文本
+func Normalize(s string) string {
+	rs := []rune(s)
+	out := make([]rune, 0, len(rs))
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		switch {
+		case r == 0x3000:
+			r = ' '
+		case r >= 0xFF01 && r <= 0xFF5E: // 全角 ASCII
+			r -= 0xFEE0
+		case r >= 0xFF66 && r <= 0xFF9D: // 半角片假名，合并后续的浊点/半浊点
+			r = halfKana[r-0xFF66]
+			if i+1 < len(rs) && (rs[i+1] == 0xFF9E || rs[i+1] == 0xFF9F) {
+				if rs[i+1] == 0xFF9E && r == 'ウ' {
+					r = 'ヴ'
+				} else {
+					r += rs[i+1] - 0xFF9D // 浊音 +1，半浊音 +2
+				}
+				i++
+			}
+		}
+		if r >= 0x30A1 && r <= 0x30F6 { // 片假名 -> 平假名
+			r -= 0x60
+		}
+		if v, ok := tradToSimp[r]; ok {
+			r = v
+		}
+		if v, ok := latinFold[r]; ok {
+			r = v
+		}
+		out = append(out, unicode.ToLower(r))
+	}
+	return strings.Join(strings.Fields(string(out)), " ")
+}
+
+// Equal 返回两段文本规范化后是否相同
+func Equal(a, b string) bool { return Normalize(a) == Normalize(b) }
@@ -0,0 +1,52 @@
+// file: internal/textnorm/textnorm_test.go
+// package: textnorm
+//
+// 测试全角/半角、繁简、假名与变音符号的规范化。
+package textnorm
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	cases := []struct{ a, b string }{
+		{"周杰倫　Ｊａｙ", "周杰伦 jay"},
+		{"愛與憂愁（Live）", "爱与忧愁(live)"},
+		{"ｶﾞﾝﾀﾞﾑ", "がんだむ"},
+		{"コーヒー", "こーひー"},
+		{"Beyoncé  Déjà Vu", "beyonce deja vu"},
+		{"張學友 - 吻別", "张学友 - 吻别"},
+	}
+	for _, c := range cases {
+		if !Equal(c.a, c.b) {
+			t.Errorf("%q 与 %q 应规范化为相同文本：%q / %q", c.a, c.b, Normalize(c.a), Normalize(c.b))
+		}
+	}
+	if Equal("十年", "千年") {
+		t.Error("不同文本不应相等")
+	}
+}
+
+func TestTitleKey(t *testing.T) {
+	same := []string{
+		"Umbrella",
+		"Umbrella (feat. JAY-Z)",
+		"Umbrella [Explicit]",
+		"Umbrella (Album Version)",
+		"Umbrella - 2007 Remaster",
+		"Umbrella ft. Jay-Z",
+		"Umbrella（Remastered 2011）",
+	}
+	for _, s := range same {
+		if k := TitleKey(s); k != "umbrella" {
+			t.Errorf("TitleKey(%q) = %q", s, k)
+		}
+	}
+	if TitleKey("Love (Live)") == TitleKey("Love (Reprise)") {
+		t.Error("未知括号内容应保留")
+	}
+	if err := RegisterTitlePattern(`[(\[]reprise[)\]]`); err != nil {
+		t.Fatal(err)
+	}
+	if TitleKey("Love (Reprise)") != "love" {
+		t.Errorf("注册的模式未生效: %q", TitleKey("Love (Reprise)"))
+	}
+}
@@ -0,0 +1,79 @@
+// file: internal/textnorm/title.go
+// package: textnorm
+//
+// 标题比较键：去掉 "(feat. X)"、"[Explicit]"、"(Album Version)" 一类不影响曲目身份的后缀，
+// 再做 Normalize。后缀模式为正则（在规范化后的小写文本上匹配），可通过 RegisterTitlePattern 追加。
+package textnorm
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// DefaultTitlePatterns 为内置的后缀模式
+var DefaultTitlePatterns = []string{
+	`[(\[]\s*(feat\.?|ft\.?|featuring|with)\s[^)\]]*[)\]]`,
+	`\s(feat\.?|ft\.?|featuring)\s.*$`,
+	`[(\[]\s*(explicit|clean|album version|single version|radio edit|original mix|mono|stereo|bonus track|live)\s*[)\]]`,
+	`[(\[]\s*(\d{4}\s+)?(digital(ly)?\s+)?remaster(ed)?(\s+\d{4})?(\s+version)?\s*[)\]]`,
+	`\s-\s(\d{4}\s+)?(digital(ly)?\s+)?remaster(ed)?(\s+\d{4})?(\s+version)?$`,
+}
+
+var (
+	titleMu       sync.RWMutex
+	titlePatterns = mustCompileAll(DefaultTitlePatterns)
+)
+
+func mustCompileAll(patterns []string) []*regexp.Regexp {
+	out := make([]*regexp.Regexp, len(patterns))
+	for i, p := range patterns {
+		out[i] = regexp.MustCompile(p)
+	}
+	return out
+}
+
+// RegisterTitlePattern 追加一个后缀模式
+func RegisterTitlePattern(pattern string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	titleMu.Lock()
+	defer titleMu.Unlock()
+	titlePatterns = append(titlePatterns, re)
+	return nil
+}
+
+// LoadTitlePatterns 从文件读取模式（每行一个正则，# 开头为注释）并注册
+func LoadTitlePatterns(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := RegisterTitlePattern(line); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+// TitleKey 返回用于比较的标题键
+func TitleKey(title string) string {
+	s := Normalize(title)
+	titleMu.RLock()
+	defer titleMu.RUnlock()
+	for _, re := range titlePatterns {
+		s = re.ReplaceAllString(s, " ")
+	}
+	return strings.Join(strings.Fields(s), " ")
+}
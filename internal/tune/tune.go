@@ -0,0 +1,143 @@
+// file: internal/tune/tune.go
+// package: tune
+//
+// 运行时自动调整解码并发：Limiter 是容量可动态调整的信号量；
+// Tuner 周期性测量吞吐量（文件/秒），用爬山法增减并发——吞吐提升就沿同方向继续，
+// 下降就反向。SSD 上通常会升到 CPU 上限，机械盘 / NAS 上会因 I/O 等待回落到较小值。
+package tune
+
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Limiter 为可调容量的信号量
+type Limiter struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	limit    int
+	active   int
+	min, max int
+}
+
+// NewLimiter 创建初始容量为 initial、允许范围 [min, max] 的 Limiter
+func NewLimiter(initial, min, max int) *Limiter {
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+	l := &Limiter{min: min, max: max}
+	l.cond = sync.NewCond(&l.mu)
+	l.limit = clamp(initial, min, max)
+	return l
+}
+
+// Acquire 阻塞直到在途任务数小于当前容量
+func (l *Limiter) Acquire() {
+	l.mu.Lock()
+	for l.active >= l.limit {
+		l.cond.Wait()
+	}
+	l.active++
+	l.mu.Unlock()
+}
+
+// Release 结束一个任务
+func (l *Limiter) Release() {
+	l.mu.Lock()
+	l.active--
+	l.mu.Unlock()
+	l.cond.Signal()
+}
+
+// Limit 返回当前容量
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// SetLimit 调整容量（会被限制在 [min, max]），返回实际生效的值
+func (l *Limiter) SetLimit(n int) int {
+	l.mu.Lock()
+	l.limit = clamp(n, l.min, l.max)
+	n = l.limit
+	l.mu.Unlock()
+	l.cond.Broadcast()
+	return n
+}
+
+func clamp(n, lo, hi int) int {
+	if n < lo {
+		return lo
+	}
+	if n > hi {
+		return hi
+	}
+	return n
+}
+
+// Tuner 根据吞吐量调整 Limiter 容量
+type Tuner struct {
+	l         *Limiter
+	completed atomic.Int64
+
+	lastRate float64
+	dir      int // +1 增加并发，-1 减少
+}
+
+// NewTuner 创建 Tuner，初始方向为增加并发
+func NewTuner(l *Limiter) *Tuner { return &Tuner{l: l, dir: 1} }
+
+// Done 记录一个完成的任务
+func (t *Tuner) Done() { t.completed.Add(1) }
+
+// Run 每隔 interval 测量一次吞吐量并调整并发，直到 stop 关闭。
+// logger 非 nil 时在并发变化时输出说明。
+func (t *Tuner) Run(stop <-chan struct{}, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	last := time.Now()
+	lastCount := t.completed.Load()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			count := t.completed.Load()
+			rate := float64(count-lastCount) / now.Sub(last).Seconds()
+			last, lastCount = now, count
+			before := t.l.Limit()
+			after := t.step(rate)
+			if logger != nil && after != before {
+				logger.Info("自动调优：调整解码并发", "files_per_sec", rate, "from", before, "to", after)
+			}
+		}
+	}
+}
+
+// step 根据本周期吞吐量做一次爬山决策，返回新的并发数
+func (t *Tuner) step(rate float64) int {
+	cur := t.l.Limit()
+	switch {
+	case t.lastRate == 0:
+		// 第一个周期只记录基线，并朝初始方向试探
+	case rate < t.lastRate*0.95:
+		t.dir = -t.dir // 变差：反向
+	case rate <= t.lastRate*1.05:
+		// 变化不明显：保持当前并发
+		t.lastRate = rate
+		return cur
+	}
+	t.lastRate = rate
+	next := t.l.SetLimit(cur + t.dir)
+	if next == cur {
+		// 已到边界，下一次朝反方向试探
+		t.dir = -t.dir
+	}
+	return next
+}
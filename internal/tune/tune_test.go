@@ -0,0 +1,31 @@
+// file: internal/tune/tune_test.go
+// package: tune
+//
+// 测试爬山决策：吞吐提升时继续增加并发，下降时反向，且不越界。
+package tune
+
+import "testing"
+
+func TestTunerStep(t *testing.T) {
+	l := NewLimiter(2, 1, 4)
+	tu := NewTuner(l)
+
+	if got := tu.step(10); got != 3 { // 基线后试探增加
+		t.Fatalf("期望并发 3，实际 %d", got)
+	}
+	if got := tu.step(15); got != 4 { // 吞吐提升，继续增加
+		t.Fatalf("期望并发 4，实际 %d", got)
+	}
+	if got := tu.step(15.5); got != 4 { // 变化不明显，保持
+		t.Fatalf("期望保持并发 4，实际 %d", got)
+	}
+	if got := tu.step(8); got != 3 { // 吞吐下降，反向
+		t.Fatalf("期望并发 3，实际 %d", got)
+	}
+	for i := 0; i < 5; i++ {
+		tu.step(float64(100 * (i + 2))) // 持续提升，沿减少方向直到下限
+	}
+	if l.Limit() < 1 || l.Limit() > 4 {
+		t.Fatalf("并发越界: %d", l.Limit())
+	}
+}
@@ -0,0 +1,47 @@
+// file: internal/upgrade/upgrade.go
+// package: upgrade
+//
+// 目标目录升级（-upgrade-dst / -upgrade-only）：按分组决定保留文件替换目标目录中的哪个旧版本，
+// 以及只升级不导入时哪些保留文件不复制。
+package upgrade
+
+import (
+	"deduplicateMusic/internal/dedup"
+	"deduplicateMusic/internal/report"
+)
+
+// Plan 为一组的升级决定
+type Plan struct {
+	Keep     string          // 组的保留文件
+	Replaced *dedup.FileMeta // 保留文件要替换的目标目录中的旧版本；nil 表示不替换
+	Only     bool            // -upgrade-only：不替换旧版本的成员不导入
+}
+
+// For 返回分组 g 的升级决定：保留文件是新副本（不在目标目录与参考库中）而组内有位于目标目录、
+// 且未被改动的重复文件时，替换其中第一个。inDst、inRef、changed 分别为目标目录中的文件、
+// 参考库中的文件与决策后被改动的文件。
+func For(g dedup.Group, inDst, inRef, changed map[string]bool, only bool) Plan {
+	p := Plan{Keep: g.Keep.Path, Only: only}
+	if inDst[g.Keep.Path] || inRef[g.Keep.Path] {
+		return p
+	}
+	for i := range g.Duplicates {
+		if inDst[g.Duplicates[i].Path] && !changed[g.Duplicates[i].Path] {
+			p.Replaced = &g.Duplicates[i].FileMeta
+			break
+		}
+	}
+	return p
+}
+
+// Action 返回要导入的成员 path 的处理动作：替换旧版本为 report.ActionUpgraded，
+// 只升级不导入时其余成员为 report.ActionSkipped，照常复制为空串
+func (p Plan) Action(path string) string {
+	switch {
+	case p.Replaced != nil && path == p.Keep:
+		return report.ActionUpgraded
+	case p.Only:
+		return report.ActionSkipped
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+// file: internal/upgrade/upgrade_test.go
+// package: upgrade
+//
+// 测试 -upgrade-only：目标目录中没有的曲目跳过，src 中质量更好的版本替换目标目录中的旧副本。
+package upgrade
+
+import (
+	"testing"
+
+	"deduplicateMusic/internal/dedup"
+	"deduplicateMusic/internal/report"
+)
+
+func TestUpgradeOnly(t *testing.T) {
+	files := []dedup.FileMeta{
+		{Path: "src/a.flac", Size: 30 << 20, FP: 0x0f0f0f0f0f0f0f0f}, // 质量更好的新版本
+		{Path: "dst/a.mp3", Size: 5 << 20, FP: 0x0f0f0f0f0f0f0f0f},   // 目标目录中的旧副本
+		{Path: "src/b.mp3", Size: 6 << 20, FP: 0xf0f0f0f0f0f0f0f0},   // 只在 src 中的曲目
+		{Path: "dst/c.flac", Size: 25 << 20, FP: 0x3333333333333333}, // 目标目录中已是最佳版本
+		{Path: "src/c.mp3", Size: 4 << 20, FP: 0x3333333333333333},
+	}
+	inDst := map[string]bool{"dst/a.mp3": true, "dst/c.flac": true}
+	groups := dedup.GroupFiles(files, 4)
+	if len(groups) != 3 {
+		t.Fatalf("期望 3 组，实际 %d", len(groups))
+	}
+	got := map[string]Plan{}
+	for _, g := range groups {
+		got[g.Keep.Path] = For(g, inDst, nil, nil, true)
+	}
+
+	if p := got["src/a.flac"]; p.Replaced == nil || p.Replaced.Path != "dst/a.mp3" || p.Action("src/a.flac") != report.ActionUpgraded {
+		t.Fatalf("src/a.flac 应替换 dst/a.mp3: %+v", p)
+	}
+	if p := got["src/b.mp3"]; p.Replaced != nil || p.Action("src/b.mp3") != report.ActionSkipped {
+		t.Fatalf("只在 src 中的曲目应跳过: %+v, action=%q", p, p.Action("src/b.mp3"))
+	}
+	if p, ok := got["dst/c.flac"]; !ok || p.Replaced != nil {
+		t.Fatalf("目标目录中已是最佳版本时不应替换: %+v", p)
+	}
+
+	// 被改动的旧副本不替换；不加 -upgrade-only 时新曲目照常导入
+	for _, g := range groups {
+		if g.Keep.Path != "src/a.flac" {
+			continue
+		}
+		if p := For(g, inDst, nil, map[string]bool{"dst/a.mp3": true}, true); p.Replaced != nil || p.Action(g.Keep.Path) != report.ActionSkipped {
+			t.Fatalf("旧副本已被改动: %+v", p)
+		}
+	}
+	for _, g := range groups {
+		if g.Keep.Path == "src/b.mp3" {
+			if a := For(g, inDst, nil, nil, false).Action(g.Keep.Path); a != "" {
+				t.Fatalf("-upgrade-dst 时新曲目应照常导入，实际 %q", a)
+			}
+		}
+	}
+}
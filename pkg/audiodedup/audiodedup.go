@@ -0,0 +1,66 @@
+// file: pkg/audiodedup/audiodedup.go
+// package: audiodedup
+//
+// 对外公开的扩展 API：嵌入方（或 Go 插件）可在此注册自定义保留策略、匹配器与排序属性，
+// 注册后即可通过命令行 -keep-policy / -matcher 按名称选用；也可注册找不到 ffmpeg 时使用的后备解码器。
+//
+// Go 插件示例（go build -buildmode=plugin）：
+//
+//	package main
+//
+//	import "deduplicateMusic/pkg/audiodedup"
+//
+//	func init() {
+//		audiodedup.RegisterKeepPolicy("smallest", audiodedup.KeepPolicyFunc(
+//			func(a, b audiodedup.FileMeta) bool { return a.Size < b.Size }))
+//	}
+package audiodedup
+
+import (
+	"fmt"
+	"plugin"
+
+	"deduplicateMusic/internal/dedup"
+	"deduplicateMusic/internal/fingerprint"
+)
+
+// 以下类型与 internal/dedup 中的定义一致（类型别名）
+type (
+	FileMeta       = dedup.FileMeta
+	KeepPolicy     = dedup.KeepPolicy
+	KeepPolicyFunc = dedup.KeepPolicyFunc
+	Matcher        = dedup.Matcher
+	MatcherFunc    = dedup.MatcherFunc
+	MatcherFactory = dedup.MatcherFactory
+	SortKey        = dedup.SortKey
+	FileGroup      = dedup.Group  // 一组重复文件：保留文件、受保护的成员与重复文件
+	Member         = dedup.Member // 组内的重复文件及其与保留文件的距离
+
+	FallbackDecoder = fingerprint.FallbackDecoder
+)
+
+// RegisterKeepPolicy 注册命名保留策略
+func RegisterKeepPolicy(name string, p KeepPolicy) { dedup.RegisterKeepPolicy(name, p) }
+
+// RegisterMatcher 注册命名匹配器工厂（参数为 -threshold 的值）
+func RegisterMatcher(name string, f MatcherFactory) { dedup.RegisterMatcher(name, f) }
+
+// RegisterSortKey 注册可用于排序表达式（如 "mykey desc, path asc"）的属性
+func RegisterSortKey(name string, k SortKey) { dedup.RegisterSortKey(name, k) }
+
+// RegisterFallbackDecoder 注册后备解码器：找不到 ffmpeg 时用它解码其支持的格式，
+// 如嵌入以 WASM 编译的解码器，在无法安装 ffmpeg 的系统上也能处理 AAC 等格式。
+// 本包自带 MP3 后备解码器；注册的解码器优先于自带的
+func RegisterFallbackDecoder(d FallbackDecoder) { fingerprint.RegisterFallbackDecoder(d) }
+
+// ParseOrder 解析排序表达式为保留策略
+func ParseOrder(expr string) (KeepPolicy, error) { return dedup.ParseOrder(expr) }
+
+// LoadPlugin 打开 Go 插件；插件应在 init() 中调用本包的 Register* 函数完成注册。
+// 注意：Go 插件仅在支持 cgo 的 Linux/macOS 上可用，且须与主程序使用相同的工具链与依赖版本构建。
+func LoadPlugin(path string) error {
+	if _, err := plugin.Open(path); err != nil {
+		return fmt.Errorf("加载插件 %s 失败: %w", path, err)
+	}
+	return nil
+}
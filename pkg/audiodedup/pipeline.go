@@ -0,0 +1,212 @@
+// file: pkg/audiodedup/pipeline.go
+// package: audiodedup
+//
+// 可编程的去重流水线：扫描（Scan）→ 计算指纹（Fingerprint）→ 分组（Group）→ 执行（Execute），
+// 与命令行的主流程一致，供其它 Go 程序直接调用。耗时的步骤接受 context，取消后尽快返回 ctx.Err()，
+// 已完成的部分结果照常返回。只处理本地文件；缓存、报告、原地删除等命令行功能不在此列。
+//
+//	files, _ := audiodedup.Scan(ctx, "/music/incoming")
+//	opts := audiodedup.DefaultOptions()
+//	metas, failed, err := audiodedup.Fingerprint(ctx, files, opts)
+//	groups := audiodedup.Group(metas, opts)
+//	placed, err := audiodedup.Execute(ctx, groups, audiodedup.ExecuteOptions{Dst: "/music/library"})
+package audiodedup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"deduplicateMusic/internal/copyutil"
+	"deduplicateMusic/internal/dedup"
+	"deduplicateMusic/internal/fingerprint"
+	"deduplicateMusic/internal/formats"
+	"deduplicateMusic/internal/scanner"
+	"deduplicateMusic/internal/tags"
+)
+
+// Options 为计算指纹与分组的参数；DefaultOptions 返回与命令行默认值一致的参数
+type Options struct {
+	Seconds   int        // 用于指纹的音频时长（秒），<= 0 时为 8
+	Threshold int        // 相似度阈值（汉明距离），0 表示只认指纹完全相同的文件；命令行默认为 8
+	Workers   int        // 并发解码数，默认 CPU 核数
+	Tags      bool       // 是否读取标签（供按标签的保留策略与调用方使用）
+	Policy    KeepPolicy // 保留策略，nil 时与命令行默认一致
+	Matcher   Matcher    // 匹配器，nil 时按 Threshold 比较汉明距离
+}
+
+// DefaultOptions 返回命令行的默认参数：指纹取 8 秒、阈值 8、并发数为 CPU 核数
+func DefaultOptions() Options {
+	return Options{Seconds: 8, Threshold: 8, Workers: runtime.NumCPU()}
+}
+
+func (o Options) withDefaults() Options {
+	if o.Seconds <= 0 {
+		o.Seconds = 8
+	}
+	if o.Workers <= 0 {
+		o.Workers = runtime.NumCPU()
+	}
+	return o
+}
+
+// FileError 为计算指纹失败的文件
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (e FileError) Error() string { return e.Path + ": " + e.Err.Error() }
+
+// Scan 递归扫描 root，返回支持的音频文件（按路径排序）。无法访问的子目录被跳过；
+// ctx 取消后停止遍历并返回 ctx.Err()。
+func Scan(ctx context.Context, root string) ([]string, error) {
+	entries, _, err := scanner.ScanEntriesContext(ctx, root, formats.Extensions())
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, len(entries))
+	for i, e := range entries {
+		files[i] = e.Path
+	}
+	return files, nil
+}
+
+// Fingerprint 并发解码 paths 并计算指纹，返回成功的文件（顺序与 paths 一致）与失败的文件。
+// ctx 取消后不再开始新的文件并终止正在运行的 ffmpeg，返回已得到的结果与 ctx.Err()；
+// 被中断的文件不算失败，不出现在任何一个结果中。
+func Fingerprint(ctx context.Context, paths []string, opts Options) ([]FileMeta, []FileError, error) {
+	opts = opts.withDefaults()
+	fo := fingerprint.Options{Seconds: opts.Seconds, Bits: 64}
+	metas := make([]FileMeta, len(paths))
+	errs := make([]error, len(paths))
+	done := make([]bool, len(paths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < opts.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				p := paths[i]
+				done[i] = true
+				fi, err := os.Stat(p)
+				if err != nil {
+					errs[i] = err
+					continue
+				}
+				an, err := fingerprint.AnalyzeFileContext(ctx, p, fo)
+				if ctx.Err() != nil {
+					done[i] = false // 被中断
+					continue
+				}
+				if err != nil {
+					errs[i] = err
+					continue
+				}
+				metas[i] = FileMeta{Path: p, Size: fi.Size(), FP: an.FP, ModTime: fi.ModTime()}
+				if opts.Tags {
+					metas[i].Tags, _ = tags.ReadFile(p)
+				}
+			}
+		}()
+	}
+send:
+	for i := range paths {
+		if ctx.Err() != nil {
+			break // 两个分支同时就绪时 select 随机选择，先检查一次
+		}
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	var ok []FileMeta
+	var failed []FileError
+	for i := range paths {
+		switch {
+		case !done[i]:
+		case errs[i] != nil:
+			failed = append(failed, FileError{Path: paths[i], Err: errs[i]})
+		default:
+			ok = append(ok, metas[i])
+		}
+	}
+	return ok, failed, ctx.Err()
+}
+
+// Group 按 opts 把文件分组并选出每组的保留文件；组按保留文件路径排序，ID 从 1 开始。
+// 只有一个文件的组（没有重复）同样返回，Duplicates 为空。
+func Group(files []FileMeta, opts Options) []FileGroup {
+	opts = opts.withDefaults()
+	return dedup.GroupWith(files, dedup.Options{Threshold: opts.Threshold, Policy: opts.Policy, Matcher: opts.Matcher})
+}
+
+// ExecuteOptions 为 Execute 的参数
+type ExecuteOptions struct {
+	Dst         string // 目标目录，不存在时创建
+	Mode        string // 放入方式：copy（默认）、move、hardlink、symlink
+	OnCollision string // 目标文件名冲突时的处理：rename（默认）、skip、error、overwrite
+}
+
+// Placed 为一个保留文件的执行结果
+type Placed struct {
+	Source   string
+	Target   string // 目标路径；冲突按 skip / error 处理时为空
+	Collided bool   // 目标文件名与其它文件冲突
+	Err      error
+}
+
+// Execute 把各组的保留文件（及受保护的成员）平铺放入 opts.Dst，同名文件按 opts.OnCollision 处理。
+// 重复文件不做任何改动，由调用方决定如何处理。ctx 取消后不再处理新的组，返回已处理的结果与 ctx.Err()；
+// 只有参数无效或无法创建目标目录时返回其它错误，单个文件的失败记在 Placed.Err 中。
+func Execute(ctx context.Context, groups []FileGroup, opts ExecuteOptions) ([]Placed, error) {
+	if opts.Mode == "" {
+		opts.Mode = string(copyutil.ModeCopy)
+	}
+	if opts.OnCollision == "" {
+		opts.OnCollision = string(copyutil.CollisionRename)
+	}
+	mode, err := copyutil.ParseMode(opts.Mode)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := copyutil.ParseCollisionPolicy(opts.OnCollision)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(opts.Dst, 0o755); err != nil {
+		return nil, err
+	}
+	caseSensitive, err := copyutil.CaseSensitive(opts.Dst)
+	if err != nil {
+		caseSensitive = true
+	}
+	names := copyutil.NewNames(caseSensitive)
+	var placed []Placed
+	for _, g := range groups {
+		if err := ctx.Err(); err != nil {
+			return placed, err
+		}
+		for _, m := range append([]FileMeta{g.Keep}, g.Protected...) {
+			want := filepath.Join(opts.Dst, filepath.Base(m.Path))
+			target, collided := names.Resolve(want, m.Path, m.Size, policy)
+			p := Placed{Source: m.Path, Target: target, Collided: collided}
+			switch {
+			case target == "" && policy == copyutil.CollisionError:
+				p.Err = fmt.Errorf("目标文件 %s 与其它文件同名", want)
+			case target != "":
+				p.Err = copyutil.Transfer(mode, m.Path, target, nil)
+			}
+			placed = append(placed, p)
+		}
+	}
+	return placed, nil
+}
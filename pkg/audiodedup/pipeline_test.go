@@ -0,0 +1,136 @@
+// file: pkg/audiodedup/pipeline_test.go
+// package: audiodedup
+//
+// 用内置解码器可解码的 WAV 测试公开流水线：扫描、计算指纹、分组、放入目标目录，
+// 取消后的行为（含终止正在运行的慢速 ffmpeg），以及阈值 0 只把指纹完全相同的文件分为一组。
+package audiodedup
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// writeWAV 写出 8kHz 单声道 16 位 WAV：2 秒的正弦波组合，freqs 不同即为不同的曲目
+func writeWAV(t *testing.T, path string, freqs ...float64) {
+	t.Helper()
+	const rate, n = 8000, 16000
+	var data bytes.Buffer
+	for i := 0; i < n; i++ {
+		v := 0.0
+		for j, f := range freqs {
+			v += math.Sin(2*math.Pi*f*float64(i)/rate) * math.Sin(math.Pi*float64(i)/float64(n)*float64(j+1))
+		}
+		_ = binary.Write(&data, binary.LittleEndian, int16(v/float64(len(freqs))*8000))
+	}
+	var b bytes.Buffer
+	le := func(v any) { _ = binary.Write(&b, binary.LittleEndian, v) }
+	b.WriteString("RIFF")
+	le(uint32(36 + data.Len()))
+	b.WriteString("WAVEfmt ")
+	le(uint32(16))
+	le(uint16(1))
+	le(uint16(1))
+	le(uint32(rate))
+	le(uint32(rate * 2))
+	le(uint16(2))
+	le(uint16(16))
+	b.WriteString("data")
+	le(uint32(data.Len()))
+	b.Write(data.Bytes())
+	if err := os.WriteFile(path, b.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	src, dst := t.TempDir(), filepath.Join(t.TempDir(), "out")
+	writeWAV(t, filepath.Join(src, "a.wav"), 440, 660)
+	if err := os.Mkdir(filepath.Join(src, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeWAV(t, filepath.Join(src, "sub", "a.wav"), 440, 660)
+	writeWAV(t, filepath.Join(src, "b.wav"), 120, 1830, 310)
+	if err := os.WriteFile(filepath.Join(src, "c.wav"), []byte("not audio"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	files, err := Scan(ctx, src)
+	if err != nil || len(files) != 4 {
+		t.Fatalf("Scan = %v, %v", files, err)
+	}
+	metas, failed, err := Fingerprint(ctx, files, Options{Workers: 2})
+	if err != nil || len(metas) != 3 || len(failed) != 1 || filepath.Base(failed[0].Path) != "c.wav" {
+		t.Fatalf("Fingerprint = %d 个, 失败 %v, %v", len(metas), failed, err)
+	}
+	groups := Group(metas, DefaultOptions())
+	if len(groups) != 2 {
+		t.Fatalf("Group = %+v", groups)
+	}
+	placed, err := Execute(ctx, groups, ExecuteOptions{Dst: dst})
+	if err != nil || len(placed) != 2 {
+		t.Fatalf("Execute = %+v, %v", placed, err)
+	}
+	for _, p := range placed {
+		if p.Err != nil || p.Collided {
+			t.Fatalf("放入失败: %+v", p)
+		}
+	}
+	if entries, _ := os.ReadDir(dst); len(entries) != 2 {
+		t.Fatalf("目标目录应有 2 个文件，实际 %d", len(entries))
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if metas, _, err := Fingerprint(cancelled, files, Options{}); !errors.Is(err, context.Canceled) || len(metas) != 0 {
+		t.Fatalf("取消后 Fingerprint = %d 个, %v", len(metas), err)
+	}
+	if _, err := Execute(cancelled, groups, ExecuteOptions{Dst: dst, Mode: "rename"}); err == nil {
+		t.Fatalf("无效的放入方式应报错")
+	}
+}
+
+func TestGroupExactThreshold(t *testing.T) {
+	files := []FileMeta{{Path: "/a.wav", FP: 0xF0}, {Path: "/b.wav", FP: 0xF0}, {Path: "/c.wav", FP: 0xF1}}
+	if groups := Group(files, Options{}); len(groups) != 2 {
+		t.Fatalf("阈值 0 时距离为 1 的文件不应同组: %+v", groups)
+	}
+	if groups := Group(files, DefaultOptions()); len(groups) != 1 || len(groups[0].Duplicates) != 2 {
+		t.Fatalf("默认阈值 8 时三个文件应同组: %+v", groups)
+	}
+}
+
+func TestFingerprintCancelKillsDecoder(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("假 ffmpeg 为 shell 脚本")
+	}
+	// 假 ffmpeg：一直不返回，模拟很慢的解码
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "ffmpeg"), []byte("#!/bin/sh\nexec sleep 30\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	p := filepath.Join(t.TempDir(), "slow.mp3")
+	if err := os.WriteFile(p, []byte("ID3"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	metas, failed, err := Fingerprint(ctx, []string{p}, Options{Workers: 1})
+	if !errors.Is(err, context.DeadlineExceeded) || len(metas) != 0 || len(failed) != 0 {
+		t.Fatalf("取消后 Fingerprint = %d 个, 失败 %v, %v", len(metas), failed, err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("取消后应终止 ffmpeg 立即返回，实际耗时 %s", d)
+	}
+}